@@ -0,0 +1,41 @@
+import { lastValueFrom, of } from 'rxjs';
+
+import { DataQueryRequest, getDefaultTimeRange, TypedVariableModel, VariableHide } from '@grafana/data';
+
+import { BackendSrv, BackendSrvRequest, FetchResponse } from '../services';
+import { setBackendSrv } from '../services/backendSrv';
+import { setTemplateSrv, TemplateSrv } from '../services/templateSrv';
+
+import { publicDashboardQueryHandler } from './publicDashboardQueryHandler';
+
+const fetchMock = jest.fn();
+
+describe('publicDashboardQueryHandler', () => {
+  beforeEach(() => {
+    fetchMock.mockReset();
+    fetchMock.mockReturnValue({ data: { results: {} } } as FetchResponse);
+    setBackendSrv({ fetch: (options: BackendSrvRequest) => of(fetchMock(options)) } as unknown as BackendSrv);
+  });
+
+  it('sends the values of the variables viewers can change', async () => {
+    const variables = [
+      { type: 'custom', name: 'job', hide: VariableHide.dontHide, current: { value: ['api', 'web'] } },
+      { type: 'custom', name: 'env', hide: VariableHide.hideLabel, current: { value: 'prod' } },
+      { type: 'custom', name: 'ds', hide: VariableHide.hideVariable, current: { value: 'prom' } },
+    ] as unknown as TypedVariableModel[];
+    setTemplateSrv({ getVariables: () => variables } as unknown as TemplateSrv);
+
+    await lastValueFrom(
+      publicDashboardQueryHandler({
+        panelId: 2,
+        intervalMs: 1000,
+        maxDataPoints: 100,
+        range: getDefaultTimeRange(),
+        targets: [{ refId: 'A' }],
+      } as DataQueryRequest)
+    );
+
+    expect(fetchMock).toHaveBeenCalledTimes(1);
+    expect(fetchMock.mock.calls[0][0].data.variables).toEqual({ job: ['api', 'web'], env: ['prod'] });
+  });
+});
@@ -0,0 +1,94 @@
+import { DataSourceSettings } from '@grafana/data';
+import { ConfigSubSection, Stack } from '@grafana/experimental';
+import { Field, Icon, Label, Switch, Tooltip } from '@grafana/ui';
+
+import { SQLOptions } from '../../types';
+
+import { NumberInput } from './NumberInput';
+
+interface Props {
+  onOptionsChange: Function;
+  options: DataSourceSettings<SQLOptions>;
+  // Hides the read-only setting of data sources always opening databases read-only
+  alwaysReadOnly?: boolean;
+}
+
+export const QueryLimits = (props: Props) => {
+  const { onOptionsChange, options, alwaysReadOnly } = props;
+  const jsonData = options.jsonData;
+
+  const updateJsonData = (values: {}) => {
+    return onOptionsChange({
+      ...options,
+      jsonData: {
+        ...jsonData,
+        ...values,
+      },
+    });
+  };
+
+  const labelWidth = 40;
+
+  return (
+    <ConfigSubSection title="Query limits">
+      <Field
+        label={
+          <Label>
+            <Stack gap={0.5}>
+              <span>Query timeout</span>
+              <Tooltip
+                content={
+                  <span>
+                    The maximum number of seconds a query may run before it is cancelled on the database server. If set
+                    to 0, queries have no timeout.
+                  </span>
+                }
+              >
+                <Icon name="info-circle" size="sm" />
+              </Tooltip>
+            </Stack>
+          </Label>
+        }
+      >
+        <NumberInput
+          value={jsonData.queryTimeout}
+          defaultValue={0}
+          onChange={(value) => {
+            updateJsonData({ queryTimeout: value });
+          }}
+          width={labelWidth}
+        />
+      </Field>
+
+      {!alwaysReadOnly && (
+        <Field
+          label={
+            <Label>
+              <Stack gap={0.5}>
+                <span>Read only</span>
+                <Tooltip
+                  content={
+                    <span>
+                      If enabled, queries run in read-only transactions, which prevent most changes to the database.
+                      Querying the database with a user having read permissions only is still required to make sure
+                      queries can&apos;t modify it.
+                    </span>
+                  }
+                >
+                  <Icon name="info-circle" size="sm" />
+                </Tooltip>
+              </Stack>
+            </Label>
+          }
+        >
+          <Switch
+            value={jsonData.readOnly || false}
+            onChange={(event) => {
+              updateJsonData({ readOnly: event.currentTarget.checked });
+            }}
+          />
+        </Field>
+      )}
+    </ConfigSubSection>
+  );
+};
@@ -43,6 +43,8 @@ export interface SQLOptions extends SQLConnectionLimits, DataSourceJsonData {
   database: string;
   url: string;
   timeInterval: string;
+  queryTimeout?: number;
+  readOnly?: boolean;
 }
 
 export enum QueryFormat {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -199,16 +200,25 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 		return response.Error(http.StatusBadRequest, "id is invalid", err)
 	}
 
-	cmd := user.DeleteUserCommand{UserID: userID}
-
-	if err := hs.userService.Delete(c.Req.Context(), &cmd); err != nil {
+	if err := hs.deleteUser(c.Req.Context(), userID); err != nil {
 		if errors.Is(err, user.ErrUserNotFound) {
 			return response.Error(http.StatusNotFound, user.ErrUserNotFound.Error(), nil)
 		}
 		return response.Error(http.StatusInternalServerError, "Failed to delete user", err)
 	}
 
-	g, ctx := errgroup.WithContext(c.Req.Context())
+	return response.Success("User deleted")
+}
+
+// deleteUser deletes the user together with everything that belongs to them.
+func (hs *HTTPServer) deleteUser(ctx context.Context, userID int64) error {
+	cmd := user.DeleteUserCommand{UserID: userID}
+
+	if err := hs.userService.Delete(ctx, &cmd); err != nil {
+		return err
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		if err := hs.starService.DeleteByUser(ctx, cmd.UserID); err != nil {
 			return err
@@ -257,11 +267,7 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 		}
 		return nil
 	})
-	if err := g.Wait(); err != nil {
-		return response.Error(http.StatusInternalServerError, "Failed to delete user", err)
-	}
-
-	return response.Success("User deleted")
+	return g.Wait()
 }
 
 // swagger:route POST /admin/users/{user_id}/disable admin_users adminDisableUser
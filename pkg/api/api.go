@@ -68,6 +68,7 @@ func (hs *HTTPServer) registerRoutes() {
 	// not logged in views
 	r.Get("/logout", hs.Logout)
 	r.Post("/login", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginPost))
+	r.Post("/login/mfa", requestmeta.SetOwner(requestmeta.TeamAuth), quota(string(auth.QuotaTargetSrv)), routing.Wrap(hs.LoginMFAPost))
 	r.Get("/login/:name", quota(string(auth.QuotaTargetSrv)), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
@@ -525,6 +526,15 @@ func (hs *HTTPServer) registerRoutes() {
 		// DataSource w/ expressions
 		apiRoute.Post("/ds/query", requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow), authorize(ac.EvalPermission(datasources.ActionQuery)), hs.getDSQueryEndpoint())
 
+		// Recorded query responses, replayed by the TestData datasource
+		apiRoute.Group("/query-recordings", func(recordingRoute routing.RouteRegister) {
+			recordingRoute.Get("/", routing.Wrap(hs.ListQueryRecordings))
+			recordingRoute.Post("/", requestmeta.SetSLOGroup(requestmeta.SLOGroupHighSlow), authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(hs.CreateQueryRecording))
+			// the permissions for the data source of a recording are checked by the handlers
+			recordingRoute.Get("/:name", routing.Wrap(hs.GetQueryRecording))
+			recordingRoute.Delete("/:name", authorize(ac.EvalPermission(datasources.ActionWrite)), routing.Wrap(hs.DeleteQueryRecording))
+		}, authorize(ac.EvalPermission(datasources.ActionQuery)))
+
 		// Unified Alerting
 		apiRoute.Get("/alert-notifiers", reqSignedIn, requestmeta.SetOwner(requestmeta.TeamAlerting), routing.Wrap(
 			hs.GetAlertNotifiers()),
@@ -613,8 +623,14 @@ func (hs *HTTPServer) registerRoutes() {
 
 	// Snapshots
 	r.Post("/api/snapshots/", reqSnapshotPublicModeOrSignedIn, hs.getCreatedSnapshotHandler())
+	r.Post("/api/snapshots/render", reqSignedIn, hs.RenderDashboardSnapshot)
 	r.Get("/api/snapshot/shared-options/", reqSignedIn, hs.GetSharingOptions)
 	r.Get("/api/snapshots/:key", routing.Wrap(hs.GetDashboardSnapshot))
 	r.Get("/api/snapshots-delete/:deleteKey", reqSnapshotPublicModeOrSignedIn, routing.Wrap(hs.DeleteDashboardSnapshotByDeleteKey))
 	r.Delete("/api/snapshots/:key", reqSignedIn, routing.Wrap(hs.DeleteDashboardSnapshot))
+
+	// SCIM provisioning
+	if hs.Cfg.SCIM.Enabled {
+		hs.registerSCIMRoutes()
+	}
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/apikeygen"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/audit"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -99,6 +100,9 @@ func (hs *HTTPServer) DeleteAPIKey(c *contextmodel.ReqContext) response.Response
 		return response.Error(status, "Failed to delete API key", err)
 	}
 
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionAPIKeyDelete).
+		WithResource(audit.ResourceAPIKey, strconv.FormatInt(id, 10), ""))
+
 	return response.Success("API key deleted")
 }
 
@@ -160,6 +164,10 @@ func (hs *HTTPServer) AddAPIKey(c *contextmodel.ReqContext) response.Response {
 		return response.Error(http.StatusInternalServerError, "Failed to add API Key", err)
 	}
 
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionAPIKeyCreate).
+		WithResource(audit.ResourceAPIKey, strconv.FormatInt(key.ID, 10), key.Name).
+		WithDetail("role", cmd.Role))
+
 	result := &dtos.NewApiKeyResult{
 		ID:   key.ID,
 		Name: key.Name,
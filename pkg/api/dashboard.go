@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
@@ -473,6 +474,9 @@ func (hs *HTTPServer) deleteDashboard(c *contextmodel.ReqContext) response.Respo
 		}
 	}
 
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDashboardDelete).
+		WithResource(audit.ResourceDashboard, dash.UID, dash.Title))
+
 	return response.JSON(http.StatusOK, util.DynMap{
 		"title":   dash.Title,
 		"message": fmt.Sprintf("Dashboard %s deleted", dash.Title),
@@ -568,6 +572,34 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 
 	dashboard, saveErr := hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
 
+	merged := false
+	if saveErr != nil && cmd.Merge && !cmd.Overwrite && errors.Is(saveErr, dashboards.ErrDashboardVersionMismatch) {
+		mergeRes, err := hs.dashboardVersionService.Merge(ctx, &dashver.MergeDashboardVersionsQuery{
+			DashboardID:  dash.ID,
+			DashboardUID: dash.UID,
+			OrgID:        dash.OrgID,
+			BaseVersion:  dash.Version,
+			Data:         cmd.Dashboard,
+		})
+		switch {
+		case err != nil:
+			hs.log.Warn("Unable to merge dashboard changes", "uid", dash.UID, "version", dash.Version, "error", err)
+		case len(mergeRes.Conflicts) > 0:
+			return response.JSON(http.StatusPreconditionFailed, util.DynMap{
+				"status":    "version-mismatch",
+				"message":   dashboards.ErrDashboardVersionMismatch.Reason,
+				"version":   mergeRes.Version,
+				"conflicts": mergeRes.Conflicts,
+			})
+		default:
+			cmd.Dashboard = mergeRes.Data
+			dash = cmd.GetDashboardModel()
+			dashItem.Dashboard = dash
+			dashboard, saveErr = hs.DashboardService.SaveDashboard(ctx, dashItem, allowUiUpdate)
+			merged = saveErr == nil
+		}
+	}
+
 	if hs.Live != nil {
 		// Tell everyone listening that the dashboard changed
 		if dashboard == nil {
@@ -608,6 +640,12 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
+	hs.auditService.Log(ctx, audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDashboardSave).
+		WithResource(audit.ResourceDashboard, dashboard.UID, dashboard.Title).
+		WithDetail("version", dashboard.Version).
+		WithDetail("folderUid", dashboard.FolderUID).
+		WithDetail("created", newDashboard))
+
 	c.TimeRequest(metrics.MApiDashboardSave)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"status":    "success",
@@ -617,6 +655,7 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 		"uid":       dashboard.UID,
 		"url":       dashboard.GetURL(),
 		"folderUid": dashboard.FolderUID,
+		"merged":    merged,
 	})
 }
 
@@ -1013,7 +1052,7 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffSemantic {
 		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
 	}
 
@@ -1323,6 +1362,10 @@ type PostDashboardResponse struct {
 		// FolderUID The unique identifier (uid) of the folder the dashboard belongs to.
 		// required: false
 		FolderUID string `json:"folderUid"`
+
+		// Merged is true when the changes were merged into a newer version of the dashboard.
+		// required: false
+		Merged bool `json:"merged"`
 	} `json:"body"`
 }
 
@@ -92,6 +92,35 @@ func (hs *HTTPServer) CreateDashboardSnapshot(c *contextmodel.ReqContext) {
 	}, cmd, hs.dashboardsnapshotsService)
 }
 
+// swagger:route POST /snapshots/render snapshots renderDashboardSnapshot
+//
+// Creates a snapshot of a saved dashboard by executing its queries on the server with the permissions of the signed in user.
+//
+// Responses:
+// 200: renderDashboardSnapshotResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) RenderDashboardSnapshot(c *contextmodel.ReqContext) {
+	cmd := dashboardsnapshots.RenderDashboardSnapshotCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		c.JsonApiErr(http.StatusBadRequest, "bad request data", err)
+		return
+	}
+
+	evaluator := ac.EvalPermission(dashboards.ActionDashboardsWrite, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(cmd.DashboardUID))
+	if canSave, err := hs.AccessControl.Evaluate(c.Req.Context(), c.SignedInUser, evaluator); err != nil || !canSave {
+		c.JsonApiErr(http.StatusForbidden, "forbidden", err)
+		return
+	}
+
+	dashboardsnapshots.RenderDashboardSnapshot(c, dashboardsnapshot.SnapshotSharingOptions{
+		SnapshotsEnabled: hs.Cfg.SnapshotEnabled,
+	}, cmd, hs.dashboardsnapshotsService, hs.DashboardService, hs.queryDataService)
+}
+
 // GET /api/snapshots/:key
 // swagger:route GET /snapshots/{key} snapshots getDashboardSnapshot
 //
@@ -314,6 +343,13 @@ type CreateSnapshotParams struct {
 	Body dashboardsnapshots.CreateDashboardSnapshotCommand `json:"body"`
 }
 
+// swagger:parameters renderDashboardSnapshot
+type RenderSnapshotParams struct {
+	// in:body
+	// required:true
+	Body dashboardsnapshots.RenderDashboardSnapshotCommand `json:"body"`
+}
+
 // swagger:parameters searchDashboardSnapshots
 type GetSnapshotsParams struct {
 	// Search Query
@@ -358,6 +394,12 @@ type CreateSnapshotResponse struct {
 	} `json:"body"`
 }
 
+// swagger:response renderDashboardSnapshotResponse
+type RenderSnapshotResponse struct {
+	// in:body
+	Body dashboardsnapshots.RenderDashboardSnapshotResponse `json:"body"`
+}
+
 // swagger:response searchDashboardSnapshotsResponse
 type SearchDashboardSnapshotsResponse struct {
 	// in:body
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -9,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -23,6 +25,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web/webtest"
@@ -125,6 +128,71 @@ func TestHTTPServer_DeleteDashboardSnapshot(t *testing.T) {
 	})
 }
 
+func TestHTTPServer_RenderDashboardSnapshot(t *testing.T) {
+	setup := func(t *testing.T) *webtest.Server {
+		t.Helper()
+
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			cfg := setting.NewCfg()
+			cfg.SnapshotEnabled = true
+			hs.Cfg = cfg
+
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(&dashboards.Dashboard{
+				UID:   "dash",
+				OrgID: 1,
+				Title: "Service",
+				Data:  simplejson.MustJson([]byte(`{"uid":"dash","panels":[{"id":1,"datasource":{"uid":"prom"},"targets":[{"refId":"A"}]}]}`)),
+			}, nil).Maybe()
+			hs.DashboardService = dashSvc
+
+			querySvc := query.NewFakeQueryService(t)
+			querySvc.On("QueryData", mock.Anything, mock.Anything, false, mock.Anything).Return(backend.NewQueryDataResponse(), nil).Maybe()
+			hs.queryDataService = querySvc
+
+			snapshotSvc := dashboardsnapshots.NewMockService(t)
+			snapshotSvc.On("CreateDashboardSnapshot", mock.Anything, mock.Anything).Return(&dashboardsnapshots.DashboardSnapshot{Key: "key", DeleteKey: "delete-key"}, nil).Maybe()
+			hs.dashboardsnapshotsService = snapshotSvc
+
+			hs.AccessControl = acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient())
+		})
+	}
+
+	newRequest := func(server *webtest.Server) *http.Request {
+		req := server.NewPostRequest("/api/snapshots/render", strings.NewReader(`{"dashboardUid":"dash","from":"now-1h","to":"now","expires":3600}`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	t.Run("User should not be able to render snapshot without permissions", func(t *testing.T) {
+		server := setup(t)
+		res, err := server.Send(webtest.RequestWithSignedInUser(
+			newRequest(server),
+			&user.SignedInUser{UserID: 1, OrgID: 1},
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("User should be able to render snapshot with correct permissions", func(t *testing.T) {
+		server := setup(t)
+		res, err := server.Send(webtest.RequestWithSignedInUser(
+			newRequest(server),
+			userWithPermissions(1, []accesscontrol.Permission{{Action: dashboards.ActionDashboardsWrite, Scope: "dashboards:uid:dash"}}),
+		))
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		result := map[string]any{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+		assert.Equal(t, "key", result["key"])
+		assert.Equal(t, "delete-key", result["deleteKey"])
+		require.NoError(t, res.Body.Close())
+	})
+}
+
 func TestDashboardSnapshotAPIEndpoint_singleSnapshot(t *testing.T) {
 	setupRemoteServer := func(fn func(http.ResponseWriter, *http.Request)) *httptest.Server {
 		s := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
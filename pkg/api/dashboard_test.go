@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/db/dbtest"
@@ -30,6 +31,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
 	accesscontrolmock "github.com/grafana/grafana/pkg/services/accesscontrol/mock"
 	"github.com/grafana/grafana/pkg/services/annotations/annotationstest"
+	"github.com/grafana/grafana/pkg/services/audit/audittest"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -46,6 +48,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/librarypanels"
 	"github.com/grafana/grafana/pkg/services/licensing/licensingtest"
 	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	pref "github.com/grafana/grafana/pkg/services/preference"
@@ -131,7 +134,7 @@ func newTestLive(t *testing.T, store db.DB) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features, acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		features, acimpl.ProvideAccessControl(features, zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, managedstream.NewMemoryFrameCache(managedstream.HistoryOptions{}))
 	require.NoError(t, err)
 	return gLive
 }
@@ -467,6 +470,67 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 			})
 		})
 
+		t.Run("Given a request to save a stale version of a dashboard with merge", func(t *testing.T) {
+			cmd := dashboards.SaveDashboardCommand{
+				OrgID:  1,
+				UserID: 5,
+				Dashboard: simplejson.NewFromAny(map[string]any{
+					"id":      2,
+					"uid":     "uid",
+					"title":   "Dash",
+					"version": 1,
+				}),
+				Merge: true,
+			}
+
+			t.Run("Saves the merged dashboard when there are no conflicts", func(t *testing.T) {
+				dashboardService := dashboards.NewFakeDashboardService(t)
+				dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
+					Return(nil, dashboards.ErrDashboardVersionMismatch).Once()
+				dashboardService.On("SaveDashboard", mock.Anything, mock.MatchedBy(func(dto *dashboards.SaveDashboardDTO) bool {
+					return dto.Dashboard.Version == 2 && dto.Dashboard.Data.Get("refresh").MustString() == "5m"
+				}), mock.AnythingOfType("bool")).
+					Return(&dashboards.Dashboard{ID: 2, UID: "uid", Title: "Dash", Slug: "dash", Version: 3}, nil).Once()
+
+				dashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+				dashboardVersionService.ExpectedMergeResult = &dashver.MergeDashboardVersionsResult{
+					Data:    simplejson.NewFromAny(map[string]any{"id": 2, "uid": "uid", "title": "Dash", "version": 2, "refresh": "5m"}),
+					Version: 2,
+				}
+
+				postDashboardMergeScenario(t, "When calling POST on", "/api/dashboards", cmd, dashboardService, dashboardVersionService, func(sc *scenarioContext) {
+					callPostDashboardShouldReturnSuccess(sc)
+
+					result := sc.ToJSON()
+					assert.Equal(t, "success", result.Get("status").MustString())
+					assert.Equal(t, 3, result.Get("version").MustInt())
+					assert.True(t, result.Get("merged").MustBool())
+				})
+			})
+
+			t.Run("Returns the conflicts when the changes can't be merged", func(t *testing.T) {
+				dashboardService := dashboards.NewFakeDashboardService(t)
+				dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
+					Return(nil, dashboards.ErrDashboardVersionMismatch).Once()
+
+				dashboardVersionService := dashvertest.NewDashboardVersionServiceFake()
+				dashboardVersionService.ExpectedMergeResult = &dashver.MergeDashboardVersionsResult{
+					Data:      simplejson.New(),
+					Version:   2,
+					Conflicts: []dashdiffs.Conflict{{Path: "title", Base: "Dash", Current: "Current", Incoming: "Incoming"}},
+				}
+
+				postDashboardMergeScenario(t, "When calling POST on", "/api/dashboards", cmd, dashboardService, dashboardVersionService, func(sc *scenarioContext) {
+					callPostDashboard(sc)
+					assert.Equal(t, http.StatusPreconditionFailed, sc.resp.Code)
+
+					result := sc.ToJSON()
+					assert.Equal(t, "version-mismatch", result.Get("status").MustString())
+					assert.Equal(t, "title", result.Get("conflicts").GetIndex(0).Get("path").MustString())
+				})
+			})
+		})
+
 		// This tests that invalid requests returns expected error responses
 		t.Run("Given incorrect requests for creating a dashboard", func(t *testing.T) {
 			testCases := []struct {
@@ -914,6 +978,7 @@ func postDashboardScenario(t *testing.T, desc string, url string, routePattern s
 			folderService:         folderService,
 			Features:              featuremgmt.WithFeatures(),
 			accesscontrolService:  actest.FakeService{},
+			auditService:          &audittest.FakeService{},
 			log:                   log.New("test-logger"),
 			tracer:                tracing.InitializeTracerForTest(),
 		}
@@ -934,6 +999,40 @@ func postDashboardScenario(t *testing.T, desc string, url string, routePattern s
 	})
 }
 
+func postDashboardMergeScenario(t *testing.T, desc string, url string, cmd dashboards.SaveDashboardCommand, dashboardService dashboards.DashboardService, dashboardVersionService dashver.Service, fn scenarioFunc) {
+	t.Run(fmt.Sprintf("%s %s", desc, url), func(t *testing.T) {
+		hs := HTTPServer{
+			Cfg:                          setting.NewCfg(),
+			ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+			dashboardProvisioningService: mockDashboardProvisioningService{},
+			QuotaService:                 quotatest.New(false, nil),
+			pluginStore:                  &pluginstore.FakePluginStore{},
+			LibraryPanelService:          &mockLibraryPanelService{},
+			DashboardService:             dashboardService,
+			dashboardVersionService:      dashboardVersionService,
+			Features:                     featuremgmt.WithFeatures(),
+			accesscontrolService:         actest.FakeService{},
+			auditService:                 &audittest.FakeService{},
+			log:                          log.New("test-logger"),
+			tracer:                       tracing.InitializeTracerForTest(),
+		}
+
+		sc := setupScenarioContext(t, url)
+		sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+			c.Req.Body = mockRequestBody(cmd)
+			c.Req.Header.Add("Content-Type", "application/json")
+			sc.context = c
+			sc.context.SignedInUser = &user.SignedInUser{OrgID: cmd.OrgID, UserID: cmd.UserID}
+
+			return hs.PostDashboard(c)
+		})
+
+		sc.m.Post(url, sc.defaultHandler)
+
+		fn(sc)
+	})
+}
+
 func postDiffScenario(t *testing.T, desc string, url string, routePattern string, cmd dtos.CalculateDiffOptions,
 	role org.RoleType, fn scenarioFunc, sqlmock db.DB, fakeDashboardVersionService *dashvertest.FakeDashboardVersionService,
 ) {
@@ -998,6 +1097,7 @@ func restoreDashboardVersionScenario(t *testing.T, desc string, url string, rout
 			accesscontrolService:    actest.FakeService{},
 			folderService:           folderSvc,
 			tracer:                  tracing.InitializeTracerForTest(),
+			auditService:            &audittest.FakeService{},
 		}
 
 		sc := setupScenarioContext(t, url)
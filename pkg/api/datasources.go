@@ -23,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -191,6 +192,8 @@ func (hs *HTTPServer) DeleteDataSourceById(c *contextmodel.ReqContext) response.
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), ds.UID)
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDatasourceDelete).
+		WithResource(audit.ResourceDatasource, ds.UID, ds.Name))
 
 	return response.Success("Data source deleted")
 }
@@ -270,6 +273,8 @@ func (hs *HTTPServer) DeleteDataSourceByUID(c *contextmodel.ReqContext) response
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), ds.UID)
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDatasourceDelete).
+		WithResource(audit.ResourceDatasource, ds.UID, ds.Name))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -320,6 +325,8 @@ func (hs *HTTPServer) DeleteDataSourceByName(c *contextmodel.ReqContext) respons
 	}
 
 	hs.Live.HandleDatasourceDelete(c.SignedInUser.GetOrgID(), dataSource.UID)
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDatasourceDelete).
+		WithResource(audit.ResourceDatasource, dataSource.UID, dataSource.Name))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message": "Data source deleted",
@@ -478,6 +485,10 @@ func (hs *HTTPServer) AddDataSource(c *contextmodel.ReqContext) response.Respons
 	// Required for cases when caller wants to immediately interact with the newly created object
 	hs.accesscontrolService.ClearUserPermissionCache(c.SignedInUser)
 
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDatasourceCreate).
+		WithResource(audit.ResourceDatasource, dataSource.UID, dataSource.Name).
+		WithDetail("type", dataSource.Type))
+
 	ds := hs.convertModelToDtos(c.Req.Context(), dataSource)
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource added",
@@ -649,6 +660,9 @@ func (hs *HTTPServer) updateDataSourceByID(c *contextmodel.ReqContext, ds *datas
 	datasourceDTO := hs.convertModelToDtos(c.Req.Context(), dataSource)
 
 	hs.Live.HandleDatasourceUpdate(c.SignedInUser.GetOrgID(), datasourceDTO.UID)
+	hs.auditService.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionDatasourceUpdate).
+		WithResource(audit.ResourceDatasource, dataSource.UID, dataSource.Name).
+		WithDetail("type", dataSource.Type))
 
 	return response.JSON(http.StatusOK, util.DynMap{
 		"message":    "Datasource updated",
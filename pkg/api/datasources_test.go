@@ -19,6 +19,7 @@ import (
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
 	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/audit/audittest"
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/datasources"
@@ -118,6 +119,7 @@ func TestAddDataSource_URLWithoutProtocol(t *testing.T) {
 		Cfg:                  setting.NewCfg(),
 		AccessControl:        acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()),
 		accesscontrolService: actest.FakeService{},
+		auditService:         &audittest.FakeService{},
 	}
 
 	sc := setupScenarioContext(t, "/api/datasources")
@@ -292,6 +294,7 @@ func TestUpdateDataSourceTeamHTTPHeaders_InvalidJSONData(t *testing.T) {
 				Cfg:                  setting.NewCfg(),
 				Features:             featuremgmt.WithFeatures(featuremgmt.FlagTeamHttpHeaders),
 				accesscontrolService: actest.FakeService{},
+				auditService:         &audittest.FakeService{},
 				AccessControl: actest.FakeAccessControl{
 					ExpectedEvaluate: true,
 					ExpectedErr:      nil,
@@ -335,6 +338,7 @@ func TestUpdateDataSource_URLWithoutProtocol(t *testing.T) {
 		Cfg:                  setting.NewCfg(),
 		AccessControl:        acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()),
 		accesscontrolService: actest.FakeService{},
+		auditService:         &audittest.FakeService{},
 	}
 
 	sc := setupScenarioContext(t, "/api/datasources/1234")
@@ -368,6 +372,7 @@ func TestUpdateDataSourceByID_DataSourceNameExists(t *testing.T) {
 		Cfg:                  setting.NewCfg(),
 		AccessControl:        acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()),
 		accesscontrolService: actest.FakeService{},
+		auditService:         &audittest.FakeService{},
 		Live:                 newTestLive(t, nil),
 	}
 
@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+type CreateQueryRecordingCmd struct {
+	// Name of the recording, used by the TestData replay scenario
+	Name  string        `json:"name"`
+	Query MetricRequest `json:"query"`
+}
+
+type QueryRecording struct {
+	Name           string    `json:"name"`
+	DatasourceUID  string    `json:"datasourceUid,omitempty"`
+	DatasourceType string    `json:"datasourceType,omitempty"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Recorded       time.Time `json:"recorded"`
+}
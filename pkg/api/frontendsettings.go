@@ -488,7 +488,7 @@ func (hs *HTTPServer) getFSDataSources(c *contextmodel.ReqContext, availablePlug
 		// Update `jsonData.database` for outdated provisioned SQL datasources created WITHOUT the `jsonData` object in their configuration.
 		// In these cases, the `Database` value is defined (if at all) on the root level of the provisioning config object.
 		// This is done for easier warning/error checking on the front end.
-		if (ds.Type == datasources.DS_MSSQL) || (ds.Type == datasources.DS_MYSQL) || (ds.Type == datasources.DS_POSTGRES) {
+		if (ds.Type == datasources.DS_MSSQL) || (ds.Type == datasources.DS_MYSQL) || (ds.Type == datasources.DS_POSTGRES) || (ds.Type == datasources.DS_SQLITE) {
 			// Only update if the value isn't already assigned.
 			if dsDTO.JSONData["database"] == nil || dsDTO.JSONData["database"] == "" {
 				dsDTO.JSONData["database"] = ds.Database
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/expr"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/queryrecording"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// CreateQueryRecording runs a data source query and stores its response so it can be
+// replayed with the TestData replay scenario.
+func (hs *HTTPServer) CreateQueryRecording(c *contextmodel.ReqContext) response.Response {
+	cmd := dtos.CreateQueryRecordingCmd{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	if len(cmd.Query.Queries) == 0 {
+		return response.Error(http.StatusBadRequest, "no queries found", nil)
+	}
+
+	// recordings keep a single data source, whose permissions apply to the whole response
+	var datasourceUID, datasourceType string
+	for _, query := range cmd.Query.Queries {
+		datasource := query.Get("datasource")
+		uid := datasource.Get("uid").MustString()
+		if expr.IsDataSource(uid) {
+			continue
+		}
+		if uid == "" {
+			return response.Error(http.StatusBadRequest, "the queries must set the data source uid", nil)
+		}
+		if datasourceUID != "" && uid != datasourceUID {
+			return response.Error(http.StatusBadRequest, "the queries of a recording must use a single data source", nil)
+		}
+		datasourceUID, datasourceType = uid, datasource.Get("type").MustString()
+	}
+	if datasourceUID == "" {
+		return response.Error(http.StatusBadRequest, "the queries must set the data source uid", nil)
+	}
+	if !hs.canAccessQueryRecording(c, datasources.ActionWrite, datasourceUID) {
+		return response.Error(http.StatusForbidden, "Permission denied", nil)
+	}
+
+	resp, err := hs.queryDataService.QueryData(c.Req.Context(), c.SignedInUser, c.SkipDSCache, cmd.Query)
+	if err != nil {
+		return hs.handleQueryMetricsError(err)
+	}
+
+	timeRange := gtime.NewTimeRange(cmd.Query.From, cmd.Query.To)
+	recording := &recordings.Recording{
+		Name:           cmd.Name,
+		DatasourceUID:  datasourceUID,
+		DatasourceType: datasourceType,
+		From:           timeRange.GetFromAsTimeUTC(),
+		To:             timeRange.GetToAsTimeUTC(),
+		Recorded:       time.Now().UTC(),
+		Response:       resp,
+	}
+
+	if err := hs.queryRecordingStore.Save(c.Req.Context(), c.SignedInUser.GetOrgID(), recording); err != nil {
+		switch {
+		case errors.Is(err, queryrecording.ErrInvalidName):
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, queryrecording.ErrRecordingExists):
+			return response.Error(http.StatusConflict, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to save query recording", err)
+	}
+
+	return response.JSON(http.StatusOK, toQueryRecordingDTO(recording))
+}
+
+// ListQueryRecordings returns the names of the query recordings of the current organization
+// the user can query the data source of.
+func (hs *HTTPServer) ListQueryRecordings(c *contextmodel.ReqContext) response.Response {
+	infos, err := hs.queryRecordingStore.List(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list query recordings", err)
+	}
+
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		if hs.canAccessQueryRecording(c, datasources.ActionQuery, info.DatasourceUID) {
+			names = append(names, info.Name)
+		}
+	}
+	return response.JSON(http.StatusOK, names)
+}
+
+// GetQueryRecording returns a query recording together with the recorded response.
+func (hs *HTTPServer) GetQueryRecording(c *contextmodel.ReqContext) response.Response {
+	recording, errResp := hs.getAccessibleQueryRecording(c, datasources.ActionQuery)
+	if errResp != nil {
+		return errResp
+	}
+	return response.JSONStreaming(http.StatusOK, recording)
+}
+
+// DeleteQueryRecording deletes a query recording.
+func (hs *HTTPServer) DeleteQueryRecording(c *contextmodel.ReqContext) response.Response {
+	recording, errResp := hs.getAccessibleQueryRecording(c, datasources.ActionWrite)
+	if errResp != nil {
+		return errResp
+	}
+	if err := hs.queryRecordingStore.Delete(c.Req.Context(), c.SignedInUser.GetOrgID(), recording.Name); err != nil {
+		if errors.Is(err, recordings.ErrNotFound) {
+			return response.Error(http.StatusNotFound, "Query recording not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete query recording", err)
+	}
+	return response.Success("Query recording deleted")
+}
+
+// getAccessibleQueryRecording returns the recording of the request if the user has the
+// permission for the data source of the recording. Recordings the user can't query are
+// reported as not found.
+func (hs *HTTPServer) getAccessibleQueryRecording(c *contextmodel.ReqContext, action string) (*recordings.Recording, response.Response) {
+	recording, err := hs.queryRecordingStore.GetRecording(c.Req.Context(), c.SignedInUser.GetOrgID(), web.Params(c.Req)[":name"])
+	if err != nil {
+		if errors.Is(err, recordings.ErrNotFound) {
+			return nil, response.Error(http.StatusNotFound, "Query recording not found", err)
+		}
+		return nil, response.Error(http.StatusInternalServerError, "Failed to get query recording", err)
+	}
+	if !hs.canAccessQueryRecording(c, datasources.ActionQuery, recording.DatasourceUID) {
+		return nil, response.Error(http.StatusNotFound, "Query recording not found", nil)
+	}
+	if action != datasources.ActionQuery && !hs.canAccessQueryRecording(c, action, recording.DatasourceUID) {
+		return nil, response.Error(http.StatusForbidden, "Permission denied", nil)
+	}
+	return recording, nil
+}
+
+// canAccessQueryRecording evaluates the data source action for the data source of a recording.
+func (hs *HTTPServer) canAccessQueryRecording(c *contextmodel.ReqContext, action string, datasourceUID string) bool {
+	ok, err := queryrecording.CanAccess(c.Req.Context(), hs.AccessControl, c.SignedInUser, action, datasourceUID)
+	if err != nil {
+		c.Logger.Warn("Failed to evaluate query recording permissions", "error", err)
+		return false
+	}
+	return ok
+}
+
+func toQueryRecordingDTO(recording *recordings.Recording) dtos.QueryRecording {
+	return dtos.QueryRecording{
+		Name:           recording.Name,
+		DatasourceUID:  recording.DatasourceUID,
+		DatasourceType: recording.DatasourceType,
+		From:           recording.From,
+		To:             recording.To,
+		Recorded:       recording.Recorded,
+	}
+}
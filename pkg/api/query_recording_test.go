@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/queryrecording"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+type fakeRecordingQueryService struct{}
+
+func (f *fakeRecordingQueryService) Run(_ context.Context) error {
+	return nil
+}
+
+func (f *fakeRecordingQueryService) QueryData(_ context.Context, _ identity.Requester, _ bool, _ dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	return backend.NewQueryDataResponse(), nil
+}
+
+func TestIntegrationQueryRecordingAPI(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	store := queryrecording.ProvideStore(db.InitTestDB(t))
+	for _, recording := range []*recordings.Recording{
+		{Name: "prom-recording", DatasourceUID: "prom", Response: backend.NewQueryDataResponse()},
+		{Name: "loki-recording", DatasourceUID: "loki", Response: backend.NewQueryDataResponse()},
+	} {
+		require.NoError(t, store.Save(context.Background(), 1, recording))
+	}
+
+	server := SetupAPITestServer(t, func(hs *HTTPServer) {
+		hs.queryRecordingStore = store
+		hs.queryDataService = &fakeRecordingQueryService{}
+	})
+
+	queryProm := accesscontrol.Permission{Action: datasources.ActionQuery, Scope: datasources.ScopeProvider.GetResourceScopeUID("prom")}
+	writeProm := accesscontrol.Permission{Action: datasources.ActionWrite, Scope: datasources.ScopeProvider.GetResourceScopeUID("prom")}
+
+	send := func(t *testing.T, req *http.Request, permissions ...accesscontrol.Permission) *http.Response {
+		t.Helper()
+		res, err := server.SendJSON(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		return res
+	}
+
+	t.Run("recordings can only be read with the query permission for their data source", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, send(t, server.NewGetRequest("/api/query-recordings/prom-recording"), queryProm).StatusCode)
+		assert.Equal(t, http.StatusNotFound, send(t, server.NewGetRequest("/api/query-recordings/loki-recording"), queryProm).StatusCode)
+	})
+
+	t.Run("recordings are listed only for the data sources the user can query", func(t *testing.T) {
+		req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/query-recordings"), userWithPermissions(1, []accesscontrol.Permission{queryProm}))
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		var names []string
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&names))
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, []string{"prom-recording"}, names)
+	})
+
+	t.Run("recordings can only be created with the write permission for the data source", func(t *testing.T) {
+		body := `{"name": "new-recording", "query": {"queries": [{"refId": "A", "datasource": {"uid": "prom"}}]}}`
+		assert.Equal(t, http.StatusForbidden, send(t, server.NewPostRequest("/api/query-recordings", strings.NewReader(body)), queryProm).StatusCode)
+		assert.Equal(t, http.StatusOK, send(t, server.NewPostRequest("/api/query-recordings", strings.NewReader(body)), queryProm, writeProm).StatusCode)
+	})
+
+	t.Run("recordings can only be created from the queries of a single data source", func(t *testing.T) {
+		body := `{"name": "mixed-recording", "query": {"queries": [{"refId": "A", "datasource": {"uid": "prom"}}, {"refId": "B", "datasource": {"uid": "loki"}}]}}`
+		assert.Equal(t, http.StatusBadRequest, send(t, server.NewPostRequest("/api/query-recordings", strings.NewReader(body)), queryProm, writeProm).StatusCode)
+
+		body = `{"name": "expression-recording", "query": {"queries": [{"refId": "A", "datasource": {"uid": "prom"}}, {"refId": "B", "datasource": {"uid": "__expr__"}}]}}`
+		assert.Equal(t, http.StatusOK, send(t, server.NewPostRequest("/api/query-recordings", strings.NewReader(body)), queryProm, writeProm).StatusCode)
+	})
+
+	t.Run("existing recordings are not replaced", func(t *testing.T) {
+		body := `{"name": "loki-recording", "query": {"queries": [{"refId": "A", "datasource": {"uid": "prom"}}]}}`
+		assert.Equal(t, http.StatusConflict, send(t, server.NewPostRequest("/api/query-recordings", strings.NewReader(body)), queryProm, writeProm).StatusCode)
+
+		recording, err := store.GetRecording(context.Background(), 1, "loki-recording")
+		require.NoError(t, err)
+		assert.Equal(t, "loki", recording.DatasourceUID)
+	})
+
+	t.Run("recordings can only be deleted with the write permission for their data source", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, send(t, server.NewRequest(http.MethodDelete, "/api/query-recordings/prom-recording", nil), queryProm).StatusCode)
+		assert.Equal(t, http.StatusNotFound, send(t, server.NewRequest(http.MethodDelete, "/api/query-recordings/loki-recording", nil), queryProm, writeProm).StatusCode)
+		assert.Equal(t, http.StatusOK, send(t, server.NewRequest(http.MethodDelete, "/api/query-recordings/prom-recording", nil), queryProm, writeProm).StatusCode)
+	})
+}
@@ -0,0 +1,881 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/scim"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+const (
+	scimDefaultCount = 100
+	scimMaxCount     = 1000
+)
+
+// scimAuth only lets the service account configured in [auth.scim] use the SCIM API.
+// Users and teams are provisioned in the organization of the service account.
+func (hs *HTTPServer) scimAuth(c *contextmodel.ReqContext) {
+	namespace, _ := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceServiceAccount ||
+		hs.Cfg.SCIM.ServiceAccountLogin == "" ||
+		c.SignedInUser.GetLogin() != hs.Cfg.SCIM.ServiceAccountLogin {
+		scimError(http.StatusForbidden, "", "only the SCIM service account can use the SCIM API").WriteTo(c)
+		return
+	}
+	if !c.SignedInUser.GetOrgRole().Includes(org.RoleAdmin) {
+		scimError(http.StatusForbidden, "", "the SCIM service account must have the Admin role").WriteTo(c)
+		return
+	}
+}
+
+func (hs *HTTPServer) registerSCIMRoutes() {
+	hs.RouteRegister.Group("/api/scim/v2", func(scimRoute routing.RouteRegister) {
+		scimRoute.Get("/ServiceProviderConfig", routing.Wrap(hs.scimGetServiceProviderConfig))
+		scimRoute.Get("/ResourceTypes", routing.Wrap(hs.scimGetResourceTypes))
+
+		scimRoute.Get("/Users", routing.Wrap(hs.scimListUsers))
+		scimRoute.Post("/Users", routing.Wrap(hs.scimCreateUser))
+		scimRoute.Get("/Users/:id", routing.Wrap(hs.scimGetUser))
+		scimRoute.Put("/Users/:id", routing.Wrap(hs.scimReplaceUser))
+		scimRoute.Patch("/Users/:id", routing.Wrap(hs.scimPatchUser))
+		scimRoute.Delete("/Users/:id", routing.Wrap(hs.scimDeleteUser))
+
+		scimRoute.Get("/Groups", routing.Wrap(hs.scimListGroups))
+		scimRoute.Post("/Groups", routing.Wrap(hs.scimCreateGroup))
+		scimRoute.Get("/Groups/:id", routing.Wrap(hs.scimGetGroup))
+		scimRoute.Put("/Groups/:id", routing.Wrap(hs.scimReplaceGroup))
+		scimRoute.Patch("/Groups/:id", routing.Wrap(hs.scimPatchGroup))
+		scimRoute.Delete("/Groups/:id", routing.Wrap(hs.scimDeleteGroup))
+	}, middleware.ReqSignedIn, hs.scimAuth)
+}
+
+func (hs *HTTPServer) scimGetServiceProviderConfig(c *contextmodel.ReqContext) response.Response {
+	return scimJSON(http.StatusOK, map[string]any{
+		"schemas":          []string{scim.SchemaServiceProviderConfig},
+		"documentationUri": "https://grafana.com/docs/grafana/latest/setup-grafana/configure-security/configure-scim-provisioning/",
+		"patch":            map[string]any{"supported": true},
+		"bulk":             map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":           map[string]any{"supported": true, "maxResults": scimMaxCount},
+		"changePassword":   map[string]any{"supported": false},
+		"sort":             map[string]any{"supported": false},
+		"etag":             map[string]any{"supported": false},
+		"authenticationSchemes": []map[string]any{{
+			"type":        "oauthbearertoken",
+			"name":        "Service account token",
+			"description": "Authentication with the token of the service account configured in [auth.scim]",
+		}},
+	})
+}
+
+func (hs *HTTPServer) scimGetResourceTypes(c *contextmodel.ReqContext) response.Response {
+	types := []any{
+		map[string]any{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   scim.SchemaUser,
+		},
+		map[string]any{
+			"schemas":  []string{scim.SchemaResourceType},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   scim.SchemaGroup,
+		},
+	}
+	return scimJSON(http.StatusOK, scim.NewListResponse(types, len(types), 1))
+}
+
+func (hs *HTTPServer) scimListUsers(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	filter, startIndex, count, errResp := parseSCIMListParams(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	orgUsers, err := hs.orgService.GetOrgUsers(ctx, &org.GetOrgUsersQuery{
+		OrgID:                    c.SignedInUser.GetOrgID(),
+		DontEnforceAccessControl: true,
+	})
+	if err != nil {
+		return hs.scimInternalError("Failed to list users", err)
+	}
+
+	// external ids are stored separately, only look them up for every user when
+	// the filter needs them
+	withExternalID := strings.Contains(strings.ToLower(c.Query("filter")), "externalid")
+	users := make([]scim.User, 0, len(orgUsers))
+	for _, ou := range orgUsers {
+		u := hs.toSCIMUser(ou.UserID, ou.Login, ou.Email, ou.Name, ou.IsDisabled)
+		if withExternalID {
+			if u.ExternalID, err = hs.scimExternalID(ctx, ou.UserID); err != nil {
+				return hs.scimInternalError("Failed to list users", err)
+			}
+		}
+		if filter != nil {
+			obj, err := scim.ToJSONObject(u)
+			if err != nil {
+				return hs.scimInternalError("Failed to list users", err)
+			}
+			if !filter.Matches(obj) {
+				continue
+			}
+		}
+		users = append(users, u)
+	}
+
+	page := paginateSCIM(users, startIndex, count)
+	if !withExternalID {
+		for i := range page {
+			id, _ := strconv.ParseInt(page[i].ID, 10, 64)
+			if page[i].ExternalID, err = hs.scimExternalID(ctx, id); err != nil {
+				return hs.scimInternalError("Failed to list users", err)
+			}
+		}
+	}
+	return scimJSON(http.StatusOK, scim.NewListResponse(page, len(users), startIndex))
+}
+
+func (hs *HTTPServer) scimGetUser(c *contextmodel.ReqContext) response.Response {
+	usr, errResp := hs.scimGetOrgUser(c)
+	if errResp != nil {
+		return errResp
+	}
+	u, err := hs.toSCIMUserWithExternalID(c.Req.Context(), usr)
+	if err != nil {
+		return hs.scimInternalError("Failed to get user", err)
+	}
+	return scimJSON(http.StatusOK, u)
+}
+
+// scimCreateUser creates a user in the organization of the service account. Existing
+// users, including users of other organizations, are never taken over.
+func (hs *HTTPServer) scimCreateUser(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	var u scim.User
+	if errResp := bindSCIM(c, &u); errResp != nil {
+		return errResp
+	}
+	if strings.TrimSpace(u.UserName) == "" {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "userName is required")
+	}
+
+	usr, err := hs.userService.Create(ctx, &user.CreateUserCommand{
+		Login:        u.UserName,
+		Email:        u.PrimaryEmail(),
+		Name:         u.FullName(),
+		OrgID:        orgID,
+		IsDisabled:   !u.IsActive(),
+		SkipOrgSetup: true,
+	})
+	if err != nil {
+		if errors.Is(err, user.ErrUserAlreadyExists) {
+			return scimError(http.StatusConflict, scim.ErrorTypeUniqueness, "a user with this userName or email already exists")
+		}
+		return hs.scimInternalError("Failed to create user", err)
+	}
+
+	if _, err := hs.orgService.InsertOrgUser(ctx, &org.OrgUser{
+		OrgID:   orgID,
+		UserID:  usr.ID,
+		Role:    hs.scimOrgRole(),
+		Created: time.Now(),
+		Updated: time.Now(),
+	}); err != nil {
+		return hs.scimInternalError("Failed to add user to organization", err)
+	}
+	// the auth info marks the user as provisioned through SCIM, even without an external id
+	if err := hs.authInfoService.SetAuthInfo(ctx, &login.SetAuthInfoCommand{AuthModule: scim.AuthModule, AuthId: u.ExternalID, UserId: usr.ID}); err != nil {
+		return hs.scimInternalError("Failed to create user", err)
+	}
+
+	return hs.scimUserResponse(ctx, http.StatusCreated, usr.ID)
+}
+
+func (hs *HTTPServer) scimReplaceUser(c *contextmodel.ReqContext) response.Response {
+	usr, errResp := hs.scimGetProvisionedUser(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var u scim.User
+	if errResp := bindSCIM(c, &u); errResp != nil {
+		return errResp
+	}
+	if strings.TrimSpace(u.UserName) == "" {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "userName is required")
+	}
+
+	if errResp := hs.scimSaveUser(c.Req.Context(), usr, &u); errResp != nil {
+		return errResp
+	}
+	return hs.scimUserResponse(c.Req.Context(), http.StatusOK, usr.ID)
+}
+
+func (hs *HTTPServer) scimPatchUser(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	usr, errResp := hs.scimGetProvisionedUser(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var patch scim.PatchRequest
+	if errResp := bindSCIM(c, &patch); errResp != nil {
+		return errResp
+	}
+
+	current, err := hs.toSCIMUserWithExternalID(ctx, usr)
+	if err != nil {
+		return hs.scimInternalError("Failed to update user", err)
+	}
+	var u scim.User
+	if errResp := applySCIMPatch(current, patch, &u); errResp != nil {
+		return errResp
+	}
+
+	if errResp := hs.scimSaveUser(ctx, usr, &u); errResp != nil {
+		return errResp
+	}
+	return hs.scimUserResponse(ctx, http.StatusOK, usr.ID)
+}
+
+// scimDeleteUser removes the user from the organization of the service account, and
+// deletes the user when it doesn't belong to any other organization.
+func (hs *HTTPServer) scimDeleteUser(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	usr, errResp := hs.scimGetProvisionedUser(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := hs.orgService.RemoveOrgUser(ctx, &org.RemoveOrgUserCommand{UserID: usr.ID, OrgID: orgID}); err != nil {
+		if errors.Is(err, org.ErrLastOrgAdmin) {
+			return scimError(http.StatusBadRequest, scim.ErrorTypeMutability, "cannot remove the last organization admin")
+		}
+		return hs.scimInternalError("Failed to delete user", err)
+	}
+	if err := hs.accesscontrolService.DeleteUserPermissions(ctx, orgID, usr.ID); err != nil {
+		hs.log.Warn("Failed to delete permissions for user", "userID", usr.ID, "orgID", orgID, "err", err)
+	}
+
+	orgs, err := hs.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: usr.ID})
+	if err != nil {
+		return hs.scimInternalError("Failed to delete user", err)
+	}
+	if len(orgs) == 0 {
+		if err := hs.deleteUser(ctx, usr.ID); err != nil {
+			return hs.scimInternalError("Failed to delete user", err)
+		}
+	}
+
+	return response.Empty(http.StatusNoContent)
+}
+
+// scimSaveUser updates the user with the attributes of the SCIM user. Tokens of users
+// that are deactivated are revoked.
+func (hs *HTTPServer) scimSaveUser(ctx context.Context, usr *user.User, u *scim.User) response.Response {
+	isDisabled := !u.IsActive()
+	cmd := &user.UpdateUserCommand{
+		UserID:     usr.ID,
+		Login:      u.UserName,
+		Email:      u.PrimaryEmail(),
+		Name:       u.FullName(),
+		IsDisabled: &isDisabled,
+	}
+	if err := hs.userService.Update(ctx, cmd); err != nil {
+		if errors.Is(err, user.ErrCaseInsensitive) || errors.Is(err, user.ErrUserAlreadyExists) {
+			return scimError(http.StatusConflict, scim.ErrorTypeUniqueness, "a user with this userName or email already exists")
+		}
+		return hs.scimInternalError("Failed to update user", err)
+	}
+
+	if isDisabled && !usr.IsDisabled {
+		if err := hs.AuthTokenService.RevokeAllUserTokens(ctx, usr.ID); err != nil {
+			return hs.scimInternalError("Failed to revoke the sessions of the user", err)
+		}
+	}
+
+	if u.ExternalID == "" {
+		return nil
+	}
+	externalID, err := hs.scimExternalID(ctx, usr.ID)
+	if err != nil {
+		return hs.scimInternalError("Failed to update user", err)
+	}
+	if externalID != u.ExternalID {
+		// provisioned users always have the auth info, it only has to be updated
+		cmd := &login.UpdateAuthInfoCommand{AuthModule: scim.AuthModule, AuthId: u.ExternalID, UserId: usr.ID}
+		if err := hs.authInfoService.UpdateAuthInfo(ctx, cmd); err != nil {
+			return hs.scimInternalError("Failed to update user", err)
+		}
+	}
+	return nil
+}
+
+// scimGetOrgUser returns the user in the id path parameter. Users outside of the
+// organization of the service account are not found.
+func (hs *HTTPServer) scimGetOrgUser(c *contextmodel.ReqContext) (*user.User, response.Response) {
+	ctx := c.Req.Context()
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return nil, scimError(http.StatusNotFound, "", "user not found")
+	}
+
+	usr, err := hs.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, scimError(http.StatusNotFound, "", "user not found")
+		}
+		return nil, hs.scimInternalError("Failed to get user", err)
+	}
+	if usr.IsServiceAccount {
+		return nil, scimError(http.StatusNotFound, "", "user not found")
+	}
+
+	member, err := hs.isOrgMember(ctx, c.SignedInUser.GetOrgID(), usr.ID)
+	if err != nil {
+		return nil, hs.scimInternalError("Failed to get user", err)
+	}
+	if !member {
+		return nil, scimError(http.StatusNotFound, "", "user not found")
+	}
+	return usr, nil
+}
+
+// scimGetProvisionedUser returns the user in the id path parameter if the service account
+// may change it. Only users provisioned through SCIM that aren't members of other
+// organizations can be changed, and server admins never are.
+func (hs *HTTPServer) scimGetProvisionedUser(c *contextmodel.ReqContext) (*user.User, response.Response) {
+	ctx := c.Req.Context()
+	usr, errResp := hs.scimGetOrgUser(c)
+	if errResp != nil {
+		return nil, errResp
+	}
+	if usr.IsAdmin {
+		return nil, scimError(http.StatusForbidden, "", "server admins can't be changed through SCIM")
+	}
+
+	if _, err := hs.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: usr.ID, AuthModule: scim.AuthModule}); err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return nil, scimError(http.StatusForbidden, "", "the user wasn't provisioned through SCIM")
+		}
+		return nil, hs.scimInternalError("Failed to get user", err)
+	}
+
+	orgs, err := hs.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: usr.ID})
+	if err != nil {
+		return nil, hs.scimInternalError("Failed to get user", err)
+	}
+	for _, o := range orgs {
+		if o.OrgID != c.SignedInUser.GetOrgID() {
+			return nil, scimError(http.StatusForbidden, "", "the user is a member of other organizations")
+		}
+	}
+	return usr, nil
+}
+
+func (hs *HTTPServer) scimUserResponse(ctx context.Context, status int, userID int64) response.Response {
+	usr, err := hs.userService.GetByID(ctx, &user.GetUserByIDQuery{ID: userID})
+	if err != nil {
+		return hs.scimInternalError("Failed to get user", err)
+	}
+	u, err := hs.toSCIMUserWithExternalID(ctx, usr)
+	if err != nil {
+		return hs.scimInternalError("Failed to get user", err)
+	}
+	return scimJSON(status, u)
+}
+
+func (hs *HTTPServer) toSCIMUserWithExternalID(ctx context.Context, usr *user.User) (scim.User, error) {
+	u := hs.toSCIMUser(usr.ID, usr.Login, usr.Email, usr.Name, usr.IsDisabled)
+	u.Meta.Created = &usr.Created
+	u.Meta.LastModified = &usr.Updated
+
+	externalID, err := hs.scimExternalID(ctx, usr.ID)
+	if err != nil {
+		return scim.User{}, err
+	}
+	u.ExternalID = externalID
+	return u, nil
+}
+
+func (hs *HTTPServer) toSCIMUser(id int64, login, email, name string, isDisabled bool) scim.User {
+	active := !isDisabled
+	u := scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.FormatInt(id, 10),
+		UserName:    login,
+		DisplayName: name,
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Location:     hs.scimLocation("Users", id),
+		},
+	}
+	if name != "" {
+		u.Name = &scim.Name{Formatted: name}
+	}
+	if email != "" {
+		u.Emails = []scim.Email{{Value: email, Type: "work", Primary: true}}
+	}
+	return u
+}
+
+// scimExternalID returns the external id the identity provider has set for the user.
+func (hs *HTTPServer) scimExternalID(ctx context.Context, userID int64) (string, error) {
+	info, err := hs.authInfoService.GetAuthInfo(ctx, &login.GetAuthInfoQuery{UserId: userID, AuthModule: scim.AuthModule})
+	if err != nil {
+		if errors.Is(err, user.ErrUserNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return info.AuthId, nil
+}
+
+func (hs *HTTPServer) scimListGroups(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	filter, startIndex, count, errResp := parseSCIMListParams(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	result, err := hs.teamService.SearchTeams(ctx, &team.SearchTeamsQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		SignedInUser: c.SignedInUser,
+		HiddenUsers:  map[string]struct{}{},
+	})
+	if err != nil {
+		return hs.scimInternalError("Failed to list groups", err)
+	}
+
+	// looking up the members of every team is expensive, only do it when the filter
+	// needs them or for the teams that are returned
+	withMembers := strings.Contains(strings.ToLower(c.Query("filter")), "members")
+	excludeMembers := strings.Contains(strings.ToLower(c.Query("excludedAttributes")), "members")
+	groups := make([]scim.Group, 0, len(result.Teams))
+	for _, t := range result.Teams {
+		g := hs.toSCIMGroup(t)
+		if withMembers {
+			if g.Members, err = hs.scimGroupMembers(c, t.ID); err != nil {
+				return hs.scimInternalError("Failed to list groups", err)
+			}
+		}
+		if filter != nil {
+			obj, err := scim.ToJSONObject(g)
+			if err != nil {
+				return hs.scimInternalError("Failed to list groups", err)
+			}
+			if !filter.Matches(obj) {
+				continue
+			}
+		}
+		groups = append(groups, g)
+	}
+
+	page := paginateSCIM(groups, startIndex, count)
+	for i := range page {
+		switch {
+		case excludeMembers:
+			page[i].Members = nil
+		case !withMembers:
+			id, _ := strconv.ParseInt(page[i].ID, 10, 64)
+			if page[i].Members, err = hs.scimGroupMembers(c, id); err != nil {
+				return hs.scimInternalError("Failed to list groups", err)
+			}
+		}
+	}
+	return scimJSON(http.StatusOK, scim.NewListResponse(page, len(groups), startIndex))
+}
+
+func (hs *HTTPServer) scimGetGroup(c *contextmodel.ReqContext) response.Response {
+	t, errResp := hs.scimGetTeam(c)
+	if errResp != nil {
+		return errResp
+	}
+	return hs.scimGroupResponse(c, http.StatusOK, t.ID)
+}
+
+func (hs *HTTPServer) scimCreateGroup(c *contextmodel.ReqContext) response.Response {
+	var g scim.Group
+	if errResp := bindSCIM(c, &g); errResp != nil {
+		return errResp
+	}
+	if strings.TrimSpace(g.DisplayName) == "" {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "displayName is required")
+	}
+
+	t, err := hs.teamService.CreateTeam(c.Req.Context(), g.DisplayName, "", c.SignedInUser.GetOrgID())
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNameTaken) {
+			return scimError(http.StatusConflict, scim.ErrorTypeUniqueness, "a group with this displayName already exists")
+		}
+		return hs.scimInternalError("Failed to create group", err)
+	}
+
+	if errResp := hs.scimSetGroupMembers(c, t.ID, g.Members); errResp != nil {
+		return errResp
+	}
+	return hs.scimGroupResponse(c, http.StatusCreated, t.ID)
+}
+
+func (hs *HTTPServer) scimReplaceGroup(c *contextmodel.ReqContext) response.Response {
+	t, errResp := hs.scimGetTeam(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var g scim.Group
+	if errResp := bindSCIM(c, &g); errResp != nil {
+		return errResp
+	}
+
+	if errResp := hs.scimSaveGroup(c, t, &g); errResp != nil {
+		return errResp
+	}
+	return hs.scimGroupResponse(c, http.StatusOK, t.ID)
+}
+
+func (hs *HTTPServer) scimPatchGroup(c *contextmodel.ReqContext) response.Response {
+	t, errResp := hs.scimGetTeam(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	var patch scim.PatchRequest
+	if errResp := bindSCIM(c, &patch); errResp != nil {
+		return errResp
+	}
+
+	current := hs.toSCIMGroup(t)
+	members, err := hs.scimGroupMembers(c, t.ID)
+	if err != nil {
+		return hs.scimInternalError("Failed to update group", err)
+	}
+	current.Members = members
+
+	var g scim.Group
+	if errResp := applySCIMPatch(current, patch, &g); errResp != nil {
+		return errResp
+	}
+
+	if errResp := hs.scimSaveGroup(c, t, &g); errResp != nil {
+		return errResp
+	}
+	return hs.scimGroupResponse(c, http.StatusOK, t.ID)
+}
+
+func (hs *HTTPServer) scimDeleteGroup(c *contextmodel.ReqContext) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+	t, errResp := hs.scimGetTeam(c)
+	if errResp != nil {
+		return errResp
+	}
+
+	if err := hs.teamService.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: orgID, ID: t.ID}); err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return scimError(http.StatusNotFound, "", "group not found")
+		}
+		return hs.scimInternalError("Failed to delete group", err)
+	}
+	if err := hs.accesscontrolService.DeleteTeamPermissions(ctx, orgID, t.ID); err != nil {
+		return hs.scimInternalError("Failed to delete group permissions", err)
+	}
+
+	return response.Empty(http.StatusNoContent)
+}
+
+// scimSaveGroup renames the team and makes its members the members of the group.
+func (hs *HTTPServer) scimSaveGroup(c *contextmodel.ReqContext, t *team.TeamDTO, g *scim.Group) response.Response {
+	if strings.TrimSpace(g.DisplayName) == "" {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "displayName is required")
+	}
+
+	if g.DisplayName != t.Name {
+		cmd := &team.UpdateTeamCommand{ID: t.ID, OrgID: t.OrgID, Name: g.DisplayName, Email: t.Email}
+		if err := hs.teamService.UpdateTeam(c.Req.Context(), cmd); err != nil {
+			if errors.Is(err, team.ErrTeamNameTaken) {
+				return scimError(http.StatusConflict, scim.ErrorTypeUniqueness, "a group with this displayName already exists")
+			}
+			return hs.scimInternalError("Failed to update group", err)
+		}
+	}
+
+	return hs.scimSetGroupMembers(c, t.ID, g.Members)
+}
+
+// scimSetGroupMembers adds the members that are missing from the team and removes the
+// ones that are not members of the group anymore. Team admins keep their permission.
+func (hs *HTTPServer) scimSetGroupMembers(c *contextmodel.ReqContext, teamID int64, members []scim.Member) response.Response {
+	ctx := c.Req.Context()
+	orgID := c.SignedInUser.GetOrgID()
+
+	wanted := make(map[int64]struct{}, len(members))
+	for _, m := range members {
+		userID, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, fmt.Sprintf("invalid member %q", m.Value))
+		}
+		member, err := hs.isOrgMember(ctx, orgID, userID)
+		if err != nil {
+			return hs.scimInternalError("Failed to update group members", err)
+		}
+		if !member {
+			return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, fmt.Sprintf("user %q is not a member of the organization", m.Value))
+		}
+		wanted[userID] = struct{}{}
+	}
+
+	current, err := hs.teamService.GetTeamMembers(ctx, &team.GetTeamMembersQuery{OrgID: orgID, TeamID: teamID, SignedInUser: c.SignedInUser})
+	if err != nil {
+		return hs.scimInternalError("Failed to update group members", err)
+	}
+
+	teamIDStr := strconv.FormatInt(teamID, 10)
+	for _, m := range current {
+		if _, ok := wanted[m.UserID]; ok {
+			delete(wanted, m.UserID)
+			continue
+		}
+		if _, err := hs.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: m.UserID}, teamIDStr, ""); err != nil {
+			return hs.scimInternalError("Failed to remove group member", err)
+		}
+	}
+
+	// add the remaining members in a stable order
+	added := make([]int64, 0, len(wanted))
+	for userID := range wanted {
+		added = append(added, userID)
+	}
+	sort.Slice(added, func(i, j int) bool { return added[i] < added[j] })
+	for _, userID := range added {
+		if _, err := hs.teamPermissionsService.SetUserPermission(ctx, orgID, accesscontrol.User{ID: userID}, teamIDStr, team.MemberPermissionName); err != nil {
+			return hs.scimInternalError("Failed to add group member", err)
+		}
+	}
+	return nil
+}
+
+func (hs *HTTPServer) scimGetTeam(c *contextmodel.ReqContext) (*team.TeamDTO, response.Response) {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return nil, scimError(http.StatusNotFound, "", "group not found")
+	}
+
+	t, err := hs.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		ID:           teamID,
+		SignedInUser: c.SignedInUser,
+		HiddenUsers:  map[string]struct{}{},
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			return nil, scimError(http.StatusNotFound, "", "group not found")
+		}
+		return nil, hs.scimInternalError("Failed to get group", err)
+	}
+	return t, nil
+}
+
+func (hs *HTTPServer) scimGroupResponse(c *contextmodel.ReqContext, status int, teamID int64) response.Response {
+	t, err := hs.teamService.GetTeamByID(c.Req.Context(), &team.GetTeamByIDQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		ID:           teamID,
+		SignedInUser: c.SignedInUser,
+		HiddenUsers:  map[string]struct{}{},
+	})
+	if err != nil {
+		return hs.scimInternalError("Failed to get group", err)
+	}
+
+	g := hs.toSCIMGroup(t)
+	if g.Members, err = hs.scimGroupMembers(c, t.ID); err != nil {
+		return hs.scimInternalError("Failed to get group", err)
+	}
+	return scimJSON(status, g)
+}
+
+func (hs *HTTPServer) scimGroupMembers(c *contextmodel.ReqContext, teamID int64) ([]scim.Member, error) {
+	members, err := hs.teamService.GetTeamMembers(c.Req.Context(), &team.GetTeamMembersQuery{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		TeamID:       teamID,
+		SignedInUser: c.SignedInUser,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]scim.Member, 0, len(members))
+	for _, m := range members {
+		result = append(result, scim.Member{
+			Value:   strconv.FormatInt(m.UserID, 10),
+			Display: m.Login,
+			Ref:     hs.scimLocation("Users", m.UserID),
+		})
+	}
+	return result, nil
+}
+
+func (hs *HTTPServer) toSCIMGroup(t *team.TeamDTO) scim.Group {
+	return scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		DisplayName: t.Name,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Location:     hs.scimLocation("Groups", t.ID),
+		},
+	}
+}
+
+func (hs *HTTPServer) scimLocation(resource string, id int64) string {
+	return fmt.Sprintf("%sapi/scim/v2/%s/%d", hs.Cfg.AppURL, resource, id)
+}
+
+// scimOrgRole is the role provisioned users get in the organization.
+func (hs *HTTPServer) scimOrgRole() org.RoleType {
+	role := org.RoleType(hs.Cfg.AutoAssignOrgRole)
+	if !role.IsValid() {
+		return org.RoleViewer
+	}
+	return role
+}
+
+func (hs *HTTPServer) isOrgMember(ctx context.Context, orgID, userID int64) (bool, error) {
+	orgs, err := hs.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		if o.OrgID == orgID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (hs *HTTPServer) scimInternalError(message string, err error) response.Response {
+	hs.log.Error(message, "err", err)
+	return scimError(http.StatusInternalServerError, "", message)
+}
+
+// applySCIMPatch applies the patch to the current version of a resource and decodes
+// the result in target.
+func applySCIMPatch(current any, patch scim.PatchRequest, target any) response.Response {
+	obj, err := scim.ToJSONObject(current)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", err.Error())
+	}
+
+	if err := scim.ApplyPatch(obj, patch.Operations); err != nil {
+		switch {
+		case errors.Is(err, scim.ErrInvalidPath), errors.Is(err, scim.ErrInvalidFilter):
+			return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidPath, err.Error())
+		case errors.Is(err, scim.ErrNoTarget):
+			return scimError(http.StatusBadRequest, scim.ErrorTypeNoTarget, err.Error())
+		default:
+			return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, err.Error())
+		}
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return scimError(http.StatusInternalServerError, "", err.Error())
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, err.Error())
+	}
+	return nil
+}
+
+// bindSCIM decodes the body of a request. Unlike web.Bind, it accepts the SCIM media type.
+func bindSCIM(c *contextmodel.ReqContext, v any) response.Response {
+	m, _, err := mime.ParseMediaType(c.Req.Header.Get("Content-Type"))
+	if err != nil || (m != "application/json" && m != scim.ContentType) {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, "the content type must be application/scim+json or application/json")
+	}
+
+	defer func() { _ = c.Req.Body.Close() }()
+	if err := json.NewDecoder(c.Req.Body).Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return scimError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, err.Error())
+	}
+	return nil
+}
+
+// parseSCIMListParams parses the filter and the 1-based paging parameters of a list request.
+func parseSCIMListParams(c *contextmodel.ReqContext) (scim.Filter, int, int, response.Response) {
+	var filter scim.Filter
+	if raw := c.Query("filter"); raw != "" {
+		f, err := scim.ParseFilter(raw)
+		if err != nil {
+			return nil, 0, 0, scimError(http.StatusBadRequest, scim.ErrorTypeInvalidFilter, err.Error())
+		}
+		filter = f
+	}
+
+	startIndex := c.QueryInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count := scimDefaultCount
+	if c.Query("count") != "" {
+		count = c.QueryInt("count")
+	}
+	if count < 0 {
+		count = 0
+	}
+	if count > scimMaxCount {
+		count = scimMaxCount
+	}
+	return filter, startIndex, count, nil
+}
+
+func paginateSCIM[T any](resources []T, startIndex, count int) []T {
+	start := startIndex - 1
+	if start >= len(resources) {
+		return []T{}
+	}
+	end := start + count
+	if end > len(resources) {
+		end = len(resources)
+	}
+	return resources[start:end]
+}
+
+func scimJSON(status int, body any) *response.NormalResponse {
+	return response.JSON(status, body).SetHeader("Content-Type", scim.ContentType)
+}
+
+func scimError(status int, scimType, detail string) *response.NormalResponse {
+	return scimJSON(status, scim.Error{
+		Schemas:  []string{scim.SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	})
+}
@@ -0,0 +1,366 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/auth/authtest"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/login/authinfotest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/scim"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/services/user/usertest"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func setupSCIMTestServer(t *testing.T, opts ...APITestServerOption) *webtest.Server {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.SCIM = setting.SCIMSettings{Enabled: true, ServiceAccountLogin: "sa-scim"}
+
+	return SetupAPITestServer(t, append([]APITestServerOption{func(hs *HTTPServer) {
+		hs.Cfg = cfg
+		hs.orgService = &orgtest.FakeOrgService{
+			ExpectedOrgUsers: []*org.OrgUserDTO{
+				{OrgID: 1, UserID: 1, Login: "alice", Email: "alice@grafana.com", Name: "Alice"},
+				{OrgID: 1, UserID: 2, Login: "bob", Email: "bob@grafana.com", Name: "Bob", IsDisabled: true},
+			},
+			ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1}},
+		}
+		hs.authInfoService = &authinfotest.FakeService{ExpectedError: user.ErrUserNotFound}
+	}}, opts...)...)
+}
+
+func scimServiceAccount(login string, role org.RoleType) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 10, OrgID: 1, Login: login, OrgRole: role, IsServiceAccount: true}
+}
+
+func TestSCIM_Auth(t *testing.T) {
+	tests := []struct {
+		desc         string
+		user         *user.SignedInUser
+		expectedCode int
+	}{
+		{desc: "should allow the configured service account", user: scimServiceAccount("sa-scim", org.RoleAdmin), expectedCode: http.StatusOK},
+		{desc: "should reject other service accounts", user: scimServiceAccount("sa-other", org.RoleAdmin), expectedCode: http.StatusForbidden},
+		{desc: "should reject the service account without the Admin role", user: scimServiceAccount("sa-scim", org.RoleEditor), expectedCode: http.StatusForbidden},
+		{desc: "should reject users", user: &user.SignedInUser{UserID: 1, OrgID: 1, Login: "sa-scim", OrgRole: org.RoleAdmin}, expectedCode: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupSCIMTestServer(t)
+			res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/scim/v2/Users"), tt.user))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.Equal(t, scim.ContentType, res.Header.Get("Content-Type"))
+			require.NoError(t, res.Body.Close())
+		})
+	}
+
+	t.Run("should not register the routes when SCIM is disabled", func(t *testing.T) {
+		server := SetupAPITestServer(t)
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/scim/v2/Users"), scimServiceAccount("sa-scim", org.RoleAdmin)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+func TestSCIM_ListUsers(t *testing.T) {
+	tests := []struct {
+		desc          string
+		query         string
+		expectedCode  int
+		expectedUsers []string
+		expectedTotal int
+	}{
+		{desc: "should list all users", expectedCode: http.StatusOK, expectedUsers: []string{"alice", "bob"}, expectedTotal: 2},
+		{desc: "should filter users", query: `filter=userName eq "Alice"`, expectedCode: http.StatusOK, expectedUsers: []string{"alice"}, expectedTotal: 1},
+		{desc: "should filter inactive users", query: `filter=active eq false`, expectedCode: http.StatusOK, expectedUsers: []string{"bob"}, expectedTotal: 1},
+		{desc: "should page users", query: "startIndex=2&count=1", expectedCode: http.StatusOK, expectedUsers: []string{"bob"}, expectedTotal: 2},
+		{desc: "should reject invalid filters", query: `filter=userName is "alice"`, expectedCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupSCIMTestServer(t)
+			target := "/api/scim/v2/Users?" + strings.ReplaceAll(strings.ReplaceAll(tt.query, " ", "%20"), `"`, "%22")
+			res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest(target), scimServiceAccount("sa-scim", org.RoleAdmin)))
+			require.NoError(t, err)
+			defer func() { require.NoError(t, res.Body.Close()) }()
+			require.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode != http.StatusOK {
+				var scimErr scim.Error
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&scimErr))
+				assert.Equal(t, scim.ErrorTypeInvalidFilter, scimErr.ScimType)
+				assert.Equal(t, "400", scimErr.Status)
+				return
+			}
+
+			var list struct {
+				TotalResults int         `json:"totalResults"`
+				Resources    []scim.User `json:"Resources"`
+			}
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&list))
+			assert.Equal(t, tt.expectedTotal, list.TotalResults)
+			logins := make([]string, 0, len(list.Resources))
+			for _, u := range list.Resources {
+				logins = append(logins, u.UserName)
+			}
+			assert.Equal(t, tt.expectedUsers, logins)
+		})
+	}
+}
+
+func TestSCIM_GetUser(t *testing.T) {
+	t.Run("should not return users of other organizations", func(t *testing.T) {
+		server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+			hs.userService = &usertest.FakeUserService{ExpectedUser: &user.User{ID: 3, Login: "carol"}}
+			hs.orgService = &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 2}}}
+		})
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/scim/v2/Users/3"), scimServiceAccount("sa-scim", org.RoleAdmin)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should return the user", func(t *testing.T) {
+		server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+			hs.userService = &usertest.FakeUserService{ExpectedUser: &user.User{ID: 1, Login: "alice", Email: "alice@grafana.com", Name: "Alice"}}
+			hs.authInfoService = &authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{AuthModule: scim.AuthModule, AuthId: "00u1"}}
+		})
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/scim/v2/Users/1"), scimServiceAccount("sa-scim", org.RoleAdmin)))
+		require.NoError(t, err)
+		defer func() { require.NoError(t, res.Body.Close()) }()
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var u scim.User
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&u))
+		assert.Equal(t, "1", u.ID)
+		assert.Equal(t, "00u1", u.ExternalID)
+		assert.Equal(t, "alice@grafana.com", u.PrimaryEmail())
+		assert.True(t, u.IsActive())
+	})
+}
+
+func TestSCIM_CreateUser(t *testing.T) {
+	body := `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "userName": "carol", "externalId": "00u3", "emails": [{"value": "carol@grafana.com", "primary": true}]}`
+
+	t.Run("should create the user and mark it as provisioned", func(t *testing.T) {
+		var authInfo *login.SetAuthInfoCommand
+		server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+			hs.userService = &usertest.FakeUserService{
+				ExpectedUser: &user.User{ID: 3, Login: "carol", Email: "carol@grafana.com"},
+				CreateFn: func(_ context.Context, cmd *user.CreateUserCommand) (*user.User, error) {
+					assert.Equal(t, "carol", cmd.Login)
+					assert.Equal(t, int64(1), cmd.OrgID)
+					return &user.User{ID: 3, Login: cmd.Login, Email: cmd.Email}, nil
+				},
+			}
+			hs.authInfoService = &authinfotest.FakeService{
+				ExpectedUserAuth: &login.UserAuth{AuthModule: scim.AuthModule, AuthId: "00u3"},
+				SetAuthInfoFn: func(_ context.Context, cmd *login.SetAuthInfoCommand) error {
+					authInfo = cmd
+					return nil
+				},
+			}
+		})
+		res := sendSCIM(t, server, http.MethodPost, "/api/scim/v2/Users", body)
+		require.Equal(t, http.StatusCreated, res.StatusCode)
+		require.NotNil(t, authInfo)
+		assert.Equal(t, &login.SetAuthInfoCommand{AuthModule: scim.AuthModule, AuthId: "00u3", UserId: 3}, authInfo)
+	})
+
+	t.Run("should not take over existing users", func(t *testing.T) {
+		server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+			hs.userService = &usertest.FakeUserService{
+				CreateFn: func(_ context.Context, _ *user.CreateUserCommand) (*user.User, error) {
+					return nil, user.ErrUserAlreadyExists
+				},
+				UpdateFn: func(_ context.Context, _ *user.UpdateUserCommand) error {
+					t.Error("existing users should not be updated")
+					return nil
+				},
+			}
+		})
+		res := sendSCIM(t, server, http.MethodPost, "/api/scim/v2/Users", body)
+		assert.Equal(t, http.StatusConflict, res.StatusCode)
+	})
+}
+
+func TestSCIM_UpdateUser(t *testing.T) {
+	provisioned := &authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{AuthModule: scim.AuthModule, AuthId: "00u1"}}
+	notProvisioned := &authinfotest.FakeService{ExpectedError: user.ErrUserNotFound}
+	requests := map[string]string{
+		http.MethodPut:   `{"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"], "userName": "alice", "externalId": "00u1", "active": false}`,
+		http.MethodPatch: `{"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"], "Operations": [{"op": "replace", "path": "active", "value": false}]}`,
+	}
+
+	tests := []struct {
+		desc         string
+		user         *user.User
+		authInfo     login.AuthInfoService
+		orgs         []*org.UserOrgDTO
+		expectedCode int
+	}{
+		{
+			desc:         "should update provisioned users",
+			user:         &user.User{ID: 1, Login: "alice"},
+			authInfo:     provisioned,
+			orgs:         []*org.UserOrgDTO{{OrgID: 1}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should not update users that weren't provisioned",
+			user:         &user.User{ID: 1, Login: "alice"},
+			authInfo:     notProvisioned,
+			orgs:         []*org.UserOrgDTO{{OrgID: 1}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not update server admins",
+			user:         &user.User{ID: 1, Login: "alice", IsAdmin: true},
+			authInfo:     provisioned,
+			orgs:         []*org.UserOrgDTO{{OrgID: 1}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not update members of other organizations",
+			user:         &user.User{ID: 1, Login: "alice"},
+			authInfo:     provisioned,
+			orgs:         []*org.UserOrgDTO{{OrgID: 1}, {OrgID: 2}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should not find users of other organizations",
+			user:         &user.User{ID: 1, Login: "alice"},
+			authInfo:     provisioned,
+			orgs:         []*org.UserOrgDTO{{OrgID: 2}},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		for method, body := range requests {
+			t.Run(fmt.Sprintf("%s with %s", tt.desc, method), func(t *testing.T) {
+				updated := false
+				server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+					hs.userService = &usertest.FakeUserService{
+						ExpectedUser: tt.user,
+						UpdateFn: func(_ context.Context, cmd *user.UpdateUserCommand) error {
+							updated = true
+							assert.True(t, *cmd.IsDisabled)
+							return nil
+						},
+					}
+					hs.authInfoService = tt.authInfo
+					hs.orgService = &orgtest.FakeOrgService{ExpectedUserOrgDTO: tt.orgs}
+					hs.AuthTokenService = authtest.NewFakeUserAuthTokenService()
+				})
+				res := sendSCIM(t, server, method, "/api/scim/v2/Users/1", body)
+				assert.Equal(t, tt.expectedCode, res.StatusCode)
+				assert.Equal(t, tt.expectedCode == http.StatusOK, updated)
+			})
+		}
+	}
+}
+
+func TestSCIM_DeleteUser(t *testing.T) {
+	tests := []struct {
+		desc         string
+		user         *user.User
+		orgs         []*org.UserOrgDTO
+		expectedCode int
+	}{
+		{desc: "should delete provisioned users", user: &user.User{ID: 1}, orgs: []*org.UserOrgDTO{{OrgID: 1}}, expectedCode: http.StatusNoContent},
+		{desc: "should not delete server admins", user: &user.User{ID: 1, IsAdmin: true}, orgs: []*org.UserOrgDTO{{OrgID: 1}}, expectedCode: http.StatusForbidden},
+		{desc: "should not delete members of other organizations", user: &user.User{ID: 1}, orgs: []*org.UserOrgDTO{{OrgID: 1}, {OrgID: 2}}, expectedCode: http.StatusForbidden},
+		{desc: "should not find users of other organizations", user: &user.User{ID: 1}, orgs: []*org.UserOrgDTO{{OrgID: 2}}, expectedCode: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			orgService := &recordingSCIMOrgService{FakeOrgService: orgtest.FakeOrgService{ExpectedUserOrgDTO: tt.orgs}}
+			server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+				hs.userService = &usertest.FakeUserService{ExpectedUser: tt.user}
+				hs.authInfoService = &authinfotest.FakeService{ExpectedUserAuth: &login.UserAuth{AuthModule: scim.AuthModule}}
+				hs.orgService = orgService
+				hs.accesscontrolService = &actest.FakeService{}
+			})
+			res := sendSCIM(t, server, http.MethodDelete, "/api/scim/v2/Users/1", "")
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.Equal(t, tt.expectedCode == http.StatusNoContent, orgService.removed)
+		})
+	}
+}
+
+func TestSCIM_PatchGroup(t *testing.T) {
+	permissions := &recordingTeamPermissionsService{}
+	server := setupSCIMTestServer(t, func(hs *HTTPServer) {
+		hs.teamService = &teamtest.FakeService{
+			ExpectedTeamDTO: &team.TeamDTO{ID: 5, OrgID: 1, Name: "devs"},
+			ExpectedMembers: []*team.TeamMemberDTO{{OrgID: 1, TeamID: 5, UserID: 1, Login: "alice"}},
+		}
+		hs.teamPermissionsService = permissions
+	})
+
+	body := `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "remove", "path": "members[value eq \"1\"]"},
+			{"op": "add", "path": "members", "value": [{"value": "2"}]}
+		]
+	}`
+	req := server.NewRequest(http.MethodPatch, "/api/scim/v2/Groups/5", strings.NewReader(body))
+	req.Header.Set("Content-Type", scim.ContentType)
+	res, err := server.Send(webtest.RequestWithSignedInUser(req, scimServiceAccount("sa-scim", org.RoleAdmin)))
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	assert.Equal(t, []string{"1:", "2:" + team.MemberPermissionName}, permissions.calls)
+}
+
+type recordingTeamPermissionsService struct {
+	actest.FakePermissionsService
+	calls []string
+}
+
+func (s *recordingTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, u accesscontrol.User, _, permission string) (*accesscontrol.ResourcePermission, error) {
+	s.calls = append(s.calls, fmt.Sprintf("%d:%s", u.ID, permission))
+	return nil, nil
+}
+
+// recordingSCIMOrgService records the removal of users from the organization.
+type recordingSCIMOrgService struct {
+	orgtest.FakeOrgService
+	removed bool
+}
+
+func (s *recordingSCIMOrgService) RemoveOrgUser(_ context.Context, _ *org.RemoveOrgUserCommand) error {
+	s.removed = true
+	return nil
+}
+
+func sendSCIM(t *testing.T, server *webtest.Server, method, target, body string) *http.Response {
+	t.Helper()
+	req := server.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", scim.ContentType)
+	res, err := server.Send(webtest.RequestWithSignedInUser(req, scimServiceAccount("sa-scim", org.RoleAdmin)))
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	return res
+}
@@ -23,6 +23,7 @@ const (
 	DiffJSON DiffType = iota
 	DiffBasic
 	DiffDelta
+	DiffSemantic
 )
 
 type Options struct {
@@ -50,6 +51,8 @@ func ParseDiffType(diff string) DiffType {
 		return DiffBasic
 	case "delta":
 		return DiffDelta
+	case "semantic":
+		return DiffSemantic
 	}
 	return DiffBasic
 }
@@ -57,6 +60,10 @@ func ParseDiffType(diff string) DiffType {
 // CompareDashboardVersionsCommand computes the JSON diff of two versions,
 // assigning the delta of the diff to the `Delta` field.
 func CalculateDiff(ctx context.Context, options *Options, baseData, newData *simplejson.Json) (*Result, error) {
+	if options.DiffType == DiffSemantic {
+		return calculateSemanticDiff(baseData, newData)
+	}
+
 	left, jsonDiff, err := getDiff(baseData, newData)
 	if err != nil {
 		return nil, err
@@ -94,6 +101,24 @@ func CalculateDiff(ctx context.Context, options *Options, baseData, newData *sim
 	return result, nil
 }
 
+func calculateSemanticDiff(baseData, newData *simplejson.Json) (*Result, error) {
+	semanticDiff, err := CalculateSemanticDiff(baseData, newData)
+	if err != nil {
+		return nil, err
+	}
+
+	if semanticDiff.Empty() {
+		return nil, ErrNilDiff
+	}
+
+	delta, err := json.Marshal(semanticDiff)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Delta: delta}, nil
+}
+
 // getDiff computes the diff of two dashboard versions.
 func getDiff(baseData, newData *simplejson.Json) (any, diff.Diff, error) {
 	leftBytes, err := baseData.Encode()
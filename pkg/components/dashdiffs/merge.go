@@ -0,0 +1,262 @@
+package dashdiffs
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Conflict is a property changed differently in both versions being merged.
+type Conflict struct {
+	Path     string `json:"path"`
+	Base     any    `json:"base"`
+	Current  any    `json:"current"`
+	Incoming any    `json:"incoming"`
+}
+
+// MergeResult is the result of a three-way merge. The dashboard can only be
+// saved when there are no conflicts.
+type MergeResult struct {
+	Dashboard *simplejson.Json `json:"dashboard"`
+	Conflicts []Conflict       `json:"conflicts"`
+}
+
+// Merge applies the changes made between base and incoming on top of current,
+// where base is the version both current and incoming were made from. Panels
+// are matched by ID and variables and annotations by name. Properties changed
+// in both current and incoming are reported as conflicts and keep the current
+// value. New panels that got the same ID in both versions are renumbered.
+func Merge(baseData, currentData, incomingData *simplejson.Json) (*MergeResult, error) {
+	base, err := normalize(baseData)
+	if err != nil {
+		return nil, err
+	}
+	current, err := normalize(currentData)
+	if err != nil {
+		return nil, err
+	}
+	incoming, err := normalize(incomingData)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &merger{nextPanelID: max(maxPanelID(current["panels"]), maxPanelID(incoming["panels"])) + 1}
+	merged := m.object("", base, current, incoming).(map[string]any)
+
+	// the identity of the dashboard is the one of the current version
+	for _, key := range []string{"id", "uid", "version"} {
+		if value, ok := current[key]; ok {
+			merged[key] = value
+		}
+	}
+
+	return &MergeResult{Dashboard: simplejson.NewFromAny(merged), Conflicts: m.conflicts}, nil
+}
+
+// missing is the value of properties that don't exist in a version
+type missingValue struct{}
+
+var missing = missingValue{}
+
+type merger struct {
+	conflicts   []Conflict
+	nextPanelID int64
+}
+
+func (m *merger) value(path string, base, current, incoming any) any {
+	switch {
+	case reflect.DeepEqual(current, incoming), reflect.DeepEqual(base, incoming):
+		return current
+	case reflect.DeepEqual(base, current):
+		return incoming
+	}
+
+	// both versions changed the value, merge objects and lists of panels and named items
+	currentObj, currentIsObj := current.(map[string]any)
+	incomingObj, incomingIsObj := incoming.(map[string]any)
+	if currentIsObj && incomingIsObj {
+		baseObj, _ := base.(map[string]any)
+		return m.object(path, baseObj, currentObj, incomingObj)
+	}
+
+	currentList, currentIsList := current.([]any)
+	incomingList, incomingIsList := incoming.([]any)
+	if keyField := listKeyField(path); keyField != "" && currentIsList && incomingIsList {
+		baseList, _ := base.([]any)
+		if merged, ok := m.list(path, keyField, baseList, currentList, incomingList); ok {
+			return merged
+		}
+	}
+
+	m.conflict(path, base, current, incoming)
+	return current
+}
+
+func (m *merger) object(path string, base, current, incoming map[string]any) any {
+	merged := map[string]any{}
+	keys := map[string]bool{}
+	for _, obj := range []map[string]any{base, current, incoming} {
+		for key := range obj {
+			keys[key] = true
+		}
+	}
+
+	for key := range keys {
+		if value := m.value(joinPath(path, key), lookup(base, key), lookup(current, key), lookup(incoming, key)); value != missing {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// list merges lists of objects identified by keyField. It returns false when
+// an item has no identity, so the list can't be merged by item.
+func (m *merger) list(path, keyField string, base, current, incoming []any) ([]any, bool) {
+	baseItems, _, ok := indexBy(base, keyField)
+	if !ok {
+		return nil, false
+	}
+	currentItems, currentOrder, ok := indexBy(current, keyField)
+	if !ok {
+		return nil, false
+	}
+	incomingItems, incomingOrder, ok := indexBy(incoming, keyField)
+	if !ok {
+		return nil, false
+	}
+
+	merged := make([]any, 0, len(current))
+	var added []any
+	for _, key := range currentOrder {
+		baseItem, inBase := baseItems[key]
+		incomingItem, inIncoming := incomingItems[key]
+		switch {
+		case !inBase && inIncoming && !reflect.DeepEqual(currentItems[key], incomingItem) && keyField == "id":
+			// both versions added a different panel with the same ID
+			merged = append(merged, currentItems[key])
+			added = append(added, m.renumber(incomingItem))
+			continue
+		case !inBase:
+			baseItem = missing
+		}
+		if !inIncoming {
+			incomingItem = missing
+		}
+
+		if value := m.value(itemPath(path, keyField, key), baseItem, currentItems[key], incomingItem); value != missing {
+			merged = append(merged, value)
+		}
+	}
+
+	for _, key := range incomingOrder {
+		if _, inCurrent := currentItems[key]; inCurrent {
+			continue
+		}
+		baseItem, inBase := baseItems[key]
+		if !inBase {
+			added = append(added, incomingItems[key])
+			continue
+		}
+		// removed in the current version, keep it removed unless the incoming version changed it
+		if value := m.value(itemPath(path, keyField, key), baseItem, missing, incomingItems[key]); value != missing {
+			merged = append(merged, value)
+		}
+	}
+
+	return append(merged, added...), true
+}
+
+func (m *merger) renumber(item any) any {
+	panel, ok := item.(map[string]any)
+	if !ok {
+		return item
+	}
+	renumbered := make(map[string]any, len(panel))
+	for key, value := range panel {
+		renumbered[key] = value
+	}
+	renumbered["id"] = m.nextPanelID
+	m.nextPanelID++
+	return renumbered
+}
+
+func (m *merger) conflict(path string, base, current, incoming any) {
+	m.conflicts = append(m.conflicts, Conflict{
+		Path:     path,
+		Base:     valueOrNil(base),
+		Current:  valueOrNil(current),
+		Incoming: valueOrNil(incoming),
+	})
+}
+
+// listKeyField returns the property identifying the items of a list that is merged by item.
+func listKeyField(path string) string {
+	switch {
+	case path == "templating.list", path == "annotations.list":
+		return "name"
+	case path == "panels", len(path) > len(".panels") && path[len(path)-len(".panels"):] == ".panels":
+		return "id"
+	}
+	return ""
+}
+
+func indexBy(items []any, keyField string) (map[string]any, []string, bool) {
+	index := make(map[string]any, len(items))
+	order := make([]string, 0, len(items))
+	for _, item := range items {
+		obj, ok := item.(map[string]any)
+		if !ok || obj[keyField] == nil {
+			return nil, nil, false
+		}
+		key := fmt.Sprint(obj[keyField])
+		if _, exists := index[key]; exists {
+			return nil, nil, false
+		}
+		index[key] = item
+		order = append(order, key)
+	}
+	return index, order, true
+}
+
+func maxPanelID(panels any) int64 {
+	var maxID int64
+	items, _ := panels.([]any)
+	for _, item := range items {
+		panel, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		maxID = max(maxID, toInt64(panel["id"]), maxPanelID(panel["panels"]))
+	}
+	return maxID
+}
+
+func lookup(obj map[string]any, key string) any {
+	if value, ok := obj[key]; ok {
+		return value
+	}
+	return missing
+}
+
+func valueOrNil(value any) any {
+	if value == missing {
+		return nil
+	}
+	return value
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func itemPath(path, keyField, key string) string {
+	if keyField == "name" {
+		key = strconv.Quote(key)
+	}
+	return fmt.Sprintf("%s[%s=%s]", path, keyField, key)
+}
@@ -0,0 +1,154 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	base := mustJSON(t, `{
+		"id": 1,
+		"uid": "dash",
+		"version": 3,
+		"title": "Dashboard",
+		"refresh": "1m",
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries"},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"}
+		],
+		"templating": {"list": [{"name": "host", "query": "hosts"}]}
+	}`)
+
+	t.Run("Merges changes to different panels, variables and properties", func(t *testing.T) {
+		current := mustJSON(t, `{
+			"id": 1,
+			"uid": "dash",
+			"version": 4,
+			"title": "Renamed",
+			"refresh": "1m",
+			"panels": [
+				{"id": 2, "title": "Memory", "type": "timeseries"},
+				{"id": 1, "title": "CPU usage", "type": "timeseries"},
+				{"id": 3, "title": "Disk", "type": "stat"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}, {"name": "env", "query": "envs"}]}
+		}`)
+		incoming := mustJSON(t, `{
+			"id": 1,
+			"uid": "dash",
+			"version": 3,
+			"title": "Dashboard",
+			"refresh": "5m",
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries"},
+				{"id": 2, "title": "Memory", "type": "gauge"},
+				{"id": 4, "title": "Logs", "type": "logs"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts", "multi": true}]}
+		}`)
+
+		result, err := Merge(base, current, incoming)
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+
+		assertJSON(t, `{
+			"id": 1,
+			"uid": "dash",
+			"version": 4,
+			"title": "Renamed",
+			"refresh": "5m",
+			"panels": [
+				{"id": 2, "title": "Memory", "type": "gauge"},
+				{"id": 1, "title": "CPU usage", "type": "timeseries"},
+				{"id": 4, "title": "Logs", "type": "logs"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts", "multi": true}, {"name": "env", "query": "envs"}]}
+		}`, result)
+	})
+
+	t.Run("Renumbers panels added with the same ID in both versions", func(t *testing.T) {
+		current := mustJSON(t, `{"id": 1, "version": 4, "panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries"},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"},
+			{"id": 4, "title": "Logs", "type": "logs"}
+		]}`)
+		incoming := mustJSON(t, `{"id": 1, "version": 3, "panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries"},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"},
+			{"id": 4, "title": "Traces", "type": "traces"}
+		]}`)
+		base := mustJSON(t, `{"id": 1, "version": 3, "panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries"},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"}
+		]}`)
+
+		result, err := Merge(base, current, incoming)
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+
+		assertJSON(t, `{"id": 1, "version": 4, "panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries"},
+			{"id": 2, "title": "Memory", "type": "timeseries"},
+			{"id": 3, "title": "Disk", "type": "stat"},
+			{"id": 4, "title": "Logs", "type": "logs"},
+			{"id": 5, "title": "Traces", "type": "traces"}
+		]}`, result)
+	})
+
+	t.Run("Reports conflicting changes and keeps the current values", func(t *testing.T) {
+		current := mustJSON(t, `{
+			"id": 1,
+			"uid": "dash",
+			"version": 4,
+			"title": "Current",
+			"refresh": "1m",
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries"},
+				{"id": 2, "title": "Memory", "type": "timeseries"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}]}
+		}`)
+		incoming := mustJSON(t, `{
+			"id": 1,
+			"uid": "dash",
+			"version": 3,
+			"title": "Incoming",
+			"refresh": "1m",
+			"panels": [
+				{"id": 1, "title": "CPU", "type": "timeseries"},
+				{"id": 2, "title": "Memory", "type": "timeseries"},
+				{"id": 3, "title": "Disk usage", "type": "stat"}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts"}]}
+		}`)
+
+		result, err := Merge(base, current, incoming)
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []Conflict{
+			{Path: "title", Base: "Dashboard", Current: "Current", Incoming: "Incoming"},
+			{
+				Path:     "panels[id=3]",
+				Base:     map[string]any{"id": json.Number("3"), "title": "Disk", "type": "stat"},
+				Current:  nil,
+				Incoming: map[string]any{"id": json.Number("3"), "title": "Disk usage", "type": "stat"},
+			},
+		}, result.Conflicts)
+		assert.Equal(t, "Current", result.Dashboard.Get("title").MustString())
+		assert.Len(t, result.Dashboard.Get("panels").MustArray(), 2)
+	})
+}
+
+func assertJSON(t *testing.T, expected string, result *MergeResult) {
+	t.Helper()
+	actual, err := result.Dashboard.Encode()
+	require.NoError(t, err)
+	assert.JSONEq(t, expected, string(actual))
+}
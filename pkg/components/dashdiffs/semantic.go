@@ -0,0 +1,270 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type SemanticChangeType string
+
+const (
+	SemanticAdded   SemanticChangeType = "added"
+	SemanticRemoved SemanticChangeType = "removed"
+	SemanticChanged SemanticChangeType = "changed"
+	// SemanticMoved is used for panels of which only the position or parent row changed
+	SemanticMoved SemanticChangeType = "moved"
+)
+
+// SemanticDiff describes the changes between two dashboard versions in terms
+// of panels, variables and annotations instead of JSON paths. Panels are
+// matched by ID and variables and annotations by name, so reordering them is
+// not reported as a change of every entry.
+type SemanticDiff struct {
+	Panels      []PanelChange `json:"panels"`
+	Variables   []ItemChange  `json:"variables"`
+	Annotations []ItemChange  `json:"annotations"`
+	// Fields are the other dashboard properties that changed, such as the title or time range.
+	Fields []string `json:"fields"`
+}
+
+// PanelChange is a panel that was added, removed, moved or changed.
+type PanelChange struct {
+	ID    int64              `json:"id"`
+	Title string             `json:"title"`
+	Type  SemanticChangeType `json:"type"`
+	// Moved is set when the position or the parent row of the panel changed.
+	Moved bool `json:"moved,omitempty"`
+	// Fields are the panel properties that changed, other than its position.
+	Fields []string `json:"fields,omitempty"`
+}
+
+// ItemChange is a variable or an annotation query that was added, removed or changed.
+type ItemChange struct {
+	Name   string             `json:"name"`
+	Type   SemanticChangeType `json:"type"`
+	Fields []string           `json:"fields,omitempty"`
+}
+
+// Empty reports whether the diff has no changes.
+func (d *SemanticDiff) Empty() bool {
+	return len(d.Panels) == 0 && len(d.Variables) == 0 && len(d.Annotations) == 0 && len(d.Fields) == 0
+}
+
+// dashboard properties that are diffed and merged separately, or change with every save
+var ignoredDashboardFields = map[string]bool{
+	"panels":      true,
+	"templating":  true,
+	"annotations": true,
+	"id":          true,
+	"version":     true,
+}
+
+// CalculateSemanticDiff computes the semantic diff of two dashboard versions.
+func CalculateSemanticDiff(baseData, newData *simplejson.Json) (*SemanticDiff, error) {
+	base, err := normalize(baseData)
+	if err != nil {
+		return nil, err
+	}
+	next, err := normalize(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	basePanels, _ := flattenPanels(base["panels"])
+	nextPanels, nextOrder := flattenPanels(next["panels"])
+
+	result := &SemanticDiff{
+		Panels:      diffPanels(basePanels, nextPanels, nextOrder),
+		Variables:   diffNamedItems(listAt(base, "templating"), listAt(next, "templating")),
+		Annotations: diffNamedItems(listAt(base, "annotations"), listAt(next, "annotations")),
+		Fields:      changedFields(base, next, ignoredDashboardFields),
+	}
+
+	return result, nil
+}
+
+type flatPanel struct {
+	model  map[string]any
+	parent string
+}
+
+// flattenPanels indexes panels by ID, including the panels of collapsed rows.
+func flattenPanels(panels any) (map[string]flatPanel, []string) {
+	index := map[string]flatPanel{}
+	var order []string
+
+	var walk func(list any, parent string)
+	walk = func(list any, parent string) {
+		items, _ := list.([]any)
+		for i, item := range items {
+			model, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			key := panelKey(model, parent, i)
+			index[key] = flatPanel{model: model, parent: parent}
+			order = append(order, key)
+			walk(model["panels"], key)
+		}
+	}
+	walk(panels, "")
+
+	return index, order
+}
+
+// panelKey identifies a panel by ID, or by position when it has no ID.
+func panelKey(model map[string]any, parent string, index int) string {
+	if id, ok := model["id"]; ok && id != nil {
+		return fmt.Sprint(id)
+	}
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+func diffPanels(base, next map[string]flatPanel, nextOrder []string) []PanelChange {
+	changes := []PanelChange{}
+	for _, key := range nextOrder {
+		panel := next[key]
+		old, ok := base[key]
+		if !ok {
+			changes = append(changes, newPanelChange(panel.model, SemanticAdded))
+			continue
+		}
+
+		moved := old.parent != panel.parent || !reflect.DeepEqual(old.model["gridPos"], panel.model["gridPos"])
+		fields := changedFields(old.model, panel.model, map[string]bool{"gridPos": true, "panels": true})
+		if !moved && len(fields) == 0 {
+			continue
+		}
+
+		change := newPanelChange(panel.model, SemanticChanged)
+		change.Moved = moved
+		change.Fields = fields
+		if len(fields) == 0 {
+			change.Type = SemanticMoved
+		}
+		changes = append(changes, change)
+	}
+
+	for key, panel := range base {
+		if _, ok := next[key]; !ok {
+			changes = append(changes, newPanelChange(panel.model, SemanticRemoved))
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].ID < changes[j].ID
+	})
+	return changes
+}
+
+func newPanelChange(model map[string]any, changeType SemanticChangeType) PanelChange {
+	title, _ := model["title"].(string)
+	return PanelChange{ID: toInt64(model["id"]), Title: title, Type: changeType}
+}
+
+func diffNamedItems(base, next []any) []ItemChange {
+	baseItems, _ := indexByName(base)
+	nextItems, nextOrder := indexByName(next)
+
+	changes := []ItemChange{}
+	for _, name := range nextOrder {
+		old, ok := baseItems[name]
+		if !ok {
+			changes = append(changes, ItemChange{Name: name, Type: SemanticAdded})
+			continue
+		}
+		if fields := changedFields(old, nextItems[name], nil); len(fields) > 0 {
+			changes = append(changes, ItemChange{Name: name, Type: SemanticChanged, Fields: fields})
+		}
+	}
+
+	_, baseOrder := indexByName(base)
+	for _, name := range baseOrder {
+		if _, ok := nextItems[name]; !ok {
+			changes = append(changes, ItemChange{Name: name, Type: SemanticRemoved})
+		}
+	}
+
+	return changes
+}
+
+func indexByName(items []any) (map[string]map[string]any, []string) {
+	index := map[string]map[string]any{}
+	var order []string
+	for _, item := range items {
+		model, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		name, _ := model["name"].(string)
+		if _, exists := index[name]; exists {
+			continue
+		}
+		index[name] = model
+		order = append(order, name)
+	}
+	return index, order
+}
+
+// changedFields returns the sorted keys of which the values differ.
+func changedFields(base, next map[string]any, ignored map[string]bool) []string {
+	fields := []string{}
+	for key, value := range next {
+		if ignored[key] {
+			continue
+		}
+		if old, ok := base[key]; !ok || !reflect.DeepEqual(old, value) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range base {
+		if _, ok := next[key]; !ok && !ignored[key] {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func listAt(data map[string]any, key string) []any {
+	obj, _ := data[key].(map[string]any)
+	list, _ := obj["list"].([]any)
+	return list
+}
+
+// normalize decodes a dashboard model into plain values, so models read from
+// the database and from requests compare equal.
+func normalize(data *simplejson.Json) (map[string]any, error) {
+	encoded, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := simplejson.NewJson(encoded)
+	if err != nil {
+		return nil, err
+	}
+	model, ok := decoded.Interface().(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("dashdiff: dashboard model is not an object")
+	}
+	return model, nil
+}
+
+func toInt64(value any) int64 {
+	switch v := value.(type) {
+	case json.Number:
+		i, _ := v.Int64()
+		return i
+	case float64:
+		return int64(v)
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,113 @@
+package dashdiffs
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func TestCalculateSemanticDiff(t *testing.T) {
+	base := mustJSON(t, `{
+		"id": 1,
+		"version": 3,
+		"title": "Dashboard",
+		"panels": [
+			{"id": 1, "title": "CPU", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+			{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+			{"id": 3, "title": "Disk", "type": "stat", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}},
+			{"id": 4, "title": "Row", "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 16, "w": 24, "h": 1}, "panels": [
+				{"id": 5, "title": "Network", "type": "timeseries", "gridPos": {"x": 0, "y": 17, "w": 12, "h": 8}}
+			]}
+		],
+		"templating": {"list": [{"name": "host", "query": "hosts"}, {"name": "env", "query": "envs"}]},
+		"annotations": {"list": [{"name": "Deploys", "enable": true}]}
+	}`)
+
+	t.Run("Reordering panels and variables is not a change", func(t *testing.T) {
+		next := mustJSON(t, `{
+			"id": 1,
+			"version": 4,
+			"title": "Dashboard",
+			"panels": [
+				{"id": 4, "title": "Row", "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 16, "w": 24, "h": 1}, "panels": [
+					{"id": 5, "title": "Network", "type": "timeseries", "gridPos": {"x": 0, "y": 17, "w": 12, "h": 8}}
+				]},
+				{"id": 3, "title": "Disk", "type": "stat", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}},
+				{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 12, "y": 0, "w": 12, "h": 8}},
+				{"id": 1, "title": "CPU", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}}
+			],
+			"templating": {"list": [{"name": "env", "query": "envs"}, {"name": "host", "query": "hosts"}]},
+			"annotations": {"list": [{"name": "Deploys", "enable": true}]}
+		}`)
+
+		diff, err := CalculateSemanticDiff(base, next)
+		require.NoError(t, err)
+		assert.True(t, diff.Empty(), "%+v", diff)
+	})
+
+	t.Run("Reports panel, variable, annotation and dashboard changes", func(t *testing.T) {
+		next := mustJSON(t, `{
+			"id": 1,
+			"version": 4,
+			"title": "Renamed",
+			"panels": [
+				{"id": 1, "title": "CPU usage", "type": "timeseries", "gridPos": {"x": 0, "y": 0, "w": 12, "h": 8}},
+				{"id": 2, "title": "Memory", "type": "timeseries", "gridPos": {"x": 0, "y": 8, "w": 12, "h": 8}},
+				{"id": 4, "title": "Row", "type": "row", "collapsed": true, "gridPos": {"x": 0, "y": 16, "w": 24, "h": 1}, "panels": []},
+				{"id": 5, "title": "Network", "type": "timeseries", "gridPos": {"x": 0, "y": 17, "w": 12, "h": 8}},
+				{"id": 6, "title": "Logs", "type": "logs", "gridPos": {"x": 12, "y": 17, "w": 12, "h": 8}}
+			],
+			"templating": {"list": [{"name": "host", "query": "hosts", "multi": true}, {"name": "region", "query": "regions"}]},
+			"annotations": {"list": []}
+		}`)
+
+		diff, err := CalculateSemanticDiff(base, next)
+		require.NoError(t, err)
+
+		assert.Equal(t, []PanelChange{
+			{ID: 1, Title: "CPU usage", Type: SemanticChanged, Fields: []string{"title"}},
+			{ID: 2, Title: "Memory", Type: SemanticMoved, Moved: true, Fields: []string{}},
+			{ID: 3, Title: "Disk", Type: SemanticRemoved},
+			{ID: 5, Title: "Network", Type: SemanticMoved, Moved: true, Fields: []string{}},
+			{ID: 6, Title: "Logs", Type: SemanticAdded},
+		}, diff.Panels)
+		assert.Equal(t, []ItemChange{
+			{Name: "host", Type: SemanticChanged, Fields: []string{"multi"}},
+			{Name: "region", Type: SemanticAdded},
+			{Name: "env", Type: SemanticRemoved},
+		}, diff.Variables)
+		assert.Equal(t, []ItemChange{
+			{Name: "Deploys", Type: SemanticRemoved},
+		}, diff.Annotations)
+		assert.Equal(t, []string{"title"}, diff.Fields)
+	})
+
+	t.Run("CalculateDiff returns the semantic diff as JSON", func(t *testing.T) {
+		next, err := simplejson.NewJson([]byte(`{"id": 1, "version": 4, "title": "Renamed"}`))
+		require.NoError(t, err)
+		prev, err := simplejson.NewJson([]byte(`{"id": 1, "version": 3, "title": "Dashboard"}`))
+		require.NoError(t, err)
+
+		result, err := CalculateDiff(context.Background(), &Options{DiffType: ParseDiffType("semantic")}, prev, next)
+		require.NoError(t, err)
+
+		diff := SemanticDiff{}
+		require.NoError(t, json.Unmarshal(result.Delta, &diff))
+		assert.Equal(t, []string{"title"}, diff.Fields)
+
+		_, err = CalculateDiff(context.Background(), &Options{DiffType: DiffSemantic}, prev, prev)
+		assert.ErrorIs(t, err, ErrNilDiff)
+	})
+}
+
+func mustJSON(t *testing.T, data string) *simplejson.Json {
+	t.Helper()
+	js, err := simplejson.NewJson([]byte(data))
+	require.NoError(t, err)
+	return js
+}
@@ -12,6 +12,18 @@ var (
 		Name:      "prometheus_plugin_backend_request_count",
 		Help:      "The total amount of prometheus backend plugin requests",
 	}, []string{"endpoint", "status", "errorSource"})
+
+	rangeQueryCacheRequestCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "prometheus_plugin_range_query_cache_request_count",
+		Help:      "The total amount of range queries using the range query cache, by result of the cache lookup",
+	}, []string{"result"})
+
+	rangeQueryCacheSavedBytes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "prometheus_plugin_range_query_cache_saved_bytes_total",
+		Help:      "The estimated amount of response bytes not fetched from Prometheus thanks to the range query cache",
+	})
 )
 
 const (
@@ -21,6 +33,10 @@ const (
 	EndpointCallResource = "callResource"
 	EndpointQueryData    = "queryData"
 
+	CacheHit        = "hit"
+	CachePartialHit = "partial_hit"
+	CacheMiss       = "miss"
+
 	PluginSource   = "plugin"
 	ExternalSource = "external"
 	DatabaseSource = "database"
@@ -38,6 +54,14 @@ func UpdateQueryDataMetrics(err error, resp *backend.QueryDataResponse) {
 	pluginRequestCounter.WithLabelValues(EndpointQueryData, status, errorSource).Inc()
 }
 
+// UpdateRangeQueryCacheMetrics counts a lookup of the range query cache and the response bytes it saved.
+func UpdateRangeQueryCacheMetrics(result string, savedBytes int64) {
+	rangeQueryCacheRequestCounter.WithLabelValues(result).Inc()
+	if savedBytes > 0 {
+		rangeQueryCacheSavedBytes.Add(float64(savedBytes))
+	}
+}
+
 func getErrorSource(err error, resp *backend.QueryDataResponse) string {
 	if err != nil {
 		return PluginSource
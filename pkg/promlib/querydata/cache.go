@@ -0,0 +1,273 @@
+package querydata
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+	"github.com/patrickmn/go-cache"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/instrumentation"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	// defaultCacheOverlapWindow is the window before now that is always fetched,
+	// recent samples may still change.
+	defaultCacheOverlapWindow = 10 * time.Minute
+	defaultCacheTTL           = time.Hour
+	defaultCacheMaxEntries    = 1000
+)
+
+// rangeQueryCache caches the results of range queries, so that refreshing a
+// dashboard only fetches the samples that are not cached yet.
+type rangeQueryCache struct {
+	cache         *cache.Cache
+	overlapWindow time.Duration
+	maxEntries    int
+	now           func() time.Time
+}
+
+// rangeCacheEntry holds the frames of a range query, evaluated from start to end.
+type rangeCacheEntry struct {
+	start  time.Time
+	end    time.Time
+	frames data.Frames
+	// bytesPerSample estimates the size of the response of a sample, to report the bytes saved by the cache.
+	bytesPerSample float64
+}
+
+func parseRangeQueryCache(jsonData map[string]any) (*rangeQueryCache, error) {
+	enabled, err := maputil.GetBoolOptional(jsonData, "rangeQueryCache")
+	if err != nil || !enabled {
+		return nil, err
+	}
+
+	overlapWindow, err := parseDurationOptional(jsonData, "rangeQueryCacheOverlapWindow", defaultCacheOverlapWindow)
+	if err != nil {
+		return nil, err
+	}
+	ttl, err := parseDurationOptional(jsonData, "rangeQueryCacheTTL", defaultCacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	maxEntries := defaultCacheMaxEntries
+	// numbers of the JSON data are unmarshalled as float64
+	if entries, ok := jsonData["rangeQueryCacheMaxEntries"].(float64); ok && entries > 0 {
+		maxEntries = int(entries)
+	}
+
+	return &rangeQueryCache{
+		cache:         cache.New(ttl, ttl),
+		overlapWindow: overlapWindow,
+		maxEntries:    maxEntries,
+		now:           time.Now,
+	}, nil
+}
+
+func parseDurationOptional(jsonData map[string]any, key string, defaultValue time.Duration) (time.Duration, error) {
+	value, err := maputil.GetStringOptional(jsonData, key)
+	if err != nil || value == "" {
+		return defaultValue, err
+	}
+	duration, err := gtime.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("invalid %s: %s is negative", key, value)
+	}
+	return duration, nil
+}
+
+// cacheKeyIgnoredHeaders are the headers identifying where a query comes from,
+// which don't change its results. Queries of different panels share results.
+var cacheKeyIgnoredHeaders = map[string]bool{
+	"X-Panel-Id":       true,
+	"X-Dashboard-Uid":  true,
+	"X-Query-Group-Id": true,
+}
+
+// rangeCacheKey returns the key of the results of a range query. The headers of
+// the request are part of the key, results fetched with the credentials of a
+// user are not returned to other users.
+func rangeCacheKey(datasourceID int64, q *models.Query, enablePrometheusDataplaneFlag bool, headers map[string]string) string {
+	h := sha256.New()
+	write := func(s string) {
+		_, _ = io.WriteString(h, s)
+		_, _ = h.Write([]byte{0})
+	}
+	write(strconv.FormatInt(datasourceID, 10))
+	write(q.Expr)
+	write(q.Step.String())
+	write(strconv.FormatInt(q.UtcOffsetSec, 10))
+	write(q.LegendFormat)
+	write(strconv.FormatBool(enablePrometheusDataplaneFlag))
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		// HTTP headers of the request are prefixed with http_
+		if !cacheKeyIgnoredHeaders[http.CanonicalHeaderKey(strings.TrimPrefix(name, "http_"))] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		write(name)
+		write(headers[name])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// query runs a range query, fetching only the samples after the cached results
+// of the same query.
+func (rc *rangeQueryCache) query(ctx context.Context, s *QueryData, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool, headers map[string]string) backend.DataResponse {
+	logger := s.log.FromContext(ctx)
+	key := rangeCacheKey(s.ID, q, enablePrometheusDataplaneFlag, headers)
+	tr := q.TimeRange()
+
+	var entry *rangeCacheEntry
+	if cached, ok := rc.cache.Get(key); ok {
+		entry = cached.(*rangeCacheEntry)
+		// the cached results must start before the query, evaluated at the same timestamps
+		if tr.Start.Before(entry.start) || tr.Start.After(entry.end) || tr.Start.Sub(entry.start)%tr.Step != 0 {
+			entry = nil
+		}
+	}
+
+	if entry == nil {
+		res, size := s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+		instrumentation.UpdateRangeQueryCacheMetrics(instrumentation.CacheMiss, 0)
+		rc.store(key, q, res, size)
+		return res
+	}
+
+	cached := sliceRangeFrames(entry.frames, tr.Start, tr.End)
+	savedBytes := int64(entry.bytesPerSample * float64(countSamples(cached)))
+	if !tr.End.After(entry.end) {
+		logger.Debug("Range query served from cache", "query", q.Expr)
+		instrumentation.UpdateRangeQueryCacheMetrics(instrumentation.CacheHit, savedBytes)
+		return backend.DataResponse{Frames: mergeRangeFrames(q, []data.Frames{cached})}
+	}
+
+	missing := *q
+	missing.Start = entry.end.Add(tr.Step)
+	logger.Debug("Range query partially served from cache", "query", q.Expr, "start", missing.Start, "end", missing.End)
+	res, size := s.fetchRange(ctx, c, &missing, enablePrometheusDataplaneFlag)
+	if res.Error != nil {
+		return res
+	}
+	if !isRangeFrames(res.Frames) {
+		// the cached results can not be merged with frames other than time series
+		rc.cache.Delete(key)
+		res, size = s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+		instrumentation.UpdateRangeQueryCacheMetrics(instrumentation.CacheMiss, 0)
+		rc.store(key, q, res, size)
+		return res
+	}
+
+	instrumentation.UpdateRangeQueryCacheMetrics(instrumentation.CachePartialHit, savedBytes)
+	res = backend.DataResponse{
+		Frames: mergeRangeFrames(q, []data.Frames{cached, res.Frames}),
+		Status: res.Status,
+	}
+	rc.storeMerged(key, q, res.Frames, entry.bytesPerSample)
+	return res
+}
+
+// cacheEnd returns the last evaluation time of a range query that is cached.
+// Samples in the overlap window before now may still change and are not cached.
+func (rc *rangeQueryCache) cacheEnd(q *models.Query) time.Time {
+	tr := q.TimeRange()
+	end := rc.now().Add(-rc.overlapWindow)
+	if end.After(tr.End) {
+		return tr.End
+	}
+	return models.AlignTimeRange(end, tr.Step, q.UtcOffsetSec)
+}
+
+// store caches the response of a range query fetched without cached results.
+func (rc *rangeQueryCache) store(key string, q *models.Query, res backend.DataResponse, size int64) {
+	if res.Error != nil || res.Status >= 400 || !isRangeFrames(res.Frames) {
+		return
+	}
+	bytesPerSample := float64(0)
+	if samples := countSamples(res.Frames); samples > 0 {
+		bytesPerSample = float64(size) / float64(samples)
+	}
+	rc.storeMerged(key, q, res.Frames, bytesPerSample)
+}
+
+func (rc *rangeQueryCache) storeMerged(key string, q *models.Query, frames data.Frames, bytesPerSample float64) {
+	tr := q.TimeRange()
+	end := rc.cacheEnd(q)
+	if end.Before(tr.Start) {
+		return
+	}
+	if _, exists := rc.cache.Get(key); !exists && rc.cache.ItemCount() >= rc.maxEntries {
+		return
+	}
+	rc.cache.SetDefault(key, &rangeCacheEntry{
+		start:          tr.Start,
+		end:            end,
+		frames:         sliceRangeFrames(frames, tr.Start, end),
+		bytesPerSample: bytesPerSample,
+	})
+}
+
+// sliceRangeFrames returns copies of the frames with the samples from start to
+// end. Frames without samples in the time range are dropped.
+func sliceRangeFrames(frames data.Frames, start, end time.Time) data.Frames {
+	sliced := make(data.Frames, 0, len(frames))
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			sliced = append(sliced, copyFrame(frame, 0, 0))
+			continue
+		}
+		times := frame.Fields[0]
+		from := sort.Search(times.Len(), func(i int) bool {
+			return !times.At(i).(time.Time).Before(start)
+		})
+		to := sort.Search(times.Len(), func(i int) bool {
+			return times.At(i).(time.Time).After(end)
+		})
+		if from < to {
+			sliced = append(sliced, copyFrame(frame, from, to))
+		}
+	}
+	return sliced
+}
+
+func countSamples(frames data.Frames) int {
+	samples := 0
+	for _, frame := range frames {
+		if len(frame.Fields) > 0 {
+			samples += frame.Fields[0].Len()
+		}
+	}
+	return samples
+}
+
+// countingReader counts the bytes read from a response body.
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.n += int64(n)
+	return n, err
+}
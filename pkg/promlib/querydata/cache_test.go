@@ -0,0 +1,171 @@
+package querydata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+type rangeRequest struct {
+	start, end time.Time
+}
+
+// fakeRangeServer answers range queries with a series whose value is the evaluation time in seconds.
+type fakeRangeServer struct {
+	mu       sync.Mutex
+	requests []rangeRequest
+}
+
+func parseSeconds(t *testing.T, value string) time.Time {
+	seconds, err := strconv.ParseFloat(value, 64)
+	require.NoError(t, err)
+	return time.UnixMilli(int64(seconds * 1000)).UTC()
+}
+
+func (s *fakeRangeServer) start(t *testing.T) *httptest.Server {
+	ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		start := parseSeconds(t, r.Form.Get("start"))
+		end := parseSeconds(t, r.Form.Get("end"))
+		stepSeconds, err := strconv.ParseFloat(r.Form.Get("step"), 64)
+		require.NoError(t, err)
+		step := time.Duration(stepSeconds * float64(time.Second))
+
+		s.mu.Lock()
+		s.requests = append(s.requests, rangeRequest{start: start, end: end})
+		s.mu.Unlock()
+
+		values := make([]string, 0)
+		for ts := start; !ts.After(end); ts = ts.Add(step) {
+			values = append(values, fmt.Sprintf(`[%d,"%d"]`, ts.Unix(), ts.Unix()))
+		}
+		_, err = fmt.Fprintf(rw, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"a"},"values":[%s]}]}}`, strings.Join(values, ","))
+		require.NoError(t, err)
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func (s *fakeRangeServer) takeRequests() []rangeRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	requests := s.requests
+	s.requests = nil
+	return requests
+}
+
+func newTestQueryData(t *testing.T, url string, jsonData string) *QueryData {
+	settings := backend.DataSourceInstanceSettings{
+		ID:       1,
+		URL:      url,
+		JSONData: json.RawMessage(jsonData),
+	}
+	qd, err := New(http.DefaultClient, settings, log.New())
+	require.NoError(t, err)
+	return qd
+}
+
+func runRangeQuery(t *testing.T, qd *QueryData, from, to time.Time, headers map[string]string) data.Frames {
+	res, err := qd.Execute(context.Background(), &backend.QueryDataRequest{
+		Headers: headers,
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			JSON:      json.RawMessage(`{"expr": "up", "range": true, "interval": "1m"}`),
+			TimeRange: backend.TimeRange{From: from, To: to},
+			Interval:  time.Minute,
+		}},
+	})
+	require.NoError(t, err)
+	require.NoError(t, res.Responses["A"].Error)
+	return res.Responses["A"].Frames
+}
+
+func sampleTimes(t *testing.T, frames data.Frames) []time.Time {
+	require.Len(t, frames, 1)
+	times := make([]time.Time, frames[0].Fields[0].Len())
+	for i := range times {
+		times[i] = frames[0].Fields[0].At(i).(time.Time)
+	}
+	return times
+}
+
+func TestRangeQueryCache(t *testing.T) {
+	server := &fakeRangeServer{}
+	url := server.start(t).URL
+	now := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+
+	newCachedQueryData := func(t *testing.T) *QueryData {
+		qd := newTestQueryData(t, url, `{"rangeQueryCache": true, "rangeQueryCacheOverlapWindow": "10m"}`)
+		qd.rangeCache.now = func() time.Time { return now }
+		return qd
+	}
+
+	t.Run("only the samples after the cached samples are fetched", func(t *testing.T) {
+		qd := newCachedQueryData(t)
+		runRangeQuery(t, qd, now.Add(-6*time.Hour), now, nil)
+		require.Len(t, server.takeRequests(), 1)
+
+		now = now.Add(5 * time.Minute)
+		frames := runRangeQuery(t, qd, now.Add(-6*time.Hour), now, nil)
+		requests := server.takeRequests()
+		require.Len(t, requests, 1)
+		// samples older than the overlap window of the first query are cached
+		require.Equal(t, now.Add(-15*time.Minute+time.Minute), requests[0].start)
+
+		expected := sampleTimes(t, runRangeQuery(t, newTestQueryData(t, url, `{}`), now.Add(-6*time.Hour), now, nil))
+		server.takeRequests()
+		require.Equal(t, expected, sampleTimes(t, frames))
+		require.Equal(t, "Expr: up\nStep: 1m0s", frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("queries over cached samples are not fetched", func(t *testing.T) {
+		qd := newCachedQueryData(t)
+		expected := sampleTimes(t, runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), nil))
+		require.Len(t, server.takeRequests(), 1)
+
+		frames := runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), nil)
+		require.Empty(t, server.takeRequests())
+		require.Equal(t, expected, sampleTimes(t, frames))
+	})
+
+	t.Run("results are not shared across request headers", func(t *testing.T) {
+		qd := newCachedQueryData(t)
+		runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), map[string]string{"Authorization": "Bearer a"})
+		require.Len(t, server.takeRequests(), 1)
+
+		runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), map[string]string{"Authorization": "Bearer b"})
+		require.Len(t, server.takeRequests(), 1)
+	})
+
+	t.Run("results are shared across panels", func(t *testing.T) {
+		qd := newCachedQueryData(t)
+		runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), map[string]string{"http_X-Panel-Id": "1", "http_X-Dashboard-Uid": "a", "http_X-Query-Group-Id": "g1"})
+		require.Len(t, server.takeRequests(), 1)
+
+		runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), map[string]string{"http_X-Panel-Id": "2", "http_X-Dashboard-Uid": "b", "http_X-Query-Group-Id": "g2"})
+		require.Empty(t, server.takeRequests())
+	})
+
+	t.Run("queries starting before the cached samples are fetched", func(t *testing.T) {
+		qd := newCachedQueryData(t)
+		runRangeQuery(t, qd, now.Add(-6*time.Hour), now.Add(-time.Hour), nil)
+		require.Len(t, server.takeRequests(), 1)
+
+		runRangeQuery(t, qd, now.Add(-12*time.Hour), now.Add(-time.Hour), nil)
+		requests := server.takeRequests()
+		require.Len(t, requests, 1)
+		require.Equal(t, now.Add(-12*time.Hour), requests[0].start)
+	})
+}
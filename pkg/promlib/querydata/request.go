@@ -43,6 +43,8 @@ type QueryData struct {
 	URL                string
 	TimeInterval       string
 	exemplarSampler    func() exemplar.Sampler
+	splitting          querySplitting
+	rangeCache         *rangeQueryCache
 }
 
 func New(
@@ -65,6 +67,16 @@ func New(
 		httpMethod = http.MethodPost
 	}
 
+	splitting, err := parseQuerySplitting(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
+	rangeCache, err := parseRangeQueryCache(jsonData)
+	if err != nil {
+		return nil, err
+	}
+
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
 	// standard deviation sampler is the default for backwards compatibility
@@ -79,6 +91,8 @@ func New(
 		ID:                 settings.ID,
 		URL:                settings.URL,
 		exemplarSampler:    exemplarSampler,
+		splitting:          splitting,
+		rangeCache:         rangeCache,
 	}, nil
 }
 
@@ -93,7 +107,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	hasPrometheusDataplaneFeatureFlag := cfg.FeatureToggles().IsEnabled("prometheusDataplane")
 
 	for _, q := range req.Queries {
-		r := s.handleQuery(ctx, q, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag)
+		r := s.handleQuery(ctx, q, req.Headers, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag)
 		if r == nil {
 			continue
 		}
@@ -103,7 +117,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	return &result, nil
 }
 
-func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool) *backend.DataResponse {
+func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, headers map[string]string, fromAlert, hasPromQLScopeFeatureFlag, hasPrometheusDataplaneFeatureFlag bool) *backend.DataResponse {
 	traceCtx, span := s.tracer.Start(ctx, "datasource.prometheus")
 	defer span.End()
 	query, err := models.Parse(span, bq, s.TimeInterval, s.intervalCalculator, fromAlert, hasPromQLScopeFeatureFlag)
@@ -113,14 +127,14 @@ func (s *QueryData) handleQuery(ctx context.Context, bq backend.DataQuery, fromA
 		}
 	}
 
-	r := s.fetch(traceCtx, s.client, query, hasPrometheusDataplaneFeatureFlag)
+	r := s.fetch(traceCtx, s.client, query, headers, hasPrometheusDataplaneFeatureFlag)
 	if r == nil {
 		s.log.FromContext(ctx).Debug("Received nil response from runQuery", "query", query.Expr)
 	}
 	return r
 }
 
-func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *models.Query, enablePrometheusDataplane bool) *backend.DataResponse {
+func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *models.Query, headers map[string]string, enablePrometheusDataplane bool) *backend.DataResponse {
 	logger := s.log.FromContext(traceCtx)
 	logger.Debug("Sending query", "start", q.Start, "end", q.End, "step", q.Step, "query", q.Expr)
 
@@ -137,7 +151,7 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 	}
 
 	if q.RangeQuery {
-		res := s.rangeQuery(traceCtx, client, q, enablePrometheusDataplane)
+		res := s.rangeQuery(traceCtx, client, q, headers, enablePrometheusDataplane)
 		if res.Error != nil {
 			if dr.Error == nil {
 				dr.Error = res.Error
@@ -164,15 +178,26 @@ func (s *QueryData) fetch(traceCtx context.Context, client *client.Client, q *mo
 	return dr
 }
 
-func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string, enablePrometheusDataplaneFlag bool) backend.DataResponse {
+	if s.rangeCache != nil {
+		return s.rangeCache.query(ctx, s, c, q, enablePrometheusDataplaneFlag, headers)
+	}
+	res, _ := s.fetchRange(ctx, c, q, enablePrometheusDataplaneFlag)
+	return res
+}
+
+// rangeQueryRequest runs a range query in a single request, it returns the size of the response body.
+func (s *QueryData) rangeQueryRequest(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) (backend.DataResponse, int64) {
 	res, err := c.QueryRange(ctx, q)
 	if err != nil {
 		return backend.DataResponse{
 			Error:  err,
 			Status: backend.StatusBadGateway,
-		}
+		}, 0
 	}
 
+	body := &countingReader{ReadCloser: res.Body}
+	res.Body = body
 	defer func() {
 		err := res.Body.Close()
 		if err != nil {
@@ -180,7 +205,7 @@ func (s *QueryData) rangeQuery(ctx context.Context, c *client.Client, q *models.
 		}
 	}()
 
-	return s.parseResponse(ctx, q, res, enablePrometheusDataplaneFlag)
+	return s.parseResponse(ctx, q, res, enablePrometheusDataplaneFlag), body.n
 }
 
 func (s *QueryData) instantQuery(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) backend.DataResponse {
@@ -0,0 +1,259 @@
+package querydata
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/utils/maputil"
+
+	"github.com/grafana/grafana/pkg/promlib/client"
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+const (
+	// defaultSplitConcurrency is the number of sub-queries of a split range
+	// query that are running at the same time, when not configured.
+	defaultSplitConcurrency = 4
+	// minSplitDuration is the shortest time range of a sub-query, shorter
+	// split durations are raised to it.
+	minSplitDuration = time.Minute
+	// maxSplitQueries is the maximum number of sub-queries of a split range query.
+	maxSplitQueries = 1000
+)
+
+// querySplitting configures how range queries over a long time range are split
+// into sub-queries.
+type querySplitting struct {
+	// Duration is the time range of a sub-query, sub-queries are aligned to
+	// multiples of it in the timezone of the query. Range queries are not
+	// split when zero.
+	Duration time.Duration
+	// Concurrency is the maximum number of sub-queries running at the same time.
+	Concurrency int
+}
+
+func parseQuerySplitting(jsonData map[string]any) (querySplitting, error) {
+	splitting := querySplitting{Concurrency: defaultSplitConcurrency}
+
+	splitDuration, err := maputil.GetStringOptional(jsonData, "rangeQuerySplitDuration")
+	if err != nil {
+		return splitting, err
+	}
+	if splitDuration != "" {
+		splitting.Duration, err = gtime.ParseDuration(splitDuration)
+		if err != nil {
+			return splitting, fmt.Errorf("invalid rangeQuerySplitDuration: %w", err)
+		}
+		if splitting.Duration < 0 {
+			return splitting, fmt.Errorf("invalid rangeQuerySplitDuration: %s is negative", splitDuration)
+		}
+	}
+
+	// numbers of the JSON data are unmarshalled as float64
+	if concurrency, ok := jsonData["rangeQuerySplitConcurrency"].(float64); ok && concurrency > 0 {
+		splitting.Concurrency = int(concurrency)
+	}
+	return splitting, nil
+}
+
+// splitRangeQuery splits a range query into sub-queries over consecutive time
+// ranges, aligned to multiples of splitDuration, for example to days. The split
+// duration is at least minSplitDuration and the step of the query. The
+// sub-queries are evaluated at the same timestamps as the original query: each
+// sub-query ends one step before the first evaluation time of the next one.
+// It fails when the query would be split into more than maxSplitQueries
+// sub-queries.
+func splitRangeQuery(q *models.Query, splitDuration time.Duration) ([]*models.Query, error) {
+	tr := q.TimeRange()
+	if splitDuration <= 0 || tr.Step <= 0 || tr.End.Sub(tr.Start) <= splitDuration {
+		return []*models.Query{q}, nil
+	}
+
+	splitDuration = max(splitDuration, minSplitDuration, tr.Step)
+	// the time range can start and end in the middle of aligned time ranges
+	count := (tr.End.Sub(tr.Start)+splitDuration-1)/splitDuration + 1
+	if count > maxSplitQueries {
+		return nil, fmt.Errorf("query would be split into %d sub-queries, more than the maximum of %d, use a longer split duration", count, maxSplitQueries)
+	}
+
+	queries := make([]*models.Query, 0, count)
+	for start := tr.Start; !start.After(tr.End); {
+		boundary := models.AlignTimeRange(start, splitDuration, q.UtcOffsetSec).Add(splitDuration)
+		steps := (boundary.Sub(start) + tr.Step - 1) / tr.Step
+		next := start.Add(steps * tr.Step)
+
+		subQuery := *q
+		subQuery.Start = start
+		// the end is set half a step after the last evaluation time, aligning it to the step never drops it
+		subQuery.End = next.Add(-tr.Step / 2)
+		if !next.Before(tr.End) {
+			subQuery.End = q.End
+		}
+		queries = append(queries, &subQuery)
+		start = next
+	}
+	return queries, nil
+}
+
+// fetchRange runs a range query, split in sub-queries run by a pool of
+// workers when its time range is longer than the split duration, and merges
+// the responses of the sub-queries.
+func (s *QueryData) fetchRange(ctx context.Context, c *client.Client, q *models.Query, enablePrometheusDataplaneFlag bool) (backend.DataResponse, int64) {
+	queries, err := splitRangeQuery(q, s.splitting.Duration)
+	if err != nil {
+		return backend.DataResponse{Error: err}, 0
+	}
+	if len(queries) == 1 {
+		return s.rangeQueryRequest(ctx, c, q, enablePrometheusDataplaneFlag)
+	}
+
+	concurrency := s.splitting.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultSplitConcurrency
+	}
+
+	responses := make([]backend.DataResponse, len(queries))
+	sizes := make([]int64, len(queries))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(concurrency, len(queries)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				responses[i], sizes[i] = s.rangeQueryRequest(ctx, c, queries[i], enablePrometheusDataplaneFlag)
+			}
+		}()
+	}
+	for i := range queries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var size int64
+	parts := make([]data.Frames, 0, len(responses))
+	for i, res := range responses {
+		if res.Error != nil {
+			return res, size
+		}
+		if !isRangeFrames(res.Frames) {
+			// frames other than time series, for example native histograms, can not be merged
+			s.log.FromContext(ctx).Debug("Running range query without splitting, its frames can not be merged", "query", q.Expr)
+			return s.rangeQueryRequest(ctx, c, q, enablePrometheusDataplaneFlag)
+		}
+		size += sizes[i]
+		parts = append(parts, res.Frames)
+	}
+
+	return backend.DataResponse{
+		Frames: mergeRangeFrames(q, parts),
+		Status: responses[len(responses)-1].Status,
+	}, size
+}
+
+// isRangeFrames returns whether frames are time series frames with a time field
+// and a value field, that can be merged by mergeRangeFrames.
+func isRangeFrames(frames data.Frames) bool {
+	for _, frame := range frames {
+		if len(frame.Fields) == 0 {
+			continue
+		}
+		if len(frame.Fields) != 2 || frame.Fields[0].Type() != data.FieldTypeTime {
+			return false
+		}
+	}
+	return true
+}
+
+func seriesKey(frame *data.Frame) string {
+	return frame.Name + "\x00" + frame.Fields[1].Name + "\x00" + frame.Fields[1].Labels.String()
+}
+
+// mergeRangeFrames merges the frames of consecutive time ranges of a range
+// query, in chronological order, into a frame per series. Samples of a series
+// that are not after its last merged sample are dropped, so time ranges
+// sharing their boundary do not return the same sample twice.
+func mergeRangeFrames(q *models.Query, parts []data.Frames) data.Frames {
+	var merged data.Frames
+	series := make(map[string]*data.Frame)
+	lastTimes := make(map[string]time.Time)
+	var empty *data.Frame
+	for _, frames := range parts {
+		for _, frame := range frames {
+			if len(frame.Fields) == 0 {
+				if empty == nil {
+					empty = copyFrame(frame, 0, 0)
+				}
+				continue
+			}
+
+			key := seriesKey(frame)
+			target, ok := series[key]
+			if !ok {
+				target = copyFrame(frame, 0, 0)
+				series[key] = target
+				merged = append(merged, target)
+			}
+			last, hasLast := lastTimes[key]
+			for i := 0; i < frame.Fields[0].Len(); i++ {
+				t := frame.Fields[0].At(i).(time.Time)
+				if hasLast && !t.After(last) {
+					continue
+				}
+				target.Fields[0].Append(t)
+				target.Fields[1].Append(frame.Fields[1].CopyAt(i))
+				last, hasLast = t, true
+			}
+			lastTimes[key] = last
+		}
+	}
+
+	// Add frame to attach metadata
+	if len(merged) == 0 {
+		if empty == nil {
+			empty = data.NewFrame("")
+		}
+		merged = data.Frames{empty}
+	}
+	// The ExecutedQueryString can be viewed in QueryInspector in UI
+	for i, frame := range merged {
+		if frame.Meta == nil {
+			frame.Meta = &data.FrameMeta{}
+		}
+		frame.Meta.ExecutedQueryString = ""
+		if i == 0 {
+			frame.Meta.ExecutedQueryString = executedQueryString(q)
+		}
+	}
+	return merged
+}
+
+// copyFrame returns a copy of the rows from start to end of a frame.
+func copyFrame(frame *data.Frame, start, end int) *data.Frame {
+	frameCopy := data.NewFrame(frame.Name)
+	frameCopy.RefID = frame.RefID
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		frameCopy.Meta = &meta
+	}
+	for _, field := range frame.Fields {
+		fieldCopy := data.NewFieldFromFieldType(field.Type(), 0)
+		fieldCopy.Name = field.Name
+		fieldCopy.Labels = field.Labels.Copy()
+		if field.Config != nil {
+			config := *field.Config
+			fieldCopy.Config = &config
+		}
+		for i := start; i < end; i++ {
+			fieldCopy.Append(field.CopyAt(i))
+		}
+		frameCopy.Fields = append(frameCopy.Fields, fieldCopy)
+	}
+	return frameCopy
+}
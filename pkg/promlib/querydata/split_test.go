@@ -0,0 +1,86 @@
+package querydata
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/promlib/models"
+)
+
+func TestSplitRangeQuery(t *testing.T) {
+	t.Run("queries shorter than the split duration are not split", func(t *testing.T) {
+		q := &models.Query{Start: time.Unix(0, 0), End: time.Unix(3600, 0), Step: time.Minute}
+		queries, err := splitRangeQuery(q, 24*time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, []*models.Query{q}, queries)
+	})
+
+	t.Run("queries are split at day boundaries and step-aligned", func(t *testing.T) {
+		start := time.Date(2024, 5, 1, 22, 0, 0, 0, time.UTC)
+		q := &models.Query{Start: start, End: start.Add(50 * time.Hour), Step: 7 * time.Minute}
+		queries, err := splitRangeQuery(q, 24*time.Hour)
+		require.NoError(t, err)
+		require.Len(t, queries, 3)
+
+		var evaluations []time.Time
+		for i, subQuery := range queries {
+			tr := subQuery.TimeRange()
+			if i > 0 {
+				// each sub-query starts at the first evaluation time after a midnight
+				previous := queries[i-1].TimeRange()
+				require.Equal(t, previous.End.Add(q.Step), tr.Start)
+				require.True(t, tr.Start.Sub(models.AlignTimeRange(tr.Start, 24*time.Hour, 0)) < q.Step)
+			}
+			for ts := tr.Start; !ts.After(tr.End); ts = ts.Add(q.Step) {
+				evaluations = append(evaluations, ts)
+			}
+		}
+
+		tr := q.TimeRange()
+		var expected []time.Time
+		for ts := tr.Start; !ts.After(tr.End); ts = ts.Add(q.Step) {
+			expected = append(expected, ts)
+		}
+		require.Equal(t, expected, evaluations)
+	})
+
+	t.Run("uses the minimum split duration and the step", func(t *testing.T) {
+		q := &models.Query{Start: time.Unix(0, 0), End: time.Unix(590, 0), Step: time.Second}
+		queries, err := splitRangeQuery(q, time.Millisecond)
+		require.NoError(t, err)
+		require.Equal(t, 10, len(queries))
+
+		q.Step = 2 * time.Minute
+		queries, err = splitRangeQuery(q, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, 5, len(queries))
+	})
+
+	t.Run("fails when split into too many sub-queries", func(t *testing.T) {
+		q := &models.Query{Start: time.Unix(0, 0), End: time.Unix(30*24*3600-60, 0), Step: time.Minute}
+		_, err := splitRangeQuery(q, time.Minute)
+		require.ErrorContains(t, err, "more than the maximum")
+
+		queries, err := splitRangeQuery(q, time.Hour)
+		require.NoError(t, err)
+		require.Equal(t, 720, len(queries))
+	})
+}
+
+func TestRangeQuerySplitting(t *testing.T) {
+	server := &fakeRangeServer{}
+	url := server.start(t).URL
+	to := time.Date(2024, 5, 4, 12, 0, 0, 0, time.UTC)
+	from := to.Add(-72 * time.Hour)
+
+	expected := runRangeQuery(t, newTestQueryData(t, url, `{}`), from, to, nil)
+	require.Len(t, server.takeRequests(), 1)
+
+	qd := newTestQueryData(t, url, `{"rangeQuerySplitDuration": "1d", "rangeQuerySplitConcurrency": 2}`)
+	frames := runRangeQuery(t, qd, from, to, nil)
+	require.Len(t, server.takeRequests(), 4)
+	require.Equal(t, sampleTimes(t, expected), sampleTimes(t, frames))
+	require.Equal(t, expected[0].Meta.ExecutedQueryString, frames[0].Meta.ExecutedQueryString)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/setting"
@@ -243,6 +244,10 @@ func (a *api) setUserPermission(c *contextmodel.ReqContext) response.Response {
 		return response.Err(err)
 	}
 
+	a.service.audit.Log(c.Req.Context(), a.auditEvent(c, resourceID).
+		WithDetail("userId", userID).
+		WithDetail("permission", cmd.Permission))
+
 	return permissionSetResponse(cmd)
 }
 
@@ -296,6 +301,10 @@ func (a *api) setTeamPermission(c *contextmodel.ReqContext) response.Response {
 		return response.Err(err)
 	}
 
+	a.service.audit.Log(c.Req.Context(), a.auditEvent(c, resourceID).
+		WithDetail("teamId", teamID).
+		WithDetail("permission", cmd.Permission))
+
 	return permissionSetResponse(cmd)
 }
 
@@ -346,6 +355,10 @@ func (a *api) setBuiltinRolePermission(c *contextmodel.ReqContext) response.Resp
 		return response.Err(err)
 	}
 
+	a.service.audit.Log(c.Req.Context(), a.auditEvent(c, resourceID).
+		WithDetail("builtInRole", builtInRole).
+		WithDetail("permission", cmd.Permission))
+
 	return permissionSetResponse(cmd)
 }
 
@@ -391,9 +404,17 @@ func (a *api) setPermissions(c *contextmodel.ReqContext) response.Response {
 		return response.Err(err)
 	}
 
+	a.service.audit.Log(c.Req.Context(), a.auditEvent(c, resourceID).WithDetail("permissions", cmd.Permissions))
+
 	return response.Success("Permissions updated")
 }
 
+// auditEvent returns an event recording that the permissions of resourceID were changed.
+func (a *api) auditEvent(c *contextmodel.ReqContext, resourceID string) *audit.Event {
+	return audit.NewEvent(c.Req, c.SignedInUser, audit.ActionPermissionsUpdate).
+		WithResource(a.service.options.Resource, resourceID, "")
+}
+
 func permissionSetResponse(cmd setPermissionCommand) response.Response {
 	message := "Permission updated"
 	if cmd.Permission == "" {
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/licensing"
 	"github.com/grafana/grafana/pkg/services/org"
@@ -60,7 +61,7 @@ type Store interface {
 func New(cfg *setting.Cfg,
 	options Options, features featuremgmt.FeatureToggles, router routing.RouteRegister, license licensing.Licensing,
 	ac accesscontrol.AccessControl, service accesscontrol.Service, sqlStore db.DB,
-	teamService team.Service, userService user.Service, actionSetService ActionSetService,
+	teamService team.Service, userService user.Service, actionSetService ActionSetService, auditService audit.Service,
 ) (*Service, error) {
 	permissions := make([]string, 0, len(options.PermissionsToActions))
 	actionSet := make(map[string]struct{})
@@ -97,6 +98,7 @@ func New(cfg *setting.Cfg,
 		teamService:  teamService,
 		userService:  userService,
 		actionSetSvc: actionSetService,
+		audit:        auditService,
 	}
 
 	s.api = newApi(cfg, ac, router, s)
@@ -126,6 +128,7 @@ type Service struct {
 	teamService  team.Service
 	userService  user.Service
 	actionSetSvc ActionSetService
+	audit        audit.Service
 }
 
 func (s *Service) GetPermissions(ctx context.Context, user identity.Requester, resourceID string) ([]accesscontrol.ResourcePermission, error) {
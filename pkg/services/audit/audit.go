@@ -0,0 +1,177 @@
+package audit
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var ErrSearchUnavailable = errutil.BadRequest("audit.search-unavailable", errutil.WithPublicMessage("Audit events can only be searched when the database sink is enabled"))
+
+type Service interface {
+	// Log records an event. Events are written to the configured sinks in the background,
+	// failing to write an event never fails the request that caused it.
+	Log(ctx context.Context, event *Event)
+	// Search returns the events recorded by the database sink, newest first.
+	Search(ctx context.Context, query *SearchQuery) (*SearchResult, error)
+}
+
+type Action string
+
+const (
+	ActionLogin Action = "login"
+
+	ActionDashboardSave   Action = "dashboard.save"
+	ActionDashboardDelete Action = "dashboard.delete"
+
+	ActionDatasourceCreate Action = "datasource.create"
+	ActionDatasourceUpdate Action = "datasource.update"
+	ActionDatasourceDelete Action = "datasource.delete"
+
+	ActionPermissionsUpdate Action = "permissions.update"
+
+	ActionServiceAccountTokenCreate Action = "serviceaccount.token.create"
+	ActionServiceAccountTokenDelete Action = "serviceaccount.token.delete"
+
+	ActionServiceAccountTrustPolicyCreate Action = "serviceaccount.trustpolicy.create"
+	ActionServiceAccountTrustPolicyDelete Action = "serviceaccount.trustpolicy.delete"
+	ActionServiceAccountTokenExchange     Action = "serviceaccount.token.exchange"
+
+	ActionAPIKeyCreate Action = "apikey.create"
+	ActionAPIKeyDelete Action = "apikey.delete"
+
+	ActionAlertRuleGroupUpdate Action = "alerting.rulegroup.update"
+	ActionAlertRuleGroupDelete Action = "alerting.rulegroup.delete"
+)
+
+type Result string
+
+const (
+	ResultSuccess Result = "success"
+	ResultFailure Result = "failure"
+)
+
+// Resource types of events. Permission changes use the resource names of
+// accesscontrol/resourcepermissions, such as dashboards or folders.
+const (
+	ResourceDashboard                 = "dashboard"
+	ResourceDatasource                = "datasource"
+	ResourceServiceAccountToken       = "serviceaccount.token"
+	ResourceServiceAccountTrustPolicy = "serviceaccount.trustpolicy"
+	ResourceAPIKey                    = "apikey"
+	ResourceAlertRuleGroup            = "alerting.rulegroup"
+	ResourceUser                      = "user"
+)
+
+const maxUserAgentLength = 512
+
+// Event describes who did what to which resource, when and from where.
+type Event struct {
+	ID        int64     `json:"id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Action    Action    `json:"action"`
+	Result    Result    `json:"result"`
+	OrgID     int64     `json:"orgId"`
+	Actor     Actor     `json:"actor"`
+	Resource  Resource  `json:"resource"`
+	IPAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+	// Details holds action specific information, such as the permissions that were set.
+	Details map[string]any `json:"details,omitempty"`
+	Error   string         `json:"error,omitempty"`
+}
+
+type Actor struct {
+	// Type is the namespace of the identity, e.g. user or service-account.
+	Type       string `json:"type"`
+	ID         string `json:"id"`
+	Login      string `json:"login"`
+	AuthModule string `json:"authModule,omitempty"`
+}
+
+type Resource struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// NewEvent returns a successful event for an action performed by requester with the
+// request r. Both the requester and the request can be nil.
+func NewEvent(r *http.Request, requester identity.Requester, action Action) *Event {
+	event := &Event{
+		Timestamp: time.Now(),
+		Action:    action,
+		Result:    ResultSuccess,
+	}
+
+	if requester != nil && !requester.IsNil() {
+		namespace, identifier := requester.GetNamespacedID()
+		event.OrgID = requester.GetOrgID()
+		event.Actor = Actor{
+			Type:       string(namespace),
+			ID:         identifier,
+			Login:      requester.GetLogin(),
+			AuthModule: requester.GetAuthenticatedBy(),
+		}
+	}
+
+	if r != nil {
+		event.IPAddress = web.RemoteAddr(r)
+		event.UserAgent = r.UserAgent()
+		if len(event.UserAgent) > maxUserAgentLength {
+			event.UserAgent = event.UserAgent[:maxUserAgentLength]
+		}
+	}
+
+	return event
+}
+
+// WithResource sets the resource the action was performed on.
+func (e *Event) WithResource(resourceType, id, name string) *Event {
+	e.Resource = Resource{Type: resourceType, ID: id, Name: name}
+	return e
+}
+
+// WithDetail adds action specific information to the event.
+func (e *Event) WithDetail(key string, value any) *Event {
+	if e.Details == nil {
+		e.Details = map[string]any{}
+	}
+	e.Details[key] = value
+	return e
+}
+
+// WithError marks the event as failed when err is not nil.
+func (e *Event) WithError(err error) *Event {
+	if err != nil {
+		e.Result = ResultFailure
+		e.Error = err.Error()
+	}
+	return e
+}
+
+type SearchQuery struct {
+	OrgID        int64
+	Action       Action
+	Result       Result
+	ActorType    string
+	ActorID      string
+	ActorLogin   string
+	ResourceType string
+	ResourceID   string
+	From         time.Time
+	To           time.Time
+	Page         int
+	Limit        int
+}
+
+type SearchResult struct {
+	TotalCount int64    `json:"totalCount"`
+	Events     []*Event `json:"events"`
+	Page       int      `json:"page"`
+	PerPage    int      `json:"perPage"`
+}
@@ -0,0 +1,78 @@
+package auditimpl
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+)
+
+const (
+	defaultPerPage = 100
+	maxPerPage     = 1000
+)
+
+type api struct {
+	service       audit.Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+}
+
+func newAPI(service audit.Service, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister) *api {
+	return &api{
+		service:       service,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+	}
+}
+
+func (a *api) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(a.accessControl)
+
+	a.routeRegister.Get("/api/admin/audit/events", middleware.ReqSignedIn,
+		authorize(accesscontrol.EvalPermission(ActionRead)), routing.Wrap(a.searchEvents))
+}
+
+// searchEvents returns the audit events matching the query parameters, newest first.
+// from and to are epoch milliseconds.
+func (a *api) searchEvents(c *contextmodel.ReqContext) response.Response {
+	query := &audit.SearchQuery{
+		OrgID:        c.QueryInt64("orgId"),
+		Action:       audit.Action(c.Query("action")),
+		Result:       audit.Result(c.Query("result")),
+		ActorType:    c.Query("actorType"),
+		ActorID:      c.Query("actorId"),
+		ActorLogin:   c.Query("actorLogin"),
+		ResourceType: c.Query("resourceType"),
+		ResourceID:   c.Query("resourceId"),
+		Page:         c.QueryInt("page"),
+		Limit:        c.QueryInt("perpage"),
+	}
+
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.UnixMilli(from)
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.UnixMilli(to)
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.Limit < 1 {
+		query.Limit = defaultPerPage
+	}
+	if query.Limit > maxPerPage {
+		query.Limit = maxPerPage
+	}
+
+	result, err := a.service.Search(c.Req.Context(), query)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to search audit events", err)
+	}
+	return response.JSON(http.StatusOK, result)
+}
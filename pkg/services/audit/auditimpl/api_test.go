@@ -0,0 +1,81 @@
+package auditimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/audit/audittest"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func setupTestServer(t *testing.T, service audit.Service) *webtest.Server {
+	t.Helper()
+
+	router := routing.NewRouteRegister()
+	newAPI(service, acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), router).registerAPIEndpoints()
+	return webtest.NewServer(t, router)
+}
+
+func userWithPermissions(permissions []accesscontrol.Permission) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 1, OrgID: 1, Login: "admin", Permissions: map[int64]map[string][]string{
+		1: accesscontrol.GroupScopesByActionContext(context.Background(), permissions),
+	}}
+}
+
+func TestAPI_SearchEvents(t *testing.T) {
+	tests := []struct {
+		desc         string
+		permissions  []accesscontrol.Permission
+		service      *audittest.FakeService
+		expectedCode int
+	}{
+		{
+			desc:         "should return the events",
+			permissions:  []accesscontrol.Permission{{Action: ActionRead}},
+			service:      &audittest.FakeService{ExpectedSearchResult: &audit.SearchResult{TotalCount: 1, Events: []*audit.Event{{Action: audit.ActionLogin}}}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should require permission to read the audit log",
+			service:      &audittest.FakeService{},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should fail when the database sink is disabled",
+			permissions:  []accesscontrol.Permission{{Action: ActionRead}},
+			service:      &audittest.FakeService{ExpectedErr: audit.ErrSearchUnavailable.Errorf("disabled")},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTestServer(t, tt.service)
+			req := webtest.RequestWithSignedInUser(server.NewGetRequest("/api/admin/audit/events"), userWithPermissions(tt.permissions))
+			res, err := server.Send(req)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var result audit.SearchResult
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&result))
+				assert.Equal(t, int64(1), result.TotalCount)
+				require.Len(t, result.Events, 1)
+				assert.Equal(t, audit.ActionLogin, result.Events[0].Action)
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package auditimpl
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/serverlock"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	queueSize       = 1000
+	maxBatchSize    = 100
+	writeTimeout    = 30 * time.Second
+	cleanupInterval = time.Hour
+)
+
+var _ audit.Service = (*Service)(nil)
+
+type Service struct {
+	cfg     *setting.Cfg
+	store   store
+	sinks   []sink
+	queue   chan *audit.Event
+	lock    *serverlock.ServerLockService
+	log     log.Logger
+	metrics *metrics
+}
+
+func ProvideService(
+	cfg *setting.Cfg,
+	sqlStore db.DB,
+	lock *serverlock.ServerLockService,
+	authnService authn.Service,
+	accessControl accesscontrol.AccessControl,
+	accesscontrolService accesscontrol.Service,
+	routeRegister routing.RouteRegister,
+	reg prometheus.Registerer,
+) (*Service, error) {
+	s := &Service{
+		cfg:     cfg,
+		store:   &xormStore{db: sqlStore},
+		queue:   make(chan *audit.Event, queueSize),
+		lock:    lock,
+		log:     log.New("audit"),
+		metrics: newMetrics(reg),
+	}
+
+	if !cfg.Audit.Enabled {
+		return s, nil
+	}
+
+	for _, name := range cfg.Audit.Sinks {
+		switch name {
+		case setting.AuditSinkDatabase:
+			s.sinks = append(s.sinks, &databaseSink{store: s.store})
+		case setting.AuditSinkFile:
+			path := cfg.Audit.FilePath
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(cfg.LogsPath, path)
+			}
+			fileSink, err := newFileSink(path)
+			if err != nil {
+				return nil, err
+			}
+			s.sinks = append(s.sinks, fileSink)
+		case setting.AuditSinkWebhook:
+			s.sinks = append(s.sinks, newWebhookSink(cfg.Audit.WebhookURL, cfg.Audit.WebhookAuthorization, cfg.Audit.WebhookTimeout))
+		}
+	}
+
+	if err := declareFixedRoles(accesscontrolService); err != nil {
+		return nil, err
+	}
+
+	authnService.RegisterPostLoginHook(s.loginHook, 200)
+	newAPI(s, accessControl, routeRegister).registerAPIEndpoints()
+
+	return s, nil
+}
+
+func (s *Service) Log(ctx context.Context, event *audit.Event) {
+	if len(s.sinks) == 0 {
+		return
+	}
+
+	select {
+	case s.queue <- event:
+	default:
+		// the sinks can't keep up, slow down the request instead of losing the event
+		s.log.FromContext(ctx).Warn("Writing audit event synchronously, the queue is full", "action", event.Action, "actor", event.Actor.Login)
+		s.metrics.synchronousWrites.Inc()
+		s.write(ctx, []*audit.Event{event})
+	}
+}
+
+func (s *Service) Search(ctx context.Context, query *audit.SearchQuery) (*audit.SearchResult, error) {
+	if !s.hasDatabaseSink() {
+		return nil, audit.ErrSearchUnavailable.Errorf("database sink is not enabled")
+	}
+	return s.store.Search(ctx, query)
+}
+
+func (s *Service) IsDisabled() bool {
+	return len(s.sinks) == 0
+}
+
+func (s *Service) Run(ctx context.Context) error {
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case event := <-s.queue:
+			s.write(ctx, s.batch(event))
+		case <-cleanup.C:
+			s.cleanup(ctx)
+		case <-ctx.Done():
+			// write the events that are still queued before shutting down
+			for {
+				select {
+				case event := <-s.queue:
+					s.write(context.Background(), s.batch(event))
+				default:
+					return ctx.Err()
+				}
+			}
+		}
+	}
+}
+
+// batch returns first together with the events that are queued behind it.
+func (s *Service) batch(first *audit.Event) []*audit.Event {
+	events := []*audit.Event{first}
+	for len(events) < maxBatchSize {
+		select {
+		case event := <-s.queue:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+	return events
+}
+
+func (s *Service) write(ctx context.Context, events []*audit.Event) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+
+	for _, sink := range s.sinks {
+		if err := sink.Write(ctx, events); err != nil {
+			s.log.Error("Failed to write audit events", "sink", sink.Name(), "count", len(events), "error", err)
+			s.metrics.droppedEvents.WithLabelValues(sink.Name()).Add(float64(len(events)))
+		}
+	}
+}
+
+func (s *Service) cleanup(ctx context.Context) {
+	if !s.hasDatabaseSink() || s.cfg.Audit.Retention <= 0 {
+		return
+	}
+
+	err := s.lock.LockAndExecute(ctx, "delete old audit events", cleanupInterval, func(ctx context.Context) {
+		deleted, err := s.store.DeleteOlderThan(ctx, time.Now().Add(-s.cfg.Audit.Retention))
+		if err != nil {
+			s.log.Error("Failed to delete old audit events", "error", err)
+			return
+		}
+		s.log.Debug("Deleted old audit events", "count", deleted)
+	})
+	if err != nil {
+		s.log.Error("Failed to lock and execute cleanup of old audit events", "error", err)
+	}
+}
+
+func (s *Service) hasDatabaseSink() bool {
+	for _, sink := range s.sinks {
+		if _, ok := sink.(*databaseSink); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Service) loginHook(ctx context.Context, identity *authn.Identity, r *authn.Request, err error) {
+	var event *audit.Event
+	if identity != nil {
+		event = audit.NewEvent(r.HTTPRequest, identity, audit.ActionLogin)
+	} else {
+		// the identity is unknown when the login failed, record who tried to sign in
+		event = audit.NewEvent(r.HTTPRequest, nil, audit.ActionLogin)
+		event.OrgID = r.OrgID
+		event.Actor = audit.Actor{
+			Type:       string(authn.NamespaceUser),
+			Login:      r.GetMeta(authn.MetaKeyUsername),
+			AuthModule: r.GetMeta(authn.MetaKeyAuthModule),
+		}
+	}
+	s.Log(ctx, event.WithError(err))
+}
@@ -0,0 +1,136 @@
+package auditimpl
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeSink struct {
+	mu     sync.Mutex
+	events []*audit.Event
+	err    error
+}
+
+func (s *fakeSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeSink) Write(_ context.Context, events []*audit.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func newTestService(sinks ...sink) *Service {
+	return &Service{
+		cfg:     setting.NewCfg(),
+		sinks:   sinks,
+		queue:   make(chan *audit.Event, queueSize),
+		log:     log.NewNopLogger(),
+		metrics: newMetrics(nil),
+	}
+}
+
+func TestService_Run(t *testing.T) {
+	t.Run("should write queued events to every sink before shutting down", func(t *testing.T) {
+		first, second := &fakeSink{}, &fakeSink{}
+		s := newTestService(first, second)
+
+		s.Log(context.Background(), audit.NewEvent(nil, nil, audit.ActionLogin))
+		s.Log(context.Background(), audit.NewEvent(nil, nil, audit.ActionDashboardSave))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		require.ErrorIs(t, s.Run(ctx), context.Canceled)
+
+		for _, sink := range []*fakeSink{first, second} {
+			require.Len(t, sink.events, 2)
+			assert.Equal(t, audit.ActionLogin, sink.events[0].Action)
+			assert.Equal(t, audit.ActionDashboardSave, sink.events[1].Action)
+		}
+	})
+
+	t.Run("should write events synchronously when the queue is full", func(t *testing.T) {
+		sink := &fakeSink{}
+		s := newTestService(sink)
+		for i := 0; i < queueSize; i++ {
+			s.Log(context.Background(), audit.NewEvent(nil, nil, audit.ActionLogin))
+		}
+		require.Empty(t, sink.events)
+
+		s.Log(context.Background(), audit.NewEvent(nil, nil, audit.ActionDashboardSave))
+		require.Len(t, sink.events, 1)
+		assert.Equal(t, audit.ActionDashboardSave, sink.events[0].Action)
+		assert.Equal(t, 1.0, testutil.ToFloat64(s.metrics.synchronousWrites))
+	})
+
+	t.Run("should count the events a sink failed to write", func(t *testing.T) {
+		s := newTestService(&fakeSink{err: errors.New("unavailable")}, &fakeSink{})
+		s.write(context.Background(), []*audit.Event{audit.NewEvent(nil, nil, audit.ActionLogin), audit.NewEvent(nil, nil, audit.ActionLogin)})
+
+		assert.Equal(t, 2.0, testutil.ToFloat64(s.metrics.droppedEvents.WithLabelValues("fake")))
+	})
+
+	t.Run("should drop events when no sink is configured", func(t *testing.T) {
+		s := newTestService()
+		s.Log(context.Background(), audit.NewEvent(nil, nil, audit.ActionLogin))
+
+		assert.True(t, s.IsDisabled())
+		assert.Empty(t, s.queue)
+	})
+}
+
+func TestService_Search(t *testing.T) {
+	s := newTestService(&fakeSink{})
+	_, err := s.Search(context.Background(), &audit.SearchQuery{})
+	assert.ErrorIs(t, err, audit.ErrSearchUnavailable)
+}
+
+func TestService_LoginHook(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "/login", nil)
+	require.NoError(t, err)
+	req.RemoteAddr = "10.0.0.1:1234"
+
+	t.Run("should record successful logins", func(t *testing.T) {
+		s := newTestService(&fakeSink{})
+		identity := &authn.Identity{ID: authn.MustParseNamespaceID("user:1"), OrgID: 1, Login: "admin", AuthenticatedBy: "password"}
+		s.loginHook(context.Background(), identity, &authn.Request{HTTPRequest: req}, nil)
+
+		event := <-s.queue
+		assert.Equal(t, audit.ActionLogin, event.Action)
+		assert.Equal(t, audit.ResultSuccess, event.Result)
+		assert.Equal(t, audit.Actor{Type: "user", ID: "1", Login: "admin", AuthModule: "password"}, event.Actor)
+		assert.Equal(t, "10.0.0.1", event.IPAddress)
+	})
+
+	t.Run("should record failed logins with the attempted username", func(t *testing.T) {
+		s := newTestService(&fakeSink{})
+		r := &authn.Request{HTTPRequest: req, OrgID: 1}
+		r.SetMeta(authn.MetaKeyUsername, "admin")
+		r.SetMeta(authn.MetaKeyAuthModule, "password")
+		s.loginHook(context.Background(), nil, r, errors.New("invalid password"))
+
+		event := <-s.queue
+		assert.Equal(t, audit.ResultFailure, event.Result)
+		assert.Equal(t, "invalid password", event.Error)
+		assert.Equal(t, "admin", event.Actor.Login)
+		assert.Equal(t, "password", event.Actor.AuthModule)
+		assert.Equal(t, int64(1), event.OrgID)
+	})
+}
@@ -0,0 +1,39 @@
+package auditimpl
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	metricsNamespace = "grafana"
+	metricsSubSystem = "audit"
+)
+
+func newMetrics(reg prometheus.Registerer) *metrics {
+	m := &metrics{
+		droppedEvents: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "dropped_events_total",
+			Help:      "Number of audit events a sink failed to write",
+		}, []string{"sink"}),
+		synchronousWrites: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Subsystem: metricsSubSystem,
+			Name:      "synchronous_writes_total",
+			Help:      "Number of audit events written by the request because the queue was full",
+		}),
+	}
+
+	if reg != nil {
+		reg.MustRegister(m.droppedEvents)
+		reg.MustRegister(m.synchronousWrites)
+	}
+
+	return m
+}
+
+type metrics struct {
+	droppedEvents     *prometheus.CounterVec
+	synchronousWrites prometheus.Counter
+}
@@ -0,0 +1,24 @@
+package auditimpl
+
+import (
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+const ActionRead = "audit.events:read"
+
+var eventsReaderRole = accesscontrol.RoleDTO{
+	Name:        "fixed:audit.events:reader",
+	DisplayName: "Audit log reader",
+	Description: "Search the audit log of all organizations",
+	Group:       "Audit log",
+	Permissions: []accesscontrol.Permission{
+		{Action: ActionRead},
+	},
+}
+
+func declareFixedRoles(service accesscontrol.Service) error {
+	return service.DeclareFixedRoles(accesscontrol.RoleRegistration{
+		Role:   eventsReaderRole,
+		Grants: []string{accesscontrol.RoleGrafanaAdmin},
+	})
+}
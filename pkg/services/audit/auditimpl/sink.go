@@ -0,0 +1,124 @@
+package auditimpl
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+// sink is a destination audit events are written to.
+type sink interface {
+	Name() string
+	Write(ctx context.Context, events []*audit.Event) error
+}
+
+type databaseSink struct {
+	store store
+}
+
+func (s *databaseSink) Name() string {
+	return "database"
+}
+
+func (s *databaseSink) Write(ctx context.Context, events []*audit.Event) error {
+	return s.store.Insert(ctx, events)
+}
+
+// fileSink appends events to a file as JSON lines. The file is reopened on every
+// write so that it can be rotated by external tools.
+type fileSink struct {
+	path string
+	mu   sync.Mutex
+}
+
+func newFileSink(path string) (*fileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+	return &fileSink{path: path}, nil
+}
+
+func (s *fileSink) Name() string {
+	return "file"
+}
+
+func (s *fileSink) Write(_ context.Context, events []*audit.Event) error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// the path is set by the administrator in the configuration
+	// nolint:gosec
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// webhookSink posts batches of events as JSON array to an HTTP endpoint.
+type webhookSink struct {
+	url           string
+	authorization string
+	client        *http.Client
+}
+
+func newWebhookSink(url, authorization string, timeout time.Duration) *webhookSink {
+	return &webhookSink{
+		url:           url,
+		authorization: authorization,
+		client:        &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *webhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *webhookSink) Write(ctx context.Context, events []*audit.Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authorization != "" {
+		req.Header.Set("Authorization", s.authorization)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package auditimpl
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.log")
+	sink, err := newFileSink(path)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.Write(context.Background(), []*audit.Event{
+		audit.NewEvent(nil, nil, audit.ActionLogin),
+		audit.NewEvent(nil, nil, audit.ActionDashboardSave),
+	}))
+	require.NoError(t, sink.Write(context.Background(), []*audit.Event{
+		audit.NewEvent(nil, nil, audit.ActionDashboardDelete),
+	}))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	expected := []audit.Action{audit.ActionLogin, audit.ActionDashboardSave, audit.ActionDashboardDelete}
+	for i, line := range lines {
+		var event audit.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		assert.Equal(t, expected[i], event.Action)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	t.Run("should post the events as JSON array", func(t *testing.T) {
+		var received []*audit.Event
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		t.Cleanup(server.Close)
+
+		sink := newWebhookSink(server.URL, "Bearer secret", time.Second)
+		err := sink.Write(context.Background(), []*audit.Event{
+			audit.NewEvent(nil, nil, audit.ActionLogin),
+			audit.NewEvent(nil, nil, audit.ActionAPIKeyCreate),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, received, 2)
+		assert.Equal(t, audit.ActionLogin, received[0].Action)
+		assert.Equal(t, audit.ActionAPIKeyCreate, received[1].Action)
+	})
+
+	t.Run("should fail when the webhook does not respond with 2xx", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		sink := newWebhookSink(server.URL, "", time.Second)
+		err := sink.Write(context.Background(), []*audit.Event{audit.NewEvent(nil, nil, audit.ActionLogin)})
+		require.ErrorContains(t, err, "500")
+	})
+}
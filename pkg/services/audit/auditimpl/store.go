@@ -0,0 +1,205 @@
+package auditimpl
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+type store interface {
+	Insert(ctx context.Context, events []*audit.Event) error
+	Search(ctx context.Context, query *audit.SearchQuery) (*audit.SearchResult, error)
+	DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error)
+}
+
+// auditLog is the database representation of an audit.Event.
+type auditLog struct {
+	ID           int64     `xorm:"pk autoincr 'id'"`
+	Created      time.Time `xorm:"'created'"`
+	OrgID        int64     `xorm:"org_id"`
+	Action       string    `xorm:"action"`
+	Result       string    `xorm:"result"`
+	ActorType    string    `xorm:"actor_type"`
+	ActorID      string    `xorm:"actor_id"`
+	ActorLogin   string    `xorm:"actor_login"`
+	AuthModule   string    `xorm:"auth_module"`
+	ResourceType string    `xorm:"resource_type"`
+	ResourceID   string    `xorm:"resource_id"`
+	ResourceName string    `xorm:"resource_name"`
+	IPAddress    string    `xorm:"ip_address"`
+	UserAgent    string    `xorm:"user_agent"`
+	Details      string    `xorm:"details"`
+	Error        string    `xorm:"error"`
+}
+
+func (auditLog) TableName() string {
+	return "audit_log"
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Insert(ctx context.Context, events []*audit.Event) error {
+	rows := make([]*auditLog, 0, len(events))
+	for _, event := range events {
+		row, err := toAuditLog(event)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+	}
+
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.InsertMulti(&rows)
+		return err
+	})
+}
+
+func (s *xormStore) Search(ctx context.Context, query *audit.SearchQuery) (*audit.SearchResult, error) {
+	result := &audit.SearchResult{Page: query.Page, PerPage: query.Limit, Events: []*audit.Event{}}
+	filters := []string{"1 = 1"}
+	params := []any{}
+	addFilter := func(condition string, value any) {
+		filters = append(filters, condition)
+		params = append(params, value)
+	}
+	if query.OrgID != 0 {
+		addFilter("org_id = ?", query.OrgID)
+	}
+	if query.Action != "" {
+		addFilter("action = ?", string(query.Action))
+	}
+	if query.Result != "" {
+		addFilter("result = ?", string(query.Result))
+	}
+	if query.ActorType != "" {
+		addFilter("actor_type = ?", query.ActorType)
+	}
+	if query.ActorID != "" {
+		addFilter("actor_id = ?", query.ActorID)
+	}
+	if query.ActorLogin != "" {
+		addFilter("actor_login = ?", query.ActorLogin)
+	}
+	if query.ResourceType != "" {
+		addFilter("resource_type = ?", query.ResourceType)
+	}
+	if query.ResourceID != "" {
+		addFilter("resource_id = ?", query.ResourceID)
+	}
+	if !query.From.IsZero() {
+		addFilter("created >= ?", query.From)
+	}
+	if !query.To.IsZero() {
+		addFilter("created <= ?", query.To)
+	}
+	where := strings.Join(filters, " AND ")
+
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		count, err := sess.Where(where, params...).Count(&auditLog{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		rows := make([]*auditLog, 0, query.Limit)
+		offset := (query.Page - 1) * query.Limit
+		if err := sess.Where(where, params...).Desc("created", "id").Limit(query.Limit, offset).Find(&rows); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			event, err := fromAuditLog(row)
+			if err != nil {
+				return err
+			}
+			result.Events = append(result.Events, event)
+		}
+		return nil
+	})
+	return result, err
+}
+
+func (s *xormStore) DeleteOlderThan(ctx context.Context, olderThan time.Time) (int64, error) {
+	var affected int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM audit_log WHERE created < ?", olderThan)
+		if err != nil {
+			return err
+		}
+		affected, err = res.RowsAffected()
+		return err
+	})
+	return affected, err
+}
+
+func toAuditLog(event *audit.Event) (*auditLog, error) {
+	row := &auditLog{
+		Created:      event.Timestamp,
+		OrgID:        event.OrgID,
+		Action:       string(event.Action),
+		Result:       string(event.Result),
+		ActorType:    event.Actor.Type,
+		ActorID:      event.Actor.ID,
+		ActorLogin:   event.Actor.Login,
+		AuthModule:   event.Actor.AuthModule,
+		ResourceType: event.Resource.Type,
+		ResourceID:   event.Resource.ID,
+		ResourceName: truncate(event.Resource.Name, 190),
+		IPAddress:    event.IPAddress,
+		UserAgent:    event.UserAgent,
+		Error:        event.Error,
+	}
+
+	if len(event.Details) > 0 {
+		details, err := json.Marshal(event.Details)
+		if err != nil {
+			return nil, err
+		}
+		row.Details = string(details)
+	}
+	return row, nil
+}
+
+func fromAuditLog(row *auditLog) (*audit.Event, error) {
+	event := &audit.Event{
+		ID:        row.ID,
+		Timestamp: row.Created,
+		Action:    audit.Action(row.Action),
+		Result:    audit.Result(row.Result),
+		OrgID:     row.OrgID,
+		Actor: audit.Actor{
+			Type:       row.ActorType,
+			ID:         row.ActorID,
+			Login:      row.ActorLogin,
+			AuthModule: row.AuthModule,
+		},
+		Resource: audit.Resource{
+			Type: row.ResourceType,
+			ID:   row.ResourceID,
+			Name: row.ResourceName,
+		},
+		IPAddress: row.IPAddress,
+		UserAgent: row.UserAgent,
+		Error:     row.Error,
+	}
+
+	if row.Details != "" {
+		if err := json.Unmarshal([]byte(row.Details), &event.Details); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+func truncate(s string, length int) string {
+	if len(s) <= length {
+		return s
+	}
+	return s[:length]
+}
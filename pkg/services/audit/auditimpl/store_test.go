@@ -0,0 +1,117 @@
+package auditimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationAuditStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	newEvent := func(orgID int64, action audit.Action, login string, created time.Time) *audit.Event {
+		event := audit.NewEvent(nil, nil, action)
+		event.Timestamp = created
+		event.OrgID = orgID
+		event.Actor = audit.Actor{Type: "user", ID: "1", Login: login}
+		return event
+	}
+
+	setup := func(t *testing.T) *xormStore {
+		s := &xormStore{db: db.InitTestDB(t)}
+		require.NoError(t, s.Insert(ctx, []*audit.Event{
+			newEvent(1, audit.ActionLogin, "admin", now.Add(-3*time.Hour)),
+			newEvent(1, audit.ActionDashboardSave, "admin", now.Add(-2*time.Hour)).
+				WithResource(audit.ResourceDashboard, "abc", "My dashboard").
+				WithDetail("version", 2),
+			newEvent(2, audit.ActionDatasourceDelete, "editor", now.Add(-time.Hour)).
+				WithResource(audit.ResourceDatasource, "ds", "Prometheus").
+				WithError(assert.AnError),
+		}))
+		return s
+	}
+
+	t.Run("should return all events newest first", func(t *testing.T) {
+		s := setup(t)
+		result, err := s.Search(ctx, &audit.SearchQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Events, 3)
+		assert.Equal(t, audit.ActionDatasourceDelete, result.Events[0].Action)
+		assert.Equal(t, audit.ResultFailure, result.Events[0].Result)
+		assert.Equal(t, assert.AnError.Error(), result.Events[0].Error)
+		assert.Equal(t, audit.ActionDashboardSave, result.Events[1].Action)
+		assert.Equal(t, audit.Resource{Type: audit.ResourceDashboard, ID: "abc", Name: "My dashboard"}, result.Events[1].Resource)
+		assert.Equal(t, map[string]any{"version": float64(2)}, result.Events[1].Details)
+		assert.Equal(t, audit.ActionLogin, result.Events[2].Action)
+	})
+
+	t.Run("should filter events", func(t *testing.T) {
+		s := setup(t)
+		tests := []struct {
+			desc     string
+			query    audit.SearchQuery
+			expected []audit.Action
+		}{
+			{desc: "by org", query: audit.SearchQuery{OrgID: 2}, expected: []audit.Action{audit.ActionDatasourceDelete}},
+			{desc: "by action", query: audit.SearchQuery{Action: audit.ActionLogin}, expected: []audit.Action{audit.ActionLogin}},
+			{desc: "by result", query: audit.SearchQuery{Result: audit.ResultFailure}, expected: []audit.Action{audit.ActionDatasourceDelete}},
+			{desc: "by actor", query: audit.SearchQuery{ActorLogin: "admin"}, expected: []audit.Action{audit.ActionDashboardSave, audit.ActionLogin}},
+			{desc: "by resource", query: audit.SearchQuery{ResourceType: audit.ResourceDashboard, ResourceID: "abc"}, expected: []audit.Action{audit.ActionDashboardSave}},
+			{desc: "by time range", query: audit.SearchQuery{From: now.Add(-150 * time.Minute), To: now.Add(-90 * time.Minute)}, expected: []audit.Action{audit.ActionDashboardSave}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				tt.query.Page, tt.query.Limit = 1, 10
+				result, err := s.Search(ctx, &tt.query)
+				require.NoError(t, err)
+
+				actions := make([]audit.Action, 0, len(result.Events))
+				for _, event := range result.Events {
+					actions = append(actions, event.Action)
+				}
+				assert.Equal(t, tt.expected, actions)
+				assert.Equal(t, int64(len(tt.expected)), result.TotalCount)
+			})
+		}
+	})
+
+	t.Run("should paginate events", func(t *testing.T) {
+		s := setup(t)
+		result, err := s.Search(ctx, &audit.SearchQuery{Page: 2, Limit: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, audit.ActionLogin, result.Events[0].Action)
+	})
+
+	t.Run("should delete events older than the retention", func(t *testing.T) {
+		s := setup(t)
+		deleted, err := s.DeleteOlderThan(ctx, now.Add(-90*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+
+		result, err := s.Search(ctx, &audit.SearchQuery{Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, result.Events, 1)
+		assert.Equal(t, audit.ActionDatasourceDelete, result.Events[0].Action)
+	})
+}
@@ -0,0 +1,35 @@
+package audittest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/services/audit"
+)
+
+var _ audit.Service = (*FakeService)(nil)
+
+type FakeService struct {
+	ExpectedSearchResult *audit.SearchResult
+	ExpectedErr          error
+
+	mu     sync.Mutex
+	events []*audit.Event
+}
+
+func (f *FakeService) Log(_ context.Context, event *audit.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, event)
+}
+
+func (f *FakeService) Search(_ context.Context, _ *audit.SearchQuery) (*audit.SearchResult, error) {
+	return f.ExpectedSearchResult, f.ExpectedErr
+}
+
+// Events returns the events logged so far.
+func (f *FakeService) Events() []*audit.Event {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*audit.Event(nil), f.events...)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/go-jose/go-jose/v3/jwt"
 
@@ -24,6 +25,20 @@ func ProvideService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache) (*Au
 	return s, nil
 }
 
+// NewKeySetURLVerifier returns a service that verifies tokens with the keys of the JSON Web Key Set
+// at jwksURL and validates the registered claims against expected. It is independent of the
+// [auth.jwt] settings, the key set is cached in the remote cache for cacheTTL.
+func NewKeySetURLVerifier(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache, jwksURL string, cacheTTL time.Duration, expected jwt.Expected) (*AuthService, error) {
+	s := newService(cfg, remoteCache)
+	keySet, err := s.newKeySetHTTP(jwksURL, cacheTTL)
+	if err != nil {
+		return nil, err
+	}
+	s.keySet = keySet
+	s.expectRegistered = expected
+	return s, nil
+}
+
 func newService(cfg *setting.Cfg, remoteCache *remotecache.RemoteCache) *AuthService {
 	return &AuthService{
 		Cfg:         cfg,
@@ -160,6 +160,44 @@ func TestVerifyUsingJWKSetURL(t *testing.T) {
 	})
 }
 
+func TestNewKeySetURLVerifier(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewEncoder(w).Encode(jwksPublic); err != nil {
+			panic(err)
+		}
+	}))
+	t.Cleanup(ts.Close)
+
+	cfg := setting.NewCfg()
+	expected := jwt.Expected{Issuer: "http://foo", Audience: jwt.Audience{"bar"}}
+
+	t.Run("should refuse non-https URL", func(t *testing.T) {
+		cfg := setting.NewCfg()
+		cfg.Env = setting.Prod
+		_, err := NewKeySetURLVerifier(cfg, remotecache.NewFakeStore(t), "http://example.com/.well-known/jwks.json", time.Hour, expected)
+		require.ErrorIs(t, err, ErrJWTSetURLMustHaveHTTPSScheme)
+	})
+
+	t.Run("should verify the key and the expected claims", func(t *testing.T) {
+		verifier, err := NewKeySetURLVerifier(cfg, remotecache.NewFakeStore(t), ts.URL, time.Hour, expected)
+		require.NoError(t, err)
+		verifier.keySet.(*keySetHTTP).client = ts.Client()
+
+		claims, err := verifier.Verify(context.Background(), sign(t, &jwKeys[0], jwt.Claims{Issuer: "http://foo", Audience: jwt.Audience{"bar"}, Subject: subject}, nil))
+		require.NoError(t, err)
+		assert.Equal(t, subject, claims["sub"])
+
+		_, err = verifier.Verify(context.Background(), sign(t, &jwKeys[0], jwt.Claims{Issuer: "http://foo", Audience: jwt.Audience{"baz"}}, nil))
+		require.Error(t, err)
+
+		_, err = verifier.Verify(context.Background(), sign(t, &jwKeys[0], jwt.Claims{Issuer: "http://other", Audience: jwt.Audience{"bar"}}, nil))
+		require.Error(t, err)
+
+		_, err = verifier.Verify(context.Background(), sign(t, jwKeys[2], jwt.Claims{Issuer: "http://foo", Audience: jwt.Audience{"bar"}}, nil))
+		require.Error(t, err)
+	})
+}
+
 func TestCachingJWKHTTPResponse(t *testing.T) {
 	jwkCachingScenario(t, "caches the jwk response", func(t *testing.T, sc cachingScenarioContext) {
 		for i := 0; i < 5; i++ {
@@ -148,42 +148,50 @@ func (s *AuthService) initKeySet() error {
 
 		s.keySet = &keySetJWKS{jwks}
 	} else if urlStr := s.Cfg.JWTAuth.JWKSetURL; urlStr != "" {
-		urlParsed, err := url.Parse(urlStr)
+		keySet, err := s.newKeySetHTTP(urlStr, s.Cfg.JWTAuth.CacheTTL)
 		if err != nil {
 			return err
 		}
-		if urlParsed.Scheme != "https" && s.Cfg.Env != setting.Dev {
-			return ErrJWTSetURLMustHaveHTTPSScheme
-		}
-		s.keySet = &keySetHTTP{
-			url: urlStr,
-			log: s.log,
-			client: &http.Client{
-				Transport: &http.Transport{
-					TLSClientConfig: &tls.Config{
-						Renegotiation: tls.RenegotiateFreelyAsClient,
-					},
-					Proxy: http.ProxyFromEnvironment,
-					DialContext: (&net.Dialer{
-						Timeout:   time.Second * 30,
-						KeepAlive: 15 * time.Second,
-					}).DialContext,
-					TLSHandshakeTimeout:   10 * time.Second,
-					ExpectContinueTimeout: 1 * time.Second,
-					MaxIdleConns:          100,
-					IdleConnTimeout:       30 * time.Second,
-				},
-				Timeout: time.Second * 30,
-			},
-			cacheKey:        fmt.Sprintf("auth-jwt:jwk-%s", urlStr),
-			cacheExpiration: s.Cfg.JWTAuth.CacheTTL,
-			cache:           s.RemoteCache,
-		}
+		s.keySet = keySet
 	}
 
 	return nil
 }
 
+func (s *AuthService) newKeySetHTTP(urlStr string, cacheTTL time.Duration) (*keySetHTTP, error) {
+	urlParsed, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, err
+	}
+	if urlParsed.Scheme != "https" && s.Cfg.Env != setting.Dev {
+		return nil, ErrJWTSetURLMustHaveHTTPSScheme
+	}
+	return &keySetHTTP{
+		url: urlStr,
+		log: s.log,
+		client: &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					Renegotiation: tls.RenegotiateFreelyAsClient,
+				},
+				Proxy: http.ProxyFromEnvironment,
+				DialContext: (&net.Dialer{
+					Timeout:   time.Second * 30,
+					KeepAlive: 15 * time.Second,
+				}).DialContext,
+				TLSHandshakeTimeout:   10 * time.Second,
+				ExpectContinueTimeout: 1 * time.Second,
+				MaxIdleConns:          100,
+				IdleConnTimeout:       30 * time.Second,
+			},
+			Timeout: time.Second * 30,
+		},
+		cacheKey:        fmt.Sprintf("auth-jwt:jwk-%s", urlStr),
+		cacheExpiration: cacheTTL,
+		cache:           s.RemoteCache,
+	}, nil
+}
+
 func (ks *keySetJWKS) Key(ctx context.Context, keyID string) ([]jose.JSONWebKey, error) {
 	return ks.JSONWebKeySet.Key(keyID), nil
 }
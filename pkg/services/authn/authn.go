@@ -17,16 +17,18 @@ import (
 )
 
 const (
-	ClientAPIKey      = "auth.client.api-key" // #nosec G101
-	ClientAnonymous   = "auth.client.anonymous"
-	ClientBasic       = "auth.client.basic"
-	ClientJWT         = "auth.client.jwt"
-	ClientExtendedJWT = "auth.client.extended-jwt"
-	ClientRender      = "auth.client.render"
-	ClientSession     = "auth.client.session"
-	ClientForm        = "auth.client.form"
-	ClientProxy       = "auth.client.proxy"
-	ClientSAML        = "auth.client.saml"
+	ClientAPIKey           = "auth.client.api-key" // #nosec G101
+	ClientAnonymous        = "auth.client.anonymous"
+	ClientBasic            = "auth.client.basic"
+	ClientJWT              = "auth.client.jwt"
+	ClientExtendedJWT      = "auth.client.extended-jwt"
+	ClientRender           = "auth.client.render"
+	ClientSession          = "auth.client.session"
+	ClientForm             = "auth.client.form"
+	ClientMFA              = "auth.client.mfa"
+	ClientProxy            = "auth.client.proxy"
+	ClientSAML             = "auth.client.saml"
+	ClientWorkloadIdentity = "auth.client.workload-identity"
 )
 
 const (
@@ -16,10 +16,12 @@ import (
 	"github.com/grafana/grafana/pkg/services/ldap/service"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/loginattempt"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -37,7 +39,7 @@ func ProvideRegistration(
 	features *featuremgmt.FeatureManager, oauthTokenService oauthtoken.OAuthTokenService,
 	socialService social.Service, cache *remotecache.RemoteCache,
 	ldapService service.LDAP, settingsProviderService setting.Provider,
-	tracer tracing.Tracer,
+	teamSyncService teamsync.Service, tracer tracing.Tracer, mfaService mfa.Service,
 ) Registration {
 	logger := log.New("authn.registration")
 
@@ -66,11 +68,11 @@ func ProvideRegistration(
 	if len(passwordClients) > 0 {
 		passwordClient := clients.ProvidePassword(loginAttempts, passwordClients...)
 		if cfg.BasicAuthEnabled {
-			authnSvc.RegisterClient(clients.ProvideBasic(passwordClient))
+			authnSvc.RegisterClient(clients.ProvideBasic(passwordClient, mfaService))
 		}
 
 		if !cfg.DisableLoginForm {
-			authnSvc.RegisterClient(clients.ProvideForm(passwordClient))
+			authnSvc.RegisterClient(clients.ProvideForm(passwordClient, mfaService))
 		}
 	}
 
@@ -102,6 +104,7 @@ func ProvideRegistration(
 	authnSvc.RegisterPostAuthHook(userSync.SyncUserHook, 10)
 	authnSvc.RegisterPostAuthHook(userSync.EnableUserHook, 20)
 	authnSvc.RegisterPostAuthHook(orgSync.SyncOrgRolesHook, 30)
+	authnSvc.RegisterPostAuthHook(sync.ProvideTeamSync(teamSyncService, tracer).SyncTeamsHook, 40)
 	authnSvc.RegisterPostAuthHook(userSync.SyncLastSeenHook, 130)
 	authnSvc.RegisterPostAuthHook(sync.ProvideOAuthTokenSync(oauthTokenService, sessionService, socialService, tracer).SyncOauthTokenHook, 60)
 	authnSvc.RegisterPostAuthHook(userSync.FetchSyncedUserHook, 100)
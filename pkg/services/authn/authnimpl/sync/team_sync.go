@@ -0,0 +1,52 @@
+package sync
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+)
+
+func ProvideTeamSync(teamSyncService teamsync.Service, tracer tracing.Tracer) *TeamSync {
+	return &TeamSync{teamSyncService, log.New("team.sync"), tracer}
+}
+
+type TeamSync struct {
+	teamSyncService teamsync.Service
+	log             log.Logger
+	tracer          tracing.Tracer
+}
+
+// SyncTeamsHook updates the team memberships of the identity from the groups
+// reported by the identity provider.
+func (s *TeamSync) SyncTeamsHook(ctx context.Context, id *authn.Identity, _ *authn.Request) error {
+	ctx, span := s.tracer.Start(ctx, "team.sync.SyncTeamsHook")
+	defer span.End()
+
+	if !id.ClientParams.SyncTeams {
+		return nil
+	}
+
+	ctxLogger := s.log.FromContext(ctx).New("id", id.ID, "login", id.Login)
+
+	if !id.ID.IsNamespace(authn.NamespaceUser) {
+		ctxLogger.Warn("Failed to sync teams, invalid namespace for identity", "namespace", id.ID.Namespace())
+		return nil
+	}
+
+	userID, err := id.ID.ParseInt()
+	if err != nil {
+		ctxLogger.Warn("Failed to sync teams, invalid ID for identity", "namespace", id.ID.Namespace(), "err", err)
+		return nil
+	}
+
+	ctxLogger.Debug("Syncing teams", "groups", id.Groups)
+	if err := s.teamSyncService.SyncUserTeams(ctx, &teamsync.SyncUserTeamsCommand{UserID: userID, Groups: id.Groups}); err != nil {
+		// a failed team sync should not prevent the user from signing in
+		ctxLogger.Error("Failed to sync teams", "error", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package sync
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/services/team/teamsync/teamsynctest"
+)
+
+func TestTeamSync_SyncTeamsHook(t *testing.T) {
+	type testCase struct {
+		desc          string
+		identity      *authn.Identity
+		expectedCalls []*teamsync.SyncUserTeamsCommand
+	}
+
+	tests := []testCase{
+		{
+			desc: "should sync teams from the groups of the identity",
+			identity: &authn.Identity{
+				ID:           authn.MustParseNamespaceID("user:1"),
+				Groups:       []string{"cn=editors,ou=groups,dc=grafana,dc=org"},
+				ClientParams: authn.ClientParams{SyncTeams: true},
+			},
+			expectedCalls: []*teamsync.SyncUserTeamsCommand{
+				{UserID: 1, Groups: []string{"cn=editors,ou=groups,dc=grafana,dc=org"}},
+			},
+		},
+		{
+			desc: "should remove synced teams when the identity has no groups",
+			identity: &authn.Identity{
+				ID:           authn.MustParseNamespaceID("user:1"),
+				ClientParams: authn.ClientParams{SyncTeams: true},
+			},
+			expectedCalls: []*teamsync.SyncUserTeamsCommand{
+				{UserID: 1},
+			},
+		},
+		{
+			desc: "should not sync teams when the client does not request it",
+			identity: &authn.Identity{
+				ID:     authn.MustParseNamespaceID("user:1"),
+				Groups: []string{"cn=editors,ou=groups,dc=grafana,dc=org"},
+			},
+		},
+		{
+			desc: "should not sync teams of service accounts",
+			identity: &authn.Identity{
+				ID:           authn.MustParseNamespaceID("service-account:1"),
+				Groups:       []string{"cn=editors,ou=groups,dc=grafana,dc=org"},
+				ClientParams: authn.ClientParams{SyncTeams: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			teamSyncService := teamsynctest.NewFakeService()
+			s := ProvideTeamSync(teamSyncService, tracing.InitializeTracerForTest())
+
+			err := s.SyncTeamsHook(context.Background(), tt.identity, nil)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCalls, teamSyncService.SyncUserTeamsCalls)
+		})
+	}
+}
@@ -5,18 +5,24 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/mfa"
 )
 
-var errDecodingBasicAuthHeader = errutil.BadRequest("basic-auth.invalid-header", errutil.WithPublicMessage("Invalid Basic Auth Header"))
+var (
+	errDecodingBasicAuthHeader = errutil.BadRequest("basic-auth.invalid-header", errutil.WithPublicMessage("Invalid Basic Auth Header"))
+	errBasicAuthMFA            = errutil.Unauthorized("basic-auth.mfa", errutil.WithPublicMessage("Basic authentication is not allowed for users with a second factor"))
+)
 
 var _ authn.ContextAwareClient = new(Basic)
+var _ authn.HookClient = new(Basic)
 
-func ProvideBasic(client authn.PasswordClient) *Basic {
-	return &Basic{client}
+func ProvideBasic(client authn.PasswordClient, mfaService mfa.Service) *Basic {
+	return &Basic{client, mfaService}
 }
 
 type Basic struct {
-	client authn.PasswordClient
+	client     authn.PasswordClient
+	mfaService mfa.Service
 }
 
 func (c *Basic) String() string {
@@ -40,6 +46,27 @@ func (c *Basic) IsEnabled() bool {
 	return true
 }
 
+// Hook rejects users that have or need a second factor, basic auth can't answer a challenge.
+func (c *Basic) Hook(ctx context.Context, identity *authn.Identity, r *authn.Request) error {
+	if !c.mfaService.IsEnabled() || !identity.ID.IsNamespace(authn.NamespaceUser) {
+		return nil
+	}
+
+	userID, err := identity.ID.ParseInt()
+	if err != nil {
+		return err
+	}
+
+	status, err := c.mfaService.GetStatus(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if status.Enabled || status.Required {
+		return errBasicAuthMFA.Errorf("user %d has a second factor", userID)
+	}
+	return nil
+}
+
 func (c *Basic) Test(ctx context.Context, r *authn.Request) bool {
 	if r.HTTPRequest == nil {
 		return false
@@ -9,6 +9,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
 )
 
 func TestBasic_Authenticate(t *testing.T) {
@@ -36,7 +38,7 @@ func TestBasic_Authenticate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideBasic(tt.client)
+			c := ProvideBasic(tt.client, &mfatest.FakeService{})
 
 			identity, err := c.Authenticate(context.Background(), tt.req)
 			if tt.expectedErr != nil {
@@ -89,8 +91,29 @@ func TestBasic_Test(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideBasic(authntest.FakePasswordClient{})
+			c := ProvideBasic(authntest.FakePasswordClient{}, &mfatest.FakeService{})
 			assert.Equal(t, tt.expected, c.Test(context.Background(), tt.req))
 		})
 	}
 }
+
+func TestBasic_Hook(t *testing.T) {
+	tests := []struct {
+		desc        string
+		service     *mfatest.FakeService
+		expectedErr error
+	}{
+		{desc: "should allow users when second factors are disabled", service: &mfatest.FakeService{ExpectedStatus: &mfa.Status{Enabled: true}}},
+		{desc: "should allow users without a second factor", service: &mfatest.FakeService{ExpectedEnabled: true, ExpectedStatus: &mfa.Status{}}},
+		{desc: "should reject users with a second factor", service: &mfatest.FakeService{ExpectedEnabled: true, ExpectedStatus: &mfa.Status{Enabled: true}}, expectedErr: errBasicAuthMFA},
+		{desc: "should reject users that have to enroll a second factor", service: &mfatest.FakeService{ExpectedEnabled: true, ExpectedStatus: &mfa.Status{Required: true}}, expectedErr: errBasicAuthMFA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			c := ProvideBasic(authntest.FakePasswordClient{}, tt.service)
+			err := c.Hook(context.Background(), &authn.Identity{ID: authn.MustParseNamespaceID("user:1")}, &authn.Request{})
+			assert.ErrorIs(t, err, tt.expectedErr)
+		})
+	}
+}
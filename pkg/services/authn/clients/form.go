@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/mfa"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -12,14 +13,15 @@ var (
 	errBadForm = errutil.BadRequest("form-auth.invalid", errutil.WithPublicMessage("bad login data"))
 )
 
-var _ authn.Client = new(Form)
+var _ authn.HookClient = new(Form)
 
-func ProvideForm(client authn.PasswordClient) *Form {
-	return &Form{client}
+func ProvideForm(client authn.PasswordClient, mfaService mfa.Service) *Form {
+	return &Form{client, mfaService}
 }
 
 type Form struct {
-	client authn.PasswordClient
+	client     authn.PasswordClient
+	mfaService mfa.Service
 }
 
 type loginForm struct {
@@ -42,3 +44,29 @@ func (c *Form) Authenticate(ctx context.Context, r *authn.Request) (*authn.Ident
 func (c *Form) IsEnabled() bool {
 	return true
 }
+
+// Hook stops the login when the user has to answer a second factor challenge, the login is completed
+// by the mfa client.
+func (c *Form) Hook(ctx context.Context, identity *authn.Identity, r *authn.Request) error {
+	if !c.mfaService.IsEnabled() || !identity.ID.IsNamespace(authn.NamespaceUser) {
+		return nil
+	}
+
+	userID, err := identity.ID.ParseInt()
+	if err != nil {
+		return err
+	}
+
+	challenge, err := c.mfaService.CreateChallenge(ctx, &mfa.CreateChallengeCommand{
+		UserID:     userID,
+		Login:      identity.Login,
+		AuthModule: identity.AuthenticatedBy,
+	})
+	if err != nil {
+		return err
+	}
+	if challenge != nil {
+		return mfa.RequiredError(challenge)
+	}
+	return nil
+}
@@ -8,9 +8,13 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/services/authn"
 	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
 )
 
 func TestForm_Authenticate(t *testing.T) {
@@ -40,9 +44,32 @@ func TestForm_Authenticate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
-			c := ProvideForm(&authntest.FakePasswordClient{})
+			c := ProvideForm(&authntest.FakePasswordClient{}, &mfatest.FakeService{})
 			_, err := c.Authenticate(context.Background(), tt.req)
 			assert.ErrorIs(t, err, tt.expectedErr)
 		})
 	}
 }
+
+func TestForm_Hook(t *testing.T) {
+	identity := &authn.Identity{ID: authn.MustParseNamespaceID("user:1"), Login: "test", AuthenticatedBy: "password"}
+
+	t.Run("should complete login when no challenge is needed", func(t *testing.T) {
+		c := ProvideForm(&authntest.FakePasswordClient{}, &mfatest.FakeService{ExpectedEnabled: true})
+		assert.NoError(t, c.Hook(context.Background(), identity, &authn.Request{}))
+	})
+
+	t.Run("should return the challenge when a second factor is needed", func(t *testing.T) {
+		c := ProvideForm(&authntest.FakePasswordClient{}, &mfatest.FakeService{
+			ExpectedEnabled:   true,
+			ExpectedChallenge: &mfa.Challenge{ID: "challenge", Methods: []mfa.FactorType{mfa.FactorTypeTOTP}},
+		})
+		err := c.Hook(context.Background(), identity, &authn.Request{})
+
+		var gfErr errutil.Error
+		require.ErrorAs(t, err, &gfErr)
+		assert.Equal(t, "mfa.required", gfErr.MessageID)
+		assert.Equal(t, "challenge", gfErr.PublicPayload["challengeId"])
+		assert.Equal(t, []mfa.FactorType{mfa.FactorTypeTOTP}, gfErr.PublicPayload["methods"])
+	})
+}
@@ -27,6 +27,7 @@ func (s *CorrelationsService) registerAPIEndpoints() {
 			entities.Get("/", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.getCorrelationHandler))
 			entities.Delete("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.deleteHandler))
 			entities.Patch("/", authorize(ac.EvalPermission(datasources.ActionWrite, uidScope)), routing.Wrap(s.updateHandler))
+			entities.Post("/resolve", authorize(ac.EvalPermission(datasources.ActionRead)), routing.Wrap(s.resolveHandler))
 		})
 	}, middleware.ReqSignedIn)
 }
@@ -352,3 +353,58 @@ type GetCorrelationsResponse struct {
 	// in: body
 	Body []Correlation `json:"body"`
 }
+
+// swagger:route POST /datasources/uid/{sourceUID}/correlations/{correlationUID}/resolve correlations resolveCorrelation
+//
+// Resolves a correlation with the data of a row of the source data source.
+//
+// Responses:
+// 200: resolveCorrelationResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 404: notFoundError
+// 500: internalServerError
+func (s *CorrelationsService) resolveHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := ResolveCorrelationCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UID = web.Params(c.Req)[":correlationUID"]
+	cmd.SourceUID = web.Params(c.Req)[":uid"]
+	cmd.OrgId = c.SignedInUser.GetOrgID()
+
+	resolved, err := s.ResolveCorrelation(c.Req.Context(), cmd)
+	if err != nil {
+		if errors.Is(err, ErrCorrelationNotFound) {
+			return response.Error(http.StatusNotFound, "Correlation not found", err)
+		}
+		if errors.Is(err, ErrSourceDataSourceDoesNotExists) {
+			return response.Error(http.StatusNotFound, "Source data source not found", err)
+		}
+		if errors.Is(err, ErrCorrelationMissingVariable) {
+			return response.Error(http.StatusBadRequest, "Failed to resolve correlation", err)
+		}
+
+		return response.Error(http.StatusInternalServerError, "Failed to resolve correlation", err)
+	}
+
+	return response.JSON(http.StatusOK, resolved)
+}
+
+// swagger:parameters resolveCorrelation
+type ResolveCorrelationParams struct {
+	// in:path
+	// required:true
+	DatasourceUID string `json:"sourceUID"`
+	// in:path
+	// required:true
+	CorrelationUID string `json:"correlationUID"`
+	// in: body
+	Body ResolveCorrelationCommand `json:"body"`
+}
+
+// swagger:response resolveCorrelationResponse
+type ResolveCorrelationResponse struct {
+	// in: body
+	Body ResolvedCorrelation `json:"body"`
+}
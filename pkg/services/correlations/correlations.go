@@ -2,6 +2,7 @@ package correlations
 
 import (
 	"context"
+	"errors"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/bus"
@@ -55,6 +56,7 @@ type Service interface {
 	DeleteCorrelation(ctx context.Context, cmd DeleteCorrelationCommand) error
 	DeleteCorrelationsBySourceUID(ctx context.Context, cmd DeleteCorrelationsBySourceUIDCommand) error
 	DeleteCorrelationsByTargetUID(ctx context.Context, cmd DeleteCorrelationsByTargetUIDCommand) error
+	ResolveCorrelationsBySourceUID(ctx context.Context, query ResolveCorrelationsBySourceUIDQuery) ([]ResolvedCorrelation, error)
 }
 
 type CorrelationsService struct {
@@ -111,6 +113,43 @@ func (s CorrelationsService) DeleteCorrelationsByTargetUID(ctx context.Context,
 	return s.deleteCorrelationsByTargetUID(ctx, cmd)
 }
 
+func (s CorrelationsService) ResolveCorrelation(ctx context.Context, cmd ResolveCorrelationCommand) (ResolvedCorrelation, error) {
+	correlation, err := s.getCorrelation(ctx, GetCorrelationQuery{
+		UID:       cmd.UID,
+		SourceUID: cmd.SourceUID,
+		OrgId:     cmd.OrgId,
+	})
+	if err != nil {
+		return ResolvedCorrelation{}, err
+	}
+	return correlation.Resolve(cmd.Values, cmd.Labels)
+}
+
+// ResolveCorrelationsBySourceUID resolves all correlations originating from a
+// data source, skipping the ones referencing variables that are not defined,
+// so that links can be added to alert notifications.
+func (s CorrelationsService) ResolveCorrelationsBySourceUID(ctx context.Context, query ResolveCorrelationsBySourceUIDQuery) ([]ResolvedCorrelation, error) {
+	correlations, err := s.getCorrelationsBySourceUID(ctx, GetCorrelationsBySourceUIDQuery{
+		SourceUID: query.SourceUID,
+		OrgId:     query.OrgId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	resolved := make([]ResolvedCorrelation, 0, len(correlations))
+	for _, correlation := range correlations {
+		r, err := correlation.Resolve(query.Values, query.Labels)
+		if err != nil {
+			if errors.Is(err, ErrCorrelationMissingVariable) {
+				continue
+			}
+			return nil, err
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
 func (s CorrelationsService) handleDatasourceDeletion(ctx context.Context, event *events.DataSourceDeleted) error {
 	return s.SQLStore.InTransaction(ctx, func(ctx context.Context) error {
 		if err := s.deleteCorrelationsBySourceUID(ctx, DeleteCorrelationsBySourceUIDCommand{
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/services/quota"
 )
@@ -18,6 +19,12 @@ var (
 	ErrInvalidTransformationType     = errors.New("invalid transformation type")
 	ErrTransformationNotNested       = errors.New("transformations must be nested under config")
 	ErrTransformationRegexReqExp     = errors.New("regex transformations require expression")
+	ErrTransformationJSONPathReqExp  = errors.New("jsonpath transformations require expression")
+	ErrTransformationLabelReqExp     = errors.New("label transformations require expression")
+	ErrExternalCorrelationReqURL     = errors.New("external correlations require a target url")
+	ErrExternalCorrelationTargetUID  = errors.New("external correlations cannot have a targetUID")
+	ErrExternalCorrelationURLScheme  = errors.New("external correlations require an http or https url")
+	ErrCorrelationMissingVariable    = errors.New("correlation variable is not defined")
 	ErrCorrelationsQuotaFailed       = errors.New("error getting correlations quota")
 	ErrCorrelationsQuotaReached      = errors.New("correlations quota reached")
 )
@@ -30,7 +37,7 @@ const (
 type CorrelationConfigType string
 
 type Transformation struct {
-	//Enum: regex,logfmt
+	//Enum: regex,logfmt,jsonpath,label
 	Type       string `json:"type"`
 	Expression string `json:"expression,omitempty"`
 	Field      string `json:"field,omitempty"`
@@ -39,10 +46,20 @@ type Transformation struct {
 
 const (
 	ConfigTypeQuery CorrelationConfigType = "query"
+	// ConfigTypeExternal correlations link to a URL built from the template in
+	// the "url" property of the target.
+	ConfigTypeExternal CorrelationConfigType = "external"
+)
+
+const (
+	TransformationTypeRegex    = "regex"
+	TransformationTypeLogfmt   = "logfmt"
+	TransformationTypeJSONPath = "jsonpath"
+	TransformationTypeLabel    = "label"
 )
 
 func (t CorrelationConfigType) Validate() error {
-	if t != ConfigTypeQuery {
+	if t != ConfigTypeQuery && t != ConfigTypeExternal {
 		return fmt.Errorf("%s: \"%s\"", ErrInvalidConfigType, t)
 	}
 	return nil
@@ -50,10 +67,25 @@ func (t CorrelationConfigType) Validate() error {
 
 func (t Transformations) Validate() error {
 	for _, v := range t {
-		if v.Type != "regex" && v.Type != "logfmt" {
+		switch v.Type {
+		case TransformationTypeRegex:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
+			}
+		case TransformationTypeLogfmt:
+		case TransformationTypeJSONPath:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationJSONPathReqExp, t)
+			}
+			if _, err := parseJSONPath(v.Expression); err != nil {
+				return err
+			}
+		case TransformationTypeLabel:
+			if len(v.Expression) == 0 {
+				return fmt.Errorf("%s: \"%s\"", ErrTransformationLabelReqExp, t)
+			}
+		default:
 			return fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, t)
-		} else if v.Type == "regex" && len(v.Expression) == 0 {
-			return fmt.Errorf("%s: \"%s\"", ErrTransformationRegexReqExp, t)
 		}
 	}
 	return nil
@@ -80,19 +112,41 @@ type CorrelationConfig struct {
 	Transformations Transformations `json:"transformations,omitempty"`
 }
 
+// validateTarget checks the target of external correlations has a valid URL
+// template. The scheme must be http or https, and can't come from a variable.
+func (c CorrelationConfig) validateTarget() error {
+	if c.Type != ConfigTypeExternal {
+		return nil
+	}
+	url, _ := c.Target["url"].(string)
+	if url == "" {
+		return ErrExternalCorrelationReqURL
+	}
+	lower := strings.ToLower(url)
+	if !strings.HasPrefix(lower, "http://") && !strings.HasPrefix(lower, "https://") {
+		return ErrExternalCorrelationURLScheme
+	}
+	_, err := templateVariables(url)
+	return err
+}
+
 func (c CorrelationConfig) MarshalJSON() ([]byte, error) {
 	target := c.Target
 	transformations := c.Transformations
+	configType := c.Type
 	if target == nil {
 		target = map[string]any{}
 	}
+	if configType == "" {
+		configType = ConfigTypeQuery
+	}
 	return json.Marshal(struct {
 		Type            CorrelationConfigType `json:"type"`
 		Field           string                `json:"field"`
 		Target          map[string]any        `json:"target"`
 		Transformations Transformations       `json:"transformations,omitempty"`
 	}{
-		Type:            ConfigTypeQuery,
+		Type:            configType,
 		Field:           c.Field,
 		Target:          target,
 		Transformations: transformations,
@@ -169,6 +223,12 @@ func (c CreateCorrelationCommand) Validate() error {
 	if c.TargetUID == nil && c.Config.Type == ConfigTypeQuery {
 		return fmt.Errorf("correlations of type \"%s\" must have a targetUID", ConfigTypeQuery)
 	}
+	if c.TargetUID != nil && c.Config.Type == ConfigTypeExternal {
+		return ErrExternalCorrelationTargetUID
+	}
+	if err := c.Config.validateTarget(); err != nil {
+		return err
+	}
 
 	if err := c.Config.Transformations.Validate(); err != nil {
 		return err
@@ -219,7 +279,7 @@ func (c CorrelationConfigUpdateDTO) Validate() error {
 		}
 	}
 
-	return nil
+	return Transformations(c.Transformations).Validate()
 }
 
 // UpdateCorrelationCommand is the command for updating a correlation
@@ -41,6 +41,46 @@ func TestCorrelationModels(t *testing.T) {
 			require.Error(t, cmd.Validate())
 		})
 
+		t.Run("Successfully validates an external correlation with a URL template", func(t *testing.T) {
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config: CorrelationConfig{
+					Field:  "field",
+					Target: map[string]any{"url": "https://tickets.example.com/new?service=${service}"},
+					Type:   ConfigTypeExternal,
+				},
+			}
+
+			require.NoError(t, cmd.Validate())
+		})
+
+		t.Run("Fails if an external correlation has no URL or a target UID", func(t *testing.T) {
+			targetUid := "targetUid"
+			cmd := &CreateCorrelationCommand{
+				SourceUID: "some-uid",
+				OrgId:     1,
+				Config: CorrelationConfig{
+					Field:  "field",
+					Target: map[string]any{},
+					Type:   ConfigTypeExternal,
+				},
+			}
+			require.ErrorIs(t, cmd.Validate(), ErrExternalCorrelationReqURL)
+
+			cmd.Config.Target["url"] = "https://tickets.example.com/${service"
+			require.Error(t, cmd.Validate())
+
+			for _, url := range []string{"javascript:alert(document.cookie)", "data:text/html,<script>", "//tickets.example.com/", "${scheme}://tickets.example.com/", "ftp://files.example.com/"} {
+				cmd.Config.Target["url"] = url
+				require.ErrorIs(t, cmd.Validate(), ErrExternalCorrelationURLScheme, url)
+			}
+
+			cmd.Config.Target["url"] = "https://tickets.example.com/"
+			cmd.TargetUID = &targetUid
+			require.ErrorIs(t, cmd.Validate(), ErrExternalCorrelationTargetUID)
+		})
+
 		t.Run("Fails if config type is unknown", func(t *testing.T) {
 			config := &CorrelationConfig{
 				Field:  "field",
@@ -66,6 +106,7 @@ func TestCorrelationModels(t *testing.T) {
 
 			tests := []test{
 				{input: "query", assertion: require.NoError},
+				{input: "external", assertion: require.NoError},
 				{input: "link", assertion: require.Error},
 			}
 
@@ -75,6 +116,31 @@ func TestCorrelationModels(t *testing.T) {
 		})
 	})
 
+	t.Run("Transformations Validate", func(t *testing.T) {
+		type test struct {
+			input     Transformations
+			assertion require.ErrorAssertionFunc
+		}
+
+		tests := []test{
+			{input: Transformations{{Type: "logfmt"}}, assertion: require.NoError},
+			{input: Transformations{{Type: "regex", Expression: "id=(\\w+)"}}, assertion: require.NoError},
+			{input: Transformations{{Type: "regex"}}, assertion: require.Error},
+			{input: Transformations{{Type: "jsonpath", Expression: "$.user.ids[0]"}}, assertion: require.NoError},
+			{input: Transformations{{Type: "jsonpath", Expression: `$["service name"]`}}, assertion: require.NoError},
+			{input: Transformations{{Type: "jsonpath"}}, assertion: require.Error},
+			{input: Transformations{{Type: "jsonpath", Expression: "$.user[0"}}, assertion: require.Error},
+			{input: Transformations{{Type: "jsonpath", Expression: "$"}}, assertion: require.Error},
+			{input: Transformations{{Type: "label", Expression: "namespace"}}, assertion: require.NoError},
+			{input: Transformations{{Type: "label"}}, assertion: require.Error},
+			{input: Transformations{{Type: "unknown"}}, assertion: require.Error},
+		}
+
+		for _, tc := range tests {
+			tc.assertion(t, tc.input.Validate())
+		}
+	})
+
 	t.Run("CorrelationConfig JSON Marshaling", func(t *testing.T) {
 		t.Run("Applies a default empty object if target is not defined", func(t *testing.T) {
 			config := CorrelationConfig{
@@ -87,5 +153,18 @@ func TestCorrelationModels(t *testing.T) {
 
 			require.Equal(t, `{"type":"query","field":"field","target":{}}`, string(data))
 		})
+
+		t.Run("Keeps the config type", func(t *testing.T) {
+			config := CorrelationConfig{
+				Field:  "field",
+				Type:   ConfigTypeExternal,
+				Target: map[string]any{"url": "https://example.com"},
+			}
+
+			data, err := json.Marshal(config)
+			require.NoError(t, err)
+
+			require.Equal(t, `{"type":"external","field":"field","target":{"url":"https://example.com"}}`, string(data))
+		})
 	})
 }
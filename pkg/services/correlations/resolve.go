@@ -0,0 +1,409 @@
+package correlations
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ResolveCorrelationCommand is the command for resolving a correlation with
+// the data of a row of the source data source
+// swagger:model
+type ResolveCorrelationCommand struct {
+	// UID of the correlation to be resolved.
+	UID       string `json:"-"`
+	SourceUID string `json:"-"`
+	OrgId     int64  `json:"-"`
+	// Values of the fields of the source row by field name
+	// example: {"message":"level=error traceID=abc123","service":"checkout"}
+	Values map[string]string `json:"values"`
+	// Labels of the source data
+	// example: {"namespace":"prod"}
+	Labels map[string]string `json:"labels"`
+}
+
+// ResolveCorrelationsBySourceUIDQuery is the query for resolving all correlations
+// originating from a data source, for example with the labels of an alert.
+type ResolveCorrelationsBySourceUIDQuery struct {
+	SourceUID string
+	OrgId     int64
+	Values    map[string]string
+	Labels    map[string]string
+}
+
+// ResolvedCorrelation is a correlation with variables interpolated.
+// swagger:model
+type ResolvedCorrelation struct {
+	// Unique identifier of the correlation
+	// example: 50xhMlg9k
+	UID string `json:"uid"`
+	// UID of the data source the correlation originates from
+	// example: d0oxYRg4z
+	SourceUID string `json:"sourceUID"`
+	// UID of the data source the correlation points to
+	// example: PE1C5CBDA0504A6A3
+	TargetUID *string `json:"targetUID,omitempty"`
+	// Label identifying the correlation
+	// example: My Label
+	Label string `json:"label"`
+	// Description of the correlation
+	// example: Logs to Traces
+	Description string `json:"description"`
+	// Target type
+	Type CorrelationConfigType `json:"type"`
+	// URL of an external correlation
+	// example: https://tickets.example.com/new?service=checkout
+	URL string `json:"url,omitempty"`
+	// Target data query of a query correlation
+	// example: {"expr":"{service=\"checkout\"}"}
+	Target map[string]any `json:"target,omitempty"`
+}
+
+// Resolve applies the transformations of a correlation to the data of a row
+// of the source data source and interpolates the resulting variables in the
+// target of the correlation. Variables are referenced as ${name}.
+func (c Correlation) Resolve(values map[string]string, labels map[string]string) (ResolvedCorrelation, error) {
+	vars, err := c.Config.Transformations.apply(c.Config.Field, values, labels)
+	if err != nil {
+		return ResolvedCorrelation{}, err
+	}
+
+	resolved := ResolvedCorrelation{
+		UID:         c.UID,
+		SourceUID:   c.SourceUID,
+		TargetUID:   c.TargetUID,
+		Label:       c.Label,
+		Description: c.Description,
+		Type:        c.Config.Type,
+	}
+	if resolved.Type == "" {
+		resolved.Type = ConfigTypeQuery
+	}
+
+	switch resolved.Type {
+	case ConfigTypeExternal:
+		template, _ := c.Config.Target["url"].(string)
+		if template == "" {
+			return ResolvedCorrelation{}, ErrExternalCorrelationReqURL
+		}
+		resolved.URL, err = interpolate(template, vars, escapeURLValue)
+		if err != nil {
+			return ResolvedCorrelation{}, err
+		}
+	default:
+		target, err := interpolateValue(c.Config.Target, vars)
+		if err != nil {
+			return ResolvedCorrelation{}, err
+		}
+		resolved.Target, _ = target.(map[string]any)
+	}
+	return resolved, nil
+}
+
+// apply returns the variables produced by the transformations. Variables
+// start with the values of the fields. Transformations read the value of
+// their field, or of the correlation field when not set.
+func (t Transformations) apply(defaultField string, values map[string]string, labels map[string]string) (map[string]string, error) {
+	vars := make(map[string]string, len(values))
+	for name, value := range values {
+		vars[name] = value
+	}
+
+	for _, transformation := range t {
+		field := transformation.Field
+		if field == "" {
+			field = defaultField
+		}
+		switch transformation.Type {
+		case TransformationTypeRegex:
+			re, err := regexp.Compile(transformation.Expression)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex transformation expression: %w", err)
+			}
+			match := re.FindStringSubmatch(vars[field])
+			if match == nil {
+				continue
+			}
+			// Named capture groups are mapped to variables matching the group name.
+			named := false
+			for i, name := range re.SubexpNames() {
+				if name != "" {
+					vars[name] = match[i]
+					named = true
+				}
+			}
+			if named {
+				continue
+			}
+			value := match[0]
+			if len(match) > 1 {
+				value = match[1]
+			}
+			vars[variableName(transformation.MapValue, field)] = value
+		case TransformationTypeLogfmt:
+			for key, value := range parseLogfmt(vars[field]) {
+				vars[key] = value
+			}
+		case TransformationTypeJSONPath:
+			path, err := parseJSONPath(transformation.Expression)
+			if err != nil {
+				return nil, err
+			}
+			var doc any
+			if err := json.Unmarshal([]byte(vars[field]), &doc); err != nil {
+				continue
+			}
+			value, ok := path.lookup(doc)
+			if !ok {
+				continue
+			}
+			vars[variableName(transformation.MapValue, path.name())] = stringValue(value)
+		case TransformationTypeLabel:
+			value, ok := labels[transformation.Expression]
+			if !ok {
+				continue
+			}
+			vars[variableName(transformation.MapValue, transformation.Expression)] = value
+		default:
+			return nil, fmt.Errorf("%s: \"%s\"", ErrInvalidTransformationType, transformation.Type)
+		}
+	}
+	return vars, nil
+}
+
+func variableName(mapValue string, fallback string) string {
+	if mapValue != "" {
+		return mapValue
+	}
+	return fallback
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case nil:
+		return ""
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
+
+var templateVariablePattern = regexp.MustCompile(`\$\{([^}]*)\}`)
+
+// templateVariables returns the names of the variables referenced in a template.
+func templateVariables(template string) ([]string, error) {
+	var names []string
+	for _, match := range templateVariablePattern.FindAllStringSubmatch(template, -1) {
+		name := strings.TrimSpace(match[1])
+		if name == "" {
+			return nil, fmt.Errorf("empty variable name in %q", template)
+		}
+		names = append(names, name)
+	}
+	if strings.Count(templateVariablePattern.ReplaceAllString(template, ""), "${") > 0 {
+		return nil, fmt.Errorf("unterminated variable in %q", template)
+	}
+	return names, nil
+}
+
+func escapeURLValue(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+// interpolate replaces the variables of a template with their values.
+func interpolate(template string, vars map[string]string, escape func(string) string) (string, error) {
+	if _, err := templateVariables(template); err != nil {
+		return "", err
+	}
+	var missing []string
+	result := templateVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := strings.TrimSpace(match[2 : len(match)-1])
+		value, ok := vars[name]
+		if !ok {
+			missing = append(missing, name)
+			return match
+		}
+		if escape != nil {
+			return escape(value)
+		}
+		return value
+	})
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrCorrelationMissingVariable, strings.Join(missing, ", "))
+	}
+	return result, nil
+}
+
+// interpolateValue interpolates the variables in all strings of a JSON value.
+func interpolateValue(value any, vars map[string]string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return interpolate(v, vars, nil)
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, item := range v {
+			interpolated, err := interpolateValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = interpolated
+		}
+		return result, nil
+	case []any:
+		result := make([]any, len(v))
+		for i, item := range v {
+			interpolated, err := interpolateValue(item, vars)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = interpolated
+		}
+		return result, nil
+	default:
+		return v, nil
+	}
+}
+
+// parseLogfmt returns the key value pairs of a logfmt formatted line.
+func parseLogfmt(line string) map[string]string {
+	pairs := map[string]string{}
+	for i := 0; i < len(line); {
+		for i < len(line) && line[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(line) && line[i] != '=' && line[i] != ' ' {
+			i++
+		}
+		key := line[start:i]
+		if i >= len(line) || line[i] != '=' {
+			if key != "" {
+				pairs[key] = ""
+			}
+			continue
+		}
+		i++
+		var value string
+		if i < len(line) && line[i] == '"' {
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				end = len(line) - 1
+			}
+			quoted := line[i : end+1]
+			if unquoted, err := strconv.Unquote(quoted); err == nil {
+				value = unquoted
+			} else {
+				value = strings.Trim(quoted, `"`)
+			}
+			i = end + 1
+		} else {
+			start := i
+			for i < len(line) && line[i] != ' ' {
+				i++
+			}
+			value = line[start:i]
+		}
+		if key != "" {
+			pairs[key] = value
+		}
+	}
+	return pairs
+}
+
+// jsonPath is a path to a value of a JSON document, for example $.user.ids[0]
+// or $["service name"].
+type jsonPath []any
+
+func parseJSONPath(expression string) (jsonPath, error) {
+	invalid := fmt.Errorf("invalid jsonpath expression %q", expression)
+	p := strings.TrimSpace(expression)
+	p = strings.TrimPrefix(p, "$")
+	var path jsonPath
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, invalid
+			}
+			path = append(path, p[:end])
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end < 0 {
+				return nil, invalid
+			}
+			segment := strings.TrimSpace(p[1:end])
+			p = p[end+1:]
+			if index, err := strconv.Atoi(segment); err == nil {
+				if index < 0 {
+					return nil, invalid
+				}
+				path = append(path, index)
+				continue
+			}
+			if len(segment) < 2 || (segment[0] != '"' && segment[0] != '\'') || segment[len(segment)-1] != segment[0] {
+				return nil, invalid
+			}
+			path = append(path, segment[1:len(segment)-1])
+		default:
+			if len(path) > 0 {
+				return nil, invalid
+			}
+			// Paths without the leading $. are accepted, for example user.id.
+			p = "." + p
+		}
+	}
+	if len(path) == 0 {
+		return nil, invalid
+	}
+	return path, nil
+}
+
+func (p jsonPath) lookup(doc any) (any, bool) {
+	value := doc
+	for _, segment := range p {
+		switch s := segment.(type) {
+		case string:
+			object, ok := value.(map[string]any)
+			if !ok {
+				return nil, false
+			}
+			if value, ok = object[s]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := value.([]any)
+			if !ok || s >= len(array) {
+				return nil, false
+			}
+			value = array[s]
+		}
+	}
+	return value, true
+}
+
+// name returns the last key of the path.
+func (p jsonPath) name() string {
+	for i := len(p) - 1; i >= 0; i-- {
+		if key, ok := p[i].(string); ok {
+			return key
+		}
+	}
+	return "value"
+}
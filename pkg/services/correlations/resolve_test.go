@@ -0,0 +1,139 @@
+package correlations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorrelationResolve(t *testing.T) {
+	targetUID := "tempo"
+
+	t.Run("Resolves external correlations to URLs with escaped values", func(t *testing.T) {
+		correlation := Correlation{
+			UID:       "runbook",
+			SourceUID: "loki",
+			Label:     "Open ticket",
+			Config: CorrelationConfig{
+				Type:  ConfigTypeExternal,
+				Field: "message",
+				Target: map[string]any{
+					"url": "https://tickets.example.com/new?title=${level} in ${service}&ns=${ns}&user=${user}",
+				},
+				Transformations: Transformations{
+					{Type: TransformationTypeLogfmt},
+					{Type: TransformationTypeLabel, Expression: "namespace", MapValue: "ns"},
+					{Type: TransformationTypeJSONPath, Field: "payload", Expression: "$.user.name", MapValue: "user"},
+				},
+			},
+		}
+
+		resolved, err := correlation.Resolve(map[string]string{
+			"message": `level=error service="checkout api" msg="payment failed"`,
+			"payload": `{"user": {"name": "Jane Doe"}}`,
+		}, map[string]string{"namespace": "prod&eu"})
+		require.NoError(t, err)
+
+		require.Equal(t, ResolvedCorrelation{
+			UID:       "runbook",
+			SourceUID: "loki",
+			Label:     "Open ticket",
+			Type:      ConfigTypeExternal,
+			URL:       "https://tickets.example.com/new?title=error in checkout%20api&ns=prod%26eu&user=Jane%20Doe",
+		}, resolved)
+	})
+
+	t.Run("Resolves query correlations by interpolating the target", func(t *testing.T) {
+		correlation := Correlation{
+			UID:       "traces",
+			SourceUID: "loki",
+			TargetUID: &targetUID,
+			Config: CorrelationConfig{
+				Type:  ConfigTypeQuery,
+				Field: "message",
+				Target: map[string]any{
+					"query":   "${traceId}",
+					"filters": []any{map[string]any{"value": "${id}"}},
+					"limit":   float64(20),
+				},
+				Transformations: Transformations{
+					{Type: TransformationTypeRegex, Expression: `traceID=(\w+)`, MapValue: "traceId"},
+					{Type: TransformationTypeJSONPath, Field: "payload", Expression: "items[1].id", MapValue: "id"},
+				},
+			},
+		}
+
+		resolved, err := correlation.Resolve(map[string]string{
+			"message": "level=info traceID=abc123 msg=done",
+			"payload": `{"items": [{"id": 1}, {"id": 2}]}`,
+		}, nil)
+		require.NoError(t, err)
+
+		require.Equal(t, &targetUID, resolved.TargetUID)
+		require.Equal(t, map[string]any{
+			"query":   "abc123",
+			"filters": []any{map[string]any{"value": "2"}},
+			"limit":   float64(20),
+		}, resolved.Target)
+	})
+
+	t.Run("Fails when a variable is not defined", func(t *testing.T) {
+		correlation := Correlation{
+			Config: CorrelationConfig{
+				Type:   ConfigTypeExternal,
+				Field:  "message",
+				Target: map[string]any{"url": "https://example.com/${traceId}"},
+				Transformations: Transformations{
+					{Type: TransformationTypeRegex, Expression: `traceID=(\w+)`, MapValue: "traceId"},
+				},
+			},
+		}
+
+		_, err := correlation.Resolve(map[string]string{"message": "no trace"}, nil)
+		require.ErrorIs(t, err, ErrCorrelationMissingVariable)
+	})
+}
+
+func TestTransformationsApply(t *testing.T) {
+	values := map[string]string{"employee": "John Doe"}
+
+	tests := []struct {
+		name            string
+		transformations Transformations
+		expected        map[string]string
+	}{
+		{
+			name:            "regex without mapValue overrides the field variable",
+			transformations: Transformations{{Type: "regex", Expression: `\w+ (\w+)`}},
+			expected:        map[string]string{"employee": "Doe"},
+		},
+		{
+			name:            "regex with mapValue creates a new variable",
+			transformations: Transformations{{Type: "regex", Expression: `(\w+) (\w+)`, MapValue: "name"}},
+			expected:        map[string]string{"employee": "John Doe", "name": "John"},
+		},
+		{
+			name:            "regex with named groups creates variables for the groups",
+			transformations: Transformations{{Type: "regex", Expression: `(?P<firstName>\w+) (?P<lastName>\w+)`, MapValue: "name"}},
+			expected:        map[string]string{"employee": "John Doe", "firstName": "John", "lastName": "Doe"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			vars, err := tc.transformations.apply("employee", values, nil)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, vars)
+		})
+	}
+}
+
+func TestParseLogfmt(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"level":   "error",
+		"msg":     `said "hi"`,
+		"empty":   "",
+		"flag":    "",
+		"trailer": "x",
+	}, parseLogfmt(`level=error  msg="said \"hi\"" empty= flag trailer=x`))
+}
@@ -206,6 +206,9 @@ type SaveDashboardCommand struct {
 	FolderID  int64  `json:"folderId" xorm:"folder_id"`
 	FolderUID string `json:"folderUid" xorm:"folder_uid"`
 	IsFolder  bool   `json:"isFolder"`
+	// Merge the changes into the current version when the dashboard was
+	// changed by someone else since the version it is based on.
+	Merge bool `json:"merge"`
 
 	UpdatedAt time.Time
 }
@@ -0,0 +1,8 @@
+package templatevars
+
+import "errors"
+
+var (
+	ErrUnknownVariable = errors.New("unknown template variable")
+	ErrInvalidValue    = errors.New("invalid template variable value")
+)
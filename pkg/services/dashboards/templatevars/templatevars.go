@@ -0,0 +1,353 @@
+// Package templatevars resolves dashboard template variables and interpolates
+// them into queries on the server, following the syntax and formats of the
+// frontend template service.
+package templatevars
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// AllValue is the value stored in a variable's current value when "All" is selected.
+const AllValue = "$__all"
+
+// variableRegex matches $var, [[var]], [[var:format]], ${var}, ${var.field} and ${var:format}.
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?:\.([^:^\}]+))?(?::([^\}]+))?\}`)
+
+var (
+	// regexEscape escapes the same characters as the frontend regex formatter.
+	regexEscape = regexp.MustCompile(`[-[\]{}()*+?.,\\^$|#\s]`)
+	// prometheusRegexEscape and lokiRegexEscape escape the characters of multi-value
+	// variables like the interpolateQueryExpr functions of these data sources.
+	prometheusRegexEscape = regexp.MustCompile(`[$^*{}\[\]'+?.()|]`)
+	lokiRegexEscape       = regexp.MustCompile(`[$^*{}\[\]+?.()|]`)
+	// influxRegexEscape escapes the same characters as escapeRegex of @grafana/data.
+	influxRegexEscape = regexp.MustCompile(`[\\^$*+?.()|[\]{}/]`)
+	// luceneEscape escapes the same characters as the frontend lucene formatter.
+	luceneEscape = regexp.MustCompile(`[!*+\-=<>\s&|()\[\]{}^~?:\\/"]`)
+)
+
+// Variable is a resolved dashboard template variable.
+type Variable struct {
+	Name string
+	Type string
+	// Values are the selected values. When "All" is selected they are expanded to every option.
+	Values []string
+	Multi  bool
+	// IncludeAll and CustomAllValue come from the variable model. A custom all
+	// value is interpolated verbatim instead of the expanded options.
+	IncludeAll     bool
+	CustomAllValue string
+	// Options are the values a viewer could choose from, as stored in the dashboard.
+	Options []string
+
+	all bool
+}
+
+// Variables are the variables of a dashboard indexed by name.
+type Variables map[string]*Variable
+
+// FromDashboard reads the variables of a dashboard model with their saved current values.
+// Ad hoc filters are not query text variables and are skipped.
+func FromDashboard(data *simplejson.Json) Variables {
+	vars := Variables{}
+	for _, obj := range data.GetPath("templating", "list").MustArray() {
+		model := simplejson.NewFromAny(obj)
+		name := model.Get("name").MustString()
+		varType := model.Get("type").MustString()
+		if name == "" || varType == "adhoc" {
+			continue
+		}
+
+		v := &Variable{
+			Name:           name,
+			Type:           varType,
+			Multi:          model.Get("multi").MustBool(),
+			IncludeAll:     model.Get("includeAll").MustBool(),
+			CustomAllValue: model.Get("allValue").MustString(),
+		}
+		for _, opt := range model.Get("options").MustArray() {
+			if value := toStrings(simplejson.NewFromAny(opt).Get("value").Interface()); len(value) > 0 && value[0] != AllValue {
+				v.Options = append(v.Options, value...)
+			}
+		}
+
+		current := toStrings(model.GetPath("current", "value").Interface())
+		if len(current) == 0 && (varType == "constant" || varType == "textbox") {
+			current = toStrings(model.Get("query").Interface())
+		}
+		v.set(current)
+		vars[name] = v
+	}
+	return vars
+}
+
+// Select replaces the current values of a variable. Selecting AllValue selects every option.
+func (vars Variables) Select(name string, values []string) error {
+	v, ok := vars[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownVariable, name)
+	}
+	if len(values) > 1 && !v.Multi {
+		return fmt.Errorf("%w: %s does not allow multiple values", ErrInvalidValue, name)
+	}
+	if isAll(values) && !v.IncludeAll {
+		return fmt.Errorf("%w: %s does not include all", ErrInvalidValue, name)
+	}
+	v.set(values)
+	return nil
+}
+
+// SetConstant adds or replaces a single value variable, such as the built-in
+// __from and __to variables.
+func (vars Variables) SetConstant(name, value string) {
+	vars[name] = &Variable{Name: name, Type: "constant", Values: []string{value}}
+}
+
+// IsAll reports whether "All" is selected.
+func (v *Variable) IsAll() bool {
+	return v.all
+}
+
+func (v *Variable) set(values []string) {
+	v.all = isAll(values)
+	if v.all {
+		v.Values = v.Options
+		return
+	}
+	v.Values = values
+}
+
+// Current returns the current value in the dashboard model format.
+func (v *Variable) Current() map[string]any {
+	if v.all {
+		return map[string]any{"text": []string{"All"}, "value": []string{AllValue}}
+	}
+	if v.Multi {
+		values := v.Values
+		if values == nil {
+			values = []string{}
+		}
+		return map[string]any{"text": values, "value": values}
+	}
+	value := ""
+	if len(v.Values) > 0 {
+		value = v.Values[0]
+	}
+	return map[string]any{"text": value, "value": value}
+}
+
+// Interpolate replaces variable references in text with the default formats of
+// the frontend template service. References to unknown variables are left as they
+// are. See InterpolateFor for the errors.
+func (vars Variables) Interpolate(text string) (string, error) {
+	return vars.InterpolateFor(text, "")
+}
+
+// InterpolateFor replaces variable references in text of a query of the data source
+// type dsType. Values without an explicit format use the default format of the data
+// source, such as a regex for Prometheus or a quoted list for SQL data sources.
+// Variables with "All" selected that have neither a custom all value nor saved
+// options can't be expanded on the server and return ErrInvalidValue.
+func (vars Variables) InterpolateFor(text, dsType string) (string, error) {
+	var err error
+	interpolated := variableRegex.ReplaceAllStringFunc(text, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name, format := groups[1], ""
+		switch {
+		case groups[2] != "":
+			name, format = groups[2], groups[3]
+		case groups[4] != "":
+			name, format = groups[4], groups[6]
+		}
+
+		v, ok := vars[name]
+		if !ok {
+			return match
+		}
+		if v.all && v.CustomAllValue != "" {
+			return v.CustomAllValue
+		}
+		if v.all && len(v.Values) == 0 {
+			// Query variables usually don't save their options, and the server
+			// can't run the variable query, so "All" has nothing to expand to.
+			if err == nil {
+				err = fmt.Errorf("%w: %s has all selected without a custom all value or saved options", ErrInvalidValue, name)
+			}
+			return match
+		}
+		if format == "" {
+			return formatDefault(v, dsType)
+		}
+		return formatValues(v, format)
+	})
+	return interpolated, err
+}
+
+// InterpolateJSON replaces variable references in every string of a JSON object,
+// such as a panel or a panel target, in place. Strings are interpolated with the
+// default format of the data source type of the closest object with a datasource
+// reference, so that the targets of mixed panels use their own data source.
+func (vars Variables) InterpolateJSON(model *simplejson.Json) error {
+	_, err := vars.interpolateValue(model.Interface(), "")
+	return err
+}
+
+func (vars Variables) interpolateValue(value any, dsType string) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return vars.InterpolateFor(v, dsType)
+	case map[string]any:
+		if ref, ok := v["datasource"].(map[string]any); ok {
+			if refType, ok := ref["type"].(string); ok {
+				dsType = refType
+			}
+		}
+		for key, item := range v {
+			interpolated, err := vars.interpolateValue(item, dsType)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = interpolated
+		}
+	case []any:
+		for i, item := range v {
+			interpolated, err := vars.interpolateValue(item, dsType)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = interpolated
+		}
+	}
+	return value, nil
+}
+
+// formatDefault formats values like the interpolation functions the frontend data
+// sources pass to the template service. Other data sources use the glob format.
+func formatDefault(v *Variable, dsType string) string {
+	values := v.Values
+	multi := v.Multi || v.IncludeAll
+	switch dsType {
+	case "prometheus":
+		if !multi {
+			return strings.ReplaceAll(strings.ReplaceAll(strings.Join(values, ","), `\`, `\\`), "'", `\\'`)
+		}
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = prometheusRegexEscape.ReplaceAllString(strings.ReplaceAll(value, `\`, `\\\\`), `\\$0`)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "loki":
+		if !multi {
+			return strings.ReplaceAll(strings.Join(values, ","), "'", `\\'`)
+		}
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = strings.ReplaceAll(lokiRegexEscape.ReplaceAllString(strings.ReplaceAll(value, `\`, `\\\\`), `\\$0`), "'", `\\'`)
+		}
+		return strings.Join(escaped, "|")
+	case "mysql", "postgres", "grafana-postgresql-datasource", "mssql":
+		if !multi {
+			return strings.ReplaceAll(strings.Join(values, ","), "'", "''")
+		}
+		return quoteValues(values, "'", "''")
+	case "influxdb":
+		if !v.Multi {
+			return strings.Join(values, ",")
+		}
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = influxRegexEscape.ReplaceAllString(value, `\$0`)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "elasticsearch":
+		return formatValues(v, "lucene")
+	}
+
+	// glob is the default format for multiple values
+	if (v.Multi || v.all) && len(values) != 1 {
+		return "{" + strings.Join(values, ",") + "}"
+	}
+	return strings.Join(values, ",")
+}
+
+func formatValues(v *Variable, format string) string {
+	values := v.Values
+	switch format {
+	case "raw", "csv":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		escaped := make([]string, len(values))
+		for i, value := range values {
+			escaped[i] = regexEscape.ReplaceAllString(value, `\$0`)
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quoteValues(values, "'", `\'`)
+	case "doublequote":
+		return quoteValues(values, `"`, `\"`)
+	case "sqlstring":
+		return quoteValues(values, "'", "''")
+	case "lucene":
+		if !v.Multi && !v.all {
+			return luceneEscape.ReplaceAllString(strings.Join(values, ","), `\$0`)
+		}
+		quoted := make([]string, len(values))
+		for i, value := range values {
+			quoted[i] = `"` + luceneEscape.ReplaceAllString(value, `\$0`) + `"`
+		}
+		return "(" + strings.Join(quoted, " OR ") + ")"
+	case "queryparam":
+		params := make([]string, len(values))
+		for i, value := range values {
+			params[i] = "var-" + v.Name + "=" + value
+		}
+		return strings.Join(params, "&")
+	}
+	return formatDefault(v, "")
+}
+
+func quoteValues(values []string, quote, escapedQuote string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote + strings.ReplaceAll(value, quote, escapedQuote) + quote
+	}
+	return strings.Join(quoted, ",")
+}
+
+func isAll(values []string) bool {
+	return len(values) == 1 && values[0] == AllValue
+}
+
+func toStrings(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	default:
+		return []string{fmt.Sprint(v)}
+	}
+}
@@ -0,0 +1,198 @@
+package templatevars
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+func testDashboard(t *testing.T) *simplejson.Json {
+	t.Helper()
+	data, err := simplejson.NewJson([]byte(`{
+		"templating": {
+			"list": [
+				{
+					"name": "job",
+					"type": "query",
+					"multi": true,
+					"includeAll": true,
+					"current": {"text": ["api"], "value": ["api"]},
+					"options": [
+						{"text": "All", "value": "$__all"},
+						{"text": "api", "value": "api"},
+						{"text": "web", "value": "web"}
+					]
+				},
+				{
+					"name": "env",
+					"type": "custom",
+					"current": {"text": "prod", "value": "prod"},
+					"options": [{"text": "prod", "value": "prod"}, {"text": "dev", "value": "dev"}]
+				},
+				{
+					"name": "region",
+					"type": "custom",
+					"includeAll": true,
+					"allValue": ".*",
+					"current": {"text": "All", "value": "$__all"}
+				},
+				{"name": "prefix", "type": "constant", "query": "app_"},
+				{"name": "filters", "type": "adhoc"}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	return data
+}
+
+func TestFromDashboard(t *testing.T) {
+	vars := FromDashboard(testDashboard(t))
+
+	require.Len(t, vars, 4)
+	require.Equal(t, []string{"api"}, vars["job"].Values)
+	require.Equal(t, []string{"api", "web"}, vars["job"].Options)
+	require.Equal(t, []string{"prod"}, vars["env"].Values)
+	require.True(t, vars["region"].IsAll())
+	require.Equal(t, []string{"app_"}, vars["prefix"].Values)
+	require.NotContains(t, vars, "filters")
+}
+
+func TestSelect(t *testing.T) {
+	vars := FromDashboard(testDashboard(t))
+
+	require.NoError(t, vars.Select("job", []string{AllValue}))
+	require.True(t, vars["job"].IsAll())
+	require.Equal(t, []string{"api", "web"}, vars["job"].Values)
+	require.Equal(t, map[string]any{"text": []string{"All"}, "value": []string{AllValue}}, vars["job"].Current())
+
+	require.NoError(t, vars.Select("env", []string{"dev"}))
+	require.Equal(t, map[string]any{"text": "dev", "value": "dev"}, vars["env"].Current())
+
+	require.ErrorIs(t, vars.Select("env", []string{"dev", "prod"}), ErrInvalidValue)
+	require.ErrorIs(t, vars.Select("env", []string{AllValue}), ErrInvalidValue)
+	require.ErrorIs(t, vars.Select("missing", []string{"x"}), ErrUnknownVariable)
+}
+
+func TestInterpolate(t *testing.T) {
+	vars := FromDashboard(testDashboard(t))
+	require.NoError(t, vars.Select("job", []string{"api", "web.1"}))
+	vars.SetConstant("__from", "1000")
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{`up{env="$env"}`, `up{env="prod"}`},
+		{`up{env="${env}"}`, `up{env="prod"}`},
+		{`up{env="[[env]]"}`, `up{env="prod"}`},
+		{`${prefix}requests`, `app_requests`},
+		{`job=~"$job"`, `job=~"{api,web.1}"`},
+		{`job=~"${job:regex}"`, `job=~"(api|web\.1)"`},
+		{`job=~"${job:pipe}"`, `job=~"api|web.1"`},
+		{`job IN (${job:sqlstring})`, `job IN ('api','web.1')`},
+		{`${job:csv}`, `api,web.1`},
+		{`${job:doublequote}`, `"api","web.1"`},
+		{`${job:lucene}`, `("api" OR "web.1")`},
+		{`[[job:queryparam]]`, `var-job=api&var-job=web.1`},
+		{`region=~"$region"`, `region=~".*"`},
+		{`$__from $__interval $unknown`, `1000 $__interval $unknown`},
+	}
+	for _, tt := range tests {
+		interpolated, err := vars.Interpolate(tt.text)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, interpolated, tt.text)
+	}
+}
+
+func TestInterpolateFor(t *testing.T) {
+	vars := FromDashboard(testDashboard(t))
+	require.NoError(t, vars.Select("job", []string{"api", "web.1", "it's"}))
+
+	tests := []struct {
+		dsType   string
+		text     string
+		expected string
+	}{
+		{"prometheus", `up{job=~"$job", env="$env"}`, `up{job=~"(api|web\\.1|it\\'s)", env="prod"}`},
+		{"loki", `{job=~"$job", env="$env"}`, `{job=~"api|web\\.1|it\\'s", env="prod"}`},
+		{"mysql", `WHERE job IN ($job) AND env = '$env'`, `WHERE job IN ('api','web.1','it''s') AND env = 'prod'`},
+		{"grafana-postgresql-datasource", `WHERE job IN ($job)`, `WHERE job IN ('api','web.1','it''s')`},
+		{"influxdb", `WHERE "job" =~ /^$job$/`, `WHERE "job" =~ /^(api|web\.1|it's)$/`},
+		{"elasticsearch", `job:$job AND env:$env`, `job:("api" OR "web.1" OR "it's") AND env:prod`},
+		{"graphite", `apps.$job.requests`, `apps.{api,web.1,it's}.requests`},
+		// an explicit format is used for every data source
+		{"prometheus", `${job:csv}`, `api,web.1,it's`},
+	}
+	for _, tt := range tests {
+		interpolated, err := vars.InterpolateFor(tt.text, tt.dsType)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, interpolated, tt.dsType)
+	}
+}
+
+func TestInterpolateAllWithoutOptions(t *testing.T) {
+	data, err := simplejson.NewJson([]byte(`{
+		"templating": {
+			"list": [
+				{
+					"name": "job",
+					"type": "query",
+					"multi": true,
+					"includeAll": true,
+					"current": {"text": ["All"], "value": ["$__all"]},
+					"options": []
+				},
+				{
+					"name": "instance",
+					"type": "query",
+					"includeAll": true,
+					"allValue": ".+",
+					"current": {"text": ["All"], "value": ["$__all"]}
+				}
+			]
+		}
+	}`))
+	require.NoError(t, err)
+	vars := FromDashboard(data)
+
+	require.True(t, vars["job"].IsAll())
+	require.Empty(t, vars["job"].Values)
+	_, err = vars.Interpolate(`up{job=~"$job"}`)
+	require.ErrorIs(t, err, ErrInvalidValue)
+	require.ErrorContains(t, err, "job")
+
+	interpolated, err := vars.Interpolate(`up{instance=~"$instance"}`)
+	require.NoError(t, err)
+	require.Equal(t, `up{instance=~".+"}`, interpolated)
+
+	require.NoError(t, vars.Select("job", []string{"api"}))
+	interpolated, err = vars.Interpolate(`up{job=~"$job"}`)
+	require.NoError(t, err)
+	require.Equal(t, `up{job=~"api"}`, interpolated)
+}
+
+func TestInterpolateJSON(t *testing.T) {
+	vars := FromDashboard(testDashboard(t))
+	require.NoError(t, vars.Select("job", []string{"api", "web"}))
+	panel, err := simplejson.NewJson([]byte(`{
+		"datasource": {"type": "datasource", "uid": "-- Mixed --"},
+		"title": "$env",
+		"targets": [
+			{"refId": "A", "datasource": {"type": "prometheus", "uid": "prom"}, "expr": "up{job=~\"$job\"}", "hide": false, "nested": {"list": ["$env", 1]}},
+			{"refId": "B", "datasource": {"type": "mysql", "uid": "sql"}, "rawSql": "SELECT * FROM t WHERE job IN ($job)"}
+		]
+	}`))
+	require.NoError(t, err)
+
+	require.NoError(t, vars.InterpolateJSON(panel))
+
+	require.Equal(t, "prod", panel.Get("title").MustString())
+	query := panel.Get("targets").GetIndex(0)
+	require.Equal(t, `up{job=~"(api|web)"}`, query.Get("expr").MustString())
+	require.Equal(t, "prod", query.GetPath("nested", "list").GetIndex(0).MustString())
+	require.Equal(t, 1, query.GetPath("nested", "list").GetIndex(1).MustInt())
+	require.Equal(t, "A", query.Get("refId").MustString())
+	require.Equal(t, "SELECT * FROM t WHERE job IN ('api','web')", panel.Get("targets").GetIndex(1).Get("rawSql").MustString())
+}
@@ -0,0 +1,468 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	common "github.com/grafana/grafana/pkg/apimachinery/apis/common/v0alpha1"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	dashboardsnapshot "github.com/grafana/grafana/pkg/apis/dashboardsnapshot/v0alpha1"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/expr"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/templatevars"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// snapshotColumnWidth is the width in pixels of a grid column on a 1920px wide
+// screen, used to pick the number of data points of panels without maxDataPoints.
+const snapshotColumnWidth = 1920 / 24
+
+var errInvalidTimeRange = errors.New("invalid time range")
+
+// RenderDashboardSnapshotCommand creates a snapshot of a saved dashboard with
+// the data returned by its queries.
+type RenderDashboardSnapshotCommand struct {
+	DashboardUID string `json:"dashboardUid"`
+	// Snapshot name, defaults to the dashboard title.
+	Name string `json:"name"`
+	// Time range, defaults to the dashboard time range. Accepts relative times like now-6h.
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Timezone string `json:"timezone"`
+	// Template variable values by variable name. Use $__all to select all values.
+	Variables map[string][]string `json:"variables"`
+	// Seconds until the snapshot is deleted, 0 to never expire.
+	Expires int64 `json:"expires"`
+}
+
+type PanelError struct {
+	PanelID int64  `json:"panelId"`
+	RefID   string `json:"refId,omitempty"`
+	Error   string `json:"error"`
+}
+
+type RenderDashboardSnapshotResponse struct {
+	dashboardsnapshot.DashboardCreateResponse
+	// Queries that failed. The snapshot is still created without their data.
+	PanelErrors []PanelError `json:"panelErrors,omitempty"`
+}
+
+// QueryDataService executes panel queries, it is implemented by query.Service.
+type QueryDataService interface {
+	QueryData(ctx context.Context, user identity.Requester, skipDSCache bool, reqDTO dtos.MetricRequest) (*backend.QueryDataResponse, error)
+}
+
+// RenderDashboardSnapshot executes the queries of every panel of a dashboard
+// with the signed in user permissions and stores the results as a snapshot.
+func RenderDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.SnapshotSharingOptions, cmd RenderDashboardSnapshotCommand,
+	svc Service, dashboardService dashboards.DashboardService, queryService QueryDataService) {
+	if !cfg.SnapshotsEnabled {
+		c.JsonApiErr(http.StatusForbidden, "Dashboard Snapshots are disabled", nil)
+		return
+	}
+
+	if !util.IsValidShortUID(cmd.DashboardUID) {
+		c.JsonApiErr(http.StatusBadRequest, "Invalid dashboard UID", nil)
+		return
+	}
+
+	dash, err := dashboardService.GetDashboard(c.Req.Context(), &dashboards.GetDashboardQuery{UID: cmd.DashboardUID, OrgID: c.SignedInUser.GetOrgID()})
+	if err != nil {
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			c.JsonApiErr(http.StatusNotFound, "Dashboard not found", err)
+			return
+		}
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to get dashboard", err)
+		return
+	}
+
+	userID, err := identity.UserIdentifier(c.SignedInUser.GetNamespacedID())
+	if err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to create snapshot", err)
+		return
+	}
+
+	model, panelErrors, err := renderDashboard(c.Req.Context(), c.SignedInUser, dash, cmd, queryService)
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidTimeRange), errors.Is(err, templatevars.ErrUnknownVariable), errors.Is(err, templatevars.ErrInvalidValue):
+			c.JsonApiErr(http.StatusBadRequest, err.Error(), err)
+		case errors.Is(err, datasources.ErrDataSourceAccessDenied):
+			c.JsonApiErr(http.StatusForbidden, "Access denied to data source", err)
+		default:
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to query dashboard", err)
+		}
+		return
+	}
+
+	createCmd := CreateDashboardSnapshotCommand{
+		DashboardCreateCommand: dashboardsnapshot.DashboardCreateCommand{
+			Name:      cmd.Name,
+			Expires:   cmd.Expires,
+			Dashboard: &common.Unstructured{Object: model.MustMap()},
+		},
+		OrgID:  c.SignedInUser.GetOrgID(),
+		UserID: userID,
+	}
+	if createCmd.Name == "" {
+		createCmd.Name = dash.Title
+	}
+	createCmd.Dashboard.SetNestedField(fmt.Sprintf("/d/%v", dash.UID), "snapshot", "originalUrl")
+
+	if err := generateSnapshotKeys(&createCmd); err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Could not generate random string", err)
+		return
+	}
+
+	result, err := svc.CreateDashboardSnapshot(c.Req.Context(), &createCmd)
+	if err != nil {
+		c.JsonApiErr(http.StatusInternalServerError, "Failed to create snapshot", err)
+		return
+	}
+
+	metrics.MApiDashboardSnapshotCreate.Inc()
+
+	c.JSON(http.StatusOK, RenderDashboardSnapshotResponse{
+		DashboardCreateResponse: dashboardsnapshot.DashboardCreateResponse{
+			Key:       result.Key,
+			DeleteKey: result.DeleteKey,
+			URL:       setting.ToAbsUrl("dashboard/snapshot/" + result.Key),
+			DeleteURL: setting.ToAbsUrl("api/snapshots-delete/" + result.DeleteKey),
+		},
+		PanelErrors: panelErrors,
+	})
+}
+
+// renderDashboard returns a copy of the dashboard model with the query results
+// stored in the snapshotData of each panel, in the format created by the frontend.
+// Queries are removed from the model so they can't be read from the snapshot.
+// Repeated panels and rows are not expanded.
+func renderDashboard(ctx context.Context, user identity.Requester, dash *dashboards.Dashboard, cmd RenderDashboardSnapshotCommand, queryService QueryDataService) (*simplejson.Json, []PanelError, error) {
+	raw, err := dash.Data.Encode()
+	if err != nil {
+		return nil, nil, err
+	}
+	model, err := simplejson.NewJson(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	from, to, err := snapshotTimeRange(model, cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	vars := templatevars.FromDashboard(model)
+	for name, values := range cmd.Variables {
+		if err := vars.Select(name, values); err != nil {
+			return nil, nil, err
+		}
+	}
+	vars.SetConstant("__from", strconv.FormatInt(from.UnixMilli(), 10))
+	vars.SetConstant("__to", strconv.FormatInt(to.UnixMilli(), 10))
+
+	r := &snapshotRenderer{
+		user:         user,
+		queryService: queryService,
+		vars:         vars,
+		from:         from,
+		to:           to,
+	}
+	if err := r.renderPanels(ctx, model.Get("panels").MustArray()); err != nil {
+		return nil, nil, err
+	}
+
+	model.Set("time", map[string]any{"from": from.UTC().Format(time.RFC3339Nano), "to": to.UTC().Format(time.RFC3339Nano)})
+	model.Set("links", []any{})
+	trimSnapshotVariables(model, vars)
+	trimSnapshotAnnotations(model)
+
+	return model, r.errors, nil
+}
+
+func snapshotTimeRange(model *simplejson.Json, cmd RenderDashboardSnapshotCommand) (time.Time, time.Time, error) {
+	from, to := cmd.From, cmd.To
+	if from == "" || to == "" {
+		from = model.GetPath("time", "from").MustString("now-6h")
+		to = model.GetPath("time", "to").MustString("now")
+	}
+
+	timezone := cmd.Timezone
+	if timezone == "" {
+		timezone = model.Get("timezone").MustString()
+	}
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "browser" {
+		location = time.UTC
+	}
+
+	timeRange := gtime.NewTimeRange(from, to)
+	fromTime, err := timeRange.ParseFrom(gtime.WithLocation(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %s", errInvalidTimeRange, err)
+	}
+	toTime, err := timeRange.ParseTo(gtime.WithLocation(location))
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %s", errInvalidTimeRange, err)
+	}
+	if !fromTime.Before(toTime) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be before to", errInvalidTimeRange)
+	}
+	return fromTime, toTime, nil
+}
+
+type snapshotRenderer struct {
+	user         identity.Requester
+	queryService QueryDataService
+	vars         templatevars.Variables
+	from         time.Time
+	to           time.Time
+	errors       []PanelError
+}
+
+func (r *snapshotRenderer) renderPanels(ctx context.Context, panels []any) error {
+	for _, obj := range panels {
+		panel := simplejson.NewFromAny(obj)
+		panel.Set("links", []any{})
+		if panel.Get("type").MustString() == "row" {
+			if err := r.renderPanels(ctx, panel.Get("panels").MustArray()); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.renderPanel(ctx, panel); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *snapshotRenderer) renderPanel(ctx context.Context, panel *simplejson.Json) error {
+	panelID := panel.Get("id").MustInt64()
+	queries, err := r.panelQueries(panel)
+	panel.Del("targets")
+
+	snapshotData := []any{}
+	defer func() { panel.Set("snapshotData", snapshotData) }()
+	if err != nil {
+		r.errors = append(r.errors, PanelError{PanelID: panelID, Error: err.Error()})
+		return nil
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	res, err := r.queryService.QueryData(ctx, r.user, false, dtos.MetricRequest{
+		From:    strconv.FormatInt(r.from.UnixMilli(), 10),
+		To:      strconv.FormatInt(r.to.UnixMilli(), 10),
+		Queries: queries,
+	})
+	if err != nil {
+		if errors.Is(err, datasources.ErrDataSourceAccessDenied) {
+			return err
+		}
+		r.errors = append(r.errors, PanelError{PanelID: panelID, Error: err.Error()})
+		return nil
+	}
+
+	for _, query := range queries {
+		refID := query.Get("refId").MustString("A")
+		dr, ok := res.Responses[refID]
+		if !ok {
+			continue
+		}
+		if dr.Error != nil {
+			r.errors = append(r.errors, PanelError{PanelID: panelID, RefID: refID, Error: dr.Error.Error()})
+			continue
+		}
+		for _, frame := range dr.Frames {
+			if frame.RefID == "" {
+				frame.RefID = refID
+			}
+			snapshotData = append(snapshotData, snapshotFrame(frame))
+		}
+	}
+	return nil
+}
+
+// panelQueries returns the interpolated queries of a panel. Hidden queries are
+// kept when the panel has expressions, since these may depend on them.
+func (r *snapshotRenderer) panelQueries(panel *simplejson.Json) ([]*simplejson.Json, error) {
+	targets := panel.Get("targets").MustArray()
+	hasExpression := false
+	for _, obj := range targets {
+		if expr.NodeTypeFromDatasourceUID(datasourceUID(simplejson.NewFromAny(obj))) == expr.TypeCMDNode {
+			hasExpression = true
+		}
+	}
+
+	maxDataPoints := panel.Get("maxDataPoints").MustInt64()
+	if maxDataPoints <= 0 {
+		maxDataPoints = panel.GetPath("gridPos", "w").MustInt64(12) * snapshotColumnWidth
+	}
+	intervalMs := r.to.Sub(r.from).Milliseconds() / maxDataPoints
+	if intervalMs < 1 {
+		intervalMs = 1
+	}
+
+	var queries []*simplejson.Json
+	for _, obj := range targets {
+		query := simplejson.NewFromAny(obj)
+		if !hasExpression && query.Get("hide").MustBool() {
+			continue
+		}
+		if _, ok := query.CheckGet("datasource"); !ok {
+			query.Set("datasource", panel.Get("datasource").Interface())
+		}
+		if err := r.vars.InterpolateJSON(query); err != nil {
+			return nil, err
+		}
+		query.Set("intervalMs", intervalMs)
+		query.Set("maxDataPoints", maxDataPoints)
+		queries = append(queries, query)
+	}
+	return queries, nil
+}
+
+func datasourceUID(query *simplejson.Json) string {
+	uid := query.Get("datasource").Get("uid").MustString()
+	// before 8.3 special types could be sent as datasource (expr)
+	if uid == "" {
+		uid = query.Get("datasource").MustString()
+	}
+	return uid
+}
+
+// snapshotFrame converts a frame to the DataFrameDTO format read by the frontend from snapshotData.
+func snapshotFrame(frame *data.Frame) map[string]any {
+	fields := make([]any, 0, len(frame.Fields))
+	for _, field := range frame.Fields {
+		values := make([]any, field.Len())
+		for i := range values {
+			values[i] = snapshotValue(field, i)
+		}
+		f := map[string]any{
+			"name":   field.Name,
+			"type":   snapshotFieldType(field.Type()),
+			"values": values,
+			"config": map[string]any{},
+		}
+		if field.Config != nil {
+			f["config"] = field.Config
+		}
+		if len(field.Labels) > 0 {
+			f["labels"] = field.Labels
+		}
+		fields = append(fields, f)
+	}
+
+	result := map[string]any{
+		"refId":  frame.RefID,
+		"fields": fields,
+	}
+	if frame.Name != "" {
+		result["name"] = frame.Name
+	}
+	if frame.Meta != nil {
+		meta := *frame.Meta
+		// the executed query may contain information the snapshot viewers should not see
+		meta.ExecutedQueryString = ""
+		result["meta"] = meta
+	}
+	return result
+}
+
+func snapshotValue(field *data.Field, idx int) any {
+	value, ok := field.ConcreteAt(idx)
+	if !ok {
+		return nil
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.UnixMilli()
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+	case float32:
+		if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+			return nil
+		}
+	}
+	return value
+}
+
+func snapshotFieldType(fieldType data.FieldType) string {
+	switch {
+	case fieldType.Time():
+		return "time"
+	case fieldType.Numeric():
+		return "number"
+	case fieldType == data.FieldTypeString || fieldType == data.FieldTypeNullableString:
+		return "string"
+	case fieldType == data.FieldTypeBool || fieldType == data.FieldTypeNullableBool:
+		return "boolean"
+	case fieldType == data.FieldTypeEnum || fieldType == data.FieldTypeNullableEnum:
+		return "enum"
+	default:
+		return "other"
+	}
+}
+
+// trimSnapshotVariables stores the rendered variable values and removes the
+// variable queries, the same way as snapshots created by the frontend.
+func trimSnapshotVariables(model *simplejson.Json, vars templatevars.Variables) {
+	for _, obj := range model.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(obj)
+		if v, ok := vars[variable.Get("name").MustString()]; ok {
+			variable.Set("current", v.Current())
+		}
+		if _, ok := variable.CheckGet("query"); ok {
+			variable.Set("query", "")
+		}
+		if _, ok := variable.CheckGet("options"); ok {
+			variable.Set("options", []any{variable.Get("current").Interface()})
+		}
+		if _, ok := variable.CheckGet("refresh"); ok {
+			variable.Set("refresh", 0)
+		}
+	}
+}
+
+// trimSnapshotAnnotations keeps the enabled annotations without their queries.
+func trimSnapshotAnnotations(model *simplejson.Json) {
+	annotations := []any{}
+	for _, obj := range model.GetPath("annotations", "list").MustArray() {
+		annotation := simplejson.NewFromAny(obj)
+		if !annotation.Get("enable").MustBool() {
+			continue
+		}
+		annotations = append(annotations, map[string]any{
+			"name":         annotation.Get("name").Interface(),
+			"enable":       true,
+			"iconColor":    annotation.Get("iconColor").Interface(),
+			"type":         annotation.Get("type").Interface(),
+			"builtIn":      annotation.Get("builtIn").Interface(),
+			"hide":         annotation.Get("hide").Interface(),
+			"snapshotData": []any{},
+		})
+	}
+	if _, ok := model.CheckGet("annotations"); ok {
+		model.SetPath([]string{"annotations", "list"}, annotations)
+	}
+}
@@ -0,0 +1,210 @@
+package dashboardsnapshots
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/templatevars"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeQueryDataService struct {
+	requests []dtos.MetricRequest
+	err      error
+}
+
+func (s *fakeQueryDataService) QueryData(_ context.Context, _ identity.Requester, _ bool, req dtos.MetricRequest) (*backend.QueryDataResponse, error) {
+	s.requests = append(s.requests, req)
+	if s.err != nil {
+		return nil, s.err
+	}
+	res := backend.NewQueryDataResponse()
+	for _, q := range req.Queries {
+		refID := q.Get("refId").MustString()
+		if q.Get("expr").MustString() == "fail" {
+			res.Responses[refID] = backend.DataResponse{Error: errors.New("query failed")}
+			continue
+		}
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.UnixMilli(1000)}),
+			data.NewField("value", data.Labels{"job": "api"}, []*float64{nil}),
+		)
+		frame.Meta = &data.FrameMeta{ExecutedQueryString: q.Get("expr").MustString()}
+		res.Responses[refID] = backend.DataResponse{Frames: data.Frames{frame}}
+	}
+	return res, nil
+}
+
+const testSnapshotDashboard = `{
+	"uid": "dash",
+	"title": "Service",
+	"timezone": "utc",
+	"time": {"from": "now-1h", "to": "now"},
+	"links": [{"title": "docs"}],
+	"templating": {
+		"list": [
+			{
+				"name": "job",
+				"type": "query",
+				"query": "label_values(job)",
+				"refresh": 1,
+				"multi": true,
+				"current": {"text": ["api"], "value": ["api"]},
+				"options": []
+			}
+		]
+	},
+	"annotations": {
+		"list": [
+			{"name": "Annotations", "enable": true, "builtIn": 1, "type": "dashboard"},
+			{"name": "Deploys", "enable": false, "target": {"expr": "deploys"}}
+		]
+	},
+	"panels": [
+		{
+			"id": 1,
+			"type": "timeseries",
+			"gridPos": {"w": 12},
+			"datasource": {"type": "prometheus", "uid": "prom"},
+			"targets": [
+				{"refId": "A", "expr": "up{job=~\"${job:regex}\"}"},
+				{"refId": "B", "expr": "hidden", "hide": true}
+			]
+		},
+		{
+			"id": 2,
+			"type": "row",
+			"collapsed": true,
+			"panels": [
+				{
+					"id": 3,
+					"type": "stat",
+					"maxDataPoints": 10,
+					"datasource": {"type": "prometheus", "uid": "prom"},
+					"targets": [{"refId": "A", "expr": "fail"}]
+				}
+			]
+		},
+		{"id": 4, "type": "text"}
+	]
+}`
+
+func TestRenderDashboard(t *testing.T) {
+	ctx := context.Background()
+	signedInUser := &user.SignedInUser{OrgID: 1}
+	dash := &dashboards.Dashboard{UID: "dash", OrgID: 1, Data: simplejson.MustJson([]byte(testSnapshotDashboard))}
+
+	t.Run("stores query results in panels", func(t *testing.T) {
+		queryService := &fakeQueryDataService{}
+		model, panelErrors, err := renderDashboard(ctx, signedInUser, dash, RenderDashboardSnapshotCommand{
+			From:      "1700000000000",
+			To:        "1700003600000",
+			Variables: map[string][]string{"job": {"api", "web"}},
+		}, queryService)
+		require.NoError(t, err)
+
+		require.Len(t, queryService.requests, 2)
+		req := queryService.requests[0]
+		require.Equal(t, "1700000000000", req.From)
+		require.Equal(t, "1700003600000", req.To)
+		require.Len(t, req.Queries, 1)
+		require.Equal(t, `up{job=~"(api|web)"}`, req.Queries[0].Get("expr").MustString())
+		require.Equal(t, int64(960), req.Queries[0].Get("maxDataPoints").MustInt64())
+		require.Equal(t, int64(3750), req.Queries[0].Get("intervalMs").MustInt64())
+		require.Equal(t, int64(10), queryService.requests[1].Queries[0].Get("maxDataPoints").MustInt64())
+
+		require.Equal(t, []PanelError{{PanelID: 3, RefID: "A", Error: "query failed"}}, panelErrors)
+
+		out, err := model.Encode()
+		require.NoError(t, err)
+		require.NotContains(t, string(out), "label_values")
+		require.NotContains(t, string(out), "up{job")
+		require.NotContains(t, string(out), "deploys")
+
+		expected := `{
+			"refId": "A",
+			"meta": {"typeVersion": [0, 0]},
+			"fields": [
+				{"name": "time", "type": "time", "config": {}, "values": [1000]},
+				{"name": "value", "type": "number", "config": {}, "labels": {"job": "api"}, "values": [null]}
+			]
+		}`
+		panel := model.Get("panels").GetIndex(0)
+		snapshotData, err := json.Marshal(panel.Get("snapshotData").GetIndex(0).Interface())
+		require.NoError(t, err)
+		require.JSONEq(t, expected, string(snapshotData))
+		require.Empty(t, panel.Get("links").MustArray())
+
+		row := model.Get("panels").GetIndex(1)
+		require.Empty(t, row.Get("panels").GetIndex(0).Get("snapshotData").MustArray())
+		require.NotNil(t, model.Get("panels").GetIndex(2).Get("snapshotData").MustArray())
+
+		require.Equal(t, "2023-11-14T22:13:20Z", model.GetPath("time", "from").MustString())
+		require.Equal(t, "2023-11-14T23:13:20Z", model.GetPath("time", "to").MustString())
+		require.Empty(t, model.Get("links").MustArray())
+
+		variable := model.GetPath("templating", "list").GetIndex(0)
+		require.Equal(t, []string{"api", "web"}, variable.GetPath("current", "value").Interface())
+		require.Equal(t, "", variable.Get("query").MustString())
+		require.Equal(t, 0, variable.Get("refresh").MustInt())
+
+		annotations := model.GetPath("annotations", "list").MustArray()
+		require.Len(t, annotations, 1)
+
+		// the saved dashboard is not modified
+		require.NotNil(t, dash.Data.Get("panels").GetIndex(0).Get("targets").Interface())
+	})
+
+	t.Run("rejects unknown variables", func(t *testing.T) {
+		_, _, err := renderDashboard(ctx, signedInUser, dash, RenderDashboardSnapshotCommand{
+			Variables: map[string][]string{"missing": {"x"}},
+		}, &fakeQueryDataService{})
+		require.ErrorIs(t, err, templatevars.ErrUnknownVariable)
+	})
+
+	t.Run("rejects invalid time range", func(t *testing.T) {
+		_, _, err := renderDashboard(ctx, signedInUser, dash, RenderDashboardSnapshotCommand{
+			From: "now",
+			To:   "now-1h",
+		}, &fakeQueryDataService{})
+		require.ErrorIs(t, err, errInvalidTimeRange)
+	})
+
+	t.Run("fails when a data source can't be queried", func(t *testing.T) {
+		_, _, err := renderDashboard(ctx, signedInUser, dash, RenderDashboardSnapshotCommand{}, &fakeQueryDataService{err: datasources.ErrDataSourceAccessDenied})
+		require.ErrorIs(t, err, datasources.ErrDataSourceAccessDenied)
+	})
+
+	t.Run("reports panels using all without options", func(t *testing.T) {
+		dash := &dashboards.Dashboard{UID: "dash", OrgID: 1, Data: simplejson.MustJson([]byte(testSnapshotDashboard))}
+		dash.Data.GetPath("templating", "list").GetIndex(0).Set("includeAll", true)
+
+		queryService := &fakeQueryDataService{}
+		_, panelErrors, err := renderDashboard(ctx, signedInUser, dash, RenderDashboardSnapshotCommand{
+			Variables: map[string][]string{"job": {templatevars.AllValue}},
+		}, queryService)
+		require.NoError(t, err)
+		require.Len(t, panelErrors, 2)
+		require.Equal(t, int64(1), panelErrors[0].PanelID)
+		require.Contains(t, panelErrors[0].Error, "job has all selected")
+		require.Len(t, queryService.requests, 1)
+	})
+
+	t.Run("reports other query errors per panel", func(t *testing.T) {
+		_, panelErrors, err := renderDashboard(ctx, signedInUser, dash, RenderDashboardSnapshotCommand{}, &fakeQueryDataService{err: errors.New("boom")})
+		require.NoError(t, err)
+		require.Equal(t, []PanelError{{PanelID: 1, Error: "boom"}, {PanelID: 3, Error: "boom"}}, panelErrors)
+	})
+}
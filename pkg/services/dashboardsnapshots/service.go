@@ -98,22 +98,9 @@ func CreateDashboardSnapshot(c *contextmodel.ReqContext, cfg dashboardsnapshot.S
 	} else {
 		cmd.DashboardCreateCommand.Dashboard.SetNestedField(originalDashboardURL, "snapshot", "originalUrl")
 
-		if cmd.Key == "" {
-			var err error
-			cmd.Key, err = util.GetRandomString(32)
-			if err != nil {
-				c.JsonApiErr(http.StatusInternalServerError, "Could not generate random string", err)
-				return
-			}
-		}
-
-		if cmd.DeleteKey == "" {
-			var err error
-			cmd.DeleteKey, err = util.GetRandomString(32)
-			if err != nil {
-				c.JsonApiErr(http.StatusInternalServerError, "Could not generate random string", err)
-				return
-			}
+		if err := generateSnapshotKeys(&cmd); err != nil {
+			c.JsonApiErr(http.StatusInternalServerError, "Could not generate random string", err)
+			return
 		}
 
 		snapshotUrl = setting.ToAbsUrl("dashboard/snapshot/" + cmd.Key)
@@ -205,6 +192,22 @@ func createExternalDashboardSnapshot(cmd CreateDashboardSnapshotCommand, externa
 	return &createSnapshotResponse, nil
 }
 
+// generateSnapshotKeys sets random keys for a local snapshot unless they were provided.
+func generateSnapshotKeys(cmd *CreateDashboardSnapshotCommand) error {
+	var err error
+	if cmd.Key == "" {
+		if cmd.Key, err = util.GetRandomString(32); err != nil {
+			return err
+		}
+	}
+	if cmd.DeleteKey == "" {
+		if cmd.DeleteKey, err = util.GetRandomString(32); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func createOriginalDashboardURL(cmd *CreateDashboardSnapshotCommand) (string, error) {
 	dashUID := cmd.Dashboard.GetNestedString("uid")
 	if ok := util.IsValidShortUID(dashUID); !ok {
@@ -8,4 +8,5 @@ type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersionDTO, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) ([]*DashboardVersionDTO, error)
+	Merge(context.Context, *MergeDashboardVersionsQuery) (*MergeDashboardVersionsResult, error)
 }
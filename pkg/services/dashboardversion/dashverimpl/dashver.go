@@ -3,7 +3,9 @@ package dashverimpl
 import (
 	"context"
 	"errors"
+	"fmt"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -129,6 +131,40 @@ func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersions
 	return dtos, nil
 }
 
+// Merge merges the changes made to the dashboard since query.BaseVersion into
+// the current version of the dashboard. The merged dashboard is only safe to
+// save when there are no conflicts.
+func (s *Service) Merge(ctx context.Context, query *dashver.MergeDashboardVersionsQuery) (*dashver.MergeDashboardVersionsResult, error) {
+	current, err := s.dashSvc.GetDashboard(ctx, &dashboards.GetDashboardQuery{
+		ID:    query.DashboardID,
+		UID:   query.DashboardUID,
+		OrgID: query.OrgID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := s.store.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID: current.ID,
+		OrgID:       query.OrgID,
+		Version:     query.BaseVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged, err := dashdiffs.Merge(base.Data, current.Data, query.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge dashboard versions: %w", err)
+	}
+
+	return &dashver.MergeDashboardVersionsResult{
+		Data:      merged.Dashboard,
+		Version:   current.Version,
+		Conflicts: merged.Conflicts,
+	}, nil
+}
+
 // getDashUIDMaybeEmpty is a helper function which takes a dashboardID and
 // returns the UID. If the dashboard is not found, it will return an empty
 // string.
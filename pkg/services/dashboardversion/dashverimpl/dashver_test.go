@@ -146,6 +146,54 @@ func TestListDashboardVersions(t *testing.T) {
 	})
 }
 
+func TestMergeDashboardVersions(t *testing.T) {
+	t.Run("Merge the changes made since the base version into the current version", func(t *testing.T) {
+		dashboardVersionStore := newDashboardVersionStoreFake()
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboardService, log: log.NewNopLogger()}
+		dashboardVersionStore.ExpectedDashboardVersion = &dashver.DashboardVersion{
+			DashboardID: 42,
+			Version:     1,
+			Data:        simplejson.NewFromAny(map[string]any{"title": "Dash", "version": 1, "refresh": "1m"}),
+		}
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).
+			Return(&dashboards.Dashboard{
+				ID:      42,
+				Version: 2,
+				Data:    simplejson.NewFromAny(map[string]any{"title": "Renamed", "version": 2, "refresh": "1m"}),
+			}, nil)
+
+		res, err := dashboardVersionService.Merge(context.Background(), &dashver.MergeDashboardVersionsQuery{
+			DashboardUID: "uid",
+			BaseVersion:  1,
+			Data:         simplejson.NewFromAny(map[string]any{"title": "Dash", "version": 1, "refresh": "5m"}),
+		})
+		require.NoError(t, err)
+		require.Empty(t, res.Conflicts)
+		require.Equal(t, 2, res.Version)
+		require.Equal(t, "Renamed", res.Data.Get("title").MustString())
+		require.Equal(t, "5m", res.Data.Get("refresh").MustString())
+		require.Equal(t, int64(2), res.Data.Get("version").MustInt64())
+	})
+
+	t.Run("Merge fails when the base version does not exist", func(t *testing.T) {
+		dashboardVersionStore := newDashboardVersionStoreFake()
+		dashboardService := dashboards.NewFakeDashboardService(t)
+		dashboardVersionService := Service{store: dashboardVersionStore, dashSvc: dashboardService, log: log.NewNopLogger()}
+		dashboardVersionStore.ExpectedError = dashver.ErrDashboardVersionNotFound
+		dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).
+			Return(&dashboards.Dashboard{ID: 42, Version: 2, Data: simplejson.New()}, nil)
+
+		res, err := dashboardVersionService.Merge(context.Background(), &dashver.MergeDashboardVersionsQuery{
+			DashboardUID: "uid",
+			BaseVersion:  1,
+			Data:         simplejson.New(),
+		})
+		require.Nil(t, res)
+		require.ErrorIs(t, err, dashver.ErrDashboardVersionNotFound)
+	})
+}
+
 type FakeDashboardVersionStore struct {
 	ExpectedDashboardVersion *dashver.DashboardVersion
 	ExptectedDeletedVersions int64
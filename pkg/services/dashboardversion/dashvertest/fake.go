@@ -10,6 +10,7 @@ type FakeDashboardVersionService struct {
 	ExpectedDashboardVersion     *dashver.DashboardVersionDTO
 	ExpectedDashboardVersions    []*dashver.DashboardVersionDTO
 	ExpectedListDashboarVersions []*dashver.DashboardVersionDTO
+	ExpectedMergeResult          *dashver.MergeDashboardVersionsResult
 	counter                      int
 	ExpectedError                error
 }
@@ -33,3 +34,7 @@ func (f *FakeDashboardVersionService) DeleteExpired(ctx context.Context, cmd *da
 func (f *FakeDashboardVersionService) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	return f.ExpectedListDashboarVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionService) Merge(ctx context.Context, query *dashver.MergeDashboardVersionsQuery) (*dashver.MergeDashboardVersionsResult, error) {
+	return f.ExpectedMergeResult, f.ExpectedError
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 )
 
@@ -66,6 +67,26 @@ type ListDashboardVersionsQuery struct {
 	Limit        int
 	Start        int
 }
+
+// MergeDashboardVersionsQuery is used to merge the changes made to the
+// dashboard since BaseVersion in Data into the current version of the
+// dashboard. Only one of DashboardID and DashboardUID are required.
+type MergeDashboardVersionsQuery struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	BaseVersion  int
+	Data         *simplejson.Json
+}
+
+type MergeDashboardVersionsResult struct {
+	// Data is the merged dashboard, based on the current version.
+	Data *simplejson.Json `json:"data"`
+	// Version is the current version of the dashboard.
+	Version   int                  `json:"version"`
+	Conflicts []dashdiffs.Conflict `json:"conflicts"`
+}
+
 type DashboardVersionDTO struct {
 	ID            int64            `json:"id"`
 	DashboardID   int64            `json:"dashboardId"`
@@ -25,6 +25,7 @@ const (
 	DS_MYSQL          = "mysql"
 	DS_POSTGRES       = "grafana-postgresql-datasource"
 	DS_MSSQL          = "mssql"
+	DS_SQLITE         = "sqlite"
 	DS_ACCESS_DIRECT  = "direct"
 	DS_ACCESS_PROXY   = "proxy"
 	DS_ES_OPEN_DISTRO = "grafana-es-open-distro-datasource"
@@ -310,7 +310,7 @@ func (s *Service) GetUserFromLDAP(c *contextmodel.ReqContext) response.Response
 		return response.Error(http.StatusBadRequest, "An organization was not found - Please verify your LDAP configuration", err)
 	}
 
-	u.Teams, err = s.ldapGroupsService.GetTeams(user.Groups, orgIDs)
+	u.Teams, err = s.ldapGroupsService.GetTeams(c.Req.Context(), user.Groups, orgIDs)
 	if err != nil {
 		return response.Error(http.StatusBadRequest, "Unable to find the teams for this user", err)
 	}
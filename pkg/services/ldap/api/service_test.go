@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/org/orgtest"
 	"github.com/grafana/grafana/pkg/services/supportbundles/supportbundlestest"
+	"github.com/grafana/grafana/pkg/services/team/teamsync/teamsynctest"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/services/user/usertest"
 	"github.com/grafana/grafana/pkg/setting"
@@ -71,7 +72,7 @@ func setupAPITest(t *testing.T, opts ...func(a *Service)) (*Service, *webtest.Se
 		acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()),
 		usertest.NewUserServiceFake(),
 		&authinfotest.FakeService{},
-		ldap.ProvideGroupsService(),
+		ldap.ProvideGroupsService(teamsynctest.NewFakeService(), &orgtest.FakeOrgService{}),
 		&authntest.FakeService{},
 		&orgtest.FakeOrgService{},
 		service.NewLDAPFakeService(),
@@ -1,15 +1,57 @@
 package ldap
 
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+)
+
 type Groups interface {
-	GetTeams(groups []string, orgIDs []int64) ([]TeamOrgGroupDTO, error)
+	GetTeams(ctx context.Context, groups []string, orgIDs []int64) ([]TeamOrgGroupDTO, error)
 }
 
-type OSSGroups struct{}
+type OSSGroups struct {
+	teamSyncService teamsync.Service
+	orgService      org.Service
+}
 
-func ProvideGroupsService() *OSSGroups {
-	return &OSSGroups{}
+func ProvideGroupsService(teamSyncService teamsync.Service, orgService org.Service) *OSSGroups {
+	return &OSSGroups{
+		teamSyncService: teamSyncService,
+		orgService:      orgService,
+	}
 }
 
-func (*OSSGroups) GetTeams(_ []string, _ []int64) ([]TeamOrgGroupDTO, error) {
-	return nil, nil
+// GetTeams returns the teams of the organizations that are synced with the LDAP groups.
+func (s *OSSGroups) GetTeams(ctx context.Context, groups []string, orgIDs []int64) ([]TeamOrgGroupDTO, error) {
+	if len(groups) == 0 || len(orgIDs) == 0 {
+		return nil, nil
+	}
+
+	teamGroups, err := s.teamSyncService.GetTeamGroupsByGroups(ctx, &teamsync.GetTeamGroupsByGroupsQuery{OrgIDs: orgIDs, Groups: groups})
+	if err != nil {
+		return nil, err
+	}
+
+	orgNames := map[int64]string{}
+	var teams []TeamOrgGroupDTO
+	for _, tg := range teamGroups {
+		orgName, ok := orgNames[tg.OrgID]
+		if !ok {
+			o, err := s.orgService.GetByID(ctx, &org.GetOrgByIDQuery{ID: tg.OrgID})
+			if err != nil {
+				return nil, err
+			}
+			orgName = o.Name
+			orgNames[tg.OrgID] = orgName
+		}
+
+		teams = append(teams, TeamOrgGroupDTO{
+			TeamName: tg.TeamName,
+			OrgName:  orgName,
+			GroupDN:  tg.GroupID,
+		})
+	}
+	return teams, nil
 }
@@ -0,0 +1,33 @@
+package ldap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/services/team/teamsync/teamsynctest"
+)
+
+func TestOSSGroups_GetTeams(t *testing.T) {
+	teamSyncService := &teamsynctest.FakeService{ExpectedTeamGroups: []*teamsync.TeamGroupDTO{
+		{OrgID: 1, TeamID: 1, TeamName: "editors", GroupID: "cn=editors,ou=groups,dc=grafana,dc=org"},
+		{OrgID: 1, TeamID: 2, TeamName: "admins", GroupID: "cn=*,ou=groups,dc=grafana,dc=org"},
+	}}
+	groups := ProvideGroupsService(teamSyncService, &orgtest.FakeOrgService{ExpectedOrg: &org.Org{ID: 1, Name: "Main Org."}})
+
+	teams, err := groups.GetTeams(context.Background(), []string{"cn=editors,ou=groups,dc=grafana,dc=org"}, []int64{1})
+	require.NoError(t, err)
+	assert.Equal(t, []TeamOrgGroupDTO{
+		{TeamName: "editors", OrgName: "Main Org.", GroupDN: "cn=editors,ou=groups,dc=grafana,dc=org"},
+		{TeamName: "admins", OrgName: "Main Org.", GroupDN: "cn=*,ou=groups,dc=grafana,dc=org"},
+	}, teams)
+
+	teams, err = groups.GetTeams(context.Background(), nil, []int64{1})
+	require.NoError(t, err)
+	assert.Empty(t, teams)
+}
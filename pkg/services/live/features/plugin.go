@@ -73,7 +73,8 @@ func (r *PluginPathRunner) OnSubscribe(ctx context.Context, user identity.Reques
 		logger.Error("Get plugin context error", "error", err, "path", r.path)
 		return model.SubscribeReply{}, 0, err
 	}
-	resp, err := r.handler.SubscribeStream(ctx, &backend.SubscribeStreamRequest{
+	// plugins check the permissions of the user on the resources of the stream
+	resp, err := r.handler.SubscribeStream(identity.WithRequester(ctx, user), &backend.SubscribeStreamRequest{
 		PluginContext: pCtx,
 		Path:          r.path,
 		Data:          e.Data,
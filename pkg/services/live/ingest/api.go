@@ -0,0 +1,104 @@
+package ingest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func (s *Service) registerAPIEndpoints(routeRegister routing.RouteRegister) {
+	routeRegister.Group("/api/live/ingest/sources", func(sources routing.RouteRegister) {
+		sources.Get("/", routing.Wrap(s.listSourcesHandler))
+		sources.Put("/:name", routing.Wrap(s.saveSourceHandler))
+		sources.Delete("/:name", routing.Wrap(s.deleteSourceHandler))
+	}, middleware.ReqOrgAdmin)
+}
+
+// SourceDTO is a source of an organization as returned by the API. The values
+// of the secure settings are never returned.
+type SourceDTO struct {
+	Source
+	// SecureFields lists the secure settings which are set.
+	SecureFields map[string]bool `json:"secureFields"`
+}
+
+// SaveSourceCommand creates or updates a source.
+type SaveSourceCommand struct {
+	Source
+	// SecureSettings sets the password and token. Settings which are not set
+	// keep their stored values, empty values remove them.
+	SecureSettings map[string]string `json:"secureSettings,omitempty"`
+}
+
+func newSourceDTO(source Source) SourceDTO {
+	dto := SourceDTO{Source: source, SecureFields: map[string]bool{}}
+	for key := range source.SecureSettings {
+		dto.SecureFields[key] = true
+	}
+	dto.Source.SecureSettings = nil
+	return dto
+}
+
+func (s *Service) listSourcesHandler(c *contextmodel.ReqContext) response.Response {
+	sources, err := s.store.list(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to list live ingest sources", err)
+	}
+	result := make([]SourceDTO, 0, len(sources))
+	for _, source := range sources {
+		result = append(result, newSourceDTO(source))
+	}
+	return response.JSON(http.StatusOK, result)
+}
+
+func (s *Service) saveSourceHandler(c *contextmodel.ReqContext) response.Response {
+	cmd := SaveSourceCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	source := cmd.Source
+	source.Name = web.Params(c.Req)[":name"]
+	source.normalize()
+	if err := source.validate(); err != nil {
+		return response.Error(http.StatusBadRequest, "Invalid live ingest source: "+err.Error(), err)
+	}
+	for key := range cmd.SecureSettings {
+		if key != securePassword && key != secureToken {
+			return response.Error(http.StatusBadRequest, "Invalid live ingest source: unknown secure setting "+key, nil)
+		}
+	}
+
+	orgID := c.SignedInUser.GetOrgID()
+	if err := s.store.save(c.Req.Context(), orgID, source, cmd.SecureSettings); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to save live ingest source", err)
+	}
+	s.triggerReload()
+
+	saved, _, err := s.store.get(c.Req.Context(), orgID, source.Name)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get live ingest source", err)
+	}
+	return response.JSON(http.StatusOK, newSourceDTO(saved))
+}
+
+func (s *Service) deleteSourceHandler(c *contextmodel.ReqContext) response.Response {
+	orgID := c.SignedInUser.GetOrgID()
+	name := web.Params(c.Req)[":name"]
+	_, ok, err := s.store.get(c.Req.Context(), orgID, name)
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get live ingest source", err)
+	}
+	if !ok {
+		return response.Error(http.StatusNotFound, "Live ingest source not found", errors.New("source not found"))
+	}
+	if err := s.store.delete(c.Req.Context(), orgID, name); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete live ingest source", err)
+	}
+	s.triggerReload()
+	return response.Success("Live ingest source deleted")
+}
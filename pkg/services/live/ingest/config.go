@@ -0,0 +1,228 @@
+package ingest
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+)
+
+const (
+	SourceTypeMQTT = "mqtt"
+	SourceTypeNATS = "nats"
+)
+
+// settingsSection is the configuration section of the instance settings of
+// live ingest.
+const settingsSection = "live.ingest"
+
+const (
+	defaultMaxMessageSize    = 1024 * 1024
+	defaultQueueSize         = 1000
+	defaultReconnectDelay    = time.Second
+	defaultMaxReconnectDelay = time.Minute
+	defaultKeepAlive         = 30 * time.Second
+	defaultReloadInterval    = time.Minute
+)
+
+// Settings are the instance settings of live ingest, they apply to the
+// sources of all organizations.
+type Settings struct {
+	// Enabled allows organizations to configure sources.
+	Enabled bool
+	// MaxMessageSize is the size in bytes of the largest packet accepted from
+	// a broker, the connection is closed when a larger packet is received.
+	MaxMessageSize int
+	// QueueSize is the number of messages buffered per source. Messages
+	// which must be acknowledged wait for room in the queue, other messages
+	// are dropped when the queue is full.
+	QueueSize         int
+	KeepAlive         time.Duration
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+	// ReloadInterval is how often sources are read from the database, which
+	// applies changes made through other Grafana instances.
+	ReloadInterval time.Duration
+}
+
+// readSettings reads the [live.ingest] section.
+func readSettings(raw *ini.File) (Settings, error) {
+	if raw == nil {
+		raw = ini.Empty()
+	}
+	section := raw.Section(settingsSection)
+	settings := Settings{
+		Enabled:           section.Key("enabled").MustBool(false),
+		MaxMessageSize:    section.Key("max_message_size").MustInt(defaultMaxMessageSize),
+		QueueSize:         section.Key("queue_size").MustInt(defaultQueueSize),
+		KeepAlive:         section.Key("keep_alive").MustDuration(defaultKeepAlive),
+		ReconnectDelay:    section.Key("reconnect_delay").MustDuration(defaultReconnectDelay),
+		MaxReconnectDelay: section.Key("max_reconnect_delay").MustDuration(defaultMaxReconnectDelay),
+		ReloadInterval:    section.Key("reload_interval").MustDuration(defaultReloadInterval),
+	}
+	if err := settings.validate(); err != nil {
+		return settings, fmt.Errorf("invalid [%s] configuration: %w", settingsSection, err)
+	}
+	return settings, nil
+}
+
+func (s Settings) validate() error {
+	if s.MaxMessageSize <= 0 || s.MaxMessageSize > mqttMaxLength {
+		return fmt.Errorf("max_message_size must be between 1 and %d", mqttMaxLength)
+	}
+	if s.QueueSize <= 0 {
+		return fmt.Errorf("queue_size must be positive")
+	}
+	if s.KeepAlive < time.Second {
+		return fmt.Errorf("keep_alive must be at least 1s")
+	}
+	if s.ReconnectDelay <= 0 || s.MaxReconnectDelay < s.ReconnectDelay {
+		return fmt.Errorf("invalid reconnect delays")
+	}
+	if s.ReloadInterval < time.Second {
+		return fmt.Errorf("reload_interval must be at least 1s")
+	}
+	return nil
+}
+
+// Source is a message broker an organization subscribes to. Sources are
+// stored per organization.
+type Source struct {
+	// Name identifies the source in its organization.
+	Name string `json:"name"`
+	// Type of the source, mqtt or nats.
+	Type string `json:"type"`
+	// URL of the broker, for example tcp://localhost:1883 or nats://localhost:4222.
+	URL      string `json:"url"`
+	Username string `json:"username,omitempty"`
+	// ClientID identifies Grafana with an MQTT broker.
+	ClientID string `json:"clientId,omitempty"`
+	// QueueGroup makes NATS deliver every message to only one Grafana
+	// instance subscribed with the same queue group.
+	QueueGroup string `json:"queueGroup,omitempty"`
+	// Topics are the MQTT topic filters or NATS subjects to subscribe to.
+	Topics []string `json:"topics"`
+	// Channel is the prefix of the channels messages are published to, the
+	// topic or subject of a message is appended to it as channel path.
+	Channel string `json:"channel"`
+	// Converter used to convert messages to frames when there is no
+	// pipeline channel rule for a channel, jsonAuto by default.
+	Converter *pipeline.ConverterConfig `json:"converter,omitempty"`
+	// SecureSettings holds the encrypted password and token.
+	SecureSettings map[string][]byte `json:"secureSettings,omitempty"`
+}
+
+const (
+	securePassword = "password"
+	secureToken    = "token"
+)
+
+var sourceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_\-]{1,40}$`)
+
+// normalize sets the defaults of a source.
+func (s *Source) normalize() {
+	s.Channel = strings.Trim(s.Channel, "/")
+	topics := s.Topics[:0]
+	for _, topic := range s.Topics {
+		if topic = strings.TrimSpace(topic); topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	s.Topics = topics
+	if s.Converter == nil {
+		s.Converter = &pipeline.ConverterConfig{}
+	}
+	if s.Converter.Type == "" {
+		s.Converter.Type = pipeline.ConverterTypeJsonAuto
+	}
+	if s.Converter.Type == pipeline.ConverterTypeInfluxAuto {
+		if s.Converter.AutoInfluxConverterConfig == nil {
+			s.Converter.AutoInfluxConverterConfig = &pipeline.AutoInfluxConverterConfig{}
+		}
+		if s.Converter.AutoInfluxConverterConfig.FrameFormat == "" {
+			s.Converter.AutoInfluxConverterConfig.FrameFormat = "labels_column"
+		}
+	}
+}
+
+func (s Source) validate() error {
+	if !sourceNamePattern.MatchString(s.Name) {
+		return fmt.Errorf("name must consist of up to 40 letters, digits, - and _")
+	}
+	switch s.Type {
+	case SourceTypeMQTT, SourceTypeNATS:
+	default:
+		return fmt.Errorf("unsupported type %q", s.Type)
+	}
+	if s.URL == "" {
+		return fmt.Errorf("missing url")
+	}
+	if _, err := url.Parse(s.URL); err != nil {
+		return fmt.Errorf("invalid url: %w", err)
+	}
+	if len(s.Topics) == 0 {
+		return fmt.Errorf("missing topics")
+	}
+	if strings.Count(s.Channel, "/") < 1 || !strings.HasPrefix(s.Channel, "stream/") {
+		return fmt.Errorf("channel must be a stream scope channel prefix like stream/<namespace>, got %q", s.Channel)
+	}
+	if _, err := pipeline.NewConverter(s.Converter); err != nil {
+		return err
+	}
+	return nil
+}
+
+// SourceConfig is the configuration a source of an organization runs with.
+type SourceConfig struct {
+	// Name of the source.
+	Name string
+	// Type of the source, mqtt or nats.
+	Type     string
+	URL      string
+	Username string
+	Password string
+	// Token is used to authenticate with NATS.
+	Token      string
+	ClientID   string
+	QueueGroup string
+	Topics     []string
+	// OrgID is the organization the source and the channels belong to.
+	OrgID     int64
+	Channel   string
+	Converter *pipeline.ConverterConfig
+	// MaxMessageSize is the size in bytes of the largest packet accepted.
+	MaxMessageSize    int
+	QueueSize         int
+	KeepAlive         time.Duration
+	ReconnectDelay    time.Duration
+	MaxReconnectDelay time.Duration
+}
+
+// newSourceConfig returns the configuration of a source of an organization
+// with its decrypted secure settings.
+func newSourceConfig(settings Settings, orgID int64, source Source, secureSettings map[string]string) SourceConfig {
+	return SourceConfig{
+		Name:              source.Name,
+		Type:              source.Type,
+		URL:               source.URL,
+		Username:          source.Username,
+		Password:          secureSettings[securePassword],
+		Token:             secureSettings[secureToken],
+		ClientID:          source.ClientID,
+		QueueGroup:        source.QueueGroup,
+		Topics:            source.Topics,
+		OrgID:             orgID,
+		Channel:           source.Channel,
+		Converter:         source.Converter,
+		MaxMessageSize:    settings.MaxMessageSize,
+		QueueSize:         settings.QueueSize,
+		KeepAlive:         settings.KeepAlive,
+		ReconnectDelay:    settings.ReconnectDelay,
+		MaxReconnectDelay: settings.MaxReconnectDelay,
+	}
+}
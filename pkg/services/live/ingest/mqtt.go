@@ -0,0 +1,341 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// MQTT 3.1.1 control packet types.
+const (
+	mqttConnect     byte = 1
+	mqttConnack     byte = 2
+	mqttPublish     byte = 3
+	mqttPuback      byte = 4
+	mqttPubrec      byte = 5
+	mqttPubrel      byte = 6
+	mqttPubcomp     byte = 7
+	mqttSubscribe   byte = 8
+	mqttSuback      byte = 9
+	mqttPingreq     byte = 12
+	mqttPingresp    byte = 13
+	mqttDisconnect  byte = 14
+	mqttMaxLength        = 268435455
+	mqttSubscribeID      = 1
+)
+
+var errMQTTProtocol = errors.New("mqtt protocol error")
+
+// mqttClient is a minimal MQTT 3.1.1 client which subscribes to topic
+// filters and receives messages published with any QoS level.
+type mqttClient struct {
+	config SourceConfig
+	dialer net.Dialer
+}
+
+func newMQTTClient(config SourceConfig) *mqttClient {
+	if config.ClientID == "" {
+		config.ClientID = "grafana-" + util.GenerateShortUID()
+	}
+	return &mqttClient{config: config}
+}
+
+func (c *mqttClient) run(ctx context.Context, onConnect func(), onMessage func(msg message)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	// Unblock reads when the context is canceled.
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	w := &mqttWriter{conn: conn}
+	r := bufio.NewReader(conn)
+
+	if err := w.write(mqttConnect<<4, c.connectPacket()); err != nil {
+		return err
+	}
+	if err := c.setReadDeadline(conn); err != nil {
+		return err
+	}
+	header, body, err := readMQTTPacket(r, c.config.MaxMessageSize)
+	if err != nil {
+		return ctxErr(ctx, err)
+	}
+	if header>>4 != mqttConnack || len(body) != 2 {
+		return fmt.Errorf("%w: expected CONNACK", errMQTTProtocol)
+	}
+	if body[1] != 0 {
+		return fmt.Errorf("mqtt connection refused: %s", mqttConnackReason(body[1]))
+	}
+
+	if err := w.write(mqttSubscribe<<4|0x02, c.subscribePacket()); err != nil {
+		return err
+	}
+
+	go c.ping(ctx, w)
+
+	subscribed := false
+	for {
+		if err := c.setReadDeadline(conn); err != nil {
+			return err
+		}
+		header, body, err := readMQTTPacket(r, c.config.MaxMessageSize)
+		if err != nil {
+			return ctxErr(ctx, err)
+		}
+		switch header >> 4 {
+		case mqttSuback:
+			if len(body) < 3 {
+				return fmt.Errorf("%w: invalid SUBACK", errMQTTProtocol)
+			}
+			for i, code := range body[2:] {
+				if code == 0x80 {
+					return fmt.Errorf("mqtt subscription to %q refused", c.config.Topics[i])
+				}
+			}
+			if !subscribed {
+				subscribed = true
+				onConnect()
+			}
+		case mqttPublish:
+			topic, payload, ack, err := parseMQTTPublish(header, body)
+			if err != nil {
+				return err
+			}
+			onMessage(message{topic: topic, payload: payload, ack: ack != nil})
+			if ack != nil {
+				// Messages are only acknowledged once they are queued, which
+				// holds back the broker while the queue is full.
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err := w.write(ack[0], ack[1:]); err != nil {
+					return err
+				}
+			}
+		case mqttPubrel:
+			if len(body) != 2 {
+				return fmt.Errorf("%w: invalid PUBREL", errMQTTProtocol)
+			}
+			if err := w.write(mqttPubcomp<<4, body); err != nil {
+				return err
+			}
+		case mqttPingresp:
+		default:
+			return fmt.Errorf("%w: unexpected packet type %d", errMQTTProtocol, header>>4)
+		}
+	}
+}
+
+func (c *mqttClient) dial(ctx context.Context) (net.Conn, error) {
+	u, err := url.Parse(c.config.URL)
+	if err != nil {
+		return nil, err
+	}
+	host := u.Host
+	switch u.Scheme {
+	case "tcp", "mqtt", "":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "1883")
+		}
+		return c.dialer.DialContext(ctx, "tcp", host)
+	case "ssl", "tls", "mqtts":
+		if u.Port() == "" {
+			host = net.JoinHostPort(u.Hostname(), "8883")
+		}
+		tlsDialer := &tls.Dialer{NetDialer: &c.dialer, Config: &tls.Config{ServerName: u.Hostname()}}
+		return tlsDialer.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("unsupported mqtt url scheme %q", u.Scheme)
+	}
+}
+
+// setReadDeadline makes reads fail when the broker does not respond to pings.
+func (c *mqttClient) setReadDeadline(conn net.Conn) error {
+	return conn.SetReadDeadline(time.Now().Add(c.config.KeepAlive * 3 / 2))
+}
+
+// ping keeps the connection alive until the context is canceled. Failed
+// pings are detected by the read deadline.
+func (c *mqttClient) ping(ctx context.Context, w *mqttWriter) {
+	ticker := time.NewTicker(c.config.KeepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			_ = w.write(mqttDisconnect<<4, nil)
+			return
+		case <-ticker.C:
+			if err := w.write(mqttPingreq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (c *mqttClient) connectPacket() []byte {
+	var flags byte = 0x02 // clean session
+	var payload []byte
+	payload = appendMQTTString(payload, c.config.ClientID)
+	if c.config.Username != "" {
+		flags |= 0x80
+		payload = appendMQTTString(payload, c.config.Username)
+		if c.config.Password != "" {
+			flags |= 0x40
+			payload = appendMQTTString(payload, c.config.Password)
+		}
+	}
+	packet := appendMQTTString(nil, "MQTT")
+	packet = append(packet, 4, flags)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(c.config.KeepAlive/time.Second))
+	return append(packet, payload...)
+}
+
+func (c *mqttClient) subscribePacket() []byte {
+	packet := binary.BigEndian.AppendUint16(nil, mqttSubscribeID)
+	for _, topic := range c.config.Topics {
+		packet = appendMQTTString(packet, topic)
+		packet = append(packet, 1) // QoS 1
+	}
+	return packet
+}
+
+// parseMQTTPublish returns the topic and payload of a PUBLISH packet and the
+// acknowledgement to send back for QoS 1 and 2 messages.
+func parseMQTTPublish(header byte, body []byte) (string, []byte, []byte, error) {
+	qos := (header >> 1) & 0x03
+	if len(body) < 2 {
+		return "", nil, nil, fmt.Errorf("%w: invalid PUBLISH", errMQTTProtocol)
+	}
+	topicLen := int(binary.BigEndian.Uint16(body))
+	if len(body) < 2+topicLen {
+		return "", nil, nil, fmt.Errorf("%w: invalid PUBLISH", errMQTTProtocol)
+	}
+	topic := string(body[2 : 2+topicLen])
+	body = body[2+topicLen:]
+	var ack []byte
+	switch qos {
+	case 0:
+	case 1, 2:
+		if len(body) < 2 {
+			return "", nil, nil, fmt.Errorf("%w: invalid PUBLISH", errMQTTProtocol)
+		}
+		ackType := mqttPuback
+		if qos == 2 {
+			ackType = mqttPubrec
+		}
+		ack = append([]byte{ackType << 4}, body[:2]...)
+		body = body[2:]
+	default:
+		return "", nil, nil, fmt.Errorf("%w: invalid QoS", errMQTTProtocol)
+	}
+	return topic, body, ack, nil
+}
+
+func mqttConnackReason(code byte) string {
+	switch code {
+	case 1:
+		return "unacceptable protocol version"
+	case 2:
+		return "identifier rejected"
+	case 3:
+		return "server unavailable"
+	case 4:
+		return "bad user name or password"
+	case 5:
+		return "not authorized"
+	default:
+		return fmt.Sprintf("code %d", code)
+	}
+}
+
+// mqttWriter serializes writes of packets to a connection.
+type mqttWriter struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (w *mqttWriter) write(header byte, body []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := w.conn.Write(encodeMQTTPacket(header, body))
+	return err
+}
+
+func encodeMQTTPacket(header byte, body []byte) []byte {
+	packet := []byte{header}
+	length := len(body)
+	for {
+		b := byte(length % 128)
+		length /= 128
+		if length > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if length == 0 {
+			break
+		}
+	}
+	return append(packet, body...)
+}
+
+// readMQTTPacket reads a packet whose body is at most maxLength bytes long.
+func readMQTTPacket(r *bufio.Reader, maxLength int) (byte, []byte, error) {
+	header, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, fmt.Errorf("%w: invalid remaining length", errMQTTProtocol)
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	if length > maxLength {
+		return 0, nil, fmt.Errorf("%w: packet of %d bytes exceeds the maximum message size of %d bytes", errMQTTProtocol, length, maxLength)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	return header, body, nil
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+// ctxErr returns the context error instead of the network error caused by
+// closing the connection when the context is canceled.
+func ctxErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
@@ -0,0 +1,260 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testMQTTBroker is an in-process MQTT broker accepting a single
+// subscription per connection.
+type testMQTTBroker struct {
+	ln    net.Listener
+	conns chan net.Conn
+}
+
+func newTestMQTTBroker(t *testing.T) *testMQTTBroker {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	b := &testMQTTBroker{ln: ln, conns: make(chan net.Conn, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			b.conns <- conn
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return b
+}
+
+func (b *testMQTTBroker) url() string {
+	return "tcp://" + b.ln.Addr().String()
+}
+
+type testMQTTConn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	connect []byte
+	topics  []string
+}
+
+// accept waits for a client to connect and subscribe.
+func (b *testMQTTBroker) accept(t *testing.T, returnCode byte) *testMQTTConn {
+	t.Helper()
+	var conn net.Conn
+	select {
+	case conn = <-b.conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for mqtt connection")
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := &testMQTTConn{conn: conn, r: bufio.NewReader(conn)}
+
+	header, body := c.read(t)
+	require.Equal(t, mqttConnect, header>>4)
+	c.connect = body
+	c.write(t, mqttConnack<<4, []byte{0, returnCode})
+	if returnCode != 0 {
+		return c
+	}
+
+	header, body = c.read(t)
+	require.Equal(t, mqttSubscribe, header>>4)
+	id, body := body[:2], body[2:]
+	codes := []byte{}
+	for len(body) > 0 {
+		n := int(binary.BigEndian.Uint16(body))
+		c.topics = append(c.topics, string(body[2:2+n]))
+		body = body[3+n:]
+		codes = append(codes, 1)
+	}
+	c.write(t, mqttSuback<<4, append(id, codes...))
+	return c
+}
+
+func (c *testMQTTConn) read(t *testing.T) (byte, []byte) {
+	t.Helper()
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	for {
+		header, body, err := readMQTTPacket(c.r, mqttMaxLength)
+		require.NoError(t, err)
+		if header>>4 != mqttPingreq {
+			return header, body
+		}
+		c.write(t, mqttPingresp<<4, nil)
+	}
+}
+
+func (c *testMQTTConn) write(t *testing.T, header byte, body []byte) {
+	t.Helper()
+	_, err := c.conn.Write(encodeMQTTPacket(header, body))
+	require.NoError(t, err)
+}
+
+func (c *testMQTTConn) publish(t *testing.T, topic string, payload string, qos byte) {
+	t.Helper()
+	body := appendMQTTString(nil, topic)
+	if qos > 0 {
+		body = binary.BigEndian.AppendUint16(body, 42)
+	}
+	c.write(t, mqttPublish<<4|qos<<1, append(body, payload...))
+	switch qos {
+	case 1:
+		header, body := c.read(t)
+		require.Equal(t, mqttPuback, header>>4)
+		require.Equal(t, []byte{0, 42}, body)
+	case 2:
+		header, body := c.read(t)
+		require.Equal(t, mqttPubrec, header>>4)
+		c.write(t, mqttPubrel<<4|0x02, body)
+		header, _ = c.read(t)
+		require.Equal(t, mqttPubcomp, header>>4)
+	}
+}
+
+type testMessage struct {
+	topic   string
+	payload string
+}
+
+func TestMQTTClient(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	client := newMQTTClient(SourceConfig{
+		Name:           "test",
+		URL:            broker.url(),
+		Username:       "user",
+		Password:       "secret",
+		ClientID:       "grafana-test",
+		Topics:         []string{"sensors/#", "devices/+/status"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: defaultMaxMessageSize,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	connected := make(chan struct{})
+	messages := make(chan testMessage, 10)
+	done := make(chan error)
+	go func() {
+		done <- client.run(ctx, func() { close(connected) }, func(msg message) {
+			messages <- testMessage{topic: msg.topic, payload: string(msg.payload)}
+		})
+	}()
+
+	conn := broker.accept(t, 0)
+	require.Contains(t, string(conn.connect), "grafana-test")
+	require.Contains(t, string(conn.connect), "user")
+	require.Contains(t, string(conn.connect), "secret")
+	require.Equal(t, []string{"sensors/#", "devices/+/status"}, conn.topics)
+	<-connected
+
+	conn.publish(t, "sensors/room1", `{"value":1}`, 0)
+	conn.publish(t, "sensors/room2", `{"value":2}`, 1)
+	conn.publish(t, "devices/a/status", `{"up":true}`, 2)
+	require.Equal(t, testMessage{"sensors/room1", `{"value":1}`}, <-messages)
+	require.Equal(t, testMessage{"sensors/room2", `{"value":2}`}, <-messages)
+	require.Equal(t, testMessage{"devices/a/status", `{"up":true}`}, <-messages)
+
+	// The client keeps the connection alive with pings.
+	time.Sleep(1200 * time.Millisecond)
+	conn.publish(t, "sensors/room1", `{"value":3}`, 0)
+	require.Equal(t, testMessage{"sensors/room1", `{"value":3}`}, <-messages)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestMQTTClient_ConnectionRefused(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	client := newMQTTClient(SourceConfig{
+		URL:            broker.url(),
+		Topics:         []string{"sensors/#"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: defaultMaxMessageSize,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- client.run(context.Background(), func() {}, func(message) {})
+	}()
+	broker.accept(t, 5)
+	require.ErrorContains(t, <-done, "not authorized")
+}
+
+func TestMQTTClient_BrokerDisconnect(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	client := newMQTTClient(SourceConfig{
+		URL:            broker.url(),
+		Topics:         []string{"sensors/#"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: defaultMaxMessageSize,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- client.run(context.Background(), func() {}, func(message) {})
+	}()
+	conn := broker.accept(t, 0)
+	require.NoError(t, conn.conn.Close())
+	require.Error(t, <-done)
+}
+
+func TestMQTTClient_AcknowledgesQueuedMessages(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	client := newMQTTClient(SourceConfig{
+		URL:            broker.url(),
+		Topics:         []string{"sensors/#"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: defaultMaxMessageSize,
+	})
+
+	received := make(chan message, 1)
+	queued := make(chan struct{})
+	go func() {
+		_ = client.run(context.Background(), func() {}, func(msg message) {
+			received <- msg
+			<-queued
+		})
+	}()
+	conn := broker.accept(t, 0)
+	conn.write(t, mqttPublish<<4|1<<1, append(binary.BigEndian.AppendUint16(appendMQTTString(nil, "sensors/a"), 42), "{}"...))
+	require.True(t, (<-received).ack)
+
+	// The message is acknowledged once onMessage returns.
+	require.NoError(t, conn.conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	_, _, err := readMQTTPacket(conn.r, mqttMaxLength)
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	require.True(t, netErr.Timeout())
+
+	close(queued)
+	header, body := conn.read(t)
+	require.Equal(t, mqttPuback, header>>4)
+	require.Equal(t, []byte{0, 42}, body)
+}
+
+func TestMQTTClient_MaxMessageSize(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	client := newMQTTClient(SourceConfig{
+		URL:            broker.url(),
+		Topics:         []string{"sensors/#"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: 16,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- client.run(context.Background(), func() {}, func(message) {})
+	}()
+	conn := broker.accept(t, 0)
+	conn.write(t, mqttPublish<<4, append(appendMQTTString(nil, "sensors/a"), `{"value":12345}`...))
+	require.ErrorContains(t, <-done, "exceeds the maximum message size")
+}
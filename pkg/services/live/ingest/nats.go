@@ -0,0 +1,243 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var errNATSProtocol = errors.New("nats protocol error")
+
+// natsMaxLineLength bounds the length of protocol lines read from the server.
+const natsMaxLineLength = 64 * 1024
+
+// natsClient is a minimal NATS client which subscribes to subjects and
+// receives the messages published to them.
+type natsClient struct {
+	config SourceConfig
+	dialer net.Dialer
+}
+
+func newNATSClient(config SourceConfig) *natsClient {
+	return &natsClient{config: config}
+}
+
+type natsServerInfo struct {
+	TLSRequired bool  `json:"tls_required"`
+	MaxPayload  int64 `json:"max_payload"`
+}
+
+type natsConnectOptions struct {
+	Verbose  bool   `json:"verbose"`
+	Pedantic bool   `json:"pedantic"`
+	Name     string `json:"name"`
+	Lang     string `json:"lang"`
+	Version  string `json:"version"`
+	Protocol int    `json:"protocol"`
+	User     string `json:"user,omitempty"`
+	Pass     string `json:"pass,omitempty"`
+	Token    string `json:"auth_token,omitempty"`
+}
+
+func (c *natsClient) run(ctx context.Context, onConnect func(), onMessage func(msg message)) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	u, err := url.Parse(c.config.URL)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		host = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	conn, err := c.dialer.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+
+	stop := context.AfterFunc(ctx, func() { _ = conn.SetDeadline(time.Now()) })
+	defer stop()
+
+	if err := c.setReadDeadline(conn); err != nil {
+		return err
+	}
+	r := bufio.NewReaderSize(conn, natsMaxLineLength)
+	line, err := readNATSLine(r)
+	if err != nil {
+		return ctxErr(ctx, err)
+	}
+	op, args := splitNATSOp(line)
+	if op != "INFO" {
+		return fmt.Errorf("%w: expected INFO", errNATSProtocol)
+	}
+	info := natsServerInfo{}
+	if err := json.Unmarshal([]byte(args), &info); err != nil {
+		return fmt.Errorf("%w: invalid INFO: %s", errNATSProtocol, err)
+	}
+	maxPayload := int64(c.config.MaxMessageSize)
+	if info.MaxPayload > 0 && info.MaxPayload < maxPayload {
+		maxPayload = info.MaxPayload
+	}
+
+	if info.TLSRequired || u.Scheme == "tls" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return ctxErr(ctx, err)
+		}
+		conn = tlsConn
+		r = bufio.NewReaderSize(conn, natsMaxLineLength)
+	}
+
+	w := &natsWriter{conn: conn}
+	if err := w.write(c.connectCommand()); err != nil {
+		return err
+	}
+	for i, subject := range c.config.Topics {
+		sub := "SUB " + subject + " "
+		if c.config.QueueGroup != "" {
+			sub += c.config.QueueGroup + " "
+		}
+		if err := w.write(sub + strconv.Itoa(i+1) + "\r\n"); err != nil {
+			return err
+		}
+	}
+	// The server answers the PING once it processed the commands above or
+	// sends an error when the connection or a subscription is refused.
+	if err := w.write("PING\r\n"); err != nil {
+		return err
+	}
+
+	go c.ping(ctx, w)
+
+	connected := false
+	for {
+		if err := c.setReadDeadline(conn); err != nil {
+			return err
+		}
+		line, err := readNATSLine(r)
+		if err != nil {
+			return ctxErr(ctx, err)
+		}
+		op, args := splitNATSOp(line)
+		switch op {
+		case "MSG":
+			subject, payload, err := readNATSMessage(r, args, maxPayload)
+			if err != nil {
+				return ctxErr(ctx, err)
+			}
+			onMessage(message{topic: subject, payload: payload})
+		case "PING":
+			if err := w.write("PONG\r\n"); err != nil {
+				return err
+			}
+		case "PONG":
+			if !connected {
+				connected = true
+				onConnect()
+			}
+		case "-ERR":
+			return fmt.Errorf("nats server error: %s", strings.Trim(args, "' "))
+		case "+OK", "INFO":
+		default:
+			return fmt.Errorf("%w: unexpected operation %q", errNATSProtocol, op)
+		}
+	}
+}
+
+func (c *natsClient) connectCommand() string {
+	options := natsConnectOptions{
+		Name:     "grafana-" + c.config.Name,
+		Lang:     "go",
+		Version:  "1.0.0",
+		Protocol: 1,
+		User:     c.config.Username,
+		Pass:     c.config.Password,
+		Token:    c.config.Token,
+	}
+	b, _ := json.Marshal(options)
+	return "CONNECT " + string(b) + "\r\n"
+}
+
+// setReadDeadline makes reads fail when the server does not respond to pings.
+func (c *natsClient) setReadDeadline(conn net.Conn) error {
+	return conn.SetReadDeadline(time.Now().Add(c.config.KeepAlive * 3 / 2))
+}
+
+// ping keeps the connection alive until the context is canceled. Failed
+// pings are detected by the read deadline.
+func (c *natsClient) ping(ctx context.Context, w *natsWriter) {
+	ticker := time.NewTicker(c.config.KeepAlive / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.write("PING\r\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// readNATSMessage reads the payload of a MSG <subject> <sid> [reply-to] <#bytes> operation.
+func readNATSMessage(r *bufio.Reader, args string, maxPayload int64) (string, []byte, error) {
+	fields := strings.Fields(args)
+	if len(fields) < 3 || len(fields) > 4 {
+		return "", nil, fmt.Errorf("%w: invalid MSG", errNATSProtocol)
+	}
+	size, err := strconv.ParseInt(fields[len(fields)-1], 10, 64)
+	if err != nil || size < 0 {
+		return "", nil, fmt.Errorf("%w: invalid MSG size", errNATSProtocol)
+	}
+	if size > maxPayload {
+		return "", nil, fmt.Errorf("%w: message of %d bytes exceeds the maximum message size of %d bytes", errNATSProtocol, size, maxPayload)
+	}
+	payload := make([]byte, size+2)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return "", nil, err
+	}
+	return fields[0], payload[:size], nil
+}
+
+// readNATSLine reads a line of at most the size of the buffer of r.
+func readNATSLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("%w: line too long", errNATSProtocol)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
+
+func splitNATSOp(line string) (string, string) {
+	op, args, _ := strings.Cut(line, " ")
+	return strings.ToUpper(op), strings.TrimSpace(args)
+}
+
+// natsWriter serializes writes of commands to a connection.
+type natsWriter struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (w *natsWriter) write(command string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, err := io.WriteString(w.conn, command)
+	return err
+}
@@ -0,0 +1,166 @@
+package ingest
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testNATSServer is an in-process NATS server speaking the client protocol.
+type testNATSServer struct {
+	ln    net.Listener
+	conns chan net.Conn
+}
+
+func newTestNATSServer(t *testing.T) *testNATSServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := &testNATSServer{ln: ln, conns: make(chan net.Conn, 10)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			s.conns <- conn
+		}
+	}()
+	t.Cleanup(func() { _ = ln.Close() })
+	return s
+}
+
+type testNATSConn struct {
+	conn    net.Conn
+	r       *bufio.Reader
+	connect string
+	subs    []string
+}
+
+// accept waits for a client to connect and subscribe to the number of subjects.
+func (s *testNATSServer) accept(t *testing.T, subjects int) *testNATSConn {
+	t.Helper()
+	var conn net.Conn
+	select {
+	case conn = <-s.conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for nats connection")
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	c := &testNATSConn{conn: conn, r: bufio.NewReader(conn)}
+	c.write(t, `INFO {"server_id":"test","max_payload":1048576}`+"\r\n")
+	c.connect = c.read(t)
+	require.True(t, strings.HasPrefix(c.connect, "CONNECT "))
+	for i := 0; i < subjects; i++ {
+		c.subs = append(c.subs, c.read(t))
+	}
+	require.Equal(t, "PING", c.read(t))
+	c.write(t, "PONG\r\n")
+	return c
+}
+
+func (c *testNATSConn) read(t *testing.T) string {
+	t.Helper()
+	require.NoError(t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := c.r.ReadString('\n')
+	require.NoError(t, err)
+	return strings.TrimRight(line, "\r\n")
+}
+
+func (c *testNATSConn) write(t *testing.T, s string) {
+	t.Helper()
+	_, err := c.conn.Write([]byte(s))
+	require.NoError(t, err)
+}
+
+func (c *testNATSConn) publish(t *testing.T, subject string, sid int, payload string) {
+	t.Helper()
+	c.write(t, fmt.Sprintf("MSG %s %d %d\r\n%s\r\n", subject, sid, len(payload), payload))
+}
+
+func TestNATSClient(t *testing.T) {
+	server := newTestNATSServer(t)
+	client := newNATSClient(SourceConfig{
+		Name:           "test",
+		URL:            "nats://" + server.ln.Addr().String(),
+		Token:          "secret",
+		QueueGroup:     "grafana",
+		Topics:         []string{"sensors.>", "devices.*.status"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: defaultMaxMessageSize,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	connected := make(chan struct{})
+	messages := make(chan testMessage, 10)
+	done := make(chan error)
+	go func() {
+		done <- client.run(ctx, func() { close(connected) }, func(msg message) {
+			messages <- testMessage{topic: msg.topic, payload: string(msg.payload)}
+		})
+	}()
+
+	conn := server.accept(t, 2)
+	require.Contains(t, conn.connect, `"auth_token":"secret"`)
+	require.Equal(t, []string{"SUB sensors.> grafana 1", "SUB devices.*.status grafana 2"}, conn.subs)
+	<-connected
+
+	conn.publish(t, "sensors.room1", 1, `{"value":1}`)
+	conn.write(t, "PING\r\n")
+	require.Equal(t, "PONG", conn.read(t))
+	conn.write(t, fmt.Sprintf("MSG devices.a.status 2 reply.to %d\r\n%s\r\n", len("up\r\n"), "up\r\n"))
+	require.Equal(t, testMessage{"sensors.room1", `{"value":1}`}, <-messages)
+	require.Equal(t, testMessage{"devices.a.status", "up\r\n"}, <-messages)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestNATSClient_ServerError(t *testing.T) {
+	server := newTestNATSServer(t)
+	client := newNATSClient(SourceConfig{
+		URL:            "nats://" + server.ln.Addr().String(),
+		Topics:         []string{"sensors.>"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: defaultMaxMessageSize,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- client.run(context.Background(), func() {}, func(message) {})
+	}()
+	var conn net.Conn
+	select {
+	case conn = <-server.conns:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for nats connection")
+	}
+	defer func() { _ = conn.Close() }()
+	_, err := conn.Write([]byte("INFO {}\r\n-ERR 'Authorization Violation'\r\n"))
+	require.NoError(t, err)
+	require.ErrorContains(t, <-done, "Authorization Violation")
+}
+
+func TestNATSClient_MaxMessageSize(t *testing.T) {
+	server := newTestNATSServer(t)
+	client := newNATSClient(SourceConfig{
+		URL:            "nats://" + server.ln.Addr().String(),
+		Topics:         []string{"sensors.>"},
+		KeepAlive:      time.Second,
+		MaxMessageSize: 16,
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- client.run(context.Background(), func() {}, func(message) {})
+	}()
+	conn := server.accept(t, 1)
+	conn.publish(t, "sensors.room1", 1, `{"value":12345678}`)
+	require.ErrorContains(t, <-done, "exceeds the maximum message size")
+}
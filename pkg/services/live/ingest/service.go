@@ -0,0 +1,410 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/live"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+var (
+	logger = log.New("live.ingest")
+
+	sourceLabels = []string{"org_id", "source"}
+
+	messagesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "messages_received_total",
+		Help:      "Number of messages received from message brokers.",
+	}, sourceLabels)
+	messagesDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "messages_dropped_total",
+		Help:      "Number of messages without acknowledgement dropped because the queue of a source was full.",
+	}, sourceLabels)
+	messagesFailed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "messages_failed_total",
+		Help:      "Number of messages which could not be published into Live channels.",
+	}, sourceLabels)
+	queueLength = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "queue_length",
+		Help:      "Number of messages waiting to be published into Live channels.",
+	}, sourceLabels)
+	queueWait = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "queue_wait_seconds_total",
+		Help:      "Time spent waiting for room in the queue of a source before acknowledging messages.",
+	}, sourceLabels)
+	connected = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "connected",
+		Help:      "Whether a source is connected to its message broker.",
+	}, sourceLabels)
+	reconnects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Subsystem: "live_ingest",
+		Name:      "reconnects_total",
+		Help:      "Number of times a source reconnected to its message broker.",
+	}, sourceLabels)
+)
+
+// client connects to a message broker and calls onMessage for every message
+// received until the context is canceled or the connection fails. Messages
+// with ack set are acknowledged to the broker once onMessage returned, unless
+// the context was canceled.
+type client interface {
+	run(ctx context.Context, onConnect func(), onMessage func(msg message)) error
+}
+
+// framePusher pushes a frame into a stream scope channel.
+type framePusher func(ctx context.Context, orgID int64, channel liveDto.Channel, frame *data.Frame) error
+
+// channelProcessor processes data published into a channel with a pipeline
+// channel rule. It returns false if there is no rule for the channel.
+type channelProcessor func(ctx context.Context, orgID int64, channel string, body []byte) (bool, error)
+
+func ProvideService(cfg *setting.Cfg, grafanaLive *live.GrafanaLive, kv kvstore.KVStore, secretsService secrets.Service,
+	routeRegister routing.RouteRegister) (*Service, error) {
+	settings, err := readSettings(cfg.Raw)
+	if err != nil {
+		return nil, err
+	}
+	s := &Service{
+		settings: settings,
+		store:    &sourceStore{kv: kv, secrets: secretsService},
+		reload:   make(chan struct{}, 1),
+		running:  map[sourceKey]*runningSource{},
+		pushFrame: func(ctx context.Context, orgID int64, channel liveDto.Channel, frame *data.Frame) error {
+			stream, err := grafanaLive.ManagedStreamRunner.GetOrCreateStream(orgID, channel.Scope, channel.Namespace)
+			if err != nil {
+				return err
+			}
+			return stream.Push(ctx, channel.Path, frame)
+		},
+		processInput: func(ctx context.Context, orgID int64, channel string, body []byte) (bool, error) {
+			if grafanaLive.Pipeline == nil {
+				return false, nil
+			}
+			return grafanaLive.Pipeline.ProcessInput(ctx, orgID, channel, body)
+		},
+	}
+	if settings.Enabled {
+		s.registerAPIEndpoints(routeRegister)
+	}
+	return s, nil
+}
+
+// Service subscribes to topics of MQTT brokers and subjects of NATS servers
+// configured by organizations and publishes the messages into Live channels.
+type Service struct {
+	settings     Settings
+	store        *sourceStore
+	reload       chan struct{}
+	running      map[sourceKey]*runningSource
+	pushFrame    framePusher
+	processInput channelProcessor
+}
+
+type sourceKey struct {
+	orgID int64
+	name  string
+}
+
+type source struct {
+	config    SourceConfig
+	client    client
+	converter pipeline.Converter
+	queue     chan message
+	labels    []string
+}
+
+type message struct {
+	topic   string
+	payload []byte
+	// ack is set for messages the broker expects to be acknowledged.
+	ack bool
+}
+
+// runningSource is a source whose goroutines run until it is stopped.
+type runningSource struct {
+	config SourceConfig
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func (r *runningSource) stop() {
+	r.cancel()
+	<-r.done
+}
+
+func newSource(config SourceConfig) (*source, error) {
+	converter, err := pipeline.NewConverter(config.Converter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid converter: %w", err)
+	}
+	src := &source{
+		config:    config,
+		converter: converter,
+		queue:     make(chan message, config.QueueSize),
+		labels:    []string{strconv.FormatInt(config.OrgID, 10), config.Name},
+	}
+	switch config.Type {
+	case SourceTypeMQTT:
+		src.client = newMQTTClient(config)
+	case SourceTypeNATS:
+		src.client = newNATSClient(config)
+	default:
+		return nil, fmt.Errorf("unsupported type %q", config.Type)
+	}
+	return src, nil
+}
+
+// IsDisabled returns true when live ingest is not enabled.
+func (s *Service) IsDisabled() bool {
+	return !s.settings.Enabled
+}
+
+// Run Service.
+func (s *Service) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.settings.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		s.sync(ctx)
+		select {
+		case <-ctx.Done():
+			for key, running := range s.running {
+				running.stop()
+				delete(s.running, key)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		case <-s.reload:
+		}
+	}
+}
+
+// triggerReload makes Run apply changes of the sources without waiting for
+// the reload interval.
+func (s *Service) triggerReload() {
+	select {
+	case s.reload <- struct{}{}:
+	default:
+	}
+}
+
+// sync starts the sources which were added or changed and stops the sources
+// which were removed or changed.
+func (s *Service) sync(ctx context.Context) {
+	configs, err := s.loadSourceConfigs(ctx)
+	if err != nil {
+		logger.Error("Failed to load live ingest sources", "error", err)
+		return
+	}
+	for key, running := range s.running {
+		if config, ok := configs[key]; !ok || !reflect.DeepEqual(config, running.config) {
+			logger.Info("Stopping live ingest source", "orgId", key.orgID, "source", key.name)
+			running.stop()
+			delete(s.running, key)
+		}
+	}
+	for key, config := range configs {
+		if _, ok := s.running[key]; ok {
+			continue
+		}
+		src, err := newSource(config)
+		if err != nil {
+			logger.Error("Invalid live ingest source", "orgId", key.orgID, "source", key.name, "error", err)
+			continue
+		}
+		s.running[key] = s.start(ctx, src)
+	}
+}
+
+func (s *Service) loadSourceConfigs(ctx context.Context) (map[sourceKey]SourceConfig, error) {
+	sources, err := s.store.listAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configs := map[sourceKey]SourceConfig{}
+	for orgID, orgSources := range sources {
+		for _, source := range orgSources {
+			secureSettings, err := s.store.decryptSecureSettings(ctx, source)
+			if err != nil {
+				logger.Error("Failed to decrypt live ingest source settings", "orgId", orgID, "source", source.Name, "error", err)
+				continue
+			}
+			configs[sourceKey{orgID: orgID, name: source.Name}] = newSourceConfig(s.settings, orgID, source, secureSettings)
+		}
+	}
+	return configs, nil
+}
+
+// start runs a source until it is stopped or the context is canceled.
+func (s *Service) start(ctx context.Context, src *source) *runningSource {
+	ctx, cancel := context.WithCancel(ctx)
+	running := &runningSource{config: src.config, cancel: cancel, done: make(chan struct{})}
+	logger.Info("Starting live ingest source", "orgId", src.config.OrgID, "source", src.config.Name, "type", src.config.Type, "url", src.config.URL)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		s.subscribe(ctx, src)
+	}()
+	go func() {
+		defer wg.Done()
+		s.publish(ctx, src)
+	}()
+	go func() {
+		wg.Wait()
+		close(running.done)
+	}()
+	return running
+}
+
+// subscribe keeps the source connected to its broker and queues received
+// messages. Messages which must be acknowledged wait for room in the queue,
+// which slows down the broker, so they are not lost when the pipeline is
+// slow. Other messages are dropped when the queue is full.
+func (s *Service) subscribe(ctx context.Context, src *source) {
+	name := src.config.Name
+	delay := src.config.ReconnectDelay
+	for {
+		var connectedAt time.Time
+		err := src.client.run(ctx, func() {
+			connectedAt = time.Now()
+			connected.WithLabelValues(src.labels...).Set(1)
+			logger.Info("Live ingest source connected", "orgId", src.config.OrgID, "source", name)
+		}, func(msg message) {
+			messagesReceived.WithLabelValues(src.labels...).Inc()
+			select {
+			case src.queue <- msg:
+			default:
+				if !msg.ack {
+					messagesDropped.WithLabelValues(src.labels...).Inc()
+					return
+				}
+				start := time.Now()
+				select {
+				case src.queue <- msg:
+				case <-ctx.Done():
+				}
+				queueWait.WithLabelValues(src.labels...).Add(time.Since(start).Seconds())
+			}
+			queueLength.WithLabelValues(src.labels...).Set(float64(len(src.queue)))
+		})
+		connected.WithLabelValues(src.labels...).Set(0)
+		if ctx.Err() != nil {
+			return
+		}
+		if !connectedAt.IsZero() && time.Since(connectedAt) > src.config.MaxReconnectDelay {
+			delay = src.config.ReconnectDelay
+		}
+		logger.Warn("Live ingest source disconnected", "orgId", src.config.OrgID, "source", name, "error", err, "reconnectIn", delay)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		reconnects.WithLabelValues(src.labels...).Inc()
+		delay *= 2
+		if delay > src.config.MaxReconnectDelay {
+			delay = src.config.MaxReconnectDelay
+		}
+	}
+}
+
+func (s *Service) publish(ctx context.Context, src *source) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg := <-src.queue:
+			queueLength.WithLabelValues(src.labels...).Set(float64(len(src.queue)))
+			if err := s.handleMessage(ctx, src, msg); err != nil {
+				messagesFailed.WithLabelValues(src.labels...).Inc()
+				logger.Error("Error publishing live ingest message", "orgId", src.config.OrgID, "source", src.config.Name, "topic", msg.topic, "error", err)
+			}
+		}
+	}
+}
+
+// handleMessage publishes a message through the pipeline channel rule of its
+// channel if there is one, otherwise it converts the message with the
+// converter of the source and pushes the frames into the channels.
+func (s *Service) handleMessage(ctx context.Context, src *source, msg message) error {
+	channelID := src.channel(msg.topic)
+	ok, err := s.processInput(ctx, src.config.OrgID, channelID, msg.payload)
+	if err != nil || ok {
+		return err
+	}
+
+	channel, err := liveDto.ParseChannel(channelID)
+	if err != nil {
+		return err
+	}
+	channelFrames, err := src.converter.Convert(ctx, pipeline.Vars{
+		OrgID:     src.config.OrgID,
+		Channel:   channelID,
+		Scope:     channel.Scope,
+		Namespace: channel.Namespace,
+		Path:      channel.Path,
+	}, msg.payload)
+	if err != nil {
+		return err
+	}
+	for _, cf := range channelFrames {
+		frameChannel := channel
+		if cf.Channel != "" {
+			frameChannel, err = liveDto.ParseChannel(cf.Channel)
+			if err != nil {
+				return err
+			}
+		}
+		if frameChannel.Scope != liveDto.ScopeStream {
+			return errors.New("frames can only be pushed into stream scope channels")
+		}
+		if err := s.pushFrame(ctx, src.config.OrgID, frameChannel, cf.Frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var invalidChannelPathChars = regexp.MustCompile(`[^A-Za-z0-9_\-/=.]`)
+
+// channel returns the channel messages of an MQTT topic or NATS subject are
+// published to. Topic levels and subject tokens become channel path segments.
+func (src *source) channel(topic string) string {
+	if src.config.Type == SourceTypeNATS {
+		topic = strings.ReplaceAll(topic, ".", "/")
+	}
+	path := invalidChannelPathChars.ReplaceAllString(strings.Trim(topic, "/"), "_")
+	return src.config.Channel + "/" + path
+}
@@ -0,0 +1,381 @@
+package ingest
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	liveDto "github.com/grafana/grafana-plugin-sdk-go/live"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/live/pipeline"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestReadSettings(t *testing.T) {
+	settings, err := readSettings(nil)
+	require.NoError(t, err)
+	require.Equal(t, Settings{
+		MaxMessageSize:    defaultMaxMessageSize,
+		QueueSize:         defaultQueueSize,
+		KeepAlive:         defaultKeepAlive,
+		ReconnectDelay:    defaultReconnectDelay,
+		MaxReconnectDelay: defaultMaxReconnectDelay,
+		ReloadInterval:    defaultReloadInterval,
+	}, settings)
+
+	raw, err := ini.Load([]byte("[live.ingest]\nenabled = true\nmax_message_size = 1024\nqueue_size = 10"))
+	require.NoError(t, err)
+	settings, err = readSettings(raw)
+	require.NoError(t, err)
+	require.True(t, settings.Enabled)
+	require.Equal(t, 1024, settings.MaxMessageSize)
+	require.Equal(t, 10, settings.QueueSize)
+
+	for _, invalid := range []string{
+		"[live.ingest]\nmax_message_size = 0",
+		"[live.ingest]\nmax_message_size = 300000000",
+		"[live.ingest]\nqueue_size = 0",
+		"[live.ingest]\nkeep_alive = 10ms",
+	} {
+		raw, err := ini.Load([]byte(invalid))
+		require.NoError(t, err)
+		_, err = readSettings(raw)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestSourceValidate(t *testing.T) {
+	valid := func() Source {
+		return Source{Name: "sensors", Type: SourceTypeMQTT, URL: "tcp://localhost:1883", Topics: []string{"sensors/#"}, Channel: "stream/iot"}
+	}
+	source := valid()
+	source.Topics = []string{" sensors/# ", ""}
+	source.Channel = "stream/iot/"
+	source.Converter = &pipeline.ConverterConfig{Type: pipeline.ConverterTypeInfluxAuto}
+	source.normalize()
+	require.NoError(t, source.validate())
+	require.Equal(t, []string{"sensors/#"}, source.Topics)
+	require.Equal(t, "stream/iot", source.Channel)
+	require.Equal(t, "labels_column", source.Converter.AutoInfluxConverterConfig.FrameFormat)
+
+	for name, modify := range map[string]func(*Source){
+		"name":      func(s *Source) { s.Name = "a/b" },
+		"type":      func(s *Source) { s.Type = "kafka" },
+		"url":       func(s *Source) { s.URL = "" },
+		"topics":    func(s *Source) { s.Topics = nil },
+		"channel":   func(s *Source) { s.Channel = "grafana/a" },
+		"converter": func(s *Source) { s.Converter = &pipeline.ConverterConfig{Type: "unknown"} },
+	} {
+		source := valid()
+		modify(&source)
+		source.normalize()
+		require.Error(t, source.validate(), name)
+	}
+}
+
+func TestProvideService(t *testing.T) {
+	cfg := setting.NewCfg()
+	s, err := ProvideService(cfg, nil, kvstore.NewFakeKVStore(), fakes.NewFakeSecretsService(), routing.NewRouteRegister())
+	require.NoError(t, err)
+	require.True(t, s.IsDisabled())
+
+	cfg.Raw, err = ini.Load([]byte("[live.ingest]\nenabled = true"))
+	require.NoError(t, err)
+	s, err = ProvideService(cfg, nil, kvstore.NewFakeKVStore(), fakes.NewFakeSecretsService(), routing.NewRouteRegister())
+	require.NoError(t, err)
+	require.False(t, s.IsDisabled())
+
+	cfg.Raw, err = ini.Load([]byte("[live.ingest]\nqueue_size = -1"))
+	require.NoError(t, err)
+	_, err = ProvideService(cfg, nil, kvstore.NewFakeKVStore(), fakes.NewFakeSecretsService(), routing.NewRouteRegister())
+	require.ErrorContains(t, err, "queue_size")
+}
+
+func TestSourceStore(t *testing.T) {
+	ctx := context.Background()
+	store := &sourceStore{kv: kvstore.NewFakeKVStore(), secrets: fakes.NewFakeSecretsService()}
+	source := Source{Name: "sensors", Type: SourceTypeMQTT, URL: "tcp://localhost:1883", Topics: []string{"sensors/#"}, Channel: "stream/iot"}
+
+	require.NoError(t, store.save(ctx, 2, source, map[string]string{securePassword: "secret"}))
+	require.NoError(t, store.save(ctx, 3, Source{Name: "other"}, nil))
+
+	sources, err := store.list(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, sources, 1)
+	secureSettings, err := store.decryptSecureSettings(ctx, sources[0])
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{securePassword: "secret"}, secureSettings)
+
+	// secure settings which are not set keep their stored values, empty values remove them
+	require.NoError(t, store.save(ctx, 2, source, map[string]string{secureToken: "token"}))
+	saved, ok, err := store.get(ctx, 2, "sensors")
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Len(t, saved.SecureSettings, 2)
+	require.NoError(t, store.save(ctx, 2, source, map[string]string{securePassword: ""}))
+	saved, _, err = store.get(ctx, 2, "sensors")
+	require.NoError(t, err)
+	require.Equal(t, []byte("token"), saved.SecureSettings[secureToken])
+	require.NotContains(t, saved.SecureSettings, securePassword)
+
+	all, err := store.listAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+
+	require.NoError(t, store.delete(ctx, 2, "sensors"))
+	sources, err = store.list(ctx, 2)
+	require.NoError(t, err)
+	require.Empty(t, sources)
+}
+
+func TestSourcesAPI(t *testing.T) {
+	s := &Service{
+		store:  &sourceStore{kv: kvstore.NewFakeKVStore(), secrets: fakes.NewFakeSecretsService()},
+		reload: make(chan struct{}, 1),
+	}
+	request := func(method string, name string, body string) (*contextmodel.ReqContext, *http.Request) {
+		req := httptest.NewRequest(method, "/api/live/ingest/sources/"+name, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = web.SetURLParams(req, map[string]string{":name": name})
+		return &contextmodel.ReqContext{
+			Context:      &web.Context{Req: req},
+			SignedInUser: &user.SignedInUser{OrgID: 2, OrgRole: org.RoleAdmin},
+		}, req
+	}
+
+	c, _ := request(http.MethodPut, "sensors", `{"type":"mqtt","url":"tcp://localhost:1883","topics":["sensors/#"],"channel":"stream/iot","secureSettings":{"password":"secret"}}`)
+	resp := s.saveSourceHandler(c)
+	require.Equal(t, http.StatusOK, resp.Status())
+	require.NotContains(t, string(resp.Body()), "secret")
+	require.Contains(t, string(resp.Body()), `"secureFields":{"password":true}`)
+	require.Len(t, s.reload, 1)
+
+	c, _ = request(http.MethodPut, "sensors", `{"type":"mqtt","url":"tcp://localhost:1883","topics":["sensors/#"],"channel":"grafana/iot"}`)
+	require.Equal(t, http.StatusBadRequest, s.saveSourceHandler(c).Status())
+
+	c, _ = request(http.MethodGet, "", "")
+	resp = s.listSourcesHandler(c)
+	require.Equal(t, http.StatusOK, resp.Status())
+	require.Contains(t, string(resp.Body()), `"name":"sensors"`)
+
+	c, _ = request(http.MethodDelete, "sensors", "")
+	require.Equal(t, http.StatusOK, s.deleteSourceHandler(c).Status())
+	c, _ = request(http.MethodDelete, "sensors", "")
+	require.Equal(t, http.StatusNotFound, s.deleteSourceHandler(c).Status())
+}
+
+func TestService_SyncsSources(t *testing.T) {
+	ctx := context.Background()
+	broker := newTestMQTTBroker(t)
+	s, _ := newTestService(t)
+	s.settings = testSettings()
+	source := Source{Name: "sensors", Type: SourceTypeMQTT, URL: broker.url(), Topics: []string{"sensors/#"}, Channel: "stream/iot"}
+	source.normalize()
+	require.NoError(t, s.store.save(ctx, 2, source, nil))
+
+	s.sync(ctx)
+	require.Len(t, s.running, 1)
+	conn := broker.accept(t, 0)
+	require.Equal(t, []string{"sensors/#"}, conn.topics)
+
+	// changed sources are restarted
+	source.Topics = []string{"devices/#"}
+	require.NoError(t, s.store.save(ctx, 2, source, nil))
+	s.sync(ctx)
+	conn = broker.accept(t, 0)
+	require.Equal(t, []string{"devices/#"}, conn.topics)
+
+	require.NoError(t, s.store.delete(ctx, 2, "sensors"))
+	s.sync(ctx)
+	require.Empty(t, s.running)
+}
+
+type testPushedFrame struct {
+	orgID   int64
+	channel string
+	frame   *data.Frame
+}
+
+type testPusher struct {
+	frames chan testPushedFrame
+	block  chan struct{}
+}
+
+func (p *testPusher) push(ctx context.Context, orgID int64, channel liveDto.Channel, frame *data.Frame) error {
+	if p.block != nil {
+		<-p.block
+	}
+	p.frames <- testPushedFrame{orgID: orgID, channel: channel.String(), frame: frame}
+	return nil
+}
+
+func newTestService(t *testing.T) (*Service, *testPusher) {
+	t.Helper()
+	pusher := &testPusher{frames: make(chan testPushedFrame, 100)}
+	s := &Service{
+		store:     &sourceStore{kv: kvstore.NewFakeKVStore(), secrets: fakes.NewFakeSecretsService()},
+		reload:    make(chan struct{}, 1),
+		running:   map[sourceKey]*runningSource{},
+		pushFrame: pusher.push,
+		processInput: func(context.Context, int64, string, []byte) (bool, error) {
+			return false, nil
+		},
+	}
+	return s, pusher
+}
+
+func newTestSource(t *testing.T, config SourceConfig) *source {
+	t.Helper()
+	src, err := newSource(config)
+	require.NoError(t, err)
+	return src
+}
+
+func testSettings() Settings {
+	return Settings{
+		Enabled:           true,
+		MaxMessageSize:    defaultMaxMessageSize,
+		QueueSize:         10,
+		KeepAlive:         time.Second,
+		ReconnectDelay:    10 * time.Millisecond,
+		MaxReconnectDelay: 100 * time.Millisecond,
+		ReloadInterval:    time.Minute,
+	}
+}
+
+func testSourceConfig(url string) SourceConfig {
+	return newSourceConfig(testSettings(), 2, Source{
+		Name:      "test",
+		Type:      SourceTypeMQTT,
+		URL:       url,
+		Topics:    []string{"sensors/#"},
+		Channel:   "stream/iot",
+		Converter: &pipeline.ConverterConfig{Type: pipeline.ConverterTypeJsonAuto},
+	}, nil)
+}
+
+func TestService_PublishesMessagesAndReconnects(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	s, pusher := newTestService(t)
+	running := s.start(context.Background(), newTestSource(t, testSourceConfig(broker.url())))
+
+	conn := broker.accept(t, 0)
+	conn.publish(t, "sensors/room 1", `{"temperature": 21.5}`, 1)
+	pushed := <-pusher.frames
+	require.Equal(t, int64(2), pushed.orgID)
+	require.Equal(t, "stream/iot/sensors/room_1", pushed.channel)
+	value, idx := pushed.frame.FieldByName("temperature")
+	require.GreaterOrEqual(t, idx, 0)
+	require.Equal(t, 21.5, *value.At(0).(*float64))
+
+	// The source reconnects when the broker closes the connection.
+	require.NoError(t, conn.conn.Close())
+	conn = broker.accept(t, 0)
+	conn.publish(t, "sensors/room2", `{"temperature": 19}`, 0)
+	pushed = <-pusher.frames
+	require.Equal(t, "stream/iot/sensors/room2", pushed.channel)
+	require.GreaterOrEqual(t, testutil.ToFloat64(reconnects.WithLabelValues("2", "test")), 1.0)
+
+	running.stop()
+}
+
+func TestService_QueueFull(t *testing.T) {
+	broker := newTestMQTTBroker(t)
+	config := testSourceConfig(broker.url())
+	config.Name = "slow"
+	config.QueueSize = 1
+	s, pusher := newTestService(t)
+	pusher.block = make(chan struct{})
+	src := newTestSource(t, config)
+	running := s.start(context.Background(), src)
+
+	received := testutil.ToFloat64(messagesReceived.WithLabelValues("2", "slow"))
+	dropped := testutil.ToFloat64(messagesDropped.WithLabelValues("2", "slow"))
+
+	conn := broker.accept(t, 0)
+	conn.publish(t, "sensors/room1", `{"temperature": 21.5}`, 1)
+	// Wait for the first message to be taken from the queue and block in push.
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(messagesReceived.WithLabelValues("2", "slow")) == received+1 && len(src.queue) == 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Messages without acknowledgement are dropped when the queue is full.
+	for i := 0; i < 4; i++ {
+		conn.publish(t, "sensors/room1", `{"temperature": 21.5}`, 0)
+	}
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(messagesReceived.WithLabelValues("2", "slow")) == received+5
+	}, 5*time.Second, 10*time.Millisecond)
+	// One message is being pushed, one is queued and the others are dropped.
+	require.Equal(t, dropped+3, testutil.ToFloat64(messagesDropped.WithLabelValues("2", "slow")))
+
+	// Messages with acknowledgement wait for room in the queue and are
+	// acknowledged once queued.
+	conn.write(t, mqttPublish<<4|1<<1, append(binary.BigEndian.AppendUint16(appendMQTTString(nil, "sensors/room1"), 7), `{"temperature": 22}`...))
+	require.NoError(t, conn.conn.SetReadDeadline(time.Now().Add(200*time.Millisecond)))
+	_, _, err := readMQTTPacket(conn.r, mqttMaxLength)
+	require.Error(t, err)
+
+	close(pusher.block)
+	header, body := conn.read(t)
+	require.Equal(t, mqttPuback, header>>4)
+	require.Equal(t, []byte{0, 7}, body)
+	for i := 0; i < 3; i++ {
+		<-pusher.frames
+	}
+	require.Equal(t, dropped+3, testutil.ToFloat64(messagesDropped.WithLabelValues("2", "slow")))
+
+	running.stop()
+}
+
+func TestService_HandleMessage(t *testing.T) {
+	t.Run("uses pipeline channel rules", func(t *testing.T) {
+		s, pusher := newTestService(t)
+		src := newTestSource(t, testSourceConfig("tcp://localhost"))
+		var processed []string
+		s.processInput = func(_ context.Context, orgID int64, channel string, body []byte) (bool, error) {
+			processed = append(processed, channel)
+			return true, nil
+		}
+		require.NoError(t, s.handleMessage(context.Background(), src, message{topic: "sensors/a", payload: []byte(`{}`)}))
+		require.Equal(t, []string{"stream/iot/sensors/a"}, processed)
+		require.Len(t, pusher.frames, 0)
+	})
+
+	t.Run("maps nats subjects to channel paths", func(t *testing.T) {
+		config := testSourceConfig("nats://localhost")
+		config.Type = SourceTypeNATS
+		config.Converter = &pipeline.ConverterConfig{
+			Type:                      pipeline.ConverterTypeInfluxAuto,
+			AutoInfluxConverterConfig: &pipeline.AutoInfluxConverterConfig{FrameFormat: "labels_column"},
+		}
+		s, pusher := newTestService(t)
+		require.NoError(t, s.handleMessage(context.Background(), newTestSource(t, config), message{
+			topic:   "sensors.room1",
+			payload: []byte("cpu,host=a usage=0.5 1600000000000000000"),
+		}))
+		pushed := <-pusher.frames
+		require.Equal(t, "stream/iot/sensors/room1/cpu", pushed.channel)
+	})
+
+	t.Run("returns conversion errors", func(t *testing.T) {
+		s, _ := newTestService(t)
+		err := s.handleMessage(context.Background(), newTestSource(t, testSourceConfig("tcp://localhost")), message{topic: "sensors/a", payload: []byte(`not json`)})
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,112 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+// kvNamespace is the namespace of the sources of an organization in the
+// key-value store, sources are stored by name.
+const kvNamespace = "live.ingest.sources"
+
+// sourceStore stores the sources of organizations with encrypted secure
+// settings.
+type sourceStore struct {
+	kv      kvstore.KVStore
+	secrets secrets.Service
+}
+
+// list returns the sources of an organization sorted by name.
+func (s *sourceStore) list(ctx context.Context, orgID int64) ([]Source, error) {
+	all, err := s.kv.GetAll(ctx, orgID, kvNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return decodeSources(all[orgID])
+}
+
+// listAll returns the sources of all organizations.
+func (s *sourceStore) listAll(ctx context.Context) (map[int64][]Source, error) {
+	all, err := s.kv.GetAll(ctx, kvstore.AllOrganizations, kvNamespace)
+	if err != nil {
+		return nil, err
+	}
+	sources := make(map[int64][]Source, len(all))
+	for orgID, values := range all {
+		orgSources, err := decodeSources(values)
+		if err != nil {
+			return nil, fmt.Errorf("org %d: %w", orgID, err)
+		}
+		sources[orgID] = orgSources
+	}
+	return sources, nil
+}
+
+func (s *sourceStore) get(ctx context.Context, orgID int64, name string) (Source, bool, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, name)
+	if err != nil || !ok {
+		return Source{}, false, err
+	}
+	source := Source{}
+	if err := json.Unmarshal([]byte(value), &source); err != nil {
+		return Source{}, false, fmt.Errorf("invalid source %q: %w", name, err)
+	}
+	return source, true, nil
+}
+
+// save stores a source. The secure settings are encrypted and replace the
+// stored values, stored values which are not set are kept.
+func (s *sourceStore) save(ctx context.Context, orgID int64, source Source, secureSettings map[string]string) error {
+	existing, ok, err := s.get(ctx, orgID, source.Name)
+	if err != nil {
+		return err
+	}
+	encrypted, err := s.secrets.EncryptJsonData(ctx, secureSettings, secrets.WithoutScope())
+	if err != nil {
+		return fmt.Errorf("error encrypting data: %w", err)
+	}
+	source.SecureSettings = map[string][]byte{}
+	if ok {
+		for key, value := range existing.SecureSettings {
+			source.SecureSettings[key] = value
+		}
+	}
+	for key, value := range encrypted {
+		if secureSettings[key] == "" {
+			delete(source.SecureSettings, key)
+			continue
+		}
+		source.SecureSettings[key] = value
+	}
+	value, err := json.Marshal(source)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, source.Name, string(value))
+}
+
+func (s *sourceStore) delete(ctx context.Context, orgID int64, name string) error {
+	return s.kv.Del(ctx, orgID, kvNamespace, name)
+}
+
+func (s *sourceStore) decryptSecureSettings(ctx context.Context, source Source) (map[string]string, error) {
+	return s.secrets.DecryptJsonData(ctx, source.SecureSettings)
+}
+
+func decodeSources(values map[string]string) ([]Source, error) {
+	sources := make([]Source, 0, len(values))
+	for name, value := range values {
+		source := Source{}
+		if err := json.Unmarshal([]byte(value), &source); err != nil {
+			return nil, fmt.Errorf("invalid source %q: %w", name, err)
+		}
+		sources = append(sources, source)
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources, nil
+}
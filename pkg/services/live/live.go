@@ -14,7 +14,6 @@ import (
 	"time"
 
 	"github.com/centrifugal/centrifuge"
-	"github.com/go-redis/redis/v8"
 	"github.com/gobwas/glob"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/live"
@@ -78,7 +77,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, frameCache managedstream.FrameCache) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -119,7 +118,6 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
-	redisHealthy := false
 	if g.IsHA() {
 		// Configure HA with Redis. In this case Centrifuge nodes
 		// will be connected over Redis PUB/SUB. Presence will work
@@ -127,40 +125,16 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		err := setupRedisLiveEngine(g, node)
 		if err != nil {
 			logger.Error("failed to setup redis live engine: %v", err)
-		} else {
-			redisHealthy = true
 		}
 	}
 
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
-	var managedStreamRunner *managedstream.Runner
-	var redisClient *redis.Client
-	if g.IsHA() && redisHealthy {
-		redisClient = redis.NewClient(&redis.Options{
-			Addr:     g.Cfg.LiveHAEngineAddress,
-			Password: g.Cfg.LiveHAEnginePassword,
-		})
-		cmd := redisClient.Ping(context.Background())
-		if _, err := cmd.Result(); err != nil {
-			logger.Error("live engine failed to ping redis, proceeding without live ha, error: %v", err)
-			redisClient = nil
-		}
-	}
-
-	if redisClient != nil {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewRedisFrameCache(redisClient),
-		)
-	} else {
-		managedStreamRunner = managedstream.NewRunner(
-			g.Publish,
-			channelLocalPublisher,
-			managedstream.NewMemoryFrameCache(),
-		)
-	}
+	managedStreamRunner := managedstream.NewRunner(
+		g.Publish,
+		channelLocalPublisher,
+		frameCache,
+	)
 
 	g.ManagedStreamRunner = managedStreamRunner
 
@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/api/routing"
@@ -17,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/authz/zanzana"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tests/testsuite"
 )
@@ -29,6 +31,7 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 	cfg := setting.NewCfg()
 
 	cfg.LiveHAEngine = "testredisunavailable"
+	frameCache := managedstream.ProvideFrameCache(cfg)
 
 	_, err := ProvideService(nil, cfg,
 		routing.NewRouteRegister(),
@@ -37,10 +40,19 @@ func Test_provideLiveService_RedisUnavailable(t *testing.T) {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		featuremgmt.WithFeatures(), acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, frameCache)
 
 	// Proceeds without live HA if redis is unavaialble
 	require.NoError(t, err)
+
+	// Managed streams fall back to the in-memory frame cache
+	frame, err := data.FrameToJSONCache(data.NewFrame("test"))
+	require.NoError(t, err)
+	_, err = frameCache.Update(context.Background(), 1, "stream/test/frame", frame)
+	require.NoError(t, err)
+	_, ok, err := frameCache.GetFrame(context.Background(), 1, "stream/test/frame")
+	require.NoError(t, err)
+	require.True(t, ok)
 }
 
 func Test_runConcurrentlyIfNeeded_Concurrent(t *testing.T) {
@@ -3,8 +3,12 @@ package managedstream
 import (
 	"context"
 	"encoding/json"
+	"sync"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 // FrameCache allows updating frame schema. Returns true is schema not changed.
@@ -13,6 +17,68 @@ type FrameCache interface {
 	GetActiveChannels(orgID int64) (map[string]json.RawMessage, error)
 	// GetFrame returns full JSON frame for a channel in org.
 	GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
+	// GetHistory returns the frames kept in history for a channel in org,
+	// merged into a single frame with the schema of the latest frame.
+	// Returns false if history is disabled or empty.
+	GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error)
 	// Update updates frame cache and returns true if schema changed.
 	Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error)
 }
+
+// ProvideFrameCache returns the frame cache shared by managed streams and the
+// Grafana datasource. Frames are kept in Redis when Live is configured with
+// an HA engine and Redis is reachable, and in memory otherwise. Redis is
+// connected on first use, so Grafana starts while Redis is unavailable.
+func ProvideFrameCache(cfg *setting.Cfg) FrameCache {
+	history := HistoryOptions{
+		MaxFrames: cfg.LiveManagedStreamHistoryMaxFrames,
+		MaxAge:    cfg.LiveManagedStreamHistoryMaxAge,
+	}
+
+	if cfg.LiveHAEngine == "" {
+		return NewMemoryFrameCache(history)
+	}
+
+	return &lazyFrameCache{connect: func() FrameCache {
+		redisClient := redis.NewClient(&redis.Options{
+			Addr:     cfg.LiveHAEngineAddress,
+			Password: cfg.LiveHAEnginePassword,
+		})
+		if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
+			logger.Error("Managed stream frame cache failed to ping redis, proceeding with in-memory cache", "error", err)
+			_ = redisClient.Close()
+			return NewMemoryFrameCache(history)
+		}
+		return NewRedisFrameCache(redisClient, history)
+	}}
+}
+
+// lazyFrameCache creates the frame cache on first use.
+type lazyFrameCache struct {
+	once    sync.Once
+	connect func() FrameCache
+	cache   FrameCache
+}
+
+func (c *lazyFrameCache) get() FrameCache {
+	c.once.Do(func() {
+		c.cache = c.connect()
+	})
+	return c.cache
+}
+
+func (c *lazyFrameCache) GetActiveChannels(orgID int64) (map[string]json.RawMessage, error) {
+	return c.get().GetActiveChannels(orgID)
+}
+
+func (c *lazyFrameCache) GetFrame(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	return c.get().GetFrame(ctx, orgID, channel)
+}
+
+func (c *lazyFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	return c.get().GetHistory(ctx, orgID, channel)
+}
+
+func (c *lazyFrameCache) Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error) {
+	return c.get().Update(ctx, orgID, channel, frameJson)
+}
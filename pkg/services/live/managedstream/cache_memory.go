@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 
@@ -12,16 +13,20 @@ import (
 
 // MemoryFrameCache ...
 type MemoryFrameCache struct {
-	mu     sync.RWMutex
-	frames map[int64]map[string]data.FrameJSONCache
-	log    log.Logger
+	mu      sync.RWMutex
+	frames  map[int64]map[string]data.FrameJSONCache
+	history map[int64]map[string][]historyEntry
+	opts    HistoryOptions
+	log     log.Logger
 }
 
 // NewMemoryFrameCache ...
-func NewMemoryFrameCache() *MemoryFrameCache {
+func NewMemoryFrameCache(history HistoryOptions) *MemoryFrameCache {
 	return &MemoryFrameCache{
-		frames: map[int64]map[string]data.FrameJSONCache{},
-		log:    log.New("live.memoryframecache"),
+		frames:  map[int64]map[string]data.FrameJSONCache{},
+		history: map[int64]map[string][]historyEntry{},
+		opts:    history,
+		log:     log.New("live.memoryframecache"),
 	}
 }
 
@@ -52,6 +57,16 @@ func (c *MemoryFrameCache) GetFrame(ctx context.Context, orgID int64, channel st
 	return raw, ok, nil
 }
 
+func (c *MemoryFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	if !c.opts.Enabled() {
+		return nil, false, nil
+	}
+	c.mu.RLock()
+	entries := trimHistory(c.history[orgID][channel], c.opts, time.Now())
+	c.mu.RUnlock()
+	return mergeHistory(entries)
+}
+
 func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel string, jsonFrame data.FrameJSONCache) (bool, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -61,6 +76,9 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	cachedJsonFrame, exists := c.frames[orgID][channel]
 	schemaUpdated := !exists || !cachedJsonFrame.SameSchema(&jsonFrame)
 	c.frames[orgID][channel] = jsonFrame
+	if c.opts.Enabled() {
+		c.appendHistory(orgID, channel, jsonFrame, time.Now())
+	}
 	c.log.Debug("Cache update",
 		"orgId", orgID,
 		"channel", channel,
@@ -68,3 +86,14 @@ func (c *MemoryFrameCache) Update(ctx context.Context, orgID int64, channel stri
 	)
 	return schemaUpdated, nil
 }
+
+func (c *MemoryFrameCache) appendHistory(orgID int64, channel string, jsonFrame data.FrameJSONCache, now time.Time) {
+	if _, ok := c.history[orgID]; !ok {
+		c.history[orgID] = map[string][]historyEntry{}
+	}
+	entries := append(c.history[orgID][channel], historyEntry{
+		Time:  now.UnixMilli(),
+		Frame: jsonFrame.Bytes(data.IncludeAll),
+	})
+	c.history[orgID][channel] = trimHistory(entries, c.opts, now)
+}
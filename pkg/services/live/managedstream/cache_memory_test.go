@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -62,8 +63,80 @@ func testFrameCache(t *testing.T, c FrameCache) {
 	require.NotEqual(t, string(channels["test"]), string(schema))
 }
 
+func testFrameCacheHistory(t *testing.T, c FrameCache) {
+	push := func(orgID int64, frame *data.Frame) {
+		frameJsonCache, err := data.FrameToJSONCache(frame)
+		require.NoError(t, err)
+		_, err = c.Update(context.Background(), orgID, "history", frameJsonCache)
+		require.NoError(t, err)
+	}
+	getHistory := func(orgID int64) *data.Frame {
+		frameJSON, ok, err := c.GetHistory(context.Background(), orgID, "history")
+		require.NoError(t, err)
+		require.True(t, ok)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		return &f
+	}
+
+	// Make sure history is empty before the first push.
+	_, ok, err := c.GetHistory(context.Background(), 1, "history")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Push more frames than kept in history.
+	for i := int64(1); i <= 4; i++ {
+		push(1, data.NewFrame("cpu", data.NewField("value", nil, []int64{i, i * 10})))
+	}
+	push(2, data.NewFrame("cpu", data.NewField("value", nil, []int64{100})))
+
+	// Make sure only the last frames are kept and merged in one frame.
+	f := getHistory(1)
+	require.Equal(t, 6, f.Rows())
+	require.Equal(t, int64(2), f.Fields[0].At(0))
+	require.Equal(t, int64(40), f.Fields[0].At(5))
+
+	// Make sure history is kept per org.
+	require.Equal(t, 1, getHistory(2).Rows())
+
+	// Make sure frames with another schema than the latest frame are skipped.
+	push(1, data.NewFrame("cpu", data.NewField("value", nil, []float64{0.5})))
+	f = getHistory(1)
+	require.Equal(t, 1, f.Rows())
+	require.Equal(t, 0.5, f.Fields[0].At(0))
+}
+
 func TestMemoryFrameCache(t *testing.T) {
-	c := NewMemoryFrameCache()
+	c := NewMemoryFrameCache(HistoryOptions{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
+
+	// History is disabled by default.
+	_, ok, err := c.GetHistory(context.Background(), 1, "test")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestMemoryFrameCacheHistory(t *testing.T) {
+	c := NewMemoryFrameCache(HistoryOptions{MaxFrames: 3})
+	testFrameCacheHistory(t, c)
+
+	t.Run("drops frames older than max age", func(t *testing.T) {
+		c := NewMemoryFrameCache(HistoryOptions{MaxAge: time.Minute})
+		frameJsonCache, err := data.FrameToJSONCache(data.NewFrame("cpu", data.NewField("value", nil, []int64{1})))
+		require.NoError(t, err)
+
+		c.mu.Lock()
+		c.frames[1] = map[string]data.FrameJSONCache{"test": frameJsonCache}
+		c.appendHistory(1, "test", frameJsonCache, time.Now().Add(-2*time.Minute))
+		c.appendHistory(1, "test", frameJsonCache, time.Now())
+		c.mu.Unlock()
+
+		frameJSON, ok, err := c.GetHistory(context.Background(), 1, "test")
+		require.NoError(t, err)
+		require.True(t, ok)
+		var f data.Frame
+		require.NoError(t, json.Unmarshal(frameJSON, &f))
+		require.Equal(t, 1, f.Rows())
+	})
 }
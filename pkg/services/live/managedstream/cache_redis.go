@@ -18,13 +18,15 @@ type RedisFrameCache struct {
 	mu          sync.RWMutex
 	redisClient *redis.Client
 	frames      map[int64]map[string]data.FrameJSONCache
+	opts        HistoryOptions
 }
 
 // NewRedisFrameCache ...
-func NewRedisFrameCache(redisClient *redis.Client) *RedisFrameCache {
+func NewRedisFrameCache(redisClient *redis.Client, history HistoryOptions) *RedisFrameCache {
 	return &RedisFrameCache{
 		frames:      map[int64]map[string]data.FrameJSONCache{},
 		redisClient: redisClient,
+		opts:        history,
 	}
 }
 
@@ -55,6 +57,26 @@ func (c *RedisFrameCache) GetFrame(ctx context.Context, orgID int64, channel str
 	return json.RawMessage(result["frame"]), true, nil
 }
 
+func (c *RedisFrameCache) GetHistory(ctx context.Context, orgID int64, channel string) (json.RawMessage, bool, error) {
+	if !c.opts.Enabled() {
+		return nil, false, nil
+	}
+	key := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+	result, err := c.redisClient.LRange(ctx, key, 0, -1).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	entries := make([]historyEntry, 0, len(result))
+	for _, item := range result {
+		var entry historyEntry
+		if err := json.Unmarshal([]byte(item), &entry); err != nil {
+			return nil, false, err
+		}
+		entries = append(entries, entry)
+	}
+	return mergeHistory(trimHistory(entries, c.opts, time.Now()))
+}
+
 const (
 	frameCacheTTL = 7 * 24 * time.Hour
 )
@@ -81,6 +103,24 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 	})
 	pipe.Expire(ctx, key, frameCacheTTL)
 
+	if c.opts.Enabled() {
+		entry, err := json.Marshal(historyEntry{
+			Time:  time.Now().UnixMilli(),
+			Frame: jsonFrame.Bytes(data.IncludeAll),
+		})
+		if err != nil {
+			return false, err
+		}
+		historyKey := getHistoryKey(orgchannel.PrependOrgID(orgID, channel))
+		historyTTL := frameCacheTTL
+		if c.opts.MaxAge > 0 {
+			historyTTL = c.opts.MaxAge
+		}
+		pipe.RPush(ctx, historyKey, entry)
+		pipe.LTrim(ctx, historyKey, int64(-c.opts.maxFrames()), -1)
+		pipe.Expire(ctx, historyKey, historyTTL)
+	}
+
 	replies, err := pipe.Exec(ctx)
 	if err != nil {
 		return false, err
@@ -110,3 +150,7 @@ func (c *RedisFrameCache) Update(ctx context.Context, orgID int64, channel strin
 func getCacheKey(channelID string) string {
 	return "gf_live.managed_stream." + channelID
 }
+
+func getHistoryKey(channelID string) string {
+	return "gf_live.managed_stream_history." + channelID
+}
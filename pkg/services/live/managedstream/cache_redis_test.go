@@ -1,6 +1,7 @@
 package managedstream
 
 import (
+	"context"
 	"os"
 	"testing"
 
@@ -30,7 +31,11 @@ func TestIntegrationRedisCacheStorage(t *testing.T) {
 		Addr: addr,
 		DB:   db,
 	})
-	c := NewRedisFrameCache(redisClient)
+	c := NewRedisFrameCache(redisClient, HistoryOptions{})
 	require.NotNil(t, c)
 	testFrameCache(t, c)
+
+	c = NewRedisFrameCache(redisClient, HistoryOptions{MaxFrames: 3})
+	require.NoError(t, redisClient.Del(context.Background(), getHistoryKey("1/history"), getHistoryKey("2/history")).Err())
+	testFrameCacheHistory(t, c)
 }
@@ -0,0 +1,100 @@
+package managedstream
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// defaultHistoryMaxFrames bounds the history when it is only limited by age.
+const defaultHistoryMaxFrames = 1000
+
+// HistoryOptions configures the history kept for each managed stream channel.
+// History is disabled when both MaxFrames and MaxAge are zero.
+type HistoryOptions struct {
+	// MaxFrames is the maximum number of frames kept per channel.
+	MaxFrames int
+	// MaxAge is the maximum age of the frames kept per channel.
+	MaxAge time.Duration
+}
+
+// Enabled returns true if frames pushed to channels are kept in history.
+func (o HistoryOptions) Enabled() bool {
+	return o.MaxFrames > 0 || o.MaxAge > 0
+}
+
+func (o HistoryOptions) maxFrames() int {
+	if o.MaxFrames > 0 {
+		return o.MaxFrames
+	}
+	return defaultHistoryMaxFrames
+}
+
+// historyEntry is a frame pushed to a channel.
+type historyEntry struct {
+	Time  int64           `json:"time"`
+	Frame json.RawMessage `json:"frame"`
+}
+
+// trimHistory drops the entries exceeding the history limits.
+func trimHistory(entries []historyEntry, opts HistoryOptions, now time.Time) []historyEntry {
+	if n := len(entries) - opts.maxFrames(); n > 0 {
+		entries = entries[n:]
+	}
+	if opts.MaxAge > 0 {
+		minTime := now.Add(-opts.MaxAge).UnixMilli()
+		for len(entries) > 0 && entries[0].Time < minTime {
+			entries = entries[1:]
+		}
+	}
+	return entries
+}
+
+// mergeHistory merges the frames in history into a single frame with the
+// schema of the latest frame. Frames with another schema are skipped.
+func mergeHistory(entries []historyEntry) (json.RawMessage, bool, error) {
+	if len(entries) == 0 {
+		return nil, false, nil
+	}
+
+	frames := make([]*data.Frame, 0, len(entries))
+	for _, entry := range entries {
+		frame := &data.Frame{}
+		if err := json.Unmarshal(entry.Frame, frame); err != nil {
+			return nil, false, err
+		}
+		frames = append(frames, frame)
+	}
+
+	latest := frames[len(frames)-1]
+	merged := latest.EmptyCopy()
+	for _, frame := range frames {
+		if !sameSchema(latest, frame) {
+			continue
+		}
+		for i := 0; i < frame.Rows(); i++ {
+			merged.AppendRow(frame.RowCopy(i)...)
+		}
+	}
+
+	frameJSON, err := data.FrameToJSON(merged, data.IncludeAll)
+	if err != nil {
+		return nil, false, err
+	}
+	return frameJSON, true, nil
+}
+
+func sameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name ||
+			a.Fields[i].Type() != b.Fields[i].Type() ||
+			a.Fields[i].Labels.String() != b.Fields[i].Labels.String() {
+			return false
+		}
+	}
+	return true
+}
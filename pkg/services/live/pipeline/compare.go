@@ -12,3 +12,14 @@ const (
 	NumberCompareOpEq  NumberCompareOp = "eq"
 	NumberCompareOpNe  NumberCompareOp = "ne"
 )
+
+// Aggregation is a function aggregating the values of a field.
+type Aggregation string
+
+// Known Aggregation types.
+const (
+	AggregationMin  Aggregation = "min"
+	AggregationMax  Aggregation = "max"
+	AggregationAvg  Aggregation = "avg"
+	AggregationLast Aggregation = "last"
+)
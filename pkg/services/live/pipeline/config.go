@@ -50,11 +50,41 @@ type KeepFieldsFrameProcessorConfig struct {
 	FieldNames []string `json:"fieldNames"`
 }
 
+type RenameFieldsFrameProcessorConfig struct {
+	// Renames maps field names to their new names.
+	Renames map[string]string `json:"renames"`
+}
+
+type ComputeFieldFrameProcessorConfig struct {
+	FieldName string `json:"fieldName"`
+	// Expression is an arithmetic expression over the values of other fields
+	// in the same row, for example "(used / total) * 100".
+	Expression string `json:"expression"`
+}
+
+type DownsampleFrameProcessorConfig struct {
+	// IntervalMilliseconds is the size of the fixed time windows frames are aggregated into.
+	IntervalMilliseconds int64 `json:"intervalMs"`
+	// Aggregation of numeric fields, last by default.
+	Aggregation Aggregation `json:"aggregation,omitempty"`
+	// FieldAggregations overrides Aggregation for some fields.
+	FieldAggregations map[string]Aggregation `json:"fieldAggregations,omitempty"`
+}
+
+type DropDuplicatesFrameProcessorConfig struct {
+	// FieldNames to compare, all fields except time fields by default.
+	FieldNames []string `json:"fieldNames,omitempty"`
+}
+
 type FrameProcessorConfig struct {
-	Type                      string                          `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
-	DropFieldsProcessorConfig *DropFieldsFrameProcessorConfig `json:"dropFields,omitempty"`
-	KeepFieldsProcessorConfig *KeepFieldsFrameProcessorConfig `json:"keepFields,omitempty"`
-	MultipleProcessorConfig   *MultipleFrameProcessorConfig   `json:"multiple,omitempty"`
+	Type                          string                              `json:"type" ts_type:"Omit<keyof FrameProcessorConfig, 'type'>"`
+	DropFieldsProcessorConfig     *DropFieldsFrameProcessorConfig     `json:"dropFields,omitempty"`
+	KeepFieldsProcessorConfig     *KeepFieldsFrameProcessorConfig     `json:"keepFields,omitempty"`
+	MultipleProcessorConfig       *MultipleFrameProcessorConfig       `json:"multiple,omitempty"`
+	RenameFieldsProcessorConfig   *RenameFieldsFrameProcessorConfig   `json:"renameFields,omitempty"`
+	ComputeFieldProcessorConfig   *ComputeFieldFrameProcessorConfig   `json:"computeField,omitempty"`
+	DownsampleProcessorConfig     *DownsampleFrameProcessorConfig     `json:"downsample,omitempty"`
+	DropDuplicatesProcessorConfig *DropDuplicatesFrameProcessorConfig `json:"dropDuplicates,omitempty"`
 }
 
 type MultipleFrameProcessorConfig struct {
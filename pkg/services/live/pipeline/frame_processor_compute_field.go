@@ -0,0 +1,216 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// ComputeFieldFrameProcessor can add a field to a data.Frame computed from
+// the values of other fields in the same row. Expressions support numbers,
+// field names, the + - * / operators, parentheses and the abs, min, max,
+// sqrt, round, floor and ceil functions. Fields with names that are not valid
+// identifiers can be referenced with field("name"). The computed field is a
+// nullable float64 field, with null values where a referenced value is null
+// or not a number.
+type ComputeFieldFrameProcessor struct {
+	config ComputeFieldFrameProcessorConfig
+	expr   ast.Expr
+}
+
+func NewComputeFieldFrameProcessor(config ComputeFieldFrameProcessorConfig) (*ComputeFieldFrameProcessor, error) {
+	if config.FieldName == "" {
+		return nil, fmt.Errorf("missing field name for %s", FrameProcessorTypeComputeField)
+	}
+	expr, err := parser.ParseExpr(config.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", config.Expression, err)
+	}
+	if err := checkComputeExpr(expr); err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", config.Expression, err)
+	}
+	return &ComputeFieldFrameProcessor{config: config, expr: expr}, nil
+}
+
+const FrameProcessorTypeComputeField = "computeField"
+
+func (p *ComputeFieldFrameProcessor) Type() string {
+	return FrameProcessorTypeComputeField
+}
+
+func (p *ComputeFieldFrameProcessor) ProcessFrame(_ context.Context, _ Vars, frame *data.Frame) (*data.Frame, error) {
+	fields := make(map[string]*data.Field, len(frame.Fields))
+	for _, field := range frame.Fields {
+		fields[field.Name] = field
+	}
+
+	rows := frame.Rows()
+	values := make([]*float64, rows)
+	for i := 0; i < rows; i++ {
+		value, err := evalComputeExpr(p.expr, fields, i)
+		if err != nil {
+			return nil, err
+		}
+		if !math.IsNaN(value) && !math.IsInf(value, 0) {
+			values[i] = &value
+		}
+	}
+
+	computed := data.NewField(p.config.FieldName, nil, values)
+	for i, field := range frame.Fields {
+		if field.Name == p.config.FieldName {
+			frame.Fields[i] = computed
+			return frame, nil
+		}
+	}
+	frame.Fields = append(frame.Fields, computed)
+	return frame, nil
+}
+
+var computeFuncs = map[string]func(args ...float64) float64{
+	"abs":   func(args ...float64) float64 { return math.Abs(args[0]) },
+	"sqrt":  func(args ...float64) float64 { return math.Sqrt(args[0]) },
+	"round": func(args ...float64) float64 { return math.Round(args[0]) },
+	"floor": func(args ...float64) float64 { return math.Floor(args[0]) },
+	"ceil":  func(args ...float64) float64 { return math.Ceil(args[0]) },
+	"min":   func(args ...float64) float64 { return math.Min(args[0], args[1]) },
+	"max":   func(args ...float64) float64 { return math.Max(args[0], args[1]) },
+}
+
+var computeFuncArgs = map[string]int{
+	"abs": 1, "sqrt": 1, "round": 1, "floor": 1, "ceil": 1, "min": 2, "max": 2,
+}
+
+// checkComputeExpr makes sure an expression only uses supported syntax.
+func checkComputeExpr(expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT && e.Kind != token.FLOAT {
+			return fmt.Errorf("unsupported literal %s", e.Value)
+		}
+	case *ast.Ident:
+	case *ast.ParenExpr:
+		return checkComputeExpr(e.X)
+	case *ast.UnaryExpr:
+		if e.Op != token.SUB && e.Op != token.ADD {
+			return fmt.Errorf("unsupported operator %s", e.Op)
+		}
+		return checkComputeExpr(e.X)
+	case *ast.BinaryExpr:
+		switch e.Op {
+		case token.ADD, token.SUB, token.MUL, token.QUO:
+		default:
+			return fmt.Errorf("unsupported operator %s", e.Op)
+		}
+		if err := checkComputeExpr(e.X); err != nil {
+			return err
+		}
+		return checkComputeExpr(e.Y)
+	case *ast.CallExpr:
+		name, ok := e.Fun.(*ast.Ident)
+		if !ok {
+			return fmt.Errorf("unsupported function call")
+		}
+		if name.Name == "field" {
+			if len(e.Args) != 1 {
+				return fmt.Errorf("field expects a field name")
+			}
+			if lit, ok := e.Args[0].(*ast.BasicLit); !ok || lit.Kind != token.STRING {
+				return fmt.Errorf("field expects a field name")
+			}
+			return nil
+		}
+		numArgs, ok := computeFuncArgs[name.Name]
+		if !ok {
+			return fmt.Errorf("unknown function %s", name.Name)
+		}
+		if len(e.Args) != numArgs {
+			return fmt.Errorf("%s expects %d arguments", name.Name, numArgs)
+		}
+		for _, arg := range e.Args {
+			if err := checkComputeExpr(arg); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported expression")
+	}
+	return nil
+}
+
+// evalComputeExpr evaluates an expression for a row. Missing, null and non
+// numeric values evaluate to NaN.
+func evalComputeExpr(expr ast.Expr, fields map[string]*data.Field, row int) (float64, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		return strconv.ParseFloat(e.Value, 64)
+	case *ast.Ident:
+		return fieldFloatAt(fields[e.Name], row), nil
+	case *ast.ParenExpr:
+		return evalComputeExpr(e.X, fields, row)
+	case *ast.UnaryExpr:
+		x, err := evalComputeExpr(e.X, fields, row)
+		if err != nil {
+			return 0, err
+		}
+		if e.Op == token.SUB {
+			return -x, nil
+		}
+		return x, nil
+	case *ast.BinaryExpr:
+		x, err := evalComputeExpr(e.X, fields, row)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evalComputeExpr(e.Y, fields, row)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			return x / y, nil
+		}
+	case *ast.CallExpr:
+		name := e.Fun.(*ast.Ident).Name
+		if name == "field" {
+			fieldName, err := strconv.Unquote(e.Args[0].(*ast.BasicLit).Value)
+			if err != nil {
+				return 0, err
+			}
+			return fieldFloatAt(fields[fieldName], row), nil
+		}
+		args := make([]float64, 0, len(e.Args))
+		for _, arg := range e.Args {
+			value, err := evalComputeExpr(arg, fields, row)
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, value)
+		}
+		return computeFuncs[name](args...), nil
+	}
+	return 0, fmt.Errorf("unsupported expression")
+}
+
+func fieldFloatAt(field *data.Field, row int) float64 {
+	if field == nil {
+		return math.NaN()
+	}
+	value, err := field.NullableFloatAt(row)
+	if err != nil || value == nil {
+		return math.NaN()
+	}
+	return *value
+}
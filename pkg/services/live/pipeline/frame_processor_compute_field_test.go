@@ -0,0 +1,63 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeFieldFrameProcessor(t *testing.T) {
+	frame := func() *data.Frame {
+		return data.NewFrame("test",
+			data.NewField("a", nil, []float64{1, 4, -9}),
+			data.NewField("b", nil, []*float64{float64Ptr(2), nil, float64Ptr(3)}),
+			data.NewField("value 1", nil, []int64{10, 20, 30}),
+		)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		want       []*float64
+	}{
+		{name: "arithmetic", expression: "(a + b) * 2 - 1", want: []*float64{float64Ptr(5), nil, float64Ptr(-13)}},
+		{name: "unary", expression: "-a", want: []*float64{float64Ptr(-1), float64Ptr(-4), float64Ptr(9)}},
+		{name: "functions", expression: "max(sqrt(abs(a)), 1.5)", want: []*float64{float64Ptr(1.5), float64Ptr(2), float64Ptr(3)}},
+		{name: "field_reference", expression: `field("value 1") / 10`, want: []*float64{float64Ptr(1), float64Ptr(2), float64Ptr(3)}},
+		{name: "division_by_zero", expression: "a / 0", want: []*float64{nil, nil, nil}},
+		{name: "missing_field", expression: "c + 1", want: []*float64{nil, nil, nil}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+				FieldName:  "result",
+				Expression: tt.expression,
+			})
+			require.NoError(t, err)
+			result, err := p.ProcessFrame(context.Background(), Vars{}, frame())
+			require.NoError(t, err)
+			require.Len(t, result.Fields, 4)
+			field := result.Fields[3]
+			require.Equal(t, "result", field.Name)
+			for i, want := range tt.want {
+				require.Equal(t, want, field.At(i), "row %d", i)
+			}
+		})
+	}
+}
+
+func TestComputeFieldFrameProcessor_InvalidExpression(t *testing.T) {
+	for _, expression := range []string{"", "a +", `"text"`, "a % 2", "a == b", "unknown(a)", "min(a)", "field(a)", "a.b"} {
+		_, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+			FieldName:  "result",
+			Expression: expression,
+		})
+		require.Error(t, err, expression)
+	}
+}
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,195 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DownsampleFrameProcessor aggregates rows of frames into fixed time windows
+// based on the first time field of a frame. Frames are buffered per channel
+// until a window is closed by a row in a later window, then a frame with one
+// row per closed window is passed further. Numeric fields are aggregated with
+// the configured aggregation, other fields keep the last value in a window.
+type DownsampleFrameProcessor struct {
+	config   DownsampleFrameProcessorConfig
+	interval time.Duration
+
+	mu      sync.Mutex
+	windows map[string]*downsampleWindow
+}
+
+type downsampleWindow struct {
+	start time.Time
+	frame *data.Frame
+}
+
+func NewDownsampleFrameProcessor(config DownsampleFrameProcessorConfig) (*DownsampleFrameProcessor, error) {
+	if config.IntervalMilliseconds <= 0 {
+		return nil, errors.New("downsample interval must be positive")
+	}
+	if config.Aggregation == "" {
+		config.Aggregation = AggregationLast
+	}
+	if !isKnownAggregation(config.Aggregation) {
+		return nil, fmt.Errorf("unknown aggregation: %s", config.Aggregation)
+	}
+	for _, aggregation := range config.FieldAggregations {
+		if !isKnownAggregation(aggregation) {
+			return nil, fmt.Errorf("unknown aggregation: %s", aggregation)
+		}
+	}
+	return &DownsampleFrameProcessor{
+		config:   config,
+		interval: time.Duration(config.IntervalMilliseconds) * time.Millisecond,
+		windows:  map[string]*downsampleWindow{},
+	}, nil
+}
+
+const FrameProcessorTypeDownsample = "downsample"
+
+func (p *DownsampleFrameProcessor) Type() string {
+	return FrameProcessorTypeDownsample
+}
+
+func (p *DownsampleFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(timeIndices) == 0 {
+		return nil, errors.New("downsample requires a time field")
+	}
+	timeIndex := timeIndices[0]
+
+	key := fmt.Sprintf("%d/%s", vars.OrgID, vars.Channel)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	window := p.windows[key]
+	if window != nil && !sameFrameSchema(window.frame, frame) {
+		window = nil
+	}
+
+	var out *data.Frame
+	for i := 0; i < frame.Rows(); i++ {
+		t, ok := frame.Fields[timeIndex].ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		start := t.(time.Time).Truncate(p.interval)
+		if window != nil && start.After(window.start) {
+			if out == nil {
+				out = p.newOutputFrame(frame, timeIndex)
+			}
+			out.AppendRow(p.aggregate(window, timeIndex)...)
+			window = nil
+		}
+		if window == nil {
+			window = &downsampleWindow{start: start, frame: frame.EmptyCopy()}
+		}
+		window.frame.AppendRow(frame.RowCopy(i)...)
+	}
+	if window != nil {
+		p.windows[key] = window
+	}
+	return out, nil
+}
+
+func (p *DownsampleFrameProcessor) newOutputFrame(frame *data.Frame, timeIndex int) *data.Frame {
+	out := data.NewFrame(frame.Name)
+	out.Meta = frame.Meta
+	for i, field := range frame.Fields {
+		var f *data.Field
+		switch {
+		case i == timeIndex:
+			f = data.NewField(field.Name, field.Labels, []time.Time{})
+		case field.Type().Numeric():
+			f = data.NewField(field.Name, field.Labels, []*float64{})
+		default:
+			f = data.NewFieldFromFieldType(field.Type(), 0)
+			f.Name = field.Name
+			f.Labels = field.Labels
+		}
+		f.Config = field.Config
+		out.Fields = append(out.Fields, f)
+	}
+	return out
+}
+
+func (p *DownsampleFrameProcessor) aggregate(window *downsampleWindow, timeIndex int) []any {
+	row := make([]any, len(window.frame.Fields))
+	last := window.frame.Rows() - 1
+	for i, field := range window.frame.Fields {
+		switch {
+		case i == timeIndex:
+			row[i] = window.start
+		case field.Type().Numeric():
+			aggregation := p.config.Aggregation
+			if fieldAggregation, ok := p.config.FieldAggregations[field.Name]; ok {
+				aggregation = fieldAggregation
+			}
+			row[i] = aggregateField(field, aggregation)
+		default:
+			row[i] = field.CopyAt(last)
+		}
+	}
+	return row
+}
+
+// aggregateField aggregates the non null values of a numeric field, it
+// returns nil when there are no values.
+func aggregateField(field *data.Field, aggregation Aggregation) *float64 {
+	var result, sum float64
+	count := 0
+	for i := 0; i < field.Len(); i++ {
+		value, err := field.NullableFloatAt(i)
+		if err != nil || value == nil {
+			continue
+		}
+		v := *value
+		switch {
+		case count == 0:
+			result = v
+		case aggregation == AggregationMin && v < result:
+			result = v
+		case aggregation == AggregationMax && v > result:
+			result = v
+		case aggregation == AggregationLast:
+			result = v
+		}
+		sum += v
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+	if aggregation == AggregationAvg {
+		result = sum / float64(count)
+	}
+	return &result
+}
+
+func isKnownAggregation(aggregation Aggregation) bool {
+	switch aggregation {
+	case AggregationMin, AggregationMax, AggregationAvg, AggregationLast:
+		return true
+	}
+	return false
+}
+
+func sameFrameSchema(a, b *data.Frame) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	for i := range a.Fields {
+		if a.Fields[i].Name != b.Fields[i].Name ||
+			a.Fields[i].Type() != b.Fields[i].Type() ||
+			a.Fields[i].Labels.String() != b.Fields[i].Labels.String() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+package pipeline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownsampleFrameProcessor(t *testing.T) {
+	p, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{
+		IntervalMilliseconds: 10000,
+		Aggregation:          AggregationAvg,
+		FieldAggregations:    map[string]Aggregation{"max": AggregationMax, "min": AggregationMin},
+	})
+	require.NoError(t, err)
+
+	start := time.Unix(1600000000, 0)
+	vars := Vars{OrgID: 1, Channel: "stream/test/xxx"}
+	process := func(offset time.Duration, value float64, status string) *data.Frame {
+		t.Helper()
+		frame := data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{start.Add(offset)}),
+			data.NewField("avg", nil, []float64{value}),
+			data.NewField("max", nil, []float64{value}),
+			data.NewField("min", nil, []*float64{&value}),
+			data.NewField("status", nil, []string{status}),
+		)
+		result, err := p.ProcessFrame(context.Background(), vars, frame)
+		require.NoError(t, err)
+		return result
+	}
+
+	require.Nil(t, process(0, 1, "a"))
+	require.Nil(t, process(3*time.Second, 5, "b"))
+	require.Nil(t, process(9*time.Second, 3, "c"))
+
+	result := process(12*time.Second, 10, "d")
+	require.NotNil(t, result)
+	require.Equal(t, 1, result.Rows())
+	require.Equal(t, start, result.Fields[0].At(0))
+	require.Equal(t, 3.0, *result.Fields[1].At(0).(*float64))
+	require.Equal(t, 5.0, *result.Fields[2].At(0).(*float64))
+	require.Equal(t, 1.0, *result.Fields[3].At(0).(*float64))
+	require.Equal(t, "c", result.Fields[4].At(0))
+
+	// Other channels are aggregated separately.
+	other, err := p.ProcessFrame(context.Background(), Vars{OrgID: 1, Channel: "stream/test/yyy"},
+		data.NewFrame("test", data.NewField("time", nil, []time.Time{start.Add(30 * time.Second)})))
+	require.NoError(t, err)
+	require.Nil(t, other)
+
+	result = process(35*time.Second, 20, "e")
+	require.NotNil(t, result)
+	require.Equal(t, start.Add(10*time.Second), result.Fields[0].At(0))
+	require.Equal(t, 10.0, *result.Fields[1].At(0).(*float64))
+}
+
+func TestDownsampleFrameProcessor_InvalidConfig(t *testing.T) {
+	_, err := NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{})
+	require.Error(t, err)
+	_, err = NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{IntervalMilliseconds: 1000, Aggregation: "median"})
+	require.Error(t, err)
+	_, err = NewDownsampleFrameProcessor(DownsampleFrameProcessorConfig{
+		IntervalMilliseconds: 1000,
+		FieldAggregations:    map[string]Aggregation{"value": "sum"},
+	})
+	require.Error(t, err)
+}
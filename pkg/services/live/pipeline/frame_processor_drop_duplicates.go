@@ -0,0 +1,73 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DropDuplicatesFrameProcessor stops processing of frames which have the same
+// values as the previous frame in a channel. Only configured fields are
+// compared, all fields except time fields by default.
+type DropDuplicatesFrameProcessor struct {
+	config DropDuplicatesFrameProcessorConfig
+
+	mu   sync.Mutex
+	last map[string]string
+}
+
+func NewDropDuplicatesFrameProcessor(config DropDuplicatesFrameProcessorConfig) *DropDuplicatesFrameProcessor {
+	return &DropDuplicatesFrameProcessor{config: config, last: map[string]string{}}
+}
+
+const FrameProcessorTypeDropDuplicates = "dropDuplicates"
+
+func (p *DropDuplicatesFrameProcessor) Type() string {
+	return FrameProcessorTypeDropDuplicates
+}
+
+func (p *DropDuplicatesFrameProcessor) ProcessFrame(_ context.Context, vars Vars, frame *data.Frame) (*data.Frame, error) {
+	values, err := p.frameValues(frame)
+	if err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%d/%s", vars.OrgID, vars.Channel)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if last, ok := p.last[key]; ok && last == values {
+		return nil, nil
+	}
+	p.last[key] = values
+	return frame, nil
+}
+
+func (p *DropDuplicatesFrameProcessor) frameValues(frame *data.Frame) (string, error) {
+	values := map[string][]any{}
+	for _, field := range frame.Fields {
+		if !p.compareField(field) {
+			continue
+		}
+		fieldValues := make([]any, field.Len())
+		for i := 0; i < field.Len(); i++ {
+			fieldValues[i] = field.At(i)
+		}
+		values[field.Name] = fieldValues
+	}
+	b, err := json.Marshal(values)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (p *DropDuplicatesFrameProcessor) compareField(field *data.Field) bool {
+	if len(p.config.FieldNames) == 0 {
+		return !field.Type().Time()
+	}
+	return stringInSlice(field.Name, p.config.FieldNames)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/require"
@@ -142,3 +143,59 @@ func TestPipeline_Recursion(t *testing.T) {
 	_, err = p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
 	require.ErrorIs(t, err, errChannelRecursion)
 }
+
+func TestPipeline_FrameProcessors(t *testing.T) {
+	computeField, err := NewComputeFieldFrameProcessor(ComputeFieldFrameProcessorConfig{
+		FieldName:  "percent",
+		Expression: "used / total * 100",
+	})
+	require.NoError(t, err)
+
+	converter := &testConverter{}
+	outputter := &testOutputter{}
+	p, err := New(&testRuleGetter{
+		rules: map[string]*LiveChannelRule{
+			"stream/test/xxx": {
+				Converter: converter,
+				FrameProcessors: []FrameProcessor{
+					NewDropDuplicatesFrameProcessor(DropDuplicatesFrameProcessorConfig{}),
+					NewRenameFieldsFrameProcessor(RenameFieldsFrameProcessorConfig{
+						Renames: map[string]string{"mem_used": "used", "mem_total": "total"},
+					}),
+					computeField,
+				},
+				FrameOutputters: []FrameOutputter{outputter},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	process := func(ts time.Time, used float64) *data.Frame {
+		t.Helper()
+		outputter.frame = nil
+		converter.frame = data.NewFrame("test",
+			data.NewField("time", nil, []time.Time{ts}),
+			data.NewField("mem_used", nil, []float64{used}),
+			data.NewField("mem_total", nil, []float64{200}),
+		)
+		_, err := p.ProcessInput(context.Background(), 1, "stream/test/xxx", []byte(`{}`))
+		require.NoError(t, err)
+		return outputter.frame
+	}
+
+	now := time.Now()
+	frame := process(now, 50)
+	require.NotNil(t, frame)
+	require.Len(t, frame.Fields, 4)
+	require.Equal(t, "used", frame.Fields[1].Name)
+	require.Equal(t, "total", frame.Fields[2].Name)
+	require.Equal(t, "percent", frame.Fields[3].Name)
+	require.Equal(t, 25.0, *frame.Fields[3].At(0).(*float64))
+
+	// Same values at a later time are dropped.
+	require.Nil(t, process(now.Add(time.Second), 50))
+
+	frame = process(now.Add(2*time.Second), 100)
+	require.NotNil(t, frame)
+	require.Equal(t, 50.0, *frame.Fields[3].At(0).(*float64))
+}
@@ -79,6 +79,34 @@ var FrameProcessorsRegistry = []EntityInfo{
 		Description: "list the fields that should be removed",
 		Example:     DropFieldsFrameProcessorConfig{},
 	},
+	{
+		Type:        FrameProcessorTypeRenameFields,
+		Description: "rename fields",
+		Example: RenameFieldsFrameProcessorConfig{
+			Renames: map[string]string{"cpu_usage": "CPU"},
+		},
+	},
+	{
+		Type:        FrameProcessorTypeComputeField,
+		Description: "add a field computed from the values of other fields",
+		Example: ComputeFieldFrameProcessorConfig{
+			FieldName:  "used_percent",
+			Expression: "(used / total) * 100",
+		},
+	},
+	{
+		Type:        FrameProcessorTypeDownsample,
+		Description: "aggregate rows into fixed time windows, frames are only output when a window is complete",
+		Example: DownsampleFrameProcessorConfig{
+			IntervalMilliseconds: 10000,
+			Aggregation:          AggregationAvg,
+		},
+	},
+	{
+		Type:        FrameProcessorTypeDropDuplicates,
+		Description: "drop frames with the same values as the previous frame of the channel",
+		Example:     DropDuplicatesFrameProcessorConfig{},
+	},
 }
 
 var DataOutputsRegistry = []EntityInfo{
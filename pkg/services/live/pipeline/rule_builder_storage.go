@@ -52,6 +52,11 @@ func (f *StorageRuleBuilder) extractConverter(config *ConverterConfig) (Converte
 	if config == nil {
 		return nil, nil
 	}
+	return NewConverter(config)
+}
+
+// NewConverter creates a Converter from its configuration.
+func NewConverter(config *ConverterConfig) (Converter, error) {
 	missingConfiguration := fmt.Errorf("missing configuration for %s", config.Type)
 	switch config.Type {
 	case ConverterTypeJsonAuto:
@@ -104,6 +109,34 @@ func (f *StorageRuleBuilder) extractFrameProcessor(config *FrameProcessorConfig)
 			processors = append(processors, proc)
 		}
 		return NewMultipleFrameProcessor(processors...), nil
+	case FrameProcessorTypeRenameFields:
+		if config.RenameFieldsProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		return NewRenameFieldsFrameProcessor(*config.RenameFieldsProcessorConfig), nil
+	case FrameProcessorTypeComputeField:
+		if config.ComputeFieldProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		proc, err := NewComputeFieldFrameProcessor(*config.ComputeFieldProcessorConfig)
+		if err != nil {
+			return nil, err
+		}
+		return proc, nil
+	case FrameProcessorTypeDownsample:
+		if config.DownsampleProcessorConfig == nil {
+			return nil, missingConfiguration
+		}
+		proc, err := NewDownsampleFrameProcessor(*config.DownsampleProcessorConfig)
+		if err != nil {
+			return nil, err
+		}
+		return proc, nil
+	case FrameProcessorTypeDropDuplicates:
+		if config.DropDuplicatesProcessorConfig == nil {
+			config.DropDuplicatesProcessorConfig = &DropDuplicatesFrameProcessorConfig{}
+		}
+		return NewDropDuplicatesFrameProcessor(*config.DropDuplicatesProcessorConfig), nil
 	default:
 		return nil, fmt.Errorf("unknown processor type: %s", config.Type)
 	}
@@ -302,7 +302,7 @@ func (s *Manager) runStream(ctx context.Context, cancelFn func(), sr streamReque
 		}
 
 		err := sr.StreamRunner.RunStream(
-			ctx,
+			identity.WithRequester(ctx, sr.user),
 			&backend.RunStreamRequest{
 				PluginContext: pluginCtx,
 				Path:          sr.Path,
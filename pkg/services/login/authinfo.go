@@ -25,14 +25,15 @@ type Store interface {
 
 const (
 	// modules
-	PasswordAuthModule  = "password"
-	APIKeyAuthModule    = "apikey"
-	SAMLAuthModule      = "auth.saml"
-	LDAPAuthModule      = "ldap"
-	AuthProxyAuthModule = "authproxy"
-	JWTModule           = "jwt"
-	ExtendedJWTModule   = "extendedjwt"
-	RenderModule        = "render"
+	PasswordAuthModule     = "password"
+	APIKeyAuthModule       = "apikey"
+	SAMLAuthModule         = "auth.saml"
+	LDAPAuthModule         = "ldap"
+	AuthProxyAuthModule    = "authproxy"
+	JWTModule              = "jwt"
+	ExtendedJWTModule      = "extendedjwt"
+	WorkloadIdentityModule = "workloadidentity"
+	RenderModule           = "render"
 	// OAuth provider modules
 	AzureADAuthModule    = "oauth_azuread"
 	GoogleAuthModule     = "oauth_google"
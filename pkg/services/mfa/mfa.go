@@ -0,0 +1,215 @@
+package mfa
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+type FactorType string
+
+const (
+	FactorTypeTOTP     FactorType = "totp"
+	FactorTypeWebAuthn FactorType = "webauthn"
+	// FactorTypeRecoveryCode is only used as a method of a challenge, recovery codes are not stored as factors.
+	FactorTypeRecoveryCode FactorType = "recovery_code"
+)
+
+var (
+	ErrDisabled           = errutil.NotFound("mfa.disabled", errutil.WithPublicMessage("Multi-factor authentication is not enabled"))
+	ErrChallengeNotFound  = errutil.Unauthorized("mfa.challenge-not-found", errutil.WithPublicMessage("Login expired, sign in again"))
+	ErrInvalidCode        = errutil.Unauthorized("mfa.invalid-code", errutil.WithPublicMessage("Invalid verification code"))
+	ErrInvalidCredential  = errutil.Unauthorized("mfa.invalid-credential", errutil.WithPublicMessage("Invalid security key response"))
+	ErrEnrollmentNotFound = errutil.BadRequest("mfa.enrollment-not-found", errutil.WithPublicMessage("Enrollment expired, start again"))
+	ErrFactorNotFound     = errutil.NotFound("mfa.factor-not-found", errutil.WithPublicMessage("Factor not found"))
+	ErrNotEnrolling       = errutil.BadRequest("mfa.not-enrolling", errutil.WithPublicMessage("A second factor is already enrolled"))
+	ErrTooManyAttempts    = errutil.TooManyRequests("mfa.too-many-attempts", errutil.WithPublicMessage("Too many invalid codes, try again later"))
+
+	errRequired = errutil.Unauthorized("mfa.required").MustTemplate("Second factor required", errutil.WithPublic("Second factor required"))
+)
+
+// RequiredError is returned by login clients when the password was verified and a second factor is needed
+// to complete the login. The public payload holds the challenge the user has to answer.
+func RequiredError(challenge *Challenge) error {
+	public := map[string]any{
+		"challengeId": challenge.ID,
+		"methods":     challenge.Methods,
+		"enroll":      challenge.Enroll,
+	}
+	if challenge.WebAuthn != nil {
+		public["webauthn"] = challenge.WebAuthn
+	}
+	return errRequired.Build(errutil.TemplateData{Public: public})
+}
+
+type Service interface {
+	// IsEnabled returns true when second factors are enabled in the configuration.
+	IsEnabled() bool
+	// GetStatus returns the factors enrolled by a user and whether a second factor is required for them.
+	GetStatus(ctx context.Context, userID int64) (*Status, error)
+	// IsRequired returns true when a second factor is required server wide or by any of the orgs of the user.
+	IsRequired(ctx context.Context, userID int64) (bool, error)
+
+	// BeginTOTPEnrollment generates a new TOTP secret for the user, the secret is stored once a code is confirmed.
+	BeginTOTPEnrollment(ctx context.Context, userID int64, login string) (*TOTPEnrollment, error)
+	ConfirmTOTPEnrollment(ctx context.Context, cmd *ConfirmTOTPEnrollmentCommand) (*Factor, error)
+	// BeginWebAuthnRegistration returns the options passed to navigator.credentials.create.
+	BeginWebAuthnRegistration(ctx context.Context, userID int64, login string) (*WebAuthnCreationOptions, error)
+	FinishWebAuthnRegistration(ctx context.Context, cmd *FinishWebAuthnRegistrationCommand) (*Factor, error)
+	DeleteFactor(ctx context.Context, userID, factorID int64) error
+	// GenerateRecoveryCodes replaces the recovery codes of the user, the codes are only returned once.
+	GenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error)
+	// Reset removes all factors and recovery codes of a user.
+	Reset(ctx context.Context, userID int64) error
+
+	// CreateChallenge creates the second step of a login, it returns nil when no second factor is needed.
+	CreateChallenge(ctx context.Context, cmd *CreateChallengeCommand) (*Challenge, error)
+	GetChallenge(ctx context.Context, challengeID string) (*Challenge, error)
+	// VerifyChallenge verifies the answer to a challenge and removes it. A challenge of a user without
+	// factors is answered by completing an enrollment.
+	VerifyChallenge(ctx context.Context, cmd *VerifyChallengeCommand) (*Challenge, error)
+
+	GetOrgPolicy(ctx context.Context, orgID int64) (*OrgPolicy, error)
+	SetOrgPolicy(ctx context.Context, orgID int64, policy OrgPolicy) error
+}
+
+type Factor struct {
+	ID           int64      `json:"id" xorm:"pk autoincr 'id'"`
+	UserID       int64      `json:"-" xorm:"user_id"`
+	Type         FactorType `json:"type" xorm:"type"`
+	Name         string     `json:"name" xorm:"name"`
+	CredentialID string     `json:"-" xorm:"credential_id"`
+	// Secret holds the encrypted TOTP secret or the COSE encoded public key of a WebAuthn credential.
+	Secret string `json:"-" xorm:"secret"`
+	// Counter holds the last accepted TOTP time step or WebAuthn signature counter.
+	Counter  int64      `json:"-" xorm:"counter"`
+	Created  time.Time  `json:"created" xorm:"created"`
+	Updated  time.Time  `json:"updated" xorm:"updated"`
+	LastUsed *time.Time `json:"lastUsed,omitempty" xorm:"last_used"`
+}
+
+func (Factor) TableName() string {
+	return "user_mfa_factor"
+}
+
+type RecoveryCode struct {
+	ID       int64     `xorm:"pk autoincr 'id'"`
+	UserID   int64     `xorm:"user_id"`
+	CodeHash string    `xorm:"code_hash"`
+	Used     bool      `xorm:"used"`
+	Created  time.Time `xorm:"created"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "user_mfa_recovery_code"
+}
+
+type Status struct {
+	Enabled                bool      `json:"enabled"`
+	Required               bool      `json:"required"`
+	Factors                []*Factor `json:"factors"`
+	RecoveryCodesRemaining int       `json:"recoveryCodesRemaining"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	// URL is the otpauth:// key URI rendered as QR code for authenticator apps.
+	URL string `json:"url"`
+}
+
+type ConfirmTOTPEnrollmentCommand struct {
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	Code   string `json:"code"`
+}
+
+type FinishWebAuthnRegistrationCommand struct {
+	UserID int64  `json:"-"`
+	Name   string `json:"name"`
+	// Credential is the PublicKeyCredential returned by navigator.credentials.create, with binary fields
+	// encoded as base64url.
+	Credential json.RawMessage `json:"credential"`
+}
+
+type CreateChallengeCommand struct {
+	UserID int64
+	Login  string
+	// AuthModule is the module the password was verified with, the identity is authenticated by it
+	// once the challenge is answered.
+	AuthModule string
+}
+
+type Challenge struct {
+	ID         string       `json:"id"`
+	UserID     int64        `json:"userId"`
+	Login      string       `json:"login"`
+	AuthModule string       `json:"authModule"`
+	Methods    []FactorType `json:"methods"`
+	// Enroll is set when the user has no factor and has to enroll one to complete the login.
+	Enroll   bool                      `json:"enroll"`
+	WebAuthn *WebAuthnAssertionOptions `json:"webauthn,omitempty"`
+	Expires  time.Time                 `json:"expires"`
+}
+
+type VerifyChallengeCommand struct {
+	ChallengeID  string `json:"challengeId"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+	// WebAuthn is the PublicKeyCredential returned by navigator.credentials.get, or by
+	// navigator.credentials.create when enrolling a security key during login.
+	WebAuthn json.RawMessage `json:"webauthn"`
+}
+
+type OrgPolicy struct {
+	// Required makes every member of the org that signs in with a password enroll a second factor.
+	Required bool `json:"required"`
+}
+
+type WebAuthnCredentialDescriptor struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type WebAuthnRelyingParty struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebAuthnUser struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type WebAuthnCredentialParameter struct {
+	Type string `json:"type"`
+	Alg  int64  `json:"alg"`
+}
+
+type WebAuthnAuthenticatorSelection struct {
+	ResidentKey      string `json:"residentKey"`
+	UserVerification string `json:"userVerification"`
+}
+
+// WebAuthnCreationOptions are the PublicKeyCredentialCreationOptions with binary fields encoded as base64url.
+type WebAuthnCreationOptions struct {
+	Challenge              string                         `json:"challenge"`
+	RelyingParty           WebAuthnRelyingParty           `json:"rp"`
+	User                   WebAuthnUser                   `json:"user"`
+	PubKeyCredParams       []WebAuthnCredentialParameter  `json:"pubKeyCredParams"`
+	Timeout                int64                          `json:"timeout"`
+	Attestation            string                         `json:"attestation"`
+	ExcludeCredentials     []WebAuthnCredentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection WebAuthnAuthenticatorSelection `json:"authenticatorSelection"`
+}
+
+// WebAuthnAssertionOptions are the PublicKeyCredentialRequestOptions with binary fields encoded as base64url.
+type WebAuthnAssertionOptions struct {
+	Challenge        string                         `json:"challenge"`
+	RelyingPartyID   string                         `json:"rpId"`
+	Timeout          int64                          `json:"timeout"`
+	AllowCredentials []WebAuthnCredentialDescriptor `json:"allowCredentials"`
+	UserVerification string                         `json:"userVerification"`
+}
@@ -0,0 +1,272 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var errNotUser = errutil.Forbidden("mfa.not-user", errutil.WithPublicMessage("Second factors are only available for users"))
+
+type API struct {
+	cfg           *setting.Cfg
+	service       mfa.Service
+	accessControl accesscontrol.AccessControl
+	authnService  authn.Service
+	routeRegister routing.RouteRegister
+	log           log.Logger
+}
+
+func ProvideAPI(
+	cfg *setting.Cfg,
+	service mfa.Service,
+	accessControl accesscontrol.AccessControl,
+	authnService authn.Service,
+	routeRegister routing.RouteRegister,
+) *API {
+	return &API{
+		cfg:           cfg,
+		service:       service,
+		accessControl: accessControl,
+		authnService:  authnService,
+		routeRegister: routeRegister,
+		log:           log.New("mfa.api"),
+	}
+}
+
+func (api *API) RegisterAPIEndpoints() {
+	authorize := accesscontrol.Middleware(api.accessControl)
+	authorizeInOrg := accesscontrol.AuthorizeInOrgMiddleware(api.accessControl, api.authnService)
+
+	api.routeRegister.Group("/api/user/mfa", func(userRoute routing.RouteRegister) {
+		userRoute.Get("/", routing.Wrap(api.getStatus))
+		userRoute.Post("/totp", routing.Wrap(api.beginTOTPEnrollment))
+		userRoute.Post("/totp/confirm", routing.Wrap(api.confirmTOTPEnrollment))
+		userRoute.Post("/webauthn", routing.Wrap(api.beginWebAuthnRegistration))
+		userRoute.Post("/webauthn/confirm", routing.Wrap(api.finishWebAuthnRegistration))
+		userRoute.Delete("/factors/:factorId", routing.Wrap(api.deleteFactor))
+		userRoute.Post("/recovery-codes", routing.Wrap(api.generateRecoveryCodes))
+	}, middleware.ReqSignedInNoAnonymous)
+
+	// used by users that have to enroll a second factor to complete a login, the challenge id is only known
+	// to users that verified their password
+	api.routeRegister.Group("/api/login/mfa", func(loginRoute routing.RouteRegister) {
+		loginRoute.Post("/totp", routing.Wrap(api.beginLoginTOTPEnrollment))
+		loginRoute.Post("/webauthn", routing.Wrap(api.beginLoginWebAuthnRegistration))
+	})
+
+	api.routeRegister.Delete("/api/admin/users/:id/mfa", middleware.ReqSignedIn,
+		authorizeInOrg(accesscontrol.UseGlobalOrg, accesscontrol.EvalPermission(accesscontrol.ActionUsersWrite, accesscontrol.Scope("global.users", "id", accesscontrol.Parameter(":id")))),
+		routing.Wrap(api.resetUser))
+
+	api.routeRegister.Group("/api/org/mfa", func(orgRoute routing.RouteRegister) {
+		orgRoute.Get("/", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgsRead)), routing.Wrap(api.getOrgPolicy))
+		orgRoute.Put("/", authorize(accesscontrol.EvalPermission(accesscontrol.ActionOrgsWrite)), routing.Wrap(api.setOrgPolicy))
+	}, middleware.ReqSignedIn)
+}
+
+func (api *API) getStatus(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	status, err := api.service.GetStatus(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get second factors", err)
+	}
+	return response.JSON(http.StatusOK, status)
+}
+
+func (api *API) beginTOTPEnrollment(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	enrollment, err := api.service.BeginTOTPEnrollment(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin enrollment", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+func (api *API) confirmTOTPEnrollment(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	cmd := mfa.ConfirmTOTPEnrollmentCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UserID = userID
+
+	factor, err := api.service.ConfirmTOTPEnrollment(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to confirm enrollment", err)
+	}
+	return response.JSON(http.StatusOK, factor)
+}
+
+func (api *API) beginWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	options, err := api.service.BeginWebAuthnRegistration(c.Req.Context(), userID, c.SignedInUser.GetLogin())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin registration", err)
+	}
+	return response.JSON(http.StatusOK, options)
+}
+
+func (api *API) finishWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	cmd := mfa.FinishWebAuthnRegistrationCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.UserID = userID
+
+	factor, err := api.service.FinishWebAuthnRegistration(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to register security key", err)
+	}
+	return response.JSON(http.StatusOK, factor)
+}
+
+func (api *API) deleteFactor(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	factorID, err := strconv.ParseInt(web.Params(c.Req)[":factorId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "factorId is invalid", err)
+	}
+
+	if err := api.service.DeleteFactor(c.Req.Context(), userID, factorID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete factor", err)
+	}
+	return response.Success("Factor deleted")
+}
+
+func (api *API) generateRecoveryCodes(c *contextmodel.ReqContext) response.Response {
+	userID, err := signedInUserID(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	codes, err := api.service.GenerateRecoveryCodes(c.Req.Context(), userID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to generate recovery codes", err)
+	}
+	return response.JSON(http.StatusOK, map[string]any{"codes": codes})
+}
+
+type loginEnrollmentForm struct {
+	ChallengeID string `json:"challengeId" binding:"Required"`
+}
+
+func (api *API) beginLoginTOTPEnrollment(c *contextmodel.ReqContext) response.Response {
+	challenge, err := api.getEnrollmentChallenge(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	enrollment, err := api.service.BeginTOTPEnrollment(c.Req.Context(), challenge.UserID, challenge.Login)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin enrollment", err)
+	}
+	return response.JSON(http.StatusOK, enrollment)
+}
+
+func (api *API) beginLoginWebAuthnRegistration(c *contextmodel.ReqContext) response.Response {
+	challenge, err := api.getEnrollmentChallenge(c)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	options, err := api.service.BeginWebAuthnRegistration(c.Req.Context(), challenge.UserID, challenge.Login)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to begin registration", err)
+	}
+	return response.JSON(http.StatusOK, options)
+}
+
+func (api *API) getEnrollmentChallenge(c *contextmodel.ReqContext) (*mfa.Challenge, error) {
+	form := loginEnrollmentForm{}
+	if err := web.Bind(c.Req, &form); err != nil {
+		return nil, mfa.ErrChallengeNotFound.Errorf("failed to parse request: %w", err)
+	}
+
+	challenge, err := api.service.GetChallenge(c.Req.Context(), form.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+	if !challenge.Enroll {
+		return nil, mfa.ErrNotEnrolling.Errorf("user %d already has a second factor", challenge.UserID)
+	}
+	return challenge, nil
+}
+
+func (api *API) resetUser(c *contextmodel.ReqContext) response.Response {
+	userID, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "id is invalid", err)
+	}
+
+	if err := api.service.Reset(c.Req.Context(), userID); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to reset second factors", err)
+	}
+	api.log.FromContext(c.Req.Context()).Info("Reset second factors of user", "userID", userID, "resetBy", c.SignedInUser.GetID())
+	return response.Success("Second factors reset")
+}
+
+func (api *API) getOrgPolicy(c *contextmodel.ReqContext) response.Response {
+	policy, err := api.service.GetOrgPolicy(c.Req.Context(), c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to get second factor policy", err)
+	}
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (api *API) setOrgPolicy(c *contextmodel.ReqContext) response.Response {
+	policy := mfa.OrgPolicy{}
+	if err := web.Bind(c.Req, &policy); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	if err := api.service.SetOrgPolicy(c.Req.Context(), c.SignedInUser.GetOrgID(), policy); err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to update second factor policy", err)
+	}
+	return response.Success("Second factor policy updated")
+}
+
+func signedInUserID(c *contextmodel.ReqContext) (int64, error) {
+	namespace, identifier := c.SignedInUser.GetNamespacedID()
+	if namespace != identity.NamespaceUser {
+		return 0, errNotUser.Errorf("expected identity of type user but got %s", namespace)
+	}
+	return identity.IntIdentifier(namespace, identifier)
+}
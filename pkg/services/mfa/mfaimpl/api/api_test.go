@@ -0,0 +1,133 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/authn/authntest"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfatest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func setupTestServer(t *testing.T, service *mfatest.FakeService) *webtest.Server {
+	t.Helper()
+
+	router := routing.NewRouteRegister()
+	api := ProvideAPI(
+		setting.NewCfg(),
+		service,
+		acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()),
+		&authntest.FakeService{},
+		router,
+	)
+	api.RegisterAPIEndpoints()
+	return webtest.NewServer(t, router)
+}
+
+func userWithPermissions(orgID int64, permissions []accesscontrol.Permission) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 1, OrgID: orgID, Login: "admin", OrgRole: org.RoleViewer, Permissions: map[int64]map[string][]string{
+		orgID: accesscontrol.GroupScopesByActionContext(context.Background(), permissions),
+	}}
+}
+
+func TestAPI_GetStatus(t *testing.T) {
+	t.Run("should return the status of the signed in user", func(t *testing.T) {
+		server := setupTestServer(t, &mfatest.FakeService{ExpectedStatus: &mfa.Status{Enabled: true}})
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/user/mfa"), userWithPermissions(1, nil)))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should reject service accounts", func(t *testing.T) {
+		server := setupTestServer(t, &mfatest.FakeService{})
+		sa := &user.SignedInUser{UserID: 2, OrgID: 1, IsServiceAccount: true}
+		res, err := server.Send(webtest.RequestWithSignedInUser(server.NewGetRequest("/api/user/mfa"), sa))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
+func TestAPI_BeginLoginEnrollment(t *testing.T) {
+	tests := []struct {
+		desc         string
+		challenge    *mfa.Challenge
+		expectedCode int
+	}{
+		{desc: "should begin enrollment for users that have to enroll", challenge: &mfa.Challenge{ID: "abc", UserID: 1, Enroll: true}, expectedCode: http.StatusOK},
+		{desc: "should reject users that already have a factor", challenge: &mfa.Challenge{ID: "abc", UserID: 1}, expectedCode: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			server := setupTestServer(t, &mfatest.FakeService{
+				ExpectedChallenge:      tt.challenge,
+				ExpectedTOTPEnrollment: &mfa.TOTPEnrollment{Secret: "secret"},
+			})
+			res, err := server.SendJSON(server.NewPostRequest("/api/login/mfa/totp", strings.NewReader(`{"challengeId": "abc"}`)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestAPI_ResetUser(t *testing.T) {
+	tests := []struct {
+		desc          string
+		permissions   []accesscontrol.Permission
+		expectedCode  int
+		expectedReset int64
+	}{
+		{
+			desc:          "should reset the factors of the user with permission",
+			permissions:   []accesscontrol.Permission{{Action: accesscontrol.ActionUsersWrite, Scope: "global.users:id:2"}},
+			expectedCode:  http.StatusOK,
+			expectedReset: 2,
+		},
+		{
+			desc:         "should reject users without permission",
+			permissions:  []accesscontrol.Permission{{Action: accesscontrol.ActionUsersWrite, Scope: "global.users:id:3"}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			service := &mfatest.FakeService{}
+			server := setupTestServer(t, service)
+			req := server.NewRequest(http.MethodDelete, "/api/admin/users/2/mfa", nil)
+			res, err := server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(accesscontrol.GlobalOrgID, tt.permissions)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+			assert.Equal(t, tt.expectedReset, service.ResetUserID)
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestAPI_SetOrgPolicy(t *testing.T) {
+	service := &mfatest.FakeService{}
+	server := setupTestServer(t, service)
+
+	req := server.NewRequest(http.MethodPut, "/api/org/mfa", strings.NewReader(`{"required": true}`))
+	req = webtest.RequestWithSignedInUser(req, userWithPermissions(1, []accesscontrol.Permission{{Action: accesscontrol.ActionOrgsWrite}}))
+	res, err := server.SendJSON(req)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	require.NoError(t, res.Body.Close())
+	assert.Equal(t, &mfa.OrgPolicy{Required: true}, service.ExpectedOrgPolicy)
+}
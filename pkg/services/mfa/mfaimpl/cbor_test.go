@@ -0,0 +1,74 @@
+package mfaimpl
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCBORDecode(t *testing.T) {
+	// examples from https://www.rfc-editor.org/rfc/rfc8949#appendix-A
+	tests := []struct {
+		hex      string
+		expected any
+	}{
+		{hex: "00", expected: int64(0)},
+		{hex: "17", expected: int64(23)},
+		{hex: "1818", expected: int64(24)},
+		{hex: "1903e8", expected: int64(1000)},
+		{hex: "1a000f4240", expected: int64(1000000)},
+		{hex: "1b000000e8d4a51000", expected: int64(1000000000000)},
+		{hex: "20", expected: int64(-1)},
+		{hex: "3863", expected: int64(-100)},
+		{hex: "390100", expected: int64(-257)},
+		{hex: "f4", expected: false},
+		{hex: "f5", expected: true},
+		{hex: "f6", expected: nil},
+		{hex: "4401020304", expected: []byte{1, 2, 3, 4}},
+		{hex: "6449455446", expected: "IETF"},
+		{hex: "83010203", expected: []any{int64(1), int64(2), int64(3)}},
+		{hex: "8301820203820405", expected: []any{int64(1), []any{int64(2), int64(3)}, []any{int64(4), int64(5)}}},
+		{hex: "a201020304", expected: map[any]any{int64(1): int64(2), int64(3): int64(4)}},
+		{hex: "a26161016162820203", expected: map[any]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hex, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			require.NoError(t, err)
+			item, rest, err := cborDecode(data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, item)
+			assert.Empty(t, rest)
+		})
+	}
+}
+
+func TestCBORDecode_Rest(t *testing.T) {
+	item, rest, err := cborDecode([]byte{0x01, 0x02, 0x03})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), item)
+	assert.Equal(t, []byte{0x02, 0x03}, rest)
+}
+
+func TestCBORDecode_Invalid(t *testing.T) {
+	for _, h := range []string{
+		"",
+		"19",           // truncated argument
+		"4401",         // truncated byte string
+		"83",           // truncated array
+		"9f01ff",       // indefinite length array
+		"a1f401",       // unsupported map key
+		"c11a514b67b0", // tag
+		"fb3ff199999999999a",
+		"9bffffffffffffffff",
+	} {
+		t.Run(h, func(t *testing.T) {
+			data, err := hex.DecodeString(h)
+			require.NoError(t, err)
+			_, _, err = cborDecode(data)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package mfaimpl
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+var errBadChallengeResponse = errutil.BadRequest("mfa.invalid-request", errutil.WithPublicMessage("bad second factor data"))
+
+var _ authn.Client = new(Client)
+
+func ProvideClient(service mfa.Service) *Client {
+	return &Client{service}
+}
+
+// Client completes a login that was started with a password by answering the challenge returned
+// by the form login.
+type Client struct {
+	service mfa.Service
+}
+
+func (c *Client) Name() string {
+	return authn.ClientMFA
+}
+
+func (c *Client) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	cmd := mfa.VerifyChallengeCommand{}
+	if err := web.Bind(r.HTTPRequest, &cmd); err != nil {
+		return nil, errBadChallengeResponse.Errorf("failed to parse request: %w", err)
+	}
+
+	challenge, err := c.service.VerifyChallenge(ctx, &cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return &authn.Identity{
+		ID:              authn.NewNamespaceID(authn.NamespaceUser, challenge.UserID),
+		OrgID:           r.OrgID,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+		AuthenticatedBy: challenge.AuthModule,
+	}, nil
+}
+
+func (c *Client) IsEnabled() bool {
+	return c.service.IsEnabled()
+}
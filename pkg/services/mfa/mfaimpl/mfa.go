@@ -0,0 +1,707 @@
+package mfaimpl
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/mfa/mfaimpl/api"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	challengeCachePrefix            = "mfa-challenge-"
+	totpEnrollmentCachePrefix       = "mfa-totp-enrollment-"
+	webAuthnRegistrationCachePrefix = "mfa-webauthn-registration-"
+	failedAttemptsCachePrefix       = "mfa-failed-attempts-"
+	// enrollmentTTL is how long a user has to confirm a TOTP secret or register a security key.
+	enrollmentTTL = 10 * time.Minute
+	// lockoutPeriod is how long a user can't answer challenges after max_attempts invalid answers.
+	// Failed attempts are counted per user, a new login doesn't reset them.
+	lockoutPeriod = 15 * time.Minute
+
+	kvNamespace    = "mfa"
+	kvKeyOrgPolicy = "org_policy"
+
+	recoveryCodeCount = 10
+
+	defaultTOTPName     = "Authenticator app"
+	defaultWebAuthnName = "Security key"
+)
+
+var _ mfa.Service = (*Service)(nil)
+
+type Service struct {
+	cfg      *setting.Cfg
+	log      log.Logger
+	store    store
+	cache    remotecache.CacheStorage
+	secrets  secrets.Service
+	kv       kvstore.KVStore
+	orgs     org.Service
+	webauthn *webAuthnVerifier
+	now      func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, cache remotecache.CacheStorage, secretsService secrets.Service,
+	kv kvstore.KVStore, orgService org.Service, authnService authn.Service,
+	accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister,
+) *Service {
+	s := newService(cfg, &xormStore{db: sqlStore}, cache, secretsService, kv, orgService)
+
+	if cfg.MFA.Enabled {
+		authnService.RegisterClient(ProvideClient(s))
+		api.ProvideAPI(cfg, s, accessControl, authnService, routeRegister).RegisterAPIEndpoints()
+	}
+
+	return s
+}
+
+func newService(cfg *setting.Cfg, store store, cache remotecache.CacheStorage, secretsService secrets.Service, kv kvstore.KVStore, orgService org.Service) *Service {
+	return &Service{
+		cfg:      cfg,
+		log:      log.New("mfa"),
+		store:    store,
+		cache:    cache,
+		secrets:  secretsService,
+		kv:       kv,
+		orgs:     orgService,
+		webauthn: &webAuthnVerifier{rpID: cfg.MFA.WebAuthnRPID, origins: cfg.MFA.WebAuthnOrigins},
+		now:      time.Now,
+	}
+}
+
+func (s *Service) IsEnabled() bool {
+	return s.cfg.MFA.Enabled
+}
+
+func (s *Service) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	factors, err := s.store.ListFactors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	required, err := s.IsRequired(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	codes, err := s.store.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mfa.Status{
+		Enabled:                len(factors) > 0,
+		Required:               required,
+		Factors:                factors,
+		RecoveryCodesRemaining: int(codes),
+	}, nil
+}
+
+func (s *Service) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	if s.cfg.MFA.Required {
+		return true, nil
+	}
+
+	orgs, err := s.orgs.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: userID})
+	if err != nil {
+		return false, err
+	}
+	for _, o := range orgs {
+		policy, err := s.GetOrgPolicy(ctx, o.OrgID)
+		if err != nil {
+			return false, err
+		}
+		if policy.Required {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *Service) BeginTOTPEnrollment(ctx context.Context, userID int64, login string) (*mfa.TOTPEnrollment, error) {
+	if !s.IsEnabled() {
+		return nil, mfa.ErrDisabled.Errorf("second factors are disabled")
+	}
+
+	secret, err := generateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.secrets.Encrypt(ctx, []byte(secret), secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(ctx, totpEnrollmentCacheKey(userID), encrypted, enrollmentTTL); err != nil {
+		return nil, err
+	}
+
+	return &mfa.TOTPEnrollment{
+		Secret: secret,
+		URL:    totpURL(s.cfg.MFA.TOTPIssuer, login, secret),
+	}, nil
+}
+
+func (s *Service) ConfirmTOTPEnrollment(ctx context.Context, cmd *mfa.ConfirmTOTPEnrollmentCommand) (*mfa.Factor, error) {
+	key := totpEnrollmentCacheKey(cmd.UserID)
+	encrypted, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, mfa.ErrEnrollmentNotFound.Errorf("no pending TOTP enrollment for user %d", cmd.UserID)
+		}
+		return nil, err
+	}
+	secret, err := s.secrets.Decrypt(ctx, encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := validateTOTP(string(secret), cmd.Code, s.now(), 0)
+	if !ok {
+		return nil, mfa.ErrInvalidCode.Errorf("invalid TOTP code")
+	}
+
+	factor, err := s.createFactor(ctx, cmd.UserID, mfa.FactorTypeTOTP, nameOrDefault(cmd.Name, defaultTOTPName), "", secret, step)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to delete TOTP enrollment", "userID", cmd.UserID, "error", err)
+	}
+	return factor, nil
+}
+
+func (s *Service) BeginWebAuthnRegistration(ctx context.Context, userID int64, login string) (*mfa.WebAuthnCreationOptions, error) {
+	if !s.IsEnabled() {
+		return nil, mfa.ErrDisabled.Errorf("second factors are disabled")
+	}
+
+	factors, err := s.store.ListFactors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	challenge, err := randomBase64URL(32)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Set(ctx, webAuthnRegistrationCacheKey(userID), []byte(challenge), enrollmentTTL); err != nil {
+		return nil, err
+	}
+
+	params := make([]mfa.WebAuthnCredentialParameter, 0, len(supportedCOSEAlgorithms))
+	for _, alg := range supportedCOSEAlgorithms {
+		params = append(params, mfa.WebAuthnCredentialParameter{Type: "public-key", Alg: alg})
+	}
+
+	return &mfa.WebAuthnCreationOptions{
+		Challenge:    challenge,
+		RelyingParty: mfa.WebAuthnRelyingParty{ID: s.cfg.MFA.WebAuthnRPID, Name: s.cfg.MFA.TOTPIssuer},
+		User: mfa.WebAuthnUser{
+			ID:          base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(userID, 10))),
+			Name:        login,
+			DisplayName: login,
+		},
+		PubKeyCredParams:   params,
+		Timeout:            enrollmentTTL.Milliseconds(),
+		Attestation:        "none",
+		ExcludeCredentials: credentialDescriptors(factors),
+		AuthenticatorSelection: mfa.WebAuthnAuthenticatorSelection{
+			ResidentKey:      "preferred",
+			UserVerification: "preferred",
+		},
+	}, nil
+}
+
+func (s *Service) FinishWebAuthnRegistration(ctx context.Context, cmd *mfa.FinishWebAuthnRegistrationCommand) (*mfa.Factor, error) {
+	key := webAuthnRegistrationCacheKey(cmd.UserID)
+	challenge, err := s.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, mfa.ErrEnrollmentNotFound.Errorf("no pending WebAuthn registration for user %d", cmd.UserID)
+		}
+		return nil, err
+	}
+
+	cred, err := parseWebAuthnCredential(cmd.Credential)
+	if err != nil {
+		return nil, mfa.ErrInvalidCredential.Errorf("failed to parse credential: %w", err)
+	}
+	registration, err := s.webauthn.verifyRegistration(cred, string(challenge))
+	if err != nil {
+		return nil, mfa.ErrInvalidCredential.Errorf("failed to verify registration: %w", err)
+	}
+
+	factors, err := s.store.ListFactors(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range factors {
+		if f.Type == mfa.FactorTypeWebAuthn && f.CredentialID == registration.credentialID {
+			return nil, mfa.ErrInvalidCredential.Errorf("credential is already registered")
+		}
+	}
+
+	factor, err := s.createFactor(ctx, cmd.UserID, mfa.FactorTypeWebAuthn, nameOrDefault(cmd.Name, defaultWebAuthnName), registration.credentialID, registration.publicKey, int64(registration.signCount))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, key); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to delete WebAuthn registration", "userID", cmd.UserID, "error", err)
+	}
+	return factor, nil
+}
+
+func (s *Service) DeleteFactor(ctx context.Context, userID, factorID int64) error {
+	deleted, err := s.store.DeleteFactor(ctx, userID, factorID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return mfa.ErrFactorNotFound.Errorf("factor %d of user %d not found", factorID, userID)
+	}
+	return nil
+}
+
+func (s *Service) GenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	factors, err := s.store.ListFactors(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(factors) == 0 {
+		return nil, mfa.ErrFactorNotFound.Errorf("user %d has no second factor", userID)
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		code := strings.ToLower(base32.StdEncoding.EncodeToString(b))
+		code = code[:8] + "-" + code[8:]
+		codes = append(codes, code)
+		hashes = append(hashes, hashRecoveryCode(code))
+	}
+
+	if err := s.store.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+func (s *Service) Reset(ctx context.Context, userID int64) error {
+	return s.store.DeleteFactors(ctx, userID)
+}
+
+func (s *Service) CreateChallenge(ctx context.Context, cmd *mfa.CreateChallengeCommand) (*mfa.Challenge, error) {
+	if !s.IsEnabled() {
+		return nil, nil
+	}
+
+	if err := s.checkFailedAttempts(ctx, cmd.UserID); err != nil {
+		return nil, err
+	}
+
+	factors, err := s.store.ListFactors(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := util.GetRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+	challenge := &mfa.Challenge{
+		ID:         id,
+		UserID:     cmd.UserID,
+		Login:      cmd.Login,
+		AuthModule: cmd.AuthModule,
+		Expires:    s.now().Add(s.cfg.MFA.ChallengeTTL),
+	}
+
+	if len(factors) == 0 {
+		required, err := s.IsRequired(ctx, cmd.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !required {
+			return nil, nil
+		}
+		challenge.Enroll = true
+		challenge.Methods = []mfa.FactorType{mfa.FactorTypeTOTP, mfa.FactorTypeWebAuthn}
+		return challenge, s.saveChallenge(ctx, challenge)
+	}
+
+	var hasTOTP bool
+	for _, f := range factors {
+		if f.Type == mfa.FactorTypeTOTP {
+			hasTOTP = true
+		}
+	}
+	if hasTOTP {
+		challenge.Methods = append(challenge.Methods, mfa.FactorTypeTOTP)
+	}
+	if credentials := credentialDescriptors(factors); len(credentials) > 0 {
+		webAuthnChallenge, err := randomBase64URL(32)
+		if err != nil {
+			return nil, err
+		}
+		challenge.Methods = append(challenge.Methods, mfa.FactorTypeWebAuthn)
+		challenge.WebAuthn = &mfa.WebAuthnAssertionOptions{
+			Challenge:        webAuthnChallenge,
+			RelyingPartyID:   s.cfg.MFA.WebAuthnRPID,
+			Timeout:          s.cfg.MFA.ChallengeTTL.Milliseconds(),
+			AllowCredentials: credentials,
+			UserVerification: "preferred",
+		}
+	}
+	codes, err := s.store.CountRecoveryCodes(ctx, cmd.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if codes > 0 {
+		challenge.Methods = append(challenge.Methods, mfa.FactorTypeRecoveryCode)
+	}
+
+	return challenge, s.saveChallenge(ctx, challenge)
+}
+
+func (s *Service) GetChallenge(ctx context.Context, challengeID string) (*mfa.Challenge, error) {
+	data, err := s.cache.Get(ctx, challengeCacheKey(challengeID))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return nil, mfa.ErrChallengeNotFound.Errorf("challenge not found")
+		}
+		return nil, err
+	}
+
+	var challenge mfa.Challenge
+	if err := json.Unmarshal(data, &challenge); err != nil {
+		return nil, err
+	}
+	if !s.now().Before(challenge.Expires) {
+		return nil, mfa.ErrChallengeNotFound.Errorf("challenge expired")
+	}
+	return &challenge, nil
+}
+
+func (s *Service) VerifyChallenge(ctx context.Context, cmd *mfa.VerifyChallengeCommand) (*mfa.Challenge, error) {
+	challenge, err := s.GetChallenge(ctx, cmd.ChallengeID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkFailedAttempts(ctx, challenge.UserID); err != nil {
+		return nil, err
+	}
+
+	if challenge.Enroll {
+		err = s.completeEnrollment(ctx, challenge, cmd)
+	} else {
+		err = s.verifyFactor(ctx, challenge, cmd)
+	}
+
+	if err != nil {
+		if errors.Is(err, mfa.ErrInvalidCode) || errors.Is(err, mfa.ErrInvalidCredential) {
+			s.recordFailedAttempt(ctx, challenge)
+		}
+		return nil, err
+	}
+
+	if err := s.cache.Delete(ctx, challengeCacheKey(challenge.ID)); err != nil {
+		return nil, err
+	}
+	if err := s.cache.Delete(ctx, failedAttemptsCacheKey(challenge.UserID)); err != nil {
+		s.log.FromContext(ctx).Warn("Failed to reset failed attempts", "userID", challenge.UserID, "error", err)
+	}
+	return challenge, nil
+}
+
+func (s *Service) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	value, ok, err := s.kv.Get(ctx, orgID, kvNamespace, kvKeyOrgPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &mfa.OrgPolicy{}
+	if !ok {
+		return policy, nil
+	}
+	if err := json.Unmarshal([]byte(value), policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *Service) SetOrgPolicy(ctx context.Context, orgID int64, policy mfa.OrgPolicy) error {
+	value, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+	return s.kv.Set(ctx, orgID, kvNamespace, kvKeyOrgPolicy, string(value))
+}
+
+// completeEnrollment answers a challenge of a user that has to enroll a factor during login.
+func (s *Service) completeEnrollment(ctx context.Context, challenge *mfa.Challenge, cmd *mfa.VerifyChallengeCommand) error {
+	switch {
+	case cmd.Code != "":
+		_, err := s.ConfirmTOTPEnrollment(ctx, &mfa.ConfirmTOTPEnrollmentCommand{UserID: challenge.UserID, Code: cmd.Code})
+		return err
+	case len(cmd.WebAuthn) > 0:
+		_, err := s.FinishWebAuthnRegistration(ctx, &mfa.FinishWebAuthnRegistrationCommand{UserID: challenge.UserID, Credential: cmd.WebAuthn})
+		return err
+	default:
+		return mfa.ErrInvalidCode.Errorf("no code or credential provided")
+	}
+}
+
+func (s *Service) verifyFactor(ctx context.Context, challenge *mfa.Challenge, cmd *mfa.VerifyChallengeCommand) error {
+	switch {
+	case cmd.Code != "":
+		return s.verifyTOTP(ctx, challenge.UserID, cmd.Code)
+	case cmd.RecoveryCode != "":
+		used, err := s.store.UseRecoveryCode(ctx, challenge.UserID, hashRecoveryCode(cmd.RecoveryCode))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return mfa.ErrInvalidCode.Errorf("invalid recovery code")
+		}
+		return nil
+	case len(cmd.WebAuthn) > 0:
+		if challenge.WebAuthn == nil {
+			return mfa.ErrInvalidCredential.Errorf("user has no security key")
+		}
+		return s.verifyWebAuthn(ctx, challenge.UserID, challenge.WebAuthn.Challenge, cmd.WebAuthn)
+	default:
+		return mfa.ErrInvalidCode.Errorf("no code or credential provided")
+	}
+}
+
+func (s *Service) verifyTOTP(ctx context.Context, userID int64, code string) error {
+	factors, err := s.store.ListFactors(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	for _, f := range factors {
+		if f.Type != mfa.FactorTypeTOTP {
+			continue
+		}
+		secret, err := s.decryptSecret(ctx, f)
+		if err != nil {
+			return err
+		}
+		step, ok := validateTOTP(string(secret), code, now, f.Counter)
+		if !ok {
+			continue
+		}
+		// the counter only moves forward, which rejects a code that was used concurrently
+		updated, err := s.store.UpdateFactorUsage(ctx, f.ID, f.Counter, step, now)
+		if err != nil {
+			return err
+		}
+		if updated {
+			return nil
+		}
+	}
+	return mfa.ErrInvalidCode.Errorf("invalid TOTP code")
+}
+
+func (s *Service) verifyWebAuthn(ctx context.Context, userID int64, challenge string, raw json.RawMessage) error {
+	cred, err := parseWebAuthnCredential(raw)
+	if err != nil {
+		return mfa.ErrInvalidCredential.Errorf("failed to parse credential: %w", err)
+	}
+	credentialID := cred.RawID
+	if credentialID == "" {
+		credentialID = cred.ID
+	}
+	credentialID = strings.TrimRight(credentialID, "=")
+
+	factors, err := s.store.ListFactors(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, f := range factors {
+		if f.Type != mfa.FactorTypeWebAuthn || f.CredentialID != credentialID {
+			continue
+		}
+
+		publicKey, err := s.decryptSecret(ctx, f)
+		if err != nil {
+			return err
+		}
+		signCount, err := s.webauthn.verifyAssertion(cred, challenge, publicKey)
+		if err != nil {
+			return mfa.ErrInvalidCredential.Errorf("failed to verify assertion: %w", err)
+		}
+		// authenticators without a signature counter always report 0, a counter that doesn't increase
+		// otherwise indicates a cloned authenticator
+		if (signCount != 0 || f.Counter != 0) && int64(signCount) <= f.Counter {
+			return mfa.ErrInvalidCredential.Errorf("signature counter did not increase")
+		}
+		updated, err := s.store.UpdateFactorUsage(ctx, f.ID, f.Counter, int64(signCount), s.now())
+		if err != nil {
+			return err
+		}
+		if !updated {
+			return mfa.ErrInvalidCredential.Errorf("credential was used concurrently")
+		}
+		return nil
+	}
+	return mfa.ErrInvalidCredential.Errorf("unknown credential")
+}
+
+func (s *Service) checkFailedAttempts(ctx context.Context, userID int64) error {
+	attempts, err := s.failedAttempts(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if attempts >= s.cfg.MFA.MaxAttempts {
+		return mfa.ErrTooManyAttempts.Errorf("user %d has %d failed attempts", userID, attempts)
+	}
+	return nil
+}
+
+func (s *Service) failedAttempts(ctx context.Context, userID int64) (int, error) {
+	data, err := s.cache.Get(ctx, failedAttemptsCacheKey(userID))
+	if err != nil {
+		if errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return strconv.Atoi(string(data))
+}
+
+// recordFailedAttempt counts an invalid answer, the challenge is removed once the user reached max_attempts.
+func (s *Service) recordFailedAttempt(ctx context.Context, challenge *mfa.Challenge) {
+	logger := s.log.FromContext(ctx)
+
+	attempts, err := s.failedAttempts(ctx, challenge.UserID)
+	if err != nil {
+		logger.Warn("Failed to get failed attempts", "userID", challenge.UserID, "error", err)
+	}
+	attempts++
+	if err := s.cache.Set(ctx, failedAttemptsCacheKey(challenge.UserID), []byte(strconv.Itoa(attempts)), lockoutPeriod); err != nil {
+		logger.Warn("Failed to record failed attempt", "userID", challenge.UserID, "error", err)
+	}
+
+	if attempts >= s.cfg.MFA.MaxAttempts {
+		logger.Warn("Too many invalid second factor answers", "userID", challenge.UserID, "attempts", attempts)
+		if err := s.cache.Delete(ctx, challengeCacheKey(challenge.ID)); err != nil {
+			logger.Warn("Failed to delete challenge", "userID", challenge.UserID, "error", err)
+		}
+	}
+}
+
+func (s *Service) saveChallenge(ctx context.Context, challenge *mfa.Challenge) error {
+	data, err := json.Marshal(challenge)
+	if err != nil {
+		return err
+	}
+	ttl := challenge.Expires.Sub(s.now())
+	if ttl <= 0 {
+		return mfa.ErrChallengeNotFound.Errorf("challenge expired")
+	}
+	return s.cache.Set(ctx, challengeCacheKey(challenge.ID), data, ttl)
+}
+
+func (s *Service) createFactor(ctx context.Context, userID int64, factorType mfa.FactorType, name, credentialID string, secret []byte, counter int64) (*mfa.Factor, error) {
+	encrypted, err := s.secrets.Encrypt(ctx, secret, secrets.WithoutScope())
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	factor := &mfa.Factor{
+		UserID:       userID,
+		Type:         factorType,
+		Name:         name,
+		CredentialID: credentialID,
+		Secret:       base64.StdEncoding.EncodeToString(encrypted),
+		Counter:      counter,
+		Created:      now,
+		Updated:      now,
+	}
+	if err := s.store.CreateFactor(ctx, factor); err != nil {
+		return nil, err
+	}
+	return factor, nil
+}
+
+func (s *Service) decryptSecret(ctx context.Context, factor *mfa.Factor) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(factor.Secret)
+	if err != nil {
+		return nil, err
+	}
+	return s.secrets.Decrypt(ctx, encrypted)
+}
+
+func credentialDescriptors(factors []*mfa.Factor) []mfa.WebAuthnCredentialDescriptor {
+	credentials := make([]mfa.WebAuthnCredentialDescriptor, 0)
+	for _, f := range factors {
+		if f.Type == mfa.FactorTypeWebAuthn {
+			credentials = append(credentials, mfa.WebAuthnCredentialDescriptor{Type: "public-key", ID: f.CredentialID})
+		}
+	}
+	return credentials
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomBase64URL(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func nameOrDefault(name, fallback string) string {
+	if name = strings.TrimSpace(name); name != "" {
+		return name
+	}
+	return fallback
+}
+
+func challengeCacheKey(id string) string {
+	return challengeCachePrefix + id
+}
+
+func totpEnrollmentCacheKey(userID int64) string {
+	return totpEnrollmentCachePrefix + strconv.FormatInt(userID, 10)
+}
+
+func webAuthnRegistrationCacheKey(userID int64) string {
+	return webAuthnRegistrationCachePrefix + strconv.FormatInt(userID, 10)
+}
+
+func failedAttemptsCacheKey(userID int64) string {
+	return failedAttemptsCachePrefix + strconv.FormatInt(userID, 10)
+}
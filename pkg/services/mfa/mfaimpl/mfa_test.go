@@ -0,0 +1,263 @@
+package mfaimpl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/kvstore"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/mfa"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/secrets/fakes"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func setupTestService(t *testing.T, opts ...func(cfg *setting.Cfg)) (*Service, *testClock) {
+	t.Helper()
+
+	cfg := setting.NewCfg()
+	cfg.MFA = setting.MFASettings{
+		Enabled:         true,
+		TOTPIssuer:      "Grafana",
+		ChallengeTTL:    5 * time.Minute,
+		MaxAttempts:     5,
+		WebAuthnRPID:    testRPID,
+		WebAuthnOrigins: []string{testOrigin},
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	orgService := &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1}}}
+	s := newService(cfg, &xormStore{db: db.InitTestDB(t)}, remotecache.NewFakeCacheStorage(), fakes.NewFakeSecretsService(), kvstore.NewFakeKVStore(), orgService)
+	clock := &testClock{now: time.Unix(1111111111, 0)}
+	s.now = clock.Now
+	return s, clock
+}
+
+func currentTOTPCode(t *testing.T, secret string, now time.Time) string {
+	code, err := totpCode(secret, totpStep(now))
+	require.NoError(t, err)
+	return code
+}
+
+func TestIntegrationService_TOTP(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, clock := setupTestService(t)
+
+	challenge, err := s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1, Login: "admin", AuthModule: "password"})
+	require.NoError(t, err)
+	assert.Nil(t, challenge, "users without factors don't need a second factor")
+
+	enrollment, err := s.BeginTOTPEnrollment(ctx, 1, "admin")
+	require.NoError(t, err)
+	_, err = s.ConfirmTOTPEnrollment(ctx, &mfa.ConfirmTOTPEnrollmentCommand{UserID: 1, Code: "000000"})
+	require.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+	code := currentTOTPCode(t, enrollment.Secret, clock.now)
+	factor, err := s.ConfirmTOTPEnrollment(ctx, &mfa.ConfirmTOTPEnrollmentCommand{UserID: 1, Code: code})
+	require.NoError(t, err)
+	assert.Equal(t, mfa.FactorTypeTOTP, factor.Type)
+	assert.Equal(t, defaultTOTPName, factor.Name)
+
+	challenge, err = s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1, Login: "admin", AuthModule: "password"})
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.False(t, challenge.Enroll)
+	assert.Equal(t, []mfa.FactorType{mfa.FactorTypeTOTP}, challenge.Methods)
+
+	t.Run("should reject the code used for the enrollment", func(t *testing.T) {
+		_, err := s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, Code: code})
+		require.ErrorIs(t, err, mfa.ErrInvalidCode)
+	})
+
+	t.Run("should verify the code of the next time step once", func(t *testing.T) {
+		clock.now = clock.now.Add(totpPeriod * time.Second)
+		verified, err := s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, Code: currentTOTPCode(t, enrollment.Secret, clock.now)})
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), verified.UserID)
+		assert.Equal(t, "password", verified.AuthModule)
+
+		_, err = s.GetChallenge(ctx, challenge.ID)
+		require.ErrorIs(t, err, mfa.ErrChallengeNotFound)
+	})
+
+	t.Run("should reset the factors of the user", func(t *testing.T) {
+		require.NoError(t, s.Reset(ctx, 1))
+		status, err := s.GetStatus(ctx, 1)
+		require.NoError(t, err)
+		assert.False(t, status.Enabled)
+		assert.Empty(t, status.Factors)
+	})
+}
+
+func TestIntegrationService_RecoveryCodes(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, clock := setupTestService(t)
+
+	_, err := s.GenerateRecoveryCodes(ctx, 1)
+	require.ErrorIs(t, err, mfa.ErrFactorNotFound)
+
+	enrollment, err := s.BeginTOTPEnrollment(ctx, 1, "admin")
+	require.NoError(t, err)
+	_, err = s.ConfirmTOTPEnrollment(ctx, &mfa.ConfirmTOTPEnrollmentCommand{UserID: 1, Code: currentTOTPCode(t, enrollment.Secret, clock.now)})
+	require.NoError(t, err)
+
+	codes, err := s.GenerateRecoveryCodes(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, codes, recoveryCodeCount)
+
+	challenge, err := s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []mfa.FactorType{mfa.FactorTypeTOTP, mfa.FactorTypeRecoveryCode}, challenge.Methods)
+
+	_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, RecoveryCode: codes[0]})
+	require.NoError(t, err)
+
+	challenge, err = s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1})
+	require.NoError(t, err)
+	_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, RecoveryCode: codes[0]})
+	require.ErrorIs(t, err, mfa.ErrInvalidCode, "recovery codes can only be used once")
+
+	status, err := s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, recoveryCodeCount-1, status.RecoveryCodesRemaining)
+}
+
+func TestIntegrationService_FailedAttempts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, clock := setupTestService(t, func(cfg *setting.Cfg) {
+		cfg.MFA.MaxAttempts = 2
+	})
+
+	enrollment, err := s.BeginTOTPEnrollment(ctx, 1, "admin")
+	require.NoError(t, err)
+	_, err = s.ConfirmTOTPEnrollment(ctx, &mfa.ConfirmTOTPEnrollmentCommand{UserID: 1, Code: currentTOTPCode(t, enrollment.Secret, clock.now)})
+	require.NoError(t, err)
+
+	challenge, err := s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1})
+	require.NoError(t, err)
+	_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, Code: "000000"})
+	require.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+	// a new login doesn't reset the failed attempts
+	challenge, err = s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1})
+	require.NoError(t, err)
+	_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, Code: "000000"})
+	require.ErrorIs(t, err, mfa.ErrInvalidCode)
+
+	_, err = s.GetChallenge(ctx, challenge.ID)
+	require.ErrorIs(t, err, mfa.ErrChallengeNotFound)
+	_, err = s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1})
+	require.ErrorIs(t, err, mfa.ErrTooManyAttempts)
+}
+
+func TestIntegrationService_RequiredEnrollment(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, clock := setupTestService(t)
+
+	required, err := s.IsRequired(ctx, 1)
+	require.NoError(t, err)
+	assert.False(t, required)
+
+	require.NoError(t, s.SetOrgPolicy(ctx, 1, mfa.OrgPolicy{Required: true}))
+	required, err = s.IsRequired(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, required)
+
+	challenge, err := s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1, Login: "admin"})
+	require.NoError(t, err)
+	require.NotNil(t, challenge)
+	assert.True(t, challenge.Enroll)
+
+	enrollment, err := s.BeginTOTPEnrollment(ctx, challenge.UserID, challenge.Login)
+	require.NoError(t, err)
+	_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, Code: currentTOTPCode(t, enrollment.Secret, clock.now)})
+	require.NoError(t, err)
+
+	status, err := s.GetStatus(ctx, 1)
+	require.NoError(t, err)
+	assert.True(t, status.Enabled)
+	assert.True(t, status.Required)
+	require.Len(t, status.Factors, 1)
+}
+
+func TestIntegrationService_WebAuthn(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	ctx := context.Background()
+	s, _ := setupTestService(t)
+	authenticator := newES256Authenticator(t)
+
+	options, err := s.BeginWebAuthnRegistration(ctx, 1, "admin")
+	require.NoError(t, err)
+	assert.Equal(t, testRPID, options.RelyingParty.ID)
+	assert.Empty(t, options.ExcludeCredentials)
+
+	credential, err := json.Marshal(authenticator.create(t, options.Challenge, testOrigin, testRPID))
+	require.NoError(t, err)
+	factor, err := s.FinishWebAuthnRegistration(ctx, &mfa.FinishWebAuthnRegistrationCommand{UserID: 1, Name: "YubiKey", Credential: credential})
+	require.NoError(t, err)
+	assert.Equal(t, mfa.FactorTypeWebAuthn, factor.Type)
+	assert.Equal(t, "YubiKey", factor.Name)
+
+	challenge, err := s.CreateChallenge(ctx, &mfa.CreateChallengeCommand{UserID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, []mfa.FactorType{mfa.FactorTypeWebAuthn}, challenge.Methods)
+	require.NotNil(t, challenge.WebAuthn)
+	require.Len(t, challenge.WebAuthn.AllowCredentials, 1)
+
+	t.Run("should reject assertions from another origin", func(t *testing.T) {
+		assertion, err := json.Marshal(authenticator.get(t, challenge.WebAuthn.Challenge, "https://evil.example.org", testRPID))
+		require.NoError(t, err)
+		_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, WebAuthn: assertion})
+		require.ErrorIs(t, err, mfa.ErrInvalidCredential)
+	})
+
+	t.Run("should verify assertions of the security key", func(t *testing.T) {
+		assertion, err := json.Marshal(authenticator.get(t, challenge.WebAuthn.Challenge, testOrigin, testRPID))
+		require.NoError(t, err)
+		_, err = s.VerifyChallenge(ctx, &mfa.VerifyChallengeCommand{ChallengeID: challenge.ID, WebAuthn: assertion})
+		require.NoError(t, err)
+	})
+
+	t.Run("should exclude registered credentials", func(t *testing.T) {
+		options, err := s.BeginWebAuthnRegistration(ctx, 1, "admin")
+		require.NoError(t, err)
+		require.Len(t, options.ExcludeCredentials, 1)
+		assert.Equal(t, factor.CredentialID, options.ExcludeCredentials[0].ID)
+	})
+}
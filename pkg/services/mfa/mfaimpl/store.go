@@ -0,0 +1,133 @@
+package mfaimpl
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+type store interface {
+	ListFactors(ctx context.Context, userID int64) ([]*mfa.Factor, error)
+	CreateFactor(ctx context.Context, factor *mfa.Factor) error
+	// UpdateFactorUsage stores the counter of a factor once it was used, it returns false when the factor
+	// was used concurrently and its counter doesn't match previousCounter anymore.
+	UpdateFactorUsage(ctx context.Context, factorID, previousCounter, counter int64, usedAt time.Time) (bool, error)
+	DeleteFactor(ctx context.Context, userID, factorID int64) (bool, error)
+	DeleteFactors(ctx context.Context, userID int64) error
+
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error
+	// UseRecoveryCode marks an unused recovery code as used, it returns false when there is no such code.
+	UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error)
+	CountRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) ListFactors(ctx context.Context, userID int64) ([]*mfa.Factor, error) {
+	factors := make([]*mfa.Factor, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("user_id = ?", userID).Asc("id").Find(&factors)
+	})
+	return factors, err
+}
+
+func (s *xormStore) CreateFactor(ctx context.Context, factor *mfa.Factor) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(factor)
+		return err
+	})
+}
+
+func (s *xormStore) UpdateFactorUsage(ctx context.Context, factorID, previousCounter, counter int64, usedAt time.Time) (bool, error) {
+	var updated bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa_factor SET counter = ?, last_used = ? WHERE id = ? AND counter = ?", counter, usedAt, factorID, previousCounter)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		updated = rows == 1
+		return err
+	})
+	return updated, err
+}
+
+func (s *xormStore) DeleteFactor(ctx context.Context, userID, factorID int64) (bool, error) {
+	var deleted bool
+	err := s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM user_mfa_factor WHERE id = ? AND user_id = ?", factorID, userID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = rows == 1
+
+		// recovery codes are only useful together with a factor
+		remaining, err := sess.Where("user_id = ?", userID).Count(&mfa.Factor{})
+		if err != nil {
+			return err
+		}
+		if remaining == 0 {
+			_, err = sess.Exec("DELETE FROM user_mfa_recovery_code WHERE user_id = ?", userID)
+		}
+		return err
+	})
+	return deleted, err
+}
+
+func (s *xormStore) DeleteFactors(ctx context.Context, userID int64) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM user_mfa_factor WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+		_, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE user_id = ?", userID)
+		return err
+	})
+}
+
+func (s *xormStore) ReplaceRecoveryCodes(ctx context.Context, userID int64, hashes []string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if _, err := sess.Exec("DELETE FROM user_mfa_recovery_code WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		codes := make([]*mfa.RecoveryCode, 0, len(hashes))
+		for _, hash := range hashes {
+			codes = append(codes, &mfa.RecoveryCode{UserID: userID, CodeHash: hash, Created: now})
+		}
+		_, err := sess.InsertMulti(codes)
+		return err
+	})
+}
+
+func (s *xormStore) UseRecoveryCode(ctx context.Context, userID int64, hash string) (bool, error) {
+	var used bool
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("UPDATE user_mfa_recovery_code SET used = ? WHERE user_id = ? AND code_hash = ? AND used = ?", true, userID, hash, false)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		used = rows == 1
+		return err
+	})
+	return used, err
+}
+
+func (s *xormStore) CountRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		count, err = sess.Where("user_id = ? AND used = ?", userID, false).Count(&mfa.RecoveryCode{})
+		return err
+	})
+	return count, err
+}
@@ -0,0 +1,86 @@
+package mfaimpl
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secret of the RFC 6238 test vectors, "12345678901234567890" encoded as base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// https://www.rfc-editor.org/rfc/rfc6238#appendix-B, truncated to 6 digits
+	tests := []struct {
+		time     int64
+		expected string
+	}{
+		{time: 59, expected: "287082"},
+		{time: 1111111109, expected: "081804"},
+		{time: 1111111111, expected: "050471"},
+		{time: 1234567890, expected: "005924"},
+		{time: 2000000000, expected: "279037"},
+		{time: 20000000000, expected: "353130"},
+	}
+	for _, tt := range tests {
+		code, err := totpCode(rfc6238Secret, totpStep(time.Unix(tt.time, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.expected, code, "time %d", tt.time)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := totpStep(now)
+
+	t.Run("should accept the code of the current step", func(t *testing.T) {
+		validStep, ok := validateTOTP(rfc6238Secret, "050471", now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, step, validStep)
+	})
+
+	t.Run("should accept codes of adjacent steps", func(t *testing.T) {
+		previous, err := totpCode(rfc6238Secret, step-1)
+		require.NoError(t, err)
+		validStep, ok := validateTOTP(rfc6238Secret, previous, now, 0)
+		assert.True(t, ok)
+		assert.Equal(t, step-1, validStep)
+	})
+
+	t.Run("should reject codes outside of the window", func(t *testing.T) {
+		old, err := totpCode(rfc6238Secret, step-2)
+		require.NoError(t, err)
+		_, ok := validateTOTP(rfc6238Secret, old, now, 0)
+		assert.False(t, ok)
+	})
+
+	t.Run("should reject codes that were already used", func(t *testing.T) {
+		_, ok := validateTOTP(rfc6238Secret, "050471", now, step)
+		assert.False(t, ok)
+	})
+
+	t.Run("should ignore spaces", func(t *testing.T) {
+		_, ok := validateTOTP(rfc6238Secret, " 050 471", now, 0)
+		assert.True(t, ok)
+	})
+
+	t.Run("should reject invalid codes", func(t *testing.T) {
+		for _, code := range []string{"", "12345", "1234567", "123456"} {
+			_, ok := validateTOTP(rfc6238Secret, code, now, 0)
+			assert.False(t, ok, code)
+		}
+	})
+}
+
+func TestTOTPURL(t *testing.T) {
+	u, err := url.Parse(totpURL("Grafana", "admin@example.org", rfc6238Secret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Grafana:admin@example.org", u.Path)
+	assert.Equal(t, rfc6238Secret, u.Query().Get("secret"))
+	assert.Equal(t, "Grafana", u.Query().Get("issuer"))
+}
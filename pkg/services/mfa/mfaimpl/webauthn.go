@@ -0,0 +1,334 @@
+package mfaimpl
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// WebAuthn Level 2 (https://www.w3.org/TR/webauthn-2/) registration and assertion verification.
+// Only the "none" attestation is requested, the attestation statement of registrations is not verified.
+
+const (
+	webAuthnTypeCreate = "webauthn.create"
+	webAuthnTypeGet    = "webauthn.get"
+
+	flagUserPresent            = 0x01
+	flagAttestedCredentialData = 0x40
+
+	// COSE algorithms (https://www.iana.org/assignments/cose/cose.xhtml#algorithms)
+	coseAlgES256 = -7
+	coseAlgEdDSA = -8
+	coseAlgRS256 = -257
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+var supportedCOSEAlgorithms = []int64{coseAlgES256, coseAlgEdDSA, coseAlgRS256}
+
+// webAuthnCredential is a PublicKeyCredential as serialized by the browser, with binary fields encoded as base64url.
+type webAuthnCredential struct {
+	ID       string `json:"id"`
+	RawID    string `json:"rawId"`
+	Type     string `json:"type"`
+	Response struct {
+		ClientDataJSON    string `json:"clientDataJSON"`
+		AttestationObject string `json:"attestationObject"`
+		AuthenticatorData string `json:"authenticatorData"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"userHandle"`
+	} `json:"response"`
+}
+
+type collectedClientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+type authenticatorData struct {
+	rpIDHash     []byte
+	flags        byte
+	signCount    uint32
+	credentialID []byte
+	publicKey    []byte
+}
+
+type webAuthnVerifier struct {
+	rpID    string
+	origins []string
+}
+
+type webAuthnRegistration struct {
+	credentialID string
+	publicKey    []byte
+	signCount    uint32
+}
+
+func parseWebAuthnCredential(raw []byte) (*webAuthnCredential, error) {
+	var cred webAuthnCredential
+	if err := json.Unmarshal(raw, &cred); err != nil {
+		return nil, err
+	}
+	if cred.Type != "public-key" {
+		return nil, fmt.Errorf("unexpected credential type %q", cred.Type)
+	}
+	return &cred, nil
+}
+
+func (v *webAuthnVerifier) verifyRegistration(cred *webAuthnCredential, challenge string) (*webAuthnRegistration, error) {
+	if err := v.verifyClientData(cred.Response.ClientDataJSON, webAuthnTypeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	attestationObject, err := decodeBase64URL(cred.Response.AttestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+	item, _, err := cborDecode(attestationObject)
+	if err != nil {
+		return nil, fmt.Errorf("invalid attestation object: %w", err)
+	}
+	attestation, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid attestation object")
+	}
+	rawAuthData, ok := attestation["authData"].([]byte)
+	if !ok {
+		return nil, errors.New("attestation object without authenticator data")
+	}
+
+	authData, err := v.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return nil, err
+	}
+	if authData.flags&flagAttestedCredentialData == 0 {
+		return nil, errors.New("authenticator data without attested credential")
+	}
+	if _, err := parseCOSEKey(authData.publicKey); err != nil {
+		return nil, err
+	}
+
+	return &webAuthnRegistration{
+		credentialID: base64.RawURLEncoding.EncodeToString(authData.credentialID),
+		publicKey:    authData.publicKey,
+		signCount:    authData.signCount,
+	}, nil
+}
+
+// verifyAssertion verifies the signature of an assertion with the public key of the credential and
+// returns the signature counter of the authenticator.
+func (v *webAuthnVerifier) verifyAssertion(cred *webAuthnCredential, challenge string, publicKey []byte) (uint32, error) {
+	if err := v.verifyClientData(cred.Response.ClientDataJSON, webAuthnTypeGet, challenge); err != nil {
+		return 0, err
+	}
+
+	rawAuthData, err := decodeBase64URL(cred.Response.AuthenticatorData)
+	if err != nil {
+		return 0, fmt.Errorf("invalid authenticator data: %w", err)
+	}
+	authData, err := v.verifyAuthenticatorData(rawAuthData)
+	if err != nil {
+		return 0, err
+	}
+
+	clientDataJSON, err := decodeBase64URL(cred.Response.ClientDataJSON)
+	if err != nil {
+		return 0, err
+	}
+	signature, err := decodeBase64URL(cred.Response.Signature)
+	if err != nil {
+		return 0, fmt.Errorf("invalid signature: %w", err)
+	}
+
+	key, err := parseCOSEKey(publicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	signed := append(append([]byte(nil), rawAuthData...), clientDataHash[:]...)
+	if err := key.verify(signed, signature); err != nil {
+		return 0, err
+	}
+
+	return authData.signCount, nil
+}
+
+func (v *webAuthnVerifier) verifyClientData(encoded, expectedType, challenge string) error {
+	raw, err := decodeBase64URL(encoded)
+	if err != nil {
+		return fmt.Errorf("invalid client data: %w", err)
+	}
+	var clientData collectedClientData
+	if err := json.Unmarshal(raw, &clientData); err != nil {
+		return fmt.Errorf("invalid client data: %w", err)
+	}
+
+	if clientData.Type != expectedType {
+		return fmt.Errorf("unexpected client data type %q", clientData.Type)
+	}
+
+	expected, err := decodeBase64URL(challenge)
+	if err != nil {
+		return err
+	}
+	actual, err := decodeBase64URL(clientData.Challenge)
+	if err != nil || !bytes.Equal(expected, actual) {
+		return errors.New("challenge mismatch")
+	}
+
+	for _, origin := range v.origins {
+		if clientData.Origin == origin {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected origin %q", clientData.Origin)
+}
+
+func (v *webAuthnVerifier) verifyAuthenticatorData(data []byte) (*authenticatorData, error) {
+	authData, err := parseAuthenticatorData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	rpIDHash := sha256.Sum256([]byte(v.rpID))
+	if !bytes.Equal(authData.rpIDHash, rpIDHash[:]) {
+		return nil, errors.New("relying party id mismatch")
+	}
+	if authData.flags&flagUserPresent == 0 {
+		return nil, errors.New("user not present")
+	}
+	return authData, nil
+}
+
+func parseAuthenticatorData(data []byte) (*authenticatorData, error) {
+	if len(data) < 37 {
+		return nil, errors.New("authenticator data too short")
+	}
+
+	authData := &authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	if authData.flags&flagAttestedCredentialData == 0 {
+		return authData, nil
+	}
+
+	// attested credential data: aaguid (16), credential id length (2), credential id, public key
+	rest := data[37:]
+	if len(rest) < 18 {
+		return nil, errors.New("attested credential data too short")
+	}
+	idLength := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLength {
+		return nil, errors.New("attested credential data too short")
+	}
+	authData.credentialID = rest[:idLength]
+	rest = rest[idLength:]
+
+	// the public key can be followed by extensions, only the public key is kept
+	_, extensions, err := cborDecode(rest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+	authData.publicKey = rest[:len(rest)-len(extensions)]
+	return authData, nil
+}
+
+type coseKey struct {
+	alg int64
+	key crypto.PublicKey
+}
+
+func parseCOSEKey(data []byte) (*coseKey, error) {
+	item, _, err := cborDecode(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid credential public key: %w", err)
+	}
+	m, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("invalid credential public key")
+	}
+
+	kty, _ := m[int64(1)].(int64)
+	alg, _ := m[int64(3)].(int64)
+	switch {
+	case kty == coseKeyTypeEC2 && alg == coseAlgES256:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		y, _ := m[int64(-3)].([]byte)
+		if crv != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return nil, errors.New("invalid EC2 credential public key")
+		}
+		// crypto/ecdh rejects points that are not on the curve
+		point := append(append([]byte{4}, x...), y...)
+		if _, err := ecdh.P256().NewPublicKey(point); err != nil {
+			return nil, fmt.Errorf("invalid EC2 credential public key: %w", err)
+		}
+		return &coseKey{alg: alg, key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}}, nil
+	case kty == coseKeyTypeOKP && alg == coseAlgEdDSA:
+		crv, _ := m[int64(-1)].(int64)
+		x, _ := m[int64(-2)].([]byte)
+		if crv != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid OKP credential public key")
+		}
+		return &coseKey{alg: alg, key: ed25519.PublicKey(x)}, nil
+	case kty == coseKeyTypeRSA && alg == coseAlgRS256:
+		n, _ := m[int64(-1)].([]byte)
+		e, _ := m[int64(-2)].([]byte)
+		if len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid RSA credential public key")
+		}
+		key := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if key.N.BitLen() < 2048 {
+			return nil, errors.New("RSA credential public key too short")
+		}
+		return &coseKey{alg: alg, key: key}, nil
+	default:
+		return nil, fmt.Errorf("unsupported credential public key type %d with algorithm %d", kty, alg)
+	}
+}
+
+func (k *coseKey) verify(data, signature []byte) error {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if !ecdsa.VerifyASN1(key, digest[:], signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case ed25519.PublicKey:
+		if !ed25519.Verify(key, data, signature) {
+			return errors.New("invalid signature")
+		}
+		return nil
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature)
+	default:
+		return errors.New("unsupported credential public key")
+	}
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
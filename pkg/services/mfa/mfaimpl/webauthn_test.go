@@ -0,0 +1,234 @@
+package mfaimpl
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "grafana.example.org"
+	testOrigin = "https://grafana.example.org"
+)
+
+func TestWebAuthnVerifier(t *testing.T) {
+	verifier := &webAuthnVerifier{rpID: testRPID, origins: []string{testOrigin}}
+
+	for _, authenticator := range []*fakeAuthenticator{newES256Authenticator(t), newEdDSAAuthenticator(t)} {
+		t.Run("should register and verify assertions", func(t *testing.T) {
+			challenge := base64.RawURLEncoding.EncodeToString([]byte("registration challenge"))
+			registration, err := verifier.verifyRegistration(authenticator.create(t, challenge, testOrigin, testRPID), challenge)
+			require.NoError(t, err)
+			assert.Equal(t, base64.RawURLEncoding.EncodeToString(authenticator.credentialID), registration.credentialID)
+
+			challenge = base64.RawURLEncoding.EncodeToString([]byte("login challenge"))
+			signCount, err := verifier.verifyAssertion(authenticator.get(t, challenge, testOrigin, testRPID), challenge, registration.publicKey)
+			require.NoError(t, err)
+			assert.Equal(t, authenticator.signCount, signCount)
+		})
+	}
+
+	authenticator := newES256Authenticator(t)
+	challenge := base64.RawURLEncoding.EncodeToString([]byte("challenge"))
+	registration, err := verifier.verifyRegistration(authenticator.create(t, challenge, testOrigin, testRPID), challenge)
+	require.NoError(t, err)
+
+	t.Run("should reject registrations for another challenge", func(t *testing.T) {
+		other := base64.RawURLEncoding.EncodeToString([]byte("other"))
+		_, err := verifier.verifyRegistration(authenticator.create(t, other, testOrigin, testRPID), challenge)
+		assert.ErrorContains(t, err, "challenge mismatch")
+	})
+
+	t.Run("should reject assertions from another origin", func(t *testing.T) {
+		_, err := verifier.verifyAssertion(authenticator.get(t, challenge, "https://evil.example.org", testRPID), challenge, registration.publicKey)
+		assert.ErrorContains(t, err, "unexpected origin")
+	})
+
+	t.Run("should reject assertions for another relying party", func(t *testing.T) {
+		_, err := verifier.verifyAssertion(authenticator.get(t, challenge, testOrigin, "evil.example.org"), challenge, registration.publicKey)
+		assert.ErrorContains(t, err, "relying party id mismatch")
+	})
+
+	t.Run("should reject assertions signed by another key", func(t *testing.T) {
+		_, err := verifier.verifyAssertion(newES256Authenticator(t).get(t, challenge, testOrigin, testRPID), challenge, registration.publicKey)
+		assert.ErrorContains(t, err, "invalid signature")
+	})
+
+	t.Run("should reject registrations as assertions", func(t *testing.T) {
+		cred := authenticator.create(t, challenge, testOrigin, testRPID)
+		_, err := verifier.verifyAssertion(cred, challenge, registration.publicKey)
+		assert.ErrorContains(t, err, "unexpected client data type")
+	})
+}
+
+type fakeAuthenticator struct {
+	credentialID []byte
+	coseKey      map[int64]any
+	sign         func(data []byte) []byte
+	signCount    uint32
+}
+
+func newES256Authenticator(t *testing.T) *fakeAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &fakeAuthenticator{
+		credentialID: randomBytes(t, 16),
+		coseKey: map[int64]any{
+			1: int64(coseKeyTypeEC2), 3: int64(coseAlgES256), -1: int64(coseCurveP256),
+			-2: key.X.FillBytes(make([]byte, 32)), -3: key.Y.FillBytes(make([]byte, 32)),
+		},
+		sign: func(data []byte) []byte {
+			digest := sha256.Sum256(data)
+			sig, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+			require.NoError(t, err)
+			return sig
+		},
+	}
+}
+
+func newEdDSAAuthenticator(t *testing.T) *fakeAuthenticator {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &fakeAuthenticator{
+		credentialID: randomBytes(t, 32),
+		coseKey: map[int64]any{
+			1: int64(coseKeyTypeOKP), 3: int64(coseAlgEdDSA), -1: int64(coseCurveEd25519), -2: []byte(public),
+		},
+		sign: func(data []byte) []byte {
+			return ed25519.Sign(private, data)
+		},
+	}
+}
+
+// create returns the credential navigator.credentials.create would return.
+func (a *fakeAuthenticator) create(t *testing.T, challenge, origin, rpID string) *webAuthnCredential {
+	authData := a.authenticatorData(rpID, flagUserPresent|flagAttestedCredentialData)
+	authData = append(authData, make([]byte, 16)...) // aaguid
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, cborEncode(a.coseKey)...)
+
+	cred := &webAuthnCredential{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(a.credentialID)}
+	cred.RawID = cred.ID
+	cred.Response.ClientDataJSON = clientDataJSON(t, webAuthnTypeCreate, challenge, origin)
+	cred.Response.AttestationObject = base64.RawURLEncoding.EncodeToString(cborEncode(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	}))
+	return cred
+}
+
+// get returns the credential navigator.credentials.get would return.
+func (a *fakeAuthenticator) get(t *testing.T, challenge, origin, rpID string) *webAuthnCredential {
+	a.signCount++
+	authData := a.authenticatorData(rpID, flagUserPresent)
+
+	cred := &webAuthnCredential{Type: "public-key", ID: base64.RawURLEncoding.EncodeToString(a.credentialID)}
+	cred.RawID = cred.ID
+	cred.Response.ClientDataJSON = clientDataJSON(t, webAuthnTypeGet, challenge, origin)
+	clientDataHash := sha256.Sum256(mustDecodeBase64URL(t, cred.Response.ClientDataJSON))
+	cred.Response.AuthenticatorData = base64.RawURLEncoding.EncodeToString(authData)
+	cred.Response.Signature = base64.RawURLEncoding.EncodeToString(a.sign(append(authData, clientDataHash[:]...)))
+	return cred
+}
+
+func (a *fakeAuthenticator) authenticatorData(rpID string, flags byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte(nil), rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+func clientDataJSON(t *testing.T, typ, challenge, origin string) string {
+	data, err := json.Marshal(collectedClientData{Type: typ, Challenge: challenge, Origin: origin})
+	require.NoError(t, err)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func mustDecodeBase64URL(t *testing.T, s string) []byte {
+	data, err := decodeBase64URL(s)
+	require.NoError(t, err)
+	return data
+}
+
+func randomBytes(t *testing.T, size int) []byte {
+	b := make([]byte, size)
+	_, err := rand.Read(b)
+	require.NoError(t, err)
+	return b
+}
+
+// cborEncode encodes the values used by authenticators with the canonical CBOR encoding.
+func cborEncode(v any) []byte {
+	var buf bytes.Buffer
+	switch v := v.(type) {
+	case int64:
+		if v >= 0 {
+			cborEncodeHead(&buf, 0, uint64(v))
+		} else {
+			cborEncodeHead(&buf, 1, uint64(-1-v))
+		}
+	case []byte:
+		cborEncodeHead(&buf, 2, uint64(len(v)))
+		buf.Write(v)
+	case string:
+		cborEncodeHead(&buf, 3, uint64(len(v)))
+		buf.WriteString(v)
+	case map[int64]any:
+		keys := make([]int64, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return bytes.Compare(cborEncode(keys[i]), cborEncode(keys[j])) < 0 })
+		cborEncodeHead(&buf, 5, uint64(len(v)))
+		for _, k := range keys {
+			buf.Write(cborEncode(k))
+			buf.Write(cborEncode(v[k]))
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		cborEncodeHead(&buf, 5, uint64(len(v)))
+		for _, k := range keys {
+			buf.Write(cborEncode(k))
+			buf.Write(cborEncode(v[k]))
+		}
+	default:
+		panic("unsupported type")
+	}
+	return buf.Bytes()
+}
+
+func cborEncodeHead(buf *bytes.Buffer, major byte, arg uint64) {
+	switch {
+	case arg < 24:
+		buf.WriteByte(major<<5 | byte(arg))
+	case arg <= 0xff:
+		buf.WriteByte(major<<5 | 24)
+		buf.WriteByte(byte(arg))
+	case arg <= 0xffff:
+		buf.WriteByte(major<<5 | 25)
+		buf.Write(binary.BigEndian.AppendUint16(nil, uint16(arg)))
+	case arg <= 0xffffffff:
+		buf.WriteByte(major<<5 | 26)
+		buf.Write(binary.BigEndian.AppendUint32(nil, uint32(arg)))
+	default:
+		buf.WriteByte(major<<5 | 27)
+		buf.Write(binary.BigEndian.AppendUint64(nil, arg))
+	}
+}
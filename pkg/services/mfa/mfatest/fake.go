@@ -0,0 +1,92 @@
+package mfatest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/mfa"
+)
+
+var _ mfa.Service = new(FakeService)
+
+type FakeService struct {
+	ExpectedEnabled        bool
+	ExpectedStatus         *mfa.Status
+	ExpectedRequired       bool
+	ExpectedTOTPEnrollment *mfa.TOTPEnrollment
+	ExpectedCreationOpts   *mfa.WebAuthnCreationOptions
+	ExpectedFactor         *mfa.Factor
+	ExpectedRecoveryCodes  []string
+	ExpectedChallenge      *mfa.Challenge
+	ExpectedOrgPolicy      *mfa.OrgPolicy
+	ExpectedErr            error
+
+	ResetUserID int64
+}
+
+func (f *FakeService) IsEnabled() bool {
+	return f.ExpectedEnabled
+}
+
+func (f *FakeService) GetStatus(ctx context.Context, userID int64) (*mfa.Status, error) {
+	if f.ExpectedStatus == nil && f.ExpectedErr == nil {
+		return &mfa.Status{}, nil
+	}
+	return f.ExpectedStatus, f.ExpectedErr
+}
+
+func (f *FakeService) IsRequired(ctx context.Context, userID int64) (bool, error) {
+	return f.ExpectedRequired, f.ExpectedErr
+}
+
+func (f *FakeService) BeginTOTPEnrollment(ctx context.Context, userID int64, login string) (*mfa.TOTPEnrollment, error) {
+	return f.ExpectedTOTPEnrollment, f.ExpectedErr
+}
+
+func (f *FakeService) ConfirmTOTPEnrollment(ctx context.Context, cmd *mfa.ConfirmTOTPEnrollmentCommand) (*mfa.Factor, error) {
+	return f.ExpectedFactor, f.ExpectedErr
+}
+
+func (f *FakeService) BeginWebAuthnRegistration(ctx context.Context, userID int64, login string) (*mfa.WebAuthnCreationOptions, error) {
+	return f.ExpectedCreationOpts, f.ExpectedErr
+}
+
+func (f *FakeService) FinishWebAuthnRegistration(ctx context.Context, cmd *mfa.FinishWebAuthnRegistrationCommand) (*mfa.Factor, error) {
+	return f.ExpectedFactor, f.ExpectedErr
+}
+
+func (f *FakeService) DeleteFactor(ctx context.Context, userID, factorID int64) error {
+	return f.ExpectedErr
+}
+
+func (f *FakeService) GenerateRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	return f.ExpectedRecoveryCodes, f.ExpectedErr
+}
+
+func (f *FakeService) Reset(ctx context.Context, userID int64) error {
+	f.ResetUserID = userID
+	return f.ExpectedErr
+}
+
+func (f *FakeService) CreateChallenge(ctx context.Context, cmd *mfa.CreateChallengeCommand) (*mfa.Challenge, error) {
+	return f.ExpectedChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) GetChallenge(ctx context.Context, challengeID string) (*mfa.Challenge, error) {
+	return f.ExpectedChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) VerifyChallenge(ctx context.Context, cmd *mfa.VerifyChallengeCommand) (*mfa.Challenge, error) {
+	return f.ExpectedChallenge, f.ExpectedErr
+}
+
+func (f *FakeService) GetOrgPolicy(ctx context.Context, orgID int64) (*mfa.OrgPolicy, error) {
+	if f.ExpectedOrgPolicy == nil && f.ExpectedErr == nil {
+		return &mfa.OrgPolicy{}, nil
+	}
+	return f.ExpectedOrgPolicy, f.ExpectedErr
+}
+
+func (f *FakeService) SetOrgPolicy(ctx context.Context, orgID int64, policy mfa.OrgPolicy) error {
+	f.ExpectedOrgPolicy = &policy
+	return f.ExpectedErr
+}
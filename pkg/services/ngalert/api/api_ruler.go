@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/apimachinery/errutil"
 	"github.com/grafana/grafana/pkg/apimachinery/identity"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/audit"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
@@ -57,6 +58,7 @@ type RulerSrv struct {
 	amConfigStore  AMConfigStore
 	amRefresher    AMRefresher
 	featureManager featuremgmt.FeatureToggles
+	audit          audit.Service
 }
 
 var (
@@ -95,6 +97,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 		return ErrResp(http.StatusInternalServerError, err, "failed to fetch provenances of alert rules")
 	}
 
+	var deleted []string
 	err = srv.xactManager.InTransaction(c.Req.Context(), func(ctx context.Context) error {
 		deletionCandidates := map[ngmodels.AlertRuleGroupKey]ngmodels.RulesGroup{}
 		if group != "" {
@@ -152,6 +155,7 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 				return err
 			}
 			logger.Info("Alert rules were deleted", "ruleUid", strings.Join(rulesToDelete, ","))
+			deleted = rulesToDelete
 			return nil
 		}
 		// if none rules were deleted return an error.
@@ -179,6 +183,14 @@ func (srv RulerSrv) RouteDeleteAlertRules(c *contextmodel.ReqContext, namespaceU
 		}
 		return ErrResp(http.StatusInternalServerError, err, "failed to delete rule group")
 	}
+
+	if len(deleted) > 0 {
+		srv.audit.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionAlertRuleGroupDelete).
+			WithResource(audit.ResourceAlertRuleGroup, ruleGroupResourceID(namespace.UID, group), group).
+			WithDetail("namespaceUid", namespace.UID).
+			WithDetail("deleted", deleted))
+	}
+
 	return response.JSON(http.StatusAccepted, util.DynMap{"message": "rules deleted"})
 }
 
@@ -492,9 +504,44 @@ func (srv RulerSrv) updateAlertRulesInGroup(c *contextmodel.ReqContext, groupKey
 		}
 	}
 
+	if !finalChanges.IsEmpty() {
+		srv.audit.Log(c.Req.Context(), auditRuleGroupUpdate(c, groupKey, finalChanges))
+	}
+
 	return changesToResponse(finalChanges)
 }
 
+// ruleGroupResourceID identifies a rule group in audit events. It is the namespace UID
+// when all groups of the namespace are affected.
+func ruleGroupResourceID(namespaceUID, group string) string {
+	if group == "" {
+		return namespaceUID
+	}
+	return namespaceUID + "/" + group
+}
+
+func auditRuleGroupUpdate(c *contextmodel.ReqContext, groupKey ngmodels.AlertRuleGroupKey, changes *store.GroupDelta) *audit.Event {
+	created := make([]string, 0, len(changes.New))
+	for _, r := range changes.New {
+		created = append(created, r.UID)
+	}
+	updated := make([]string, 0, len(changes.Update))
+	for _, r := range changes.Update {
+		updated = append(updated, r.Existing.UID)
+	}
+	deleted := make([]string, 0, len(changes.Delete))
+	for _, r := range changes.Delete {
+		deleted = append(deleted, r.UID)
+	}
+
+	return audit.NewEvent(c.Req, c.SignedInUser, audit.ActionAlertRuleGroupUpdate).
+		WithResource(audit.ResourceAlertRuleGroup, ruleGroupResourceID(groupKey.NamespaceUID, groupKey.RuleGroup), groupKey.RuleGroup).
+		WithDetail("namespaceUid", groupKey.NamespaceUID).
+		WithDetail("created", created).
+		WithDetail("updated", updated).
+		WithDetail("deleted", deleted)
+}
+
 func changesToResponse(finalChanges *store.GroupDelta) response.Response {
 	body := apimodels.UpdateRuleGroupResponse{
 		Message: "rule group updated successfully",
@@ -14,6 +14,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/correlations"
 	"github.com/grafana/grafana/pkg/services/ngalert/eval"
 	"github.com/grafana/grafana/pkg/services/ngalert/models"
 	"github.com/grafana/grafana/pkg/services/ngalert/state/template"
@@ -54,7 +55,7 @@ func Test_expand(t *testing.T) {
 		// err should be an ExpandError that contains the template for the Summary and an error
 		var expandErr template.ExpandError
 		require.True(t, errors.As(err, &expandErr))
-		require.EqualError(t, expandErr, "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}Instance {{ $labels. }} has been down for more than 5 minutes': error parsing template __alert_test: template: __alert_test:1: unexpected <.> in operand")
+		require.EqualError(t, expandErr, "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $correlations := .Correlations -}}Instance {{ $labels. }} has been down for more than 5 minutes': error parsing template __alert_test: template: __alert_test:1: unexpected <.> in operand")
 	})
 
 	t.Run("originals are returned with two errors", func(t *testing.T) {
@@ -78,8 +79,8 @@ func Test_expand(t *testing.T) {
 			unwrappedErrors[1].Error(),
 		}
 
-		firstErrStr := "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}Instance {{ $labels. }} has been down for more than 5 minutes': error parsing template __alert_test: template: __alert_test:1: unexpected <.> in operand"
-		secondErrStr := "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}The instance has been down for {{ $value minutes, please check the instance is online': error parsing template __alert_test: template: __alert_test:1: function \"minutes\" not defined"
+		firstErrStr := "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $correlations := .Correlations -}}Instance {{ $labels. }} has been down for more than 5 minutes': error parsing template __alert_test: template: __alert_test:1: unexpected <.> in operand"
+		secondErrStr := "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $correlations := .Correlations -}}The instance has been down for {{ $value minutes, please check the instance is online': error parsing template __alert_test: template: __alert_test:1: function \"minutes\" not defined"
 
 		require.Contains(t, errsStr, firstErrStr)
 		require.Contains(t, errsStr, secondErrStr)
@@ -113,7 +114,7 @@ func Test_expand(t *testing.T) {
 		// assert each error matches the expected error
 		var expandErr template.ExpandError
 		require.True(t, errors.As(err, &expandErr))
-		require.EqualError(t, expandErr, "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}The instance has been down for {{ $value minutes, please check the instance is online': error parsing template __alert_test: template: __alert_test:1: function \"minutes\" not defined")
+		require.EqualError(t, expandErr, "failed to expand template '{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $correlations := .Correlations -}}The instance has been down for {{ $value minutes, please check the instance is online': error parsing template __alert_test: template: __alert_test:1: function \"minutes\" not defined")
 	})
 }
 
@@ -136,7 +137,7 @@ func Test_getOrCreate(t *testing.T) {
 		result := eval.Result{
 			Instance: models.GenerateAlertLabels(5, "result-"),
 		}
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, nil, url)
 		for key, expected := range extraLabels {
 			require.Equal(t, expected, state.Labels[key])
 		}
@@ -164,7 +165,7 @@ func Test_getOrCreate(t *testing.T) {
 			result.Instance[key] = "result-" + util.GenerateShortUID()
 		}
 
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, nil, url)
 		for key, expected := range extraLabels {
 			require.Equal(t, expected, state.Labels[key])
 		}
@@ -180,7 +181,7 @@ func Test_getOrCreate(t *testing.T) {
 		for key := range rule.Labels {
 			result.Instance[key] = "result-" + util.GenerateShortUID()
 		}
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, nil, url)
 		for key, expected := range rule.Labels {
 			require.Equal(t, expected, state.Labels[key])
 		}
@@ -202,7 +203,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule.Labels = labelTemplates
 
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, nil, url)
 		for key, expected := range extraLabels {
 			assert.Equal(t, expected, state.Labels["rule-"+key])
 		}
@@ -229,7 +230,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule.Annotations = annotationTemplates
 
-		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, extraLabels, nil, url)
 		for key, expected := range extraLabels {
 			assert.Equal(t, expected, state.Annotations["rule-"+key])
 		}
@@ -248,7 +249,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule := generateRule()
 
-		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, nil, url)
 		assert.Equal(t, map[string]float64{"A": 1, "B": 2}, state.Values)
 	})
 
@@ -262,7 +263,7 @@ func Test_getOrCreate(t *testing.T) {
 		}
 		rule := generateRule()
 
-		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, nil, url)
 		assert.Equal(t, map[string]float64{"B0": 1, "B1": 2}, state.Values)
 	})
 
@@ -288,7 +289,7 @@ func Test_getOrCreate(t *testing.T) {
 
 		rule := generateRule()
 
-		state := c.getOrCreate(context.Background(), l, rule, result, nil, url)
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, nil, url)
 
 		for key := range models.LabelsUserCannotSpecify {
 			assert.NotContains(t, state.Labels, key)
@@ -310,7 +311,7 @@ func Test_getOrCreate(t *testing.T) {
 			result.Instance["label1_user"] = uuid.NewString()
 			result.Instance["label4_user"] = uuid.NewString()
 
-			state = c.getOrCreate(context.Background(), l, rule, result, nil, url)
+			state = c.getOrCreate(context.Background(), l, rule, result, nil, nil, url)
 			assert.NotContains(t, state.Labels, "__label1__")
 			assert.Contains(t, state.Labels, "label1")
 			assert.Equal(t, state.Labels["label1"], result.Instance["label1"])
@@ -320,6 +321,41 @@ func Test_getOrCreate(t *testing.T) {
 			assert.Equal(t, state.Labels["label4_user"], result.Instance["label4_user"])
 		})
 	})
+
+	t.Run("should expand correlations of the data sources of the rule", func(t *testing.T) {
+		rule := generateRule()
+		rule.Annotations = map[string]string{"runbook_url": `{{ index $correlations "Runbook" }}`}
+		result := eval.Result{
+			Instance: data.Labels{"service": "checkout"},
+		}
+		resolver := &fakeCorrelationsResolver{}
+
+		state := c.getOrCreate(context.Background(), l, rule, result, nil, resolver, url)
+		assert.Equal(t, "https://runbooks.example.com/checkout", state.Annotations["runbook_url"])
+		require.NotEmpty(t, resolver.queries)
+		assert.Equal(t, rule.Data[0].DatasourceUID, resolver.queries[0].SourceUID)
+		assert.Equal(t, rule.OrgID, resolver.queries[0].OrgId)
+		assert.Equal(t, "checkout", resolver.queries[0].Labels["service"])
+
+		t.Run("should not resolve correlations if templates do not use them", func(t *testing.T) {
+			rule := generateRule()
+			resolver := &fakeCorrelationsResolver{}
+			_ = c.getOrCreate(context.Background(), l, rule, result, nil, resolver, url)
+			assert.Empty(t, resolver.queries)
+		})
+	})
+}
+
+type fakeCorrelationsResolver struct {
+	queries []correlations.ResolveCorrelationsBySourceUIDQuery
+}
+
+func (f *fakeCorrelationsResolver) ResolveCorrelationsBySourceUID(_ context.Context, query correlations.ResolveCorrelationsBySourceUIDQuery) ([]correlations.ResolvedCorrelation, error) {
+	f.queries = append(f.queries, query)
+	return []correlations.ResolvedCorrelation{
+		{Label: "Logs", Type: correlations.ConfigTypeQuery, Target: map[string]any{"expr": "{}"}},
+		{Label: "Runbook", Type: correlations.ConfigTypeExternal, URL: "https://runbooks.example.com/" + query.Labels["service"]},
+	}, nil
 }
 
 func Test_mergeLabels(t *testing.T) {
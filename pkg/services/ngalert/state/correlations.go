@@ -0,0 +1,67 @@
+package state
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/correlations"
+	ngModels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// CorrelationsResolver resolves the correlations originating from a data source.
+type CorrelationsResolver interface {
+	ResolveCorrelationsBySourceUID(ctx context.Context, query correlations.ResolveCorrelationsBySourceUIDQuery) ([]correlations.ResolvedCorrelation, error)
+}
+
+// resolveCorrelations returns the URLs of the external correlations of the data
+// sources queried by the rule, resolved with the labels of an alert, by
+// correlation label. Correlations are only resolved if labels or annotations
+// of the rule use them, as this requires a database query per data source.
+func resolveCorrelations(ctx context.Context, logger log.Logger, resolver CorrelationsResolver, alertRule *ngModels.AlertRule, labels map[string]string) map[string]string {
+	if resolver == nil || !usesCorrelations(alertRule) {
+		return nil
+	}
+	result := make(map[string]string)
+	seen := make(map[string]struct{}, len(alertRule.Data))
+	for _, query := range alertRule.Data {
+		if isExpr, _ := query.IsExpression(); isExpr {
+			continue
+		}
+		if _, ok := seen[query.DatasourceUID]; ok {
+			continue
+		}
+		seen[query.DatasourceUID] = struct{}{}
+		resolved, err := resolver.ResolveCorrelationsBySourceUID(ctx, correlations.ResolveCorrelationsBySourceUIDQuery{
+			SourceUID: query.DatasourceUID,
+			OrgId:     alertRule.OrgID,
+			Values:    labels,
+			Labels:    labels,
+		})
+		if err != nil {
+			logger.Warn("Failed to resolve correlations", "datasourceUID", query.DatasourceUID, "error", err)
+			continue
+		}
+		for _, correlation := range resolved {
+			if correlation.Type != correlations.ConfigTypeExternal || correlation.URL == "" {
+				continue
+			}
+			// The first correlation with a label wins.
+			if _, ok := result[correlation.Label]; !ok {
+				result[correlation.Label] = correlation.URL
+			}
+		}
+	}
+	return result
+}
+
+func usesCorrelations(alertRule *ngModels.AlertRule) bool {
+	for _, templates := range []map[string]string{alertRule.Labels, alertRule.Annotations} {
+		for _, tmpl := range templates {
+			if strings.Contains(tmpl, "$correlations") || strings.Contains(tmpl, ".Correlations") {
+				return true
+			}
+		}
+	}
+	return false
+}
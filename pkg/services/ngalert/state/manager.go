@@ -50,6 +50,7 @@ type Manager struct {
 	instanceStore InstanceStore
 	images        ImageCapturer
 	historian     Historian
+	correlations  CorrelationsResolver
 	externalURL   *url.URL
 
 	doNotSaveNormalState           bool
@@ -66,6 +67,8 @@ type ManagerCfg struct {
 	Images        ImageCapturer
 	Clock         clock.Clock
 	Historian     Historian
+	// Correlations resolves the correlations used in templates of labels and annotations.
+	Correlations CorrelationsResolver
 	// DoNotSaveNormalState controls whether eval.Normal state is persisted to the database and returned by get methods
 	DoNotSaveNormalState bool
 	// MaxStateSaveConcurrency controls the number of goroutines (per rule) that can save alert state in parallel.
@@ -101,6 +104,7 @@ func NewManager(cfg ManagerCfg, statePersister StatePersister) *Manager {
 		instanceStore:                  cfg.InstanceStore,
 		images:                         cfg.Images,
 		historian:                      cfg.Historian,
+		correlations:                   cfg.Correlations,
 		clock:                          cfg.Clock,
 		externalURL:                    cfg.ExternalURL,
 		doNotSaveNormalState:           cfg.DoNotSaveNormalState,
@@ -382,7 +386,7 @@ func (st *Manager) setNextStateForRule(ctx context.Context, alertRule *ngModels.
 	}
 	transitions := make([]StateTransition, 0, len(results))
 	for _, result := range results {
-		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.externalURL)
+		currentState := st.cache.getOrCreate(ctx, logger, alertRule, result, extraLabels, st.correlations, st.externalURL)
 		s := st.setNextState(ctx, alertRule, currentState, result, logger)
 		transitions = append(transitions, s)
 	}
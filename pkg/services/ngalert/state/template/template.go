@@ -74,6 +74,9 @@ type Data struct {
 	Labels Labels
 	Values map[string]Value
 	Value  string
+	// Correlations are the URLs of the external correlations of the data
+	// sources of the rule resolved with the labels, by correlation label.
+	Correlations map[string]string
 }
 
 func NewData(labels map[string]string, res eval.Result) Data {
@@ -103,7 +106,7 @@ func Expand(ctx context.Context, name, tmpl string, data Data, externalURL *url.
 	// add __alert_ to avoid possible conflicts with other templates
 	name = "__alert_" + name
 	// add variables for the labels and values to the beginning of the template
-	tmpl = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}" + tmpl
+	tmpl = "{{- $labels := .Labels -}}{{- $values := .Values -}}{{- $value := .Value -}}{{- $correlations := .Correlations -}}" + tmpl
 	// ctx and queryFunc are no-ops as `query()` is not supported in Grafana
 	queryFunc := func(context.Context, string, time.Time) (promql.Vector, error) {
 		return nil, nil
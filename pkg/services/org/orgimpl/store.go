@@ -817,6 +817,8 @@ func (ss *sqlStore) userDeletions() []string {
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM user_mfa_factor WHERE user_id = ?",
+		"DELETE FROM user_mfa_recovery_code WHERE user_id = ?",
 	}
 	return deletes
 }
@@ -448,6 +448,10 @@ func (m *mockCorrelationsStore) DeleteCorrelationsByTargetUID(c context.Context,
 	return nil
 }
 
+func (m *mockCorrelationsStore) ResolveCorrelationsBySourceUID(c context.Context, query correlations.ResolveCorrelationsBySourceUIDQuery) ([]correlations.ResolvedCorrelation, error) {
+	return nil, nil
+}
+
 type spyStore struct {
 	inserted []*datasources.AddDataSourceCommand
 	deleted  []*datasources.DeleteDataSourceCommand
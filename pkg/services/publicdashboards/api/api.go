@@ -73,9 +73,9 @@ func (api *Api) RegisterAPIEndpoints() {
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
 	api.routeRegister.Group("/api/public/dashboards/:accessToken", func(apiRoute routing.RouteRegister) {
-		apiRoute.Get("/", routing.Wrap(api.ViewPublicDashboard))
-		apiRoute.Get("/annotations", routing.Wrap(api.GetPublicAnnotations))
-		apiRoute.Post("/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+		apiRoute.Get("/", CheckAccessToken(api.PublicDashboardService, TokenAccessView), routing.Wrap(api.ViewPublicDashboard))
+		apiRoute.Get("/annotations", CheckAccessToken(api.PublicDashboardService, TokenAccessQuery), routing.Wrap(api.GetPublicAnnotations))
+		apiRoute.Post("/panels/:panelId/query", CheckAccessToken(api.PublicDashboardService, TokenAccessQuery), routing.Wrap(api.QueryPublicDashboard))
 	}, api.Middleware.HandleApi)
 
 	// Auth endpoints
@@ -103,6 +103,21 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// List access tokens of Public Dashboard
+	api.routeRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.ListPublicDashboardTokens))
+
+	// Create access token for Public Dashboard
+	api.routeRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.CreatePublicDashboardToken))
+
+	// Revoke access token of Public Dashboard
+	api.routeRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/tokens/:tokenUid",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.RevokePublicDashboardToken))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboard_public listPublicDashboards
@@ -300,6 +315,118 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 	return response.Empty(http.StatusOK)
 }
 
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens dashboard_public listPublicDashboardTokens
+//
+//	List access tokens of a public dashboard with their access log
+//
+// Responses:
+// 200: listPublicDashboardTokensResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) ListPublicDashboardTokens(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardTokens: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardTokens: invalid Uid %s", uid))
+	}
+
+	tokens, err := api.PublicDashboardService.FindTokens(c.Req.Context(), c.SignedInUser.GetOrgID(), dashboardUid, uid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens dashboard_public createPublicDashboardToken
+//
+//	Create an access token for a public dashboard
+//
+// Produces:
+// - application/json
+//
+// Responses:
+// 200: createPublicDashboardTokenResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) CreatePublicDashboardToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardToken: invalid Uid %s", uid))
+	}
+
+	tokenDTO := &PublicDashboardTokenDTO{}
+	if err := web.Bind(c.Req, tokenDTO); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreatePublicDashboardToken: bad request data %v", err))
+	}
+
+	// Always set the orgID and userID from the session
+	dto := &SavePublicDashboardTokenDTO{
+		PublicDashboardUid: uid,
+		DashboardUid:       dashboardUid,
+		OrgID:              c.SignedInUser.GetOrgID(),
+		UserId:             c.UserID,
+		Token:              tokenDTO,
+	}
+
+	token, err := api.PublicDashboardService.CreateToken(c.Req.Context(), c.SignedInUser, dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, token)
+}
+
+// swagger:route DELETE /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/tokens/{tokenUid} dashboard_public revokePublicDashboardToken
+//
+//	Revoke an access token of a public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) RevokePublicDashboardToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardToken: invalid Uid %s", uid))
+	}
+
+	tokenUid := web.Params(c.Req)[":tokenUid"]
+	if !validation.IsValidShortUID(tokenUid) {
+		return response.Err(ErrInvalidUid.Errorf("RevokePublicDashboardToken: invalid token Uid %s", tokenUid))
+	}
+
+	err := api.PublicDashboardService.RevokeToken(c.Req.Context(), c.SignedInUser.GetOrgID(), dashboardUid, uid, tokenUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.Empty(http.StatusOK)
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(ctx context.Context, features featuremgmt.FeatureToggles, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
@@ -379,3 +506,51 @@ type DeletePublicDashboardParams struct {
 	// required:true
 	Uid string `json:"uid"`
 }
+
+// swagger:parameters listPublicDashboardTokens
+type ListPublicDashboardTokensParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response listPublicDashboardTokensResponse
+type ListPublicDashboardTokensResponse struct {
+	// in: body
+	Body []*PublicDashboardToken `json:"body"`
+}
+
+// swagger:parameters createPublicDashboardToken
+type CreatePublicDashboardTokenParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	// required:true
+	Body PublicDashboardTokenDTO
+}
+
+// swagger:response createPublicDashboardTokenResponse
+type CreatePublicDashboardTokenResponse struct {
+	// in: body
+	Body PublicDashboardToken `json:"body"`
+}
+
+// swagger:parameters revokePublicDashboardToken
+type RevokePublicDashboardTokenParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:path
+	// required:true
+	TokenUid string `json:"tokenUid"`
+}
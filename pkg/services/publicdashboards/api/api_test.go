@@ -287,6 +287,72 @@ func TestAPIDeletePublicDashboard(t *testing.T) {
 	}
 }
 
+func TestAPIPublicDashboardTokens(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	basePath := fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/tokens", dashboardUid, publicDashboardUid)
+	token := &PublicDashboardToken{Uid: "tokenUid", PublicDashboardUid: publicDashboardUid, AccessToken: validAccessToken, Label: "partners", RateLimit: 60, ViewCount: 3}
+
+	t.Run("viewers can't manage tokens", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		testServer := setupTestServer(t, nil, service, userViewer, true)
+
+		response := callAPI(testServer, http.MethodGet, basePath, nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		response = callAPI(testServer, http.MethodPost, basePath, strings.NewReader(`{}`), t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		response = callAPI(testServer, http.MethodDelete, basePath+"/tokenUid", nil, t)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+
+	t.Run("lists tokens with their access log", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("FindTokens", mock.Anything, int64(1), dashboardUid, publicDashboardUid).Return([]*PublicDashboardToken{token}, nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodGet, basePath, nil, t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var tokens []*PublicDashboardToken
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &tokens))
+		assert.Equal(t, []*PublicDashboardToken{token}, tokens)
+	})
+
+	t.Run("creates a token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CreateToken", mock.Anything, mock.Anything, mock.MatchedBy(func(dto *SavePublicDashboardTokenDTO) bool {
+			return dto.PublicDashboardUid == publicDashboardUid && dto.DashboardUid == dashboardUid && dto.OrgID == 1 &&
+				dto.Token.Label == "partners" && dto.Token.RateLimit == 60 && assert.ObjectsAreEqual([]string{"10.0.0.0/8"}, dto.Token.AllowedIPs)
+		})).Return(token, nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodPost, basePath, strings.NewReader(`{"label": "partners", "rateLimit": 60, "allowedIps": ["10.0.0.0/8"]}`), t)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var created PublicDashboardToken
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+		assert.Equal(t, validAccessToken, created.AccessToken)
+	})
+
+	t.Run("revokes a token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("RevokeToken", mock.Anything, int64(1), dashboardUid, publicDashboardUid, "tokenUid").Return(nil)
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodDelete, basePath+"/tokenUid", nil, t)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("returns 404 when revoking an unknown token", func(t *testing.T) {
+		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("RevokeToken", mock.Anything, int64(1), dashboardUid, publicDashboardUid, "unknown").Return(ErrAccessTokenNotFound.Errorf(""))
+		testServer := setupTestServer(t, nil, service, userAdmin, true)
+
+		response := callAPI(testServer, http.MethodDelete, basePath+"/unknown", nil, t)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+	})
+}
+
 func TestAPIGetPublicDashboard(t *testing.T) {
 	pubdash := &PublicDashboard{IsEnabled: true}
 
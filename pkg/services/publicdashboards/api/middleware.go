@@ -1,11 +1,14 @@
 package api
 
 import (
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -62,6 +65,28 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// CheckAccessToken Middleware to enforce the IP allow-list and rate limit of the access token, and to record its use in
+// the token access log.
+func CheckAccessToken(publicDashboardService publicdashboards.Service, access TokenAccess) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			// the handler responds to invalid access tokens
+			return
+		}
+
+		// use the address of the connection, the X-Real-IP and X-Forwarded-For headers can be set by the client
+		host, _, err := net.SplitHostPort(c.Req.RemoteAddr)
+		if err != nil {
+			host = c.Req.RemoteAddr
+		}
+		ip := net.ParseIP(strings.Trim(host, "[]"))
+		if err := publicDashboardService.CheckAccessToken(c.Req.Context(), accessToken, ip, access); err != nil {
+			c.WriteErr(err)
+		}
+	}
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
@@ -2,14 +2,17 @@ package api
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"errors"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
@@ -144,6 +147,86 @@ func TestSetPublicDashboardOrgIdOnContext(t *testing.T) {
 	}
 }
 
+func TestCheckAccessToken(t *testing.T) {
+	tests := []struct {
+		Name                 string
+		AccessToken          string
+		CheckErr             error
+		ShouldCallService    bool
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Continues when the access token can be used",
+			AccessToken:          validAccessToken,
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Leaves invalid access tokens to the handler",
+			AccessToken:          "invalidAccessToken",
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 403 when the address is not allowed",
+			AccessToken:          validAccessToken,
+			CheckErr:             ErrAccessTokenIPNotAllowed.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Returns 429 when the token is rate limited",
+			AccessToken:          validAccessToken,
+			CheckErr:             ErrAccessTokenRateLimited.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusTooManyRequests,
+		},
+		{
+			Name:                 "Returns 404 when the token is revoked or expired",
+			AccessToken:          validAccessToken,
+			CheckErr:             ErrPublicDashboardNotFound.Errorf(""),
+			ShouldCallService:    true,
+			ExpectedResponseCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := publicdashboards.NewFakePublicDashboardService(t)
+			if tt.ShouldCallService {
+				publicdashboardService.On("CheckAccessToken", mock.Anything, tt.AccessToken, mock.Anything, TokenAccessQuery).Return(tt.CheckErr)
+			}
+
+			params := map[string]string{":accessToken": tt.AccessToken}
+			mw := CheckAccessToken(publicdashboardService, TokenAccessQuery)
+			_, resp := runMw(t, &contextmodel.ReqContext{Logger: log.NewNopLogger()}, "POST", "/api/public/dashboards/myAccesstoken/panels/1/query", params, mw)
+			assert.Equal(t, tt.ExpectedResponseCode, resp.Code)
+		})
+	}
+}
+
+func TestCheckAccessTokenIgnoresForwardedHeaders(t *testing.T) {
+	publicdashboardService := publicdashboards.NewFakePublicDashboardService(t)
+	publicdashboardService.On("CheckAccessToken", mock.Anything, validAccessToken, mock.MatchedBy(func(ip net.IP) bool {
+		return ip.Equal(net.ParseIP("10.0.0.1"))
+	}), TokenAccessQuery).Return(ErrAccessTokenIPNotAllowed.Errorf(""))
+
+	request, err := http.NewRequest("POST", "/api/public/dashboards/myAccesstoken/panels/1/query", nil)
+	require.NoError(t, err)
+	request.RemoteAddr = "10.0.0.1:52000"
+	request.Header.Set("X-Real-IP", "192.168.0.1")
+	request.Header.Set("X-Forwarded-For", "192.168.0.1")
+
+	response := httptest.NewRecorder()
+	ctx := &contextmodel.ReqContext{
+		Context:      &web.Context{Req: web.SetURLParams(request, map[string]string{":accessToken": validAccessToken}), Resp: web.NewResponseWriter("POST", response)},
+		SignedInUser: &user.SignedInUser{},
+		Logger:       log.NewNopLogger(),
+	}
+	CheckAccessToken(publicdashboardService, TokenAccessQuery)(ctx)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+}
+
 func TestSetPublicDashboardFlag(t *testing.T) {
 	t.Run("Adds context.PublicDashboardAccessToken to request", func(t *testing.T) {
 		ctx := &contextmodel.ReqContext{Context: &web.Context{Req: web.SetURLParams(&http.Request{}, map[string]string{":accessToken": "asdfasdfasdfsadfasdfsfd"})}}
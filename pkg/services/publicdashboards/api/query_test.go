@@ -100,6 +100,8 @@ func TestAPIViewPublicDashboard(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
 			service.On("GetPublicDashboardForView", mock.Anything, mock.AnythingOfType("string")).
 				Return(test.DashboardResult, test.Err).Maybe()
+			service.On("CheckAccessToken", mock.Anything, mock.AnythingOfType("string"), mock.Anything, TokenAccessView).
+				Return(nil).Maybe()
 
 			testServer := setupTestServer(t, nil, service, anonymousUser, true)
 
@@ -190,6 +192,8 @@ func TestAPIQueryPublicDashboard(t *testing.T) {
 
 	setup := func(enabled bool) (*web.Mux, *publicdashboards.FakePublicDashboardService) {
 		service := publicdashboards.NewFakePublicDashboardService(t)
+		service.On("CheckAccessToken", mock.Anything, mock.AnythingOfType("string"), mock.Anything, TokenAccessQuery).
+			Return(nil).Maybe()
 		testServer := setupTestServer(t, nil, service, anonymousUser, true)
 
 		return testServer, service
@@ -413,6 +417,8 @@ func TestAPIGetAnnotations(t *testing.T) {
 			service := publicdashboards.NewFakePublicDashboardService(t)
 
 			if test.ExpectedServiceCalled {
+				service.On("CheckAccessToken", mock.Anything, mock.AnythingOfType("string"), mock.Anything, TokenAccessQuery).
+					Return(nil).Once()
 				service.On("FindAnnotations", mock.Anything, mock.Anything, mock.AnythingOfType("string")).
 					Return(test.Annotations, test.ServiceError).Once()
 			}
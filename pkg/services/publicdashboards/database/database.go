@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -116,29 +117,14 @@ func (d *PublicDashboardStoreImpl) Find(ctx context.Context, uid string) (*Publi
 	return publicDashboard, nil
 }
 
-// FindByAccessToken Returns public dashboard by access token or nil if not found
+// FindByAccessToken Returns public dashboard by access token or nil if not found or the token is revoked or expired
 func (d *PublicDashboardStoreImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
-	if accessToken == "" {
-		return nil, nil
-	}
-
-	var found bool
-	publicDashboard := &PublicDashboard{AccessToken: accessToken}
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
-		var err error
-		found, err = sess.Get(publicDashboard)
-		return err
-	})
-
-	if err != nil {
+	token, err := d.findActiveToken(ctx, accessToken)
+	if err != nil || token == nil {
 		return nil, err
 	}
 
-	if !found {
-		return nil, nil
-	}
-
-	return publicDashboard, nil
+	return d.Find(ctx, token.PublicDashboardUid)
 }
 
 // FindByDashboardUid Retrieves public dashboard by dashboard uid or nil if not found
@@ -187,39 +173,24 @@ func (d *PublicDashboardStoreImpl) ExistsEnabledByDashboardUid(ctx context.Conte
 	return hasPublicDashboard, err
 }
 
-// ExistsEnabledByAccessToken Responds true if the accessToken exists and the public dashboard is enabled
+// ExistsEnabledByAccessToken Responds true if the accessToken is active and the public dashboard is enabled
 func (d *PublicDashboardStoreImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
-	hasPublicDashboard := false
-	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT COUNT(*) FROM dashboard_public WHERE access_token=? AND is_enabled=true"
-
-		result, err := dbSession.SQL(sql, accessToken).Count()
-		if err != nil {
-			return err
-		}
-
-		hasPublicDashboard = result > 0
-		return err
-	})
+	publicDashboard, err := d.FindByAccessToken(ctx, accessToken)
+	if err != nil || publicDashboard == nil {
+		return false, err
+	}
 
-	return hasPublicDashboard, err
+	return publicDashboard.IsEnabled, nil
 }
 
-// GetOrgIdByAccessToken Returns the public dashboard OrgId if exists.
+// GetOrgIdByAccessToken Returns the public dashboard OrgId if the accessToken is active.
 func (d *PublicDashboardStoreImpl) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
-	var orgId int64
-	err := d.sqlStore.WithDbSession(ctx, func(dbSession *db.Session) error {
-		sql := "SELECT org_id FROM dashboard_public WHERE access_token=?"
-
-		_, err := dbSession.SQL(sql, accessToken).Get(&orgId)
-		if err != nil {
-			return err
-		}
-
-		return err
-	})
+	token, err := d.findActiveToken(ctx, accessToken)
+	if err != nil || token == nil {
+		return 0, err
+	}
 
-	return orgId, err
+	return token.OrgId, nil
 }
 
 // Creates a public dashboard
@@ -229,9 +200,27 @@ func (d *PublicDashboardStoreImpl) Create(ctx context.Context, cmd SavePublicDas
 	}
 
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
-		affectedRows, err = sess.UseBool("is_enabled").Insert(&cmd.PublicDashboard)
+		sess.UseBool("is_enabled")
+		if cmd.PublicDashboard.Variables == nil {
+			// keep the column NULL, so it's read back as nil
+			sess.Omit("variables")
+		}
+		affectedRows, err = sess.Insert(&cmd.PublicDashboard)
+		if err != nil {
+			return err
+		}
+
+		// the default token shares the uid and access token of the public dashboard
+		_, err = sess.Insert(&PublicDashboardToken{
+			Uid:                cmd.PublicDashboard.Uid,
+			PublicDashboardUid: cmd.PublicDashboard.Uid,
+			OrgId:              cmd.PublicDashboard.OrgId,
+			AccessToken:        cmd.PublicDashboard.AccessToken,
+			CreatedBy:          cmd.PublicDashboard.CreatedBy,
+			CreatedAt:          cmd.PublicDashboard.CreatedAt,
+		})
 		return err
 	})
 
@@ -247,12 +236,22 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		var variablesJSON any
+		if cmd.PublicDashboard.Variables != nil {
+			data, err := json.Marshal(cmd.PublicDashboard.Variables)
+			if err != nil {
+				return err
+			}
+			variablesJSON = string(data)
+		}
+
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, variables = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			variablesJSON,
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
@@ -273,16 +272,109 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
 
+		_, err = sess.Exec("DELETE FROM dashboard_public_token WHERE public_dashboard_uid = ?", uid)
 		return err
 	})
 
 	return affectedRows, err
 }
 
+// FindToken Returns the token with the given access token, including revoked and expired tokens, or nil if not found
+func (d *PublicDashboardStoreImpl) FindToken(ctx context.Context, accessToken string) (*PublicDashboardToken, error) {
+	if accessToken == "" {
+		return nil, nil
+	}
+
+	var found bool
+	token := &PublicDashboardToken{AccessToken: accessToken}
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(token)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return token, nil
+}
+
+// FindTokens Returns the tokens of a public dashboard, oldest first
+func (d *PublicDashboardStoreImpl) FindTokens(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardToken, error) {
+	tokens := make([]*PublicDashboardToken, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("public_dashboard_uid = ?", publicDashboardUid).Asc("created_at", "uid").Find(&tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// CreateToken Creates an additional token for a public dashboard
+func (d *PublicDashboardStoreImpl) CreateToken(ctx context.Context, token *PublicDashboardToken) (int64, error) {
+	if token.PublicDashboardUid == "" {
+		return 0, ErrPublicDashboardIdentifierNotSet.Errorf("CreateToken: no public dashboard uid")
+	}
+
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Insert(token)
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// RevokeToken Revokes a token of a public dashboard. Revoked tokens are kept for their access log.
+func (d *PublicDashboardStoreImpl) RevokeToken(ctx context.Context, publicDashboardUid string, uid string, revokedAt time.Time) (int64, error) {
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		sqlResult, err := sess.Exec("UPDATE dashboard_public_token SET revoked_at = ? WHERE uid = ? AND public_dashboard_uid = ? AND revoked_at IS NULL",
+			revokedAt.UTC().Format("2006-01-02 15:04:05"), uid, publicDashboardUid)
+		if err != nil {
+			return err
+		}
+
+		affectedRows, err = sqlResult.RowsAffected()
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// RecordTokenAccess Adds views and queries to the counts of a token and sets when it was last seen
+func (d *PublicDashboardStoreImpl) RecordTokenAccess(ctx context.Context, uid string, views int64, queries int64, lastSeenAt time.Time) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE dashboard_public_token SET view_count = view_count + ?, query_count = query_count + ?, last_seen_at = ? WHERE uid = ?",
+			views, queries, lastSeenAt.UTC().Format("2006-01-02 15:04:05"), uid)
+		return err
+	})
+}
+
+func (d *PublicDashboardStoreImpl) findActiveToken(ctx context.Context, accessToken string) (*PublicDashboardToken, error) {
+	token, err := d.FindToken(ctx, accessToken)
+	if err != nil || token == nil || !token.IsActive(time.Now()) {
+		return nil, err
+	}
+
+	return token, nil
+}
+
 func (d *PublicDashboardStoreImpl) FindByFolder(ctx context.Context, orgId int64, folderUid string) ([]*PublicDashboard, error) {
 	var pubdashes []*PublicDashboard
 
@@ -593,6 +593,7 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 			TimeSelectionEnabled: true,
 			Share:                EmailShareType,
 			TimeSettings:         &TimeSettings{From: "now-8", To: "now"},
+			Variables:            &VariableSettings{Values: map[string][]string{"job": {"api"}}},
 			UpdatedAt:            time.Now().UTC().Round(time.Second),
 			UpdatedBy:            8,
 		}
@@ -614,6 +615,7 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.Equal(t, updatedPublicDashboard.AnnotationsEnabled, pdRetrieved.AnnotationsEnabled)
 		assert.Equal(t, updatedPublicDashboard.TimeSelectionEnabled, pdRetrieved.TimeSelectionEnabled)
 		assert.Equal(t, updatedPublicDashboard.Share, pdRetrieved.Share)
+		assert.Equal(t, updatedPublicDashboard.Variables, pdRetrieved.Variables)
 
 		// not updated dashboard shouldn't have changed
 		pdNotUpdatedRetrieved, err := publicdashboardStore.FindByDashboardUid(context.Background(), anotherSavedDashboard.OrgID, anotherSavedDashboard.UID)
@@ -622,6 +624,7 @@ func TestIntegrationUpdatePublicDashboard(t *testing.T) {
 		assert.NotEqual(t, updatedPublicDashboard.IsEnabled, pdNotUpdatedRetrieved.IsEnabled)
 		assert.NotEqual(t, updatedPublicDashboard.AnnotationsEnabled, pdNotUpdatedRetrieved.AnnotationsEnabled)
 		assert.NotEqual(t, updatedPublicDashboard.Share, pdNotUpdatedRetrieved.Share)
+		assert.Nil(t, pdNotUpdatedRetrieved.Variables)
 	})
 }
 
@@ -850,6 +853,129 @@ func TestGetMetrics(t *testing.T) {
 }
 
 // helper function to insert a dashboard
+func TestIntegrationPublicDashboardTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedPublicDashboard *PublicDashboard
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBWithCfg(t)
+		dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
+		require.NoError(t, err)
+		publicdashboardStore = ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+		savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, "", true)
+		savedPublicDashboard = insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, PublicShareType)
+	}
+
+	insertToken := func(t *testing.T, token *PublicDashboardToken) *PublicDashboardToken {
+		t.Helper()
+		accessToken, err := service.GenerateAccessToken()
+		require.NoError(t, err)
+		token.Uid = util.GenerateShortUID()
+		token.PublicDashboardUid = savedPublicDashboard.Uid
+		token.OrgId = savedPublicDashboard.OrgId
+		token.AccessToken = accessToken
+		token.CreatedBy = 1
+		token.CreatedAt = DefaultTime
+		affectedRows, err := publicdashboardStore.CreateToken(context.Background(), token)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, affectedRows)
+		return token
+	}
+
+	t.Run("Create adds a default token with the public dashboard access token", func(t *testing.T) {
+		setup()
+
+		tokens, err := publicdashboardStore.FindTokens(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.Equal(t, savedPublicDashboard.Uid, tokens[0].Uid)
+		assert.Equal(t, savedPublicDashboard.AccessToken, tokens[0].AccessToken)
+		assert.Nil(t, tokens[0].ExpiresAt)
+		assert.Nil(t, tokens[0].AllowedIPs)
+	})
+
+	t.Run("additional tokens resolve to the public dashboard", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(time.Hour)
+		token := insertToken(t, &PublicDashboardToken{Label: "partners", ExpiresAt: &expiresAt, AllowedIPs: IPAllowList{"10.0.0.0/8"}, RateLimit: 60})
+
+		found, err := publicdashboardStore.FindToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "partners", found.Label)
+		assert.Equal(t, IPAllowList{"10.0.0.0/8"}, found.AllowedIPs)
+		assert.EqualValues(t, 60, found.RateLimit)
+		assert.Equal(t, expiresAt, found.ExpiresAt.UTC())
+
+		pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, savedPublicDashboard.Uid, pubdash.Uid)
+
+		orgId, err := publicdashboardStore.GetOrgIdByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, savedPublicDashboard.OrgId, orgId)
+	})
+
+	t.Run("revoked and expired tokens can't be used", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(-time.Hour)
+		expired := insertToken(t, &PublicDashboardToken{ExpiresAt: &expiresAt})
+		revoked := insertToken(t, &PublicDashboardToken{})
+
+		affectedRows, err := publicdashboardStore.RevokeToken(context.Background(), savedPublicDashboard.Uid, revoked.Uid, DefaultTime)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		// revoking twice doesn't change anything
+		affectedRows, err = publicdashboardStore.RevokeToken(context.Background(), savedPublicDashboard.Uid, revoked.Uid, DefaultTime)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, affectedRows)
+
+		for _, token := range []*PublicDashboardToken{expired, revoked} {
+			pubdash, err := publicdashboardStore.FindByAccessToken(context.Background(), token.AccessToken)
+			require.NoError(t, err)
+			assert.Nil(t, pubdash)
+
+			exists, err := publicdashboardStore.ExistsEnabledByAccessToken(context.Background(), token.AccessToken)
+			require.NoError(t, err)
+			assert.False(t, exists)
+		}
+
+		found, err := publicdashboardStore.FindToken(context.Background(), revoked.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found.RevokedAt)
+		assert.Equal(t, DefaultTime, found.RevokedAt.UTC())
+	})
+
+	t.Run("RecordTokenAccess counts views and queries", func(t *testing.T) {
+		setup()
+		ctx := context.Background()
+
+		require.NoError(t, publicdashboardStore.RecordTokenAccess(ctx, savedPublicDashboard.Uid, 1, 0, DefaultTime))
+		require.NoError(t, publicdashboardStore.RecordTokenAccess(ctx, savedPublicDashboard.Uid, 0, 2, DefaultTime.Add(time.Minute)))
+
+		token, err := publicdashboardStore.FindToken(ctx, savedPublicDashboard.AccessToken)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, token.ViewCount)
+		assert.EqualValues(t, 2, token.QueryCount)
+		assert.Equal(t, DefaultTime.Add(time.Minute), token.LastSeenAt.UTC())
+	})
+
+	t.Run("Delete removes the tokens", func(t *testing.T) {
+		setup()
+		insertToken(t, &PublicDashboardToken{})
+
+		_, err := publicdashboardStore.Delete(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+
+		tokens, err := publicdashboardStore.FindTokens(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+}
+
 func insertTestDashboard(t *testing.T, dashboardStore dashboards.Store, title string, orgID int64,
 	folderUID string, isFolder bool, tags ...any) *dashboards.Dashboard {
 	t.Helper()
@@ -6,6 +6,7 @@ var (
 	ErrInternalServerError = errutil.Internal("publicdashboards.internalServerError", errutil.WithPublicMessage("Internal server error"))
 
 	ErrPublicDashboardNotFound = errutil.NotFound("publicdashboards.notFound", errutil.WithPublicMessage("Dashboard not found"))
+	ErrAccessTokenNotFound     = errutil.NotFound("publicdashboards.accessTokenNotFound", errutil.WithPublicMessage("Access token not found"))
 	ErrDashboardNotFound       = errutil.NotFound("publicdashboards.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrPanelNotFound           = errutil.NotFound("publicdashboards.panelNotFound", errutil.WithPublicMessage("Dashboard panel not found"))
 
@@ -19,10 +20,15 @@ var (
 	ErrInvalidInterval                     = errutil.BadRequest("publicdashboards.invalidInterval", errutil.WithPublicMessage("intervalMS should be greater than 0"))
 	ErrInvalidMaxDataPoints                = errutil.BadRequest("publicdashboards.maxDataPoints", errutil.WithPublicMessage("maxDataPoints should be greater than 0"))
 	ErrInvalidTimeRange                    = errutil.BadRequest("publicdashboards.invalidTimeRange", errutil.WithPublicMessage("Invalid time range"))
+	ErrInvalidVariables                    = errutil.BadRequest("publicdashboards.invalidVariables", errutil.WithPublicMessage("Invalid template variable values"))
+	ErrInvalidTokenSettings                = errutil.BadRequest("publicdashboards.invalidTokenSettings", errutil.WithPublicMessage("Invalid access token settings"))
 	ErrInvalidShareType                    = errutil.BadRequest("publicdashboards.invalidShareType", errutil.WithPublicMessage("Invalid share type"))
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Dashboard Access Token already exists"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Dashboard paused"))
+	ErrAccessTokenIPNotAllowed   = errutil.Forbidden("publicdashboards.ipNotAllowed", errutil.WithPublicMessage("Access token can't be used from this address"))
+
+	ErrAccessTokenRateLimited = errutil.TooManyRequests("publicdashboards.rateLimited", errutil.WithPublicMessage("Too many queries, try again later"))
 )
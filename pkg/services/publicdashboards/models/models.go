@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"net"
 	"time"
 
 	"github.com/grafana/grafana/pkg/kinds/dashboard"
@@ -48,21 +49,23 @@ type PublicDashboard struct {
 	CreatedAt    time.Time `json:"createdAt" xorm:"created_at"`
 	UpdatedAt    time.Time `json:"updatedAt" xorm:"updated_at"`
 	//config fields
-	TimeSettings         *TimeSettings `json:"-" xorm:"time_settings"`
-	TimeSelectionEnabled bool          `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
-	IsEnabled            bool          `json:"isEnabled" xorm:"is_enabled"`
-	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
-	Share                ShareType     `json:"share" xorm:"share"`
-	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	TimeSettings         *TimeSettings     `json:"-" xorm:"time_settings"`
+	TimeSelectionEnabled bool              `json:"timeSelectionEnabled" xorm:"time_selection_enabled"`
+	IsEnabled            bool              `json:"isEnabled" xorm:"is_enabled"`
+	AnnotationsEnabled   bool              `json:"annotationsEnabled" xorm:"annotations_enabled"`
+	Share                ShareType         `json:"share" xorm:"share"`
+	Variables            *VariableSettings `json:"variables,omitempty" xorm:"variables"`
+	Recipients           []EmailDTO        `json:"recipients,omitempty" xorm:"-"`
 }
 
 type PublicDashboardDTO struct {
-	Uid                  string    `json:"uid"`
-	AccessToken          string    `json:"accessToken"`
-	TimeSelectionEnabled *bool     `json:"timeSelectionEnabled"`
-	IsEnabled            *bool     `json:"isEnabled"`
-	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
-	Share                ShareType `json:"share"`
+	Uid                  string            `json:"uid"`
+	AccessToken          string            `json:"accessToken"`
+	TimeSelectionEnabled *bool             `json:"timeSelectionEnabled"`
+	IsEnabled            *bool             `json:"isEnabled"`
+	AnnotationsEnabled   *bool             `json:"annotationsEnabled"`
+	Share                ShareType         `json:"share"`
+	Variables            *VariableSettings `json:"variables"`
 }
 
 type EmailDTO struct {
@@ -134,6 +137,28 @@ func (ts *TimeSettings) ToDB() ([]byte, error) {
 	return json.Marshal(ts)
 }
 
+// VariableSettings are the template variable values used by a public dashboard.
+// Viewers can only select values from the allowed values, which keeps them from
+// injecting arbitrary text in queries.
+type VariableSettings struct {
+	// Values replace the values saved with the dashboard, by variable name
+	Values map[string][]string `json:"values,omitempty"`
+	// AllowedValues are the values viewers may select, by variable name. Viewers
+	// can't change variables without allowed values.
+	AllowedValues map[string][]string `json:"allowedValues,omitempty"`
+}
+
+func (vs *VariableSettings) FromDB(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, vs)
+}
+
+func (vs *VariableSettings) ToDB() ([]byte, error) {
+	return json.Marshal(vs)
+}
+
 // DTO for transforming user input in the api
 type SavePublicDashboardDTO struct {
 	Uid             string
@@ -149,11 +174,111 @@ type TimeRangeDTO struct {
 	Timezone string
 }
 
+// PublicDashboardToken is an access token of a public dashboard. Every public
+// dashboard has a default token sharing its uid and AccessToken. Additional
+// tokens can expire, be limited to some addresses and rate limited, and are
+// revoked individually.
+type PublicDashboardToken struct {
+	Uid                string      `json:"uid" xorm:"pk uid"`
+	PublicDashboardUid string      `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	OrgId              int64       `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
+	AccessToken        string      `json:"accessToken" xorm:"access_token"`
+	Label              string      `json:"label" xorm:"label"`
+	ExpiresAt          *time.Time  `json:"expiresAt,omitempty" xorm:"expires_at"`
+	AllowedIPs         IPAllowList `json:"allowedIps,omitempty" xorm:"allowed_ips"`
+	// RateLimit is the number of queries allowed per minute, 0 means unlimited
+	RateLimit int64      `json:"rateLimit" xorm:"rate_limit"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty" xorm:"revoked_at"`
+	CreatedBy int64      `json:"createdBy" xorm:"created_by"`
+	CreatedAt time.Time  `json:"createdAt" xorm:"created_at"`
+	//access log fields
+	ViewCount  int64      `json:"viewCount" xorm:"view_count"`
+	QueryCount int64      `json:"queryCount" xorm:"query_count"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty" xorm:"last_seen_at"`
+}
+
+func (t PublicDashboardToken) TableName() string {
+	return "dashboard_public_token"
+}
+
+// IsActive reports whether the token can be used at the given time.
+func (t *PublicDashboardToken) IsActive(now time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+	return t.ExpiresAt == nil || now.Before(*t.ExpiresAt)
+}
+
+// IsIPAllowed reports whether requests from ip may use the token. Tokens
+// without an allow-list can be used from any address.
+func (t *PublicDashboardToken) IsIPAllowed(ip net.IP) bool {
+	if len(t.AllowedIPs) == 0 {
+		return true
+	}
+	if ip == nil {
+		return false
+	}
+	for _, allowed := range t.AllowedIPs {
+		if _, network, err := net.ParseCIDR(allowed); err == nil {
+			if network.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPAllowList is a list of IP addresses and CIDR ranges
+type IPAllowList []string
+
+func (l *IPAllowList) FromDB(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, l)
+}
+
+func (l IPAllowList) ToDB() ([]byte, error) {
+	return json.Marshal(l)
+}
+
+// TokenAccess is the kind of request made with a public dashboard token
+type TokenAccess string
+
+const (
+	// TokenAccessView is a request for the dashboard
+	TokenAccessView TokenAccess = "view"
+	// TokenAccessQuery is a panel or annotations query, which counts towards the token rate limit
+	TokenAccessQuery TokenAccess = "query"
+)
+
+type PublicDashboardTokenDTO struct {
+	Label      string     `json:"label"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	AllowedIPs []string   `json:"allowedIps"`
+	RateLimit  int64      `json:"rateLimit"`
+}
+
+// DTO for transforming token input in the api
+type SavePublicDashboardTokenDTO struct {
+	PublicDashboardUid string
+	DashboardUid       string
+	OrgID              int64
+	UserId             int64
+	Token              *PublicDashboardTokenDTO
+}
+
 type PublicDashboardQueryDTO struct {
 	IntervalMs      int64
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables are the template variable values selected by the viewer
+	Variables map[string][]string
 }
 
 type AnnotationsQueryDTO struct {
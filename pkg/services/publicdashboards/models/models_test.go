@@ -1,7 +1,9 @@
 package models
 
 import (
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -9,3 +11,25 @@ import (
 func TestPublicDashboardTableName(t *testing.T) {
 	assert.Equal(t, "dashboard_public", PublicDashboard{}.TableName())
 }
+
+func TestPublicDashboardTokenIsActive(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	assert.True(t, (&PublicDashboardToken{}).IsActive(now))
+	assert.True(t, (&PublicDashboardToken{ExpiresAt: &future}).IsActive(now))
+	assert.False(t, (&PublicDashboardToken{ExpiresAt: &past}).IsActive(now))
+	assert.False(t, (&PublicDashboardToken{RevokedAt: &past}).IsActive(now))
+}
+
+func TestPublicDashboardTokenIsIPAllowed(t *testing.T) {
+	token := &PublicDashboardToken{AllowedIPs: IPAllowList{"10.0.0.0/8", "192.168.1.10", "2001:db8::/32"}}
+
+	assert.True(t, token.IsIPAllowed(net.ParseIP("10.20.30.40")))
+	assert.True(t, token.IsIPAllowed(net.ParseIP("192.168.1.10")))
+	assert.True(t, token.IsIPAllowed(net.ParseIP("2001:db8::1")))
+	assert.False(t, token.IsIPAllowed(net.ParseIP("192.168.1.11")))
+	assert.False(t, token.IsIPAllowed(nil))
+
+	assert.True(t, (&PublicDashboardToken{}).IsIPAllowed(net.ParseIP("192.168.1.11")))
+}
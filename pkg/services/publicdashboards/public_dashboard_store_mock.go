@@ -4,6 +4,7 @@ package publicdashboards
 
 import (
 	context "context"
+	time "time"
 
 	models "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	mock "github.com/stretchr/testify/mock"
@@ -38,6 +39,30 @@ func (_m *FakePublicDashboardStore) Create(ctx context.Context, cmd models.SaveP
 	return r0, r1
 }
 
+// CreateToken provides a mock function with given fields: ctx, token
+func (_m *FakePublicDashboardStore) CreateToken(ctx context.Context, token *models.PublicDashboardToken) (int64, error) {
+	ret := _m.Called(ctx, token)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardToken) (int64, error)); ok {
+		return rf(ctx, token)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.PublicDashboardToken) int64); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.PublicDashboardToken) error); ok {
+		r1 = rf(ctx, token)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid
 func (_m *FakePublicDashboardStore) Delete(ctx context.Context, uid string) (int64, error) {
 	ret := _m.Called(ctx, uid)
@@ -240,6 +265,58 @@ func (_m *FakePublicDashboardStore) FindByFolder(ctx context.Context, orgId int6
 	return r0, r1
 }
 
+// FindToken provides a mock function with given fields: ctx, accessToken
+func (_m *FakePublicDashboardStore) FindToken(ctx context.Context, accessToken string) (*models.PublicDashboardToken, error) {
+	ret := _m.Called(ctx, accessToken)
+
+	var r0 *models.PublicDashboardToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*models.PublicDashboardToken, error)); ok {
+		return rf(ctx, accessToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *models.PublicDashboardToken); ok {
+		r0 = rf(ctx, accessToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, accessToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindTokens provides a mock function with given fields: ctx, publicDashboardUid
+func (_m *FakePublicDashboardStore) FindTokens(ctx context.Context, publicDashboardUid string) ([]*models.PublicDashboardToken, error) {
+	ret := _m.Called(ctx, publicDashboardUid)
+
+	var r0 []*models.PublicDashboardToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]*models.PublicDashboardToken, error)); ok {
+		return rf(ctx, publicDashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []*models.PublicDashboardToken); ok {
+		r0 = rf(ctx, publicDashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, publicDashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetMetrics provides a mock function with given fields: ctx
 func (_m *FakePublicDashboardStore) GetMetrics(ctx context.Context) (*models.Metrics, error) {
 	ret := _m.Called(ctx)
@@ -290,6 +367,44 @@ func (_m *FakePublicDashboardStore) GetOrgIdByAccessToken(ctx context.Context, a
 	return r0, r1
 }
 
+// RecordTokenAccess provides a mock function with given fields: ctx, uid, views, queries, lastSeenAt
+func (_m *FakePublicDashboardStore) RecordTokenAccess(ctx context.Context, uid string, views int64, queries int64, lastSeenAt time.Time) error {
+	ret := _m.Called(ctx, uid, views, queries, lastSeenAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int64, time.Time) error); ok {
+		r0 = rf(ctx, uid, views, queries, lastSeenAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeToken provides a mock function with given fields: ctx, publicDashboardUid, uid, revokedAt
+func (_m *FakePublicDashboardStore) RevokeToken(ctx context.Context, publicDashboardUid string, uid string, revokedAt time.Time) (int64, error) {
+	ret := _m.Called(ctx, publicDashboardUid, uid, revokedAt)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (int64, error)); ok {
+		return rf(ctx, publicDashboardUid, uid, revokedAt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) int64); ok {
+		r0 = rf(ctx, publicDashboardUid, uid, revokedAt)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, publicDashboardUid, uid, revokedAt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Update provides a mock function with given fields: ctx, cmd
 func (_m *FakePublicDashboardStore) Update(ctx context.Context, cmd models.SavePublicDashboardCommand) (int64, error) {
 	ret := _m.Called(ctx, cmd)
@@ -2,6 +2,8 @@ package publicdashboards
 
 import (
 	"context"
+	"net"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -38,6 +40,11 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	FindTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*PublicDashboardToken, error)
+	CreateToken(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardTokenDTO) (*PublicDashboardToken, error)
+	RevokeToken(ctx context.Context, orgId int64, dashboardUid string, uid string, tokenUid string) error
+	CheckAccessToken(ctx context.Context, accessToken string, ip net.IP, access TokenAccess) error
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,6 +70,12 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+
+	FindToken(ctx context.Context, accessToken string) (*PublicDashboardToken, error)
+	FindTokens(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardToken, error)
+	CreateToken(ctx context.Context, token *PublicDashboardToken) (int64, error)
+	RevokeToken(ctx context.Context, publicDashboardUid string, uid string, revokedAt time.Time) (int64, error)
+	RecordTokenAccess(ctx context.Context, uid string, views int64, queries int64, lastSeenAt time.Time) error
 }
 
 //go:generate mockery --name Middleware --structname FakePublicDashboardMiddleware --inpackage --filename public_dashboard_middleware_mock.go
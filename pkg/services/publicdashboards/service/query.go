@@ -2,7 +2,10 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
@@ -13,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/dashboards/templatevars"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -156,6 +160,18 @@ func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context,
 
 // buildMetricRequest merges public dashboard parameters with dashboard and returns a metrics request to be sent to query backend
 func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, panelId int64, reqDTO models.PublicDashboardQueryDTO) (dtos.MetricRequest, error) {
+	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
+
+	vars, err := buildVariables(dashboard, publicDashboard, reqDTO, ts)
+	if err != nil {
+		return dtos.MetricRequest{}, err
+	}
+	// Interpolate the panels in place, so data source variables are also resolved
+	// when building the permissions of the anonymous user.
+	if err := vars.InterpolateJSON(dashboard.Data.Get("panels")); err != nil {
+		return dtos.MetricRequest{}, models.ErrInvalidVariables.Errorf("buildMetricRequest: %w", err)
+	}
+
 	// group queries by panel
 	queriesByPanel := groupQueriesByPanelId(dashboard.Data)
 	queries, ok := queriesByPanel[panelId]
@@ -163,8 +179,6 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 		return dtos.MetricRequest{}, models.ErrPanelNotFound.Errorf("buildMetricRequest: public dashboard panel not found")
 	}
 
-	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
-
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
 	for i := range queries {
@@ -180,6 +194,29 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 	}, nil
 }
 
+// buildVariables returns the template variables of the dashboard with the values
+// configured in the public dashboard and selected by the viewer, which must have
+// been validated against the allowed values.
+func buildVariables(dashboard *dashboards.Dashboard, publicDashboard *models.PublicDashboard, reqDTO models.PublicDashboardQueryDTO, ts models.TimeSettings) (templatevars.Variables, error) {
+	vars := templatevars.FromDashboard(dashboard.Data)
+	if publicDashboard.Variables != nil {
+		for name, values := range publicDashboard.Variables.Values {
+			// variables removed from the dashboard after sharing it are ignored
+			if err := vars.Select(name, values); err != nil && !errors.Is(err, templatevars.ErrUnknownVariable) {
+				return nil, models.ErrInvalidVariables.Errorf("buildVariables: %w", err)
+			}
+		}
+	}
+	for name, values := range reqDTO.Variables {
+		if err := vars.Select(name, values); err != nil {
+			return nil, models.ErrInvalidVariables.Errorf("buildVariables: %w", err)
+		}
+	}
+	vars.SetConstant("__from", ts.From)
+	vars.SetConstant("__to", ts.To)
+	return vars, nil
+}
+
 // buildAnonymousUser creates a user with permissions to read from all datasources used in the dashboard
 func buildAnonymousUser(ctx context.Context, dashboard *dashboards.Dashboard, features featuremgmt.FeatureToggles) *user.SignedInUser {
 	datasourceUids := getUniqueDashboardDatasourceUids(dashboard.Data)
@@ -368,6 +405,71 @@ func sanitizeData(data *simplejson.Json) {
 	}
 }
 
+// sanitizeVariables replaces the dashboard variables with custom variables
+// containing only the values viewers may select, so the variable queries and
+// their other values are not exposed. Variables viewers can't change are hidden,
+// the frontend only sends the values of the other variables with queries.
+func sanitizeVariables(data *simplejson.Json, settings *models.VariableSettings) {
+	vars := templatevars.FromDashboard(data)
+	if settings != nil {
+		for name, values := range settings.Values {
+			_ = vars.Select(name, values)
+		}
+	}
+
+	for _, obj := range data.GetPath("templating", "list").MustArray() {
+		variable := simplejson.NewFromAny(obj)
+		v, ok := vars[variable.Get("name").MustString()]
+		if !ok {
+			continue
+		}
+
+		current := v.Current()
+		values := v.Values
+		if v.IsAll() {
+			values = []string{templatevars.AllValue}
+		}
+		hide := variable.Get("hide").MustInt()
+		if settings != nil && len(settings.AllowedValues[v.Name]) > 0 {
+			values = settings.AllowedValues[v.Name]
+		} else {
+			hide = hideVariable
+		}
+
+		options := make([]any, 0, len(values))
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			if value == templatevars.AllValue {
+				continue
+			}
+			options = append(options, map[string]any{"text": value, "value": value, "selected": slices.Contains(v.Values, value)})
+			escaped = append(escaped, strings.ReplaceAll(value, ",", `\,`))
+		}
+
+		sanitized := map[string]any{
+			"name":       v.Name,
+			"type":       "custom",
+			"label":      variable.Get("label").Interface(),
+			"hide":       hide,
+			"multi":      v.Multi,
+			"includeAll": v.IncludeAll && slices.Contains(values, templatevars.AllValue),
+			"query":      strings.Join(escaped, ","),
+			"current":    current,
+			"options":    options,
+		}
+		model := variable.MustMap()
+		for key := range model {
+			delete(model, key)
+		}
+		for key, value := range sanitized {
+			model[key] = value
+		}
+	}
+}
+
+// hideVariable is the hide value of variables without a picker in the dashboard model
+const hideVariable = 2
+
 // NewTimeRange declared to be able to stub this function in tests
 var NewTimeRange = gtime.NewTimeRange
 
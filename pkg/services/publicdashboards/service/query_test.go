@@ -21,6 +21,7 @@ import (
 	. "github.com/grafana/grafana/pkg/services/publicdashboards"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/internal"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/service/intervalv2"
 	"github.com/grafana/grafana/pkg/services/query"
 	"github.com/grafana/grafana/pkg/services/quota/quotatest"
 	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
@@ -1319,6 +1320,105 @@ func TestBuildMetricRequest(t *testing.T) {
 	})
 }
 
+const dashboardWithVariables = `{
+	"time": {"from": "2023-01-01T00:00:00Z", "to": "2023-01-01T01:00:00Z"},
+	"templating": {
+		"list": [
+			{
+				"name": "job",
+				"type": "query",
+				"query": "label_values(up{secret=\"1\"}, job)",
+				"datasource": {"type": "prometheus", "uid": "prom"},
+				"multi": true,
+				"includeAll": true,
+				"current": {"text": ["api"], "value": ["api"]},
+				"options": [{"text": "api", "value": "api"}, {"text": "web", "value": "web"}, {"text": "db", "value": "db"}]
+			},
+			{
+				"name": "ds",
+				"type": "datasource",
+				"query": "prometheus",
+				"current": {"text": "prom", "value": "prom"}
+			}
+		]
+	},
+	"panels": [
+		{
+			"id": 1,
+			"datasource": {"type": "prometheus", "uid": "${ds}"},
+			"targets": [{"refId": "A", "expr": "up{job=~\"$job\"}"}]
+		}
+	]
+}`
+
+func TestBuildMetricRequestWithVariables(t *testing.T) {
+	service := &PublicDashboardServiceImpl{intervalCalculator: intervalv2.NewCalculator()}
+	newDashboard := func() *dashboards.Dashboard {
+		return &dashboards.Dashboard{OrgID: 1, Data: simplejson.MustJson([]byte(dashboardWithVariables))}
+	}
+	pubdash := &PublicDashboard{
+		Variables: &VariableSettings{
+			Values:        map[string][]string{"job": {"web"}},
+			AllowedValues: map[string][]string{"job": {"api", "web"}},
+		},
+	}
+
+	t.Run("uses public dashboard values", func(t *testing.T) {
+		dashboard := newDashboard()
+		reqDTO, err := service.buildMetricRequest(dashboard, pubdash, 1, PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+		require.Len(t, reqDTO.Queries, 1)
+		require.Equal(t, `up{job=~"web"}`, reqDTO.Queries[0].Get("expr").MustString())
+		require.Equal(t, "prom", reqDTO.Queries[0].Get("datasource").Get("uid").MustString())
+
+		// data source variables are resolved for the anonymous user permissions
+		require.Equal(t, []string{"prom"}, getUniqueDashboardDatasourceUids(dashboard.Data))
+	})
+
+	t.Run("uses values selected by the viewer", func(t *testing.T) {
+		reqDTO, err := service.buildMetricRequest(newDashboard(), pubdash, 1, PublicDashboardQueryDTO{
+			Variables: map[string][]string{"job": {"api", "web"}},
+		})
+		require.NoError(t, err)
+		require.Equal(t, `up{job=~"(api|web)"}`, reqDTO.Queries[0].Get("expr").MustString())
+	})
+
+	t.Run("uses dashboard values without public dashboard values", func(t *testing.T) {
+		reqDTO, err := service.buildMetricRequest(newDashboard(), &PublicDashboard{}, 1, PublicDashboardQueryDTO{})
+		require.NoError(t, err)
+		require.Equal(t, `up{job=~"api"}`, reqDTO.Queries[0].Get("expr").MustString())
+	})
+}
+
+func TestSanitizeVariables(t *testing.T) {
+	data := simplejson.MustJson([]byte(dashboardWithVariables))
+	sanitizeVariables(data, &VariableSettings{
+		Values:        map[string][]string{"job": {"web"}},
+		AllowedValues: map[string][]string{"job": {"api", "web"}},
+	})
+
+	out, err := data.Get("templating").Encode()
+	require.NoError(t, err)
+	require.NotContains(t, string(out), "label_values")
+	require.NotContains(t, string(out), "db")
+
+	job := data.GetPath("templating", "list").GetIndex(0)
+	require.Equal(t, "custom", job.Get("type").MustString())
+	require.Equal(t, "api,web", job.Get("query").MustString())
+	require.False(t, job.Get("includeAll").MustBool())
+	require.Equal(t, []string{"web"}, job.GetPath("current", "value").Interface())
+	require.Len(t, job.Get("options").MustArray(), 2)
+	require.Nil(t, job.Get("datasource").Interface())
+	require.Equal(t, 0, job.Get("hide").MustInt())
+
+	// variables viewers can't change have no picker
+	ds := data.GetPath("templating", "list").GetIndex(1)
+	require.Equal(t, "custom", ds.Get("type").MustString())
+	require.Equal(t, "prom", ds.Get("query").MustString())
+	require.Equal(t, "prom", ds.GetPath("current", "value").MustString())
+	require.Equal(t, hideVariable, ds.Get("hide").MustInt())
+}
+
 func TestBuildAnonymousUser(t *testing.T) {
 	sqlStore, cfg := db.InitTestDBWithCfg(t)
 	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore), quotatest.New(false, nil))
@@ -42,6 +42,8 @@ type PublicDashboardServiceImpl struct {
 	serviceWrapper     publicdashboards.ServiceWrapper
 	dashboardService   dashboards.DashboardService
 	license            licensing.Licensing
+	tokenLimiters      tokenRateLimiters
+	tokenAccessLog     tokenAccessLog
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -104,6 +106,7 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
 
 	sanitizeData(dash.Data)
+	sanitizeVariables(dash.Data, pubdash.Variables)
 
 	return &dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}, nil
 }
@@ -197,7 +200,12 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	// ensure dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateVariableSettings(dto.PublicDashboard.Variables, dash.Data)
 	if err != nil {
 		return nil, err
 	}
@@ -254,7 +262,12 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	}
 
 	// validate dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateVariableSettings(dto.PublicDashboard.Variables, dash.Data)
 	if err != nil {
 		return nil, err
 	}
@@ -325,8 +338,9 @@ func (pd *PublicDashboardServiceImpl) NewPublicDashboardAccessToken(ctx context.
 			continue
 		}
 
-		pubdash, _ := pd.store.FindByAccessToken(ctx, accessToken)
-		if pubdash == nil {
+		// revoked and expired tokens are kept, so they can't be reused
+		token, _ := pd.store.FindToken(ctx, accessToken)
+		if token == nil {
 			return accessToken, nil
 		}
 	}
@@ -483,8 +497,8 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 	//Check if accessToken already exists, if none then auto generate
 	accessToken := dto.PublicDashboard.AccessToken
 	if accessToken != "" {
-		existingPubdash, _ := pd.store.FindByAccessToken(ctx, accessToken)
-		if existingPubdash != nil {
+		existingToken, _ := pd.store.FindToken(ctx, accessToken)
+		if existingToken != nil {
 			return nil, ErrPublicDashboardAccessTokenExists.Errorf("Create: public dashboard access token %s already exists", accessToken)
 		}
 	} else {
@@ -514,6 +528,7 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         &TimeSettings{},
 		Share:                share,
+		Variables:            dto.PublicDashboard.Variables,
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
 		UpdatedBy:            dto.UserId,
@@ -533,6 +548,11 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	variables := pubdashDTO.Variables
+	if variables == nil {
+		variables = pd.Variables
+	}
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
@@ -540,6 +560,7 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
 		Share:                share,
+		Variables:            variables,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
 	}
@@ -831,7 +831,7 @@ func TestCreatePublicDashboard(t *testing.T) {
 
 		publicDashboardStore := &FakePublicDashboardStore{}
 		publicDashboardStore.On("Find", mock.Anything, mock.Anything).Return(nil, nil)
-		publicDashboardStore.On("FindByAccessToken", mock.Anything, "ExistingAccessToken").Return(pubdash, nil)
+		publicDashboardStore.On("FindToken", mock.Anything, "ExistingAccessToken").Return(&PublicDashboardToken{Uid: pubdash.Uid, AccessToken: pubdash.AccessToken}, nil)
 		publicDashboardStore.On("FindByDashboardUid", mock.Anything, mock.Anything, mock.Anything).Return(nil, ErrPublicDashboardNotFound.Errorf(""))
 		fakeDashboardService := &dashboards.FakeDashboardService{}
 		fakeDashboardService.On("GetDashboard", mock.Anything, mock.Anything, mock.Anything).Return(dashboard, nil)
@@ -894,7 +894,7 @@ func TestCreatePublicDashboard(t *testing.T) {
 		}
 
 		publicDashboardStore := &FakePublicDashboardStore{}
-		publicDashboardStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(pubdash, nil)
+		publicDashboardStore.On("FindToken", mock.Anything, mock.Anything).Return(&PublicDashboardToken{PublicDashboardUid: pubdash.Uid}, nil)
 		service, _, _ := newPublicDashboardServiceImpl(t, publicDashboardStore, nil, nil)
 
 		_, err := service.NewPublicDashboardAccessToken(context.Background())
@@ -1567,12 +1567,10 @@ func TestPublicDashboardServiceImpl_NewPublicDashboardUid(t *testing.T) {
 }
 
 func TestPublicDashboardServiceImpl_NewPublicDashboardAccessToken(t *testing.T) {
-	mockedDashboard := &PublicDashboard{
-		IsEnabled:          true,
-		AnnotationsEnabled: false,
-		DashboardUid:       "NOTTHESAME",
+	mockedToken := &PublicDashboardToken{
+		Uid:                "NOTTHESAME",
+		PublicDashboardUid: "NOTTHESAME",
 		OrgId:              9999999,
-		TimeSettings:       timeSettings,
 	}
 
 	type args struct {
@@ -1580,8 +1578,8 @@ func TestPublicDashboardServiceImpl_NewPublicDashboardAccessToken(t *testing.T)
 	}
 
 	type mockResponse struct {
-		PublicDashboard *PublicDashboard
-		Err             error
+		Token *PublicDashboardToken
+		Err   error
 	}
 	tests := []struct {
 		name      string
@@ -1600,7 +1598,7 @@ func TestPublicDashboardServiceImpl_NewPublicDashboardAccessToken(t *testing.T)
 		{
 			name:      "should return an error if the generated access token exists 3 times",
 			args:      args{ctx: context.Background()},
-			mockStore: &mockResponse{mockedDashboard, nil},
+			mockStore: &mockResponse{mockedToken, nil},
 			want:      "",
 			wantErr:   assert.Error,
 		},
@@ -1608,8 +1606,8 @@ func TestPublicDashboardServiceImpl_NewPublicDashboardAccessToken(t *testing.T)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			store := NewFakePublicDashboardStore(t)
-			store.On("FindByAccessToken", mock.Anything, mock.Anything).
-				Return(tt.mockStore.PublicDashboard, tt.mockStore.Err)
+			store.On("FindToken", mock.Anything, mock.Anything).
+				Return(tt.mockStore.Token, tt.mockStore.Err)
 
 			pd := &PublicDashboardServiceImpl{store: store}
 
@@ -1621,9 +1619,9 @@ func TestPublicDashboardServiceImpl_NewPublicDashboardAccessToken(t *testing.T)
 			if err == nil {
 				assert.NotEqual(t, got, tt.want, "NewPublicDashboardAccessToken(%v)", tt.args.ctx)
 				assert.True(t, validation.IsValidAccessToken(got), "NewPublicDashboardAccessToken(%v)", tt.args.ctx)
-				store.AssertNumberOfCalls(t, "FindByAccessToken", 1)
+				store.AssertNumberOfCalls(t, "FindToken", 1)
 			} else {
-				store.AssertNumberOfCalls(t, "FindByAccessToken", 3)
+				store.AssertNumberOfCalls(t, "FindToken", 3)
 				assert.True(t, ErrInternalServerError.Is(err))
 			}
 		})
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// FindTokens returns the access tokens of a public dashboard with their access log
+func (pd *PublicDashboardServiceImpl) FindTokens(ctx context.Context, orgId int64, dashboardUid string, uid string) ([]*PublicDashboardToken, error) {
+	if _, err := pd.findPublicDashboardOfDashboard(ctx, orgId, dashboardUid, uid); err != nil {
+		return nil, err
+	}
+
+	tokens, err := pd.store.FindTokens(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindTokens: failed to find tokens of public dashboard %s: %w", uid, err)
+	}
+
+	return tokens, nil
+}
+
+// CreateToken creates an additional access token for a public dashboard
+func (pd *PublicDashboardServiceImpl) CreateToken(ctx context.Context, u *user.SignedInUser, dto *SavePublicDashboardTokenDTO) (*PublicDashboardToken, error) {
+	now := time.Now()
+	if err := validation.ValidatePublicDashboardToken(dto.Token, now); err != nil {
+		return nil, err
+	}
+
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, u.OrgID, dto.DashboardUid, dto.PublicDashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &PublicDashboardToken{
+		Uid:                util.GenerateShortUID(),
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		AccessToken:        accessToken,
+		Label:              dto.Token.Label,
+		ExpiresAt:          dto.Token.ExpiresAt,
+		AllowedIPs:         dto.Token.AllowedIPs,
+		RateLimit:          dto.Token.RateLimit,
+		CreatedBy:          dto.UserId,
+		CreatedAt:          now,
+	}
+
+	affectedRows, err := pd.store.CreateToken(ctx, token)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateToken: failed to create token for public dashboard %s: %w", pubdash.Uid, err)
+	} else if affectedRows == 0 {
+		return nil, ErrInternalServerError.Errorf("CreateToken: failed to create a database entry for a token of public dashboard %s. 0 rows changed, no error reported.", pubdash.Uid)
+	}
+
+	pd.log.Info("Public dashboard access token created", "publicDashboardUid", pubdash.Uid, "tokenUid", token.Uid, "user", u.Login)
+
+	return token, nil
+}
+
+// RevokeToken revokes an access token of a public dashboard. The token is kept
+// with its access log and can't be used anymore.
+func (pd *PublicDashboardServiceImpl) RevokeToken(ctx context.Context, orgId int64, dashboardUid string, uid string, tokenUid string) error {
+	if _, err := pd.findPublicDashboardOfDashboard(ctx, orgId, dashboardUid, uid); err != nil {
+		return err
+	}
+
+	affectedRows, err := pd.store.RevokeToken(ctx, uid, tokenUid, time.Now())
+	if err != nil {
+		return ErrInternalServerError.Errorf("RevokeToken: failed to revoke token %s: %w", tokenUid, err)
+	}
+
+	if affectedRows == 0 {
+		return ErrAccessTokenNotFound.Errorf("RevokeToken: token %s not found or already revoked", tokenUid)
+	}
+
+	pd.tokenLimiters.remove(tokenUid)
+	pd.tokenAccessLog.remove(tokenUid)
+	pd.log.Info("Public dashboard access token revoked", "publicDashboardUid", uid, "tokenUid", tokenUid)
+
+	return nil
+}
+
+// CheckAccessToken enforces the IP allow-list and rate limit of an access token
+// and records its use in the token access log. The access log is written to the
+// database by Run.
+func (pd *PublicDashboardServiceImpl) CheckAccessToken(ctx context.Context, accessToken string, ip net.IP, access TokenAccess) error {
+	token, err := pd.store.FindToken(ctx, accessToken)
+	if err != nil {
+		return ErrInternalServerError.Errorf("CheckAccessToken: failed to find access token: %w", err)
+	}
+
+	now := time.Now()
+	if token == nil || !token.IsActive(now) {
+		return ErrPublicDashboardNotFound.Errorf("CheckAccessToken: access token not found, revoked or expired")
+	}
+
+	if !token.IsIPAllowed(ip) {
+		return ErrAccessTokenIPNotAllowed.Errorf("CheckAccessToken: address %s is not allowed to use token %s", ip, token.Uid)
+	}
+
+	if access == TokenAccessQuery && !pd.tokenLimiters.allow(token, now) {
+		return ErrAccessTokenRateLimited.Errorf("CheckAccessToken: token %s exceeded %d queries per minute", token.Uid, token.RateLimit)
+	}
+
+	pd.tokenAccessLog.record(token.Uid, access, now)
+
+	return nil
+}
+
+// Run writes the token access log to the database every flush interval, and
+// once more on shutdown.
+func (pd *PublicDashboardServiceImpl) Run(ctx context.Context) error {
+	ticker := time.NewTicker(tokenAccessFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			pd.flushTokenAccess(ctx)
+		case <-ctx.Done():
+			pd.flushTokenAccess(context.Background())
+			return ctx.Err()
+		}
+	}
+}
+
+func (pd *PublicDashboardServiceImpl) flushTokenAccess(ctx context.Context) {
+	for tokenUid, pending := range pd.tokenAccessLog.flush() {
+		if err := pd.store.RecordTokenAccess(ctx, tokenUid, pending.views, pending.queries, pending.lastSeenAt); err != nil {
+			// the access log must not break public dashboards
+			pd.log.Warn("Failed to record public dashboard token access", "tokenUid", tokenUid, "error", err)
+		}
+	}
+}
+
+// findPublicDashboardOfDashboard returns the public dashboard with uid if it belongs to the dashboard
+func (pd *PublicDashboardServiceImpl) findPublicDashboardOfDashboard(ctx context.Context, orgId int64, dashboardUid string, uid string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("findPublicDashboardOfDashboard: failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+
+	if pubdash == nil || pubdash.OrgId != orgId {
+		return nil, ErrPublicDashboardNotFound.Errorf("findPublicDashboardOfDashboard: public dashboard not found by uid: %s", uid)
+	}
+
+	if pubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("findPublicDashboardOfDashboard: the public dashboard does not belong to the dashboard")
+	}
+
+	return pubdash, nil
+}
+
+// tokenAccessFlushInterval is how often the access log of a token is written
+// to the database, so busy tokens don't cause a write for every view and query.
+const tokenAccessFlushInterval = time.Minute
+
+// tokenAccessLog counts the views and queries of access tokens in memory until
+// they are written to the database.
+type tokenAccessLog struct {
+	mu      sync.Mutex
+	pending map[string]*pendingTokenAccess
+}
+
+type pendingTokenAccess struct {
+	views      int64
+	queries    int64
+	lastSeenAt time.Time
+}
+
+// record counts an access of a token.
+func (l *tokenAccessLog) record(tokenUid string, access TokenAccess, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.pending == nil {
+		l.pending = map[string]*pendingTokenAccess{}
+	}
+
+	pending, ok := l.pending[tokenUid]
+	if !ok {
+		pending = &pendingTokenAccess{}
+		l.pending[tokenUid] = pending
+	}
+
+	if access == TokenAccessQuery {
+		pending.queries++
+	} else {
+		pending.views++
+	}
+	pending.lastSeenAt = now
+}
+
+// flush returns the counted accesses of all tokens and removes them from the log.
+func (l *tokenAccessLog) flush() map[string]*pendingTokenAccess {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	flushed := l.pending
+	l.pending = nil
+	return flushed
+}
+
+func (l *tokenAccessLog) remove(tokenUid string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.pending, tokenUid)
+}
+
+// tokenRateLimiters keeps a query rate limiter per access token. Limits are
+// enforced per Grafana instance.
+type tokenRateLimiters struct {
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func (l *tokenRateLimiters) allow(token *PublicDashboardToken, now time.Time) bool {
+	if token.RateLimit <= 0 {
+		return true
+	}
+
+	limit := rate.Limit(float64(token.RateLimit) / time.Minute.Seconds())
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limiters == nil {
+		l.limiters = map[string]*rate.Limiter{}
+	}
+
+	limiter, ok := l.limiters[token.Uid]
+	if !ok || limiter.Limit() != limit {
+		limiter = rate.NewLimiter(limit, int(token.RateLimit))
+		l.limiters[token.Uid] = limiter
+	}
+
+	return limiter.AllowN(now, 1)
+}
+
+func (l *tokenRateLimiters) remove(tokenUid string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.limiters, tokenUid)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+func TestCheckAccessToken(t *testing.T) {
+	ctx := context.Background()
+	clientIP := net.ParseIP("10.1.2.3")
+
+	t.Run("records the access of an active token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindToken", mock.Anything, "token").Return(&PublicDashboardToken{Uid: "tokenUid"}, nil)
+		store.On("RecordTokenAccess", mock.Anything, "tokenUid", int64(2), int64(1), mock.AnythingOfType("time.Time")).Return(nil).Once()
+		pd := &PublicDashboardServiceImpl{store: store}
+
+		// accesses are only counted in memory until the access log is flushed
+		require.NoError(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessView))
+		require.NoError(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessView))
+		require.NoError(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessQuery))
+
+		pd.flushTokenAccess(ctx)
+		// flushed counts are removed from the access log
+		pd.flushTokenAccess(ctx)
+	})
+
+	t.Run("returns not found for revoked and expired tokens", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		for _, token := range []*PublicDashboardToken{nil, {Uid: "revoked", RevokedAt: &past}, {Uid: "expired", ExpiresAt: &past}} {
+			store := NewFakePublicDashboardStore(t)
+			store.On("FindToken", mock.Anything, "token").Return(token, nil)
+			pd := &PublicDashboardServiceImpl{store: store}
+
+			err := pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessView)
+			assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+		}
+	})
+
+	t.Run("rejects addresses outside the allow-list", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindToken", mock.Anything, "token").Return(&PublicDashboardToken{Uid: "tokenUid", AllowedIPs: IPAllowList{"192.168.0.0/16"}}, nil)
+		pd := &PublicDashboardServiceImpl{store: store}
+
+		err := pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessView)
+		assert.ErrorIs(t, err, ErrAccessTokenIPNotAllowed)
+	})
+
+	t.Run("rate limits queries", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("FindToken", mock.Anything, "token").Return(&PublicDashboardToken{Uid: "tokenUid", RateLimit: 2}, nil)
+		pd := &PublicDashboardServiceImpl{store: store}
+
+		require.NoError(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessQuery))
+		require.NoError(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessQuery))
+		assert.ErrorIs(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessQuery), ErrAccessTokenRateLimited)
+
+		// views don't count towards the limit
+		require.NoError(t, pd.CheckAccessToken(ctx, "token", clientIP, TokenAccessView))
+	})
+}
+
+func TestTokenAccessLog(t *testing.T) {
+	var accessLog tokenAccessLog
+	now := time.Now()
+
+	accessLog.record("a", TokenAccessView, now)
+	accessLog.record("a", TokenAccessQuery, now.Add(time.Second))
+	accessLog.record("a", TokenAccessQuery, now.Add(2*time.Second))
+	accessLog.record("b", TokenAccessQuery, now)
+	accessLog.record("c", TokenAccessView, now)
+	accessLog.remove("c")
+
+	assert.Equal(t, map[string]*pendingTokenAccess{
+		"a": {views: 1, queries: 2, lastSeenAt: now.Add(2 * time.Second)},
+		"b": {queries: 1, lastSeenAt: now},
+	}, accessLog.flush())
+	assert.Empty(t, accessLog.flush())
+}
+
+func TestRunFlushesTokenAccessOnShutdown(t *testing.T) {
+	store := NewFakePublicDashboardStore(t)
+	store.On("RecordTokenAccess", mock.Anything, "tokenUid", int64(1), int64(0), mock.AnythingOfType("time.Time")).Return(nil).Once()
+	pd := &PublicDashboardServiceImpl{store: store}
+	pd.tokenAccessLog.record("tokenUid", TokenAccessView, time.Now())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, pd.Run(ctx), context.Canceled)
+}
+
+func TestCreateToken(t *testing.T) {
+	ctx := context.Background()
+	signedInUser := &user.SignedInUser{OrgID: 1, UserID: 7}
+	pubdash := &PublicDashboard{Uid: "pubdashUid", DashboardUid: "dashUid", OrgId: 1}
+
+	t.Run("creates a token with a new access token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdashUid").Return(pubdash, nil)
+		store.On("FindToken", mock.Anything, mock.Anything).Return(nil, nil)
+		store.On("CreateToken", mock.Anything, mock.AnythingOfType("*models.PublicDashboardToken")).Return(int64(1), nil)
+		pd := &PublicDashboardServiceImpl{store: store, log: log.New("test.logger")}
+
+		expiresAt := time.Now().Add(time.Hour)
+		token, err := pd.CreateToken(ctx, signedInUser, &SavePublicDashboardTokenDTO{
+			PublicDashboardUid: "pubdashUid",
+			DashboardUid:       "dashUid",
+			OrgID:              1,
+			UserId:             7,
+			Token:              &PublicDashboardTokenDTO{Label: "partners", ExpiresAt: &expiresAt, AllowedIPs: []string{"10.0.0.1"}, RateLimit: 10},
+		})
+		require.NoError(t, err)
+
+		assert.NotEmpty(t, token.Uid)
+		assert.Len(t, token.AccessToken, 32)
+		assert.Equal(t, "pubdashUid", token.PublicDashboardUid)
+		assert.Equal(t, IPAllowList{"10.0.0.1"}, token.AllowedIPs)
+		assert.EqualValues(t, 7, token.CreatedBy)
+	})
+
+	t.Run("validates the token settings", func(t *testing.T) {
+		pd := &PublicDashboardServiceImpl{store: NewFakePublicDashboardStore(t)}
+
+		_, err := pd.CreateToken(ctx, signedInUser, &SavePublicDashboardTokenDTO{
+			PublicDashboardUid: "pubdashUid",
+			DashboardUid:       "dashUid",
+			Token:              &PublicDashboardTokenDTO{AllowedIPs: []string{"not an ip"}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidTokenSettings)
+	})
+
+	t.Run("fails when the public dashboard belongs to another dashboard", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdashUid").Return(pubdash, nil)
+		pd := &PublicDashboardServiceImpl{store: store}
+
+		_, err := pd.CreateToken(ctx, signedInUser, &SavePublicDashboardTokenDTO{
+			PublicDashboardUid: "pubdashUid",
+			DashboardUid:       "otherDashUid",
+			Token:              &PublicDashboardTokenDTO{},
+		})
+		assert.ErrorIs(t, err, ErrInvalidUid)
+	})
+}
+
+func TestRevokeToken(t *testing.T) {
+	ctx := context.Background()
+	pubdash := &PublicDashboard{Uid: "pubdashUid", DashboardUid: "dashUid", OrgId: 1}
+
+	t.Run("revokes the token", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdashUid").Return(pubdash, nil)
+		store.On("RevokeToken", mock.Anything, "pubdashUid", "tokenUid", mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+		pd := &PublicDashboardServiceImpl{store: store, log: log.New("test.logger")}
+
+		require.NoError(t, pd.RevokeToken(ctx, 1, "dashUid", "pubdashUid", "tokenUid"))
+	})
+
+	t.Run("returns not found when the token is unknown or already revoked", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdashUid").Return(pubdash, nil)
+		store.On("RevokeToken", mock.Anything, "pubdashUid", "tokenUid", mock.AnythingOfType("time.Time")).Return(int64(0), nil)
+		pd := &PublicDashboardServiceImpl{store: store}
+
+		assert.ErrorIs(t, pd.RevokeToken(ctx, 1, "dashUid", "pubdashUid", "tokenUid"), ErrAccessTokenNotFound)
+	})
+
+	t.Run("returns not found for public dashboards of other orgs", func(t *testing.T) {
+		store := NewFakePublicDashboardStore(t)
+		store.On("Find", mock.Anything, "pubdashUid").Return(pubdash, nil)
+		pd := &PublicDashboardServiceImpl{store: store}
+
+		assert.ErrorIs(t, pd.RevokeToken(ctx, 2, "dashUid", "pubdashUid", "tokenUid"), ErrPublicDashboardNotFound)
+	})
+}
@@ -1,8 +1,14 @@
 package validation
 
 import (
+	"net"
+	"slices"
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/dashboards/templatevars"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -16,6 +22,94 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 	return nil
 }
 
+// ValidateVariableSettings checks the variable values of a public dashboard
+// against the template variables of the dashboard.
+func ValidateVariableSettings(settings *VariableSettings, dashboard *simplejson.Json) error {
+	if settings == nil {
+		return nil
+	}
+
+	vars := templatevars.FromDashboard(dashboard)
+	for name, values := range settings.Values {
+		if err := vars.Select(name, values); err != nil {
+			return ErrInvalidVariables.Errorf("ValidateVariableSettings: %w", err)
+		}
+		if vars[name].IsAll() {
+			if err := validateAll(vars[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, allowed := range settings.AllowedValues {
+		v, ok := vars[name]
+		if !ok {
+			return ErrInvalidVariables.Errorf("ValidateVariableSettings: %w: %s", templatevars.ErrUnknownVariable, name)
+		}
+		if slices.Contains(allowed, templatevars.AllValue) {
+			if err := validateAll(v); err != nil {
+				return err
+			}
+		}
+
+		// the values viewers start with must be allowed too
+		current := v.Values
+		if v.IsAll() {
+			current = []string{templatevars.AllValue}
+		}
+		for _, value := range current {
+			if !slices.Contains(allowed, value) {
+				return ErrInvalidVariables.Errorf("ValidateVariableSettings: value %q of %s is not allowed", value, name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateAll checks that all values of a variable can be expanded on the server,
+// which doesn't run variable queries.
+func validateAll(v *templatevars.Variable) error {
+	if !v.IncludeAll {
+		return ErrInvalidVariables.Errorf("ValidateVariableSettings: %s does not include all", v.Name)
+	}
+	if v.CustomAllValue == "" && len(v.Options) == 0 {
+		return ErrInvalidVariables.Errorf("ValidateVariableSettings: %s has neither a custom all value nor saved options", v.Name)
+	}
+	return nil
+}
+
+// ValidatePublicDashboardToken checks the settings of an additional access token
+func ValidatePublicDashboardToken(dto *PublicDashboardTokenDTO, now time.Time) error {
+	if len(dto.Label) > 255 {
+		return ErrInvalidTokenSettings.Errorf("ValidatePublicDashboardToken: label is longer than 255 characters")
+	}
+
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(now) {
+		return ErrInvalidTokenSettings.Errorf("ValidatePublicDashboardToken: expiry time is in the past")
+	}
+
+	if dto.RateLimit < 0 {
+		return ErrInvalidTokenSettings.Errorf("ValidatePublicDashboardToken: rate limit should not be negative")
+	}
+
+	for _, allowed := range dto.AllowedIPs {
+		if !IsValidIPOrCIDR(allowed) {
+			return ErrInvalidTokenSettings.Errorf("ValidatePublicDashboardToken: invalid IP address or range %q", allowed)
+		}
+	}
+
+	return nil
+}
+
+// IsValidIPOrCIDR reports whether s is an IP address or a CIDR range
+func IsValidIPOrCIDR(s string) bool {
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return true
+	}
+	return net.ParseIP(s) != nil
+}
+
 func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *PublicDashboard) error {
 	if req.IntervalMs < 0 {
 		return ErrInvalidInterval.Errorf("ValidateQueryPublicDashboardRequest: intervalMS should be greater than 0")
@@ -38,6 +132,22 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 		}
 	}
 
+	// viewers can only select allowed values
+	for name, values := range req.Variables {
+		var allowed []string
+		if pd.Variables != nil {
+			allowed = pd.Variables.AllowedValues[name]
+		}
+		if len(allowed) == 0 {
+			return ErrInvalidVariables.Errorf("ValidateQueryPublicDashboardRequest: variable %s can't be changed", name)
+		}
+		for _, value := range values {
+			if !slices.Contains(allowed, value) {
+				return ErrInvalidVariables.Errorf("ValidateQueryPublicDashboardRequest: value %q of %s is not allowed", value, name)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -1,8 +1,11 @@
 package validation
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -129,6 +132,42 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns no error when variable values are allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"job": {"api", "web"}},
+				},
+				pd: &PublicDashboard{
+					Variables: &VariableSettings{AllowedValues: map[string][]string{"job": {"api", "web"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when variable value is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"job": {"api", `"} or vector(1)`}},
+				},
+				pd: &PublicDashboard{
+					Variables: &VariableSettings{AllowedValues: map[string][]string{"job": {"api", "web"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns validation error when variable has no allowed values",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"env": {"prod"}},
+				},
+				pd: &PublicDashboard{
+					Variables: &VariableSettings{AllowedValues: map[string][]string{"job": {"api", "web"}}},
+				},
+			},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -139,6 +178,106 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 	}
 }
 
+func TestValidateVariableSettings(t *testing.T) {
+	dashboard := simplejson.MustJson([]byte(`{
+		"templating": {
+			"list": [
+				{"name": "job", "type": "query", "multi": true, "includeAll": true, "current": {"value": ["api"]}, "options": [{"value": "api"}, {"value": "web"}]},
+				{"name": "env", "type": "custom", "current": {"value": "prod"}},
+				{"name": "instance", "type": "query", "includeAll": true, "current": {"value": "a"}},
+				{"name": "pod", "type": "query", "includeAll": true, "allValue": ".*", "current": {"value": "a"}}
+			]
+		}
+	}`))
+
+	tests := []struct {
+		name     string
+		settings *VariableSettings
+		wantErr  bool
+	}{
+		{name: "no settings", settings: nil},
+		{
+			name: "valid values and allowed values",
+			settings: &VariableSettings{
+				Values:        map[string][]string{"job": {"web"}},
+				AllowedValues: map[string][]string{"job": {"api", "web", "$__all"}},
+			},
+		},
+		{
+			name:     "unknown variable",
+			settings: &VariableSettings{Values: map[string][]string{"missing": {"x"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "multiple values for single value variable",
+			settings: &VariableSettings{Values: map[string][]string{"env": {"prod", "dev"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "all is not included",
+			settings: &VariableSettings{AllowedValues: map[string][]string{"env": {"prod", "$__all"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "all without options",
+			settings: &VariableSettings{AllowedValues: map[string][]string{"instance": {"a", "$__all"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "all without options selected",
+			settings: &VariableSettings{Values: map[string][]string{"instance": {"$__all"}}},
+			wantErr:  true,
+		},
+		{
+			name:     "all with custom all value",
+			settings: &VariableSettings{AllowedValues: map[string][]string{"pod": {"a", "$__all"}}},
+		},
+		{
+			name:     "current value is not allowed",
+			settings: &VariableSettings{AllowedValues: map[string][]string{"env": {"dev"}}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateVariableSettings(tt.settings, dashboard)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidVariables)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestValidatePublicDashboardToken(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Hour)
+
+	testCases := []struct {
+		name  string
+		dto   PublicDashboardTokenDTO
+		valid bool
+	}{
+		{name: "empty settings", dto: PublicDashboardTokenDTO{}, valid: true},
+		{name: "all settings", dto: PublicDashboardTokenDTO{Label: "partners", ExpiresAt: &future, AllowedIPs: []string{"10.0.0.1", "10.1.0.0/16", "::1"}, RateLimit: 60}, valid: true},
+		{name: "expiry in the past", dto: PublicDashboardTokenDTO{ExpiresAt: &past}},
+		{name: "negative rate limit", dto: PublicDashboardTokenDTO{RateLimit: -1}},
+		{name: "invalid address", dto: PublicDashboardTokenDTO{AllowedIPs: []string{"10.0.0"}}},
+		{name: "long label", dto: PublicDashboardTokenDTO{Label: strings.Repeat("a", 256)}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidatePublicDashboardToken(&tc.dto, now)
+			if tc.valid {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, ErrInvalidTokenSettings)
+			}
+		})
+	}
+}
+
 func TestValidAccessToken(t *testing.T) {
 	t.Run("true", func(t *testing.T) {
 		uuid := "da82510c2aa64d78a2e87fef36c58e89"
@@ -0,0 +1,48 @@
+package queryrecording
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+)
+
+// ReplayGetter loads the recordings replayed by the TestData data source. Users only get the
+// recordings of their organization whose data source they can query.
+type ReplayGetter struct {
+	store *Store
+	ac    accesscontrol.AccessControl
+}
+
+var _ recordings.Getter = (*ReplayGetter)(nil)
+
+func ProvideReplayGetter(store *Store, ac accesscontrol.AccessControl) *ReplayGetter {
+	return &ReplayGetter{store: store, ac: ac}
+}
+
+func (g *ReplayGetter) GetRecording(ctx context.Context, user identity.Requester, name string) (*recordings.Recording, error) {
+	recording, err := g.store.GetRecording(ctx, user.GetOrgID(), name)
+	if err != nil {
+		return nil, err
+	}
+	ok, err := CanAccess(ctx, g.ac, user, datasources.ActionQuery, recording.DatasourceUID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, recordings.ErrNotFound
+	}
+	return recording, nil
+}
+
+// CanAccess evaluates the data source action for the data source of a recording. Recordings
+// without a data source require the action on every data source.
+func CanAccess(ctx context.Context, ac accesscontrol.AccessControl, user identity.Requester, action string, datasourceUID string) (bool, error) {
+	scope := datasources.ScopeProvider.GetResourceAllScope()
+	if datasourceUID != "" {
+		scope = datasources.ScopeProvider.GetResourceScopeUID(datasourceUID)
+	}
+	return ac.Evaluate(ctx, user, accesscontrol.EvalPermission(action, scope))
+}
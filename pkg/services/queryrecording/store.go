@@ -0,0 +1,133 @@
+package queryrecording
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+)
+
+const (
+	rootFolder        = "/query-recordings/"
+	recordingMimeType = "application/json"
+	recordingFileExt  = ".json"
+
+	// datasourceUIDProperty keeps the data source of a recording with the file, so recordings can
+	// be listed by data source without loading their responses.
+	datasourceUIDProperty = "datasourceUid"
+)
+
+var (
+	ErrInvalidName     = errors.New("recording names may only contain letters, numbers, '-', '_' and '.'")
+	ErrRecordingExists = errors.New("a recording with the same name already exists")
+	validName          = regexp.MustCompile(`^[A-Za-z0-9\-_.]{1,128}$`)
+)
+
+// Store keeps recorded query responses in the database file storage.
+type Store struct {
+	fs filestorage.FileStorage
+}
+
+func ProvideStore(sqlStore db.DB) *Store {
+	return &Store{
+		fs: filestorage.NewDbStorage(log.New("query-recordings"), sqlStore, nil, rootFolder),
+	}
+}
+
+func recordingPath(orgID int64, name string) string {
+	return filestorage.Join(fmt.Sprintf("%d", orgID), name+recordingFileExt)
+}
+
+// Info describes a stored recording without its response.
+type Info struct {
+	Name          string
+	DatasourceUID string
+}
+
+// Save stores a new recording, existing recordings are never replaced and have to be deleted
+// first.
+func (s *Store) Save(ctx context.Context, orgID int64, recording *recordings.Recording) error {
+	if !validName.MatchString(recording.Name) {
+		return ErrInvalidName
+	}
+	path := recordingPath(orgID, recording.Name)
+	_, found, err := s.fs.Get(ctx, path, &filestorage.GetFileOptions{WithContents: false})
+	if err != nil {
+		return err
+	}
+	if found {
+		return ErrRecordingExists
+	}
+
+	contents, err := json.Marshal(recording)
+	if err != nil {
+		return err
+	}
+	return s.fs.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:       path,
+		MimeType:   recordingMimeType,
+		Contents:   contents,
+		Properties: map[string]string{datasourceUIDProperty: recording.DatasourceUID},
+	})
+}
+
+func (s *Store) GetRecording(ctx context.Context, orgID int64, name string) (*recordings.Recording, error) {
+	if !validName.MatchString(name) {
+		return nil, recordings.ErrNotFound
+	}
+	file, found, err := s.fs.Get(ctx, recordingPath(orgID, name), &filestorage.GetFileOptions{WithContents: true})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, recordings.ErrNotFound
+	}
+
+	recording := &recordings.Recording{}
+	if err := json.Unmarshal(file.Contents, recording); err != nil {
+		return nil, fmt.Errorf("invalid recording %s: %w", name, err)
+	}
+	return recording, nil
+}
+
+// List returns the recordings of the organization ordered by name.
+func (s *Store) List(ctx context.Context, orgID int64) ([]Info, error) {
+	infos := make([]Info, 0)
+	paging := &filestorage.Paging{}
+	for {
+		resp, err := s.fs.List(ctx, filestorage.Join(fmt.Sprintf("%d", orgID)), paging, &filestorage.ListOptions{
+			WithFiles: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range resp.Files {
+			infos = append(infos, Info{
+				Name:          strings.TrimSuffix(file.Name, recordingFileExt),
+				DatasourceUID: file.Properties[datasourceUIDProperty],
+			})
+		}
+		if !resp.HasMore {
+			break
+		}
+		paging = &filestorage.Paging{After: resp.LastPath}
+	}
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+func (s *Store) Delete(ctx context.Context, orgID int64, name string) error {
+	if !validName.MatchString(name) {
+		return recordings.ErrNotFound
+	}
+	return s.fs.Delete(ctx, recordingPath(orgID, name))
+}
@@ -0,0 +1,84 @@
+package queryrecording
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	store := ProvideStore(db.InitTestDB(t))
+	from := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+	recording := &recordings.Recording{
+		Name:          "incident-1",
+		DatasourceUID: "prom",
+		From:          from,
+		To:            from.Add(time.Hour),
+		Recorded:      from.Add(2 * time.Hour),
+		Response: &backend.QueryDataResponse{
+			Responses: backend.Responses{
+				"A": backend.DataResponse{
+					Frames: data.Frames{data.NewFrame("cpu",
+						data.NewField("time", nil, []time.Time{from}),
+						data.NewField("value", nil, []float64{1}),
+					)},
+				},
+			},
+		},
+	}
+
+	require.NoError(t, store.Save(ctx, 1, recording))
+	require.ErrorIs(t, store.Save(ctx, 1, &recordings.Recording{Name: "incident-1", DatasourceUID: "other"}), ErrRecordingExists)
+	require.NoError(t, store.Save(ctx, 1, &recordings.Recording{Name: "another", DatasourceUID: "loki", Response: backend.NewQueryDataResponse()}))
+	require.NoError(t, store.Save(ctx, 2, &recordings.Recording{Name: "other-org", Response: backend.NewQueryDataResponse()}))
+	require.ErrorIs(t, store.Save(ctx, 1, &recordings.Recording{Name: "../escape"}), ErrInvalidName)
+
+	loaded, err := store.GetRecording(ctx, 1, "incident-1")
+	require.NoError(t, err)
+	require.Equal(t, "prom", loaded.DatasourceUID)
+	require.True(t, from.Equal(loaded.From))
+	require.Len(t, loaded.Response.Responses["A"].Frames, 1)
+	require.Equal(t, 1.0, loaded.Response.Responses["A"].Frames[0].Fields[1].At(0))
+
+	_, err = store.GetRecording(ctx, 2, "incident-1")
+	require.ErrorIs(t, err, recordings.ErrNotFound)
+
+	infos, err := store.List(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, []Info{{Name: "another", DatasourceUID: "loki"}, {Name: "incident-1", DatasourceUID: "prom"}}, infos)
+
+	replay := ProvideReplayGetter(store, acimpl.ProvideAccessControlTest())
+	reader := &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{
+		1: {datasources.ActionQuery: {datasources.ScopeProvider.GetResourceScopeUID("prom")}},
+	}}
+	loaded, err = replay.GetRecording(ctx, reader, "incident-1")
+	require.NoError(t, err)
+	require.Equal(t, "incident-1", loaded.Name)
+	_, err = replay.GetRecording(ctx, reader, "another")
+	require.ErrorIs(t, err, recordings.ErrNotFound)
+
+	require.NoError(t, store.Delete(ctx, 1, "incident-1"))
+	_, err = store.GetRecording(ctx, 1, "incident-1")
+	require.ErrorIs(t, err, recordings.ErrNotFound)
+}
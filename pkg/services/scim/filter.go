@@ -0,0 +1,444 @@
+package scim
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Filter is a parsed SCIM filter expression (RFC 7644, section 3.4.2.2). Filters are
+// evaluated against resources in their JSON form, as returned by ToJSONObject.
+type Filter interface {
+	Matches(resource map[string]any) bool
+}
+
+// ParseFilter parses a filter expression such as
+//
+//	userName eq "alice" and (emails.value co "@grafana.com" or not (active eq false))
+func ParseFilter(filter string) (Filter, error) {
+	tokens, err := tokenize(filter)
+	if err != nil {
+		return nil, err
+	}
+	p := &filterParser{tokens: tokens}
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.done() {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, p.peek().value)
+	}
+	return f, nil
+}
+
+// ToJSONObject converts a resource to the generic form filters and patches operate on.
+func ToJSONObject(resource any) (map[string]any, error) {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return nil, err
+	}
+	obj := map[string]any{}
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenString
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+func tokenize(s string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenOpenParen, value: "("})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenCloseParen, value: ")"})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{kind: tokenOpenBracket, value: "["})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{kind: tokenCloseBracket, value: "]"})
+			i++
+		case c == '"':
+			// strings are JSON strings, find the closing quote that isn't escaped
+			end := i + 1
+			for ; end < len(s); end++ {
+				if s[end] == '\\' {
+					end++
+					continue
+				}
+				if s[end] == '"' {
+					break
+				}
+			}
+			if end >= len(s) {
+				return nil, fmt.Errorf("%w: unterminated string", ErrInvalidFilter)
+			}
+			var value string
+			if err := json.Unmarshal([]byte(s[i:end+1]), &value); err != nil {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidFilter, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value})
+			i = end + 1
+		default:
+			end := i
+			for end < len(s) && !unicode.IsSpace(rune(s[end])) && !strings.ContainsRune("()[]\"", rune(s[end])) {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: s[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+type filterParser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *filterParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *filterParser) peek() token {
+	if p.done() {
+		return token{}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() (token, error) {
+	if p.done() {
+		return token{}, fmt.Errorf("%w: unexpected end of filter", ErrInvalidFilter)
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *filterParser) peekKeyword(keyword string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.value, keyword)
+}
+
+func (p *filterParser) expect(kind tokenKind, value string) error {
+	t, err := p.next()
+	if err != nil {
+		return err
+	}
+	if t.kind != kind {
+		return fmt.Errorf("%w: expected %q, got %q", ErrInvalidFilter, value, t.value)
+	}
+	return nil
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("or") {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseFactor()
+	if err != nil {
+		return nil, err
+	}
+	for p.peekKeyword("and") {
+		p.pos++
+		right, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		left = andFilter{left, right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseFactor() (Filter, error) {
+	if p.peekKeyword("not") {
+		p.pos++
+		if err := p.expect(tokenOpenParen, "("); err != nil {
+			return nil, err
+		}
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return notFilter{f}, nil
+	}
+
+	if p.peek().kind == tokenOpenParen {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+
+	attr, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if attr.kind != tokenWord {
+		return nil, fmt.Errorf("%w: expected an attribute, got %q", ErrInvalidFilter, attr.value)
+	}
+	path := parseAttrPath(attr.value)
+
+	// complex attribute filter, such as emails[type eq "work" and value co "@grafana.com"]
+	if p.peek().kind == tokenOpenBracket {
+		p.pos++
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenCloseBracket, "]"); err != nil {
+			return nil, err
+		}
+		return valuePathFilter{path: path, filter: f}, nil
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	operator := strings.ToLower(op.value)
+	if op.kind != tokenWord || !isOperator(operator) {
+		return nil, fmt.Errorf("%w: unknown operator %q", ErrInvalidFilter, op.value)
+	}
+	if operator == "pr" {
+		return presentFilter{path: path}, nil
+	}
+
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	var expected any
+	switch {
+	case value.kind == tokenString:
+		expected = value.value
+	case value.kind == tokenWord && value.value == "true":
+		expected = true
+	case value.kind == tokenWord && value.value == "false":
+		expected = false
+	case value.kind == tokenWord && value.value == "null":
+		expected = nil
+	case value.kind == tokenWord:
+		n, err := strconv.ParseFloat(value.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid value %q", ErrInvalidFilter, value.value)
+		}
+		expected = n
+	default:
+		return nil, fmt.Errorf("%w: invalid value %q", ErrInvalidFilter, value.value)
+	}
+	return compareFilter{path: path, op: operator, value: expected}, nil
+}
+
+func isOperator(op string) bool {
+	switch op {
+	case "eq", "ne", "co", "sw", "ew", "pr", "gt", "ge", "lt", "le":
+		return true
+	}
+	return false
+}
+
+// parseAttrPath splits an attribute path into its parts, dropping the schema URN
+// attributes can be prefixed with.
+func parseAttrPath(attr string) []string {
+	if strings.HasPrefix(strings.ToLower(attr), "urn:") {
+		if i := strings.LastIndex(attr, ":"); i >= 0 {
+			attr = attr[i+1:]
+		}
+	}
+	return strings.Split(attr, ".")
+}
+
+// resolve returns the values at the path, flattening multi-valued attributes.
+// Attribute names are case insensitive.
+func resolve(value any, path []string) []any {
+	if arr, ok := value.([]any); ok {
+		var result []any
+		for _, v := range arr {
+			result = append(result, resolve(v, path)...)
+		}
+		return result
+	}
+	if len(path) == 0 {
+		if value == nil {
+			return nil
+		}
+		return []any{value}
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+	for k, v := range obj {
+		if strings.EqualFold(k, path[0]) {
+			return resolve(v, path[1:])
+		}
+	}
+	return nil
+}
+
+type andFilter struct{ left, right Filter }
+
+func (f andFilter) Matches(r map[string]any) bool { return f.left.Matches(r) && f.right.Matches(r) }
+
+type orFilter struct{ left, right Filter }
+
+func (f orFilter) Matches(r map[string]any) bool { return f.left.Matches(r) || f.right.Matches(r) }
+
+type notFilter struct{ filter Filter }
+
+func (f notFilter) Matches(r map[string]any) bool { return !f.filter.Matches(r) }
+
+type presentFilter struct{ path []string }
+
+func (f presentFilter) Matches(r map[string]any) bool {
+	for _, v := range resolve(r, f.path) {
+		if s, ok := v.(string); !ok || s != "" {
+			return true
+		}
+	}
+	return false
+}
+
+type valuePathFilter struct {
+	path   []string
+	filter Filter
+}
+
+func (f valuePathFilter) Matches(r map[string]any) bool {
+	for _, v := range resolve(r, f.path) {
+		if obj, ok := v.(map[string]any); ok && f.filter.Matches(obj) {
+			return true
+		}
+	}
+	return false
+}
+
+type compareFilter struct {
+	path  []string
+	op    string
+	value any
+}
+
+func (f compareFilter) Matches(r map[string]any) bool {
+	values := resolve(r, f.path)
+	if f.op == "ne" {
+		// ne matches when no value is equal
+		for _, v := range values {
+			if compare("eq", v, f.value) {
+				return false
+			}
+		}
+		return true
+	}
+	if len(values) == 0 {
+		return f.op == "eq" && f.value == nil
+	}
+	for _, v := range values {
+		if compare(f.op, v, f.value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(op string, actual, expected any) bool {
+	switch e := expected.(type) {
+	case string:
+		a, ok := actual.(string)
+		if !ok {
+			return false
+		}
+		// string attributes of users and groups are not case exact
+		a, e = strings.ToLower(a), strings.ToLower(e)
+		switch op {
+		case "eq":
+			return a == e
+		case "co":
+			return strings.Contains(a, e)
+		case "sw":
+			return strings.HasPrefix(a, e)
+		case "ew":
+			return strings.HasSuffix(a, e)
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case bool:
+		a, ok := actual.(bool)
+		return ok && op == "eq" && a == e
+	case float64:
+		a, ok := actual.(float64)
+		if !ok {
+			return false
+		}
+		switch op {
+		case "eq":
+			return a == e
+		case "gt":
+			return a > e
+		case "ge":
+			return a >= e
+		case "lt":
+			return a < e
+		case "le":
+			return a <= e
+		}
+	case nil:
+		return op == "eq" && actual == nil
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	active := true
+	user, err := ToJSONObject(User{
+		Schemas:    []string{SchemaUser},
+		ID:         "1",
+		ExternalID: "00u1",
+		UserName:   "alice",
+		Name:       &Name{GivenName: "Alice", FamilyName: "Smith"},
+		Emails: []Email{
+			{Value: "alice@grafana.com", Type: "work", Primary: true},
+			{Value: "alice@example.org", Type: "home"},
+		},
+		Active: &active,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		filter  string
+		matches bool
+	}{
+		{filter: `userName eq "alice"`, matches: true},
+		{filter: `userName eq "ALICE"`, matches: true},
+		{filter: `UserName Eq "alice"`, matches: true},
+		{filter: `userName eq "bob"`, matches: false},
+		{filter: `userName ne "bob"`, matches: true},
+		{filter: `userName sw "al"`, matches: true},
+		{filter: `userName ew "ce"`, matches: true},
+		{filter: `userName co "lic"`, matches: true},
+		{filter: `externalId eq "00u1"`, matches: true},
+		{filter: `urn:ietf:params:scim:schemas:core:2.0:User:userName eq "alice"`, matches: true},
+		{filter: `name.familyName eq "Smith"`, matches: true},
+		{filter: `name.formatted pr`, matches: false},
+		{filter: `emails.value eq "alice@example.org"`, matches: true},
+		{filter: `emails[type eq "work" and value co "@grafana.com"]`, matches: true},
+		{filter: `emails[type eq "home" and value co "@grafana.com"]`, matches: false},
+		{filter: `active eq true`, matches: true},
+		{filter: `active eq false`, matches: false},
+		{filter: `userName eq "bob" or active eq true`, matches: true},
+		{filter: `userName eq "alice" and not (active eq true)`, matches: false},
+		{filter: `(userName eq "bob" or userName eq "alice") and emails pr`, matches: true},
+		{filter: `displayName eq null`, matches: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			f, err := ParseFilter(tt.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, f.Matches(user))
+		})
+	}
+}
+
+func TestParseFilter_Invalid(t *testing.T) {
+	for _, filter := range []string{
+		``,
+		`userName`,
+		`userName eq`,
+		`userName is "alice"`,
+		`userName eq "alice`,
+		`userName eq alice`,
+		`(userName eq "alice"`,
+		`userName eq "alice" userName eq "bob"`,
+		`emails[type eq "work"`,
+		`not userName eq "alice"`,
+	} {
+		t.Run(filter, func(t *testing.T) {
+			_, err := ParseFilter(filter)
+			require.ErrorIs(t, err, ErrInvalidFilter)
+		})
+	}
+}
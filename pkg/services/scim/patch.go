@@ -0,0 +1,303 @@
+package scim
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidPath  = errors.New("invalid path")
+	ErrInvalidValue = errors.New("invalid value")
+	ErrNoTarget     = errors.New("no target")
+)
+
+// ApplyPatch applies the operations of a PATCH request (RFC 7644, section 3.5.2) to a
+// resource in its JSON form.
+func ApplyPatch(resource map[string]any, operations []PatchOperation) error {
+	for _, op := range operations {
+		var err error
+		switch strings.ToLower(op.Op) {
+		case "add":
+			err = patchAdd(resource, op)
+		case "replace":
+			err = patchReplace(resource, op)
+		case "remove":
+			err = patchRemove(resource, op)
+		default:
+			err = fmt.Errorf("%w: unknown operation %q", ErrInvalidValue, op.Op)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type patchPath struct {
+	attr      []string
+	filter    Filter
+	subAttr   string
+	rawFilter string
+}
+
+func parsePatchPath(path string) (*patchPath, error) {
+	p := &patchPath{}
+	attr := path
+	if open := strings.Index(path, "["); open >= 0 {
+		closing := strings.LastIndex(path, "]")
+		if closing < open {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+		}
+		attr = path[:open]
+		p.rawFilter = path[open+1 : closing]
+		filter, err := ParseFilter(p.rawFilter)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidPath, err)
+		}
+		p.filter = filter
+
+		if rest := path[closing+1:]; rest != "" {
+			if !strings.HasPrefix(rest, ".") || strings.Contains(rest[1:], ".") {
+				return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+			}
+			p.subAttr = rest[1:]
+		}
+	}
+	if attr == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPath, path)
+	}
+	p.attr = parseAttrPath(attr)
+	return p, nil
+}
+
+func patchAdd(resource map[string]any, op PatchOperation) error {
+	if op.Path == "" {
+		return mergeValue(resource, op.Value, true)
+	}
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	if path.filter != nil {
+		return setFiltered(resource, path, op.Value)
+	}
+
+	parent, key := lookupParent(resource, path.attr, true)
+	if existing, ok := parent[key].([]any); ok {
+		parent[key] = appendUnique(existing, toSlice(op.Value))
+		return nil
+	}
+	if sub, ok := parent[key].(map[string]any); ok {
+		return mergeValue(sub, op.Value, true)
+	}
+	parent[key] = normalizeValue(key, op.Value)
+	return nil
+}
+
+func patchReplace(resource map[string]any, op PatchOperation) error {
+	if op.Path == "" {
+		return mergeValue(resource, op.Value, false)
+	}
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	if path.filter != nil {
+		return setFiltered(resource, path, op.Value)
+	}
+
+	parent, key := lookupParent(resource, path.attr, true)
+	parent[key] = normalizeValue(key, op.Value)
+	return nil
+}
+
+func patchRemove(resource map[string]any, op PatchOperation) error {
+	if op.Path == "" {
+		return fmt.Errorf("%w: remove requires a path", ErrNoTarget)
+	}
+	path, err := parsePatchPath(op.Path)
+	if err != nil {
+		return err
+	}
+
+	parent, key := lookupParent(resource, path.attr, false)
+	if parent == nil {
+		return nil
+	}
+
+	if path.filter == nil {
+		existing, isList := parent[key].([]any)
+		// some identity providers send the values to remove instead of a filter
+		if isList && op.Value != nil {
+			parent[key] = removeValues(existing, toSlice(op.Value))
+			return nil
+		}
+		delete(parent, key)
+		return nil
+	}
+
+	existing, ok := parent[key].([]any)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoTarget, op.Path)
+	}
+	kept := make([]any, 0, len(existing))
+	matched := false
+	for _, v := range existing {
+		obj, isObj := v.(map[string]any)
+		if !isObj || !path.filter.Matches(obj) {
+			kept = append(kept, v)
+			continue
+		}
+		matched = true
+		if path.subAttr != "" {
+			delete(obj, findKey(obj, path.subAttr))
+			kept = append(kept, obj)
+		}
+	}
+	if !matched {
+		return fmt.Errorf("%w: %s", ErrNoTarget, op.Path)
+	}
+	parent[key] = kept
+	return nil
+}
+
+// setFiltered sets the value of the elements of a multi-valued attribute that match
+// the filter of the path, or of their sub-attribute.
+func setFiltered(resource map[string]any, path *patchPath, value any) error {
+	parent, key := lookupParent(resource, path.attr, false)
+	if parent == nil {
+		return fmt.Errorf("%w: %s", ErrNoTarget, strings.Join(path.attr, "."))
+	}
+	existing, ok := parent[key].([]any)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNoTarget, strings.Join(path.attr, "."))
+	}
+
+	matched := false
+	for i, v := range existing {
+		obj, isObj := v.(map[string]any)
+		if !isObj || !path.filter.Matches(obj) {
+			continue
+		}
+		matched = true
+		if path.subAttr != "" {
+			obj[findKey(obj, path.subAttr)] = value
+			continue
+		}
+		existing[i] = value
+	}
+	if !matched {
+		return fmt.Errorf("%w: %s[%s]", ErrNoTarget, strings.Join(path.attr, "."), path.rawFilter)
+	}
+	return nil
+}
+
+// mergeValue sets every attribute of value on the resource. Multi-valued attributes
+// are appended to when adding and replaced otherwise.
+func mergeValue(resource map[string]any, value any, add bool) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%w: operations without a path require an object value", ErrInvalidValue)
+	}
+	for k, v := range obj {
+		// attributes can be set with their full path, for example name.givenName
+		parent, key := lookupParent(resource, parseAttrPath(k), true)
+		if existing, isList := parent[key].([]any); isList && add {
+			parent[key] = appendUnique(existing, toSlice(v))
+			continue
+		}
+		parent[key] = normalizeValue(key, v)
+	}
+	return nil
+}
+
+// lookupParent returns the object holding the last attribute of the path and the
+// key of the attribute in it. Missing objects are created if create is set,
+// otherwise nil is returned.
+func lookupParent(resource map[string]any, attr []string, create bool) (map[string]any, string) {
+	obj := resource
+	for _, name := range attr[:len(attr)-1] {
+		key := findKey(obj, name)
+		child, ok := obj[key].(map[string]any)
+		if !ok {
+			if !create {
+				return nil, ""
+			}
+			child = map[string]any{}
+			obj[key] = child
+		}
+		obj = child
+	}
+	return obj, findKey(obj, attr[len(attr)-1])
+}
+
+// findKey returns the key of the attribute in obj, ignoring case, or name if it is missing.
+func findKey(obj map[string]any, name string) string {
+	for k := range obj {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func toSlice(value any) []any {
+	if arr, ok := value.([]any); ok {
+		return arr
+	}
+	return []any{value}
+}
+
+func appendUnique(existing []any, values []any) []any {
+	for _, v := range values {
+		if indexOfValue(existing, v) < 0 {
+			existing = append(existing, v)
+		}
+	}
+	return existing
+}
+
+func removeValues(existing []any, values []any) []any {
+	kept := make([]any, 0, len(existing))
+	for _, v := range existing {
+		if indexOfValue(values, v) < 0 {
+			kept = append(kept, v)
+		}
+	}
+	return kept
+}
+
+// indexOfValue finds a value in a multi-valued attribute. Complex values, such as
+// group members, are compared by their value sub-attribute.
+func indexOfValue(values []any, value any) int {
+	for i, v := range values {
+		if sameValue(v, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+func sameValue(a, b any) bool {
+	objA, okA := a.(map[string]any)
+	objB, okB := b.(map[string]any)
+	if okA && okB {
+		return fmt.Sprint(objA[findKey(objA, "value")]) == fmt.Sprint(objB[findKey(objB, "value")])
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// normalizeValue works around identity providers that send booleans as strings.
+func normalizeValue(key string, value any) any {
+	if !strings.EqualFold(key, "active") {
+		return value
+	}
+	if s, ok := value.(string); ok {
+		if b, err := strconv.ParseBool(s); err == nil {
+			return b
+		}
+	}
+	return value
+}
@@ -0,0 +1,120 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		desc       string
+		resource   map[string]any
+		operations []PatchOperation
+		expected   map[string]any
+	}{
+		{
+			desc:       "replace attribute",
+			resource:   map[string]any{"userName": "alice", "active": true},
+			operations: []PatchOperation{{Op: "replace", Path: "active", Value: false}},
+			expected:   map[string]any{"userName": "alice", "active": false},
+		},
+		{
+			desc:       "replace attribute with case insensitive op and path",
+			resource:   map[string]any{"userName": "alice", "active": true},
+			operations: []PatchOperation{{Op: "Replace", Path: "Active", Value: "False"}},
+			expected:   map[string]any{"userName": "alice", "active": false},
+		},
+		{
+			desc:       "replace without a path",
+			resource:   map[string]any{"userName": "alice", "active": true},
+			operations: []PatchOperation{{Op: "replace", Value: map[string]any{"active": false, "name.givenName": "Alice"}}},
+			expected:   map[string]any{"userName": "alice", "active": false, "name": map[string]any{"givenName": "Alice"}},
+		},
+		{
+			desc:       "replace sub-attribute",
+			resource:   map[string]any{"name": map[string]any{"givenName": "Alice"}},
+			operations: []PatchOperation{{Op: "replace", Path: "name.familyName", Value: "Smith"}},
+			expected:   map[string]any{"name": map[string]any{"givenName": "Alice", "familyName": "Smith"}},
+		},
+		{
+			desc: "replace value of filtered element",
+			resource: map[string]any{"emails": []any{
+				map[string]any{"type": "work", "value": "alice@grafana.com"},
+				map[string]any{"type": "home", "value": "alice@example.org"},
+			}},
+			operations: []PatchOperation{{Op: "replace", Path: `emails[type eq "work"].value`, Value: "alice@grafana.net"}},
+			expected: map[string]any{"emails": []any{
+				map[string]any{"type": "work", "value": "alice@grafana.net"},
+				map[string]any{"type": "home", "value": "alice@example.org"},
+			}},
+		},
+		{
+			desc:     "add members",
+			resource: map[string]any{"members": []any{map[string]any{"value": "1"}}},
+			operations: []PatchOperation{{Op: "add", Path: "members", Value: []any{
+				map[string]any{"value": "1"},
+				map[string]any{"value": "2"},
+			}}},
+			expected: map[string]any{"members": []any{map[string]any{"value": "1"}, map[string]any{"value": "2"}}},
+		},
+		{
+			desc:       "add members to a group without members",
+			resource:   map[string]any{"displayName": "devs"},
+			operations: []PatchOperation{{Op: "add", Path: "members", Value: []any{map[string]any{"value": "2"}}}},
+			expected:   map[string]any{"displayName": "devs", "members": []any{map[string]any{"value": "2"}}},
+		},
+		{
+			desc:       "remove member with a filter",
+			resource:   map[string]any{"members": []any{map[string]any{"value": "1"}, map[string]any{"value": "2"}}},
+			operations: []PatchOperation{{Op: "remove", Path: `members[value eq "1"]`}},
+			expected:   map[string]any{"members": []any{map[string]any{"value": "2"}}},
+		},
+		{
+			desc:       "remove member with a value",
+			resource:   map[string]any{"members": []any{map[string]any{"value": "1"}, map[string]any{"value": "2"}}},
+			operations: []PatchOperation{{Op: "remove", Path: "members", Value: []any{map[string]any{"value": "2"}}}},
+			expected:   map[string]any{"members": []any{map[string]any{"value": "1"}}},
+		},
+		{
+			desc:       "remove all members",
+			resource:   map[string]any{"displayName": "devs", "members": []any{map[string]any{"value": "1"}}},
+			operations: []PatchOperation{{Op: "remove", Path: "members"}},
+			expected:   map[string]any{"displayName": "devs"},
+		},
+		{
+			desc:       "remove missing attribute",
+			resource:   map[string]any{"userName": "alice"},
+			operations: []PatchOperation{{Op: "remove", Path: "name.givenName"}},
+			expected:   map[string]any{"userName": "alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.NoError(t, ApplyPatch(tt.resource, tt.operations))
+			assert.Equal(t, tt.expected, tt.resource)
+		})
+	}
+}
+
+func TestApplyPatch_Errors(t *testing.T) {
+	tests := []struct {
+		desc      string
+		operation PatchOperation
+		err       error
+	}{
+		{desc: "unknown operation", operation: PatchOperation{Op: "move", Path: "userName"}, err: ErrInvalidValue},
+		{desc: "remove without a path", operation: PatchOperation{Op: "remove"}, err: ErrNoTarget},
+		{desc: "add without a path or object", operation: PatchOperation{Op: "add", Value: "alice"}, err: ErrInvalidValue},
+		{desc: "invalid filter", operation: PatchOperation{Op: "remove", Path: `members[value]`}, err: ErrInvalidPath},
+		{desc: "unclosed filter", operation: PatchOperation{Op: "remove", Path: `members[value eq "1"`}, err: ErrInvalidPath},
+		{desc: "filter without a match", operation: PatchOperation{Op: "remove", Path: `members[value eq "3"]`}, err: ErrNoTarget},
+	}
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			resource := map[string]any{"userName": "alice", "members": []any{map[string]any{"value": "1"}}}
+			require.ErrorIs(t, ApplyPatch(resource, []PatchOperation{tt.operation}), tt.err)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements"
 	"github.com/grafana/grafana/pkg/services/user"
 )
 
@@ -30,6 +31,8 @@ type simpleAuthService struct {
 
 func (a *simpleAuthService) GetDashboardReadFilter(ctx context.Context, orgID int64, user *user.SignedInUser) (ResourceFilter, error) {
 	canReadDashboard, canReadFolder := accesscontrol.Checker(user, dashboards.ActionDashboardsRead), accesscontrol.Checker(user, dashboards.ActionFoldersRead)
+	canReadAlertRule := accesscontrol.Checker(user, accesscontrol.ActionAlertingRuleRead)
+	canReadLibraryPanel := accesscontrol.Checker(user, libraryelements.ActionLibraryPanelsRead)
 	return func(kind entityKind, uid, parent string) bool {
 		if kind == entityKindFolder {
 			scopes, err := dashboards.GetInheritedScopes(ctx, orgID, uid, a.folderService)
@@ -46,6 +49,22 @@ func (a *simpleAuthService) GetDashboardReadFilter(ctx context.Context, orgID in
 			scopes = append(scopes, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(uid))
 			scopes = append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent))
 			return canReadDashboard(scopes...)
+		} else if kind == entityKindAlertRule {
+			// Alert rules inherit their permissions from the folder they belong to.
+			scopes, err := dashboards.GetInheritedScopes(ctx, orgID, parent, a.folderService)
+			if err != nil {
+				a.logger.Debug("Could not retrieve inherited folder scopes:", "err", err)
+			}
+			scopes = append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent))
+			return canReadAlertRule(scopes...)
+		} else if kind == entityKindLibraryPanel {
+			scopes, err := dashboards.GetInheritedScopes(ctx, orgID, parent, a.folderService)
+			if err != nil {
+				a.logger.Debug("Could not retrieve inherited folder scopes:", "err", err)
+			}
+			scopes = append(scopes, libraryelements.ScopeLibraryPanelsProvider.GetResourceScopeUID(uid))
+			scopes = append(scopes, dashboards.ScopeFoldersProvider.GetResourceScopeUID(parent))
+			return canReadLibraryPanel(scopes...)
 		}
 		return false
 	}, nil
@@ -18,6 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/slugify"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/store/entity"
+	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
 )
 
 const (
@@ -33,11 +34,14 @@ const (
 	documentFieldTransformer = "transformer"
 	documentFieldDSUID       = "ds_uid"
 	documentFieldDSType      = "ds_type"
+	documentFieldQuery       = "query" // raw query text of panel targets and alert rule queries
+	documentFieldLabel       = "label" // alert rule labels, indexed as both "key" and "key=value"
+	documentFieldRuleGroup   = "rule_group"
 	DocumentFieldCreatedAt   = "created_at"
 	DocumentFieldUpdatedAt   = "updated_at"
 )
 
-func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
+func initOrgIndex(dashboards []dashboard, alertRules []alertRule, libraryPanels []libraryPanel, logger log.Logger, extendDoc ExtendDashboardFunc) (*orgIndex, error) {
 	dashboardWriter, err := bluge.OpenWriter(bluge.InMemoryOnlyConfig())
 	if err != nil {
 		return nil, fmt.Errorf("error opening writer: %v", err)
@@ -120,6 +124,22 @@ func initOrgIndex(dashboards []dashboard, logger log.Logger, extendDoc ExtendDas
 		}
 	}
 
+	// Alert rules and library panels live in folders only, so they can be
+	// indexed independently of dashboards.
+	for _, rule := range alertRules {
+		batch.Insert(getAlertRuleDoc(rule))
+		if err := flushIfRequired(false); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, panel := range libraryPanels {
+		batch.Insert(getLibraryPanelDoc(panel))
+		if err := flushIfRequired(false); err != nil {
+			return nil, err
+		}
+	}
+
 	// Flush docs in batch with force as we are in the end.
 	if err := flushIfRequired(true); err != nil {
 		return nil, err
@@ -170,21 +190,15 @@ func getNonFolderDashboardDoc(dash dashboard, location string) *bluge.Document {
 
 	for _, ref := range dash.summary.References {
 		if ref.Family == entity.StandardKindDataSource {
-			if ref.Type != "" {
-				doc.AddField(bluge.NewKeywordField(documentFieldDSType, ref.Type).
-					StoreValue().
-					Aggregatable().
-					SearchTermPositions())
-			}
-			if ref.Identifier != "" {
-				doc.AddField(bluge.NewKeywordField(documentFieldDSUID, ref.Identifier).
-					StoreValue().
-					Aggregatable().
-					SearchTermPositions())
-			}
+			addDatasourceFields(doc, ref.Type, ref.Identifier)
 		}
 	}
 
+	// Dashboards are also matched by the queries of their panels.
+	for _, panel := range dash.summary.Nested {
+		addQueryTextFields(doc, panel.Fields)
+	}
+
 	return doc
 }
 
@@ -209,21 +223,12 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 			AddField(bluge.NewKeywordField(documentFieldLocation, location).Aggregatable().StoreValue()).
 			AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindPanel)).Aggregatable().StoreValue()) // likely want independent index for this
 
+		addQueryTextFields(doc, panel.Fields)
+
 		for _, ref := range panel.References {
 			switch ref.Family {
-			case entity.StandardKindDashboard:
-				if ref.Type != "" {
-					doc.AddField(bluge.NewKeywordField(documentFieldDSType, ref.Type).
-						StoreValue().
-						Aggregatable().
-						SearchTermPositions())
-				}
-				if ref.Identifier != "" {
-					doc.AddField(bluge.NewKeywordField(documentFieldDSUID, ref.Identifier).
-						StoreValue().
-						Aggregatable().
-						SearchTermPositions())
-				}
+			case entity.StandardKindDataSource:
+				addDatasourceFields(doc, ref.Type, ref.Identifier)
 			case entity.ExternalEntityReferencePlugin:
 				if ref.Type == entity.StandardKindPanel && ref.Identifier != "" {
 					doc.AddField(bluge.NewKeywordField(documentFieldPanelType, ref.Identifier).Aggregatable().StoreValue())
@@ -240,6 +245,75 @@ func getDashboardPanelDocs(dash dashboard, location string) []*bluge.Document {
 	return docs
 }
 
+func getAlertRuleDoc(rule alertRule) *bluge.Document {
+	url := fmt.Sprintf("/alerting/grafana/%s/view", rule.uid)
+
+	doc := newSearchDocument(rule.uid, rule.title, "", url).
+		AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindAlertRule)).Aggregatable().StoreValue()).
+		AddField(bluge.NewKeywordField(documentFieldLocation, rule.folderUID).Aggregatable().StoreValue()).
+		AddField(bluge.NewKeywordField(documentFieldRuleGroup, rule.ruleGroup).Aggregatable().StoreValue()).
+		AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, rule.updated).Sortable().StoreValue())
+
+	for k, v := range rule.labels {
+		doc.AddField(bluge.NewKeywordField(documentFieldLabel, k).Aggregatable().StoreValue())
+		doc.AddField(bluge.NewKeywordField(documentFieldLabel, k+"="+v).Aggregatable().StoreValue())
+	}
+
+	for _, q := range rule.queries {
+		addDatasourceFields(doc, q.dsType, q.dsUID)
+		if q.text != "" {
+			doc.AddField(bluge.NewTextField(documentFieldQuery, q.text).SearchTermPositions())
+		}
+	}
+	return doc
+}
+
+func getLibraryPanelDoc(panel libraryPanel) *bluge.Document {
+	doc := newSearchDocument(panel.uid, panel.name, panel.description, "/library-panels").
+		AddField(bluge.NewKeywordField(documentFieldKind, string(entityKindLibraryPanel)).Aggregatable().StoreValue()).
+		AddField(bluge.NewKeywordField(documentFieldLocation, panel.folderUID).Aggregatable().StoreValue()).
+		AddField(bluge.NewDateTimeField(DocumentFieldCreatedAt, panel.created).Sortable().StoreValue()).
+		AddField(bluge.NewDateTimeField(DocumentFieldUpdatedAt, panel.updated).Sortable().StoreValue())
+
+	if panel.panelType != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldPanelType, panel.panelType).Aggregatable().StoreValue())
+	}
+
+	if panel.summary != nil {
+		for _, ref := range panel.summary.References {
+			if ref.Family == entity.StandardKindDataSource {
+				addDatasourceFields(doc, ref.Type, ref.Identifier)
+			}
+		}
+		addQueryTextFields(doc, panel.summary.Fields)
+	}
+	return doc
+}
+
+func addDatasourceFields(doc *bluge.Document, dsType, dsUID string) {
+	if dsType != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldDSType, dsType).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+	if dsUID != "" {
+		doc.AddField(bluge.NewKeywordField(documentFieldDSUID, dsUID).
+			StoreValue().
+			Aggregatable().
+			SearchTermPositions())
+	}
+}
+
+// addQueryTextFields indexes the raw query text stored in the panel summary fields.
+func addQueryTextFields(doc *bluge.Document, fields map[string]string) {
+	for k, v := range fields {
+		if strings.HasPrefix(k, kdash.PanelQueryFieldPrefix) && v != "" {
+			doc.AddField(bluge.NewTextField(documentFieldQuery, v).SearchTermPositions())
+		}
+	}
+}
+
 // Names need to be indexed a few ways to support key features
 func newSearchDocument(uid, name, descr, url string) *bluge.Document {
 	doc := bluge.NewDocument(uid)
@@ -443,6 +517,29 @@ func doSearchQuery(
 		hasConstraints = true
 	}
 
+	// Alert rule labels
+	if len(q.Labels) > 0 {
+		bq := bluge.NewBooleanQuery()
+		for _, v := range q.Labels {
+			bq.AddMust(bluge.NewTermQuery(v).SetField(documentFieldLabel))
+		}
+		fullQuery.AddMust(bq)
+		hasConstraints = true
+	}
+
+	// Raw query text of panel targets and alert rule queries
+	if q.QueryText != "" {
+		textQuery, err := newQueryTextQuery(q.QueryText)
+		if err != nil {
+			response.Error = err
+			return response
+		}
+		if textQuery != nil {
+			fullQuery.AddMust(textQuery)
+			hasConstraints = true
+		}
+	}
+
 	// Folder
 	if q.Location != "" {
 		fullQuery.AddMust(bluge.NewTermQuery(q.Location).SetField(documentFieldLocation))
@@ -21,19 +21,21 @@ type PermissionFilter struct {
 type entityKind string
 
 const (
-	entityKindPanel      entityKind = entity.StandardKindPanel
-	entityKindDashboard  entityKind = entity.StandardKindDashboard
-	entityKindFolder     entityKind = entity.StandardKindFolder
-	entityKindDatasource entityKind = entity.StandardKindDataSource
-	entityKindQuery      entityKind = entity.StandardKindQuery
+	entityKindPanel        entityKind = entity.StandardKindPanel
+	entityKindDashboard    entityKind = entity.StandardKindDashboard
+	entityKindFolder       entityKind = entity.StandardKindFolder
+	entityKindDatasource   entityKind = entity.StandardKindDataSource
+	entityKindQuery        entityKind = entity.StandardKindQuery
+	entityKindAlertRule    entityKind = entity.StandardKindAlertRule
+	entityKindLibraryPanel entityKind = entity.StandardKindLibraryPanel
 )
 
 func (r entityKind) IsValid() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r == entityKindAlertRule || r == entityKindLibraryPanel
 }
 
 func (r entityKind) supportsAuthzCheck() bool {
-	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder
+	return r == entityKindPanel || r == entityKindDashboard || r == entityKindFolder || r == entityKindAlertRule || r == entityKindLibraryPanel
 }
 
 var (
@@ -75,7 +77,7 @@ func (q *PermissionFilter) canAccess(kind entityKind, id, location string) bool
 	// TODO add `kind` to the `ResourceFilter` interface so that we can move the switch out of here
 	//
 	switch kind {
-	case entityKindFolder, entityKindDashboard:
+	case entityKindFolder, entityKindDashboard, entityKindAlertRule, entityKindLibraryPanel:
 		decision := q.filter(kind, id, location)
 		q.logAccessDecision(decision, kind, id, "resourceFilter")
 		return decision
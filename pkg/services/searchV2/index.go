@@ -3,6 +3,7 @@ package searchV2
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -22,6 +23,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
 	"github.com/grafana/grafana/pkg/services/store"
 	"github.com/grafana/grafana/pkg/services/store/entity"
 	kdash "github.com/grafana/grafana/pkg/services/store/kind/dashboard"
@@ -37,6 +39,18 @@ type dashboardLoader interface {
 	LoadDashboards(ctx context.Context, orgID int64, dashboardUID string) ([]dashboard, error)
 }
 
+// alertRuleLoader loads the Grafana-managed alert rules of an organization. Alert
+// rules do not emit entity events yet, so they are refreshed on full re-indexing.
+type alertRuleLoader interface {
+	LoadAlertRules(ctx context.Context, orgID int64) ([]alertRule, error)
+}
+
+// libraryPanelLoader loads the library panels of an organization. Like alert rules,
+// library panels are refreshed on full re-indexing.
+type libraryPanelLoader interface {
+	LoadLibraryPanels(ctx context.Context, orgID int64) ([]libraryPanel, error)
+}
+
 type eventStore interface {
 	GetLastEvent(ctx context.Context) (*store.EntityEvent, error)
 	GetAllEventsAfter(ctx context.Context, id int64) ([]*store.EntityEvent, error)
@@ -59,6 +73,36 @@ type dashboard struct {
 	summary *entity.EntitySummary
 }
 
+type alertRule struct {
+	uid       string
+	title     string
+	folderUID string
+	ruleGroup string
+	labels    map[string]string
+	updated   time.Time
+	queries   []alertRuleQuery
+}
+
+type alertRuleQuery struct {
+	refID  string
+	dsUID  string
+	dsType string
+	text   string
+}
+
+type libraryPanel struct {
+	uid         string
+	name        string
+	description string
+	folderUID   string
+	panelType   string
+	created     time.Time
+	updated     time.Time
+
+	// Summary of the panel model, includes datasource references and query text.
+	summary *entity.EntitySummary
+}
+
 // buildSignal is sent when search index is accessed in organization for which
 // we have not constructed an index yet.
 type buildSignal struct {
@@ -91,11 +135,14 @@ func (i *orgIndex) readerForIndex(idxType indexType) (*bluge.Reader, func(), err
 type searchIndex struct {
 	mu                      sync.RWMutex
 	loader                  dashboardLoader
+	ruleLoader              alertRuleLoader
+	libraryPanelLoader      libraryPanelLoader
 	perOrgIndex             map[int64]*orgIndex
 	initializedOrgs         map[int64]bool
 	initialIndexingComplete bool
 	initializationMutex     sync.RWMutex
 	eventStore              eventStore
+	snapshots               indexSnapshotStore
 	logger                  log.Logger
 	buildSignals            chan buildSignal
 	extender                DocumentExtender
@@ -106,20 +153,23 @@ type searchIndex struct {
 	settings                setting.SearchSettings
 }
 
-func newSearchIndex(dashLoader dashboardLoader, evStore eventStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
+func newSearchIndex(dashLoader dashboardLoader, ruleLoader alertRuleLoader, libraryPanelLoader libraryPanelLoader, evStore eventStore, snapshots indexSnapshotStore, extender DocumentExtender, folderIDs folderUIDLookup, tracer tracing.Tracer, features featuremgmt.FeatureToggles, settings setting.SearchSettings) *searchIndex {
 	return &searchIndex{
-		loader:          dashLoader,
-		eventStore:      evStore,
-		perOrgIndex:     map[int64]*orgIndex{},
-		initializedOrgs: map[int64]bool{},
-		logger:          log.New("searchIndex"),
-		buildSignals:    make(chan buildSignal),
-		extender:        extender,
-		folderIdLookup:  folderIDs,
-		syncCh:          make(chan chan struct{}),
-		tracer:          tracer,
-		features:        features,
-		settings:        settings,
+		loader:             dashLoader,
+		ruleLoader:         ruleLoader,
+		libraryPanelLoader: libraryPanelLoader,
+		eventStore:         evStore,
+		snapshots:          snapshots,
+		perOrgIndex:        map[int64]*orgIndex{},
+		initializedOrgs:    map[int64]bool{},
+		logger:             log.New("searchIndex"),
+		buildSignals:       make(chan buildSignal),
+		extender:           extender,
+		folderIdLookup:     folderIDs,
+		syncCh:             make(chan chan struct{}),
+		tracer:             tracer,
+		features:           features,
+		settings:           settings,
 	}
 }
 
@@ -204,11 +254,20 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 		lastEventID = lastEvent.Id
 	}
 
-	err = i.buildInitialIndexes(initialSetupCtx, orgIDs)
+	// Indexes loaded from snapshots may be behind the last event.
+	lastEventID, err = i.buildInitialIndexes(initialSetupCtx, orgIDs, lastEventID)
 	if err != nil {
 		initialSetupSpan.End()
 		return err
 	}
+	lastEventID = i.applyIndexUpdates(initialSetupCtx, lastEventID)
+
+	// Snapshots are disabled when no storage is configured.
+	snapshotTimer := time.NewTimer(i.settings.IndexSnapshotInterval)
+	if i.snapshots == nil {
+		snapshotTimer.Stop()
+	}
+	defer snapshotTimer.Stop()
 
 	// This semaphore channel allows limiting concurrent async re-indexing routines to 1.
 	asyncReIndexSemaphore := make(chan struct{}, 1)
@@ -262,6 +321,11 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 				// We need semaphore here since asynchronous re-indexing may be in progress already.
 				asyncReIndexSemaphore <- struct{}{}
 				defer func() { <-asyncReIndexSemaphore }()
+				if snapshotEventID, ok := i.loadOrgIndexSnapshot(buildSignalCtx, signal.orgID, lastIndexedEventID); ok {
+					signal.done <- nil
+					reIndexDoneCh <- snapshotEventID
+					return
+				}
 				_, err = i.buildOrgIndex(buildSignalCtx, signal.orgID)
 				signal.done <- err
 				reIndexDoneCh <- lastIndexedEventID
@@ -290,6 +354,23 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 				i.logger.Info("Full re-indexing finished", i.withCtxData(fullReindexCtx, "fullReIndexElapsed", time.Since(started))...)
 				reIndexDoneCh <- lastIndexedEventID
 			}()
+		case <-snapshotTimer.C:
+			// Skip snapshots while an asynchronous re-indexing replaces indexes, since
+			// their state would not match lastEventID.
+			select {
+			case asyncReIndexSemaphore <- struct{}{}:
+			default:
+				snapshotTimer.Reset(i.settings.IndexSnapshotInterval)
+				continue
+			}
+			snapshotCtx, span := i.tracer.Start(ctx, "searchV2 snapshot timer")
+			snapshotEventID := lastEventID
+			go func() {
+				defer span.End()
+				defer func() { <-asyncReIndexSemaphore }()
+				i.saveIndexSnapshots(snapshotCtx, snapshotEventID)
+			}()
+			snapshotTimer.Reset(i.settings.IndexSnapshotInterval)
 		case lastIndexedEventID := <-reIndexDoneCh:
 			// Asynchronous re-indexing is finished. Set lastEventID to the value which
 			// was actual at the re-indexing start – so that we could re-apply all the
@@ -307,17 +388,112 @@ func (i *searchIndex) run(ctx context.Context, orgIDs []int64, reIndexSignalCh c
 	}
 }
 
-func (i *searchIndex) buildInitialIndexes(ctx context.Context, orgIDs []int64) error {
+// buildInitialIndexes builds the index of every organization, loading them from snapshots
+// when possible. It returns the ID of the last event applied to all the indexes, which is
+// lower than lastEventID when snapshots were loaded.
+func (i *searchIndex) buildInitialIndexes(ctx context.Context, orgIDs []int64, lastEventID int64) (int64, error) {
 	started := time.Now()
 	i.logger.Info("Start building in-memory indexes")
+	appliedEventID := lastEventID
 	for _, orgID := range orgIDs {
+		if snapshotEventID, ok := i.loadOrgIndexSnapshot(ctx, orgID, lastEventID); ok {
+			if snapshotEventID < appliedEventID {
+				appliedEventID = snapshotEventID
+			}
+			continue
+		}
 		err := i.buildInitialIndex(ctx, orgID)
 		if err != nil {
-			return fmt.Errorf("can't build initial dashboard search index for org %d: %w", orgID, err)
+			return appliedEventID, fmt.Errorf("can't build initial dashboard search index for org %d: %w", orgID, err)
 		}
 	}
 	i.logger.Info("Finish building in-memory indexes", "elapsed", time.Since(started))
-	return nil
+	return appliedEventID, nil
+}
+
+// loadOrgIndexSnapshot replaces the organization index with its persisted snapshot. It
+// returns the ID of the last event applied to the snapshot, and false when no usable
+// snapshot was found.
+func (i *searchIndex) loadOrgIndexSnapshot(ctx context.Context, orgID int64, lastEventID int64) (int64, bool) {
+	if i.snapshots == nil {
+		return 0, false
+	}
+
+	ctx, span := i.tracer.Start(ctx, "searchV2 loadOrgIndexSnapshot", trace.WithAttributes(
+		attribute.Int64("org_id", orgID),
+	))
+	defer span.End()
+
+	started := time.Now()
+	snapshot, err := i.snapshots.Load(ctx, orgID)
+	if err != nil {
+		i.logger.Warn("Can't load index snapshot", "orgId", orgID, "error", err)
+		return 0, false
+	}
+	if snapshot == nil {
+		return 0, false
+	}
+	if time.Since(snapshot.created) > i.settings.IndexSnapshotMaxAge {
+		i.logger.Info("Ignoring outdated index snapshot", "orgId", orgID, "created", snapshot.created)
+		return 0, false
+	}
+	if snapshot.lastEventID > lastEventID {
+		// Events the snapshot is based on do not exist, e.g. the database was restored.
+		i.logger.Warn("Ignoring index snapshot ahead of entity events", "orgId", orgID, "snapshotEventID", snapshot.lastEventID, "lastEventID", lastEventID)
+		return 0, false
+	}
+
+	index, err := restoreOrgIndex(snapshot.data)
+	if err != nil {
+		i.logger.Warn("Can't restore index snapshot", "orgId", orgID, "error", err)
+		return 0, false
+	}
+
+	i.mu.Lock()
+	if oldIndex, ok := i.perOrgIndex[orgID]; ok {
+		for _, w := range oldIndex.writers {
+			_ = w.Close()
+		}
+	}
+	i.perOrgIndex[orgID] = index
+	i.mu.Unlock()
+
+	i.initializationMutex.Lock()
+	i.initializedOrgs[orgID] = true
+	i.initializationMutex.Unlock()
+
+	i.logger.Info("Loaded index snapshot", "orgId", orgID, "snapshotEventID", snapshot.lastEventID, "size", formatBytes(uint64(len(snapshot.data))), "elapsed", time.Since(started))
+	return snapshot.lastEventID, true
+}
+
+// saveIndexSnapshots persists the index of every organization. lastEventID must not
+// be greater than the last event applied to the indexes.
+func (i *searchIndex) saveIndexSnapshots(ctx context.Context, lastEventID int64) {
+	i.mu.RLock()
+	indexes := make(map[int64]*orgIndex, len(i.perOrgIndex))
+	for orgID, index := range i.perOrgIndex {
+		indexes[orgID] = index
+	}
+	i.mu.RUnlock()
+
+	for orgID, index := range indexes {
+		started := time.Now()
+		data, err := backupOrgIndex(index)
+		if err != nil {
+			i.logger.Error("Can't create index snapshot", "orgId", orgID, "error", err)
+			continue
+		}
+		err = i.snapshots.Save(ctx, &indexSnapshot{
+			orgID:       orgID,
+			lastEventID: lastEventID,
+			data:        data,
+		})
+		if err != nil {
+			i.logger.Error("Can't save index snapshot", "orgId", orgID, "error", err)
+			continue
+		}
+		i.logger.Info("Saved index snapshot", "orgId", orgID, "snapshotEventID", lastEventID, "size", formatBytes(uint64(len(data))), "elapsed", time.Since(started))
+	}
 }
 
 func (i *searchIndex) buildInitialIndex(ctx context.Context, orgID int64) error {
@@ -489,14 +665,27 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 	}
 	i.logger.Info("Finish loading org dashboards", "elapsed", orgSearchIndexLoadTime, "orgId", orgID)
 
+	alertRules, err := i.ruleLoader.LoadAlertRules(ctx, orgID)
+	if err != nil {
+		return 0, fmt.Errorf("error loading alert rules: %w", err)
+	}
+
+	libraryPanels, err := i.libraryPanelLoader.LoadLibraryPanels(ctx, orgID)
+	if err != nil {
+		return 0, fmt.Errorf("error loading library panels: %w", err)
+	}
+	orgSearchIndexLoadTime = time.Since(started)
+
 	dashboardExtender := i.extender.GetDashboardExtender(orgID)
 
 	_, initOrgIndexSpan := i.tracer.Start(ctx, "searchV2 buildOrgIndex init org index", trace.WithAttributes(
 		attribute.Int64("org_id", orgID),
 		attribute.Int("dashboardCount", len(dashboards)),
+		attribute.Int("alertRuleCount", len(alertRules)),
+		attribute.Int("libraryPanelCount", len(libraryPanels)),
 	))
 
-	index, err := initOrgIndex(dashboards, i.logger, dashboardExtender)
+	index, err := initOrgIndex(dashboards, alertRules, libraryPanels, i.logger, dashboardExtender)
 
 	initOrgIndexSpan.End()
 
@@ -511,7 +700,9 @@ func (i *searchIndex) buildOrgIndex(ctx context.Context, orgID int64) (int, erro
 			"orgSearchIndexLoadTime", orgSearchIndexLoadTime,
 			"orgSearchIndexBuildTime", orgSearchIndexBuildTime,
 			"orgSearchIndexTotalTime", orgSearchIndexTotalTime,
-			"orgSearchDashboardCount", len(dashboards))...)
+			"orgSearchDashboardCount", len(dashboards),
+			"orgSearchAlertRuleCount", len(alertRules),
+			"orgSearchLibraryPanelCount", len(libraryPanels))...)
 
 	i.mu.Lock()
 	if oldIndex, ok := i.perOrgIndex[orgID]; ok {
@@ -965,6 +1156,121 @@ func (l sqlDashboardLoader) LoadDashboards(ctx context.Context, orgID int64, das
 	return dashboards, err
 }
 
+func (l sqlDashboardLoader) LoadAlertRules(ctx context.Context, orgID int64) ([]alertRule, error) {
+	ctx, span := l.tracer.Start(ctx, "sqlDashboardLoader LoadAlertRules", trace.WithAttributes(
+		attribute.Int64("orgID", orgID),
+	))
+	defer span.End()
+
+	lookup, err := kdash.LoadDatasourceLookup(ctx, orgID, l.sql)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*alertRuleQueryResult, 0)
+	err = l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("alert_rule").
+			Where("org_id = ?", orgID).
+			Cols("uid", "title", "namespace_uid", "rule_group", "data", "labels", "updated").
+			OrderBy("id ASC").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]alertRule, 0, len(rows))
+	for _, row := range rows {
+		rule := alertRule{
+			uid:       row.UID,
+			title:     row.Title,
+			folderUID: row.NamespaceUID,
+			ruleGroup: row.RuleGroup,
+			updated:   row.Updated,
+		}
+		if len(row.Labels) > 0 {
+			if err := json.Unmarshal(row.Labels, &rule.labels); err != nil {
+				l.logger.Warn("Error reading alert rule labels", "error", err, "ruleUID", row.UID)
+			}
+		}
+		var queries []alertRuleQueryData
+		if err := json.Unmarshal(row.Data, &queries); err != nil {
+			l.logger.Warn("Error reading alert rule queries", "error", err, "ruleUID", row.UID)
+		}
+		for _, q := range queries {
+			query := alertRuleQuery{
+				refID: q.RefID,
+				dsUID: q.DatasourceUID,
+				text:  kdash.ReadQueryText(q.Model),
+			}
+			// Server side expressions are not datasources.
+			if q.DatasourceUID == expressionDatasourceUID {
+				query.dsUID = ""
+			} else if ds := lookup.ByRef(&kdash.DataSourceRef{UID: q.DatasourceUID}); ds != nil {
+				query.dsType = ds.Type
+			}
+			rule.queries = append(rule.queries, query)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (l sqlDashboardLoader) LoadLibraryPanels(ctx context.Context, orgID int64) ([]libraryPanel, error) {
+	ctx, span := l.tracer.Start(ctx, "sqlDashboardLoader LoadLibraryPanels", trace.WithAttributes(
+		attribute.Int64("orgID", orgID),
+	))
+	defer span.End()
+
+	lookup, err := kdash.LoadDatasourceLookup(ctx, orgID, l.sql)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]*libraryPanelQueryResult, 0)
+	err = l.sql.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("library_element").
+			Where("org_id = ? AND kind = ?", orgID, model.PanelElement).
+			Cols("uid", "folder_uid", "name", "type", "description", "model", "created", "updated").
+			OrderBy("id ASC").
+			Find(&rows)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reader := kdash.NewStaticDashboardSummaryBuilder(lookup, false)
+	panels := make([]libraryPanel, 0, len(rows))
+	for _, row := range rows {
+		panel := libraryPanel{
+			uid:         row.UID,
+			name:        row.Name,
+			description: row.Description,
+			folderUID:   row.FolderUID,
+			panelType:   row.Type,
+			created:     row.Created,
+			updated:     row.Updated,
+		}
+		// Read the model as a single panel dashboard to reuse the panel summary,
+		// which resolves datasource references and extracts the query text.
+		body, err := json.Marshal(map[string]any{
+			"title":  row.Name,
+			"panels": []json.RawMessage{row.Model},
+		})
+		if err == nil {
+			summary, _, err := reader(ctx, row.UID, body)
+			if err != nil {
+				l.logger.Warn("Error indexing library panel model", "error", err, "libraryPanelUID", row.UID)
+			}
+			if summary != nil && len(summary.Nested) > 0 {
+				panel.summary = summary.Nested[0]
+			}
+		}
+		panels = append(panels, panel)
+	}
+	return panels, nil
+}
+
 func newFolderIDLookup(sql db.DB) folderUIDLookup {
 	return func(ctx context.Context, folderID int64) (string, error) {
 		uid := ""
@@ -982,6 +1288,36 @@ func newFolderIDLookup(sql db.DB) folderUIDLookup {
 	}
 }
 
+// expressionDatasourceUID is the datasource UID used by server side expressions in alert rules.
+const expressionDatasourceUID = "__expr__"
+
+type alertRuleQueryResult struct {
+	UID          string `xorm:"uid"`
+	Title        string `xorm:"title"`
+	NamespaceUID string `xorm:"namespace_uid"`
+	RuleGroup    string `xorm:"rule_group"`
+	Data         []byte `xorm:"data"`
+	Labels       []byte `xorm:"labels"`
+	Updated      time.Time
+}
+
+type alertRuleQueryData struct {
+	RefID         string          `json:"refId"`
+	DatasourceUID string          `json:"datasourceUid"`
+	Model         json.RawMessage `json:"model"`
+}
+
+type libraryPanelQueryResult struct {
+	UID         string `xorm:"uid"`
+	FolderUID   string `xorm:"folder_uid"`
+	Name        string
+	Type        string
+	Description string
+	Model       []byte
+	Created     time.Time
+	Updated     time.Time
+}
+
 type dashboardQueryResult struct {
 	Id       int64
 	Uid      string
@@ -21,13 +21,23 @@ import (
 )
 
 type testDashboardLoader struct {
-	dashboards []dashboard
+	dashboards    []dashboard
+	alertRules    []alertRule
+	libraryPanels []libraryPanel
 }
 
 func (t *testDashboardLoader) LoadDashboards(_ context.Context, _ int64, _ string) ([]dashboard, error) {
 	return t.dashboards, nil
 }
 
+func (t *testDashboardLoader) LoadAlertRules(_ context.Context, _ int64) ([]alertRule, error) {
+	return t.alertRules, nil
+}
+
+func (t *testDashboardLoader) LoadLibraryPanels(_ context.Context, _ int64) ([]libraryPanel, error) {
+	return t.libraryPanels, nil
+}
+
 var testLogger = log.New("index-test-logger")
 
 var testAllowAllFilter = func(kind entityKind, uid, parent string) bool {
@@ -59,10 +69,12 @@ func initTestIndexFromDashes(t *testing.T, dashboards []dashboard) *searchIndex
 
 func initTestIndexFromDashesExtended(t *testing.T, dashboards []dashboard, extender DocumentExtender) *searchIndex {
 	t.Helper()
-	dashboardLoader := &testDashboardLoader{
-		dashboards: dashboards,
-	}
-	index := newSearchIndex(dashboardLoader, &store.MockEntityEventsService{}, extender, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
+	return initTestIndexFromLoader(t, &testDashboardLoader{dashboards: dashboards}, extender)
+}
+
+func initTestIndexFromLoader(t *testing.T, dashboardLoader *testDashboardLoader, extender DocumentExtender) *searchIndex {
+	t.Helper()
+	index := newSearchIndex(dashboardLoader, dashboardLoader, dashboardLoader, &store.MockEntityEventsService{}, nil, extender, func(ctx context.Context, folderId int64) (string, error) { return "x", nil }, tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{})
 	require.NotNil(t, index)
 	numDashboards, err := index.buildOrgIndex(context.Background(), testOrgID)
 	require.NoError(t, err)
@@ -731,3 +743,166 @@ func TestDashboardIndex_MultiTermPrefixMatch(t *testing.T) {
 		})
 	}
 }
+
+var dashboardsWithQueries = []dashboard{
+	{
+		id:  1,
+		uid: "1",
+		summary: &entity.EntitySummary{
+			Name: "API overview",
+			Nested: []*entity.EntitySummary{
+				{
+					Kind: "panel",
+					UID:  "1#1",
+					Name: "Requests",
+					Fields: map[string]string{
+						"type":    "timeseries",
+						"query.A": `sum(rate(http_requests_total{job="api"}[5m]))`,
+					},
+					References: []*entity.EntityExternalReference{
+						{Family: entity.StandardKindDataSource, Type: "prometheus", Identifier: "prom-1"},
+					},
+				},
+				{
+					Kind: "panel",
+					UID:  "1#2",
+					Name: "Logs",
+					Fields: map[string]string{
+						"type":    "logs",
+						"query.A": `{job="api"} |= "error"`,
+					},
+					References: []*entity.EntityExternalReference{
+						{Family: entity.StandardKindDataSource, Type: "loki", Identifier: "loki-1"},
+					},
+				},
+			},
+			References: []*entity.EntityExternalReference{
+				{Family: entity.StandardKindDataSource, Type: "prometheus", Identifier: "prom-1"},
+				{Family: entity.StandardKindDataSource, Type: "loki", Identifier: "loki-1"},
+			},
+		},
+	},
+}
+
+var alertRulesWithQueries = []alertRule{
+	{
+		uid:       "rule-1",
+		title:     "High error rate",
+		folderUID: "alerts",
+		ruleGroup: "api",
+		labels:    map[string]string{"team": "backend", "severity": "critical"},
+		queries: []alertRuleQuery{
+			{refID: "A", dsUID: "prom-1", dsType: "prometheus", text: `sum(rate(http_requests_total{job="api",code=~"5.."}[5m]))`},
+			{refID: "B", text: "$A > 10"},
+		},
+	},
+	{
+		uid:       "rule-2",
+		title:     "Orders stalled",
+		folderUID: "alerts",
+		ruleGroup: "shop",
+		labels:    map[string]string{"team": "shop"},
+		queries: []alertRuleQuery{
+			{refID: "A", dsUID: "mysql-1", dsType: "mysql", text: "SELECT count(*) FROM orders WHERE created_at > NOW() - INTERVAL 5 MINUTE"},
+		},
+	},
+}
+
+var libraryPanelsWithQueries = []libraryPanel{
+	{
+		uid:       "lib-1",
+		name:      "Node CPU",
+		folderUID: "shared",
+		panelType: "timeseries",
+		summary: &entity.EntitySummary{
+			Fields: map[string]string{
+				"type":    "timeseries",
+				"query.A": `avg by (instance) (rate(node_cpu_seconds_total{mode!="idle"}[5m]))`,
+			},
+			References: []*entity.EntityExternalReference{
+				{Family: entity.StandardKindDataSource, Type: "prometheus", Identifier: "prom-2"},
+			},
+		},
+	},
+}
+
+func searchResultUIDs(t *testing.T, resp *backend.DataResponse) []string {
+	t.Helper()
+	require.NoError(t, resp.Error)
+	uidField, idx := resp.Frames[0].FieldByName("uid")
+	require.NotEqual(t, -1, idx)
+	uids := make([]string, 0, uidField.Len())
+	for i := 0; i < uidField.Len(); i++ {
+		uids = append(uids, uidField.At(i).(string))
+	}
+	return uids
+}
+
+func TestDashboardIndex_QueryText(t *testing.T) {
+	index := initTestIndexFromLoader(t, &testDashboardLoader{
+		dashboards:    dashboardsWithQueries,
+		alertRules:    alertRulesWithQueries,
+		libraryPanels: libraryPanelsWithQueries,
+	}, &NoopDocumentExtender{}).perOrgIndex[testOrgID]
+
+	search := func(t *testing.T, q DashboardQuery) []string {
+		t.Helper()
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, q, &NoopQueryExtender{}, "")
+		return searchResultUIDs(t, resp)
+	}
+
+	t.Run("alert rules and library panels are indexed", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"rule-1", "rule-2"}, search(t, DashboardQuery{Kind: []string{string(entityKindAlertRule)}}))
+		require.ElementsMatch(t, []string{"lib-1"}, search(t, DashboardQuery{Kind: []string{string(entityKindLibraryPanel)}}))
+		require.ElementsMatch(t, []string{"rule-2"}, search(t, DashboardQuery{Query: "stalled"}))
+	})
+
+	t.Run("metric name matches dashboards, panels and alert rules", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"1", "1#1", "rule-1"}, search(t, DashboardQuery{QueryText: "http_requests_total"}))
+		require.ElementsMatch(t, []string{"rule-1"}, search(t, DashboardQuery{QueryText: "http_requests_total", Kind: []string{string(entityKindAlertRule)}}))
+	})
+
+	t.Run("all terms must match", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"1", "1#2"}, search(t, DashboardQuery{QueryText: "job error"}))
+	})
+
+	t.Run("phrase", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"rule-2"}, search(t, DashboardQuery{QueryText: `"from orders"`}))
+		require.Empty(t, search(t, DashboardQuery{QueryText: `"orders from"`}))
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"lib-1"}, search(t, DashboardQuery{QueryText: "node_cpu*"}))
+	})
+
+	t.Run("exclusion", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"1", "1#1"}, search(t, DashboardQuery{QueryText: "http_requests_total -code"}))
+		require.ElementsMatch(t, []string{"lib-1", "rule-2"}, search(t, DashboardQuery{QueryText: "-job", Kind: []string{string(entityKindPanel), string(entityKindAlertRule), string(entityKindLibraryPanel)}}))
+	})
+
+	t.Run("unterminated quote", func(t *testing.T) {
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, DashboardQuery{QueryText: `"rate`}, &NoopQueryExtender{}, "")
+		require.ErrorIs(t, resp.Error, errUnterminatedQuote)
+	})
+
+	t.Run("labels", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"rule-1", "rule-2"}, search(t, DashboardQuery{Labels: []string{"team"}}))
+		require.ElementsMatch(t, []string{"rule-1"}, search(t, DashboardQuery{Labels: []string{"team=backend"}}))
+	})
+
+	t.Run("datasource filters and facets", func(t *testing.T) {
+		require.ElementsMatch(t, []string{"1", "1#1", "rule-1"}, search(t, DashboardQuery{Datasource: "prom-1"}))
+		require.ElementsMatch(t, []string{"1", "1#1", "rule-1", "lib-1"}, search(t, DashboardQuery{DatasourceType: "prometheus"}))
+
+		resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter,
+			DashboardQuery{Facet: []FacetField{{Field: documentFieldDSType}}}, &NoopQueryExtender{}, "")
+		require.NoError(t, resp.Error)
+		require.Len(t, resp.Frames, 2)
+		facet := resp.Frames[1]
+		counts := map[string]uint64{}
+		for i := 0; i < facet.Rows(); i++ {
+			counts[facet.Fields[0].At(i).(string)] = facet.Fields[1].At(i).(uint64)
+		}
+		require.Equal(t, map[string]uint64{"prometheus": 4, "loki": 2, "mysql": 1}, counts)
+	})
+}
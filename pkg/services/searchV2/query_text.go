@@ -0,0 +1,101 @@
+package searchV2
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/blugelabs/bluge"
+)
+
+var errUnterminatedQuote = errors.New("query_text: unterminated quote")
+
+type queryTextToken struct {
+	value  string
+	phrase bool
+	negate bool
+}
+
+// newQueryTextQuery builds a query matching documents by the raw text of their
+// datasource queries (PromQL, LogQL, SQL, ...). The syntax is intentionally small:
+//
+//	http_requests_total job     every term must match
+//	"job api"                   quoted phrases must match in order
+//	-staging                    terms or phrases prefixed with - must not match
+//	node_*                      a trailing * matches any term with that prefix
+//
+// Terms are analyzed the same way as the indexed text, so punctuation like
+// braces, quotes and operators is ignored. Returns nil when there is nothing to match.
+func newQueryTextQuery(text string) (bluge.Query, error) {
+	tokens, err := tokenizeQueryText(text)
+	if err != nil {
+		return nil, err
+	}
+
+	bq := bluge.NewBooleanQuery()
+	hasMust := false
+	for _, t := range tokens {
+		var q bluge.Query
+		switch {
+		case t.phrase:
+			q = bluge.NewMatchPhraseQuery(t.value).SetField(documentFieldQuery)
+		case strings.HasSuffix(t.value, "*") && len(t.value) > 1:
+			q = bluge.NewWildcardQuery(strings.ToLower(t.value)).SetField(documentFieldQuery)
+		default:
+			q = bluge.NewMatchQuery(t.value).
+				SetField(documentFieldQuery).
+				SetOperator(bluge.MatchQueryOperatorAnd)
+		}
+
+		if t.negate {
+			bq.AddMustNot(q)
+			continue
+		}
+		bq.AddMust(q)
+		hasMust = true
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	// Exclusions alone should still only consider documents with queries.
+	if !hasMust {
+		bq.AddMust(bluge.NewWildcardQuery("*").SetField(documentFieldQuery))
+	}
+	return bq, nil
+}
+
+func tokenizeQueryText(text string) ([]queryTextToken, error) {
+	var tokens []queryTextToken
+	rest := strings.TrimSpace(text)
+	for rest != "" {
+		t := queryTextToken{}
+		if strings.HasPrefix(rest, "-") && len(rest) > 1 {
+			t.negate = true
+			rest = rest[1:]
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				return nil, errUnterminatedQuote
+			}
+			t.value = rest[1 : end+1]
+			t.phrase = true
+			rest = rest[end+2:]
+		} else {
+			end := strings.IndexAny(rest, " \t\n")
+			if end < 0 {
+				end = len(rest)
+			}
+			t.value = rest[:end]
+			rest = rest[end:]
+		}
+
+		if strings.TrimSpace(t.value) != "" {
+			tokens = append(tokens, t)
+		}
+		rest = strings.TrimSpace(rest)
+	}
+	return tokens, nil
+}
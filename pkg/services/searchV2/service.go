@@ -86,6 +86,11 @@ func ProvideService(cfg *setting.Cfg, sql db.DB, entityEventStore store.EntityEv
 	userService user.Service, folderService folder.Service) SearchService {
 	extender := &NoopExtender{}
 	logger := log.New("searchV2")
+	loader := newSQLDashboardLoader(sql, tracer, cfg.Search)
+	snapshots, err := newIndexSnapshotStore(cfg.Search.IndexSnapshotURL, logger)
+	if err != nil {
+		logger.Error("Index snapshots are disabled", "error", err)
+	}
 	s := &StandardSearchService{
 		cfg: cfg,
 		sql: sql,
@@ -97,8 +102,11 @@ func ProvideService(cfg *setting.Cfg, sql db.DB, entityEventStore store.EntityEv
 			logger:        logger,
 		},
 		dashboardIndex: newSearchIndex(
-			newSQLDashboardLoader(sql, tracer, cfg.Search),
+			loader,
+			loader,
+			loader,
 			entityEventStore,
+			snapshots,
 			extender.GetDocumentExtender(),
 			newFolderIDLookup(sql),
 			tracer,
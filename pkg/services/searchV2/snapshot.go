@@ -0,0 +1,307 @@
+package searchV2
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blugelabs/bluge"
+	"github.com/blugelabs/bluge/index"
+	segment "github.com/blugelabs/bluge_segment_api"
+	"gocloud.dev/blob"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	// indexSnapshotFormatVersion must be incremented whenever documents change in a way
+	// that makes older snapshots incompatible, so they get rebuilt from the database.
+	indexSnapshotFormatVersion = "1"
+
+	indexSnapshotPropertyVersion     = "format_version"
+	indexSnapshotPropertyLastEventID = "last_event_id"
+	indexSnapshotMimeType            = "application/gzip"
+)
+
+var errIndexSnapshotVersionMismatch = errors.New("index snapshot format version mismatch")
+
+// indexSnapshot is a persisted copy of an organization index together with the
+// ID of the last entity event applied to it.
+type indexSnapshot struct {
+	orgID       int64
+	lastEventID int64
+	created     time.Time
+	data        []byte // gzipped tar of the bluge index files
+}
+
+// indexSnapshotStore persists index snapshots so that a restarted or a new instance
+// can load them instead of rebuilding every organization index from the database.
+type indexSnapshotStore interface {
+	Save(ctx context.Context, snapshot *indexSnapshot) error
+	// Load returns nil when there is no snapshot for the organization.
+	Load(ctx context.Context, orgID int64) (*indexSnapshot, error)
+}
+
+type fileIndexSnapshotStore struct {
+	fs filestorage.FileStorage
+}
+
+func newIndexSnapshotStore(url string, logger log.Logger) (indexSnapshotStore, error) {
+	if url == "" {
+		return nil, nil
+	}
+	bucket, err := blob.OpenBucket(context.Background(), url)
+	if err != nil {
+		return nil, fmt.Errorf("can't open index snapshot storage: %w", err)
+	}
+	return &fileIndexSnapshotStore{
+		fs: filestorage.NewCdkBlobStorage(logger, bucket, "", nil),
+	}, nil
+}
+
+func indexSnapshotPath(orgID int64) string {
+	return filestorage.Join(fmt.Sprintf("org-%d.tar.gz", orgID))
+}
+
+func (s *fileIndexSnapshotStore) Save(ctx context.Context, snapshot *indexSnapshot) error {
+	return s.fs.Upsert(ctx, &filestorage.UpsertFileCommand{
+		Path:     indexSnapshotPath(snapshot.orgID),
+		MimeType: indexSnapshotMimeType,
+		Contents: snapshot.data,
+		Properties: map[string]string{
+			indexSnapshotPropertyVersion:     indexSnapshotFormatVersion,
+			indexSnapshotPropertyLastEventID: strconv.FormatInt(snapshot.lastEventID, 10),
+		},
+	})
+}
+
+func (s *fileIndexSnapshotStore) Load(ctx context.Context, orgID int64) (*indexSnapshot, error) {
+	file, found, err := s.fs.Get(ctx, indexSnapshotPath(orgID), &filestorage.GetFileOptions{WithContents: true})
+	if err != nil || !found {
+		return nil, err
+	}
+	if file.Properties[indexSnapshotPropertyVersion] != indexSnapshotFormatVersion {
+		return nil, errIndexSnapshotVersionMismatch
+	}
+	lastEventID, err := strconv.ParseInt(file.Properties[indexSnapshotPropertyLastEventID], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid last event ID in index snapshot: %w", err)
+	}
+	return &indexSnapshot{
+		orgID:       orgID,
+		lastEventID: lastEventID,
+		created:     file.Modified,
+		data:        file.Contents,
+	}, nil
+}
+
+// backupOrgIndex archives a point-in-time copy of the index.
+func backupOrgIndex(index *orgIndex) ([]byte, error) {
+	reader, cancel, err := index.readerForIndex(indexTypeDashboard)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	// Bluge only supports backups to a directory on disk.
+	tmpDir, err := os.MkdirTemp("", "grafana.search_snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmpDir) }()
+
+	if err := reader.Backup(tmpDir, make(chan struct{})); err != nil {
+		return nil, fmt.Errorf("can't backup index: %w", err)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		// nolint:gosec
+		// We can ignore the gosec G304 warning since the path is the temporary backup directory.
+		contents, err := os.ReadFile(filepath.Join(tmpDir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if err := tw.WriteHeader(&tar.Header{Name: entry.Name(), Mode: 0600, Size: int64(len(contents))}); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(contents); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// restoreOrgIndex opens an in-memory index from an archive created by backupOrgIndex.
+func restoreOrgIndex(data []byte) (*orgIndex, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = gz.Close() }()
+
+	dir := newSnapshotDirectory()
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		kind, id, err := parseIndexItemName(header.Name)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		dir.items[snapshotDirectoryKey{kind: kind, id: id}] = contents
+	}
+
+	if ids, _ := dir.List(index.ItemKindSnapshot); len(ids) == 0 {
+		return nil, errors.New("index snapshot does not contain an index")
+	}
+
+	writer, err := bluge.OpenWriter(bluge.DefaultConfigWithDirectory(func() index.Directory {
+		return dir
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("error opening writer: %w", err)
+	}
+	return &orgIndex{
+		writers: map[indexType]*bluge.Writer{
+			indexTypeDashboard: writer,
+		},
+	}, nil
+}
+
+// parseIndexItemName parses file names of bluge's file system directory, which
+// are made of a 12 hex digits ID followed by the item kind extension.
+func parseIndexItemName(name string) (string, uint64, error) {
+	ext := filepath.Ext(name)
+	if ext != index.ItemKindSegment && ext != index.ItemKindSnapshot {
+		return "", 0, fmt.Errorf("unexpected index snapshot item: %s", name)
+	}
+	id, err := strconv.ParseUint(strings.TrimSuffix(name, ext), 16, 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("unexpected index snapshot item: %s", name)
+	}
+	return ext, id, nil
+}
+
+type snapshotDirectoryKey struct {
+	kind string
+	id   uint64
+}
+
+// snapshotDirectory is an in-memory bluge directory. Unlike index.InMemoryDirectory
+// it also keeps snapshot items, which is required to open a writer on existing segments.
+type snapshotDirectory struct {
+	mu    sync.RWMutex
+	items map[snapshotDirectoryKey][]byte
+}
+
+var _ index.Directory = (*snapshotDirectory)(nil)
+
+func newSnapshotDirectory() *snapshotDirectory {
+	return &snapshotDirectory{items: map[snapshotDirectoryKey][]byte{}}
+}
+
+func (d *snapshotDirectory) Setup(_ bool) error {
+	return nil
+}
+
+func (d *snapshotDirectory) List(kind string) ([]uint64, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var ids []uint64
+	for k := range d.items {
+		if k.kind == kind {
+			ids = append(ids, k.id)
+		}
+	}
+	// Items are expected in descending order.
+	sort.Slice(ids, func(i, j int) bool { return ids[i] > ids[j] })
+	return ids, nil
+}
+
+func (d *snapshotDirectory) Load(kind string, id uint64) (*segment.Data, io.Closer, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	contents, ok := d.items[snapshotDirectoryKey{kind: kind, id: id}]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s %d not found", kind, id)
+	}
+	return segment.NewDataBytes(contents), nil, nil
+}
+
+func (d *snapshotDirectory) Persist(kind string, id uint64, w index.WriterTo, closeCh chan struct{}) error {
+	var buf bytes.Buffer
+	if _, err := w.WriteTo(&buf, closeCh); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.items[snapshotDirectoryKey{kind: kind, id: id}] = buf.Bytes()
+	return nil
+}
+
+func (d *snapshotDirectory) Remove(kind string, id uint64) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.items, snapshotDirectoryKey{kind: kind, id: id})
+	return nil
+}
+
+func (d *snapshotDirectory) Stats() (uint64, uint64) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	var numBytes uint64
+	for _, v := range d.items {
+		numBytes += uint64(len(v))
+	}
+	return uint64(len(d.items)), numBytes
+}
+
+func (d *snapshotDirectory) Sync() error {
+	return nil
+}
+
+func (d *snapshotDirectory) Lock() error {
+	return nil
+}
+
+func (d *snapshotDirectory) Unlock() error {
+	return nil
+}
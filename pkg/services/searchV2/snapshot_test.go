@@ -0,0 +1,165 @@
+package searchV2
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/store"
+	"github.com/grafana/grafana/pkg/services/store/entity"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func searchIndexUIDs(t *testing.T, index *orgIndex, q DashboardQuery) []string {
+	t.Helper()
+	resp := doSearchQuery(context.Background(), testLogger, index, testAllowAllFilter, q, &NoopQueryExtender{}, "")
+	return searchResultUIDs(t, resp)
+}
+
+func TestIndexSnapshot_BackupRestore(t *testing.T) {
+	index := initTestIndexFromLoader(t, &testDashboardLoader{
+		dashboards:    dashboardsWithQueries,
+		alertRules:    alertRulesWithQueries,
+		libraryPanels: libraryPanelsWithQueries,
+	}, &NoopDocumentExtender{})
+	orgIdx, ok := index.getOrgIndex(testOrgID)
+	require.True(t, ok)
+
+	data, err := backupOrgIndex(orgIdx)
+	require.NoError(t, err)
+
+	restored, err := restoreOrgIndex(data)
+	require.NoError(t, err)
+
+	for _, q := range []DashboardQuery{
+		{},
+		{Query: "overview"},
+		{QueryText: "http_requests_total"},
+		{Labels: []string{"team=backend"}},
+		{Kind: []string{string(entityKindPanel)}},
+	} {
+		require.ElementsMatch(t, searchIndexUIDs(t, orgIdx, q), searchIndexUIDs(t, restored, q))
+	}
+
+	// The restored index keeps accepting updates.
+	err = index.updateDashboard(context.Background(), testOrgID, restored, dashboard{
+		id:      2,
+		uid:     "2",
+		summary: &entity.EntitySummary{Name: "created after restore"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"2"}, searchIndexUIDs(t, restored, DashboardQuery{Query: "restore"}))
+	require.Empty(t, searchIndexUIDs(t, orgIdx, DashboardQuery{Query: "restore"}))
+
+	_, err = restoreOrgIndex([]byte("not a snapshot"))
+	require.Error(t, err)
+}
+
+func newTestIndexSnapshotStore(t *testing.T) *fileIndexSnapshotStore {
+	t.Helper()
+	snapshots, err := newIndexSnapshotStore("mem://", testLogger)
+	require.NoError(t, err)
+	return snapshots.(*fileIndexSnapshotStore)
+}
+
+func TestIndexSnapshotStore(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("disabled without url", func(t *testing.T) {
+		snapshots, err := newIndexSnapshotStore("", testLogger)
+		require.NoError(t, err)
+		require.Nil(t, snapshots)
+	})
+
+	t.Run("save and load", func(t *testing.T) {
+		snapshots := newTestIndexSnapshotStore(t)
+
+		snapshot, err := snapshots.Load(ctx, 1)
+		require.NoError(t, err)
+		require.Nil(t, snapshot)
+
+		err = snapshots.Save(ctx, &indexSnapshot{orgID: 1, lastEventID: 42, data: []byte("data")})
+		require.NoError(t, err)
+
+		snapshot, err = snapshots.Load(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, int64(42), snapshot.lastEventID)
+		require.Equal(t, []byte("data"), snapshot.data)
+		require.WithinDuration(t, time.Now(), snapshot.created, time.Minute)
+
+		snapshot, err = snapshots.Load(ctx, 2)
+		require.NoError(t, err)
+		require.Nil(t, snapshot)
+	})
+
+	t.Run("format version mismatch", func(t *testing.T) {
+		snapshots := newTestIndexSnapshotStore(t)
+		err := snapshots.fs.Upsert(ctx, &filestorage.UpsertFileCommand{
+			Path:       indexSnapshotPath(1),
+			Contents:   []byte("data"),
+			Properties: map[string]string{indexSnapshotPropertyVersion: "0", indexSnapshotPropertyLastEventID: "1"},
+		})
+		require.NoError(t, err)
+
+		_, err = snapshots.Load(ctx, 1)
+		require.ErrorIs(t, err, errIndexSnapshotVersionMismatch)
+	})
+}
+
+func TestSearchIndex_LoadOrgIndexSnapshot(t *testing.T) {
+	ctx := context.Background()
+	snapshots := newTestIndexSnapshotStore(t)
+
+	source := initTestIndexFromLoader(t, &testDashboardLoader{dashboards: testDashboards}, &NoopDocumentExtender{})
+	source.snapshots = snapshots
+	source.saveIndexSnapshots(ctx, 5)
+
+	newIndex := func(maxAge time.Duration) *searchIndex {
+		loader := &testDashboardLoader{}
+		return newSearchIndex(loader, loader, loader, &store.MockEntityEventsService{}, snapshots, &NoopDocumentExtender{},
+			func(ctx context.Context, folderId int64) (string, error) { return "x", nil },
+			tracing.InitializeTracerForTest(), featuremgmt.WithFeatures(), setting.SearchSettings{IndexSnapshotMaxAge: maxAge})
+	}
+
+	t.Run("loads snapshot", func(t *testing.T) {
+		index := newIndex(time.Hour)
+		eventID, ok := index.loadOrgIndexSnapshot(ctx, testOrgID, 10)
+		require.True(t, ok)
+		require.Equal(t, int64(5), eventID)
+
+		orgIdx, ok := index.getOrgIndex(testOrgID)
+		require.True(t, ok)
+		require.Equal(t, []string{"2"}, searchIndexUIDs(t, orgIdx, DashboardQuery{Query: "boom"}))
+		require.True(t, index.initializedOrgs[testOrgID])
+	})
+
+	t.Run("replays events from the oldest snapshot", func(t *testing.T) {
+		index := newIndex(time.Hour)
+		eventID, err := index.buildInitialIndexes(ctx, []int64{testOrgID, 2}, 10)
+		require.NoError(t, err)
+		require.Equal(t, int64(5), eventID)
+	})
+
+	t.Run("ignores missing snapshot", func(t *testing.T) {
+		index := newIndex(time.Hour)
+		_, ok := index.loadOrgIndexSnapshot(ctx, 2, 10)
+		require.False(t, ok)
+	})
+
+	t.Run("ignores snapshot ahead of events", func(t *testing.T) {
+		index := newIndex(time.Hour)
+		_, ok := index.loadOrgIndexSnapshot(ctx, testOrgID, 4)
+		require.False(t, ok)
+	})
+
+	t.Run("ignores outdated snapshot", func(t *testing.T) {
+		index := newIndex(time.Nanosecond)
+		_, ok := index.loadOrgIndexSnapshot(ctx, testOrgID, 10)
+		require.False(t, ok)
+	})
+}
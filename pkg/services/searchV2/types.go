@@ -23,6 +23,8 @@ type DashboardQuery struct {
 	Tags               []string     `json:"tags,omitempty"`
 	Kind               []string     `json:"kind,omitempty"`
 	PanelType          string       `json:"panel_type,omitempty"`
+	QueryText          string       `json:"query_text,omitempty"` // search within the raw text of datasource queries
+	Labels             []string     `json:"labels,omitempty"`     // alert rule labels as "key" or "key=value"
 	UIDs               []string     `json:"uid,omitempty"`
 	Explain            bool         `json:"explain,omitempty"`            // adds details on why document matched
 	WithAllowedActions bool         `json:"withAllowedActions,omitempty"` // adds allowed actions per entity
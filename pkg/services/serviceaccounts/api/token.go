@@ -8,6 +8,7 @@ import (
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/components/satokengen"
+	"github.com/grafana/grafana/pkg/services/audit"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	"github.com/grafana/grafana/pkg/web"
@@ -173,6 +174,11 @@ func (api *ServiceAccountsAPI) CreateToken(c *contextmodel.ReqContext) response.
 		return response.ErrOrFallback(http.StatusInternalServerError, "failed to add service account token", err)
 	}
 
+	api.audit.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionServiceAccountTokenCreate).
+		WithResource(audit.ResourceServiceAccountToken, strconv.FormatInt(apiKey.ID, 10), apiKey.Name).
+		WithDetail("serviceAccountId", saID).
+		WithDetail("secondsToLive", cmd.SecondsToLive))
+
 	result := &dtos.NewApiKeyResult{
 		ID:   apiKey.ID,
 		Name: apiKey.Name,
@@ -218,6 +224,10 @@ func (api *ServiceAccountsAPI) DeleteToken(c *contextmodel.ReqContext) response.
 		return response.ErrOrFallback(http.StatusInternalServerError, failedToDeleteMsg, err)
 	}
 
+	api.audit.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionServiceAccountTokenDelete).
+		WithResource(audit.ResourceServiceAccountToken, strconv.FormatInt(tokenID, 10), "").
+		WithDetail("serviceAccountId", saID))
+
 	return response.Success("Service account token deleted")
 }
 
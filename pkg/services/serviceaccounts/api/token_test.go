@@ -13,6 +13,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/apikey"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/audit/audittest"
 	"github.com/grafana/grafana/pkg/services/serviceaccounts"
 	satests "github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -109,12 +111,14 @@ func TestServiceAccountsAPI_CreateToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.desc, func(t *testing.T) {
+			auditService := &audittest.FakeService{}
 			server := setupTests(t, func(a *ServiceAccountsAPI) {
 				a.cfg.ApiKeyMaxSecondsToLive = tt.tokenTTL
 				a.service = &satests.FakeServiceAccountService{
 					ExpectedErr:    tt.expectedErr,
 					ExpectedAPIKey: tt.expectedAPIKey,
 				}
+				a.audit = auditService
 			})
 			req := server.NewRequest(http.MethodPost, fmt.Sprintf("/api/serviceaccounts/%d/tokens", tt.id), strings.NewReader(tt.body))
 			webtest.RequestWithSignedInUser(req, &user.SignedInUser{OrgID: 1, Permissions: map[int64]map[string][]string{1: accesscontrol.GroupScopesByActionContext(context.Background(), tt.permissions)}})
@@ -123,6 +127,15 @@ func TestServiceAccountsAPI_CreateToken(t *testing.T) {
 
 			assert.Equal(t, tt.expectedCode, res.StatusCode)
 			require.NoError(t, res.Body.Close())
+
+			if tt.expectedCode == http.StatusOK {
+				events := auditService.Events()
+				require.Len(t, events, 1)
+				assert.Equal(t, audit.ActionServiceAccountTokenCreate, events[0].Action)
+				assert.Equal(t, int64(1), events[0].OrgID)
+			} else {
+				assert.Empty(t, auditService.Events())
+			}
 		})
 	}
 }
@@ -0,0 +1,150 @@
+package workloadidentity
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/middleware"
+	"github.com/grafana/grafana/pkg/middleware/requestmeta"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/authn"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+type api struct {
+	service       *Service
+	accessControl accesscontrol.AccessControl
+	routeRegister routing.RouteRegister
+	audit         audit.Service
+}
+
+func newAPI(service *Service, accessControl accesscontrol.AccessControl, routeRegister routing.RouteRegister, auditService audit.Service) *api {
+	return &api{
+		service:       service,
+		accessControl: accessControl,
+		routeRegister: routeRegister,
+		audit:         auditService,
+	}
+}
+
+func (a *api) registerAPIEndpoints() {
+	authorize := accesscontrol.Middleware(a.accessControl)
+
+	a.routeRegister.Group("/api/serviceaccounts/:serviceAccountId/trust-policies", func(policyRoute routing.RouteRegister) {
+		policyRoute.Get("/", authorize(accesscontrol.EvalPermission(serviceaccounts.ActionRead, serviceaccounts.ScopeID)), routing.Wrap(a.listTrustPolicies))
+		policyRoute.Post("/", authorize(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(a.createTrustPolicy))
+		policyRoute.Delete("/:uid", authorize(accesscontrol.EvalPermission(serviceaccounts.ActionWrite, serviceaccounts.ScopeID)), routing.Wrap(a.deleteTrustPolicy))
+	}, middleware.ReqSignedIn, requestmeta.SetOwner(requestmeta.TeamAuth))
+
+	// workloads authenticate with the token they exchange
+	a.routeRegister.Post("/api/workload-identity/token", requestmeta.SetOwner(requestmeta.TeamAuth), routing.Wrap(a.exchangeToken))
+}
+
+func (a *api) listTrustPolicies(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	policies, err := a.service.ListTrustPolicies(c.Req.Context(), c.SignedInUser.GetOrgID(), saID)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to list trust policies", err)
+	}
+	return response.JSON(http.StatusOK, policies)
+}
+
+func (a *api) createTrustPolicy(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	cmd := CreateTrustPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.ServiceAccountID = saID
+
+	policy, err := a.service.CreateTrustPolicy(c.Req.Context(), &cmd)
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to create trust policy", err)
+	}
+
+	a.audit.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionServiceAccountTrustPolicyCreate).
+		WithResource(audit.ResourceServiceAccountTrustPolicy, policy.UID, policy.Name).
+		WithDetail("serviceAccountId", saID).
+		WithDetail("issuer", policy.Issuer).
+		WithDetail("subject", policy.Subject))
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+func (a *api) deleteTrustPolicy(c *contextmodel.ReqContext) response.Response {
+	saID, err := strconv.ParseInt(web.Params(c.Req)[":serviceAccountId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "Service Account ID is invalid", err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	err = a.service.DeleteTrustPolicy(c.Req.Context(), &DeleteTrustPolicyCommand{
+		OrgID:            c.SignedInUser.GetOrgID(),
+		ServiceAccountID: saID,
+		UID:              uid,
+	})
+	if err != nil {
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to delete trust policy", err)
+	}
+
+	a.audit.Log(c.Req.Context(), audit.NewEvent(c.Req, c.SignedInUser, audit.ActionServiceAccountTrustPolicyDelete).
+		WithResource(audit.ResourceServiceAccountTrustPolicy, uid, "").
+		WithDetail("serviceAccountId", saID))
+
+	return response.Success("Trust policy deleted")
+}
+
+// exchangeToken exchanges a workload token for a service account access token. It accepts the form
+// encoded request of RFC 8693 as well as JSON.
+func (a *api) exchangeToken(c *contextmodel.ReqContext) response.Response {
+	cmd := ExchangeTokenCommand{}
+	if mediaType, _, _ := mime.ParseMediaType(c.Req.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		cmd.GrantType = c.Req.PostFormValue("grant_type")
+		cmd.SubjectToken = c.Req.PostFormValue("subject_token")
+		cmd.TrustPolicy = c.Req.PostFormValue("trust_policy")
+		if orgID := c.Req.PostFormValue("org_id"); orgID != "" {
+			var err error
+			if cmd.OrgID, err = strconv.ParseInt(orgID, 10, 64); err != nil {
+				return response.Error(http.StatusBadRequest, "org_id is invalid", err)
+			}
+		}
+	} else if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	result, err := a.service.Exchange(c.Req.Context(), &cmd)
+	if err != nil {
+		a.audit.Log(c.Req.Context(), audit.NewEvent(c.Req, nil, audit.ActionServiceAccountTokenExchange).WithError(err))
+		return response.ErrOrFallback(http.StatusInternalServerError, "Failed to exchange token", err)
+	}
+
+	event := audit.NewEvent(c.Req, nil, audit.ActionServiceAccountTokenExchange).
+		WithResource(audit.ResourceServiceAccountTrustPolicy, result.Policy.UID, result.Policy.Name).
+		WithDetail("subject", result.Subject)
+	event.OrgID = result.Policy.OrgID
+	event.Actor = audit.Actor{
+		Type:       string(authn.NamespaceServiceAccount),
+		ID:         strconv.FormatInt(result.Policy.ServiceAccountID, 10),
+		Login:      result.ServiceAccountLogin,
+		AuthModule: login.WorkloadIdentityModule,
+	}
+	a.audit.Log(c.Req.Context(), event)
+
+	return response.JSON(http.StatusOK, result.Token).SetHeader("Cache-Control", "no-store")
+}
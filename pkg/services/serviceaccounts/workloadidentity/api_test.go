@@ -0,0 +1,155 @@
+package workloadidentity
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/audit"
+	"github.com/grafana/grafana/pkg/services/audit/audittest"
+	"github.com/grafana/grafana/pkg/services/authz/zanzana"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func setupTestServer(t *testing.T, service *Service, auditService audit.Service) *webtest.Server {
+	t.Helper()
+
+	router := routing.NewRouteRegister()
+	newAPI(service, acimpl.ProvideAccessControl(featuremgmt.WithFeatures(), zanzana.NewNoopClient()), router, auditService).registerAPIEndpoints()
+	return webtest.NewServer(t, router)
+}
+
+func userWithPermissions(permissions []accesscontrol.Permission) *user.SignedInUser {
+	return &user.SignedInUser{UserID: 1, OrgID: 1, Login: "admin", Permissions: map[int64]map[string][]string{
+		1: accesscontrol.GroupScopesByActionContext(context.Background(), permissions),
+	}}
+}
+
+func TestAPI_CreateTrustPolicy(t *testing.T) {
+	tests := []struct {
+		desc         string
+		permissions  []accesscontrol.Permission
+		body         string
+		expectedCode int
+	}{
+		{
+			desc:         "should create trust policy",
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:2"}},
+			body:         `{"name": "CI", "issuer": "https://token.actions.example.com", "jwksUrl": "https://token.actions.example.com/jwks", "subject": "repo:grafana/grafana:ref:refs/heads/*", "audience": "grafana"}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			desc:         "should require permission to write the service account",
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:3"}},
+			body:         `{"name": "CI", "issuer": "https://token.actions.example.com", "jwksUrl": "https://token.actions.example.com/jwks", "subject": "repo:grafana/grafana:ref:refs/heads/*", "audience": "grafana"}`,
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			desc:         "should reject invalid trust policy",
+			permissions:  []accesscontrol.Permission{{Action: serviceaccounts.ActionWrite, Scope: "serviceaccounts:id:2"}},
+			body:         `{"name": "CI", "issuer": "https://token.actions.example.com", "jwksUrl": "https://token.actions.example.com/jwks", "subject": "*", "audience": "grafana"}`,
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			auditService := &audittest.FakeService{}
+			server := setupTestServer(t, newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1}), auditService)
+
+			req := server.NewPostRequest("/api/serviceaccounts/2/trust-policies", strings.NewReader(tt.body))
+			res, err := server.SendJSON(webtest.RequestWithSignedInUser(req, userWithPermissions(tt.permissions)))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, res.StatusCode)
+
+			if tt.expectedCode == http.StatusOK {
+				var policy TrustPolicy
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&policy))
+				assert.Equal(t, int64(2), policy.ServiceAccountID)
+
+				events := auditService.Events()
+				require.Len(t, events, 1)
+				assert.Equal(t, audit.ActionServiceAccountTrustPolicyCreate, events[0].Action)
+				assert.Equal(t, policy.UID, events[0].Resource.ID)
+			}
+			require.NoError(t, res.Body.Close())
+		})
+	}
+}
+
+func TestAPI_ExchangeToken(t *testing.T) {
+	w := newWorkload(t)
+
+	t.Run("should exchange form encoded request", func(t *testing.T) {
+		service := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1, Login: "sa-ci"})
+		policy := createPolicy(t, service, w, testSubject)
+		auditService := &audittest.FakeService{}
+		server := setupTestServer(t, service, auditService)
+
+		form := url.Values{
+			"grant_type":         {TokenExchangeGrantType},
+			"subject_token":      {w.token(t, validClaims())},
+			"subject_token_type": {"urn:ietf:params:oauth:token-type:id_token"},
+			"org_id":             {"1"},
+		}
+		req := server.NewPostRequest("/api/workload-identity/token", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		res, err := server.Send(req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "no-store", res.Header.Get("Cache-Control"))
+
+		var token AccessToken
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&token))
+		require.NoError(t, res.Body.Close())
+		assert.True(t, strings.HasPrefix(token.AccessToken, TokenPrefix))
+		assert.Equal(t, AccessTokenType, token.IssuedTokenType)
+
+		events := auditService.Events()
+		require.Len(t, events, 1)
+		assert.Equal(t, audit.ActionServiceAccountTokenExchange, events[0].Action)
+		assert.Equal(t, audit.Actor{Type: "service-account", ID: "2", Login: "sa-ci", AuthModule: login.WorkloadIdentityModule}, events[0].Actor)
+		assert.Equal(t, policy.UID, events[0].Resource.ID)
+		assert.Equal(t, testSubject, events[0].Details["subject"])
+	})
+
+	t.Run("should reject tokens without matching trust policy", func(t *testing.T) {
+		auditService := &audittest.FakeService{}
+		server := setupTestServer(t, newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1}), auditService)
+
+		req := server.NewPostRequest("/api/workload-identity/token", strings.NewReader(`{"org_id": 1, "subject_token": "`+w.token(t, validClaims())+`"}`))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		events := auditService.Events()
+		require.Len(t, events, 1)
+		assert.Equal(t, audit.ResultFailure, events[0].Result)
+	})
+
+	t.Run("should reject requests without org", func(t *testing.T) {
+		service := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		createPolicy(t, service, w, testSubject)
+		server := setupTestServer(t, service, &audittest.FakeService{})
+
+		req := server.NewPostRequest("/api/workload-identity/token", strings.NewReader(`{"subject_token": "`+w.token(t, validClaims())+`"}`))
+		res, err := server.SendJSON(req)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
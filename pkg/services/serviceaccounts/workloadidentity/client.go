@@ -0,0 +1,69 @@
+package workloadidentity
+
+import (
+	"context"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+)
+
+const bearerPrefix = "Bearer "
+
+var _ authn.ContextAwareClient = new(Client)
+
+func ProvideClient(service *Service) *Client {
+	return &Client{service}
+}
+
+// Client authenticates requests with the access tokens issued in exchange for workload tokens.
+type Client struct {
+	service *Service
+}
+
+func (c *Client) Name() string {
+	return authn.ClientWorkloadIdentity
+}
+
+func (c *Client) Authenticate(ctx context.Context, r *authn.Request) (*authn.Identity, error) {
+	claims, err := c.service.VerifyAccessToken(ctx, getTokenFromRequest(r))
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := authn.ParseNamespaceID(claims.Subject)
+	if err != nil {
+		return nil, ErrInvalidAccessToken.Errorf("invalid subject: %w", err)
+	}
+
+	return &authn.Identity{
+		ID:              id,
+		OrgID:           claims.OrgID,
+		AuthenticatedBy: login.WorkloadIdentityModule,
+		ClientParams:    authn.ClientParams{FetchSyncedUser: true, SyncPermissions: true},
+	}, nil
+}
+
+func (c *Client) IsEnabled() bool {
+	return c.service.IsEnabled()
+}
+
+func (c *Client) Test(ctx context.Context, r *authn.Request) bool {
+	return strings.HasPrefix(getTokenFromRequest(r), TokenPrefix)
+}
+
+// Priority is higher than the one of the api key client that accepts any bearer token.
+func (c *Client) Priority() uint {
+	return 25
+}
+
+func getTokenFromRequest(r *authn.Request) string {
+	if r.HTTPRequest == nil {
+		return ""
+	}
+	token, ok := strings.CutPrefix(r.HTTPRequest.Header.Get("Authorization"), bearerPrefix)
+	if !ok {
+		return ""
+	}
+	return token
+}
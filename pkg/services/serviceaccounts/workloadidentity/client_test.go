@@ -0,0 +1,55 @@
+package workloadidentity
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+)
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+	w := newWorkload(t)
+	s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+	createPolicy(t, s, w, testSubject)
+	client := ProvideClient(s)
+
+	result, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+	require.NoError(t, err)
+
+	newRequest := func(authorization string) *authn.Request {
+		req, err := http.NewRequest(http.MethodGet, "/api/dashboards/uid/abc", nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", authorization)
+		return &authn.Request{HTTPRequest: req}
+	}
+
+	t.Run("should only handle workload identity access tokens", func(t *testing.T) {
+		assert.True(t, client.Test(ctx, newRequest("Bearer "+result.Token.AccessToken)))
+		assert.False(t, client.Test(ctx, newRequest("Bearer glsa_token")))
+		assert.False(t, client.Test(ctx, newRequest(result.Token.AccessToken)))
+		assert.False(t, client.Test(ctx, &authn.Request{}))
+	})
+
+	t.Run("should authenticate as service account", func(t *testing.T) {
+		identity, err := client.Authenticate(ctx, newRequest("Bearer "+result.Token.AccessToken))
+		require.NoError(t, err)
+
+		assert.Equal(t, authn.MustParseNamespaceID("service-account:2"), identity.ID)
+		assert.Equal(t, int64(1), identity.OrgID)
+		assert.Equal(t, login.WorkloadIdentityModule, identity.AuthenticatedBy)
+		assert.True(t, identity.ClientParams.FetchSyncedUser)
+		assert.True(t, identity.ClientParams.SyncPermissions)
+	})
+
+	t.Run("should fail for invalid tokens", func(t *testing.T) {
+		_, err := client.Authenticate(ctx, newRequest("Bearer "+TokenPrefix+"invalid"))
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+}
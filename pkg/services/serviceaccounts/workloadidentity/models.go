@@ -0,0 +1,87 @@
+package workloadidentity
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/apimachinery/errutil"
+)
+
+const (
+	invalidTrustPolicyMessage = `Invalid trust policy: {{ .Public.reason }}`
+	invalidRequestMessage     = `Invalid token exchange request: {{ .Public.reason }}`
+)
+
+var (
+	ErrTrustPolicyNotFound = errutil.NotFound("workloadidentity.not-found", errutil.WithPublicMessage("Trust policy not found"))
+	ErrInvalidToken        = errutil.Unauthorized("workloadidentity.invalid-token", errutil.WithPublicMessage("Failed to verify workload token"))
+	ErrAmbiguousToken      = errutil.BadRequest("workloadidentity.ambiguous-token",
+		errutil.WithPublicMessage("Token matches more than one trust policy, specify the trust policy to use"))
+	ErrInvalidAccessToken = errutil.Unauthorized("workloadidentity.invalid-access-token", errutil.WithPublicMessage("Invalid access token"))
+
+	ErrInvalidTrustPolicy = errutil.BadRequest("workloadidentity.invalid-policy").
+				MustTemplate(invalidTrustPolicyMessage, errutil.WithPublic(invalidTrustPolicyMessage))
+	ErrInvalidRequest = errutil.BadRequest("workloadidentity.invalid-request").
+				MustTemplate(invalidRequestMessage, errutil.WithPublic(invalidRequestMessage))
+)
+
+func reasonData(reason string) errutil.TemplateData {
+	return errutil.TemplateData{Public: map[string]any{"reason": reason}}
+}
+
+// TrustPolicy allows workloads that present an OIDC token of Issuer, signed by a key of JWKSURL,
+// to act as the service account.
+type TrustPolicy struct {
+	ID               int64  `json:"-" xorm:"pk autoincr 'id'"`
+	UID              string `json:"uid" xorm:"uid"`
+	OrgID            int64  `json:"orgId" xorm:"org_id"`
+	ServiceAccountID int64  `json:"serviceAccountId" xorm:"service_account_id"`
+	Name             string `json:"name" xorm:"name"`
+	Issuer           string `json:"issuer" xorm:"issuer"`
+	JWKSURL          string `json:"jwksUrl" xorm:"jwks_url"`
+	// Subject the sub claim has to be equal to. A trailing * matches any subject with the same prefix.
+	Subject string `json:"subject" xorm:"subject"`
+	// Audience the aud claim has to contain.
+	Audience string    `json:"audience" xorm:"audience"`
+	Created  time.Time `json:"created" xorm:"'created'"`
+	Updated  time.Time `json:"updated" xorm:"'updated'"`
+}
+
+func (TrustPolicy) TableName() string {
+	return "service_account_trust_policy"
+}
+
+type CreateTrustPolicyCommand struct {
+	OrgID            int64  `json:"-"`
+	ServiceAccountID int64  `json:"-"`
+	Name             string `json:"name"`
+	Issuer           string `json:"issuer"`
+	JWKSURL          string `json:"jwksUrl"`
+	Subject          string `json:"subject"`
+	Audience         string `json:"audience"`
+}
+
+type DeleteTrustPolicyCommand struct {
+	OrgID            int64
+	ServiceAccountID int64
+	UID              string
+}
+
+// ExchangeTokenCommand follows the OAuth 2.0 token exchange request of RFC 8693.
+type ExchangeTokenCommand struct {
+	GrantType    string `json:"grant_type"`
+	SubjectToken string `json:"subject_token"`
+	// OrgID is the org whose trust policies the token is verified with. It can be omitted when
+	// TrustPolicy is set.
+	OrgID int64 `json:"org_id"`
+	// TrustPolicy is the uid of the trust policy to use, only required when the token matches
+	// more than one policy of the org.
+	TrustPolicy string `json:"trust_policy"`
+}
+
+// AccessToken follows the OAuth 2.0 token exchange response of RFC 8693.
+type AccessToken struct {
+	AccessToken     string `json:"access_token"`
+	IssuedTokenType string `json:"issued_token_type"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+}
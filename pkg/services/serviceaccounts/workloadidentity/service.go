@@ -0,0 +1,394 @@
+package workloadidentity
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+
+	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/audit"
+	authjwt "github.com/grafana/grafana/pkg/services/auth/jwt"
+	"github.com/grafana/grafana/pkg/services/authn"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	"github.com/grafana/grafana/pkg/services/signingkeys"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// TokenPrefix is prepended to issued access tokens so that the authn client can tell
+	// them apart from service account tokens and other JWTs.
+	TokenPrefix = "glwi_"
+
+	// TokenExchangeGrantType and AccessTokenType are the grant and token types of RFC 8693.
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	AccessTokenType        = "urn:ietf:params:oauth:token-type:access_token"
+
+	signingKeyPrefix    = "workload-identity"
+	accessTokenAudience = "grafana-workload-identity"
+	headerKeyID         = "kid"
+
+	// subjectSeparators separate the path segments of a subject, a wildcard matches a single segment.
+	subjectSeparators = "/:"
+)
+
+// AccessTokenClaims are the claims of the access tokens issued in exchange for workload tokens.
+// The subject is the namespaced id of the service account.
+type AccessTokenClaims struct {
+	jwt.Claims
+	OrgID       int64  `json:"orgId"`
+	TrustPolicy string `json:"trustPolicy"`
+}
+
+// ExchangeResult is the outcome of a successful token exchange.
+type ExchangeResult struct {
+	Token  *AccessToken
+	Policy *TrustPolicy
+	// Subject is the sub claim of the exchanged workload token.
+	Subject             string
+	ServiceAccountLogin string
+}
+
+type Service struct {
+	cfg             *setting.Cfg
+	log             log.Logger
+	store           store
+	remoteCache     *remotecache.RemoteCache
+	serviceAccounts serviceaccounts.Service
+	signingKeys     signingkeys.Service
+	now             func() time.Time
+}
+
+func ProvideService(
+	cfg *setting.Cfg, sqlStore db.DB, remoteCache *remotecache.RemoteCache, serviceAccountsService serviceaccounts.Service,
+	signingKeysService signingkeys.Service, authnService authn.Service, accessControl accesscontrol.AccessControl,
+	routeRegister routing.RouteRegister, auditService audit.Service,
+) *Service {
+	s := newService(cfg, &xormStore{db: sqlStore}, remoteCache, serviceAccountsService, signingKeysService)
+
+	if cfg.WorkloadIdentity.Enabled {
+		authnService.RegisterClient(ProvideClient(s))
+		newAPI(s, accessControl, routeRegister, auditService).registerAPIEndpoints()
+	}
+
+	return s
+}
+
+func newService(
+	cfg *setting.Cfg, store store, remoteCache *remotecache.RemoteCache, serviceAccountsService serviceaccounts.Service,
+	signingKeysService signingkeys.Service,
+) *Service {
+	return &Service{
+		cfg:             cfg,
+		log:             log.New("workloadidentity"),
+		store:           store,
+		remoteCache:     remoteCache,
+		serviceAccounts: serviceAccountsService,
+		signingKeys:     signingKeysService,
+		now:             time.Now,
+	}
+}
+
+func (s *Service) IsEnabled() bool {
+	return s.cfg.WorkloadIdentity.Enabled
+}
+
+func (s *Service) CreateTrustPolicy(ctx context.Context, cmd *CreateTrustPolicyCommand) (*TrustPolicy, error) {
+	if err := s.validate(cmd); err != nil {
+		return nil, err
+	}
+
+	// make sure the service account exists in the org
+	if _, err := s.serviceAccounts.RetrieveServiceAccount(ctx, cmd.OrgID, cmd.ServiceAccountID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	policy := &TrustPolicy{
+		UID:              util.GenerateShortUID(),
+		OrgID:            cmd.OrgID,
+		ServiceAccountID: cmd.ServiceAccountID,
+		Name:             cmd.Name,
+		Issuer:           cmd.Issuer,
+		JWKSURL:          cmd.JWKSURL,
+		Subject:          cmd.Subject,
+		Audience:         cmd.Audience,
+		Created:          now,
+		Updated:          now,
+	}
+	if err := s.store.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *Service) ListTrustPolicies(ctx context.Context, orgID, serviceAccountID int64) ([]*TrustPolicy, error) {
+	return s.store.List(ctx, orgID, serviceAccountID)
+}
+
+// DeleteTrustPolicy deletes the policy, access tokens issued with it are no longer accepted.
+func (s *Service) DeleteTrustPolicy(ctx context.Context, cmd *DeleteTrustPolicyCommand) error {
+	return s.store.Delete(ctx, cmd)
+}
+
+func (s *Service) validate(cmd *CreateTrustPolicyCommand) error {
+	if cmd.Name == "" || cmd.Issuer == "" || cmd.JWKSURL == "" || cmd.Subject == "" || cmd.Audience == "" {
+		return ErrInvalidTrustPolicy.Build(reasonData("name, issuer, jwksUrl, subject and audience are required"))
+	}
+	if err := validateSubject(cmd.Subject); err != nil {
+		return err
+	}
+
+	jwksURL, err := url.Parse(cmd.JWKSURL)
+	if err != nil || jwksURL.Host == "" {
+		return ErrInvalidTrustPolicy.Build(reasonData("jwksUrl is not a valid URL"))
+	}
+	// plain http is only allowed for local key sets during development
+	if jwksURL.Scheme != "https" && (jwksURL.Scheme != "http" || s.cfg.Env != setting.Dev) {
+		return ErrInvalidTrustPolicy.Build(reasonData("jwksUrl must use https"))
+	}
+	return nil
+}
+
+// validateSubject only allows a wildcard in place of the last path segment of the subject, such as
+// repo:grafana/grafana:ref:refs/heads/*, so that a policy can't match every subject of an issuer.
+func validateSubject(subject string) error {
+	if !strings.Contains(subject, "*") {
+		return nil
+	}
+	prefix, ok := strings.CutSuffix(subject, "*")
+	if !ok || strings.Contains(prefix, "*") {
+		return ErrInvalidTrustPolicy.Build(reasonData("subject may only end with a wildcard"))
+	}
+	segments := strings.FieldsFunc(prefix, func(r rune) bool { return strings.ContainsRune(subjectSeparators, r) })
+	if len(segments) == 0 || !strings.ContainsAny(prefix[len(prefix)-1:], subjectSeparators) {
+		return ErrInvalidTrustPolicy.Build(reasonData("subject wildcard has to follow a / or : and can only match a single path segment"))
+	}
+	return nil
+}
+
+// Exchange verifies the workload token against the trust policies of its issuer in the requested
+// org, or against the requested trust policy, and returns an access token for the service account
+// of the matching policy.
+func (s *Service) Exchange(ctx context.Context, cmd *ExchangeTokenCommand) (*ExchangeResult, error) {
+	if cmd.GrantType != "" && cmd.GrantType != TokenExchangeGrantType {
+		return nil, ErrInvalidRequest.Build(reasonData("unsupported grant_type"))
+	}
+	if cmd.SubjectToken == "" {
+		return nil, ErrInvalidRequest.Build(reasonData("subject_token is required"))
+	}
+
+	token, err := jwt.ParseSigned(cmd.SubjectToken)
+	if err != nil {
+		return nil, ErrInvalidToken.Errorf("failed to parse token: %w", err)
+	}
+
+	// the issuer is only used to find the policies the token has to be verified with
+	var unverified jwt.Claims
+	if err := token.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return nil, ErrInvalidToken.Errorf("failed to read token claims: %w", err)
+	}
+	if unverified.Issuer == "" {
+		return nil, ErrInvalidToken.Errorf("token has no issuer")
+	}
+
+	policies, err := s.listExchangePolicies(ctx, cmd, unverified.Issuer)
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []*TrustPolicy
+	var subject string
+	for _, policy := range policies {
+		sub, err := s.verifyWorkloadToken(ctx, cmd.SubjectToken, policy)
+		if err != nil {
+			s.log.Debug("Token does not match trust policy", "policy", policy.UID, "error", err)
+			continue
+		}
+		matched = append(matched, policy)
+		subject = sub
+	}
+
+	switch len(matched) {
+	case 0:
+		return nil, ErrInvalidToken.Errorf("token does not match a trust policy of issuer %s", unverified.Issuer)
+	case 1:
+	default:
+		return nil, ErrAmbiguousToken.Errorf("token matches %d trust policies", len(matched))
+	}
+
+	policy := matched[0]
+	serviceAccount, err := s.serviceAccounts.RetrieveServiceAccount(ctx, policy.OrgID, policy.ServiceAccountID)
+	if err != nil {
+		if errors.Is(err, serviceaccounts.ErrServiceAccountNotFound) {
+			return nil, ErrInvalidToken.Errorf("service account %d of trust policy %s not found", policy.ServiceAccountID, policy.UID)
+		}
+		return nil, err
+	}
+	if serviceAccount.IsDisabled {
+		return nil, ErrInvalidToken.Errorf("service account %d is disabled", policy.ServiceAccountID)
+	}
+
+	accessToken, err := s.issueAccessToken(ctx, policy)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExchangeResult{
+		Token: &AccessToken{
+			AccessToken:     accessToken,
+			IssuedTokenType: AccessTokenType,
+			TokenType:       "Bearer",
+			ExpiresIn:       int64(s.cfg.WorkloadIdentity.TokenLifetime.Seconds()),
+		},
+		Policy:              policy,
+		Subject:             subject,
+		ServiceAccountLogin: serviceAccount.Login,
+	}, nil
+}
+
+// listExchangePolicies returns the requested trust policy, or the policies of the issuer in the
+// requested org. Policies of other orgs are never considered, so they can't make a token ambiguous.
+func (s *Service) listExchangePolicies(ctx context.Context, cmd *ExchangeTokenCommand, issuer string) ([]*TrustPolicy, error) {
+	if cmd.TrustPolicy != "" {
+		policy, err := s.store.Get(ctx, cmd.TrustPolicy)
+		if err != nil {
+			if errors.Is(err, ErrTrustPolicyNotFound) {
+				return nil, ErrInvalidToken.Errorf("trust policy %s not found", cmd.TrustPolicy)
+			}
+			return nil, err
+		}
+		if policy.Issuer != issuer || (cmd.OrgID != 0 && policy.OrgID != cmd.OrgID) {
+			return nil, nil
+		}
+		return []*TrustPolicy{policy}, nil
+	}
+
+	if cmd.OrgID == 0 {
+		return nil, ErrInvalidRequest.Build(reasonData("org_id or trust_policy is required"))
+	}
+	return s.store.ListByIssuer(ctx, cmd.OrgID, issuer)
+}
+
+// verifyWorkloadToken verifies the token with the key set and claims of the policy and returns the subject.
+func (s *Service) verifyWorkloadToken(ctx context.Context, token string, policy *TrustPolicy) (string, error) {
+	verifier, err := authjwt.NewKeySetURLVerifier(s.cfg, s.remoteCache, policy.JWKSURL, s.cfg.WorkloadIdentity.JWKSCacheTTL,
+		jwt.Expected{Issuer: policy.Issuer, Audience: jwt.Audience{policy.Audience}})
+	if err != nil {
+		return "", err
+	}
+
+	claims, err := verifier.Verify(ctx, token)
+	if err != nil {
+		return "", err
+	}
+	if claims["exp"] == nil {
+		return "", errors.New("token has no expiry")
+	}
+	subject, _ := claims["sub"].(string)
+	if !matchSubject(policy.Subject, subject) {
+		return "", errors.New("subject does not match")
+	}
+	return subject, nil
+}
+
+// matchSubject matches the subject exactly, or a trailing wildcard with a single non-empty path segment.
+func matchSubject(pattern, subject string) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		rest, ok := strings.CutPrefix(subject, prefix)
+		return ok && rest != "" && !strings.ContainsAny(rest, subjectSeparators)
+	}
+	return pattern == subject
+}
+
+func (s *Service) issueAccessToken(ctx context.Context, policy *TrustPolicy) (string, error) {
+	keyID, key, err := s.signingKeys.GetOrCreatePrivateKey(ctx, signingKeyPrefix, jose.ES256)
+	if err != nil {
+		return "", err
+	}
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key}, &jose.SignerOptions{
+		ExtraHeaders: map[jose.HeaderKey]any{
+			headerKeyID:     keyID,
+			jose.HeaderType: "jwt",
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	now := s.now()
+	claims := AccessTokenClaims{
+		Claims: jwt.Claims{
+			ID:        util.GenerateShortUID(),
+			Issuer:    s.cfg.AppURL,
+			Subject:   authn.NewNamespaceID(authn.NamespaceServiceAccount, policy.ServiceAccountID).String(),
+			Audience:  jwt.Audience{accessTokenAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(s.cfg.WorkloadIdentity.TokenLifetime)),
+		},
+		OrgID:       policy.OrgID,
+		TrustPolicy: policy.UID,
+	}
+
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		return "", err
+	}
+	return TokenPrefix + token, nil
+}
+
+// VerifyAccessToken verifies an access token issued by Exchange and returns the claims. Tokens
+// of deleted trust policies are rejected.
+func (s *Service) VerifyAccessToken(ctx context.Context, accessToken string) (*AccessTokenClaims, error) {
+	raw, ok := strings.CutPrefix(accessToken, TokenPrefix)
+	if !ok {
+		return nil, ErrInvalidAccessToken.Errorf("missing token prefix")
+	}
+
+	token, err := jwt.ParseSigned(raw)
+	if err != nil {
+		return nil, ErrInvalidAccessToken.Errorf("failed to parse token: %w", err)
+	}
+
+	jwks, err := s.signingKeys.GetJWKS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := jwks.Key(token.Headers[0].KeyID)
+	if len(keys) == 0 {
+		return nil, ErrInvalidAccessToken.Errorf("signing key %q not found", token.Headers[0].KeyID)
+	}
+
+	var claims AccessTokenClaims
+	if err := token.Claims(keys[0], &claims); err != nil {
+		return nil, ErrInvalidAccessToken.Errorf("failed to verify token: %w", err)
+	}
+	expected := jwt.Expected{Issuer: s.cfg.AppURL, Audience: jwt.Audience{accessTokenAudience}, Time: s.now()}
+	if err := claims.ValidateWithLeeway(expected, jwt.DefaultLeeway); err != nil {
+		return nil, ErrInvalidAccessToken.Errorf("invalid claims: %w", err)
+	}
+
+	policy, err := s.store.Get(ctx, claims.TrustPolicy)
+	if err != nil {
+		if errors.Is(err, ErrTrustPolicyNotFound) {
+			return nil, ErrInvalidAccessToken.Errorf("trust policy %s was deleted", claims.TrustPolicy)
+		}
+		return nil, err
+	}
+	if policy.OrgID != claims.OrgID ||
+		claims.Subject != authn.NewNamespaceID(authn.NamespaceServiceAccount, policy.ServiceAccountID).String() {
+		return nil, ErrInvalidAccessToken.Errorf("token does not match trust policy %s", policy.UID)
+	}
+	return &claims, nil
+}
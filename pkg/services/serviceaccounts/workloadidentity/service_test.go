@@ -0,0 +1,444 @@
+package workloadidentity
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/services/serviceaccounts"
+	satests "github.com/grafana/grafana/pkg/services/serviceaccounts/tests"
+	"github.com/grafana/grafana/pkg/services/signingkeys/signingkeystest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+const (
+	testIssuer   = "https://token.actions.example.com"
+	testAudience = "https://grafana.example.com"
+	testSubject  = "repo:grafana/grafana:ref:refs/heads/main"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	policies []*TrustPolicy
+}
+
+func (f *fakeStore) Create(_ context.Context, policy *TrustPolicy) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	policy.ID = int64(len(f.policies) + 1)
+	f.policies = append(f.policies, policy)
+	return nil
+}
+
+func (f *fakeStore) Delete(_ context.Context, cmd *DeleteTrustPolicyCommand) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, policy := range f.policies {
+		if policy.OrgID == cmd.OrgID && policy.ServiceAccountID == cmd.ServiceAccountID && policy.UID == cmd.UID {
+			f.policies = append(f.policies[:i], f.policies[i+1:]...)
+			return nil
+		}
+	}
+	return ErrTrustPolicyNotFound.Errorf("not found")
+}
+
+func (f *fakeStore) Get(_ context.Context, uid string) (*TrustPolicy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, policy := range f.policies {
+		if policy.UID == uid {
+			return policy, nil
+		}
+	}
+	return nil, ErrTrustPolicyNotFound.Errorf("not found")
+}
+
+func (f *fakeStore) List(_ context.Context, orgID, serviceAccountID int64) ([]*TrustPolicy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var policies []*TrustPolicy
+	for _, policy := range f.policies {
+		if policy.OrgID == orgID && policy.ServiceAccountID == serviceAccountID {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+func (f *fakeStore) ListByIssuer(_ context.Context, orgID int64, issuer string) ([]*TrustPolicy, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var policies []*TrustPolicy
+	for _, policy := range f.policies {
+		if policy.OrgID == orgID && policy.Issuer == issuer {
+			policies = append(policies, policy)
+		}
+	}
+	return policies, nil
+}
+
+// workload is an OIDC issuer of a CI platform that serves its keys from a local JWKS endpoint.
+type workload struct {
+	key      *rsa.PrivateKey
+	server   *httptest.Server
+	requests atomic.Int32
+}
+
+func newWorkload(t *testing.T) *workload {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	w := &workload{key: key}
+	w.server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		w.requests.Add(1)
+		rw.Header().Set("Content-Type", "application/json")
+		assert.NoError(t, json.NewEncoder(rw).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "workload-key", Algorithm: string(jose.RS256), Use: "sig"},
+		}}))
+	}))
+	t.Cleanup(w.server.Close)
+	return w
+}
+
+func (w *workload) token(t *testing.T, claims jwt.Claims) string {
+	t.Helper()
+	return signToken(t, w.key, "workload-key", claims)
+}
+
+func signToken(t *testing.T, key *rsa.PrivateKey, keyID string, claims jwt.Claims) string {
+	t.Helper()
+
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", keyID))
+	require.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	require.NoError(t, err)
+	return token
+}
+
+func validClaims() jwt.Claims {
+	now := time.Now()
+	return jwt.Claims{
+		Issuer:   testIssuer,
+		Subject:  testSubject,
+		Audience: jwt.Audience{testAudience},
+		IssuedAt: jwt.NewNumericDate(now),
+		Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+	}
+}
+
+func newTestService(t *testing.T, serviceAccount *serviceaccounts.ServiceAccountProfileDTO) *Service {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	cfg := setting.NewCfg()
+	cfg.Env = setting.Dev
+	cfg.AppURL = "http://localhost:3000/"
+	cfg.WorkloadIdentity = setting.WorkloadIdentitySettings{Enabled: true, TokenLifetime: 15 * time.Minute, JWKSCacheTTL: time.Hour}
+
+	signingKeys := &signingkeystest.FakeSigningKeysService{
+		ExpectedKeyID:  "workload-identity-2024-06",
+		ExpectedSinger: key,
+		ExpectedJSONWebKeySet: jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: key.Public(), KeyID: "workload-identity-2024-06", Algorithm: string(jose.ES256), Use: "sig"},
+		}},
+	}
+	serviceAccounts := &satests.FakeServiceAccountService{ExpectedServiceAccountProfile: serviceAccount}
+	return newService(cfg, &fakeStore{}, remotecache.NewFakeStore(t), serviceAccounts, signingKeys)
+}
+
+func createPolicy(t *testing.T, s *Service, w *workload, subject string) *TrustPolicy {
+	t.Helper()
+	return createOrgPolicy(t, s, w, 1, subject)
+}
+
+func createOrgPolicy(t *testing.T, s *Service, w *workload, orgID int64, subject string) *TrustPolicy {
+	t.Helper()
+
+	policy, err := s.CreateTrustPolicy(context.Background(), &CreateTrustPolicyCommand{
+		OrgID:            orgID,
+		ServiceAccountID: 2,
+		Name:             "CI " + subject,
+		Issuer:           testIssuer,
+		JWKSURL:          w.server.URL,
+		Subject:          subject,
+		Audience:         testAudience,
+	})
+	require.NoError(t, err)
+	return policy
+}
+
+func TestService_CreateTrustPolicy(t *testing.T) {
+	valid := func() *CreateTrustPolicyCommand {
+		return &CreateTrustPolicyCommand{
+			OrgID:            1,
+			ServiceAccountID: 2,
+			Name:             "CI",
+			Issuer:           testIssuer,
+			JWKSURL:          "https://token.actions.example.com/.well-known/jwks",
+			Subject:          "repo:grafana/grafana:ref:refs/heads/*",
+			Audience:         testAudience,
+		}
+	}
+
+	tests := []struct {
+		desc        string
+		env         string
+		modify      func(cmd *CreateTrustPolicyCommand)
+		expectedErr error
+	}{
+		{desc: "should create policy", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) {}},
+		{desc: "should require audience", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.Audience = "" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject subject that matches everything", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.Subject = "*" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject wildcard without prefix segment", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.Subject = ":*" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject wildcard within a segment", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.Subject = "repo:grafana/graf*" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject wildcard that isn't last", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.Subject = "repo:*/grafana" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject more than one wildcard", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.Subject = "repo:grafana/*:ref:*" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject invalid url", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.JWKSURL = "jwks" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should reject http url", env: setting.Prod, modify: func(cmd *CreateTrustPolicyCommand) { cmd.JWKSURL = "http://localhost/jwks" }, expectedErr: ErrInvalidTrustPolicy},
+		{desc: "should allow http url in development", env: setting.Dev, modify: func(cmd *CreateTrustPolicyCommand) { cmd.JWKSURL = "http://localhost/jwks" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+			s.cfg.Env = tt.env
+
+			cmd := valid()
+			tt.modify(cmd)
+			policy, err := s.CreateTrustPolicy(context.Background(), cmd)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, policy.UID)
+
+			policies, err := s.ListTrustPolicies(context.Background(), 1, 2)
+			require.NoError(t, err)
+			assert.Equal(t, []*TrustPolicy{policy}, policies)
+		})
+	}
+}
+
+func TestService_Exchange(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should exchange workload token for access token", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1, Login: "sa-ci"})
+		policy := createPolicy(t, s, w, testSubject)
+
+		result, err := s.Exchange(ctx, &ExchangeTokenCommand{GrantType: TokenExchangeGrantType, SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		require.NoError(t, err)
+
+		assert.Equal(t, policy, result.Policy)
+		assert.Equal(t, testSubject, result.Subject)
+		assert.Equal(t, "sa-ci", result.ServiceAccountLogin)
+		assert.Equal(t, "Bearer", result.Token.TokenType)
+		assert.Equal(t, int64(900), result.Token.ExpiresIn)
+		assert.True(t, strings.HasPrefix(result.Token.AccessToken, TokenPrefix))
+
+		claims, err := s.VerifyAccessToken(ctx, result.Token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "service-account:2", claims.Subject)
+		assert.Equal(t, int64(1), claims.OrgID)
+		assert.Equal(t, policy.UID, claims.TrustPolicy)
+	})
+
+	t.Run("should match a single path segment with wildcard", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		createPolicy(t, s, w, "repo:grafana/grafana:ref:refs/heads/*")
+
+		_, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		require.NoError(t, err)
+
+		for _, subject := range []string{"repo:grafana/grafana:ref:refs/heads/feature/main", "repo:grafana/grafana:ref:refs/heads/"} {
+			claims := validClaims()
+			claims.Subject = subject
+			_, err = s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, claims), OrgID: 1})
+			assert.ErrorIs(t, err, ErrInvalidToken, subject)
+		}
+	})
+
+	t.Run("should require org or trust policy", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		policy := createPolicy(t, s, w, testSubject)
+
+		_, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims())})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		result, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), TrustPolicy: policy.UID})
+		require.NoError(t, err)
+		assert.Equal(t, policy.UID, result.Policy.UID)
+	})
+
+	t.Run("should only match trust policies of the requested org", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		policy := createOrgPolicy(t, s, w, 1, testSubject)
+		other := createOrgPolicy(t, s, w, 2, testSubject)
+
+		result, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		require.NoError(t, err)
+		assert.Equal(t, policy.UID, result.Policy.UID)
+
+		_, err = s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 3})
+		assert.ErrorIs(t, err, ErrInvalidToken)
+
+		_, err = s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1, TrustPolicy: other.UID})
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("should reject tokens that don't match the policy", func(t *testing.T) {
+		other, err := rsa.GenerateKey(rand.Reader, 2048)
+		require.NoError(t, err)
+
+		tests := []struct {
+			desc  string
+			token func(w *workload) string
+		}{
+			{desc: "other subject", token: func(w *workload) string {
+				claims := validClaims()
+				claims.Subject = "repo:grafana/other:ref:refs/heads/main"
+				return w.token(t, claims)
+			}},
+			{desc: "other audience", token: func(w *workload) string {
+				claims := validClaims()
+				claims.Audience = jwt.Audience{"https://other.example.com"}
+				return w.token(t, claims)
+			}},
+			{desc: "unknown issuer", token: func(w *workload) string {
+				claims := validClaims()
+				claims.Issuer = "https://other.example.com"
+				return w.token(t, claims)
+			}},
+			{desc: "expired", token: func(w *workload) string {
+				claims := validClaims()
+				claims.Expiry = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+				return w.token(t, claims)
+			}},
+			{desc: "no expiry", token: func(w *workload) string {
+				claims := validClaims()
+				claims.Expiry = nil
+				return w.token(t, claims)
+			}},
+			{desc: "signed by another key", token: func(w *workload) string {
+				return signToken(t, other, "workload-key", validClaims())
+			}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.desc, func(t *testing.T) {
+				w := newWorkload(t)
+				s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+				createPolicy(t, s, w, testSubject)
+
+				_, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: tt.token(w), OrgID: 1})
+				assert.ErrorIs(t, err, ErrInvalidToken)
+			})
+		}
+	})
+
+	t.Run("should require trust policy when token matches more than one", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		createPolicy(t, s, w, testSubject)
+		policy := createPolicy(t, s, w, "repo:grafana/grafana:ref:refs/heads/*")
+
+		_, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		assert.ErrorIs(t, err, ErrAmbiguousToken)
+
+		result, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1, TrustPolicy: policy.UID})
+		require.NoError(t, err)
+		assert.Equal(t, policy.UID, result.Policy.UID)
+	})
+
+	t.Run("should reject tokens of disabled service accounts", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1, IsDisabled: true})
+		createPolicy(t, s, w, testSubject)
+
+		_, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("should reject unsupported grant types", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+
+		_, err := s.Exchange(ctx, &ExchangeTokenCommand{GrantType: "client_credentials", SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+	})
+
+	t.Run("should cache the key set", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		createPolicy(t, s, w, testSubject)
+
+		for i := 0; i < 3; i++ {
+			_, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+			require.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), w.requests.Load())
+	})
+}
+
+func TestService_VerifyAccessToken(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("should reject tokens of deleted trust policies", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		policy := createPolicy(t, s, w, testSubject)
+
+		result, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		require.NoError(t, err)
+
+		require.NoError(t, s.DeleteTrustPolicy(ctx, &DeleteTrustPolicyCommand{OrgID: 1, ServiceAccountID: 2, UID: policy.UID}))
+		_, err = s.VerifyAccessToken(ctx, result.Token.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+
+	t.Run("should reject expired tokens", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+		createPolicy(t, s, w, testSubject)
+
+		result, err := s.Exchange(ctx, &ExchangeTokenCommand{SubjectToken: w.token(t, validClaims()), OrgID: 1})
+		require.NoError(t, err)
+
+		s.now = func() time.Time { return time.Now().Add(time.Hour) }
+		_, err = s.VerifyAccessToken(ctx, result.Token.AccessToken)
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+
+	t.Run("should reject workload tokens", func(t *testing.T) {
+		w := newWorkload(t)
+		s := newTestService(t, &serviceaccounts.ServiceAccountProfileDTO{Id: 2, OrgId: 1})
+
+		_, err := s.VerifyAccessToken(ctx, TokenPrefix+w.token(t, validClaims()))
+		assert.ErrorIs(t, err, ErrInvalidAccessToken)
+	})
+}
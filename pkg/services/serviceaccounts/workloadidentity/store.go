@@ -0,0 +1,74 @@
+package workloadidentity
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+type store interface {
+	Create(ctx context.Context, policy *TrustPolicy) error
+	Delete(ctx context.Context, cmd *DeleteTrustPolicyCommand) error
+	Get(ctx context.Context, uid string) (*TrustPolicy, error)
+	List(ctx context.Context, orgID, serviceAccountID int64) ([]*TrustPolicy, error)
+	ListByIssuer(ctx context.Context, orgID int64, issuer string) ([]*TrustPolicy, error)
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (s *xormStore) Create(ctx context.Context, policy *TrustPolicy) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Insert(policy)
+		return err
+	})
+}
+
+func (s *xormStore) Delete(ctx context.Context, cmd *DeleteTrustPolicyCommand) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		affected, err := sess.Where("org_id = ? AND service_account_id = ? AND uid = ?", cmd.OrgID, cmd.ServiceAccountID, cmd.UID).
+			Delete(&TrustPolicy{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrTrustPolicyNotFound.Errorf("trust policy %s not found", cmd.UID)
+		}
+		return nil
+	})
+}
+
+func (s *xormStore) Get(ctx context.Context, uid string) (*TrustPolicy, error) {
+	policy := &TrustPolicy{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("uid = ?", uid).Get(policy)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrTrustPolicyNotFound.Errorf("trust policy %s not found", uid)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *xormStore) List(ctx context.Context, orgID, serviceAccountID int64) ([]*TrustPolicy, error) {
+	policies := []*TrustPolicy{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND service_account_id = ?", orgID, serviceAccountID).Asc("name").Find(&policies)
+	})
+	return policies, err
+}
+
+func (s *xormStore) ListByIssuer(ctx context.Context, orgID int64, issuer string) ([]*TrustPolicy, error) {
+	policies := []*TrustPolicy{}
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("org_id = ? AND issuer = ?", orgID, issuer).Find(&policies)
+	})
+	return policies, err
+}
@@ -0,0 +1,91 @@
+package workloadidentity
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationTrustPolicyStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	newPolicy := func(uid string, orgID, serviceAccountID int64, issuer string) *TrustPolicy {
+		return &TrustPolicy{
+			UID:              uid,
+			OrgID:            orgID,
+			ServiceAccountID: serviceAccountID,
+			Name:             "policy " + uid,
+			Issuer:           issuer,
+			JWKSURL:          issuer + "/jwks",
+			Subject:          "repo:grafana/grafana:*",
+			Audience:         "grafana",
+			Created:          now,
+			Updated:          now,
+		}
+	}
+
+	setup := func(t *testing.T) *xormStore {
+		s := &xormStore{db: db.InitTestDB(t)}
+		require.NoError(t, s.Create(ctx, newPolicy("a", 1, 2, "https://ci.example.com")))
+		require.NoError(t, s.Create(ctx, newPolicy("b", 1, 2, "https://cloud.example.com")))
+		require.NoError(t, s.Create(ctx, newPolicy("c", 2, 3, "https://ci.example.com")))
+		return s
+	}
+
+	t.Run("should get policy by uid", func(t *testing.T) {
+		s := setup(t)
+		policy, err := s.Get(ctx, "b")
+		require.NoError(t, err)
+		assert.Equal(t, "https://cloud.example.com", policy.Issuer)
+		assert.Equal(t, int64(2), policy.ServiceAccountID)
+
+		_, err = s.Get(ctx, "unknown")
+		assert.ErrorIs(t, err, ErrTrustPolicyNotFound)
+	})
+
+	t.Run("should list policies of service account", func(t *testing.T) {
+		s := setup(t)
+		policies, err := s.List(ctx, 1, 2)
+		require.NoError(t, err)
+		require.Len(t, policies, 2)
+		assert.Equal(t, "a", policies[0].UID)
+		assert.Equal(t, "b", policies[1].UID)
+	})
+
+	t.Run("should list policies of issuer in org", func(t *testing.T) {
+		s := setup(t)
+		policies, err := s.ListByIssuer(ctx, 1, "https://ci.example.com")
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, "a", policies[0].UID)
+
+		policies, err = s.ListByIssuer(ctx, 3, "https://ci.example.com")
+		require.NoError(t, err)
+		assert.Empty(t, policies)
+	})
+
+	t.Run("should only delete policies of the service account", func(t *testing.T) {
+		s := setup(t)
+		err := s.Delete(ctx, &DeleteTrustPolicyCommand{OrgID: 1, ServiceAccountID: 2, UID: "c"})
+		assert.ErrorIs(t, err, ErrTrustPolicyNotFound)
+
+		require.NoError(t, s.Delete(ctx, &DeleteTrustPolicyCommand{OrgID: 2, ServiceAccountID: 3, UID: "c"}))
+		_, err = s.Get(ctx, "c")
+		assert.ErrorIs(t, err, ErrTrustPolicyNotFound)
+	})
+}
@@ -96,4 +96,45 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	mg.AddMigration("add variables column", NewAddColumnMigration(dashboardPublicCfgV2, &Column{
+		Name:     "variables",
+		Type:     DB_Text,
+		Nullable: true,
+	}))
+
+	var dashboardPublicTokenV1 = Table{
+		Name: "dashboard_public_token",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "label", Type: DB_NVarchar, Length: 255, Nullable: false, Default: "''"},
+
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+			{Name: "allowed_ips", Type: DB_Text, Nullable: true},
+			{Name: "rate_limit", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "revoked_at", Type: DB_DateTime, Nullable: true},
+
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+
+			{Name: "view_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "query_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "last_seen_at", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+			{Cols: []string{"public_dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public token table v1", NewAddTableMigration(dashboardPublicTokenV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicTokenV1)
+
+	// every public dashboard gets a default token with its existing access token
+	mg.AddMigration("copy public dashboard access tokens to dashboard_public_token", NewRawSQLMigration(`
+		INSERT INTO dashboard_public_token (uid, public_dashboard_uid, org_id, access_token, label, rate_limit, created_by, created_at, view_count, query_count)
+		SELECT uid, uid, org_id, access_token, '', 0, created_by, created_at, 0, 0 FROM dashboard_public`))
 }
@@ -123,6 +123,11 @@ func (oss *OSSMigrations) AddMigration(mg *Migrator) {
 	accesscontrol.AddManagedFolderAlertingSilencesActionsMigrator(mg)
 
 	ualert.AddRecordingRuleColumns(mg)
+
+	addUserMFAMigrations(mg)
+
+	addAuditLogMigrations(mg)
+	addWorkloadIdentityMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
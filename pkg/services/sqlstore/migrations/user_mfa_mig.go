@@ -0,0 +1,44 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addUserMFAMigrations(mg *Migrator) {
+	factorV1 := Table{
+		Name: "user_mfa_factor",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "type", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "credential_id", Type: DB_NVarchar, Length: 1024, Nullable: true},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "counter", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+			{Name: "last_used", Type: DB_DateTime, Nullable: true},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_mfa_factor table", NewAddTableMigration(factorV1))
+	mg.AddMigration("add index user_mfa_factor.user_id", NewAddIndexMigration(factorV1, factorV1.Indices[0]))
+
+	recoveryCodeV1 := Table{
+		Name: "user_mfa_recovery_code",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "code_hash", Type: DB_NVarchar, Length: 64, Nullable: false},
+			{Name: "used", Type: DB_Bool, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_mfa_recovery_code table", NewAddTableMigration(recoveryCodeV1))
+	mg.AddMigration("add index user_mfa_recovery_code.user_id", NewAddIndexMigration(recoveryCodeV1, recoveryCodeV1.Indices[0]))
+}
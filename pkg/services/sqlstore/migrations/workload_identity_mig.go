@@ -0,0 +1,30 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addWorkloadIdentityMigrations(mg *Migrator) {
+	trustPolicyV1 := Table{
+		Name: "service_account_trust_policy",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "service_account_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "issuer", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "jwks_url", Type: DB_Text, Nullable: false},
+			{Name: "subject", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "audience", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"uid"}, Type: UniqueIndex},
+			{Cols: []string{"org_id", "service_account_id"}},
+			{Cols: []string{"issuer"}},
+		},
+	}
+
+	mg.AddMigration("create service_account_trust_policy table", NewAddTableMigration(trustPolicyV1))
+	addTableIndicesMigrations(mg, "v1", trustPolicyV1)
+}
@@ -70,6 +70,7 @@ func TestReadDashboard(t *testing.T) {
 		"mixed-datasource-with-variable",
 		"special-datasource-types",
 		"panels-without-datasources",
+		"panel-queries",
 	}
 
 	devdash := "../../../../../devenv/dev-dashboards/"
@@ -112,6 +113,13 @@ func TestReadDashboard(t *testing.T) {
 	}
 }
 
+func TestReadQueryText(t *testing.T) {
+	require.Equal(t, `rate(http_requests_total{job="api"}[5m])`, ReadQueryText([]byte(`{"refId":"A","datasource":{"uid":"prom-1"},"expr":"rate(http_requests_total{job=\"api\"}[5m])"}`)))
+	require.Equal(t, "SELECT 1", ReadQueryText([]byte(`{"refId":"A","rawQuery":true,"rawSql":"SELECT 1"}`)))
+	require.Equal(t, "", ReadQueryText([]byte(`{"refId":"A","scenarioId":"random_walk"}`)))
+	require.Equal(t, "", ReadQueryText([]byte(`[]`)))
+}
+
 // assure consistent ordering of datasources to prevent random failures of `assert.JSONEq`
 func sortDatasources(dash *dashboardInfo) {
 	sort.Slice(dash.Datasource, func(i, j int) bool {
@@ -11,6 +11,10 @@ import (
 	"github.com/grafana/grafana/pkg/services/store/entity"
 )
 
+// PanelQueryFieldPrefix prefixes the panel summary fields holding the raw query
+// text of each target, keyed by the target refId (or its position when unset).
+const PanelQueryFieldPrefix = "query."
+
 // This summary does not resolve old name as UID
 func GetEntitySummaryBuilder() entity.EntitySummaryBuilder {
 	builder := NewStaticDashboardSummaryBuilder(&directLookup{}, true)
@@ -86,6 +90,17 @@ func panelSummary(panel panelInfo, uid string, dashboardRefs ReferenceAccumulato
 	p.Description = panel.Description
 	p.Fields = make(map[string]string, 0)
 	p.Fields["type"] = panel.Type
+	for idx, q := range panel.Queries {
+		key := q.RefID
+		if key == "" {
+			key = strconv.Itoa(idx)
+		}
+		if existing, ok := p.Fields[PanelQueryFieldPrefix+key]; ok {
+			p.Fields[PanelQueryFieldPrefix+key] = existing + "\n" + q.Text
+			continue
+		}
+		p.Fields[PanelQueryFieldPrefix+key] = q.Text
+	}
 
 	if panel.Type != "row" {
 		panelRefs.Add(entity.ExternalEntityReferencePlugin, string(plugins.TypePanel), panel.Type)
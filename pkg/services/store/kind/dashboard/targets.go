@@ -1,12 +1,25 @@
 package dashboard
 
 import (
+	"strings"
+
 	jsoniter "github.com/json-iterator/go"
 )
 
+// queryTextFields are the target properties holding the raw query text for the
+// most common datasources (PromQL/LogQL, SQL, Graphite, InfluxQL/Flux, expressions).
+var queryTextFields = map[string]bool{
+	"expr":       true,
+	"rawSql":     true,
+	"query":      true,
+	"target":     true,
+	"expression": true,
+}
+
 type targetInfo struct {
-	lookup DatasourceLookup
-	uids   map[string]*DataSourceRef
+	lookup  DatasourceLookup
+	uids    map[string]*DataSourceRef
+	queries []queryInfo
 }
 
 func newTargetInfo(lookup DatasourceLookup) targetInfo {
@@ -67,19 +80,35 @@ func (s *targetInfo) addRef(ref *DataSourceRef) {
 }
 
 func (s *targetInfo) addTarget(iter *jsoniter.Iterator) {
+	query := queryInfo{}
+	var text []string
 	for l1Field := iter.ReadObject(); l1Field != ""; l1Field = iter.ReadObject() {
 		switch l1Field {
 		case "datasource":
 			s.addDatasource(iter)
 
 		case "refId":
-			iter.Skip()
+			if iter.WhatIsNext() == jsoniter.StringValue {
+				query.RefID = iter.ReadString()
+			} else {
+				iter.Skip()
+			}
 
 		default:
+			if queryTextFields[l1Field] && iter.WhatIsNext() == jsoniter.StringValue {
+				if v := strings.TrimSpace(iter.ReadString()); v != "" {
+					text = append(text, v)
+				}
+				continue
+			}
 			v := iter.Read()
 			logf("[Panel.TARGET] %s=%v\n", l1Field, v)
 		}
 	}
+	if len(text) > 0 {
+		query.Text = strings.Join(text, "\n")
+		s.queries = append(s.queries, query)
+	}
 }
 
 func (s *targetInfo) addPanel(panel panelInfo) {
@@ -89,3 +118,19 @@ func (s *targetInfo) addPanel(panel panelInfo) {
 		}
 	}
 }
+
+// ReadQueryText returns the raw query text (PromQL, LogQL, SQL, ...) of a single
+// query model, such as a panel target or an alert rule query. An empty string is
+// returned when the model does not contain any known query text property.
+func ReadQueryText(model []byte) string {
+	iter := jsoniter.ParseBytes(jsoniter.ConfigDefault, model)
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		return ""
+	}
+	targets := newTargetInfo(&directLookup{})
+	targets.addTarget(iter)
+	if len(targets.queries) == 0 {
+		return ""
+	}
+	return targets.queries[0].Text
+}
@@ -9,10 +9,16 @@ type panelInfo struct {
 	LibraryPanel  string          `json:"libraryPanel,omitempty"` // UID of referenced library panel
 	Datasource    []DataSourceRef `json:"datasource,omitempty"`   // UIDs
 	Transformer   []string        `json:"transformer,omitempty"`  // ids of the transformation steps
+	Queries       []queryInfo     `json:"queries,omitempty"`      // raw query text of the targets
 	// Rows define panels as sub objects
 	Collapsed []panelInfo `json:"collapsed,omitempty"`
 }
 
+type queryInfo struct {
+	RefID string `json:"refId,omitempty"`
+	Text  string `json:"text"`
+}
+
 type dashboardInfo struct {
 	UID           string          `json:"uid,omitempty"`
 	ID            int64           `json:"id,omitempty"` // internal ID
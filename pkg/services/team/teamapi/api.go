@@ -9,6 +9,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/licensing"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/setting"
 )
@@ -22,6 +23,7 @@ type TeamAPI struct {
 	cfg                    *setting.Cfg
 	preferenceService      pref.Service
 	ds                     dashboards.DashboardService
+	teamSyncService        teamsync.Service
 	logger                 log.Logger
 }
 
@@ -36,6 +38,7 @@ func ProvideTeamAPI(
 	cfg *setting.Cfg,
 	preferenceService pref.Service,
 	ds dashboards.DashboardService,
+	teamSyncService teamsync.Service,
 ) *TeamAPI {
 	tapi := &TeamAPI{
 		teamService:            teamService,
@@ -46,6 +49,7 @@ func ProvideTeamAPI(
 		cfg:                    cfg,
 		preferenceService:      preferenceService,
 		ds:                     ds,
+		teamSyncService:        teamSyncService,
 		logger:                 log.New("team-api"),
 	}
 
@@ -74,6 +78,12 @@ func (tapi *TeamAPI) registerRoutes(router routing.RouteRegister, ac accesscontr
 				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.setTeamMemberships))
 			teamsRoute.Delete("/:teamId/members/:userId", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite,
 				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.removeTeamMember))
+			teamsRoute.Get("/:teamId/groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsRead,
+				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.getTeamGroups))
+			teamsRoute.Post("/:teamId/groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite,
+				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.addTeamGroup))
+			teamsRoute.Delete("/:teamId/groups", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsPermissionsWrite,
+				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.removeTeamGroup))
 			teamsRoute.Get("/:teamId/preferences", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsRead,
 				accesscontrol.ScopeTeamsID)), routing.Wrap(tapi.getTeamPreferences))
 			teamsRoute.Put("/:teamId/preferences", authorize(accesscontrol.EvalPermission(accesscontrol.ActionTeamsWrite,
@@ -0,0 +1,80 @@
+package teamapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// getTeamGroups lists the external groups that are synced with the team.
+func (tapi *TeamAPI) getTeamGroups(c *contextmodel.ReqContext) response.Response {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+
+	groups, err := tapi.teamSyncService.GetTeamGroups(c.Req.Context(), &teamsync.GetTeamGroupsQuery{OrgID: c.SignedInUser.GetOrgID(), TeamID: teamID})
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to get Team Groups", err)
+	}
+
+	return response.JSON(http.StatusOK, groups)
+}
+
+// addTeamGroup syncs the team with an external group.
+func (tapi *TeamAPI) addTeamGroup(c *contextmodel.ReqContext) response.Response {
+	cmd := teamsync.AddTeamGroupCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.TeamID = teamID
+
+	if err := tapi.teamSyncService.AddTeamGroup(c.Req.Context(), &cmd); err != nil {
+		switch {
+		case errors.Is(err, teamsync.ErrTeamGroupAlreadyAdded), errors.Is(err, teamsync.ErrTeamGroupIDEmpty):
+			return response.Error(http.StatusBadRequest, err.Error(), nil)
+		case errors.Is(err, team.ErrTeamNotFound):
+			return response.Error(http.StatusNotFound, "Team not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to add Group to Team", err)
+	}
+
+	return response.Success("Group added to Team")
+}
+
+// removeTeamGroup stops syncing the team with the external group in the groupId query
+// parameter. Group ids can contain characters that are not allowed in a path.
+func (tapi *TeamAPI) removeTeamGroup(c *contextmodel.ReqContext) response.Response {
+	teamID, err := strconv.ParseInt(web.Params(c.Req)[":teamId"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "teamId is invalid", err)
+	}
+	groupID := c.Query("groupId")
+	if groupID == "" {
+		return response.Error(http.StatusBadRequest, "groupId is missing", nil)
+	}
+
+	cmd := &teamsync.RemoveTeamGroupCommand{OrgID: c.SignedInUser.GetOrgID(), TeamID: teamID, GroupID: groupID}
+	if err := tapi.teamSyncService.RemoveTeamGroup(c.Req.Context(), cmd); err != nil {
+		switch {
+		case errors.Is(err, team.ErrTeamNotFound):
+			return response.Error(http.StatusNotFound, "Team not found", nil)
+		case errors.Is(err, teamsync.ErrTeamGroupNotFound):
+			return response.Error(http.StatusNotFound, "Group not found", nil)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to remove Group from Team", err)
+	}
+
+	return response.Success("Team Group removed")
+}
@@ -0,0 +1,81 @@
+package teamapi
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/services/team/teamsync/teamsynctest"
+	"github.com/grafana/grafana/pkg/web/webtest"
+)
+
+func newTeamGroupsRequest(server *webtest.Server, method, target, body string, permissions []accesscontrol.Permission) *http.Request {
+	return webtest.RequestWithSignedInUser(server.NewRequest(method, target, strings.NewReader(body)), authedUserWithPermissions(1, 1, permissions))
+}
+
+func TestTeamGroupsAPIEndpoints(t *testing.T) {
+	teamSyncService := teamsynctest.NewFakeService()
+	server := SetupAPITestServer(t, func(a *TeamAPI) {
+		a.teamSyncService = teamSyncService
+	})
+
+	readPermissions := []accesscontrol.Permission{{Action: accesscontrol.ActionTeamsPermissionsRead, Scope: "teams:id:1"}}
+	writePermissions := []accesscontrol.Permission{{Action: accesscontrol.ActionTeamsPermissionsWrite, Scope: "teams:id:1"}}
+
+	t.Run("should list the groups of the team", func(t *testing.T) {
+		teamSyncService.ExpectedTeamGroups = []*teamsync.TeamGroupDTO{{OrgID: 1, TeamID: 1, GroupID: "cn=editors,ou=groups,dc=grafana,dc=org"}}
+		t.Cleanup(func() { teamSyncService.ExpectedTeamGroups = nil })
+
+		res, err := server.Send(newTeamGroupsRequest(server, http.MethodGet, "/api/teams/1/groups", "", readPermissions))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not list the groups of another team", func(t *testing.T) {
+		res, err := server.Send(newTeamGroupsRequest(server, http.MethodGet, "/api/teams/2/groups", "", readPermissions))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should add a group to the team", func(t *testing.T) {
+		res, err := server.SendJSON(newTeamGroupsRequest(server, http.MethodPost, "/api/teams/1/groups", `{"groupId": "cn=editors,ou=groups,dc=grafana,dc=org"}`, writePermissions))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should not add a group twice", func(t *testing.T) {
+		teamSyncService.ExpectedError = teamsync.ErrTeamGroupAlreadyAdded
+		t.Cleanup(func() { teamSyncService.ExpectedError = nil })
+
+		res, err := server.SendJSON(newTeamGroupsRequest(server, http.MethodPost, "/api/teams/1/groups", `{"groupId": "cn=editors,ou=groups,dc=grafana,dc=org"}`, writePermissions))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should require the group id when removing a group", func(t *testing.T) {
+		res, err := server.Send(newTeamGroupsRequest(server, http.MethodDelete, "/api/teams/1/groups", "", writePermissions))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("should return not found when removing a group from an unknown team", func(t *testing.T) {
+		teamSyncService.ExpectedError = team.ErrTeamNotFound
+		t.Cleanup(func() { teamSyncService.ExpectedError = nil })
+
+		res, err := server.Send(newTeamGroupsRequest(server, http.MethodDelete, "/api/teams/1/groups?groupId=cn%3Deditors%2Cou%3Dgroups", "", writePermissions))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
@@ -0,0 +1,70 @@
+package teamsync
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	ErrTeamGroupAlreadyAdded = errors.New("group is already added to this team")
+	ErrTeamGroupNotFound     = errors.New("group not found")
+	ErrTeamGroupIDEmpty      = errors.New("group id cannot be empty")
+)
+
+// TeamGroup maps an external group, such as an LDAP group DN or a value of the
+// groups claim of an OAuth or JWT token, to a team.
+type TeamGroup struct {
+	ID      int64  `xorm:"pk autoincr 'id'"`
+	OrgID   int64  `xorm:"org_id"`
+	TeamID  int64  `xorm:"team_id"`
+	GroupID string `xorm:"group_id"`
+
+	Created time.Time
+	Updated time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+type AddTeamGroupCommand struct {
+	OrgID   int64  `json:"-"`
+	TeamID  int64  `json:"-"`
+	GroupID string `json:"groupId" binding:"Required"`
+}
+
+type RemoveTeamGroupCommand struct {
+	OrgID   int64
+	TeamID  int64
+	GroupID string
+}
+
+type SyncUserTeamsCommand struct {
+	UserID int64
+	// Groups are the external groups the user is a member of.
+	Groups []string
+}
+
+// ----------------------
+// QUERIES
+
+type GetTeamGroupsQuery struct {
+	OrgID  int64
+	TeamID int64
+}
+
+// GetTeamGroupsByGroupsQuery finds the team groups matching any of the groups.
+// When OrgIDs is empty teams of all organizations are returned.
+type GetTeamGroupsByGroupsQuery struct {
+	OrgIDs []int64
+	Groups []string
+}
+
+// ----------------------
+// Projections and DTOs
+
+type TeamGroupDTO struct {
+	OrgID    int64  `json:"orgId" xorm:"org_id"`
+	TeamID   int64  `json:"teamId" xorm:"team_id"`
+	TeamName string `json:"-" xorm:"team_name"`
+	GroupID  string `json:"groupId" xorm:"group_id"`
+}
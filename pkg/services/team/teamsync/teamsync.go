@@ -0,0 +1,16 @@
+package teamsync
+
+import (
+	"context"
+)
+
+type Service interface {
+	AddTeamGroup(ctx context.Context, cmd *AddTeamGroupCommand) error
+	RemoveTeamGroup(ctx context.Context, cmd *RemoveTeamGroupCommand) error
+	GetTeamGroups(ctx context.Context, query *GetTeamGroupsQuery) ([]*TeamGroupDTO, error)
+	GetTeamGroupsByGroups(ctx context.Context, query *GetTeamGroupsByGroupsQuery) ([]*TeamGroupDTO, error)
+	// SyncUserTeams adds the user to the teams mapped to their groups and removes them
+	// from the teams they were synced to earlier but are no longer mapped to.
+	// Memberships that were added by hand are left untouched.
+	SyncUserTeams(ctx context.Context, cmd *SyncUserTeamsCommand) error
+}
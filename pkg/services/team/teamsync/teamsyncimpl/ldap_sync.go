@@ -0,0 +1,106 @@
+package teamsyncimpl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// LDAPSync periodically looks up the groups of the users that signed in with LDAP
+// and syncs their teams, so that changes in LDAP reach Grafana before the users
+// sign in again. It runs on the schedule of the sync_cron setting of [auth.ldap]
+// when active_sync_enabled is set.
+type LDAPSync struct {
+	cfg         *setting.Cfg
+	store       store
+	ldapService service.LDAP
+	teamSync    teamsync.Service
+	log         log.Logger
+}
+
+func ProvideLDAPSync(cfg *setting.Cfg, teamSync *Service, ldapService service.LDAP) *LDAPSync {
+	return &LDAPSync{
+		cfg:         cfg,
+		store:       teamSync.store,
+		ldapService: ldapService,
+		teamSync:    teamSync,
+		log:         log.New("team.sync.ldap"),
+	}
+}
+
+func (s *LDAPSync) IsDisabled() bool {
+	return !s.cfg.LDAPAuthEnabled || !s.cfg.LDAPActiveSyncEnabled
+}
+
+func (s *LDAPSync) Run(ctx context.Context) error {
+	schedule, err := cron.ParseStandard(s.cfg.LDAPSyncCron)
+	if err != nil {
+		s.log.Error("Invalid sync_cron in [auth.ldap], LDAP team sync is disabled", "sync_cron", s.cfg.LDAPSyncCron, "error", err)
+		return nil
+	}
+
+	timer := time.NewTimer(time.Until(schedule.Next(time.Now())))
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			if err := s.SyncTeams(ctx); err != nil {
+				s.log.Error("Failed to sync LDAP teams", "error", err)
+			}
+			timer.Reset(time.Until(schedule.Next(time.Now())))
+		}
+	}
+}
+
+// SyncTeams syncs the teams of every LDAP user. Users that no longer exist in LDAP
+// are removed from their synced teams.
+func (s *LDAPSync) SyncTeams(ctx context.Context) error {
+	users, err := s.store.GetUsersByAuthModule(ctx, login.LDAPAuthModule)
+	if err != nil {
+		return fmt.Errorf("failed to get LDAP users: %w", err)
+	}
+
+	start := time.Now()
+	var synced, failed int
+	for _, u := range users {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var groups []string
+		info, err := s.ldapService.User(u.Login)
+		switch {
+		case errors.Is(err, multildap.ErrDidNotFindUser):
+			s.log.Debug("User not found in LDAP, removing synced teams", "userID", u.UserID, "login", u.Login)
+		case err != nil:
+			s.log.Warn("Failed to look up user in LDAP", "userID", u.UserID, "login", u.Login, "error", err)
+			failed++
+			continue
+		default:
+			groups = info.Groups
+		}
+
+		if err := s.teamSync.SyncUserTeams(ctx, &teamsync.SyncUserTeamsCommand{UserID: u.UserID, Groups: groups}); err != nil {
+			s.log.Warn("Failed to sync teams of user", "userID", u.UserID, "login", u.Login, "error", err)
+			failed++
+			continue
+		}
+		synced++
+	}
+
+	s.log.Info("Synced LDAP teams", "users", synced, "failed", failed, "duration", time.Since(start))
+	return nil
+}
@@ -0,0 +1,78 @@
+package teamsyncimpl
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/ldap/multildap"
+	"github.com/grafana/grafana/pkg/services/ldap/service"
+	"github.com/grafana/grafana/pkg/services/login"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/services/team/teamsync/teamsynctest"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+type fakeUserStore struct {
+	store
+	users []*externalUser
+}
+
+func (f *fakeUserStore) GetUsersByAuthModule(_ context.Context, authModule string) ([]*externalUser, error) {
+	if authModule != login.LDAPAuthModule {
+		return nil, nil
+	}
+	return f.users, nil
+}
+
+type fakeLDAP struct {
+	service.LDAP
+	users map[string]*login.ExternalUserInfo
+}
+
+func (f *fakeLDAP) User(username string) (*login.ExternalUserInfo, error) {
+	if username == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	if u, ok := f.users[username]; ok {
+		return u, nil
+	}
+	return nil, multildap.ErrDidNotFindUser
+}
+
+func TestLDAPSync_SyncTeams(t *testing.T) {
+	teamSync := teamsynctest.NewFakeService()
+	s := &LDAPSync{
+		cfg: setting.NewCfg(),
+		store: &fakeUserStore{users: []*externalUser{
+			{UserID: 1, Login: "alice"},
+			{UserID: 2, Login: "removed"},
+			{UserID: 3, Login: "broken"},
+		}},
+		ldapService: &fakeLDAP{users: map[string]*login.ExternalUserInfo{
+			"alice": {Login: "alice", Groups: []string{"cn=editors,ou=groups,dc=grafana,dc=org"}},
+		}},
+		teamSync: teamSync,
+		log:      log.NewNopLogger(),
+	}
+
+	require.NoError(t, s.SyncTeams(context.Background()))
+	assert.Equal(t, []*teamsync.SyncUserTeamsCommand{
+		{UserID: 1, Groups: []string{"cn=editors,ou=groups,dc=grafana,dc=org"}},
+		{UserID: 2},
+	}, teamSync.SyncUserTeamsCalls)
+}
+
+func TestLDAPSync_IsDisabled(t *testing.T) {
+	cfg := setting.NewCfg()
+	s := &LDAPSync{cfg: cfg}
+	assert.True(t, s.IsDisabled())
+
+	cfg.LDAPAuthEnabled = true
+	cfg.LDAPActiveSyncEnabled = true
+	assert.False(t, s.IsDisabled())
+}
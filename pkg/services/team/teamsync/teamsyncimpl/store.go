@@ -0,0 +1,171 @@
+package teamsyncimpl
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+)
+
+type store interface {
+	Add(ctx context.Context, cmd *teamsync.AddTeamGroupCommand) error
+	Remove(ctx context.Context, cmd *teamsync.RemoveTeamGroupCommand) error
+	GetByTeam(ctx context.Context, query *teamsync.GetTeamGroupsQuery) ([]*teamsync.TeamGroupDTO, error)
+	GetByGroups(ctx context.Context, query *teamsync.GetTeamGroupsByGroupsQuery) ([]*teamsync.TeamGroupDTO, error)
+	GetUsersByAuthModule(ctx context.Context, authModule string) ([]*externalUser, error)
+}
+
+type externalUser struct {
+	UserID int64  `xorm:"user_id"`
+	Login  string `xorm:"login"`
+}
+
+type xormStore struct {
+	db db.DB
+}
+
+func (ss *xormStore) Add(ctx context.Context, cmd *teamsync.AddTeamGroupCommand) error {
+	groupID := strings.TrimSpace(cmd.GroupID)
+	if groupID == "" {
+		return teamsync.ErrTeamGroupIDEmpty
+	}
+
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := teamExists(sess, cmd.OrgID, cmd.TeamID); err != nil {
+			return err
+		}
+
+		// group ids are matched case insensitively, so they must be unique the same way
+		exists, err := sess.Table("team_group").
+			Where("org_id=? AND team_id=? AND LOWER(group_id)=?", cmd.OrgID, cmd.TeamID, strings.ToLower(groupID)).
+			Exist()
+		if err != nil {
+			return err
+		}
+		if exists {
+			return teamsync.ErrTeamGroupAlreadyAdded
+		}
+
+		entity := teamsync.TeamGroup{
+			OrgID:   cmd.OrgID,
+			TeamID:  cmd.TeamID,
+			GroupID: groupID,
+			Created: time.Now(),
+			Updated: time.Now(),
+		}
+		_, err = sess.Insert(&entity)
+		return err
+	})
+}
+
+func (ss *xormStore) Remove(ctx context.Context, cmd *teamsync.RemoveTeamGroupCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		if err := teamExists(sess, cmd.OrgID, cmd.TeamID); err != nil {
+			return err
+		}
+
+		res, err := sess.Exec("DELETE FROM team_group WHERE org_id=? AND team_id=? AND group_id=?", cmd.OrgID, cmd.TeamID, cmd.GroupID)
+		if err != nil {
+			return err
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rows == 0 {
+			return teamsync.ErrTeamGroupNotFound
+		}
+		return nil
+	})
+}
+
+func (ss *xormStore) GetByTeam(ctx context.Context, query *teamsync.GetTeamGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	result := make([]*teamsync.TeamGroupDTO, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Table("team_group").
+			Join("INNER", "team", "team.id=team_group.team_id").
+			Select("team_group.org_id, team_group.team_id, team.name AS team_name, team_group.group_id").
+			Where("team_group.org_id=? AND team_group.team_id=?", query.OrgID, query.TeamID).
+			Asc("team_group.group_id").
+			Find(&result)
+	})
+	return result, err
+}
+
+func (ss *xormStore) GetByGroups(ctx context.Context, query *teamsync.GetTeamGroupsByGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	result := make([]*teamsync.TeamGroupDTO, 0)
+	candidates := groupCandidates(query.Groups)
+	if len(candidates) == 0 {
+		return result, nil
+	}
+
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		args := make([]any, 0, len(candidates))
+		for _, c := range candidates {
+			args = append(args, c)
+		}
+
+		sess.Table("team_group").
+			Join("INNER", "team", "team.id=team_group.team_id").
+			Select("team_group.org_id, team_group.team_id, team.name AS team_name, team_group.group_id").
+			Where("LOWER(team_group.group_id) IN (?"+strings.Repeat(",?", len(candidates)-1)+")", args...)
+		if len(query.OrgIDs) > 0 {
+			sess.In("team_group.org_id", query.OrgIDs)
+		}
+		return sess.Asc("team_group.org_id", "team.name", "team_group.group_id").Find(&result)
+	})
+	return result, err
+}
+
+// GetUsersByAuthModule returns the users that have signed in with the auth module.
+func (ss *xormStore) GetUsersByAuthModule(ctx context.Context, authModule string) ([]*externalUser, error) {
+	result := make([]*externalUser, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		userTable := ss.db.GetDialect().Quote("user")
+		return sess.SQL(`SELECT DISTINCT user_auth.user_id, `+userTable+`.login
+			FROM user_auth
+			INNER JOIN `+userTable+` ON `+userTable+`.id = user_auth.user_id
+			WHERE user_auth.auth_module = ?
+			ORDER BY user_auth.user_id`, authModule).Find(&result)
+	})
+	return result, err
+}
+
+// groupCandidates returns the lower cased group ids a team group must have to match
+// one of the groups. Like the LDAP group mappings, a team group of the form
+// cn=*,ou=groups,dc=grafana,dc=org matches every group in the organizational unit.
+func groupCandidates(groups []string) []string {
+	seen := make(map[string]struct{}, len(groups)*2)
+	candidates := make([]string, 0, len(groups)*2)
+	add := func(c string) {
+		if _, ok := seen[c]; ok {
+			return
+		}
+		seen[c] = struct{}{}
+		candidates = append(candidates, c)
+	}
+
+	for _, g := range groups {
+		g = strings.ToLower(strings.TrimSpace(g))
+		if g == "" {
+			continue
+		}
+		add(g)
+		if rdn, parent, ok := strings.Cut(g, ","); ok && strings.HasPrefix(rdn, "cn=") {
+			add("cn=*," + parent)
+		}
+	}
+	return candidates
+}
+
+func teamExists(sess *db.Session, orgID, teamID int64) error {
+	if res, err := sess.Query("SELECT 1 FROM team WHERE org_id=? AND id=?", orgID, teamID); err != nil {
+		return err
+	} else if len(res) != 1 {
+		return team.ErrTeamNotFound
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package teamsyncimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamimpl"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/tests/testsuite"
+)
+
+func TestMain(m *testing.M) {
+	testsuite.Run(m)
+}
+
+func TestIntegrationTeamGroupStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	ctx := context.Background()
+	sqlStore, cfg := db.InitTestDBWithCfg(t)
+	teamSvc, err := teamimpl.ProvideService(sqlStore, cfg, tracing.InitializeTracerForTest())
+	require.NoError(t, err)
+	teamSvc.RegisterDelete("DELETE FROM team_group WHERE org_id = ? AND team_id = ?")
+	store := &xormStore{db: sqlStore}
+
+	editors, err := teamSvc.CreateTeam(ctx, "editors", "", 1)
+	require.NoError(t, err)
+	admins, err := teamSvc.CreateTeam(ctx, "admins", "", 1)
+	require.NoError(t, err)
+	other, err := teamSvc.CreateTeam(ctx, "editors", "", 2)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Add(ctx, &teamsync.AddTeamGroupCommand{OrgID: 1, TeamID: editors.ID, GroupID: "cn=editors,ou=groups,dc=grafana,dc=org"}))
+	require.NoError(t, store.Add(ctx, &teamsync.AddTeamGroupCommand{OrgID: 1, TeamID: editors.ID, GroupID: "platform"}))
+	require.NoError(t, store.Add(ctx, &teamsync.AddTeamGroupCommand{OrgID: 1, TeamID: admins.ID, GroupID: "cn=*,ou=admins,dc=grafana,dc=org"}))
+	require.NoError(t, store.Add(ctx, &teamsync.AddTeamGroupCommand{OrgID: 2, TeamID: other.ID, GroupID: "platform"}))
+
+	t.Run("should not add a group twice", func(t *testing.T) {
+		err := store.Add(ctx, &teamsync.AddTeamGroupCommand{OrgID: 1, TeamID: editors.ID, GroupID: "Platform"})
+		require.ErrorIs(t, err, teamsync.ErrTeamGroupAlreadyAdded)
+	})
+
+	t.Run("should not add a group to a team of another org", func(t *testing.T) {
+		err := store.Add(ctx, &teamsync.AddTeamGroupCommand{OrgID: 2, TeamID: editors.ID, GroupID: "platform"})
+		require.ErrorIs(t, err, team.ErrTeamNotFound)
+	})
+
+	t.Run("should list the groups of a team", func(t *testing.T) {
+		groups, err := store.GetByTeam(ctx, &teamsync.GetTeamGroupsQuery{OrgID: 1, TeamID: editors.ID})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, "cn=editors,ou=groups,dc=grafana,dc=org", groups[0].GroupID)
+		assert.Equal(t, "platform", groups[1].GroupID)
+		assert.Equal(t, "editors", groups[1].TeamName)
+	})
+
+	t.Run("should find teams by groups case insensitively and with wildcards", func(t *testing.T) {
+		groups, err := store.GetByGroups(ctx, &teamsync.GetTeamGroupsByGroupsQuery{
+			OrgIDs: []int64{1},
+			Groups: []string{"CN=Editors,OU=Groups,DC=grafana,DC=org", "cn=oncall,ou=admins,dc=grafana,dc=org", "unknown"},
+		})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, admins.ID, groups[0].TeamID)
+		assert.Equal(t, editors.ID, groups[1].TeamID)
+	})
+
+	t.Run("should find teams of all orgs", func(t *testing.T) {
+		groups, err := store.GetByGroups(ctx, &teamsync.GetTeamGroupsByGroupsQuery{Groups: []string{"platform"}})
+		require.NoError(t, err)
+		require.Len(t, groups, 2)
+		assert.Equal(t, int64(1), groups[0].OrgID)
+		assert.Equal(t, int64(2), groups[1].OrgID)
+	})
+
+	t.Run("should remove a group", func(t *testing.T) {
+		require.NoError(t, store.Remove(ctx, &teamsync.RemoveTeamGroupCommand{OrgID: 1, TeamID: editors.ID, GroupID: "platform"}))
+		err := store.Remove(ctx, &teamsync.RemoveTeamGroupCommand{OrgID: 1, TeamID: editors.ID, GroupID: "platform"})
+		require.ErrorIs(t, err, teamsync.ErrTeamGroupNotFound)
+	})
+
+	t.Run("should remove the groups of a deleted team", func(t *testing.T) {
+		require.NoError(t, teamSvc.DeleteTeam(ctx, &team.DeleteTeamCommand{OrgID: 2, ID: other.ID}))
+		groups, err := store.GetByGroups(ctx, &teamsync.GetTeamGroupsByGroupsQuery{Groups: []string{"platform"}})
+		require.NoError(t, err)
+		require.Empty(t, groups)
+	})
+}
@@ -0,0 +1,157 @@
+package teamsyncimpl
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+)
+
+type Service struct {
+	store                  store
+	teamService            team.Service
+	teamPermissionsService accesscontrol.TeamPermissionsService
+	orgService             org.Service
+	log                    log.Logger
+	tracer                 tracing.Tracer
+}
+
+func ProvideService(db db.DB, teamService team.Service, teamPermissionsService accesscontrol.TeamPermissionsService,
+	orgService org.Service, tracer tracing.Tracer) *Service {
+	// team groups are removed together with their team
+	teamService.RegisterDelete("DELETE FROM team_group WHERE org_id = ? AND team_id = ?")
+
+	return &Service{
+		store:                  &xormStore{db: db},
+		teamService:            teamService,
+		teamPermissionsService: teamPermissionsService,
+		orgService:             orgService,
+		log:                    log.New("team.sync"),
+		tracer:                 tracer,
+	}
+}
+
+func (s *Service) AddTeamGroup(ctx context.Context, cmd *teamsync.AddTeamGroupCommand) error {
+	ctx, span := s.tracer.Start(ctx, "teamsync.AddTeamGroup", trace.WithAttributes(
+		attribute.Int64("orgID", cmd.OrgID),
+		attribute.Int64("teamID", cmd.TeamID),
+	))
+	defer span.End()
+	return s.store.Add(ctx, cmd)
+}
+
+func (s *Service) RemoveTeamGroup(ctx context.Context, cmd *teamsync.RemoveTeamGroupCommand) error {
+	ctx, span := s.tracer.Start(ctx, "teamsync.RemoveTeamGroup", trace.WithAttributes(
+		attribute.Int64("orgID", cmd.OrgID),
+		attribute.Int64("teamID", cmd.TeamID),
+	))
+	defer span.End()
+	return s.store.Remove(ctx, cmd)
+}
+
+func (s *Service) GetTeamGroups(ctx context.Context, query *teamsync.GetTeamGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	ctx, span := s.tracer.Start(ctx, "teamsync.GetTeamGroups", trace.WithAttributes(
+		attribute.Int64("orgID", query.OrgID),
+		attribute.Int64("teamID", query.TeamID),
+	))
+	defer span.End()
+	return s.store.GetByTeam(ctx, query)
+}
+
+func (s *Service) GetTeamGroupsByGroups(ctx context.Context, query *teamsync.GetTeamGroupsByGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	ctx, span := s.tracer.Start(ctx, "teamsync.GetTeamGroupsByGroups")
+	defer span.End()
+	return s.store.GetByGroups(ctx, query)
+}
+
+type teamKey struct {
+	orgID  int64
+	teamID int64
+}
+
+func (s *Service) SyncUserTeams(ctx context.Context, cmd *teamsync.SyncUserTeamsCommand) error {
+	ctx, span := s.tracer.Start(ctx, "teamsync.SyncUserTeams", trace.WithAttributes(
+		attribute.Int64("userID", cmd.UserID),
+	))
+	defer span.End()
+
+	ctxLogger := s.log.FromContext(ctx).New("userID", cmd.UserID)
+
+	orgs, err := s.orgService.GetUserOrgList(ctx, &org.GetUserOrgListQuery{UserID: cmd.UserID})
+	if err != nil {
+		return fmt.Errorf("failed to get organizations of user: %w", err)
+	}
+	if len(orgs) == 0 {
+		return nil
+	}
+
+	orgIDs := make([]int64, 0, len(orgs))
+	for _, o := range orgs {
+		orgIDs = append(orgIDs, o.OrgID)
+	}
+
+	// the teams the user should be synced to
+	mapped := map[teamKey]bool{}
+	if len(cmd.Groups) > 0 {
+		teamGroups, err := s.store.GetByGroups(ctx, &teamsync.GetTeamGroupsByGroupsQuery{OrgIDs: orgIDs, Groups: cmd.Groups})
+		if err != nil {
+			return fmt.Errorf("failed to get teams of groups: %w", err)
+		}
+		for _, tg := range teamGroups {
+			mapped[teamKey{orgID: tg.OrgID, teamID: tg.TeamID}] = true
+		}
+	}
+
+	for _, orgID := range orgIDs {
+		memberships, err := s.teamService.GetUserTeamMemberships(ctx, orgID, cmd.UserID, false)
+		if err != nil {
+			return fmt.Errorf("failed to get team memberships of user: %w", err)
+		}
+
+		isMember := map[int64]bool{}
+		for _, m := range memberships {
+			isMember[m.TeamID] = true
+
+			// only memberships added by team sync are removed
+			if !m.External || mapped[teamKey{orgID: orgID, teamID: m.TeamID}] {
+				continue
+			}
+			ctxLogger.Debug("Removing user from synced team", "orgID", orgID, "teamID", m.TeamID)
+			if err := s.setMembership(ctx, orgID, m.TeamID, cmd.UserID, ""); err != nil {
+				return err
+			}
+		}
+
+		for key := range mapped {
+			if key.orgID != orgID || isMember[key.teamID] {
+				continue
+			}
+			ctxLogger.Debug("Adding user to synced team", "orgID", orgID, "teamID", key.teamID)
+			if err := s.setMembership(ctx, orgID, key.teamID, cmd.UserID, team.MemberPermissionName); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setMembership goes through the team permission service, like the team API does, so
+// the managed roles of the team are updated together with the membership.
+func (s *Service) setMembership(ctx context.Context, orgID, teamID, userID int64, permission string) error {
+	user := accesscontrol.User{ID: userID, IsExternal: true}
+	if _, err := s.teamPermissionsService.SetUserPermission(ctx, orgID, user, strconv.FormatInt(teamID, 10), permission); err != nil {
+		return fmt.Errorf("failed to sync membership of user %d in team %d: %w", userID, teamID, err)
+	}
+	return nil
+}
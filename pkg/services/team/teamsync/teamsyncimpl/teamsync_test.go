@@ -0,0 +1,127 @@
+package teamsyncimpl
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/services/org/orgtest"
+	"github.com/grafana/grafana/pkg/services/team"
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+	"github.com/grafana/grafana/pkg/services/team/teamtest"
+)
+
+type fakeStore struct {
+	store
+	teamGroups []*teamsync.TeamGroupDTO
+}
+
+func (f *fakeStore) GetByGroups(_ context.Context, query *teamsync.GetTeamGroupsByGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	candidates := map[string]bool{}
+	for _, c := range groupCandidates(query.Groups) {
+		candidates[c] = true
+	}
+	var result []*teamsync.TeamGroupDTO
+	for _, tg := range f.teamGroups {
+		if candidates[tg.GroupID] {
+			result = append(result, tg)
+		}
+	}
+	return result, nil
+}
+
+type membershipChange struct {
+	teamID     string
+	permission string
+}
+
+type fakeTeamPermissionsService struct {
+	actest.FakePermissionsService
+	changes  []membershipChange
+	internal int
+}
+
+func (f *fakeTeamPermissionsService) SetUserPermission(_ context.Context, _ int64, user accesscontrol.User, resourceID, permission string) (*accesscontrol.ResourcePermission, error) {
+	if !user.IsExternal {
+		f.internal++
+	}
+	f.changes = append(f.changes, membershipChange{teamID: resourceID, permission: permission})
+	return &accesscontrol.ResourcePermission{}, nil
+}
+
+func TestService_SyncUserTeams(t *testing.T) {
+	teamGroups := []*teamsync.TeamGroupDTO{
+		{OrgID: 1, TeamID: 1, GroupID: "cn=editors,ou=groups,dc=grafana,dc=org"},
+		{OrgID: 1, TeamID: 2, GroupID: "cn=*,ou=admins,dc=grafana,dc=org"},
+		{OrgID: 1, TeamID: 3, GroupID: "platform"},
+	}
+
+	type testCase struct {
+		desc            string
+		groups          []string
+		members         []*team.TeamMemberDTO
+		expectedChanges []membershipChange
+	}
+
+	tests := []testCase{
+		{
+			desc:   "should add the user to the teams of their groups",
+			groups: []string{"CN=Editors,OU=Groups,DC=grafana,DC=org", "cn=oncall,ou=admins,dc=grafana,dc=org"},
+			expectedChanges: []membershipChange{
+				{teamID: "1", permission: team.MemberPermissionName},
+				{teamID: "2", permission: team.MemberPermissionName},
+			},
+		},
+		{
+			desc:   "should not change existing memberships",
+			groups: []string{"platform"},
+			members: []*team.TeamMemberDTO{
+				{OrgID: 1, TeamID: 3, Permission: 4},
+			},
+		},
+		{
+			desc:   "should remove the user from synced teams they are no longer mapped to",
+			groups: []string{"platform"},
+			members: []*team.TeamMemberDTO{
+				{OrgID: 1, TeamID: 1, External: true},
+				{OrgID: 1, TeamID: 3, External: true},
+			},
+			expectedChanges: []membershipChange{
+				{teamID: "1", permission: ""},
+			},
+		},
+		{
+			desc: "should keep memberships that were added by hand",
+			members: []*team.TeamMemberDTO{
+				{OrgID: 1, TeamID: 1},
+				{OrgID: 1, TeamID: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			permissions := &fakeTeamPermissionsService{}
+			s := &Service{
+				store:                  &fakeStore{teamGroups: teamGroups},
+				teamService:            &teamtest.FakeService{ExpectedMembers: tt.members},
+				teamPermissionsService: permissions,
+				orgService:             &orgtest.FakeOrgService{ExpectedUserOrgDTO: []*org.UserOrgDTO{{OrgID: 1}}},
+				log:                    log.NewNopLogger(),
+				tracer:                 tracing.InitializeTracerForTest(),
+			}
+
+			err := s.SyncUserTeams(context.Background(), &teamsync.SyncUserTeamsCommand{UserID: 1, Groups: tt.groups})
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.expectedChanges, permissions.changes)
+			assert.Zero(t, permissions.internal, "synced memberships must be external")
+		})
+	}
+}
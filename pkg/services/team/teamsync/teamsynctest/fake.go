@@ -0,0 +1,41 @@
+package teamsynctest
+
+import (
+	"context"
+
+	"github.com/grafana/grafana/pkg/services/team/teamsync"
+)
+
+var _ teamsync.Service = (*FakeService)(nil)
+
+type FakeService struct {
+	ExpectedTeamGroups []*teamsync.TeamGroupDTO
+	ExpectedError      error
+
+	SyncUserTeamsCalls []*teamsync.SyncUserTeamsCommand
+}
+
+func NewFakeService() *FakeService {
+	return &FakeService{}
+}
+
+func (f *FakeService) AddTeamGroup(ctx context.Context, cmd *teamsync.AddTeamGroupCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeService) RemoveTeamGroup(ctx context.Context, cmd *teamsync.RemoveTeamGroupCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeService) GetTeamGroups(ctx context.Context, query *teamsync.GetTeamGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	return f.ExpectedTeamGroups, f.ExpectedError
+}
+
+func (f *FakeService) GetTeamGroupsByGroups(ctx context.Context, query *teamsync.GetTeamGroupsByGroupsQuery) ([]*teamsync.TeamGroupDTO, error) {
+	return f.ExpectedTeamGroups, f.ExpectedError
+}
+
+func (f *FakeService) SyncUserTeams(ctx context.Context, cmd *teamsync.SyncUserTeamsCommand) error {
+	f.SyncUserTeamsCalls = append(f.SyncUserTeamsCalls, cmd)
+	return f.ExpectedError
+}
@@ -263,6 +263,18 @@ type Cfg struct {
 	JWTAuth    AuthJWTSettings
 	ExtJWTAuth ExtJWTSettings
 
+	// SCIM provisioning
+	SCIM SCIMSettings
+
+	// Second factor for password logins
+	MFA MFASettings
+
+	// Exchange of workload tokens for service account access tokens
+	WorkloadIdentity WorkloadIdentitySettings
+
+	// Audit log
+	Audit AuditSettings
+
 	// SSO Settings Auth
 	SSOSettingsReloadInterval        time.Duration
 	SSOSettingsConfigurableProviders map[string]bool
@@ -339,6 +351,8 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	// Directories the SQLite data source may open database files from
+	SqlDatasourceSQLiteAllowedPaths []string
 
 	// Snapshots
 	SnapshotEnabled      bool
@@ -427,6 +441,14 @@ type Cfg struct {
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
+	// LiveManagedStreamHistoryMaxFrames is the maximum number of frames kept
+	// per managed stream channel and sent to new subscribers. Managed streams
+	// keep only the latest frame when both this and
+	// LiveManagedStreamHistoryMaxAge are zero.
+	LiveManagedStreamHistoryMaxFrames int
+	// LiveManagedStreamHistoryMaxAge is the maximum age of the frames kept
+	// per managed stream channel.
+	LiveManagedStreamHistoryMaxAge time.Duration
 
 	// Grafana.com URL, used for OAuth redirect.
 	GrafanaComURL string
@@ -1210,6 +1232,9 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 	cfg.readAzureSettings()
 	cfg.readAuthJWTSettings()
 	cfg.readAuthExtJWTSettings()
+	cfg.readSCIMSettings()
+	cfg.readMFASettings()
+	cfg.readWorkloadIdentitySettings()
 	cfg.readAuthProxySettings()
 	cfg.readSessionConfig()
 	if err := cfg.readSmtpSettings(); err != nil {
@@ -1221,6 +1246,10 @@ func (cfg *Cfg) parseINIFile(iniFile *ini.File) error {
 
 	cfg.readQuotaSettings()
 
+	if err := cfg.readAuditSettings(); err != nil {
+		return err
+	}
+
 	cfg.readExpressionsSettings()
 	if err := cfg.readGrafanaEnvironmentMetrics(); err != nil {
 		return err
@@ -1974,6 +2003,7 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	cfg.SqlDatasourceSQLiteAllowedPaths = util.SplitString(sqlDatasources.Key("sqlite_allowed_paths").MustString(""))
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -2003,6 +2033,14 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveManagedStreamHistoryMaxFrames = section.Key("managed_stream_history_max_frames").MustInt(0)
+	if cfg.LiveManagedStreamHistoryMaxFrames < 0 {
+		return fmt.Errorf("unexpected value %d for [live] managed_stream_history_max_frames", cfg.LiveManagedStreamHistoryMaxFrames)
+	}
+	cfg.LiveManagedStreamHistoryMaxAge = section.Key("managed_stream_history_max_age").MustDuration(0)
+	if cfg.LiveManagedStreamHistoryMaxAge < 0 {
+		return fmt.Errorf("unexpected value %s for [live] managed_stream_history_max_age", cfg.LiveManagedStreamHistoryMaxAge)
+	}
 
 	allowedOrigins := section.Key("allowed_origins").MustString("")
 	origins := strings.Split(allowedOrigins, ",")
@@ -0,0 +1,66 @@
+package setting
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	AuditSinkDatabase = "database"
+	AuditSinkFile     = "file"
+	AuditSinkWebhook  = "webhook"
+)
+
+type AuditSettings struct {
+	Enabled bool
+	// Sinks are the destinations events are written to, any of database, file and webhook.
+	Sinks []string
+	// Retention is how long events are kept in the database, 0 keeps them forever.
+	Retention time.Duration
+
+	// FilePath is the file the file sink appends events to as JSON lines,
+	// relative paths are resolved against the logs directory.
+	FilePath string
+
+	WebhookURL string
+	// WebhookAuthorization is sent as Authorization header of webhook requests.
+	WebhookAuthorization string
+	WebhookTimeout       time.Duration
+}
+
+func (cfg *Cfg) readAuditSettings() error {
+	section := cfg.SectionWithEnvOverrides("audit")
+	settings := AuditSettings{
+		Enabled:              section.Key("enabled").MustBool(false),
+		Sinks:                util.SplitString(section.Key("sinks").MustString(AuditSinkDatabase)),
+		FilePath:             section.Key("file_path").MustString("audit.log"),
+		WebhookURL:           section.Key("webhook_url").MustString(""),
+		WebhookAuthorization: section.Key("webhook_authorization").MustString(""),
+		WebhookTimeout:       section.Key("webhook_timeout").MustDuration(10 * time.Second),
+	}
+
+	retention, err := gtime.ParseDuration(section.Key("retention").MustString("90d"))
+	if err != nil {
+		return fmt.Errorf("invalid audit retention: %w", err)
+	}
+	settings.Retention = retention
+
+	for _, sink := range settings.Sinks {
+		switch sink {
+		case AuditSinkDatabase, AuditSinkFile:
+		case AuditSinkWebhook:
+			if settings.WebhookURL == "" {
+				return fmt.Errorf("audit sink %q requires webhook_url", sink)
+			}
+		default:
+			return fmt.Errorf("unknown audit sink %q", sink)
+		}
+	}
+
+	cfg.Audit = settings
+	return nil
+}
@@ -0,0 +1,53 @@
+package setting
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/util"
+)
+
+type MFASettings struct {
+	Enabled bool
+	// Required makes every user that signs in with a password enroll a second factor.
+	// Organizations can require it for their members when it is not required server wide.
+	Required     bool
+	TOTPIssuer   string
+	ChallengeTTL time.Duration
+	MaxAttempts  int
+
+	// WebAuthnRPID is the relying party id security keys and passkeys are bound to,
+	// the host name of root_url by default.
+	WebAuthnRPID string
+	// WebAuthnOrigins are the origins WebAuthn responses are accepted from, the origin
+	// of root_url by default.
+	WebAuthnOrigins []string
+}
+
+func (cfg *Cfg) readMFASettings() {
+	section := cfg.SectionWithEnvOverrides("auth.mfa")
+	settings := MFASettings{
+		Enabled:         section.Key("enabled").MustBool(false),
+		Required:        section.Key("required").MustBool(false),
+		TOTPIssuer:      section.Key("totp_issuer").MustString("Grafana"),
+		ChallengeTTL:    section.Key("challenge_ttl").MustDuration(5 * time.Minute),
+		MaxAttempts:     section.Key("max_attempts").MustInt(5),
+		WebAuthnRPID:    section.Key("webauthn_rp_id").MustString(""),
+		WebAuthnOrigins: util.SplitString(section.Key("webauthn_origins").MustString("")),
+	}
+
+	if appURL, err := url.Parse(cfg.AppURL); err == nil {
+		if settings.WebAuthnRPID == "" {
+			settings.WebAuthnRPID = appURL.Hostname()
+		}
+		if len(settings.WebAuthnOrigins) == 0 {
+			settings.WebAuthnOrigins = []string{appURL.Scheme + "://" + appURL.Host}
+		}
+	}
+	for i, origin := range settings.WebAuthnOrigins {
+		settings.WebAuthnOrigins[i] = strings.TrimSuffix(origin, "/")
+	}
+
+	cfg.MFA = settings
+}
@@ -0,0 +1,16 @@
+package setting
+
+type SCIMSettings struct {
+	Enabled bool
+	// ServiceAccountLogin is the login of the service account the identity provider
+	// authenticates with. Tokens of other service accounts are rejected.
+	ServiceAccountLogin string
+}
+
+func (cfg *Cfg) readSCIMSettings() {
+	section := cfg.SectionWithEnvOverrides("auth.scim")
+	cfg.SCIM = SCIMSettings{
+		Enabled:             section.Key("enabled").MustBool(false),
+		ServiceAccountLogin: section.Key("service_account_login").MustString(""),
+	}
+}
@@ -10,6 +10,12 @@ type SearchSettings struct {
 	FullReindexInterval       time.Duration
 	IndexUpdateInterval       time.Duration
 	DashboardLoadingBatchSize int
+
+	// IndexSnapshotURL is the blob storage location (for example file:///var/lib/grafana/search)
+	// where index snapshots are persisted. Snapshots are disabled when empty.
+	IndexSnapshotURL      string
+	IndexSnapshotInterval time.Duration
+	IndexSnapshotMaxAge   time.Duration
 }
 
 func readSearchSettings(iniFile *ini.File) SearchSettings {
@@ -19,5 +25,8 @@ func readSearchSettings(iniFile *ini.File) SearchSettings {
 	s.DashboardLoadingBatchSize = searchSection.Key("dashboard_loading_batch_size").MustInt(200)
 	s.FullReindexInterval = searchSection.Key("full_reindex_interval").MustDuration(5 * time.Minute)
 	s.IndexUpdateInterval = searchSection.Key("index_update_interval").MustDuration(10 * time.Second)
+	s.IndexSnapshotURL = searchSection.Key("index_snapshot_url").MustString("")
+	s.IndexSnapshotInterval = searchSection.Key("index_snapshot_interval").MustDuration(10 * time.Minute)
+	s.IndexSnapshotMaxAge = searchSection.Key("index_snapshot_max_age").MustDuration(24 * time.Hour)
 	return s
 }
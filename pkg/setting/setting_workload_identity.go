@@ -0,0 +1,25 @@
+package setting
+
+import "time"
+
+type WorkloadIdentitySettings struct {
+	Enabled bool
+	// TokenLifetime is how long access tokens issued in exchange for workload tokens are valid.
+	TokenLifetime time.Duration
+	// JWKSCacheTTL is how long the keys of trust policy JWKS URLs are cached. Unknown key ids
+	// refresh the cache at most once a minute.
+	JWKSCacheTTL time.Duration
+}
+
+func (cfg *Cfg) readWorkloadIdentitySettings() {
+	section := cfg.SectionWithEnvOverrides("auth.workload_identity")
+	cfg.WorkloadIdentity = WorkloadIdentitySettings{
+		Enabled:       section.Key("enabled").MustBool(false),
+		TokenLifetime: section.Key("token_lifetime").MustDuration(15 * time.Minute),
+		JWKSCacheTTL:  section.Key("jwks_cache_ttl").MustDuration(time.Hour),
+	}
+	if cfg.WorkloadIdentity.TokenLifetime > time.Hour {
+		cfg.Logger.Warn("[auth.workload_identity.token_lifetime] is too high; the maximum allowed (1h) is enforced")
+		cfg.WorkloadIdentity.TokenLifetime = time.Hour
+	}
+}
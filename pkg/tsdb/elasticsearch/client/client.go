@@ -17,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	exp "github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -38,6 +39,7 @@ type DatasourceInfo struct {
 	Interval                   string
 	MaxConcurrentShardRequests int64
 	IncludeFrozen              bool
+	MaxCompositeBuckets        int
 }
 
 type ConfiguredFields struct {
@@ -49,8 +51,10 @@ type ConfiguredFields struct {
 // Client represents a client which can interact with elasticsearch api
 type Client interface {
 	GetConfiguredFields() ConfiguredFields
+	GetMaxCompositeBuckets() int
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	ExecuteESQL(r *ESQLRequest) (*ESQLResponse, error)
 }
 
 // NewClient creates a new elasticsearch client
@@ -88,6 +92,10 @@ func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
 	return c.configuredFields
 }
 
+func (c *baseClientImpl) GetMaxCompositeBuckets() int {
+	return c.ds.MaxCompositeBuckets
+}
+
 type multiRequest struct {
 	header   map[string]any
 	body     any
@@ -99,7 +107,7 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -131,7 +139,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*http.Response, error) {
 	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
@@ -150,7 +158,7 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	//nolint:bodyclose
 	resp, err := c.ds.HTTPClient.Do(req)
@@ -266,3 +274,68 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder()
 }
+
+// ExecuteESQL runs an ES|QL query, the query names the indices it reads, which must be indices of
+// the data source.
+func (c *baseClientImpl) ExecuteESQL(r *ESQLRequest) (*ESQLResponse, error) {
+	var err error
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.executeESQL", trace.WithAttributes(
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	indices, err := c.indexPattern.GetIndices(r.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkESQLIndices(r.Query, indices); err != nil {
+		return nil, exp.PluginError(err, false)
+	}
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, "_query", "", "application/json", body)
+	if err != nil {
+		c.logger.Error("Error received from Elasticsearch", "error", err, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	if res.StatusCode >= http.StatusBadRequest {
+		var errRes struct {
+			Error struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"error"`
+		}
+		if decodeErr := json.NewDecoder(res.Body).Decode(&errRes); decodeErr != nil || errRes.Error.Reason == "" {
+			err = fmt.Errorf("ES|QL query failed with status %d", res.StatusCode)
+		} else {
+			err = fmt.Errorf("ES|QL query failed: %s", errRes.Error.Reason)
+		}
+		return nil, exp.SourceError(backend.ErrorSourceFromHTTPStatus(res.StatusCode), err, false)
+	}
+
+	var esqlRes ESQLResponse
+	if err = json.NewDecoder(res.Body).Decode(&esqlRes); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "duration", time.Since(start))
+		return nil, err
+	}
+	return &esqlRes, nil
+}
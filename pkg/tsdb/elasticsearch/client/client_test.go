@@ -326,3 +326,102 @@ func createMultisearchWithMultipleQueriesForTest(t *testing.T, c Client, firstTi
 
 	return msb.Build()
 }
+
+func TestClient_ExecuteESQL(t *testing.T) {
+	newESQLClient := func(t *testing.T, handler http.HandlerFunc) Client {
+		ts := httptest.NewServer(handler)
+		t.Cleanup(ts.Close)
+
+		ds := DatasourceInfo{
+			URL:              ts.URL,
+			HTTPClient:       ts.Client(),
+			Database:         "logs-*",
+			ConfiguredFields: ConfiguredFields{TimeField: "@timestamp"},
+		}
+		c, err := NewClient(context.Background(), &ds, log.New("test", "test"), tracing.InitializeTracerForTest())
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("Given a query the columnar response is decoded", func(t *testing.T) {
+		var request *http.Request
+		var requestBody []byte
+		c := newESQLClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			request = r
+			var err error
+			requestBody, err = io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			rw.Header().Set("Content-Type", "application/json")
+			_, err = rw.Write([]byte(`{
+				"columns": [{ "name": "host", "type": "keyword" }, { "name": "count", "type": "long" }],
+				"values": [["a", "b"], [1, 2]]
+			}`))
+			require.NoError(t, err)
+		})
+
+		res, err := c.ExecuteESQL(&ESQLRequest{
+			Query:    "FROM logs-* | STATS count = COUNT(*) BY host",
+			Filter:   &RangeFilter{Key: "@timestamp", Gte: 1, Lte: 2, Format: DateFormatEpochMS},
+			Columnar: true,
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, request)
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/_query", request.URL.Path)
+		assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+		jBody, err := simplejson.NewJson(requestBody)
+		require.NoError(t, err)
+		assert.Equal(t, "FROM logs-* | STATS count = COUNT(*) BY host", jBody.Get("query").MustString())
+		assert.True(t, jBody.Get("columnar").MustBool())
+		assert.Equal(t, int64(1), jBody.GetPath("filter", "range", "@timestamp", "gte").MustInt64())
+
+		require.Equal(t, []ESQLColumn{{Name: "host", Type: "keyword"}, {Name: "count", Type: "long"}}, res.Columns)
+		require.Equal(t, [][]any{{"a", "b"}, {float64(1), float64(2)}}, res.Values)
+	})
+
+	t.Run("Given an invalid query the reason of the error is returned", func(t *testing.T) {
+		c := newESQLClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusBadRequest)
+			_, err := rw.Write([]byte(`{ "error": { "type": "verification_exception", "reason": "Unknown column [hots]" }, "status": 400 }`))
+			require.NoError(t, err)
+		})
+
+		_, err := c.ExecuteESQL(&ESQLRequest{Query: "FROM logs-* | KEEP hots"})
+		require.EqualError(t, err, "ES|QL query failed: Unknown column [hots]")
+	})
+
+	t.Run("Given a query reading other indices no request is sent", func(t *testing.T) {
+		c := newESQLClient(t, func(rw http.ResponseWriter, r *http.Request) {
+			t.Fatal("unexpected request")
+		})
+
+		for query, expected := range map[string]string{
+			"ROW a = 1":                                    "ES|QL queries must start with a FROM command reading the index of the data source",
+			"FROM secrets":                                 "ES|QL queries can only read the index of the data source, logs-*, got secrets",
+			"FROM logs-*, secrets METADATA _index":         "ES|QL queries can only read the index of the data source, logs-*, got secrets",
+			`FROM "remote:logs-*"`:                         "ES|QL queries can only read the index of the data source, logs-*, got remote:logs-*",
+			"FROM logs-* | ENRICH hosts ON host":           "ES|QL queries can only read the index of the data source, the ENRICH command is not supported",
+			"FROM logs-* // comment\n| lookup join s ON a": "ES|QL queries can only read the index of the data source, the LOOKUP command is not supported",
+		} {
+			_, err := c.ExecuteESQL(&ESQLRequest{Query: query})
+			require.EqualError(t, err, expected, query)
+		}
+	})
+}
+
+func TestCheckESQLIndices(t *testing.T) {
+	for _, query := range []string{
+		"FROM logs-*",
+		"from logs-* METADATA _id | KEEP _id",
+		`FROM "logs-*",metrics | WHERE host == "a|b" | EVAL s = """x | ENRICH y""" // | LOOKUP`,
+		"/* FROM secrets */ FROM metrics | STATS count = COUNT(*) BY `lookup`",
+	} {
+		require.NoError(t, checkESQLIndices(query, []string{"logs-*, metrics"}), query)
+	}
+
+	require.EqualError(t, checkESQLIndices("FROM logs-*", nil), "ES|QL queries require the index of the data source to be set")
+}
@@ -0,0 +1,101 @@
+package es
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// esqlJoinCommands are the processing commands reading other indices than the indices of the
+// source command.
+var esqlJoinCommands = []string{"ENRICH", "LOOKUP"}
+
+// checkESQLIndices returns an error when an ES|QL query reads other indices than the indices of
+// the data source. The query must start with a FROM command naming some of them.
+func checkESQLIndices(query string, dsIndices []string) error {
+	var indices []string
+	for _, index := range dsIndices {
+		for _, name := range strings.Split(index, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				indices = append(indices, name)
+			}
+		}
+	}
+	if len(indices) == 0 {
+		return fmt.Errorf("ES|QL queries require the index of the data source to be set")
+	}
+
+	commands := splitESQLCommands(query)
+	words := strings.Fields(commands[0])
+	if len(words) < 2 || !strings.EqualFold(words[0], "FROM") {
+		return fmt.Errorf("ES|QL queries must start with a FROM command reading the index of the data source")
+	}
+
+	source := strings.TrimSpace(commands[0][strings.Index(strings.ToUpper(commands[0]), "FROM")+len("FROM"):])
+	if i := strings.Index(strings.ToUpper(source), " METADATA "); i >= 0 {
+		source = source[:i]
+	}
+	for _, index := range strings.Split(source, ",") {
+		index = strings.Trim(strings.TrimSpace(index), `"`)
+		if !slices.Contains(indices, index) {
+			return fmt.Errorf("ES|QL queries can only read the index of the data source, %s, got %s", strings.Join(indices, ","), index)
+		}
+	}
+
+	for _, command := range commands[1:] {
+		words := strings.Fields(command)
+		if len(words) > 0 && slices.Contains(esqlJoinCommands, strings.ToUpper(words[0])) {
+			return fmt.Errorf("ES|QL queries can only read the index of the data source, the %s command is not supported", strings.ToUpper(words[0]))
+		}
+	}
+	return nil
+}
+
+// splitESQLCommands splits an ES|QL query into its commands, with comments removed.
+func splitESQLCommands(query string) []string {
+	var commands []string
+	var command strings.Builder
+	for i := 0; i < len(query); {
+		switch {
+		case query[i] == '|':
+			commands = append(commands, command.String())
+			command.Reset()
+			i++
+		case strings.HasPrefix(query[i:], "//"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			i += end
+		case strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i - 4
+			}
+			i += end + 4
+			command.WriteByte(' ')
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			if end < 0 {
+				end = len(query) - i - 6
+			}
+			command.WriteString(query[i : i+end+6])
+			i += end + 6
+		case query[i] == '"' || query[i] == '`':
+			quote := query[i]
+			end := i + 1
+			for ; end < len(query) && query[end] != quote; end++ {
+				if quote == '"' && query[end] == '\\' {
+					end++
+				}
+			}
+			end = min(end+1, len(query))
+			command.WriteString(query[i:end])
+			i = end
+		default:
+			command.WriteByte(query[i])
+			i++
+		}
+	}
+	return append(commands, command.String())
+}
@@ -245,6 +245,20 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation
+type CompositeAggregation struct {
+	Size    int              `json:"size"`
+	Sources []map[string]any `json:"sources"`
+	After   map[string]any   `json:"after,omitempty"`
+}
+
+// CompositeTermsSource represents a terms source of a composite aggregation
+type CompositeTermsSource struct {
+	Field         string `json:"field"`
+	Order         string `json:"order,omitempty"`
+	MissingBucket bool   `json:"missing_bucket,omitempty"`
+}
+
 // NestedAggregation represents a nested aggregation
 type NestedAggregation struct {
 	Path string `json:"path"`
@@ -334,3 +348,25 @@ func (a *PipelineAggregation) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(root)
 }
+
+// ESQLRequest represents an ES|QL query request
+type ESQLRequest struct {
+	Query    string `json:"query"`
+	Filter   Filter `json:"filter,omitempty"`
+	Columnar bool   `json:"columnar"`
+	// TimeRange selects the indices of the data source the query can read
+	TimeRange backend.TimeRange `json:"-"`
+}
+
+// ESQLColumn represents a column of an ES|QL query response
+type ESQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ESQLResponse represents an ES|QL query response. Values holds the values of
+// each column when the request is columnar.
+type ESQLResponse struct {
+	Columns []ESQLColumn `json:"columns"`
+	Values  [][]any      `json:"values"`
+}
@@ -0,0 +1,226 @@
+package elasticsearch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+// compositePageSize is the largest number of buckets requested per page of a composite aggregation.
+const compositePageSize = 1000
+
+// compositeSources returns the leading terms aggregations of a query that are sent as the sources of a
+// single composite aggregation. It returns nil when the query does not use composite aggregations.
+func compositeSources(q *Query) []*BucketAgg {
+	var sources []*BucketAgg
+	for _, bucketAgg := range q.BucketAggs {
+		if bucketAgg.Type != termsType || bucketAgg.Settings == nil || !bucketAgg.Settings.Get("useComposite").MustBool(false) {
+			break
+		}
+		sources = append(sources, bucketAgg)
+	}
+	return sources
+}
+
+// nextCompositePageSize returns the size of the next page of a composite aggregation having collected
+// buckets. One bucket more than the limit is requested, which tells whether the buckets are truncated.
+func nextCompositePageSize(limit, collected int) int {
+	return min(compositePageSize, limit+1-collected)
+}
+
+func addCompositeAgg(aggBuilder es.AggBuilder, sources []*BucketAgg, size int, after map[string]any) es.AggBuilder {
+	first := sources[0]
+	aggBuilder.Composite(first.ID, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		// the size of the terms aggregations doesn't apply, all the terms are paged through
+		a.Size = size
+		a.After = after
+
+		for _, source := range sources {
+			terms := es.CompositeTermsSource{Field: source.Field}
+			// composite buckets are always sorted by their keys, ordering by metrics is not supported
+			switch source.Settings.Get("orderBy").MustString() {
+			case "_term", "_key":
+				terms.Order = source.Settings.Get("order").MustString("desc")
+			}
+			if _, err := source.Settings.Get("missing").String(); err == nil {
+				terms.MissingBucket = true
+			}
+			a.Sources = append(a.Sources, map[string]any{source.ID: map[string]any{"terms": terms}})
+		}
+
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// compositePages holds the buckets of a composite aggregation collected across pages.
+type compositePages struct {
+	query     *Query
+	sources   []*BucketAgg
+	buckets   []map[string]any
+	afterKey  map[string]any
+	truncated bool
+}
+
+// collect appends the buckets of a response page and returns whether another page should be requested.
+func (p *compositePages) collect(res *es.SearchResponse, limit int) bool {
+	agg, _ := res.Aggregations[p.sources[0].ID].(map[string]any)
+	if agg == nil {
+		return false
+	}
+	buckets, _ := agg["buckets"].([]any)
+	for _, b := range buckets {
+		if bucket, ok := b.(map[string]any); ok {
+			p.buckets = append(p.buckets, bucket)
+		}
+	}
+
+	afterKey, _ := agg["after_key"].(map[string]any)
+	p.afterKey = afterKey
+	if len(p.buckets) > limit {
+		p.truncated = true
+		p.buckets = p.buckets[:limit]
+		return false
+	}
+	return afterKey != nil && len(buckets) > 0
+}
+
+// pageCompositeAggs requests the next pages of the composite aggregations of the responses, up to the
+// configured maximum number of buckets, and replaces the composite aggregations of the responses with
+// the equivalent nested terms aggregations. It returns the queries whose buckets were truncated.
+func (e *elasticsearchDataQuery) pageCompositeAggs(queries []*Query, responses []*es.SearchResponse) (map[string]bool, error) {
+	limit := e.client.GetMaxCompositeBuckets()
+	pages := make(map[int]*compositePages)
+	pending := make([]int, 0)
+	for i, q := range queries {
+		if i >= len(responses) || responses[i].Error != nil || isLogsQuery(q) || isDocumentQuery(q) {
+			continue
+		}
+		sources := compositeSources(q)
+		if len(sources) == 0 {
+			continue
+		}
+		pages[i] = &compositePages{query: q, sources: sources}
+		if pages[i].collect(responses[i], limit) {
+			pending = append(pending, i)
+		}
+	}
+
+	for len(pending) > 0 {
+		ms := e.client.MultiSearch()
+		for _, i := range pending {
+			q := queries[i]
+			q.compositeAfter = pages[i].afterKey
+			q.compositeSize = nextCompositePageSize(limit, len(pages[i].buckets))
+			from := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
+			to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+			if err := e.processQuery(q, ms, from, to); err != nil {
+				return nil, err
+			}
+		}
+
+		req, err := ms.Build()
+		if err != nil {
+			return nil, err
+		}
+		res, err := e.client.ExecuteMultisearch(req)
+		if err != nil {
+			return nil, err
+		}
+
+		next := make([]int, 0, len(pending))
+		for j, i := range pending {
+			if j >= len(res.Responses) {
+				break
+			}
+			if res.Responses[j].Error != nil {
+				responses[i] = res.Responses[j]
+				delete(pages, i)
+				continue
+			}
+			if pages[i].collect(res.Responses[j], limit) {
+				next = append(next, i)
+			}
+		}
+		pending = next
+	}
+
+	truncated := make(map[string]bool)
+	for i, p := range pages {
+		responses[i].Aggregations[p.sources[0].ID] = nestCompositeBuckets(p.buckets, p.sources)
+		if p.truncated {
+			truncated[p.query.RefID] = true
+		}
+	}
+	return truncated, nil
+}
+
+// nestCompositeBuckets converts the buckets of a composite aggregation into the buckets of nested terms
+// aggregations, one level per source, so that they are processed as terms aggregations.
+func nestCompositeBuckets(buckets []map[string]any, sources []*BucketAgg) map[string]any {
+	source := sources[0]
+	keys := make([]any, 0)
+	groups := make([][]map[string]any, 0)
+	index := make(map[string]int)
+	for _, bucket := range buckets {
+		compositeKey, _ := bucket["key"].(map[string]any)
+		key := compositeKey[source.ID]
+		if key == nil {
+			key = source.Settings.Get("missing").MustString()
+		}
+		id := fmt.Sprint(key)
+		i, ok := index[id]
+		if !ok {
+			i = len(groups)
+			index[id] = i
+			keys = append(keys, key)
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], bucket)
+	}
+
+	result := make([]any, 0, len(groups))
+	for i, group := range groups {
+		bucket := map[string]any{"key": keys[i]}
+		if len(sources) == 1 {
+			// composite keys are unique, the deepest level has a single bucket per key
+			for k, v := range group[0] {
+				if k != "key" {
+					bucket[k] = v
+				}
+			}
+		} else {
+			docCount := float64(0)
+			for _, b := range group {
+				if count, ok := b["doc_count"].(float64); ok {
+					docCount += count
+				}
+			}
+			bucket["doc_count"] = docCount
+			bucket[sources[1].ID] = nestCompositeBuckets(group, sources[1:])
+		}
+		result = append(result, bucket)
+	}
+	return map[string]any{"buckets": result}
+}
+
+// addCompositeNotices warns about the responses of queries whose composite buckets were truncated.
+func addCompositeNotices(result *backend.QueryDataResponse, truncated map[string]bool, limit int) {
+	for refID := range truncated {
+		res, ok := result.Responses[refID]
+		if !ok {
+			continue
+		}
+		for _, frame := range res.Frames {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results are limited to the first %d composite buckets. Increase the maximum number of composite buckets of the data source to get more results.", limit),
+			})
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package elasticsearch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const compositeQuery = `{
+	"bucketAggs": [
+		{ "type": "terms", "field": "host", "id": "2", "settings": { "size": "2", "useComposite": true, "orderBy": "_key", "order": "asc", "missing": "unknown" } },
+		{ "type": "terms", "field": "dc", "id": "3", "settings": { "useComposite": true } },
+		{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+	],
+	"metrics": [{ "type": "count", "id": "1" }]
+}`
+
+func compositeBucket(host, dc any, value float64) map[string]any {
+	return map[string]any{
+		"key":       map[string]any{"2": host, "3": dc},
+		"doc_count": value,
+		"4": map[string]any{
+			"buckets": []any{
+				map[string]any{"key": float64(1000), "doc_count": value},
+			},
+		},
+	}
+}
+
+func compositePage(afterKey map[string]any, buckets ...map[string]any) *es.MultiSearchResponse {
+	agg := map[string]any{"buckets": make([]any, 0, len(buckets))}
+	for _, b := range buckets {
+		agg["buckets"] = append(agg["buckets"].([]any), b)
+	}
+	if afterKey != nil {
+		agg["after_key"] = afterKey
+	}
+	return &es.MultiSearchResponse{
+		Responses: []*es.SearchResponse{{Aggregations: map[string]any{"2": agg}}},
+	}
+}
+
+func TestCompositeAggregations(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	t.Run("Leading terms aggregations are sent as a composite aggregation", func(t *testing.T) {
+		c := newFakeClient()
+		_, err := executeElasticsearchDataQuery(c, compositeQuery, from, to)
+		require.NoError(t, err)
+
+		sr := c.multisearchRequests[0].Requests[0]
+		require.Len(t, sr.Aggs, 1)
+		firstLevel := sr.Aggs[0]
+		require.Equal(t, "2", firstLevel.Key)
+		require.Equal(t, "composite", firstLevel.Aggregation.Type)
+		compositeAgg := firstLevel.Aggregation.Aggregation.(*es.CompositeAggregation)
+		require.Equal(t, compositePageSize, compositeAgg.Size)
+		require.Nil(t, compositeAgg.After)
+		require.Equal(t, []map[string]any{
+			{"2": map[string]any{"terms": es.CompositeTermsSource{Field: "host", Order: "asc", MissingBucket: true}}},
+			{"3": map[string]any{"terms": es.CompositeTermsSource{Field: "dc"}}},
+		}, compositeAgg.Sources)
+
+		secondLevel := firstLevel.Aggregation.Aggs[0]
+		require.Equal(t, "4", secondLevel.Key)
+		require.Equal(t, "@timestamp", secondLevel.Aggregation.Aggregation.(*es.DateHistogramAgg).Field)
+	})
+
+	t.Run("Terms aggregations without composite aggregations are not changed", func(t *testing.T) {
+		c := newFakeClient()
+		_, err := executeElasticsearchDataQuery(c, `{
+			"bucketAggs": [
+				{ "type": "terms", "field": "host", "id": "2" },
+				{ "type": "terms", "field": "dc", "id": "3", "settings": { "useComposite": true } },
+				{ "type": "date_histogram", "field": "@timestamp", "id": "4" }
+			],
+			"metrics": [{ "type": "count", "id": "1" }]
+		}`, from, to)
+		require.NoError(t, err)
+
+		sr := c.multisearchRequests[0].Requests[0]
+		require.Equal(t, "terms", sr.Aggs[0].Aggregation.Type)
+		require.Equal(t, "terms", sr.Aggs[0].Aggregation.Aggs[0].Aggregation.Type)
+	})
+
+	t.Run("Composite buckets are paged with the after key", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchPages = []*es.MultiSearchResponse{
+			compositePage(map[string]any{"2": "a", "3": "y"}, compositeBucket("a", "x", 1), compositeBucket("a", "y", 2)),
+			compositePage(map[string]any{"2": nil, "3": "x"}, compositeBucket("b", "x", 3), compositeBucket(nil, "x", 4)),
+			compositePage(nil),
+		}
+
+		res, err := executeElasticsearchDataQuery(c, compositeQuery, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, c.multisearchRequests, 3)
+		after := c.multisearchRequests[1].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation).After
+		require.Equal(t, map[string]any{"2": "a", "3": "y"}, after)
+		after = c.multisearchRequests[2].Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation).After
+		require.Equal(t, map[string]any{"2": nil, "3": "x"}, after)
+
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 4)
+		names := make([]string, 0, len(frames))
+		for _, frame := range frames {
+			names = append(names, frame.Name)
+			require.Empty(t, frame.Meta.Notices)
+		}
+		require.Equal(t, []string{"x a", "y a", "x b", "x unknown"}, names)
+	})
+
+	t.Run("Composite buckets are limited to the maximum number of buckets", func(t *testing.T) {
+		c := newFakeClient()
+		c.maxCompositeBuckets = 3
+		c.multiSearchPages = []*es.MultiSearchResponse{
+			compositePage(map[string]any{"2": "a", "3": "y"}, compositeBucket("a", "x", 1), compositeBucket("a", "y", 2)),
+			compositePage(map[string]any{"2": "b", "3": "y"}, compositeBucket("b", "x", 3), compositeBucket("b", "y", 4)),
+		}
+
+		res, err := executeElasticsearchDataQuery(c, compositeQuery, from, to)
+		require.NoError(t, err)
+
+		require.Len(t, c.multisearchRequests, 2)
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 3)
+		require.Equal(t, data.NoticeSeverityWarning, frames[0].Meta.Notices[0].Severity)
+		require.Contains(t, frames[0].Meta.Notices[0].Text, "limited to the first 3 composite buckets")
+	})
+
+	t.Run("Composite buckets are not truncated when the last page ends at the maximum number of buckets", func(t *testing.T) {
+		c := newFakeClient()
+		c.maxCompositeBuckets = 3
+		c.multiSearchPages = []*es.MultiSearchResponse{
+			compositePage(map[string]any{"2": "a", "3": "y"}, compositeBucket("a", "x", 1), compositeBucket("a", "y", 2)),
+			compositePage(map[string]any{"2": "b", "3": "x"}, compositeBucket("b", "x", 3)),
+			compositePage(nil),
+		}
+
+		res, err := executeElasticsearchDataQuery(c, compositeQuery, from, to)
+		require.NoError(t, err)
+
+		sizes := make([]int, 0, len(c.multisearchRequests))
+		for _, r := range c.multisearchRequests {
+			sizes = append(sizes, r.Requests[0].Aggs[0].Aggregation.Aggregation.(*es.CompositeAggregation).Size)
+		}
+		require.Equal(t, []int{4, 2, 1}, sizes)
+		frames := res.Responses["A"].Frames
+		require.Len(t, frames, 3)
+		for _, frame := range frames {
+			require.Empty(t, frame.Meta.Notices)
+		}
+	})
+
+	t.Run("Composite buckets are converted to nested terms buckets", func(t *testing.T) {
+		query, err := parseQuery([]backend.DataQuery{{JSON: []byte(compositeQuery)}}, log.New("test.logger"))
+		require.NoError(t, err)
+		nested := nestCompositeBuckets([]map[string]any{
+			compositeBucket("a", "x", 1), compositeBucket("a", "y", 2), compositeBucket(nil, "x", 4),
+		}, compositeSources(query[0]))
+
+		buckets := nested["buckets"].([]any)
+		require.Len(t, buckets, 2)
+		require.Equal(t, "a", buckets[0].(map[string]any)["key"])
+		require.Equal(t, float64(3), buckets[0].(map[string]any)["doc_count"])
+		require.Equal(t, "unknown", buckets[1].(map[string]any)["key"])
+
+		inner := buckets[0].(map[string]any)["3"].(map[string]any)["buckets"].([]any)
+		require.Len(t, inner, 2)
+		require.Equal(t, "y", inner[1].(map[string]any)["key"])
+		require.Equal(t, float64(2), inner[1].(map[string]any)["doc_count"])
+		require.Contains(t, inner[1].(map[string]any), "4")
+	})
+}
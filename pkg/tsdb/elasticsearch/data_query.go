@@ -50,8 +50,22 @@ var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, req
 func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 	start := time.Now()
 	response := backend.NewQueryDataResponse()
-	e.logger.Debug("Parsing queries", "queriesLength", len(e.dataQueries))
-	queries, err := parseQuery(e.dataQueries, e.logger)
+
+	// ES|QL queries are not part of the multisearch request
+	dataQueries := make([]backend.DataQuery, 0, len(e.dataQueries))
+	for _, q := range e.dataQueries {
+		if isESQLQuery(q) {
+			response.Responses[q.RefID] = e.executeESQLQuery(q)
+			continue
+		}
+		dataQueries = append(dataQueries, q)
+	}
+	if len(dataQueries) == 0 {
+		return response, nil
+	}
+
+	e.logger.Debug("Parsing queries", "queriesLength", len(dataQueries))
+	queries, err := parseQuery(dataQueries, e.logger)
 	if err != nil {
 		mq, _ := json.Marshal(e.dataQueries)
 		e.logger.Error("Failed to parse queries", "error", err, "queries", string(mq), "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
@@ -61,6 +75,7 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 	ms := e.client.MultiSearch()
 
 	for _, q := range queries {
+		q.compositeSize = nextCompositePageSize(e.client.GetMaxCompositeBuckets(), 0)
 		from := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
 		to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
 		if err := e.processQuery(q, ms, from, to); err != nil {
@@ -84,7 +99,20 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	truncated, err := e.pageCompositeAggs(queries, res.Responses)
+	if err != nil {
+		return errorsource.AddErrorToResponse(e.dataQueries[0].RefID, response, err), nil
+	}
+
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.keepLabelsInResponse, e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	addCompositeNotices(result, truncated, e.client.GetMaxCompositeBuckets())
+	for refID, res := range response.Responses {
+		result.Responses[refID] = res
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -406,9 +434,19 @@ func processDocumentQuery(q *Query, b *es.SearchRequestBuilder, from, to int64,
 
 func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64, defaultTimeField string) {
 	aggBuilder := b.Agg()
+	// Leading terms aggregations using composite aggregations are sent as a single composite aggregation
+	sources := compositeSources(q)
+	if len(sources) > 0 {
+		for _, bucketAgg := range sources {
+			bucketAgg.Settings = simplejson.NewFromAny(
+				bucketAgg.generateSettingsForDSL(),
+			)
+		}
+		aggBuilder = addCompositeAgg(aggBuilder, sources, q.compositeSize, q.compositeAfter)
+	}
 	// Process buckets
 	// iterate backwards to create aggregations bottom-down
-	for _, bucketAgg := range q.BucketAggs {
+	for _, bucketAgg := range q.BucketAggs[len(sources):] {
 		bucketAgg.Settings = simplejson.NewFromAny(
 			bucketAgg.generateSettingsForDSL(),
 		)
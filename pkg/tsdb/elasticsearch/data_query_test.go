@@ -1806,6 +1806,12 @@ type fakeClient struct {
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	// multiSearchPages are returned by the successive multisearch requests, before multiSearchResponse
+	multiSearchPages    []*es.MultiSearchResponse
+	maxCompositeBuckets int
+	esqlResponse        *es.ESQLResponse
+	esqlError           error
+	esqlRequests        []*es.ESQLRequest
 }
 
 func newFakeClient() *fakeClient {
@@ -1819,6 +1825,7 @@ func newFakeClient() *fakeClient {
 		configuredFields:    configuredFields,
 		multisearchRequests: make([]*es.MultiSearchRequest, 0),
 		multiSearchResponse: &es.MultiSearchResponse{},
+		maxCompositeBuckets: 10000,
 	}
 }
 
@@ -1828,9 +1835,23 @@ func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
 
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchPages) > 0 {
+		page := c.multiSearchPages[0]
+		c.multiSearchPages = c.multiSearchPages[1:]
+		return page, nil
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) GetMaxCompositeBuckets() int {
+	return c.maxCompositeBuckets
+}
+
+func (c *fakeClient) ExecuteESQL(r *es.ESQLRequest) (*es.ESQLResponse, error) {
+	c.esqlRequests = append(c.esqlRequests, r)
+	return c.esqlResponse, c.esqlError
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
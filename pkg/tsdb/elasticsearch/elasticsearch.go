@@ -36,6 +36,8 @@ const (
 	headerFromAlert = "FromAlert"
 	// this is the default value for the maxConcurrentShardRequests setting - it should be in sync with the default value in the datasource config settings
 	defaultMaxConcurrentShardRequests = int64(5)
+	// this is the default value for the maxCompositeBuckets setting - it should be in sync with the default value in the datasource config settings
+	defaultMaxCompositeBuckets = 10000
 )
 
 type Service struct {
@@ -159,6 +161,24 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			maxConcurrentShardRequests = defaultMaxConcurrentShardRequests
 		}
 
+		var maxCompositeBuckets int
+
+		switch v := jsonData["maxCompositeBuckets"].(type) {
+		case float64:
+			maxCompositeBuckets = int(v)
+		case string:
+			maxCompositeBuckets, err = strconv.Atoi(v)
+			if err != nil {
+				maxCompositeBuckets = defaultMaxCompositeBuckets
+			}
+		default:
+			maxCompositeBuckets = defaultMaxCompositeBuckets
+		}
+
+		if maxCompositeBuckets <= 0 {
+			maxCompositeBuckets = defaultMaxCompositeBuckets
+		}
+
 		includeFrozen, ok := jsonData["includeFrozen"].(bool)
 		if !ok {
 			includeFrozen = false
@@ -179,6 +199,7 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			ConfiguredFields:           configuredFields,
 			Interval:                   interval,
 			IncludeFrozen:              includeFrozen,
+			MaxCompositeBuckets:        maxCompositeBuckets,
 		}
 		return model, nil
 	}
@@ -15,6 +15,7 @@ import (
 type datasourceInfo struct {
 	TimeField                  any    `json:"timeField"`
 	MaxConcurrentShardRequests any    `json:"maxConcurrentShardRequests,omitempty"`
+	MaxCompositeBuckets        any    `json:"maxCompositeBuckets,omitempty"`
 	Interval                   string `json:"interval"`
 }
 
@@ -72,6 +73,38 @@ func TestNewInstanceSettings(t *testing.T) {
 		})
 	})
 
+	t.Run("maxCompositeBuckets", func(t *testing.T) {
+		tests := []struct {
+			name     string
+			value    any
+			expected int
+		}{
+			{name: "no maxCompositeBuckets", value: nil, expected: defaultMaxCompositeBuckets},
+			{name: "string maxCompositeBuckets", value: "20", expected: 20},
+			{name: "number maxCompositeBuckets", value: 20, expected: 20},
+			{name: "invalid maxCompositeBuckets", value: "x", expected: defaultMaxCompositeBuckets},
+			{name: "negative maxCompositeBuckets", value: -1, expected: defaultMaxCompositeBuckets},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				dsInfo := datasourceInfo{
+					TimeField:           "@timestamp",
+					MaxCompositeBuckets: tt.value,
+				}
+				settingsJSON, err := json.Marshal(dsInfo)
+				require.NoError(t, err)
+
+				dsSettings := backend.DataSourceInstanceSettings{
+					JSONData: json.RawMessage(settingsJSON),
+				}
+
+				instance, err := newInstanceSettings(httpclient.NewProvider())(context.Background(), dsSettings)
+				require.NoError(t, err)
+				require.Equal(t, tt.expected, instance.(es.DatasourceInfo).MaxCompositeBuckets)
+			})
+		}
+	})
+
 	t.Run("maxConcurrentShardRequests", func(t *testing.T) {
 		t.Run("no maxConcurrentShardRequests", func(t *testing.T) {
 			dsInfo := datasourceInfo{
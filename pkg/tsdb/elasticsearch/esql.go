@@ -0,0 +1,134 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/experimental/errorsource"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const esqlQueryType = "esql"
+
+func isESQLQuery(q backend.DataQuery) bool {
+	return q.QueryType == esqlQueryType
+}
+
+// executeESQLQuery runs an ES|QL query, limited to the time range of the query, and converts its
+// columnar response into a data frame.
+func (e *elasticsearchDataQuery) executeESQLQuery(q backend.DataQuery) backend.DataResponse {
+	model, err := simplejson.NewJson(q.JSON)
+	if err != nil {
+		return errorsource.Response(errorsource.PluginError(err, false))
+	}
+	query := strings.TrimSpace(model.Get("query").MustString())
+	if query == "" {
+		return errorsource.Response(errorsource.PluginError(errors.New("invalid ES|QL query, the query is empty"), false))
+	}
+
+	from := q.TimeRange.From.UnixNano() / int64(time.Millisecond)
+	to := q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	res, err := e.client.ExecuteESQL(&es.ESQLRequest{
+		Query:     query,
+		Filter:    &es.RangeFilter{Key: e.client.GetConfiguredFields().TimeField, Gte: from, Lte: to, Format: es.DateFormatEpochMS},
+		Columnar:  true,
+		TimeRange: q.TimeRange,
+	})
+	if err != nil {
+		// errors of the request carry their source, other errors are downstream errors
+		var sourceErr errorsource.Error
+		if !errors.As(err, &sourceErr) {
+			err = errorsource.DownstreamError(err, false)
+		}
+		return errorsource.Response(err)
+	}
+
+	frame, err := esqlResponseToFrame(q.RefID, res)
+	if err != nil {
+		return errorsource.Response(errorsource.DownstreamError(err, false))
+	}
+	frame.Meta.ExecutedQueryString = query
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// esqlResponseToFrame converts a columnar ES|QL response into a data frame with a field per column.
+func esqlResponseToFrame(refID string, res *es.ESQLResponse) (*data.Frame, error) {
+	if len(res.Values) != 0 && len(res.Values) != len(res.Columns) {
+		return nil, fmt.Errorf("invalid ES|QL response, got %d columns and %d value columns", len(res.Columns), len(res.Values))
+	}
+
+	frame := data.NewFrame(refID)
+	frame.Meta = &data.FrameMeta{}
+	hasTime, hasNumber := false, false
+	for i, column := range res.Columns {
+		var values []any
+		if len(res.Values) > 0 {
+			values = res.Values[i]
+		}
+
+		var field *data.Field
+		switch column.Type {
+		case "date", "date_nanos":
+			hasTime = true
+			times := make([]*time.Time, len(values))
+			for j, v := range values {
+				if s, ok := v.(string); ok {
+					t, err := time.Parse(time.RFC3339Nano, s)
+					if err != nil {
+						return nil, fmt.Errorf("invalid value of date column %q: %w", column.Name, err)
+					}
+					times[j] = &t
+				}
+			}
+			field = data.NewField(column.Name, nil, times)
+		case "double", "float", "half_float", "scaled_float", "long", "integer", "short", "byte", "unsigned_long", "counter_long", "counter_integer", "counter_double":
+			hasNumber = true
+			numbers := make([]*float64, len(values))
+			for j, v := range values {
+				if n, ok := v.(float64); ok {
+					numbers[j] = &n
+				}
+			}
+			field = data.NewField(column.Name, nil, numbers)
+		case "boolean":
+			booleans := make([]*bool, len(values))
+			for j, v := range values {
+				if b, ok := v.(bool); ok {
+					booleans[j] = &b
+				}
+			}
+			field = data.NewField(column.Name, nil, booleans)
+		default:
+			strs := make([]*string, len(values))
+			for j, v := range values {
+				switch v := v.(type) {
+				case nil:
+				case string:
+					strs[j] = &v
+				default:
+					// multi-valued fields and other types are returned as JSON
+					b, err := json.Marshal(v)
+					if err != nil {
+						return nil, err
+					}
+					s := string(b)
+					strs[j] = &s
+				}
+			}
+			field = data.NewField(column.Name, nil, strs)
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	if hasTime && hasNumber {
+		frame.Meta.Type = data.FrameTypeTimeSeriesLong
+	}
+	return frame, nil
+}
@@ -0,0 +1,144 @@
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestESQLQueries(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	execute := func(c es.Client, queries ...backend.DataQuery) (*backend.QueryDataResponse, error) {
+		for i := range queries {
+			queries[i].TimeRange = backend.TimeRange{From: from, To: to}
+		}
+		req := &backend.QueryDataRequest{Queries: queries}
+		return newElasticsearchDataQuery(context.Background(), c, req, log.New("test.logger"), tracing.InitializeTracerForTest()).execute()
+	}
+
+	t.Run("ES|QL queries are converted to data frames", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{
+			Columns: []es.ESQLColumn{
+				{Name: "@timestamp", Type: "date"},
+				{Name: "count", Type: "long"},
+				{Name: "host", Type: "keyword"},
+				{Name: "up", Type: "boolean"},
+				{Name: "tags", Type: "text"},
+			},
+			Values: [][]any{
+				{"2018-05-15T17:50:00.000Z", "2018-05-15T17:51:00.000Z"},
+				{float64(1), nil},
+				{"a", "b"},
+				{true, false},
+				{[]any{"x", "y"}, nil},
+			},
+		}
+
+		res, err := execute(c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{ "query": "FROM logs-* | STATS count = COUNT(*) BY @timestamp, host" }`),
+		})
+		require.NoError(t, err)
+		require.Empty(t, c.multisearchRequests)
+
+		require.Len(t, c.esqlRequests, 1)
+		require.Equal(t, "FROM logs-* | STATS count = COUNT(*) BY @timestamp, host", c.esqlRequests[0].Query)
+		require.True(t, c.esqlRequests[0].Columnar)
+		rangeFilter := c.esqlRequests[0].Filter.(*es.RangeFilter)
+		require.Equal(t, "@timestamp", rangeFilter.Key)
+		require.Equal(t, from.UnixMilli(), rangeFilter.Gte)
+		require.Equal(t, to.UnixMilli(), rangeFilter.Lte)
+
+		dataRes := res.Responses["A"]
+		require.NoError(t, dataRes.Error)
+		require.Len(t, dataRes.Frames, 1)
+		frame := dataRes.Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesLong, frame.Meta.Type)
+		require.Equal(t, "FROM logs-* | STATS count = COUNT(*) BY @timestamp, host", frame.Meta.ExecutedQueryString)
+		require.Len(t, frame.Fields, 5)
+
+		ts := time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC)
+		require.Equal(t, &ts, frame.Fields[0].At(1))
+		count := float64(1)
+		require.Equal(t, &count, frame.Fields[1].At(0))
+		require.Nil(t, frame.Fields[1].At(1))
+		host := "b"
+		require.Equal(t, &host, frame.Fields[2].At(1))
+		up := true
+		require.Equal(t, &up, frame.Fields[3].At(0))
+		tags := `["x","y"]`
+		require.Equal(t, &tags, frame.Fields[4].At(0))
+	})
+
+	t.Run("ES|QL queries run alongside other queries", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlResponse = &es.ESQLResponse{Columns: []es.ESQLColumn{{Name: "host", Type: "keyword"}}, Values: [][]any{{"a"}}}
+		c.multiSearchResponse = &es.MultiSearchResponse{Responses: []*es.SearchResponse{{
+			Aggregations: map[string]any{"2": map[string]any{"buckets": []any{}}},
+		}}}
+
+		res, err := execute(c,
+			backend.DataQuery{
+				RefID:     "A",
+				QueryType: esqlQueryType,
+				JSON:      json.RawMessage(`{ "query": "FROM logs-* | KEEP host" }`),
+			},
+			backend.DataQuery{
+				RefID: "B",
+				JSON: json.RawMessage(`{
+					"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+					"metrics": [{ "type": "count", "id": "1" }]
+				}`),
+			},
+		)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		require.Contains(t, res.Responses, "A")
+		require.Contains(t, res.Responses, "B")
+		require.Len(t, res.Responses["A"].Frames, 1)
+		require.Empty(t, res.Responses["A"].Frames[0].Meta.Type)
+		require.NoError(t, res.Responses["B"].Error)
+	})
+
+	t.Run("ES|QL errors are returned as downstream errors", func(t *testing.T) {
+		c := newFakeClient()
+		c.esqlError = errors.New("connection refused")
+
+		res, err := execute(c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{ "query": "FROM logs-*" }`),
+		})
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses["A"].Error, "connection refused")
+		require.Equal(t, backend.ErrorSourceDownstream, res.Responses["A"].ErrorSource)
+	})
+
+	t.Run("Empty ES|QL queries are rejected", func(t *testing.T) {
+		c := newFakeClient()
+
+		res, err := execute(c, backend.DataQuery{
+			RefID:     "A",
+			QueryType: esqlQueryType,
+			JSON:      json.RawMessage(`{ "query": " " }`),
+		})
+		require.NoError(t, err)
+		require.Empty(t, c.esqlRequests)
+		require.EqualError(t, res.Responses["A"].Error, "invalid ES|QL query, the query is empty")
+	})
+}
@@ -564,11 +564,12 @@ type TermsOrder string
 
 // TermsSettings defines model for TermsSettings.
 type TermsSettings struct {
-	MinDocCount *string     `json:"min_doc_count,omitempty"`
-	Missing     *string     `json:"missing,omitempty"`
-	Order       *TermsOrder `json:"order,omitempty"`
-	OrderBy     *string     `json:"orderBy,omitempty"`
-	Size        *string     `json:"size,omitempty"`
+	MinDocCount  *string     `json:"min_doc_count,omitempty"`
+	Missing      *string     `json:"missing,omitempty"`
+	Order        *TermsOrder `json:"order,omitempty"`
+	OrderBy      *string     `json:"orderBy,omitempty"`
+	Size         *string     `json:"size,omitempty"`
+	UseComposite *bool       `json:"useComposite,omitempty"`
 }
 
 // TopMetrics defines model for TopMetrics.
@@ -18,6 +18,11 @@ type Query struct {
 	RefID         string
 	MaxDataPoints int64
 	TimeRange     backend.TimeRange
+
+	// compositeAfter is the after key of the next page of a composite aggregation
+	compositeAfter map[string]any
+	// compositeSize is the number of buckets of the next page of a composite aggregation
+	compositeSize int
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -83,6 +83,7 @@ func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit in
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 		RowLimit:          rowLimit,
+		SessionHandler:    &postgresSessionHandler{},
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{}
@@ -93,8 +94,12 @@ func newPostgres(ctx context.Context, userFacingDefaultError string, rowLimit in
 	db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
 	db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
 
-	handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &queryResultTransformer, newPostgresMacroEngine(dsInfo.JsonData.Timescaledb),
-		logger)
+	macroEngine := newPostgresMacroEngine(dsInfo.JsonData.Timescaledb)
+	if dsInfo.JsonData.ReadOnly {
+		macroEngine = &readOnlyMacroEngine{SQLMacroEngine: macroEngine}
+	}
+
+	handler, err := sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &queryResultTransformer, macroEngine, logger)
 	if err != nil {
 		logger.Error("Failed connecting to Postgres", "err", err)
 		return nil, nil, err
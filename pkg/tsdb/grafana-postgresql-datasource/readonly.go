@@ -0,0 +1,172 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+)
+
+// readOnlyMacroEngine rejects the queries of read-only data sources which control transactions.
+// The driver sends queries without parameters with the simple query protocol, which runs every
+// statement of a query, so a COMMIT would end the read-only transaction and let the following
+// statements modify the database.
+type readOnlyMacroEngine struct {
+	sqleng.SQLMacroEngine
+}
+
+func (m *readOnlyMacroEngine) Interpolate(query *backend.DataQuery, timeRange backend.TimeRange, sql string) (string, error) {
+	sql, err := m.SQLMacroEngine.Interpolate(query, timeRange, sql)
+	if err != nil {
+		return "", err
+	}
+	if err := checkReadOnlyStatements(sql); err != nil {
+		return "", err
+	}
+	return sql, nil
+}
+
+// transactionControlCommands are the commands starting, ending or changing transactions.
+var transactionControlCommands = map[string]bool{
+	"ABORT":     true,
+	"BEGIN":     true,
+	"COMMIT":    true,
+	"END":       true,
+	"RELEASE":   true,
+	"ROLLBACK":  true,
+	"SAVEPOINT": true,
+	"START":     true,
+}
+
+// checkReadOnlyStatements returns an error when a statement of sql controls transactions, or
+// changes whether transactions are read-only.
+func checkReadOnlyStatements(sql string) error {
+	for _, statement := range splitStatements(sql) {
+		words := strings.Fields(statement)
+		if len(words) == 0 {
+			continue
+		}
+		rejected := transactionControlCommands[words[0]]
+		switch words[0] {
+		case "PREPARE":
+			rejected = len(words) > 1 && words[1] == "TRANSACTION"
+		case "SET", "RESET":
+			rejected = strings.Contains(statement, "TRANSACTION") || strings.Contains(statement, "CHARACTERISTICS") ||
+				strings.Contains(statement, "READ_ONLY") || (len(words) > 1 && words[1] == "ALL")
+		}
+		if rejected {
+			return fmt.Errorf("%s statements are not allowed in read-only mode", words[0])
+		}
+	}
+	return nil
+}
+
+// splitStatements splits sql into its statements, with comments, string constants, quoted
+// identifiers and dollar-quoted strings removed and the rest in upper case.
+func splitStatements(sql string) []string {
+	var statements []string
+	var statement strings.Builder
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ';':
+			statements = append(statements, strings.ToUpper(statement.String()))
+			statement.Reset()
+			i++
+			continue
+		case c == '-' && strings.HasPrefix(sql[i:], "--"):
+			i = skipLineComment(sql, i)
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			i = skipBlockComment(sql, i)
+		case c == '\'':
+			escapes := i > 0 && (sql[i-1] == 'e' || sql[i-1] == 'E') && (i == 1 || !isIdentifierChar(sql[i-2]))
+			i = skipQuoted(sql, i, '\'', escapes)
+		case c == '"':
+			i = skipQuoted(sql, i, '"', false)
+		case c == '$' && (i == 0 || !isIdentifierChar(sql[i-1])):
+			if end, ok := skipDollarQuoted(sql, i); ok {
+				i = end
+			} else {
+				statement.WriteByte(c)
+				i++
+				continue
+			}
+		default:
+			statement.WriteByte(c)
+			i++
+			continue
+		}
+		// separate the words around the removed text
+		statement.WriteByte(' ')
+	}
+	return append(statements, strings.ToUpper(statement.String()))
+}
+
+func skipLineComment(sql string, i int) int {
+	if end := strings.IndexByte(sql[i:], '\n'); end >= 0 {
+		return i + end + 1
+	}
+	return len(sql)
+}
+
+// skipBlockComment skips a block comment, which can be nested.
+func skipBlockComment(sql string, i int) int {
+	depth := 0
+	for i < len(sql) {
+		switch {
+		case strings.HasPrefix(sql[i:], "/*"):
+			depth++
+			i += 2
+		case strings.HasPrefix(sql[i:], "*/"):
+			depth--
+			i += 2
+			if depth == 0 {
+				return i
+			}
+		default:
+			i++
+		}
+	}
+	return len(sql)
+}
+
+// skipQuoted skips a string constant or a quoted identifier, where doubled quotes are part of
+// the text, and backslashes escape the next character in escape string constants.
+func skipQuoted(sql string, i int, quote byte, escapes bool) int {
+	for i++; i < len(sql); i++ {
+		switch {
+		case escapes && sql[i] == '\\':
+			i++
+		case sql[i] == quote:
+			if i+1 < len(sql) && sql[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(sql)
+}
+
+// skipDollarQuoted skips a dollar-quoted string, $tag$...$tag$. It returns false when the
+// dollar sign doesn't start one, as in positional parameters.
+func skipDollarQuoted(sql string, i int) (int, bool) {
+	end := i + 1
+	for end < len(sql) && isIdentifierChar(sql[end]) && sql[end] != '$' {
+		end++
+	}
+	if end >= len(sql) || sql[end] != '$' || (end > i+1 && sql[i+1] >= '0' && sql[i+1] <= '9') {
+		return 0, false
+	}
+	tag := sql[i : end+1]
+	if closing := strings.Index(sql[end+1:], tag); closing >= 0 {
+		return end + 1 + closing + len(tag), true
+	}
+	return len(sql), true
+}
+
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnlyStatements(t *testing.T) {
+	t.Run("allows queries", func(t *testing.T) {
+		for _, sql := range []string{
+			"SELECT 1",
+			"SELECT 1; SELECT 2;",
+			"WITH t AS (SELECT 1) SELECT * FROM t",
+			"SET LOCAL search_path = metrics; SELECT 1",
+			"SELECT 'COMMIT'; SELECT \"rollback\" FROM t",
+			"SELECT 'it''s; COMMIT'",
+			"SELECT E'\\'; COMMIT; --'",
+			"SELECT $$; COMMIT; $$, $tag$ ; END $tag$",
+			"SELECT $1, a$b FROM t; -- COMMIT",
+			"/* /* COMMIT; */ ; END */ SELECT 1",
+			"SELECT 1 AS begin_time, 2 AS \"end\"",
+		} {
+			require.NoError(t, checkReadOnlyStatements(sql), sql)
+		}
+	})
+
+	t.Run("rejects transaction control statements", func(t *testing.T) {
+		for _, sql := range []string{
+			"COMMIT; DELETE FROM t",
+			"SELECT 1; commit; DELETE FROM t",
+			"SELECT 1;END;DELETE FROM t",
+			"SELECT 1; ROLLBACK; BEGIN; DELETE FROM t",
+			"SELECT 1; ABORT",
+			"SELECT 1; START TRANSACTION READ WRITE",
+			"SELECT 1; SAVEPOINT s; RELEASE SAVEPOINT s",
+			"SELECT 1; PREPARE TRANSACTION 'tx'",
+			"SET TRANSACTION READ WRITE; DELETE FROM t",
+			"SET SESSION CHARACTERISTICS AS TRANSACTION READ WRITE",
+			"SET default_transaction_read_only = off",
+			"RESET ALL",
+			"SELECT 'a'/**/;/**/COMMIT",
+			"SELECT 'it''s'; COMMIT",
+			"SELECT e'\\\\'; COMMIT",
+			"SELECT 'a\\'; COMMIT; --'",
+			"SELECT $$ $$; COMMIT",
+		} {
+			require.Error(t, checkReadOnlyStatements(sql), sql)
+		}
+	})
+
+	t.Run("checks the interpolated query", func(t *testing.T) {
+		engine := &readOnlyMacroEngine{SQLMacroEngine: newPostgresMacroEngine(false)}
+
+		sql, err := engine.Interpolate(&backend.DataQuery{}, backend.TimeRange{}, "SELECT $__timeEpoch(time)")
+		require.NoError(t, err)
+		require.Equal(t, `SELECT extract(epoch from time) as "time"`, sql)
+
+		_, err = engine.Interpolate(&backend.DataQuery{}, backend.TimeRange{}, "SELECT 1; COMMIT; DELETE FROM t")
+		require.EqualError(t, err, "COMMIT statements are not allowed in read-only mode")
+	})
+}
@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+)
+
+// postgresSessionHandler runs the queries of read-only data sources, or having a query timeout,
+// in transactions. The driver cancels the statement of a cancelled query on the server.
+type postgresSessionHandler struct{}
+
+func (h *postgresSessionHandler) TxOptions(jsonData sqleng.JsonData) *sql.TxOptions {
+	if !jsonData.ReadOnly && jsonData.QueryTimeout <= 0 {
+		return nil
+	}
+	// the driver starts read-only transactions with BEGIN READ ONLY, which only applies to the
+	// current transaction and doesn't leak to the pooled connection.
+	return &sql.TxOptions{ReadOnly: jsonData.ReadOnly}
+}
+
+func (h *postgresSessionHandler) Prepare(ctx context.Context, tx *sql.Tx, jsonData sqleng.JsonData) (func() error, error) {
+	if jsonData.QueryTimeout > 0 {
+		// SET LOCAL is reset when the transaction ends
+		timeout := time.Duration(jsonData.QueryTimeout) * time.Second
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
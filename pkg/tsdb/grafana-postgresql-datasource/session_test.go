@@ -0,0 +1,58 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/grafana-postgresql-datasource/sqleng"
+)
+
+func TestPostgresSessionHandler(t *testing.T) {
+	t.Run("queries run in transactions only for read-only data sources or with a query timeout", func(t *testing.T) {
+		handler := &postgresSessionHandler{}
+		require.Nil(t, handler.TxOptions(sqleng.JsonData{}))
+		require.Equal(t, &sql.TxOptions{ReadOnly: true}, handler.TxOptions(sqleng.JsonData{ReadOnly: true}))
+		require.Equal(t, &sql.TxOptions{}, handler.TxOptions(sqleng.JsonData{QueryTimeout: 10}))
+	})
+
+	for _, tc := range []struct {
+		desc       string
+		jsonData   sqleng.JsonData
+		statements []string
+	}{
+		{
+			desc:     "read-only transactions have no session statements",
+			jsonData: sqleng.JsonData{ReadOnly: true},
+		},
+		{
+			desc:       "the query timeout is set for the transaction",
+			jsonData:   sqleng.JsonData{ReadOnly: true, QueryTimeout: 10},
+			statements: []string{"SET LOCAL statement_timeout = 10000"},
+		},
+	} {
+		t.Run(tc.desc, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = db.Close() })
+			handler := &postgresSessionHandler{}
+
+			mock.ExpectBegin()
+			for _, statement := range tc.statements {
+				mock.ExpectExec(statement).WillReturnResult(sqlmock.NewResult(0, 0))
+			}
+			mock.ExpectRollback()
+
+			tx, err := db.BeginTx(context.Background(), handler.TxOptions(tc.jsonData))
+			require.NoError(t, err)
+			stop, err := handler.Prepare(context.Background(), tx, tc.jsonData)
+			require.NoError(t, err)
+			require.Nil(t, stop)
+			require.NoError(t, tx.Rollback())
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
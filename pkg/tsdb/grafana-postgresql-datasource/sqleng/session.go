@@ -0,0 +1,128 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+)
+
+// SessionHandler prepares the database session running the queries of a data source, to enforce
+// its read-only mode and query timeout on the database server.
+type SessionHandler interface {
+	// TxOptions returns the options of the transaction running a query. Queries run outside of
+	// transactions when it returns nil.
+	TxOptions(jsonData JsonData) *sql.TxOptions
+	// Prepare runs the statements setting up the transaction before a query. The returned
+	// function, if any, stops the statement running in the transaction on the server and is
+	// called when the query is cancelled or times out.
+	Prepare(ctx context.Context, tx *sql.Tx, jsonData JsonData) (func() error, error)
+}
+
+// queryTimeout returns the timeout of the queries of the data source, 0 means no timeout.
+func (e *DataSourceHandler) queryTimeout() time.Duration {
+	return time.Duration(e.dsInfo.JsonData.QueryTimeout) * time.Second
+}
+
+// querySession is a query running in the session of a data source.
+type querySession struct {
+	ctx     context.Context
+	rows    *sql.Rows
+	release func()
+	timeout time.Duration
+}
+
+// close closes the rows of the query and releases its session.
+func (s *querySession) close(logger log.Logger) {
+	if err := s.rows.Close(); err != nil {
+		logger.Warn("Failed to close rows", "err", err)
+	}
+	s.release()
+}
+
+// err replaces the error of a query cancelled by the query timeout of the data source.
+func (s *querySession) err(err error) error {
+	if s.timeout > 0 && errors.Is(s.ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("query exceeded the timeout of %s: %w", s.timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// query runs a query in the session of the data source, the session has to be closed once the
+// rows are read.
+func (e *DataSourceHandler) query(ctx context.Context, query string) (*querySession, error) {
+	session := &querySession{release: func() {}, timeout: e.queryTimeout()}
+	if session.timeout > 0 {
+		ctx, session.release = context.WithTimeout(ctx, session.timeout)
+	}
+	session.ctx = ctx
+
+	var txOptions *sql.TxOptions
+	if e.sessionHandler != nil {
+		txOptions = e.sessionHandler.TxOptions(e.dsInfo.JsonData)
+	}
+	if txOptions == nil {
+		rows, err := e.db.QueryContext(ctx, query)
+		if err != nil {
+			session.release()
+			return nil, session.err(err)
+		}
+		session.rows = rows
+		return session, nil
+	}
+
+	tx, err := e.db.BeginTx(ctx, txOptions)
+	if err != nil {
+		session.release()
+		return nil, session.err(err)
+	}
+
+	stop, err := e.sessionHandler.Prepare(ctx, tx, e.dsInfo.JsonData)
+	if err != nil {
+		_ = tx.Rollback()
+		session.release()
+		return nil, session.err(err)
+	}
+
+	done := make(chan struct{})
+	if stop != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				if err := stop(); err != nil {
+					e.log.Warn("Failed to stop the query on the server", "error", err)
+				}
+			case <-done:
+			}
+		}()
+	}
+
+	cancel := session.release
+	session.release = func() {
+		close(done)
+		// changes of read-only data sources are rolled back, for databases without read-only
+		// transactions. Other data sources keep the behavior of queries running outside of
+		// transactions.
+		var err error
+		if e.dsInfo.JsonData.ReadOnly {
+			err = tx.Rollback()
+		} else {
+			err = tx.Commit()
+		}
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			e.log.Debug("Failed to end the query transaction", "error", err)
+		}
+		cancel()
+	}
+
+	rows, err := tx.QueryContext(ctx, query)
+	if err != nil {
+		session.release()
+		return nil, session.err(err)
+	}
+	session.rows = rows
+	return session, nil
+}
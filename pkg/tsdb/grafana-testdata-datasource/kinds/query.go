@@ -65,6 +65,7 @@ const (
 	TestDataQueryTypeRandomWalkTable              TestDataQueryType = "random_walk_table"
 	TestDataQueryTypeRandomWalkWithError          TestDataQueryType = "random_walk_with_error"
 	TestDataQueryTypeRawFrame                     TestDataQueryType = "raw_frame"
+	TestDataQueryTypeReplay                       TestDataQueryType = "replay"
 	TestDataQueryTypeServerError500               TestDataQueryType = "server_error_500"
 	TestDataQueryTypeSimulation                   TestDataQueryType = "simulation"
 	TestDataQueryTypeSlowQuery                    TestDataQueryType = "slow_query"
@@ -110,6 +111,7 @@ type TestDataQuery struct {
 
 	Nodes     *NodesQuery      `json:"nodes,omitempty"`
 	PulseWave *PulseWaveQuery  `json:"pulseWave,omitempty"`
+	Replay    *ReplayQuery     `json:"replay,omitempty"`
 	Sim       *SimulationQuery `json:"sim,omitempty"`
 	Stream    *StreamingQuery  `json:"stream,omitempty"`
 	Usa       *USAQuery        `json:"usa,omitempty"`
@@ -139,6 +141,27 @@ type PulseWaveQuery struct {
 	TimeStep int64   `json:"timeStep,omitempty"`
 }
 
+// ReplayAlign defines model for ReplayQuery.Align.
+// +enum
+type ReplayAlign string
+
+const (
+	ReplayAlignEnd   ReplayAlign = "end"
+	ReplayAlignStart ReplayAlign = "start"
+)
+
+// ReplayQuery defines model for ReplayQuery.
+type ReplayQuery struct {
+	// Name of the recording to replay
+	Name string `json:"name"`
+	// RefID of the recorded query, defaults to the refId of the query
+	RefID string `json:"refId,omitempty"`
+	// Align the end (default) or the start of the recording with the requested time range
+	Align ReplayAlign `json:"align,omitempty"`
+	// Stream the recording over Grafana Live after the initial response
+	Stream bool `json:"stream,omitempty"`
+}
+
 // SimulationQuery defines model for SimulationQuery.
 type SimulationQuery struct {
 	Config map[string]any `json:"config,omitempty"`
@@ -22,6 +22,7 @@ func TestQueryTypeDefinitions(t *testing.T) {
 				reflect.TypeOf(StreamingQueryTypeFetch),      // pick an example value (not the root)
 				reflect.TypeOf(ErrorTypeServerPanic),         // pick an example value (not the root)
 				reflect.TypeOf(TestDataQueryTypeAnnotations), // pick an example value (not the root)
+				reflect.TypeOf(ReplayAlignEnd),               // pick an example value (not the root)
 			},
 		})
 	require.NoError(t, err)
@@ -0,0 +1,254 @@
+package testdatasource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+)
+
+const replayStreamPathPrefix = "replay/"
+
+func (s *Service) handleReplayScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			continue
+		}
+		resp.Responses[q.RefID] = s.replayQuery(ctx, req.PluginContext, q, model.Replay)
+	}
+
+	return resp, nil
+}
+
+func (s *Service) replayQuery(ctx context.Context, pCtx backend.PluginContext, q backend.DataQuery, replay *kinds.ReplayQuery) backend.DataResponse {
+	if replay == nil || replay.Name == "" {
+		return backend.ErrDataResponse(backend.StatusBadRequest, "missing recording name")
+	}
+
+	refID := replay.RefID
+	if refID == "" {
+		refID = q.RefID
+	}
+
+	recording, recorded, err := s.loadRecordedResponse(ctx, replay.Name, refID)
+	if err != nil {
+		if errors.Is(err, recordings.ErrNotFound) {
+			return backend.ErrDataResponse(backend.StatusNotFound, err.Error())
+		}
+		return backend.ErrDataResponse(backend.StatusInternal, err.Error())
+	}
+
+	// recorded errors are replayed as well
+	if recorded.Error != nil {
+		return recorded
+	}
+
+	offset := q.TimeRange.To.Sub(recording.To)
+	if replay.Align == kinds.ReplayAlignStart {
+		offset = q.TimeRange.From.Sub(recording.From)
+	}
+
+	frames := make(data.Frames, 0, len(recorded.Frames))
+	for i, frame := range recorded.Frames {
+		shiftFrameTimes(frame, offset)
+		frame, err = filterFrameByTimeRange(frame, q.TimeRange)
+		if err != nil {
+			return backend.ErrDataResponse(backend.StatusInternal, err.Error())
+		}
+
+		if replay.Stream && pCtx.DataSourceInstanceSettings != nil {
+			if frame.Meta == nil {
+				frame.Meta = &data.FrameMeta{}
+			}
+			frame.Meta.Channel = fmt.Sprintf("ds/%s/%s%s/%s/%d", pCtx.DataSourceInstanceSettings.UID, replayStreamPathPrefix, recording.Name, refID, i)
+		}
+		frames = append(frames, frame)
+	}
+
+	return backend.DataResponse{
+		Frames: frames,
+		Status: recorded.Status,
+	}
+}
+
+// loadRecordedResponse loads the response of a recording for the user of the request, who must be
+// able to query the data source of the recording.
+func (s *Service) loadRecordedResponse(ctx context.Context, name string, refID string) (*recordings.Recording, backend.DataResponse, error) {
+	if s.recordings == nil {
+		return nil, backend.DataResponse{}, errors.New("recordings are not available")
+	}
+	user, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, backend.DataResponse{}, fmt.Errorf("replaying recordings requires a signed in user: %w", err)
+	}
+
+	recording, err := s.recordings.GetRecording(ctx, user, name)
+	if err != nil {
+		return nil, backend.DataResponse{}, err
+	}
+
+	if recording.Response == nil {
+		return nil, backend.DataResponse{}, fmt.Errorf("%w: %s has no response", recordings.ErrNotFound, name)
+	}
+	recorded, ok := recording.Response.Responses[refID]
+	if !ok {
+		return nil, backend.DataResponse{}, fmt.Errorf("%w: %s has no response for refId %s", recordings.ErrNotFound, name, refID)
+	}
+	return recording, recorded, nil
+}
+
+// shiftFrameTimes moves every time value of the frame by offset.
+func shiftFrameTimes(frame *data.Frame, offset time.Duration) {
+	for _, field := range frame.Fields {
+		switch field.Type() {
+		case data.FieldTypeTime:
+			for i := 0; i < field.Len(); i++ {
+				field.Set(i, field.At(i).(time.Time).Add(offset))
+			}
+		case data.FieldTypeNullableTime:
+			for i := 0; i < field.Len(); i++ {
+				if v, ok := field.ConcreteAt(i); ok {
+					t := v.(time.Time).Add(offset)
+					field.Set(i, &t)
+				}
+			}
+		}
+	}
+}
+
+// filterFrameByTimeRange drops the rows outside the time range. Frames without a time field are
+// returned as they are.
+func filterFrameByTimeRange(frame *data.Frame, timeRange backend.TimeRange) (*data.Frame, error) {
+	timeIndices := frame.TypeIndices(data.FieldTypeTime)
+	if len(timeIndices) == 0 {
+		return frame, nil
+	}
+
+	return frame.FilterRowsByField(timeIndices[0], func(v any) (bool, error) {
+		t := v.(time.Time)
+		return !t.Before(timeRange.From) && !t.After(timeRange.To), nil
+	})
+}
+
+// replayStreamFrame loads the recorded frame for a stream path in the
+// form replay/<name>/<refId>/<frame index>.
+func (s *Service) replayStreamFrame(ctx context.Context, path string) (*data.Frame, int, error) {
+	parts := strings.Split(strings.TrimPrefix(path, replayStreamPathPrefix), "/")
+	if len(parts) != 3 {
+		return nil, 0, fmt.Errorf("invalid replay path: %s", path)
+	}
+	frameIdx, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid frame index in replay path: %s", path)
+	}
+
+	_, recorded, err := s.loadRecordedResponse(ctx, parts[0], parts[1])
+	if err != nil {
+		return nil, 0, err
+	}
+	if frameIdx < 0 || frameIdx >= len(recorded.Frames) {
+		return nil, 0, fmt.Errorf("%w: %s has no frame %d", recordings.ErrNotFound, parts[0], frameIdx)
+	}
+
+	frame := recorded.Frames[frameIdx]
+	timeIndices := frame.TypeIndices(data.FieldTypeTime)
+	if len(timeIndices) == 0 {
+		return nil, 0, fmt.Errorf("recorded frame %d of %s has no time field", frameIdx, parts[0])
+	}
+	frame.Meta = nil
+	return frame, timeIndices[0], nil
+}
+
+func (s *Service) subscribeReplayStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	frame, _, err := s.replayStreamFrame(ctx, req.Path)
+	if err != nil {
+		s.logger.FromContext(ctx).Debug("Unable to subscribe to replay stream", "path", req.Path, "error", err)
+		return &backend.SubscribeStreamResponse{
+			Status: backend.SubscribeStreamStatusNotFound,
+		}, nil
+	}
+
+	initialData, err := backend.NewInitialFrame(frame.EmptyCopy(), data.IncludeSchemaOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	return &backend.SubscribeStreamResponse{
+		Status:      backend.SubscribeStreamStatusOK,
+		InitialData: initialData,
+	}, nil
+}
+
+// runReplayStream sends the rows of a recorded frame one at a time, keeping the
+// original spacing between them and starting again from the first row at the end.
+func (s *Service) runReplayStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	frame, timeIdx, err := s.replayStreamFrame(ctx, req.Path)
+	if err != nil {
+		return err
+	}
+
+	rowCount, err := frame.RowLen()
+	if err != nil {
+		return err
+	}
+	if rowCount == 0 {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	timeField := frame.Fields[timeIdx]
+	rows := make([]int, rowCount)
+	for i := range rows {
+		rows[i] = i
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return timeField.At(rows[i]).(time.Time).Before(timeField.At(rows[j]).(time.Time))
+	})
+
+	first := timeField.At(rows[0]).(time.Time)
+	last := timeField.At(rows[rowCount-1]).(time.Time)
+	step := time.Second
+	if rowCount > 1 && last.After(first) {
+		step = last.Sub(first) / time.Duration(rowCount-1)
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	<-timer.C
+
+	start := time.Now()
+	for {
+		for _, row := range rows {
+			at := start.Add(timeField.At(row).(time.Time).Sub(first))
+			timer.Reset(time.Until(at))
+			select {
+			case <-ctx.Done():
+				s.logger.FromContext(ctx).Debug("Stop streaming data for path", "path", req.Path)
+				return ctx.Err()
+			case <-timer.C:
+			}
+
+			values := frame.RowCopy(row)
+			values[timeIdx] = at
+			out := frame.EmptyCopy()
+			out.AppendRow(values...)
+			if err := sender.SendFrame(out, data.IncludeDataOnly); err != nil {
+				return err
+			}
+		}
+		start = start.Add(last.Sub(first) + step)
+	}
+}
@@ -0,0 +1,143 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
+)
+
+type fakeRecordingStore struct {
+	recordings map[string]*recordings.Recording
+	// queryable are the data sources the users can query
+	queryable []string
+}
+
+func (f *fakeRecordingStore) GetRecording(_ context.Context, _ identity.Requester, name string) (*recordings.Recording, error) {
+	// every call gets a fresh copy, like a real store would return
+	r, ok := f.recordings[name]
+	if !ok || !slices.Contains(f.queryable, r.DatasourceUID) {
+		return nil, recordings.ErrNotFound
+	}
+	b, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+	out := &recordings.Recording{}
+	return out, json.Unmarshal(b, out)
+}
+
+func TestReplayScenario(t *testing.T) {
+	recordedFrom := time.Date(2024, time.March, 1, 10, 0, 0, 0, time.UTC)
+	recordedTo := recordedFrom.Add(3 * time.Minute)
+
+	s := &Service{
+		recordings: &fakeRecordingStore{
+			queryable: []string{"prom"},
+			recordings: map[string]*recordings.Recording{
+				"private": {Name: "private", DatasourceUID: "loki", Response: backend.NewQueryDataResponse()},
+				"incident": {
+					Name:          "incident",
+					DatasourceUID: "prom",
+					From:          recordedFrom,
+					To:            recordedTo,
+					Response: &backend.QueryDataResponse{
+						Responses: backend.Responses{
+							"A": backend.DataResponse{
+								Frames: data.Frames{data.NewFrame("cpu",
+									data.NewField("time", nil, []time.Time{recordedFrom, recordedFrom.Add(time.Minute), recordedFrom.Add(2 * time.Minute), recordedTo}),
+									data.NewField("value", nil, []float64{1, 2, 3, 4}),
+								)},
+							},
+							"B": backend.ErrDataResponse(backend.StatusBadRequest, "bad query"),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	ctx := identity.WithRequester(context.Background(), &user.SignedInUser{OrgID: 1})
+	query := func(replay kinds.ReplayQuery, from, to time.Time) backend.DataResponse {
+		t.Helper()
+		raw, err := json.Marshal(kinds.TestDataQuery{ScenarioId: kinds.TestDataQueryTypeReplay, Replay: &replay})
+		require.NoError(t, err)
+		resp, err := s.handleReplayScenario(ctx, &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{
+				OrgID:                      1,
+				DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "testdata"},
+			},
+			Queries: []backend.DataQuery{{RefID: "A", JSON: raw, TimeRange: backend.TimeRange{From: from, To: to}}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	now := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("aligns the end of the recording with the end of the range", func(t *testing.T) {
+		dr := query(kinds.ReplayQuery{Name: "incident"}, now.Add(-time.Hour), now)
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		require.Equal(t, 4, dr.Frames[0].Rows())
+		require.Equal(t, now.Add(-3*time.Minute), dr.Frames[0].Fields[0].At(0))
+		require.Equal(t, now, dr.Frames[0].Fields[0].At(3))
+	})
+
+	t.Run("aligns the start of the recording and drops rows outside the range", func(t *testing.T) {
+		dr := query(kinds.ReplayQuery{Name: "incident", Align: kinds.ReplayAlignStart}, now, now.Add(90*time.Second))
+		require.NoError(t, dr.Error)
+		require.Equal(t, 2, dr.Frames[0].Rows())
+		require.Equal(t, now, dr.Frames[0].Fields[0].At(0))
+		require.Equal(t, 2.0, dr.Frames[0].Fields[1].At(1))
+	})
+
+	t.Run("sets the live channel when streaming", func(t *testing.T) {
+		dr := query(kinds.ReplayQuery{Name: "incident", Stream: true}, now.Add(-time.Hour), now)
+		require.NoError(t, dr.Error)
+		require.Equal(t, "ds/testdata/replay/incident/A/0", dr.Frames[0].Meta.Channel)
+
+		frame, timeIdx, err := s.replayStreamFrame(ctx, "replay/incident/A/0")
+		require.NoError(t, err)
+		require.Equal(t, 0, timeIdx)
+		require.Equal(t, 4, frame.Rows())
+	})
+
+	t.Run("replays recorded errors", func(t *testing.T) {
+		dr := query(kinds.ReplayQuery{Name: "incident", RefID: "B"}, now.Add(-time.Hour), now)
+		require.EqualError(t, dr.Error, "bad query")
+	})
+
+	t.Run("returns an error for unknown recordings", func(t *testing.T) {
+		dr := query(kinds.ReplayQuery{Name: "missing"}, now.Add(-time.Hour), now)
+		require.Error(t, dr.Error)
+		require.Equal(t, backend.StatusNotFound, dr.Status)
+
+		dr = query(kinds.ReplayQuery{Name: "incident", RefID: "C"}, now.Add(-time.Hour), now)
+		require.Error(t, dr.Error)
+		require.Equal(t, backend.StatusNotFound, dr.Status)
+	})
+
+	t.Run("does not replay recordings of data sources the user can't query", func(t *testing.T) {
+		dr := query(kinds.ReplayQuery{Name: "private"}, now.Add(-time.Hour), now)
+		require.Equal(t, backend.StatusNotFound, dr.Status)
+
+		_, _, err := s.replayStreamFrame(ctx, "replay/private/A/0")
+		require.ErrorIs(t, err, recordings.ErrNotFound)
+	})
+
+	t.Run("requires a user", func(t *testing.T) {
+		_, _, err := s.replayStreamFrame(context.Background(), "replay/incident/A/0")
+		require.Error(t, err)
+	})
+}
@@ -198,6 +198,14 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		Name: "Trace",
 	})
 
+	s.registerScenario(&Scenario{
+		ID:      kinds.TestDataQueryTypeReplay,
+		Name:    "Replay",
+		handler: s.handleReplayScenario,
+		Description: `Replay serves the frames of a recorded query response.
+Timestamps are shifted so that the end (or the start) of the recording lines up with the requested time range.`,
+	})
+
 	s.queryMux.HandleFunc("", s.handleFallbackScenario)
 }
 
@@ -22,6 +22,10 @@ func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStr
 		return s.sims.SubscribeStream(ctx, req)
 	}
 
+	if strings.HasPrefix(req.Path, replayStreamPathPrefix) {
+		return s.subscribeReplayStream(ctx, req)
+	}
+
 	initialData, err := backend.NewInitialFrame(s.frame, data.IncludeSchemaOnly)
 	if err != nil {
 		return nil, err
@@ -61,6 +65,10 @@ func (s *Service) RunStream(ctx context.Context, request *backend.RunStreamReque
 		return s.sims.RunStream(ctx, request, sender)
 	}
 
+	if strings.HasPrefix(request.Path, replayStreamPathPrefix) {
+		return s.runReplayStream(ctx, request, sender)
+	}
+
 	var conf testStreamConfig
 	switch {
 	case request.Path == "random-2s-stream":
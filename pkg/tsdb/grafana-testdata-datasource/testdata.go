@@ -12,6 +12,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/kinds"
 	"github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource/sims"
+	"github.com/grafana/grafana/pkg/tsdb/recordings"
 )
 
 // ensures that testdata implements all client functions
@@ -47,6 +48,13 @@ func ProvideService() *Service {
 	return s
 }
 
+// ProvideServiceWithRecordings returns a service that can replay recorded query responses.
+func ProvideServiceWithRecordings(store recordings.Getter) *Service {
+	s := ProvideService()
+	s.recordings = store
+	return s
+}
+
 var (
 	_ backend.QueryDataHandler      = (*Service)(nil)
 	_ backend.CallResourceHandler   = (*Service)(nil)
@@ -62,6 +70,7 @@ type Service struct {
 	queryMux        *datasource.QueryTypeMux
 	resourceHandler backend.CallResourceHandler
 	sims            *sims.SimulationEngine
+	recordings      recordings.Getter
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -0,0 +1,170 @@
+package grafanads
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type alertRuleStore interface {
+	ListAlertRules(ctx context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error)
+	ListAlertInstances(ctx context.Context, query *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error)
+}
+
+type alertRuleAccess interface {
+	HasAccessInFolder(ctx context.Context, user identity.Requester, rule ngmodels.Namespaced) (bool, error)
+}
+
+func (s *Service) doAlertInstancesQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &alertInstancesQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	if s.ruleStore == nil || s.ruleAccess == nil {
+		response.Error = fmt.Errorf("alert instances are not available")
+		return response
+	}
+	states := make(map[ngmodels.InstanceStateType]bool, len(q.States))
+	for _, state := range q.States {
+		if !ngmodels.InstanceStateType(state).IsValid() {
+			response.Error = fmt.Errorf("invalid alert instance state %q", state)
+			return response
+		}
+		states[ngmodels.InstanceStateType(state)] = true
+	}
+
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		response.Error = errNoRequester
+		return response
+	}
+
+	rulesQuery := &ngmodels.ListAlertRulesQuery{
+		OrgID:        req.PluginContext.OrgID,
+		DashboardUID: q.DashboardUID,
+		PanelID:      q.PanelID,
+	}
+	if q.RuleUID != "" {
+		rulesQuery.RuleUIDs = []string{q.RuleUID}
+	}
+	rules, err := s.readableAlertRules(ctx, requester, rulesQuery)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	rulesByUID := make(map[string]*ngmodels.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByUID[rule.UID] = rule
+	}
+
+	instances, err := s.ruleStore.ListAlertInstances(ctx, &ngmodels.ListAlertInstancesQuery{
+		RuleOrgID: req.PluginContext.OrgID,
+		RuleUID:   q.RuleUID,
+	})
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	type row struct {
+		rule     *ngmodels.AlertRule
+		instance *ngmodels.AlertInstance
+		labels   json.RawMessage
+	}
+	rows := make([]row, 0, len(instances))
+	for _, instance := range instances {
+		rule, ok := rulesByUID[instance.RuleUID]
+		if !ok {
+			continue
+		}
+		if len(states) > 0 && !states[instance.CurrentState] {
+			continue
+		}
+		labels := instance.Labels
+		if labels == nil {
+			labels = ngmodels.InstanceLabels{}
+		}
+		// keys of maps are sorted when marshalled, the labels are compared as JSON
+		labelsJSON, err := json.Marshal(labels)
+		if err != nil {
+			response.Error = err
+			return response
+		}
+		rows = append(rows, row{rule: rule, instance: instance, labels: labelsJSON})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].rule.Title != rows[j].rule.Title {
+			return rows[i].rule.Title < rows[j].rule.Title
+		}
+		if rows[i].rule.UID != rows[j].rule.UID {
+			return rows[i].rule.UID < rows[j].rule.UID
+		}
+		return string(rows[i].labels) < string(rows[j].labels)
+	})
+
+	frame := data.NewFrame("alertInstances",
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeJSON, 0),
+		data.NewFieldFromFieldType(data.FieldTypeTime, 0),
+		data.NewFieldFromFieldType(data.FieldTypeTime, 0),
+	)
+	frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTable})
+	for i, name := range []string{"ruleUID", "ruleTitle", "folderUID", "state", "reason", "labels", "activeSince", "lastEvaluation"} {
+		frame.Fields[i].Name = name
+	}
+	for _, r := range rows {
+		frame.AppendRow(
+			r.rule.UID,
+			r.rule.Title,
+			r.rule.NamespaceUID,
+			string(r.instance.CurrentState),
+			r.instance.CurrentReason,
+			r.labels,
+			r.instance.CurrentStateSince.UTC(),
+			r.instance.LastEvalTime.UTC(),
+		)
+	}
+	response.Frames = data.Frames{frame}
+	return response
+}
+
+// readableAlertRules returns the alert rules matching the query, in folders
+// where the user can read alert rules.
+func (s *Service) readableAlertRules(ctx context.Context, requester identity.Requester, query *ngmodels.ListAlertRulesQuery) ([]*ngmodels.AlertRule, error) {
+	rules, err := s.ruleStore.ListAlertRules(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	access := make(map[string]bool)
+	readable := make([]*ngmodels.AlertRule, 0, len(rules))
+	for _, rule := range rules {
+		ok, checked := access[rule.NamespaceUID]
+		if !checked {
+			ok, err = s.ruleAccess.HasAccessInFolder(ctx, requester, rule)
+			if err != nil {
+				return nil, err
+			}
+			access[rule.NamespaceUID] = ok
+		}
+		if ok {
+			readable = append(readable, rule)
+		}
+	}
+	return readable, nil
+}
@@ -0,0 +1,114 @@
+package grafanads
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+type fakeAlertRuleStore struct {
+	rules     ngmodels.RulesGroup
+	instances []*ngmodels.AlertInstance
+}
+
+func (s *fakeAlertRuleStore) ListAlertRules(_ context.Context, query *ngmodels.ListAlertRulesQuery) (ngmodels.RulesGroup, error) {
+	var rules ngmodels.RulesGroup
+	for _, rule := range s.rules {
+		if len(query.RuleUIDs) > 0 && !slices.Contains(query.RuleUIDs, rule.UID) {
+			continue
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (s *fakeAlertRuleStore) ListAlertInstances(_ context.Context, query *ngmodels.ListAlertInstancesQuery) ([]*ngmodels.AlertInstance, error) {
+	var instances []*ngmodels.AlertInstance
+	for _, instance := range s.instances {
+		if query.RuleUID != "" && instance.RuleUID != query.RuleUID {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// fakeRuleAccess grants access to the rules in the folders set to true.
+type fakeRuleAccess map[string]bool
+
+func (a fakeRuleAccess) HasAccessInFolder(_ context.Context, _ identity.Requester, rule ngmodels.Namespaced) (bool, error) {
+	return a[rule.GetNamespaceUID()], nil
+}
+
+func TestAlertInstancesQuery(t *testing.T) {
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	instance := func(ruleUID string, state ngmodels.InstanceStateType, labels ngmodels.InstanceLabels) *ngmodels.AlertInstance {
+		return &ngmodels.AlertInstance{
+			AlertInstanceKey:  ngmodels.AlertInstanceKey{RuleOrgID: 1, RuleUID: ruleUID},
+			Labels:            labels,
+			CurrentState:      state,
+			CurrentStateSince: since,
+			LastEvalTime:      since.Add(time.Minute),
+		}
+	}
+	s := newService(nil, nil, nil)
+	s.ruleStore = &fakeAlertRuleStore{
+		rules: ngmodels.RulesGroup{
+			{ID: 1, UID: "latency", Title: "High latency", NamespaceUID: "folder-a"},
+			{ID: 2, UID: "disk", Title: "Disk full", NamespaceUID: "folder-a"},
+			{ID: 3, UID: "hidden", Title: "Hidden", NamespaceUID: "folder-b"},
+		},
+		instances: []*ngmodels.AlertInstance{
+			instance("latency", ngmodels.InstanceStateFiring, ngmodels.InstanceLabels{"service": "b"}),
+			instance("latency", ngmodels.InstanceStateNormal, ngmodels.InstanceLabels{"service": "a"}),
+			instance("disk", ngmodels.InstanceStatePending, ngmodels.InstanceLabels{"host": "x"}),
+			instance("hidden", ngmodels.InstanceStateFiring, nil),
+		},
+	}
+	s.ruleAccess = fakeRuleAccess{"folder-a": true}
+
+	t.Run("instances of readable rules are returned", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAlertInstances, `{}`)
+		require.NoError(t, res.Error)
+
+		frame := res.Frames[0]
+		require.Equal(t, 3, frame.Rows())
+		rows := make([][]any, 0, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			rows = append(rows, []any{frame.Fields[0].At(i), frame.Fields[3].At(i), string(frame.Fields[5].At(i).(json.RawMessage))})
+		}
+		require.Equal(t, [][]any{
+			{"disk", "Pending", `{"host":"x"}`},
+			{"latency", "Normal", `{"service":"a"}`},
+			{"latency", "Alerting", `{"service":"b"}`},
+		}, rows)
+		require.Equal(t, since, frame.Fields[6].At(0))
+		require.Equal(t, since.Add(time.Minute), frame.Fields[7].At(0))
+	})
+
+	t.Run("instances are filtered by rule and state", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAlertInstances, `{"ruleUID": "latency", "states": ["Alerting"]}`)
+		require.NoError(t, res.Error)
+
+		frame := res.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.JSONEq(t, `{"service": "b"}`, string(frame.Fields[5].At(0).(json.RawMessage)))
+	})
+
+	t.Run("invalid states are rejected", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAlertInstances, `{"states": ["Firing"]}`)
+		require.EqualError(t, res.Error, `invalid alert instance state "Firing"`)
+	})
+
+	t.Run("instances are not available without the alerting store", func(t *testing.T) {
+		res := queryGrafanaDS(t, newService(nil, nil, nil), signedInContext(), queryTypeAlertInstances, `{}`)
+		require.EqualError(t, res.Error, "alert instances are not available")
+	})
+}
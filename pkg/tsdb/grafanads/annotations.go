@@ -0,0 +1,278 @@
+package grafanads
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+)
+
+// defaultAnnotationsLimit is the maximum number of annotations returned when
+// the query does not set a limit, same as the annotations API.
+const defaultAnnotationsLimit = 100
+
+var errNoRequester = errors.New("annotation and alerting queries require a signed in user")
+
+func (s *Service) doAnnotationsQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &annotationsQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	if s.annotations == nil {
+		response.Error = fmt.Errorf("annotations are not available")
+		return response
+	}
+	if q.AnnotationType != "" && q.AnnotationType != "annotation" && q.AnnotationType != "alert" {
+		response.Error = fmt.Errorf("invalid annotation type %q, expected annotation or alert", q.AnnotationType)
+		return response
+	}
+
+	itemQuery, err := s.annotationsItemQuery(ctx, req, query, q.DashboardUID, q.PanelID, q.Limit)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	itemQuery.Tags = q.Tags
+	itemQuery.MatchAny = q.MatchAny
+	itemQuery.Type = q.AnnotationType
+
+	items, err := s.annotations.Find(ctx, itemQuery)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	dashboardUIDs := s.dashboardUIDs(ctx, req.PluginContext.OrgID)
+	frame := data.NewFrame("annotations",
+		data.NewFieldFromFieldType(data.FieldTypeInt64, 0),
+		data.NewFieldFromFieldType(data.FieldTypeTime, 0),
+		data.NewFieldFromFieldType(data.FieldTypeTime, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeJSON, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeInt64, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+	)
+	frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTable})
+	for i, name := range []string{"id", "time", "timeEnd", "text", "tags", "dashboardUID", "panelId", "login"} {
+		frame.Fields[i].Name = name
+	}
+	for _, item := range items {
+		tags := item.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		tagsJSON, err := json.Marshal(tags)
+		if err != nil {
+			response.Error = err
+			return response
+		}
+		frame.AppendRow(
+			item.ID,
+			time.UnixMilli(item.Time),
+			time.UnixMilli(item.TimeEnd),
+			item.Text,
+			json.RawMessage(tagsJSON),
+			dashboardUIDs(item.DashboardID),
+			item.PanelID,
+			item.Login,
+		)
+	}
+	response.Frames = data.Frames{frame}
+	return response
+}
+
+func (s *Service) doAlertStateHistoryQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &alertStateHistoryQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	if s.annotations == nil || s.ruleStore == nil || s.ruleAccess == nil {
+		response.Error = fmt.Errorf("alert state history is not available")
+		return response
+	}
+
+	itemQuery, err := s.annotationsItemQuery(ctx, req, query, q.DashboardUID, q.PanelID, q.Limit)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	itemQuery.Type = "alert"
+
+	rulesQuery := &ngmodels.ListAlertRulesQuery{
+		OrgID:        req.PluginContext.OrgID,
+		DashboardUID: q.DashboardUID,
+		PanelID:      q.PanelID,
+	}
+	if q.RuleUID != "" {
+		rulesQuery.RuleUIDs = []string{q.RuleUID}
+	}
+	rules, err := s.readableAlertRules(ctx, itemQuery.SignedInUser, rulesQuery)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	if q.RuleUID != "" {
+		if len(rules) == 0 {
+			response.Error = fmt.Errorf("alert rule %q not found", q.RuleUID)
+			return response
+		}
+		itemQuery.AlertID = rules[0].ID
+	}
+	rulesByID := make(map[int64]*ngmodels.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByID[rule.ID] = rule
+	}
+
+	items, err := s.readableStateHistory(ctx, itemQuery, rulesByID)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	frame := data.NewFrame("stateHistory",
+		data.NewFieldFromFieldType(data.FieldTypeTime, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeString, 0),
+		data.NewFieldFromFieldType(data.FieldTypeJSON, 0),
+	)
+	frame.SetMeta(&data.FrameMeta{Type: data.FrameTypeTable})
+	for i, name := range []string{"time", "ruleUID", "ruleTitle", "prevState", "newState", "text", "data"} {
+		frame.Fields[i].Name = name
+	}
+	for _, item := range items {
+		rule := rulesByID[item.AlertID]
+		itemData := json.RawMessage("{}")
+		if item.Data != nil {
+			itemData, err = item.Data.MarshalJSON()
+			if err != nil {
+				response.Error = err
+				return response
+			}
+		}
+		frame.AppendRow(
+			time.UnixMilli(item.Time),
+			rule.UID,
+			rule.Title,
+			item.PrevState,
+			item.NewState,
+			item.Text,
+			itemData,
+		)
+	}
+	response.Frames = data.Frames{frame}
+	return response
+}
+
+// readableStateHistory returns the state changes of the rules, up to the limit of the query. State
+// changes of rules that were deleted, or that the user can not read, are dropped before applying
+// the limit, more state changes are fetched until the limit is reached or all were fetched.
+func (s *Service) readableStateHistory(ctx context.Context, query *annotations.ItemQuery, rulesByID map[int64]*ngmodels.AlertRule) ([]*annotations.ItemDTO, error) {
+	limit := query.Limit
+	for {
+		items, err := s.annotations.Find(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		readable := make([]*annotations.ItemDTO, 0, len(items))
+		for _, item := range items {
+			if _, ok := rulesByID[item.AlertID]; ok {
+				readable = append(readable, item)
+			}
+		}
+		if int64(len(readable)) >= limit {
+			return readable[:limit], nil
+		}
+		if int64(len(items)) < query.Limit {
+			return readable, nil
+		}
+		query.Limit *= 2
+	}
+}
+
+// annotationsItemQuery returns the annotations query of the user in the time range of the query.
+func (s *Service) annotationsItemQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery, dashboardUID string, panelID int64, limit int64) (*annotations.ItemQuery, error) {
+	requester, err := identity.GetRequester(ctx)
+	if err != nil {
+		return nil, errNoRequester
+	}
+
+	if limit <= 0 {
+		limit = defaultAnnotationsLimit
+	}
+	itemQuery := &annotations.ItemQuery{
+		OrgID:        req.PluginContext.OrgID,
+		From:         query.TimeRange.From.UnixMilli(),
+		To:           query.TimeRange.To.UnixMilli(),
+		PanelID:      panelID,
+		Limit:        limit,
+		SignedInUser: requester,
+	}
+
+	if dashboardUID != "" {
+		if s.dashboards == nil || s.ac == nil {
+			return nil, fmt.Errorf("filtering by dashboard is not available")
+		}
+		// dashboards the user can't read are not found, which doesn't tell whether they exist
+		notFound := fmt.Errorf("dashboard %q not found", dashboardUID)
+		canRead, err := s.ac.Evaluate(ctx, requester, accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, dashboards.ScopeDashboardsProvider.GetResourceScopeUID(dashboardUID)))
+		if err != nil {
+			return nil, err
+		}
+		if !canRead {
+			return nil, notFound
+		}
+		dash, err := s.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{UID: dashboardUID, OrgID: req.PluginContext.OrgID})
+		if errors.Is(err, dashboards.ErrDashboardNotFound) {
+			return nil, notFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		itemQuery.DashboardID = dash.ID
+		itemQuery.DashboardUID = dashboardUID
+	}
+	return itemQuery, nil
+}
+
+// dashboardUIDs returns a function that looks up the UID of dashboards by ID,
+// since there are several annotations per dashboard the UIDs are cached.
+func (s *Service) dashboardUIDs(ctx context.Context, orgID int64) func(id int64) string {
+	cache := make(map[int64]string)
+	return func(id int64) string {
+		if id == 0 || s.dashboards == nil {
+			return ""
+		}
+		if uid, ok := cache[id]; ok {
+			return uid
+		}
+		dash, err := s.dashboards.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: id, OrgID: orgID})
+		if err == nil && dash != nil {
+			cache[id] = dash.UID
+		} else {
+			cache[id] = ""
+		}
+		return cache[id]
+	}
+}
@@ -0,0 +1,191 @@
+package grafanads
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/actest"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	ngmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/services/user"
+)
+
+type fakeAnnotationsRepo struct {
+	annotations.Repository
+	items   []*annotations.ItemDTO
+	queries []*annotations.ItemQuery
+}
+
+func (r *fakeAnnotationsRepo) Find(_ context.Context, query *annotations.ItemQuery) ([]*annotations.ItemDTO, error) {
+	q := *query
+	r.queries = append(r.queries, &q)
+	if query.Limit > 0 && int64(len(r.items)) > query.Limit {
+		return r.items[:query.Limit], nil
+	}
+	return r.items, nil
+}
+
+type fakeDashboardService struct {
+	dashboards.DashboardService
+	dashboards []*dashboards.Dashboard
+}
+
+func (s *fakeDashboardService) GetDashboard(_ context.Context, query *dashboards.GetDashboardQuery) (*dashboards.Dashboard, error) {
+	for _, dash := range s.dashboards {
+		if (query.UID != "" && dash.UID == query.UID) || (query.ID != 0 && dash.ID == query.ID) {
+			return dash, nil
+		}
+	}
+	return nil, dashboards.ErrDashboardNotFound
+}
+
+var testTimeRange = backend.TimeRange{
+	From: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+	To:   time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC),
+}
+
+func queryGrafanaDS(t *testing.T, s *Service, ctx context.Context, queryType string, model string) backend.DataResponse {
+	t.Helper()
+	res, err := s.QueryData(ctx, &backend.QueryDataRequest{
+		PluginContext: backend.PluginContext{OrgID: 1},
+		Queries: []backend.DataQuery{{
+			RefID:     "A",
+			QueryType: queryType,
+			JSON:      json.RawMessage(model),
+			TimeRange: testTimeRange,
+		}},
+	})
+	require.NoError(t, err)
+	return res.Responses["A"]
+}
+
+func signedInContext() context.Context {
+	return identity.WithRequester(context.Background(), &user.SignedInUser{UserID: 1, OrgID: 1})
+}
+
+func TestAnnotationsQuery(t *testing.T) {
+	repo := &fakeAnnotationsRepo{items: []*annotations.ItemDTO{{
+		ID:          3,
+		DashboardID: 7,
+		PanelID:     2,
+		Time:        testTimeRange.From.Add(time.Hour).UnixMilli(),
+		TimeEnd:     testTimeRange.From.Add(2 * time.Hour).UnixMilli(),
+		Text:        "deploy",
+		Tags:        []string{"release"},
+		Login:       "admin",
+	}}}
+	s := newService(nil, nil, nil)
+	s.annotations = repo
+	s.dashboards = &fakeDashboardService{dashboards: []*dashboards.Dashboard{{ID: 7, UID: "dash"}}}
+	s.ac = actest.FakeAccessControl{ExpectedEvaluate: true}
+
+	t.Run("annotations are filtered and returned as a frame", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAnnotations, `{"dashboardUID": "dash", "tags": ["release"], "annotationType": "annotation"}`)
+		require.NoError(t, res.Error)
+
+		query := repo.queries[len(repo.queries)-1]
+		require.Equal(t, int64(1), query.OrgID)
+		require.Equal(t, int64(7), query.DashboardID)
+		require.Equal(t, testTimeRange.From.UnixMilli(), query.From)
+		require.Equal(t, testTimeRange.To.UnixMilli(), query.To)
+		require.Equal(t, []string{"release"}, query.Tags)
+		require.Equal(t, "annotation", query.Type)
+		require.Equal(t, int64(defaultAnnotationsLimit), query.Limit)
+		require.NotNil(t, query.SignedInUser)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, testTimeRange.From.Add(time.Hour), frame.Fields[1].At(0).(time.Time).UTC())
+		require.Equal(t, "deploy", frame.Fields[3].At(0))
+		require.JSONEq(t, `["release"]`, string(frame.Fields[4].At(0).(json.RawMessage)))
+		require.Equal(t, "dash", frame.Fields[5].At(0))
+	})
+
+	t.Run("dashboards the user can not read are not found", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAnnotations, `{"dashboardUID": "missing"}`)
+		require.EqualError(t, res.Error, `dashboard "missing" not found`)
+
+		denied := newService(nil, nil, nil)
+		denied.annotations = repo
+		denied.dashboards = s.dashboards
+		denied.ac = actest.FakeAccessControl{ExpectedEvaluate: false}
+		res = queryGrafanaDS(t, denied, signedInContext(), queryTypeAnnotations, `{"dashboardUID": "dash"}`)
+		require.EqualError(t, res.Error, `dashboard "dash" not found`)
+	})
+
+	t.Run("annotations require a signed in user", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, context.Background(), queryTypeAnnotations, `{}`)
+		require.ErrorIs(t, res.Error, errNoRequester)
+	})
+
+	t.Run("invalid annotation types are rejected", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAnnotations, `{"annotationType": "other"}`)
+		require.Error(t, res.Error)
+	})
+}
+
+func TestAlertStateHistoryQuery(t *testing.T) {
+	rules := &fakeAlertRuleStore{rules: ngmodels.RulesGroup{
+		{ID: 1, UID: "readable", Title: "High latency", NamespaceUID: "folder-a"},
+		{ID: 2, UID: "hidden", Title: "Disk full", NamespaceUID: "folder-b"},
+	}}
+	repo := &fakeAnnotationsRepo{items: []*annotations.ItemDTO{
+		{AlertID: 1, Time: testTimeRange.From.UnixMilli(), PrevState: "Normal", NewState: "Alerting", Data: simplejson.NewFromAny(map[string]any{"values": map[string]any{"B": 1}})},
+		{AlertID: 2, Time: testTimeRange.From.UnixMilli(), PrevState: "Normal", NewState: "Alerting"},
+	}}
+	s := newService(nil, nil, nil)
+	s.annotations = repo
+	s.ruleStore = rules
+	s.ruleAccess = fakeRuleAccess{"folder-a": true}
+
+	t.Run("state changes of readable rules are returned", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAlertStateHistory, `{}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, "alert", repo.queries[len(repo.queries)-1].Type)
+
+		frame := res.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "readable", frame.Fields[1].At(0))
+		require.Equal(t, "High latency", frame.Fields[2].At(0))
+		require.Equal(t, "Alerting", frame.Fields[4].At(0))
+		require.JSONEq(t, `{"values": {"B": 1}}`, string(frame.Fields[6].At(0).(json.RawMessage)))
+	})
+
+	t.Run("state changes are filtered by rule", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAlertStateHistory, `{"ruleUID": "readable"}`)
+		require.NoError(t, res.Error)
+		require.Equal(t, int64(1), repo.queries[len(repo.queries)-1].AlertID)
+	})
+
+	t.Run("rules the user can not read are not found", func(t *testing.T) {
+		res := queryGrafanaDS(t, s, signedInContext(), queryTypeAlertStateHistory, `{"ruleUID": "hidden"}`)
+		require.EqualError(t, res.Error, `alert rule "hidden" not found`)
+	})
+
+	t.Run("the limit applies to the state changes of readable rules", func(t *testing.T) {
+		limited := newService(nil, nil, nil)
+		limited.annotations = &fakeAnnotationsRepo{items: []*annotations.ItemDTO{
+			{AlertID: 2, Time: testTimeRange.From.UnixMilli(), NewState: "Alerting"},
+			{AlertID: 1, Time: testTimeRange.From.UnixMilli(), NewState: "Normal"},
+			{AlertID: 1, Time: testTimeRange.From.UnixMilli(), NewState: "Alerting"},
+		}}
+		limited.ruleStore = rules
+		limited.ruleAccess = s.ruleAccess
+
+		res := queryGrafanaDS(t, limited, signedInContext(), queryTypeAlertStateHistory, `{"limit": 1}`)
+		require.NoError(t, res.Error)
+		frame := res.Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "readable", frame.Fields[1].At(0))
+		require.Equal(t, "Normal", frame.Fields[4].At(0))
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
@@ -14,7 +15,13 @@ import (
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/services/annotations"
+	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	ngac "github.com/grafana/grafana/pkg/services/ngalert/accesscontrol"
+	ngstore "github.com/grafana/grafana/pkg/services/ngalert/store"
 	"github.com/grafana/grafana/pkg/services/searchV2"
 	"github.com/grafana/grafana/pkg/services/store"
 	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
@@ -51,15 +58,28 @@ var (
 	)
 )
 
-func ProvideService(search searchV2.SearchService, store store.StorageService) *Service {
-	return newService(search, store)
+func ProvideService(search searchV2.SearchService, store store.StorageService, frameCache managedstream.FrameCache,
+	annotationsRepo annotations.Repository, dashboardService dashboards.DashboardService, ruleStore *ngstore.DBstore,
+	ac accesscontrol.AccessControl) *Service {
+	s := newService(search, store, frameCache)
+	s.annotations = annotationsRepo
+	s.dashboards = dashboardService
+	if ruleStore != nil {
+		s.ruleStore = ruleStore
+	}
+	if ac != nil {
+		s.ac = ac
+		s.ruleAccess = ngac.NewRuleService(ac)
+	}
+	return s
 }
 
-func newService(search searchV2.SearchService, store store.StorageService) *Service {
+func newService(search searchV2.SearchService, store store.StorageService, frameCache managedstream.FrameCache) *Service {
 	s := &Service{
-		search: search,
-		store:  store,
-		log:    log.New("grafanads"),
+		search:     search,
+		store:      store,
+		frameCache: frameCache,
+		log:        log.New("grafanads"),
 	}
 
 	return s
@@ -67,9 +87,15 @@ func newService(search searchV2.SearchService, store store.StorageService) *Serv
 
 // Service exists regardless of user settings
 type Service struct {
-	search searchV2.SearchService
-	store  store.StorageService
-	log    log.Logger
+	search      searchV2.SearchService
+	store       store.StorageService
+	frameCache  managedstream.FrameCache
+	annotations annotations.Repository
+	dashboards  dashboards.DashboardService
+	ac          accesscontrol.AccessControl
+	ruleStore   alertRuleStore
+	ruleAccess  alertRuleAccess
+	log         log.Logger
 }
 
 func DataSourceModel(orgId int64) *datasources.DataSource {
@@ -97,6 +123,14 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 			response.Responses[q.RefID] = s.doReadQuery(ctx, q)
 		case queryTypeSearch:
 			response.Responses[q.RefID] = s.doSearchQuery(ctx, req, q)
+		case queryTypeLiveHistory:
+			response.Responses[q.RefID] = s.doLiveHistoryQuery(ctx, req, q)
+		case queryTypeAnnotations:
+			response.Responses[q.RefID] = s.doAnnotationsQuery(ctx, req, q)
+		case queryTypeAlertInstances:
+			response.Responses[q.RefID] = s.doAlertInstancesQuery(ctx, req, q)
+		case queryTypeAlertStateHistory:
+			response.Responses[q.RefID] = s.doAlertStateHistoryQuery(ctx, req, q)
 		default:
 			response.Responses[q.RefID] = backend.DataResponse{
 				Error: fmt.Errorf("unknown query type"),
@@ -163,6 +197,60 @@ func (s *Service) doReadQuery(ctx context.Context, query backend.DataQuery) back
 	return response
 }
 
+func (s *Service) doLiveHistoryQuery(ctx context.Context, req *backend.QueryDataRequest, query backend.DataQuery) backend.DataResponse {
+	q := &liveHistoryQueryModel{}
+	response := backend.DataResponse{}
+	err := json.Unmarshal(query.JSON, &q)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	if s.frameCache == nil {
+		response.Error = fmt.Errorf("live history is not available")
+		return response
+	}
+
+	frameJSON, ok, err := s.frameCache.GetHistory(ctx, req.PluginContext.OrgID, q.Channel)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+	if !ok {
+		return response
+	}
+
+	frame := &data.Frame{}
+	if err := json.Unmarshal(frameJSON, frame); err != nil {
+		response.Error = err
+		return response
+	}
+	response.Frames = data.Frames{filterTimeRange(frame, query.TimeRange)}
+	return response
+}
+
+// filterTimeRange returns the rows of which the first time field is within the time range.
+func filterTimeRange(frame *data.Frame, timeRange backend.TimeRange) *data.Frame {
+	timeIndices := frame.TypeIndices(data.FieldTypeTime, data.FieldTypeNullableTime)
+	if len(timeIndices) == 0 || timeRange.From.IsZero() || timeRange.To.IsZero() {
+		return frame
+	}
+
+	timeField := frame.Fields[timeIndices[0]]
+	filtered := frame.EmptyCopy()
+	for i := 0; i < frame.Rows(); i++ {
+		t, ok := timeField.ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		if ts := t.(time.Time); ts.Before(timeRange.From) || ts.After(timeRange.To) {
+			continue
+		}
+		filtered.AppendRow(frame.RowCopy(i)...)
+	}
+	return filtered
+}
+
 func (s *Service) doRandomWalk(query backend.DataQuery) backend.DataResponse {
 	response := backend.DataResponse{}
 
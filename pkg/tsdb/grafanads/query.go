@@ -14,6 +14,22 @@ const (
 	// currently only .csv files are supported,
 	// other file types will eventually be supported (parquet, etc)
 	queryTypeRead = "read"
+
+	// queryTypeLiveHistory returns the frames kept in history for a
+	// Grafana Live managed stream channel
+	queryTypeLiveHistory = "liveHistory"
+
+	// QueryTypeAnnotations returns the annotations in the time range,
+	// optionally filtered by dashboard, panel and tags
+	queryTypeAnnotations = "annotationList"
+
+	// QueryTypeAlertInstances returns the current state of the alert
+	// instances of the alert rules the user can read
+	queryTypeAlertInstances = "alertInstances"
+
+	// QueryTypeAlertStateHistory returns the state changes of alert
+	// instances in the time range
+	queryTypeAlertStateHistory = "alertStateHistory"
 )
 
 type listQueryModel struct {
@@ -22,3 +38,27 @@ type listQueryModel struct {
 type readQueryModel struct {
 	Path string `json:"path"`
 }
+type liveHistoryQueryModel struct {
+	Channel string `json:"channel"`
+}
+type annotationsQueryModel struct {
+	DashboardUID string   `json:"dashboardUID"`
+	PanelID      int64    `json:"panelId"`
+	Tags         []string `json:"tags"`
+	MatchAny     bool     `json:"matchAny"`
+	// AnnotationType is either "annotation" or "alert", all annotations are returned when empty
+	AnnotationType string `json:"annotationType"`
+	Limit          int64  `json:"limit"`
+}
+type alertInstancesQueryModel struct {
+	RuleUID      string   `json:"ruleUID"`
+	DashboardUID string   `json:"dashboardUID"`
+	PanelID      int64    `json:"panelId"`
+	States       []string `json:"states"`
+}
+type alertStateHistoryQueryModel struct {
+	RuleUID      string `json:"ruleUID"`
+	DashboardUID string `json:"dashboardUID"`
+	PanelID      int64  `json:"panelId"`
+	Limit        int64  `json:"limit"`
+}
@@ -1,6 +1,7 @@
 package graphite
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -10,6 +11,7 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -26,9 +28,13 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/instrumentation"
 )
 
-var logger = log.New("tsdb.graphite")
+var (
+	logger         = log.New("tsdb.graphite")
+	requestMetrics = instrumentation.NewRequestMetrics("graphite", "Graphite")
+)
 
 type Service struct {
 	im     instancemgmt.InstanceManager
@@ -38,6 +44,8 @@ type Service struct {
 const (
 	TargetFullModelField = "targetFull"
 	TargetModelField     = "target"
+
+	graphiteTypeMetrictank = "metrictank"
 )
 
 func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
@@ -51,6 +59,14 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 	Id         int64
+
+	GraphiteType           string
+	RollupIndicatorEnabled bool
+}
+
+type jsonData struct {
+	GraphiteType           string `json:"graphiteType"`
+	RollupIndicatorEnabled bool   `json:"rollupIndicatorEnabled"`
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -65,10 +81,19 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jsonData := jsonData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jsonData); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:             client,
+			URL:                    settings.URL,
+			Id:                     settings.ID,
+			GraphiteType:           jsonData.GraphiteType,
+			RollupIndicatorEnabled: jsonData.RollupIndicatorEnabled,
 		}
 
 		return model, nil
@@ -85,6 +110,12 @@ func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp, err := s.queryData(ctx, req)
+	requestMetrics.UpdateQueryDataMetrics(err, resp)
+	return resp, err
+}
+
+func (s *Service) queryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	if len(req.Queries) == 0 {
 		return nil, fmt.Errorf("query contains no queries")
 	}
@@ -112,6 +143,10 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		"maxDataPoints": []string{"500"},
 		"target":        []string{},
 	}
+	if dsInfo.GraphiteType == graphiteTypeMetrictank {
+		// Metrictank returns which archive it read from and how the series were consolidated
+		formData["meta"] = []string{"true"}
+	}
 
 	// Convert datasource query to graphite target request
 	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, req.Queries)
@@ -168,7 +203,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	frames, err := s.toDataFrames(logger, res, origRefIds)
+	frames, err := s.toDataFrames(logger, res, origRefIds, dsInfo.RollupIndicatorEnabled)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -235,10 +270,12 @@ func (s *Service) processQueries(logger log.Logger, queries []backend.DataQuery)
 	return targets, emptyQueries, origRefIds, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]TargetResponseDTO, error) {
+// parseResponse returns the series of a render response, and the request metadata
+// if Metrictank was asked to include it.
+func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]TargetResponseDTO, map[string]any, error) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer func() {
 		if err := res.Body.Close(); err != nil {
@@ -248,27 +285,37 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]Target
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("request failed, status: %s", res.Status)
+		return nil, nil, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	// with meta=true the series are wrapped in an object next to the request metadata
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '{' {
+		var data MetaResponseDTO
+		if err := json.Unmarshal(body, &data); err != nil {
+			logger.Info("Failed to unmarshal graphite response", "error", err, "status", res.Status, "body", string(body))
+			return nil, nil, err
+		}
+		return data.Series, data.Meta, nil
 	}
 
 	var data []TargetResponseDTO
 	err = json.Unmarshal(body, &data)
 	if err != nil {
 		logger.Info("Failed to unmarshal graphite response", "error", err, "status", res.Status, "body", string(body))
-		return nil, err
+		return nil, nil, err
 	}
 
-	return data, nil
+	return data, nil, nil
 }
 
-func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origRefIds map[string]string) (frames data.Frames, error error) {
-	responseData, err := s.parseResponse(logger, response)
+func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origRefIds map[string]string, rollupIndicatorEnabled bool) (frames data.Frames, error error) {
+	responseData, requestMeta, err := s.parseResponse(logger, response)
 	if err != nil {
 		return nil, err
 	}
 
 	frames = data.Frames{}
-	for _, series := range responseData {
+	for i, series := range responseData {
 		timeVector := make([]time.Time, 0, len(series.DataPoints))
 		values := make([]*float64, 0, len(series.DataPoints))
 		// series.Target will be in the format <resolvedSeriesName> <formattedRefId>
@@ -300,12 +347,23 @@ func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origR
 				tags[name] = value
 			case float64:
 				tags[name] = strconv.FormatFloat(value, 'f', -1, 64)
+			case bool:
+				tags[name] = strconv.FormatBool(value)
 			}
 		}
 
-		frames = append(frames, data.NewFrame(refId,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: target})))
+		timeField := data.NewField("time", nil, timeVector)
+		frame := data.NewFrame(refId,
+			timeField,
+			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: seriesDisplayName(target, tags)}))
+
+		if len(series.Meta) > 0 {
+			frame.Meta = seriesFrameMeta(series.Meta, requestMeta, i == 0, rollupIndicatorEnabled)
+			if step := seriesStep(series.Meta); step > 0 {
+				timeField.SetConfig(&data.FieldConfig{Interval: float64(step.Milliseconds())})
+			}
+		}
+		frames = append(frames, frame)
 
 		if setting.Env == setting.Dev {
 			logger.Debug("Graphite response", "target", series.Target, "datapoints", len(series.DataPoints))
@@ -314,6 +372,29 @@ func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origR
 	return frames, nil
 }
 
+// seriesDisplayName returns the name to show for a series. Tagged series which were not
+// renamed with an alias function (e.g. aliasByTags) are returned by Graphite as
+// name;tag1=value1;tag2=value2, those are shown as name{tag1="value1", tag2="value2"}
+// the same way as the labels of other time series data sources.
+func seriesDisplayName(target string, tags map[string]string) string {
+	parts := strings.Split(target, ";")
+	if len(parts) < 2 || parts[0] != tags["name"] || len(parts) != len(tags) {
+		return target
+	}
+
+	pairs := make([]string, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		key, value, ok := strings.Cut(part, "=")
+		if !ok || key == "name" || tags[key] != value {
+			return target
+		}
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, value))
+	}
+	sort.Strings(pairs)
+
+	return parts[0] + "{" + strings.Join(pairs, ", ") + "}"
+}
+
 func (s *Service) createRequest(ctx context.Context, l log.Logger, dsInfo *datasourceInfo, data url.Values) (*http.Request, error) {
 	u, err := url.Parse(dsInfo.URL)
 	if err != nil {
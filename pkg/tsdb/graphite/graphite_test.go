@@ -170,7 +170,7 @@ func TestConvertResponses(t *testing.T) {
 		expectedFrames := data.Frames{expectedFrame}
 
 		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
-		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{})
+		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{}, false)
 
 		require.NoError(t, err)
 		if !reflect.DeepEqual(expectedFrames, dataFrames) {
@@ -203,7 +203,7 @@ func TestConvertResponses(t *testing.T) {
 		expectedFrames := data.Frames{expectedFrame}
 
 		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
-		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{})
+		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{}, false)
 
 		require.NoError(t, err)
 		if !reflect.DeepEqual(expectedFrames, dataFrames) {
@@ -238,7 +238,7 @@ func TestConvertResponses(t *testing.T) {
 		expectedFrames := data.Frames{expectedFrameA, expectedFrameB}
 
 		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
-		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{})
+		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{}, false)
 
 		require.NoError(t, err)
 		if !reflect.DeepEqual(expectedFrames, dataFrames) {
@@ -265,7 +265,7 @@ func TestConvertResponses(t *testing.T) {
 		expectedFrames := data.Frames{expectedFrame}
 
 		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
-		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{"A_A": "A A"})
+		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{"A_A": "A A"}, false)
 
 		require.NoError(t, err)
 		if !reflect.DeepEqual(expectedFrames, dataFrames) {
@@ -275,6 +275,85 @@ func TestConvertResponses(t *testing.T) {
 		}
 	})
 
+	t.Run("Converts tagged series to labels and names them after their tags", func(t *testing.T) {
+		body := `
+		[
+			{
+				"target": "cpu.usage;dc=eu;host=a A",
+				"tags": { "name": "cpu.usage", "host": "a", "dc": "eu" },
+				"datapoints": [[50, 1]]
+			},
+			{
+				"target": "a B",
+				"tags": { "name": "cpu.usage", "host": "a", "dc": "eu", "virtual": true },
+				"datapoints": [[50, 1]]
+			}
+		]`
+		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
+		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{}, false)
+		require.NoError(t, err)
+		require.Len(t, dataFrames, 2)
+
+		require.Equal(t, data.Labels{"name": "cpu.usage", "host": "a", "dc": "eu"}, dataFrames[0].Fields[1].Labels)
+		require.Equal(t, `cpu.usage{dc="eu", host="a"}`, dataFrames[0].Fields[1].Config.DisplayNameFromDS)
+
+		// aliased series, e.g. with aliasByTags, keep the name returned by Graphite
+		require.Equal(t, "true", dataFrames[1].Fields[1].Labels["virtual"])
+		require.Equal(t, "a", dataFrames[1].Fields[1].Config.DisplayNameFromDS)
+		require.Nil(t, dataFrames[1].Meta)
+	})
+
+	t.Run("Converts Metrictank response with metadata", func(t *testing.T) {
+		body := `
+		{
+			"meta": { "stats": { "executeplan.get-targets.ms": 12, "executeplan.cache-hit.count": 3 } },
+			"series": [
+				{
+					"target": "target A",
+					"datapoints": [[50, 60], [100, 120]],
+					"meta": [{
+						"schema-name": "default",
+						"schema-retentions": "10s:1d,1min:30d",
+						"archive-read": 1,
+						"archive-interval": 60,
+						"aggnum-norm": 1,
+						"consolidator-normfetch": "AverageConsolidator",
+						"aggnum-rc": 0,
+						"consolidator-rc": "NoneConsolidator",
+						"count": 1
+					}]
+				},
+				{
+					"target": "target B",
+					"datapoints": [[50, 120]],
+					"meta": [{ "archive-interval": 10, "aggnum-norm": 2, "aggnum-rc": 3, "consolidator-rc": "MaximumConsolidator" }]
+				}
+			]
+		}`
+		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
+		dataFrames, err := service.toDataFrames(logger, httpResponse, map[string]string{}, true)
+		require.NoError(t, err)
+		require.Len(t, dataFrames, 2)
+
+		meta := dataFrames[0].Meta
+		require.NotNil(t, meta)
+		require.Len(t, meta.Custom.(map[string]any)["seriesMetaList"], 1)
+		require.Equal(t, []data.Notice{{
+			Severity: data.NoticeSeverityInfo,
+			Text:     "Data is rolled up, aggregated over 1min using Average function",
+			Inspect:  data.InspectTypeMeta,
+		}}, meta.Notices)
+		require.Len(t, meta.Stats, 2)
+		require.Equal(t, "executeplan.cache-hit.count", meta.Stats[0].DisplayName)
+		require.Equal(t, "ms", meta.Stats[1].Unit)
+		require.Equal(t, 60000.0, dataFrames[0].Fields[0].Config.Interval)
+
+		meta = dataFrames[1].Meta
+		require.Empty(t, meta.Stats)
+		require.Equal(t, "Data is runtime consolidated, 3 datapoints combined using Maximum function", meta.Notices[0].Text)
+		require.Equal(t, 60000.0, dataFrames[1].Fields[0].Config.Interval)
+	})
+
 	t.Run("Chokes on response with invalid target name", func(*testing.T) {
 		body := `
 		[
@@ -284,7 +363,7 @@ func TestConvertResponses(t *testing.T) {
 			}
 		]`
 		httpResponse := &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader(body))}
-		_, err := service.toDataFrames(logger, httpResponse, map[string]string{})
+		_, err := service.toDataFrames(logger, httpResponse, map[string]string{}, false)
 		require.Error(t, err)
 	})
 }
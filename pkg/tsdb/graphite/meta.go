@@ -0,0 +1,109 @@
+package graphite
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// seriesFrameMeta exposes the Metrictank metadata of a series the same way as the
+// frontend does, so it can be found in the query inspector. The request stats are
+// only added to the first frame.
+func seriesFrameMeta(seriesMeta []SeriesMetaDTO, requestMeta map[string]any, first bool, rollupIndicatorEnabled bool) *data.FrameMeta {
+	meta := &data.FrameMeta{
+		Custom: map[string]any{
+			"requestMetaList": requestMeta,
+			"seriesMetaList":  seriesMeta,
+		},
+	}
+
+	if rollupIndicatorEnabled {
+		if notice := rollupNotice(seriesMeta); notice != nil {
+			meta.Notices = []data.Notice{*notice}
+		} else if notice := runtimeConsolidationNotice(seriesMeta); notice != nil {
+			meta.Notices = []data.Notice{*notice}
+		}
+	}
+
+	if first {
+		meta.Stats = requestStats(requestMeta)
+	}
+	return meta
+}
+
+// seriesStep returns the interval between the points of a series after Metrictank
+// read it from an archive and consolidated it.
+func seriesStep(seriesMeta []SeriesMetaDTO) time.Duration {
+	var step int64
+	for _, m := range seriesMeta {
+		s := m.ArchiveInterval * max(m.AggNumNorm, 1) * max(m.AggNumRC, 1)
+		step = max(step, s)
+	}
+	return time.Duration(step) * time.Second
+}
+
+func rollupNotice(seriesMeta []SeriesMetaDTO) *data.Notice {
+	for _, m := range seriesMeta {
+		if m.ArchiveRead <= 0 {
+			continue
+		}
+
+		interval := ""
+		if retentions := strings.Split(m.SchemaRetentions, ","); m.ArchiveRead < len(retentions) {
+			interval, _, _ = strings.Cut(retentions[m.ArchiveRead], ":")
+		}
+		return &data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("Data is rolled up, aggregated over %s using %s function", interval, strings.TrimSuffix(m.ConsolidatorNormFetch, "Consolidator")),
+			Inspect:  data.InspectTypeMeta,
+		}
+	}
+	return nil
+}
+
+func runtimeConsolidationNotice(seriesMeta []SeriesMetaDTO) *data.Notice {
+	for _, m := range seriesMeta {
+		if m.AggNumRC <= 0 {
+			continue
+		}
+		return &data.Notice{
+			Severity: data.NoticeSeverityInfo,
+			Text:     fmt.Sprintf("Data is runtime consolidated, %d datapoints combined using %s function", m.AggNumRC, strings.TrimSuffix(m.ConsolidatorRC, "Consolidator")),
+			Inspect:  data.InspectTypeMeta,
+		}
+	}
+	return nil
+}
+
+func requestStats(requestMeta map[string]any) []data.QueryStat {
+	stats, ok := requestMeta["stats"].(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(stats))
+	for key := range stats {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]data.QueryStat, 0, len(keys))
+	for _, key := range keys {
+		value, ok := stats[key].(float64)
+		if !ok {
+			continue
+		}
+		stat := data.QueryStat{
+			FieldConfig: data.FieldConfig{DisplayName: key},
+			Value:       value,
+		}
+		if strings.HasSuffix(key, ".ms") {
+			stat.Unit = "ms"
+		}
+		result = append(result, stat)
+	}
+	return result
+}
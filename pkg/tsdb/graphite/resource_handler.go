@@ -0,0 +1,202 @@
+package graphite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/grafana/grafana/pkg/tsdb/instrumentation"
+)
+
+var (
+	_ backend.CallResourceHandler = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+)
+
+// resourcePaths are the read-only Graphite API endpoints which can be called
+// through the data source with the methods they accept, used for metric and
+// tag autocompletion. The tag lookups accept POST for long expressions.
+var resourcePaths = map[string][]string{
+	"metrics/find":             {http.MethodGet},
+	"metrics/expand":           {http.MethodGet},
+	"tags":                     {http.MethodGet},
+	"tags/findSeries":          {http.MethodGet, http.MethodPost},
+	"tags/autoComplete/tags":   {http.MethodGet, http.MethodPost},
+	"tags/autoComplete/values": {http.MethodGet, http.MethodPost},
+	"functions":                {http.MethodGet},
+	"version":                  {http.MethodGet},
+}
+
+// tagWritePaths are the endpoints of the tags API which write to the tag
+// database. They look like tags/<tag>, so they are rejected by name.
+var tagWritePaths = map[string]struct{}{
+	"tags/tagseries":      {},
+	"tags/tagmultiseries": {},
+	"tags/delseries":      {},
+	"tags/delmultiseries": {},
+}
+
+// tagPath matches tags/<tag>, which returns the values of a tag.
+var tagPath = regexp.MustCompile(`^tags/([^/]+)$`)
+
+// resourceMethods returns the methods a resource path can be called with,
+// none if the path can't be called.
+func resourceMethods(resourcePath string) []string {
+	if methods, ok := resourcePaths[resourcePath]; ok {
+		return methods
+	}
+	if _, ok := tagWritePaths[strings.ToLower(resourcePath)]; ok {
+		return nil
+	}
+	if match := tagPath.FindStringSubmatch(resourcePath); match != nil && match[1] != "." && match[1] != ".." {
+		return []string{http.MethodGet}
+	}
+	return nil
+}
+
+// CallResource proxies metric and tag lookups to Graphite.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	methods := resourceMethods(req.Path)
+	if methods == nil {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if !slices.Contains(methods, req.Method) {
+		logger.Error("Invalid resource method", "method", req.Method, "path", req.Path)
+		return fmt.Errorf("invalid resource method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "datasource.graphite.CallResource")
+	span.SetAttributes(
+		attribute.String("path", req.Path),
+		attribute.Int64("datasource_id", dsInfo.Id),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	)
+	defer span.End()
+
+	res, body, err := s.doResourceRequest(ctx, dsInfo, req.Method, req.Path, resourceQuery(req.URL), req.Headers["Content-Type"], req.Body)
+	if err != nil {
+		requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCallResource, err, 0)
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		logger.Error("Failed resource call from graphite", "error", err, "path", req.Path)
+		return err
+	}
+	requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCallResource, nil, res.StatusCode)
+	span.SetAttributes(attribute.Int("graphite.response.code", res.StatusCode))
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  res.StatusCode,
+		Headers: map[string][]string{"content-type": {contentType}},
+		Body:    body,
+	})
+}
+
+// CheckHealth checks that the root of the metric tree can be listed.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Failed to get data source info",
+		}, err
+	}
+
+	res, body, err := s.doResourceRequest(ctx, dsInfo, http.MethodGet, "metrics/find", url.Values{"query": []string{"*"}}, nil, nil)
+	if err != nil {
+		requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCheckHealth, err, 0)
+		logger.Error("Graphite health check failed", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Unable to connect with Graphite. Please check the server logs for more details.",
+		}, nil
+	}
+	requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCheckHealth, nil, res.StatusCode)
+
+	if res.StatusCode/100 != 2 {
+		logger.Error("Graphite health check failed", "statusCode", res.StatusCode, "body", string(body))
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Graphite returned an error: %s", res.Status),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source successfully connected.",
+	}, nil
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, method string, resourcePath string, query url.Values, contentType []string, body []byte) (*http.Response, []byte, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse data source URL: %w", err)
+	}
+	u.Path = path.Join(u.Path, resourcePath)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if len(contentType) > 0 {
+		req.Header.Set("Content-Type", contentType[0])
+	}
+	s.tracer.Inject(ctx, req.Header, nil)
+
+	start := time.Now()
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Debug("Response received from graphite", "path", resourcePath, "statusCode", res.StatusCode, "duration", time.Since(start))
+	return res, resBody, nil
+}
+
+// resourceQuery returns the query parameters of a resource request URL.
+func resourceQuery(resourceURL string) url.Values {
+	_, rawQuery, _ := strings.Cut(resourceURL, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return url.Values{}
+	}
+	return query
+}
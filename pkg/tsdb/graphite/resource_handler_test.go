@@ -0,0 +1,146 @@
+package graphite
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type testInstanceManager struct {
+	dsInfo datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		im:     testInstanceManager{dsInfo: datasourceInfo{HTTPClient: server.Client(), URL: server.URL + "/graphite"}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("proxies metric and tag lookups to graphite", func(t *testing.T) {
+		var requests []string
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, r.Method+" "+r.URL.String()+" "+string(body))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"text":"carbon"}]`))
+		})
+
+		sender := &fakeSender{}
+
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "metrics/find",
+			URL:    "metrics/find?query=carbon.*&from=-1h",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.resp.Status)
+		require.JSONEq(t, `[{"text":"carbon"}]`, string(sender.resp.Body))
+
+		err = service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method:  http.MethodPost,
+			Path:    "tags/autoComplete/values",
+			URL:     "tags/autoComplete/values",
+			Headers: map[string][]string{"Content-Type": {"application/x-www-form-urlencoded"}},
+			Body:    []byte("tag=name&expr=env%3Dprod"),
+		}, sender)
+		require.NoError(t, err)
+
+		err = service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "tags/service.name",
+			URL:    "tags/service.name",
+		}, sender)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{
+			"GET /graphite/metrics/find?from=-1h&query=carbon.%2A ",
+			"POST /graphite/tags/autoComplete/values tag=name&expr=env%3Dprod",
+			"GET /graphite/tags/service.name ",
+		}, requests)
+	})
+
+	t.Run("rejects paths which are not graphite lookups", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("unexpected request %s", r.URL)
+		})
+
+		for _, p := range []string{"render", "tags/..", "metrics/find/../../admin", "events/get_data"} {
+			err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: p, URL: p}, nil)
+			require.Error(t, err, p)
+		}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodDelete, Path: "tags", URL: "tags"}, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("rejects tag database writes and posts to read endpoints", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("unexpected request %s", r.URL)
+		})
+
+		for _, p := range []string{"tags/tagSeries", "tags/tagMultiSeries", "tags/delSeries", "tags/delMultiSeries", "tags/DelSeries"} {
+			for _, method := range []string{http.MethodGet, http.MethodPost} {
+				err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: method, Path: p, URL: p}, nil)
+				require.Error(t, err, method+" "+p)
+			}
+		}
+		for _, p := range []string{"metrics/find", "metrics/expand", "tags", "tags/service.name", "functions", "version"} {
+			err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodPost, Path: p, URL: p}, nil)
+			require.Error(t, err, p)
+		}
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("healthy when the metric tree can be listed", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/graphite/metrics/find", r.URL.Path)
+			require.Equal(t, "*", r.URL.Query().Get("query"))
+			_, _ = w.Write([]byte(`[]`))
+		})
+
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("unhealthy when graphite returns an error", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "Graphite returned an error: 401 Unauthorized", res.Message)
+	})
+}
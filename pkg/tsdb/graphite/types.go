@@ -9,6 +9,27 @@ type TargetResponseDTO struct {
 	DataPoints DataTimeSeriesPoints `json:"datapoints"`
 	// Graphite <=1.1.7 may return some tags as numbers requiring extra conversion. See https://github.com/grafana/grafana/issues/37614
 	Tags map[string]any `json:"tags"`
+	// Meta is only returned by Metrictank when the request has meta=true
+	Meta []SeriesMetaDTO `json:"meta,omitempty"`
+}
+
+// MetaResponseDTO is the response of Metrictank when the request has meta=true.
+type MetaResponseDTO struct {
+	Series []TargetResponseDTO `json:"series"`
+	Meta   map[string]any      `json:"meta"`
+}
+
+// SeriesMetaDTO describes which archive Metrictank read a series from and how it was consolidated.
+type SeriesMetaDTO struct {
+	SchemaName            string `json:"schema-name"`
+	SchemaRetentions      string `json:"schema-retentions"`
+	ArchiveRead           int    `json:"archive-read"`
+	ArchiveInterval       int64  `json:"archive-interval"`
+	AggNumNorm            int64  `json:"aggnum-norm"`
+	ConsolidatorNormFetch string `json:"consolidator-normfetch"`
+	AggNumRC              int64  `json:"aggnum-rc"`
+	ConsolidatorRC        string `json:"consolidator-rc"`
+	Count                 int64  `json:"count"`
 }
 
 type DataTimePoint [2]null.Float
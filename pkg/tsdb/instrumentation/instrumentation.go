@@ -0,0 +1,129 @@
+// Package instrumentation counts the requests of the backends of core data
+// sources.
+package instrumentation
+
+import (
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	StatusOK    = "ok"
+	StatusError = "error"
+
+	EndpointCallResource = "callResource"
+	EndpointCheckHealth  = "checkHealth"
+	EndpointQueryData    = "queryData"
+
+	PluginSource   = "plugin"
+	ExternalSource = "external"
+	DatabaseSource = "database"
+	NoneSource     = "none"
+)
+
+// RequestMetrics counts the requests of the backend of a data source by
+// endpoint, status and error source.
+type RequestMetrics struct {
+	requestCounter *prometheus.CounterVec
+}
+
+// NewRequestMetrics registers the grafana_<plugin>_plugin_backend_request_count
+// metric of a data source.
+func NewRequestMetrics(plugin string, name string) *RequestMetrics {
+	return &RequestMetrics{
+		requestCounter: promauto.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "grafana",
+			Name:      plugin + "_plugin_backend_request_count",
+			Help:      fmt.Sprintf("The total amount of %s backend plugin requests", name),
+		}, []string{"endpoint", "status", "errorSource"}),
+	}
+}
+
+func (m *RequestMetrics) UpdateQueryDataMetrics(err error, resp *backend.QueryDataResponse) {
+	status := StatusOK
+	if err != nil {
+		status = StatusError
+	}
+
+	errorSource := PluginSource
+	if err == nil {
+		errorSource = getQueryDataErrorSource(resp)
+	}
+
+	m.requestCounter.WithLabelValues(EndpointQueryData, status, errorSource).Inc()
+}
+
+// getQueryDataErrorSource returns the most severe error source of the
+// responses. The priority order is: plugin > database > external > none.
+func getQueryDataErrorSource(resp *backend.QueryDataResponse) string {
+	errorSource := NoneSource
+	if resp == nil {
+		return errorSource
+	}
+	for _, res := range resp.Responses {
+		if res.Error == nil && res.Status < 400 {
+			continue
+		}
+
+		responseErrorSource := PluginSource
+		if res.Status >= 400 {
+			responseErrorSource = GetErrorSource(nil, int(res.Status))
+		} else if res.ErrorSource == backend.ErrorSourceDownstream {
+			responseErrorSource = DatabaseSource
+		}
+
+		switch responseErrorSource {
+		case PluginSource:
+			return PluginSource
+		case DatabaseSource:
+			errorSource = DatabaseSource
+		case ExternalSource:
+			if errorSource == NoneSource {
+				errorSource = ExternalSource
+			}
+		}
+	}
+	return errorSource
+}
+
+// UpdateRequestMetrics counts a call resource or health check request
+// answered with the given status code by the data source. The status code is
+// 0 when no response was received.
+func (m *RequestMetrics) UpdateRequestMetrics(endpoint string, err error, statusCode int) {
+	status := StatusOK
+	if err != nil || statusCode >= 400 {
+		status = StatusError
+	}
+
+	m.requestCounter.WithLabelValues(endpoint, status, GetErrorSource(err, statusCode)).Inc()
+}
+
+// GetErrorSource classifies errors of requests sent to the data source.
+func GetErrorSource(err error, statusCode int) string {
+	if err != nil && statusCode == 0 {
+		// The data source could not be reached.
+		return DatabaseSource
+	}
+
+	if statusCode >= 500 {
+		return DatabaseSource
+	}
+
+	if statusCode >= 400 {
+		// Those error codes are related to authentication and authorization.
+		if statusCode == 401 || statusCode == 402 || statusCode == 403 || statusCode == 407 {
+			return ExternalSource
+		}
+
+		return PluginSource
+	}
+
+	if err != nil {
+		return PluginSource
+	}
+
+	return NoneSource
+}
@@ -0,0 +1,36 @@
+package instrumentation
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetErrorSource(t *testing.T) {
+	err := errors.New("error")
+
+	require.Equal(t, NoneSource, GetErrorSource(nil, http.StatusOK))
+	require.Equal(t, DatabaseSource, GetErrorSource(err, 0))
+	require.Equal(t, DatabaseSource, GetErrorSource(nil, http.StatusBadGateway))
+	require.Equal(t, ExternalSource, GetErrorSource(nil, http.StatusForbidden))
+	require.Equal(t, PluginSource, GetErrorSource(nil, http.StatusBadRequest))
+	require.Equal(t, PluginSource, GetErrorSource(err, http.StatusOK))
+}
+
+func TestGetQueryDataErrorSource(t *testing.T) {
+	require.Equal(t, NoneSource, getQueryDataErrorSource(nil))
+	require.Equal(t, NoneSource, getQueryDataErrorSource(&backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {},
+	}}))
+	require.Equal(t, DatabaseSource, getQueryDataErrorSource(&backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Error: errors.New("unavailable"), Status: backend.StatusBadGateway},
+		"B": {Error: errors.New("unauthorized"), Status: backend.StatusUnauthorized},
+	}}))
+	require.Equal(t, PluginSource, getQueryDataErrorSource(&backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Error: errors.New("unavailable"), Status: backend.StatusBadGateway},
+		"B": {Error: errors.New("invalid query")},
+	}}))
+}
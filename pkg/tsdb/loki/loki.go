@@ -64,6 +64,9 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 
+	// splitting of long range queries
+	splitting querySplitting
+
 	// open streams
 	streams   map[string]data.FrameJSONCache
 	streamsMu sync.RWMutex
@@ -73,6 +76,7 @@ type QueryJSONModel struct {
 	dataquery.LokiDataQuery
 	Direction           *string `json:"direction,omitempty"`
 	SupportingQueryType *string `json:"supportingQueryType"`
+	SplitDuration       *string `json:"splitDuration,omitempty"`
 }
 
 type ResponseOpts struct {
@@ -98,9 +102,15 @@ func newInstanceSettings(httpClientProvider *httpclient.Provider) datasource.Ins
 			return nil, err
 		}
 
+		splitting, err := parseQuerySplitting(settings.JSONData)
+		if err != nil {
+			return nil, err
+		}
+
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
+			splitting:  splitting,
 			streams:    make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
@@ -196,7 +206,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, dsInfo.splitting, tracer, plog)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -205,7 +215,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, dsInfo.splitting, tracer, plog)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -213,7 +223,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 	return result, err
 }
 
-func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
+func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, splitting querySplitting, tracer tracing.Tracer, plog log.Logger) backend.DataResponse {
 	ctx, span := tracer.Start(ctx, "datasource.loki.queryData.runQueries.runQuery", trace.WithAttributes(
 		attribute.Bool("runInParallel", runInParallel),
 		attribute.String("expr", query.Expr),
@@ -226,7 +236,7 @@ func executeQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataR
 
 	defer span.End()
 
-	queryRes, err := runQuery(ctx, api, query, responseOpts, plog)
+	queryRes, err := runSplitQuery(ctx, api, query, responseOpts, splitting, plog)
 	if queryRes == nil {
 		// we always want to return a backend.DataResponse object, even if we received just an error
 		queryRes = &backend.DataResponse{}
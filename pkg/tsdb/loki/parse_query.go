@@ -171,6 +171,14 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 
 		supportingQueryType := parseSupportingQueryType(model.SupportingQueryType)
 
+		var splitDuration time.Duration
+		if model.SplitDuration != nil && *model.SplitDuration != "" {
+			splitDuration, err = gtime.ParseDuration(*model.SplitDuration)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse splitDuration: %w", err)
+			}
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:                expr,
 			QueryType:           queryType,
@@ -182,6 +190,7 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			End:                 end,
 			RefID:               query.RefID,
 			SupportingQueryType: supportingQueryType,
+			SplitDuration:       splitDuration,
 		})
 	}
 
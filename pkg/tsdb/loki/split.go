@@ -0,0 +1,367 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	// defaultSplitConcurrency is the number of sub-queries of a split query
+	// that are running at the same time, when not configured.
+	defaultSplitConcurrency = 5
+	// minSplitDuration is the shortest time range of a sub-query, shorter
+	// split durations are raised to it.
+	minSplitDuration = time.Minute
+	// maxSplitQueries is the maximum number of sub-queries of a split query.
+	maxSplitQueries = 1000
+)
+
+// querySplitting configures how range queries over a long time range are
+// split into sub-queries over smaller time ranges.
+type querySplitting struct {
+	// Duration is the maximum time range of a sub-query, range queries are
+	// not split when zero.
+	Duration time.Duration
+	// Concurrency is the maximum number of sub-queries running at the same time.
+	Concurrency int
+}
+
+type splittingJSONData struct {
+	QuerySplitDuration    string `json:"querySplitDuration"`
+	QuerySplitConcurrency int    `json:"querySplitConcurrency"`
+}
+
+func parseQuerySplitting(jsonData json.RawMessage) (querySplitting, error) {
+	splitting := querySplitting{Concurrency: defaultSplitConcurrency}
+	if len(jsonData) == 0 {
+		return splitting, nil
+	}
+
+	var settings splittingJSONData
+	if err := json.Unmarshal(jsonData, &settings); err != nil {
+		return splitting, fmt.Errorf("failed to parse query splitting settings: %w", err)
+	}
+
+	if settings.QuerySplitDuration != "" {
+		duration, err := gtime.ParseDuration(settings.QuerySplitDuration)
+		if err != nil {
+			return splitting, fmt.Errorf("invalid querySplitDuration: %w", err)
+		}
+		if duration < 0 {
+			return splitting, fmt.Errorf("invalid querySplitDuration: %s is negative", settings.QuerySplitDuration)
+		}
+		splitting.Duration = duration
+	}
+	if settings.QuerySplitConcurrency > 0 {
+		splitting.Concurrency = settings.QuerySplitConcurrency
+	}
+	return splitting, nil
+}
+
+// splitQuery splits a range query into sub-queries over consecutive time
+// ranges no longer than splitDuration, and at least minSplitDuration or the
+// step of the query. The sub-ranges are aligned to the step of the query, so
+// metric queries are evaluated at the same timestamps as the original query.
+// Consecutive sub-ranges share their boundary, samples and log lines returned
+// twice are removed when merging the responses. It fails when the query would
+// be split into more than maxSplitQueries sub-queries.
+func splitQuery(query *lokiQuery, splitDuration time.Duration) ([]*lokiQuery, error) {
+	if query.QueryType != QueryTypeRange || splitDuration <= 0 || query.End.Sub(query.Start) <= splitDuration {
+		return []*lokiQuery{query}, nil
+	}
+
+	chunk := durationMax(splitDuration, minSplitDuration)
+	if query.Step > 0 {
+		chunk = durationMax(chunk.Truncate(query.Step), query.Step)
+	}
+
+	count := (query.End.Sub(query.Start) + chunk - 1) / chunk
+	if count > maxSplitQueries {
+		return nil, fmt.Errorf("query would be split into %d sub-queries, more than the maximum of %d, use a longer split duration", count, maxSplitQueries)
+	}
+
+	queries := make([]*lokiQuery, 0, count)
+	for start := query.Start; start.Before(query.End); start = start.Add(chunk) {
+		end := start.Add(chunk)
+		if end.After(query.End) {
+			end = query.End
+		}
+		subQuery := *query
+		subQuery.Start = start
+		subQuery.End = end
+		queries = append(queries, &subQuery)
+	}
+	return queries, nil
+}
+
+// runSplitQuery runs a query split in sub-queries when its time range is longer
+// than the split duration, and merges the responses of the sub-queries.
+//
+// Sub-queries are run in the order of the direction of the query, at most
+// splitting.Concurrency at a time. Once the sub-queries of a logs query
+// returned the requested number of lines, the remaining ones are not run.
+func runSplitQuery(ctx context.Context, api *LokiAPI, query *lokiQuery, responseOpts ResponseOpts, splitting querySplitting, plog log.Logger) (*backend.DataResponse, error) {
+	splitDuration := splitting.Duration
+	if query.SplitDuration > 0 {
+		splitDuration = query.SplitDuration
+	}
+
+	queries, err := splitQuery(query, splitDuration)
+	if err != nil {
+		return nil, err
+	}
+	if len(queries) == 1 {
+		return runQuery(ctx, api, query, responseOpts, plog)
+	}
+
+	// the newest logs are fetched first when the direction is backward
+	if query.Direction == DirectionBackward {
+		slices.Reverse(queries)
+	}
+
+	limit := splitting.Concurrency
+	if limit <= 0 {
+		limit = defaultSplitConcurrency
+	}
+
+	plog.Debug("Splitting query", "refId", query.RefID, "splitDuration", splitDuration, "subQueries", len(queries), "concurrency", limit)
+
+	responses := make([]*backend.DataResponse, len(queries))
+	logs := false
+	for next := 0; next < len(queries); {
+		// logs queries are run in batches so we can stop once we have enough
+		// lines, the sub-queries of metric queries are all run at once.
+		batch := len(queries) - next
+		if logs || next == 0 {
+			batch = min(batch, limit)
+		}
+
+		var mu sync.Mutex
+		offset := next
+		err := concurrency.ForEachJob(ctx, batch, limit, func(ctx context.Context, idx int) error {
+			res, err := runQuery(ctx, api, queries[offset+idx], responseOpts, plog)
+			if res == nil {
+				res = &backend.DataResponse{}
+			}
+			if err != nil {
+				res.Error = err
+			}
+
+			mu.Lock()
+			responses[offset+idx] = res
+			mu.Unlock()
+			return res.Error
+		})
+		if err != nil {
+			for _, res := range responses[offset : offset+batch] {
+				if res != nil && res.Error != nil {
+					return res, res.Error
+				}
+			}
+			return nil, err
+		}
+		next += batch
+
+		logs = logs || hasLogsFrames(responses[:next])
+		if logs && query.MaxLines > 0 && next < len(queries) {
+			merged := mergeSplitResponses(query, queries[:next], responses[:next])
+			if countLogLines(merged) >= query.MaxLines {
+				plog.Debug("Stopped running sub-queries, line limit reached", "refId", query.RefID, "subQueries", next)
+				queries, responses = queries[:next], responses[:next]
+				break
+			}
+		}
+	}
+
+	return mergeSplitResponses(query, queries, responses), nil
+}
+
+func isLogsFrame(frame *data.Frame) bool {
+	// adjusted logs frames start with the labels field
+	return len(frame.Fields) > 0 && frame.Fields[0].Type() == data.FieldTypeJSON
+}
+
+func hasLogsFrames(responses []*backend.DataResponse) bool {
+	for _, res := range responses {
+		for _, frame := range res.Frames {
+			if isLogsFrame(frame) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func countLogLines(res *backend.DataResponse) int {
+	lines := 0
+	for _, frame := range res.Frames {
+		if isLogsFrame(frame) {
+			lines += frame.Rows()
+		}
+	}
+	return lines
+}
+
+// mergeSplitResponses merges the frames returned for the same series by the
+// sub-queries of a split query.
+func mergeSplitResponses(query *lokiQuery, queries []*lokiQuery, responses []*backend.DataResponse) *backend.DataResponse {
+	// the frames of a series are merged in the order of time
+	order := make([]int, len(queries))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return queries[order[i]].Start.Before(queries[order[j]].Start)
+	})
+
+	var keys []string
+	series := map[string][]*data.Frame{}
+	for _, idx := range order {
+		for _, frame := range responses[idx].Frames {
+			key := frameKey(frame)
+			if _, ok := series[key]; !ok {
+				keys = append(keys, key)
+			}
+			series[key] = append(series[key], frame)
+		}
+	}
+
+	res := &backend.DataResponse{}
+	for _, key := range keys {
+		frames := series[key]
+		if isLogsFrame(frames[0]) {
+			res.Frames = append(res.Frames, mergeLogsFrames(query, frames))
+		} else {
+			res.Frames = append(res.Frames, mergeMetricFrames(frames))
+		}
+	}
+	return res
+}
+
+// frameKey identifies the frames of the same series, or of the same logs
+// response shape.
+func frameKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("\x00")
+		sb.WriteString(field.Name)
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
+
+func newMergedFrame(frames []*data.Frame) *data.Frame {
+	first := frames[0]
+	merged := first.EmptyCopy()
+	for i, field := range first.Fields {
+		merged.Fields[i].Config = field.Config
+	}
+
+	if first.Meta != nil {
+		meta := *first.Meta
+		meta.Stats = mergeStats(frames)
+		merged.Meta = &meta
+	}
+	return merged
+}
+
+// mergeMetricFrames concatenates the samples of a series, samples at the
+// boundary of two sub-queries are only kept once.
+func mergeMetricFrames(frames []*data.Frame) *data.Frame {
+	merged := newMergedFrame(frames)
+
+	var last time.Time
+	for _, frame := range frames {
+		timeField := frame.Fields[0]
+		for i := 0; i < frame.Rows(); i++ {
+			t, ok := timeField.At(i).(time.Time)
+			if ok && merged.Rows() > 0 && !t.After(last) {
+				continue
+			}
+			merged.AppendRow(frame.RowCopy(i)...)
+			last = t
+		}
+	}
+	return merged
+}
+
+// mergeLogsFrames concatenates the log lines, sorts them in the direction of
+// the query and keeps at most the requested number of lines. Lines returned
+// by two sub-queries are only kept once.
+func mergeLogsFrames(query *lokiQuery, frames []*data.Frame) *data.Frame {
+	merged := newMergedFrame(frames)
+
+	type row struct {
+		frame *data.Frame
+		idx   int
+		time  time.Time
+	}
+
+	var rows []row
+	seen := map[string]struct{}{}
+	for _, frame := range frames {
+		idField, _ := frame.FieldByName("id")
+		timeField := frame.Fields[1]
+		for i := 0; i < frame.Rows(); i++ {
+			if idField != nil {
+				id, _ := idField.At(i).(string)
+				if _, ok := seen[id]; ok {
+					continue
+				}
+				seen[id] = struct{}{}
+			}
+			t, _ := timeField.At(i).(time.Time)
+			rows = append(rows, row{frame: frame, idx: i, time: t})
+		}
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		if query.Direction == DirectionForward {
+			return rows[i].time.Before(rows[j].time)
+		}
+		return rows[i].time.After(rows[j].time)
+	})
+
+	if query.MaxLines > 0 && len(rows) > query.MaxLines {
+		rows = rows[:query.MaxLines]
+	}
+
+	for _, r := range rows {
+		merged.AppendRow(r.frame.RowCopy(r.idx)...)
+	}
+	return merged
+}
+
+// mergeStats sums the query statistics of the frames.
+func mergeStats(frames []*data.Frame) []data.QueryStat {
+	var stats []data.QueryStat
+	index := map[string]int{}
+	for _, frame := range frames {
+		if frame.Meta == nil {
+			continue
+		}
+		for _, stat := range frame.Meta.Stats {
+			name := stat.DisplayName
+			if i, ok := index[name]; ok {
+				stats[i].Value += stat.Value
+				continue
+			}
+			index[name] = len(stats)
+			stats = append(stats, stat)
+		}
+	}
+	return stats
+}
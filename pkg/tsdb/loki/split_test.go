@@ -0,0 +1,295 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type splitRoundTripper struct {
+	mu       sync.Mutex
+	requests []url.Values
+	respond  func(params url.Values) (int, string)
+}
+
+func (rt *splitRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	rt.mu.Lock()
+	rt.requests = append(rt.requests, params)
+	rt.mu.Unlock()
+
+	status, body := rt.respond(params)
+	header := http.Header{}
+	header.Add("Content-Type", "application/json")
+	return &http.Response{
+		StatusCode: status,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil
+}
+
+func makeSplitMockedAPI(respond func(params url.Values) (int, string)) (*LokiAPI, *splitRoundTripper) {
+	rt := &splitRoundTripper{respond: respond}
+	client := http.Client{Transport: rt}
+	return newLokiAPI(&client, "http://localhost:9999", backend.NewLoggerWith("logger", "test"), tracing.InitializeTracerForTest(), false), rt
+}
+
+func paramTime(t *testing.T, params url.Values, name string) int64 {
+	t.Helper()
+	ns, err := strconv.ParseInt(params.Get(name), 10, 64)
+	require.NoError(t, err)
+	return ns
+}
+
+// matrixResponse returns a sample every step between start and end, both included.
+func matrixResponse(t *testing.T, params url.Values) (int, string) {
+	step, err := time.ParseDuration(params.Get("step"))
+	require.NoError(t, err)
+	var values []string
+	for ns := paramTime(t, params, "start"); ns <= paramTime(t, params, "end"); ns += step.Nanoseconds() {
+		values = append(values, fmt.Sprintf(`[%d,"%d"]`, ns/int64(time.Second), ns/int64(time.Second)))
+	}
+	return http.StatusOK, fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"level":"error"},"values":[%s]}]}}`, strings.Join(values, ","))
+}
+
+// streamsResponse returns a log line every 10 seconds between start and end,
+// the end is included when includeEnd is true.
+func streamsResponse(t *testing.T, params url.Values, includeEnd bool) (int, string) {
+	start, end := paramTime(t, params, "start"), paramTime(t, params, "end")
+	var values []string
+	for ns := start; ns < end || (includeEnd && ns == end); ns += int64(10 * time.Second) {
+		values = append(values, fmt.Sprintf(`["%d","line %d"]`, ns, ns/int64(time.Second)))
+	}
+	if params.Get("direction") == string(DirectionBackward) {
+		for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+			values[i], values[j] = values[j], values[i]
+		}
+	}
+	if limit, err := strconv.Atoi(params.Get("limit")); err == nil && len(values) > limit {
+		values = values[:limit]
+	}
+	return http.StatusOK, fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"shop"},"values":[%s]}]}}`, strings.Join(values, ","))
+}
+
+func TestSplitQuery(t *testing.T) {
+	query := &lokiQuery{
+		QueryType: QueryTypeRange,
+		Start:     time.Unix(0, 0),
+		End:       time.Unix(300, 0),
+		Step:      45 * time.Second,
+	}
+
+	t.Run("splits in step aligned time ranges", func(t *testing.T) {
+		queries, err := splitQuery(query, 100*time.Second)
+		require.NoError(t, err)
+		// 100s is aligned to the 45s step
+		require.Len(t, queries, 4)
+		expected := [][2]int64{{0, 90}, {90, 180}, {180, 270}, {270, 300}}
+		for i, q := range queries {
+			require.Equal(t, expected[i][0], q.Start.Unix())
+			require.Equal(t, expected[i][1], q.End.Unix())
+			require.Equal(t, query.Step, q.Step)
+		}
+	})
+
+	t.Run("uses the step when longer than the split duration", func(t *testing.T) {
+		queries, err := splitQuery(query, 10*time.Second)
+		require.NoError(t, err)
+		require.Len(t, queries, 7)
+	})
+
+	t.Run("uses the minimum split duration", func(t *testing.T) {
+		noStep := *query
+		noStep.Step = 0
+		queries, err := splitQuery(&noStep, time.Millisecond)
+		require.NoError(t, err)
+		require.Len(t, queries, 5)
+		require.Equal(t, minSplitDuration, queries[0].End.Sub(queries[0].Start))
+	})
+
+	t.Run("fails when split into too many sub-queries", func(t *testing.T) {
+		long := *query
+		long.End = long.Start.Add(30 * 24 * time.Hour)
+		long.Step = time.Millisecond
+		_, err := splitQuery(&long, time.Millisecond)
+		require.ErrorContains(t, err, "more than the maximum")
+
+		queries, err := splitQuery(&long, time.Hour)
+		require.NoError(t, err)
+		require.Len(t, queries, 720)
+	})
+
+	t.Run("does not split short queries", func(t *testing.T) {
+		for _, splitDuration := range []time.Duration{300 * time.Second, 0} {
+			queries, err := splitQuery(query, splitDuration)
+			require.NoError(t, err)
+			require.Equal(t, []*lokiQuery{query}, queries)
+		}
+	})
+
+	t.Run("does not split instant queries", func(t *testing.T) {
+		instant := *query
+		instant.QueryType = QueryTypeInstant
+		queries, err := splitQuery(&instant, 10*time.Second)
+		require.NoError(t, err)
+		require.Equal(t, []*lokiQuery{&instant}, queries)
+	})
+}
+
+func TestParseQuerySplitting(t *testing.T) {
+	splitting, err := parseQuerySplitting(nil)
+	require.NoError(t, err)
+	require.Equal(t, querySplitting{Concurrency: defaultSplitConcurrency}, splitting)
+
+	splitting, err = parseQuerySplitting([]byte(`{"querySplitDuration":"1d","querySplitConcurrency":2}`))
+	require.NoError(t, err)
+	require.Equal(t, querySplitting{Duration: 24 * time.Hour, Concurrency: 2}, splitting)
+
+	_, err = parseQuerySplitting([]byte(`{"querySplitDuration":"abc"}`))
+	require.Error(t, err)
+}
+
+func TestRunSplitQuery(t *testing.T) {
+	logger := backend.NewLoggerWith("logger", "test")
+
+	t.Run("merges the series of metric queries", func(t *testing.T) {
+		api, rt := makeSplitMockedAPI(func(params url.Values) (int, string) {
+			return matrixResponse(t, params)
+		})
+		query := &lokiQuery{
+			Expr:      `count_over_time({app="shop"}[1m])`,
+			QueryType: QueryTypeRange,
+			Direction: DirectionBackward,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(300, 0),
+			Step:      time.Minute,
+			MaxLines:  1000,
+			RefID:     "A",
+		}
+
+		res, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, querySplitting{Duration: 2 * time.Minute, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, rt.requests, 3)
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 6, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			require.Equal(t, time.Unix(int64(i*60), 0).UTC(), frame.Fields[0].At(i))
+			require.Equal(t, float64(i*60), frame.Fields[1].At(i))
+		}
+		require.Equal(t, "{level=\"error\"}", frame.Name)
+		require.Equal(t, float64(time.Minute.Milliseconds()), frame.Fields[0].Config.Interval)
+	})
+
+	t.Run("stops once the line limit of logs queries is reached", func(t *testing.T) {
+		api, rt := makeSplitMockedAPI(func(params url.Values) (int, string) {
+			return streamsResponse(t, params, false)
+		})
+		query := &lokiQuery{
+			Expr:      `{app="shop"}`,
+			QueryType: QueryTypeRange,
+			Direction: DirectionBackward,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(300, 0),
+			Step:      time.Second,
+			MaxLines:  8,
+			RefID:     "A",
+		}
+
+		res, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, querySplitting{Duration: time.Minute, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		// the two newest time ranges return enough lines
+		require.Len(t, rt.requests, 2)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, 8, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			require.Equal(t, time.Unix(int64(290-i*10), 0).UTC(), frame.Fields[1].At(i))
+		}
+	})
+
+	t.Run("removes duplicate log lines at boundaries", func(t *testing.T) {
+		api, rt := makeSplitMockedAPI(func(params url.Values) (int, string) {
+			return streamsResponse(t, params, true)
+		})
+		query := &lokiQuery{
+			Expr:      `{app="shop"}`,
+			QueryType: QueryTypeRange,
+			Direction: DirectionForward,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(300, 0),
+			Step:      time.Second,
+			MaxLines:  1000,
+			RefID:     "A",
+		}
+
+		res, err := runSplitQuery(context.Background(), api, query, ResponseOpts{logsDataplane: true}, querySplitting{Duration: time.Minute, Concurrency: 2}, logger)
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, rt.requests, 5)
+
+		frame := res.Frames[0]
+		require.Equal(t, 31, frame.Rows())
+		for i := 0; i < frame.Rows(); i++ {
+			require.Equal(t, time.Unix(int64(i*10), 0).UTC(), frame.Fields[1].At(i))
+		}
+	})
+
+	t.Run("returns the error of a failed sub-query", func(t *testing.T) {
+		api, _ := makeSplitMockedAPI(func(params url.Values) (int, string) {
+			if paramTime(t, params, "start") > 0 {
+				return http.StatusBadRequest, `{"message":"the query time range exceeds the limit"}`
+			}
+			return matrixResponse(t, params)
+		})
+		query := &lokiQuery{
+			Expr:      `count_over_time({app="shop"}[1m])`,
+			QueryType: QueryTypeRange,
+			Direction: DirectionForward,
+			Start:     time.Unix(0, 0),
+			End:       time.Unix(300, 0),
+			Step:      time.Minute,
+			RefID:     "A",
+		}
+
+		res, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, querySplitting{Duration: 2 * time.Minute, Concurrency: 1}, logger)
+		require.EqualError(t, err, "the query time range exceeds the limit")
+		require.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+
+	t.Run("uses the split duration of the query", func(t *testing.T) {
+		api, rt := makeSplitMockedAPI(func(params url.Values) (int, string) {
+			return matrixResponse(t, params)
+		})
+		query := &lokiQuery{
+			Expr:          `count_over_time({app="shop"}[1m])`,
+			QueryType:     QueryTypeRange,
+			Start:         time.Unix(0, 0),
+			End:           time.Unix(300, 0),
+			Step:          time.Minute,
+			RefID:         "A",
+			SplitDuration: 3 * time.Minute,
+		}
+
+		_, err := runSplitQuery(context.Background(), api, query, ResponseOpts{}, querySplitting{}, logger)
+		require.NoError(t, err)
+		require.Len(t, rt.requests, 2)
+	})
+}
@@ -113,6 +113,7 @@ func newMSSQL(ctx context.Context, driverName string, userFacingDefaultError str
 		DSInfo:            dsInfo,
 		MetricColumnTypes: []string{"VARCHAR", "CHAR", "NVARCHAR", "NCHAR"},
 		RowLimit:          rowLimit,
+		SessionHandler:    &mssqlSessionHandler{},
 	}
 
 	queryResultTransformer := mssqlQueryResultTransformer{
@@ -302,6 +303,11 @@ func generateConnectionString(dsInfo sqleng.DataSourceInfo, cfg *setting.Cfg, az
 		connStr += fmt.Sprintf("connection timeout=%d;", dsInfo.JsonData.ConnectionTimeout)
 	}
 
+	// the application intent may already be set with the host
+	if dsInfo.JsonData.ReadOnly && !strings.Contains(strings.ToLower(connStr), "applicationintent=") {
+		connStr += "ApplicationIntent=ReadOnly;"
+	}
+
 	return connStr, nil
 }
 
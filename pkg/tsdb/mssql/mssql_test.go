@@ -1535,6 +1535,26 @@ func TestGenerateConnectionString(t *testing.T) {
 			},
 			expConnStr: "server=localhost\\instance;database=database;user id=user;password=;port=333;ApplicationIntent=ReadOnly;",
 		},
+		{
+			desc: "Read-only",
+			dataSource: sqleng.DataSourceInfo{
+				URL:      "localhost:333",
+				Database: "database",
+				User:     "user",
+				JsonData: sqleng.JsonData{ReadOnly: true},
+			},
+			expConnStr: "server=localhost;database=database;user id=user;password=;port=333;ApplicationIntent=ReadOnly;",
+		},
+		{
+			desc: "Read-only with ApplicationIntent",
+			dataSource: sqleng.DataSourceInfo{
+				URL:      "localhost\\instance;ApplicationIntent=ReadOnly",
+				Database: "database",
+				User:     "user",
+				JsonData: sqleng.JsonData{ReadOnly: true},
+			},
+			expConnStr: "server=localhost\\instance;ApplicationIntent=ReadOnly;database=database;user id=user;password=;",
+		},
 		{
 			desc: "Defaults",
 			dataSource: sqleng.DataSourceInfo{
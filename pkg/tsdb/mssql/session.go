@@ -0,0 +1,27 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+)
+
+// mssqlSessionHandler runs the queries of read-only data sources in transactions, which are rolled
+// back since SQL Server has no read-only transactions. Read-only is therefore best effort: changes
+// are undone but the statements still run with the permissions of the user, and the connections
+// only declare a read-only application intent, which matters for routing to readable secondary
+// replicas. SQL Server has no statement timeout either, the query timeout cancels the context of
+// the query and the driver then sends an attention to cancel the statement on the server.
+type mssqlSessionHandler struct{}
+
+func (h *mssqlSessionHandler) TxOptions(jsonData sqleng.JsonData) *sql.TxOptions {
+	if !jsonData.ReadOnly {
+		return nil
+	}
+	return &sql.TxOptions{}
+}
+
+func (h *mssqlSessionHandler) Prepare(_ context.Context, _ *sql.Tx, _ sqleng.JsonData) (func() error, error) {
+	return nil, nil
+}
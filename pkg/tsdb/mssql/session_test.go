@@ -0,0 +1,40 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/mssql/sqleng"
+)
+
+func TestMSSQLSessionHandler(t *testing.T) {
+	t.Run("queries run in transactions only for read-only data sources", func(t *testing.T) {
+		handler := &mssqlSessionHandler{}
+		require.Nil(t, handler.TxOptions(sqleng.JsonData{}))
+		require.Nil(t, handler.TxOptions(sqleng.JsonData{QueryTimeout: 10}))
+		require.Equal(t, &sql.TxOptions{}, handler.TxOptions(sqleng.JsonData{ReadOnly: true}))
+	})
+
+	t.Run("transactions have no session statements", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		handler := &mssqlSessionHandler{}
+		jsonData := sqleng.JsonData{ReadOnly: true, QueryTimeout: 10}
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		tx, err := db.BeginTx(context.Background(), handler.TxOptions(jsonData))
+		require.NoError(t, err)
+		stop, err := handler.Prepare(context.Background(), tx, jsonData)
+		require.NoError(t, err)
+		require.Nil(t, stop)
+		require.NoError(t, tx.Rollback())
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
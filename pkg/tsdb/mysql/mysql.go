@@ -154,6 +154,8 @@ func NewInstanceSettings(logger log.Logger) datasource.InstanceFactoryFunc {
 		db.SetMaxIdleConns(config.DSInfo.JsonData.MaxIdleConns)
 		db.SetConnMaxLifetime(time.Duration(config.DSInfo.JsonData.ConnMaxLifetime) * time.Second)
 
+		config.SessionHandler = &mysqlSessionHandler{db: db}
+
 		return sqleng.NewQueryDataHandler(userFacingDefaultError, db, config, &rowTransformer, newMysqlMacroEngine(logger, userFacingDefaultError), logger)
 	}
 }
@@ -166,7 +168,7 @@ func (t *mysqlQueryResultTransformer) TransformQueryError(logger log.Logger, err
 	var driverErr *mysql.MySQLError
 	if errors.As(err, &driverErr) {
 		if driverErr.Number != mysqlerr.ER_PARSE_ERROR && driverErr.Number != mysqlerr.ER_BAD_FIELD_ERROR &&
-			driverErr.Number != mysqlerr.ER_NO_SUCH_TABLE {
+			driverErr.Number != mysqlerr.ER_NO_SUCH_TABLE && driverErr.Number != mysqlerr.ER_CANT_EXECUTE_IN_READ_ONLY_TRANSACTION {
 			logger.Error("Query error", "error", err)
 			return fmt.Errorf(("query failed - %s"), t.userError)
 		}
@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+)
+
+// killQueryTimeout is the timeout of the statement stopping a cancelled query on the server.
+const killQueryTimeout = 10 * time.Second
+
+// mysqlSessionHandler runs the queries of read-only data sources, or having a query timeout, in
+// transactions. The driver only closes the connection of a cancelled query, so the query is
+// stopped on the server with KILL QUERY.
+type mysqlSessionHandler struct {
+	db *sql.DB
+}
+
+func (h *mysqlSessionHandler) TxOptions(jsonData sqleng.JsonData) *sql.TxOptions {
+	if !jsonData.ReadOnly && jsonData.QueryTimeout <= 0 {
+		return nil
+	}
+	// the driver starts read-only transactions with START TRANSACTION READ ONLY, which only
+	// applies to the current transaction and doesn't leak to the pooled connection.
+	return &sql.TxOptions{ReadOnly: jsonData.ReadOnly}
+}
+
+func (h *mysqlSessionHandler) Prepare(ctx context.Context, tx *sql.Tx, _ sqleng.JsonData) (func() error, error) {
+	var connectionID uint64
+	if err := tx.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&connectionID); err != nil {
+		return nil, err
+	}
+
+	return func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), killQueryTimeout)
+		defer cancel()
+		_, err := h.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", connectionID))
+		return err
+	}, nil
+}
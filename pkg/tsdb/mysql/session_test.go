@@ -0,0 +1,43 @@
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/mysql/sqleng"
+)
+
+func TestMySQLSessionHandler(t *testing.T) {
+	t.Run("queries run in transactions only for read-only data sources or with a query timeout", func(t *testing.T) {
+		handler := &mysqlSessionHandler{}
+		require.Nil(t, handler.TxOptions(sqleng.JsonData{}))
+		require.Equal(t, &sql.TxOptions{ReadOnly: true}, handler.TxOptions(sqleng.JsonData{ReadOnly: true}))
+		require.Equal(t, &sql.TxOptions{}, handler.TxOptions(sqleng.JsonData{QueryTimeout: 10}))
+	})
+
+	t.Run("stopped queries are killed on the server", func(t *testing.T) {
+		db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = db.Close() })
+		handler := &mysqlSessionHandler{db: db}
+		jsonData := sqleng.JsonData{ReadOnly: true}
+
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT CONNECTION_ID()").WillReturnRows(sqlmock.NewRows([]string{"CONNECTION_ID()"}).AddRow(42))
+		mock.ExpectExec("KILL QUERY 42").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		tx, err := db.BeginTx(context.Background(), handler.TxOptions(jsonData))
+		require.NoError(t, err)
+		stop, err := handler.Prepare(context.Background(), tx, jsonData)
+		require.NoError(t, err)
+		require.NotNil(t, stop)
+		require.NoError(t, stop())
+		require.NoError(t, tx.Rollback())
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
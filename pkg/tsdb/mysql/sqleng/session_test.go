@@ -0,0 +1,125 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSessionHandler struct {
+	txOptions *sql.TxOptions
+	prepared  atomic.Int32
+	stopped   atomic.Int32
+}
+
+func (h *fakeSessionHandler) TxOptions(_ JsonData) *sql.TxOptions {
+	return h.txOptions
+}
+
+func (h *fakeSessionHandler) Prepare(_ context.Context, _ *sql.Tx, _ JsonData) (func() error, error) {
+	h.prepared.Add(1)
+	return func() error {
+		h.stopped.Add(1)
+		return nil
+	}, nil
+}
+
+func newSessionTestHandler(t *testing.T, jsonData JsonData, sessionHandler SessionHandler) (*DataSourceHandler, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return &DataSourceHandler{
+		db:             db,
+		dsInfo:         DataSourceInfo{JsonData: jsonData},
+		sessionHandler: sessionHandler,
+		log:            backend.NewLoggerWith("logger", "test"),
+	}, mock
+}
+
+func runSessionQuery(ctx context.Context, handler *DataSourceHandler, query string) error {
+	session, err := handler.query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer session.close(handler.log)
+	for session.rows.Next() {
+	}
+	return session.err(session.rows.Err())
+}
+
+func TestQuerySession(t *testing.T) {
+	const query = `SELECT value FROM metrics`
+	newRows := func() *sqlmock.Rows { return sqlmock.NewRows([]string{"value"}).AddRow(1) }
+
+	t.Run("queries run outside of transactions without session handler", func(t *testing.T) {
+		handler, mock := newSessionTestHandler(t, JsonData{ReadOnly: true}, nil)
+		mock.ExpectQuery(query).WillReturnRows(newRows())
+
+		require.NoError(t, runSessionQuery(context.Background(), handler, query))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("queries run outside of transactions without transaction options", func(t *testing.T) {
+		sessionHandler := &fakeSessionHandler{}
+		handler, mock := newSessionTestHandler(t, JsonData{}, sessionHandler)
+		mock.ExpectQuery(query).WillReturnRows(newRows())
+
+		require.NoError(t, runSessionQuery(context.Background(), handler, query))
+		require.NoError(t, mock.ExpectationsWereMet())
+		require.Equal(t, int32(0), sessionHandler.prepared.Load())
+	})
+
+	t.Run("transactions of read-only data sources are rolled back", func(t *testing.T) {
+		sessionHandler := &fakeSessionHandler{txOptions: &sql.TxOptions{ReadOnly: true}}
+		handler, mock := newSessionTestHandler(t, JsonData{ReadOnly: true}, sessionHandler)
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).WillReturnRows(newRows())
+		mock.ExpectRollback()
+
+		require.NoError(t, runSessionQuery(context.Background(), handler, query))
+		require.NoError(t, mock.ExpectationsWereMet())
+		require.Equal(t, int32(1), sessionHandler.prepared.Load())
+		require.Equal(t, int32(0), sessionHandler.stopped.Load())
+	})
+
+	t.Run("transactions of other data sources are committed", func(t *testing.T) {
+		sessionHandler := &fakeSessionHandler{txOptions: &sql.TxOptions{}}
+		handler, mock := newSessionTestHandler(t, JsonData{QueryTimeout: 10}, sessionHandler)
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).WillReturnRows(newRows())
+		mock.ExpectCommit()
+
+		require.NoError(t, runSessionQuery(context.Background(), handler, query))
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("queries are interrupted after the query timeout", func(t *testing.T) {
+		handler, mock := newSessionTestHandler(t, JsonData{QueryTimeout: 1}, nil)
+		mock.ExpectQuery(query).WillDelayFor(time.Minute).WillReturnRows(newRows())
+
+		start := time.Now()
+		err := runSessionQuery(context.Background(), handler, query)
+		require.EqualError(t, err, "query exceeded the timeout of 1s: context deadline exceeded")
+		require.Less(t, time.Since(start), 10*time.Second)
+	})
+
+	t.Run("cancelled queries are stopped on the server", func(t *testing.T) {
+		sessionHandler := &fakeSessionHandler{txOptions: &sql.TxOptions{}}
+		handler, mock := newSessionTestHandler(t, JsonData{}, sessionHandler)
+		mock.ExpectBegin()
+		mock.ExpectQuery(query).WillDelayFor(time.Minute).WillReturnRows(newRows())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		require.Error(t, runSessionQuery(ctx, handler, query))
+		require.Eventually(t, func() bool { return sessionHandler.stopped.Load() == 1 }, time.Second, 10*time.Millisecond)
+	})
+}
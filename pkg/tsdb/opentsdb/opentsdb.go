@@ -21,9 +21,13 @@ import (
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/instrumentation"
 )
 
-var logger = log.New("tsdb.opentsdb")
+var (
+	logger         = log.New("tsdb.opentsdb")
+	requestMetrics = instrumentation.NewRequestMetrics("opentsdb", "OpenTSDB")
+)
 
 type Service struct {
 	im instancemgmt.InstanceManager
@@ -64,6 +68,12 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp, err := s.queryData(ctx, req)
+	requestMetrics.UpdateQueryDataMetrics(err, resp)
+	return resp, err
+}
+
+func (s *Service) queryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	var tsdbQuery OpenTsdbQuery
 
 	logger := logger.FromContext(ctx)
@@ -0,0 +1,150 @@
+package opentsdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/tsdb/instrumentation"
+)
+
+var (
+	_ backend.CallResourceHandler = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+)
+
+// resourcePaths maps the resources of the data source to the OpenTSDB API
+// endpoints used for metric, tag and aggregator autocompletion.
+var resourcePaths = map[string]string{
+	"suggest":        "api/suggest",
+	"aggregators":    "api/aggregators",
+	"config/filters": "api/config/filters",
+	"search/lookup":  "api/search/lookup",
+}
+
+// CallResource proxies suggest, aggregator and tag lookups to OpenTSDB.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	apiPath, ok := resourcePaths[req.Path]
+	if !ok {
+		logger.Error("Invalid resource path", "path", req.Path)
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if req.Method != http.MethodGet {
+		logger.Error("Invalid resource method", "method", req.Method, "path", req.Path)
+		return fmt.Errorf("invalid resource method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+
+	res, body, err := s.doResourceRequest(ctx, dsInfo, apiPath, resourceQuery(req.URL))
+	if err != nil {
+		requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCallResource, err, 0)
+		logger.Error("Failed resource call from opentsdb", "error", err, "path", req.Path)
+		return err
+	}
+	requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCallResource, nil, res.StatusCode)
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  res.StatusCode,
+		Headers: map[string][]string{"content-type": {contentType}},
+		Body:    body,
+	})
+}
+
+// CheckHealth checks that the aggregators of OpenTSDB can be listed.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Failed to get data source info",
+		}, err
+	}
+
+	res, body, err := s.doResourceRequest(ctx, dsInfo, "api/aggregators", url.Values{})
+	if err != nil {
+		requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCheckHealth, err, 0)
+		logger.Error("OpenTSDB health check failed", "error", err)
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Unable to connect with OpenTSDB. Please check the server logs for more details.",
+		}, nil
+	}
+	requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCheckHealth, nil, res.StatusCode)
+
+	if res.StatusCode/100 != 2 {
+		logger.Error("OpenTSDB health check failed", "statusCode", res.StatusCode, "body", string(body))
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("OpenTSDB returned an error: %s", res.Status),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source successfully connected.",
+	}, nil
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *datasourceInfo, apiPath string, query url.Values) (*http.Response, []byte, error) {
+	logger := logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse data source URL: %w", err)
+	}
+	u.Path = path.Join(u.Path, apiPath)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	logger.Debug("Response received from opentsdb", "path", apiPath, "statusCode", res.StatusCode, "duration", time.Since(start))
+	return res, body, nil
+}
+
+// resourceQuery returns the query parameters of a resource request URL.
+func resourceQuery(resourceURL string) url.Values {
+	_, rawQuery, _ := strings.Cut(resourceURL, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return url.Values{}
+	}
+	return query
+}
@@ -0,0 +1,115 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+)
+
+type testInstanceManager struct {
+	dsInfo *datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		im: testInstanceManager{dsInfo: &datasourceInfo{HTTPClient: server.Client(), URL: server.URL}},
+	}
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("proxies suggest requests to opentsdb", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/suggest", r.URL.Path)
+			require.Equal(t, "metrics", r.URL.Query().Get("type"))
+			require.Equal(t, "cpu", r.URL.Query().Get("q"))
+			w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+			_, _ = w.Write([]byte(`["cpu.idle","cpu.user"]`))
+		})
+
+		sender := &fakeSender{}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "suggest",
+			URL:    "suggest?type=metrics&q=cpu&max=1000",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, sender.resp.Status)
+		require.Equal(t, []string{"application/json; charset=UTF-8"}, sender.resp.Headers["content-type"])
+		require.JSONEq(t, `["cpu.idle","cpu.user"]`, string(sender.resp.Body))
+	})
+
+	t.Run("passes opentsdb errors to the client", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"message":"Missing type"}}`))
+		})
+
+		sender := &fakeSender{}
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			Path:   "search/lookup",
+			URL:    "search/lookup?m=cpu",
+		}, sender)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusBadRequest, sender.resp.Status)
+	})
+
+	t.Run("rejects unknown resources", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("unexpected request %s", r.URL)
+		})
+
+		err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: "query", URL: "query"}, nil)
+		require.Error(t, err)
+		err = service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodPost, Path: "suggest", URL: "suggest"}, nil)
+		require.Error(t, err)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("healthy when aggregators can be listed", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/aggregators", r.URL.Path)
+			_, _ = w.Write([]byte(`["sum","avg"]`))
+		})
+
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("unhealthy when opentsdb cannot be reached", func(t *testing.T) {
+		service := &Service{
+			im: testInstanceManager{dsInfo: &datasourceInfo{HTTPClient: http.DefaultClient, URL: "http://127.0.0.1:1"}},
+		}
+
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+	})
+}
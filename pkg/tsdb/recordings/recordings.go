@@ -0,0 +1,34 @@
+// Package recordings holds the recorded query responses shared by the query recording API and
+// the TestData replay scenario, without either depending on the other.
+package recordings
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/apimachinery/identity"
+)
+
+var ErrNotFound = errors.New("recording not found")
+
+// Recording is the captured response of a data source query.
+type Recording struct {
+	Name           string    `json:"name"`
+	DatasourceUID  string    `json:"datasourceUid,omitempty"`
+	DatasourceType string    `json:"datasourceType,omitempty"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	Recorded       time.Time `json:"recorded"`
+
+	Response *backend.QueryDataResponse `json:"response"`
+}
+
+// Getter loads recordings for the replay scenario.
+type Getter interface {
+	// GetRecording returns the recording with the name in the organization of the user. It
+	// returns ErrNotFound if there is no such recording or the user can't query its data source.
+	GetRecording(ctx context.Context, user identity.Requester, name string) (*Recording, error)
+}
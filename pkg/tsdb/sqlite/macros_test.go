@@ -0,0 +1,89 @@
+package sqlite
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	engine := newSqliteMacroEngine()
+	query := &backend.DataQuery{JSON: []byte("{}")}
+
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+
+	t.Run("interpolate __time function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__time(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, "select CAST(strftime('%s', time_column) AS INTEGER) AS \"time\"", sql)
+	})
+
+	t.Run("interpolate __timeFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__timeFilter(time_column)")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("WHERE datetime(time_column) BETWEEN datetime(%d, 'unixepoch') AND datetime(%d, 'unixepoch')", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __timeFrom and __timeTo functions", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "select $__timeFrom(), $__timeTo()")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("select datetime(%d, 'unixepoch'), datetime(%d, 'unixepoch')", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __timeGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column , '5m')")
+		require.NoError(t, err)
+		sql2, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "GROUP BY CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300", sql)
+		require.Equal(t, sql+" AS \"time\"", sql2)
+	})
+
+	t.Run("interpolate __timeGroup function with fill", func(t *testing.T) {
+		fillQuery := &backend.DataQuery{JSON: []byte("{}")}
+		_, err := engine.Interpolate(fillQuery, timeRange, "GROUP BY $__timeGroup(time_column,'5m', NULL)")
+		require.NoError(t, err)
+
+		require.JSONEq(t, `{"fill":true,"fillInterval":300,"fillMode":"null"}`, string(fillQuery.JSON))
+	})
+
+	t.Run("interpolate __timeGroup function with a sub-second interval", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeGroup(time_column,'100ms')")
+		require.Error(t, err)
+	})
+
+	t.Run("interpolate __unixEpochFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__unixEpochFilter(time)")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("WHERE time >= %d AND time <= %d", from.Unix(), to.Unix()), sql)
+	})
+
+	t.Run("interpolate __unixEpochNanoFilter function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "WHERE $__unixEpochNanoFilter(time)")
+		require.NoError(t, err)
+
+		require.Equal(t, fmt.Sprintf("WHERE time >= %d AND time <= %d", from.UnixNano(), to.UnixNano()), sql)
+	})
+
+	t.Run("interpolate __unixEpochGroup function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "SELECT $__unixEpochGroupAlias(time_column,'5m')")
+		require.NoError(t, err)
+
+		require.Equal(t, "SELECT CAST(time_column AS INTEGER) / 300 * 300 AS \"time\"", sql)
+	})
+
+	t.Run("fails on unknown macros", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "SELECT $__unknown(time_column)")
+		require.EqualError(t, err, "unknown macro __unknown")
+	})
+}
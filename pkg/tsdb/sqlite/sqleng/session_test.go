@@ -0,0 +1,90 @@
+package sqleng
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+// endlessQuery counts the rows of an endless recursive query, it runs until it is interrupted.
+const endlessQuery = `WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT count(*) FROM c`
+
+type fakeSessionHandler struct {
+	txOptions *sql.TxOptions
+}
+
+func (h *fakeSessionHandler) TxOptions(_ JsonData) *sql.TxOptions {
+	return h.txOptions
+}
+
+func (h *fakeSessionHandler) Prepare(_ context.Context, _ *sql.Tx, _ JsonData) (func() error, error) {
+	return nil, nil
+}
+
+func newSessionTestHandler(t *testing.T, jsonData JsonData, sessionHandler SessionHandler) *DataSourceHandler {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	_, err = db.Exec(`CREATE TABLE metrics (value REAL)`)
+	require.NoError(t, err)
+
+	return &DataSourceHandler{
+		db:             db,
+		dsInfo:         DataSourceInfo{JsonData: jsonData},
+		sessionHandler: sessionHandler,
+		log:            backend.NewLoggerWith("logger", "test"),
+	}
+}
+
+func countRows(t *testing.T, handler *DataSourceHandler) int {
+	t.Helper()
+	var count int
+	require.NoError(t, handler.db.QueryRow(`SELECT count(*) FROM metrics`).Scan(&count))
+	return count
+}
+
+func runSessionQuery(ctx context.Context, handler *DataSourceHandler, query string) error {
+	session, err := handler.query(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer session.close(handler.log)
+	for session.rows.Next() {
+	}
+	return session.err(session.rows.Err())
+}
+
+// TestQuerySession runs sessions against a database, the handling of transactions and stop
+// functions is covered with mocks by the tests of the MySQL sqleng package.
+func TestQuerySession(t *testing.T) {
+	t.Run("changes of read-only data sources are rolled back", func(t *testing.T) {
+		handler := newSessionTestHandler(t, JsonData{ReadOnly: true}, &fakeSessionHandler{txOptions: &sql.TxOptions{}})
+
+		require.NoError(t, runSessionQuery(context.Background(), handler, `INSERT INTO metrics VALUES (1)`))
+		require.Equal(t, 0, countRows(t, handler))
+	})
+
+	t.Run("changes of other data sources are committed", func(t *testing.T) {
+		handler := newSessionTestHandler(t, JsonData{}, &fakeSessionHandler{txOptions: &sql.TxOptions{}})
+
+		require.NoError(t, runSessionQuery(context.Background(), handler, `INSERT INTO metrics VALUES (1)`))
+		require.Equal(t, 1, countRows(t, handler))
+	})
+
+	t.Run("queries are interrupted after the query timeout", func(t *testing.T) {
+		handler := newSessionTestHandler(t, JsonData{QueryTimeout: 1}, nil)
+
+		start := time.Now()
+		err := runSessionQuery(context.Background(), handler, endlessQuery)
+		require.EqualError(t, err, "query exceeded the timeout of 1s: context deadline exceeded")
+		require.Less(t, time.Since(start), 10*time.Second)
+	})
+}
@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func createTestDatabase(t *testing.T, path string) {
+	t.Helper()
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.Exec(`CREATE TABLE metrics (ts TEXT, host TEXT, value REAL)`)
+	require.NoError(t, err)
+	for _, row := range []struct {
+		ts    string
+		host  string
+		value float64
+	}{
+		{"2024-01-01 10:00:10", "edge-1", 1},
+		{"2024-01-01 10:00:40", "edge-1", 3},
+		{"2024-01-01 10:01:10", "edge-1", 4},
+		{"2024-01-01 10:00:20", "edge-2", 10},
+		{"2024-01-01 10:01:20", "edge-2", 20},
+		{"2024-01-01 11:00:00", "edge-2", 30},
+	} {
+		_, err = db.Exec(`INSERT INTO metrics VALUES (?, ?, ?)`, row.ts, row.host, row.value)
+		require.NoError(t, err)
+	}
+}
+
+func newTestService(t *testing.T, rowLimit int64) (*Service, string) {
+	t.Helper()
+	dir := t.TempDir()
+	createTestDatabase(t, filepath.Join(dir, "metrics.db"))
+
+	cfg := setting.NewCfg()
+	cfg.SqlDatasourceSQLiteAllowedPaths = []string{dir}
+	cfg.SqlDatasourceMaxOpenConnsDefault = 2
+	cfg.DataProxyRowLimit = rowLimit
+	cfg.UserFacingDefaultError = "query failed"
+	return ProvideService(cfg), dir
+}
+
+func pluginContext(database string) backend.PluginContext {
+	return backend.PluginContext{
+		DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{
+			ID:       1,
+			UID:      "sqlite",
+			JSONData: []byte(`{"database":"` + database + `"}`),
+		},
+	}
+}
+
+func runQuery(t *testing.T, s *Service, database string, format string, rawSQL string) backend.DataResponse {
+	t.Helper()
+	model, err := json.Marshal(map[string]any{"rawSql": rawSQL, "format": format})
+	require.NoError(t, err)
+
+	resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+		PluginContext: pluginContext(database),
+		Queries: []backend.DataQuery{{
+			RefID: "A",
+			JSON:  model,
+			TimeRange: backend.TimeRange{
+				From: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+				To:   time.Date(2024, 1, 1, 10, 5, 0, 0, time.UTC),
+			},
+		}},
+	})
+	require.NoError(t, err)
+	return resp.Responses["A"]
+}
+
+func TestSQLite(t *testing.T) {
+	s, _ := newTestService(t, 1000)
+
+	t.Run("time series query with macros", func(t *testing.T) {
+		res := runQuery(t, s, "metrics.db", "time_series", `
+			SELECT $__timeGroupAlias(ts, '1m'), host AS metric, avg(value) AS value
+			FROM metrics
+			WHERE $__timeFilter(ts)
+			GROUP BY 1, 2
+			ORDER BY 1`)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Len(t, frame.Fields, 3)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, data.TimeSeriesTimeFieldName, frame.Fields[0].Name)
+		require.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), frame.Fields[0].At(0).(time.Time).UTC())
+		require.Equal(t, "edge-1", frame.Fields[1].Name)
+		require.Equal(t, 2.0, *frame.Fields[1].At(0).(*float64))
+		require.Equal(t, 4.0, *frame.Fields[1].At(1).(*float64))
+		require.Equal(t, "edge-2", frame.Fields[2].Name)
+		require.Equal(t, 20.0, *frame.Fields[2].At(1).(*float64))
+	})
+
+	t.Run("table query", func(t *testing.T) {
+		res := runQuery(t, s, "metrics.db", "table", `SELECT host, count(*) AS count FROM metrics GROUP BY host ORDER BY host`)
+		require.NoError(t, res.Error)
+
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, "edge-1", *frame.Fields[0].At(0).(*string))
+		require.Equal(t, 3.0, *frame.Fields[1].At(1).(*float64))
+	})
+
+	t.Run("does not allow to modify the database", func(t *testing.T) {
+		res := runQuery(t, s, "metrics.db", "table", `INSERT INTO metrics VALUES ('2024-01-01 10:00:00', 'edge-3', 1)`)
+		require.ErrorContains(t, res.Error, "readonly database")
+
+		res = runQuery(t, s, "metrics.db", "table", `PRAGMA query_only = false; DELETE FROM metrics`)
+		require.ErrorContains(t, res.Error, "readonly database")
+
+		res = runQuery(t, s, "metrics.db", "table", `SELECT count(*) AS count FROM metrics`)
+		require.NoError(t, res.Error)
+		require.Equal(t, 6.0, *res.Frames[0].Fields[0].At(0).(*float64))
+	})
+
+	t.Run("does not allow to attach databases", func(t *testing.T) {
+		res := runQuery(t, s, "metrics.db", "table", `ATTACH DATABASE '/tmp/other.db' AS other`)
+		require.ErrorContains(t, res.Error, "too many attached databases")
+	})
+
+	t.Run("health check", func(t *testing.T) {
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext("metrics.db")})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+
+		ctx := pluginContext("/etc/passwd")
+		ctx.DataSourceInstanceSettings.ID = 2
+		res, err = s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: ctx})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, errPathNotAllowed.Error(), res.Message)
+	})
+}
+
+func TestSQLiteRowLimit(t *testing.T) {
+	s, _ := newTestService(t, 2)
+
+	res := runQuery(t, s, "metrics.db", "table", `SELECT * FROM metrics`)
+	require.NoError(t, res.Error)
+	require.Equal(t, 2, res.Frames[0].Rows())
+}
+
+func TestResolveDatabasePath(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "allowed")
+	require.NoError(t, os.MkdirAll(filepath.Join(allowed, "nested"), 0o750))
+	for _, name := range []string{filepath.Join(allowed, "a.db"), filepath.Join(allowed, "nested", "b.db"), filepath.Join(dir, "outside.db")} {
+		require.NoError(t, os.WriteFile(name, nil, 0o600))
+	}
+	require.NoError(t, os.Symlink(filepath.Join(dir, "outside.db"), filepath.Join(allowed, "link.db")))
+
+	allowedPaths := []string{allowed}
+	resolvedAllowed, err := filepath.EvalSymlinks(allowed)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		database     string
+		allowedPaths []string
+		expected     string
+		err          error
+	}{
+		{name: "relative path", database: "a.db", allowedPaths: allowedPaths, expected: filepath.Join(resolvedAllowed, "a.db")},
+		{name: "absolute path", database: filepath.Join(allowed, "nested", "b.db"), allowedPaths: allowedPaths, expected: filepath.Join(resolvedAllowed, "nested", "b.db")},
+		{name: "outside of allowed directories", database: filepath.Join(dir, "outside.db"), allowedPaths: allowedPaths, err: errPathNotAllowed},
+		{name: "parent directory", database: "../outside.db", allowedPaths: allowedPaths, err: errPathNotAllowed},
+		{name: "symbolic link to outside of allowed directories", database: "link.db", allowedPaths: allowedPaths, err: errPathNotAllowed},
+		{name: "allowed directory", database: ".", allowedPaths: allowedPaths, err: errPathNotAllowed},
+		{name: "missing file", database: "missing.db", allowedPaths: allowedPaths, err: errDatabaseNotFound},
+		{name: "missing database", database: " ", allowedPaths: allowedPaths, err: errMissingDatabase},
+		{name: "no allowed directories", database: "a.db", err: errNoAllowedPaths},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			path, err := resolveDatabasePath(tc.database, tc.allowedPaths)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, path)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package tempo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/tsdb/instrumentation"
+)
+
+var (
+	_ backend.CallResourceHandler = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+)
+
+// resourcePaths are the Tempo API endpoints which can be called through the
+// data source, used for tag name and tag value autocompletion.
+var resourcePaths = regexp.MustCompile(`^api/(v2/)?search/(tags|tag/([^/]+)/values)$`)
+
+// CallResource proxies tag name and tag value lookups to Tempo.
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	ctxLogger := s.logger.FromContext(ctx)
+	match := resourcePaths.FindStringSubmatch(req.Path)
+	if match == nil || match[3] == "." || match[3] == ".." {
+		ctxLogger.Error("Invalid resource path", "path", req.Path, "function", logEntrypoint())
+		return fmt.Errorf("invalid resource URL: %s", req.Path)
+	}
+	if req.Method != http.MethodGet {
+		ctxLogger.Error("Invalid resource method", "method", req.Method, "path", req.Path, "function", logEntrypoint())
+		return fmt.Errorf("invalid resource method: %s", req.Method)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		ctxLogger.Error("Failed to get data source info", "error", err, "function", logEntrypoint())
+		return err
+	}
+
+	res, body, err := s.doResourceRequest(ctx, dsInfo, req.Path, resourceQuery(req.URL))
+	if err != nil {
+		requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCallResource, err, 0)
+		ctxLogger.Error("Failed resource call from tempo", "error", err, "path", req.Path, "function", logEntrypoint())
+		return err
+	}
+	requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCallResource, nil, res.StatusCode)
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return sender.Send(&backend.CallResourceResponse{
+		Status:  res.StatusCode,
+		Headers: map[string][]string{"content-type": {contentType}},
+		Body:    body,
+	})
+}
+
+// CheckHealth checks that Tempo answers its echo endpoint.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		ctxLogger.Error("Failed to get data source info", "error", err, "function", logEntrypoint())
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusUnknown,
+			Message: "Failed to get data source info",
+		}, err
+	}
+
+	res, body, err := s.doResourceRequest(ctx, dsInfo, "api/echo", url.Values{})
+	if err != nil {
+		requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCheckHealth, err, 0)
+		ctxLogger.Error("Tempo health check failed", "error", err, "function", logEntrypoint())
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: "Unable to connect with Tempo. Please check the server logs for more details.",
+		}, nil
+	}
+	requestMetrics.UpdateRequestMetrics(instrumentation.EndpointCheckHealth, nil, res.StatusCode)
+
+	if res.StatusCode/100 != 2 {
+		ctxLogger.Error("Tempo health check failed", "statusCode", res.StatusCode, "body", string(body), "function", logEntrypoint())
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: fmt.Sprintf("Tempo returned an error: %s", res.Status),
+		}, nil
+	}
+
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusOk,
+		Message: "Data source successfully connected.",
+	}, nil
+}
+
+func (s *Service) doResourceRequest(ctx context.Context, dsInfo *Datasource, apiPath string, query url.Values) (*http.Response, []byte, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	u, err := url.Parse(dsInfo.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse data source URL: %w", err)
+	}
+	u.Path = path.Join(u.Path, apiPath)
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	res, err := dsInfo.HTTPClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			ctxLogger.Warn("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctxLogger.Debug("Response received from tempo", "path", apiPath, "statusCode", res.StatusCode, "duration", time.Since(start), "function", logEntrypoint())
+	return res, body, nil
+}
+
+// resourceQuery returns the query parameters of a resource request URL.
+func resourceQuery(resourceURL string) url.Values {
+	_, rawQuery, _ := strings.Cut(resourceURL, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return url.Values{}
+	}
+	return query
+}
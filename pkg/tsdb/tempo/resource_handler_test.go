@@ -0,0 +1,107 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/require"
+)
+
+type testInstanceManager struct {
+	dsInfo *Datasource
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.dsInfo, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		logger: backend.NewLoggerWith("logger", "tsdb.tempo"),
+		im:     testInstanceManager{dsInfo: &Datasource{HTTPClient: server.Client(), URL: server.URL}},
+	}
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("proxies tag lookups to tempo", func(t *testing.T) {
+		var requests []string
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.URL.String())
+			_, _ = w.Write([]byte(`{"tagNames":["service.name"]}`))
+		})
+
+		for _, url := range []string{
+			"api/search/tags",
+			"api/v2/search/tags?scope=resource",
+			"api/v2/search/tag/resource.service.name/values?q=%7B%7D",
+		} {
+			sender := &fakeSender{}
+			path, _, _ := strings.Cut(url, "?")
+			err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: path, URL: url}, sender)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, sender.resp.Status)
+		}
+
+		require.Equal(t, []string{
+			"/api/search/tags",
+			"/api/v2/search/tags?scope=resource",
+			"/api/v2/search/tag/resource.service.name/values?q=%7B%7D",
+		}, requests)
+	})
+
+	t.Run("rejects paths which are not tag lookups", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatalf("unexpected request %s", r.URL)
+		})
+
+		for _, p := range []string{"api/traces/1234", "api/search", "api/search/tag/../values", "api/echo"} {
+			err := service.CallResource(context.Background(), &backend.CallResourceRequest{Method: http.MethodGet, Path: p, URL: p}, nil)
+			require.Error(t, err, p)
+		}
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("healthy when tempo answers the echo endpoint", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/echo", r.URL.Path)
+			_, _ = w.Write([]byte("echo"))
+		})
+
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("unhealthy when tempo returns an error", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		})
+
+		res, err := service.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Equal(t, "Tempo returned an error: 502 Bad Gateway", res.Message)
+	})
+}
@@ -14,8 +14,10 @@ type Datasource struct {
 }
 
 var (
-	_ backend.QueryDataHandler = (*Datasource)(nil)
-	_ backend.StreamHandler    = (*Datasource)(nil)
+	_ backend.QueryDataHandler    = (*Datasource)(nil)
+	_ backend.StreamHandler       = (*Datasource)(nil)
+	_ backend.CallResourceHandler = (*Datasource)(nil)
+	_ backend.CheckHealthHandler  = (*Datasource)(nil)
 )
 
 func NewDatasource(c context.Context, b backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
@@ -28,6 +30,14 @@ func (d *Datasource) QueryData(ctx context.Context, req *backend.QueryDataReques
 	return d.Service.QueryData(ctx, req)
 }
 
+func (d *Datasource) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	return d.Service.CallResource(ctx, req, sender)
+}
+
+func (d *Datasource) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	return d.Service.CheckHealth(ctx, req)
+}
+
 func (d *Datasource) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
 	return d.Service.SubscribeStream(ctx, req)
 }
@@ -0,0 +1,515 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+const (
+	defaultSearchLimit = 20
+	defaultSpss        = 3
+	// maxMetricsSamples limits the number of samples of a series returned by a
+	// TraceQL metrics query, the step is increased for longer time ranges.
+	maxMetricsSamples = 11000
+	minMetricsStep    = time.Second
+)
+
+// traceIDPattern matches 64 and 128-bit trace IDs.
+var traceIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{16}([0-9a-fA-F]{16})?$`)
+
+// metricsQueryPattern matches TraceQL queries with a metrics function, for
+// example { resource.service.name = "api" } | rate() by (span.http.route).
+var metricsQueryPattern = regexp.MustCompile(`\|\s*(rate|count_over_time|min_over_time|max_over_time|avg_over_time|sum_over_time|quantile_over_time|histogram_over_time)\s*\(`)
+
+// runTraceQL runs a TraceQL search query, returning a table of traces or
+// spans, or a TraceQL metrics query, returning time series.
+func (s *Service) runTraceQL(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	result := &backend.DataResponse{}
+
+	model := &dataquery.TempoQuery{}
+	if err := json.Unmarshal(query.JSON, model); err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	var traceQL string
+	if model.Query != nil {
+		traceQL = strings.TrimSpace(*model.Query)
+	}
+	switch {
+	case query.QueryType == string(dataquery.TempoQueryTypeTraceqlSearch):
+		// The search query builder sends its filters, the query is only set
+		// when the user edited the generated query.
+		if traceQL == "" {
+			traceQL = traceQLFromFilters(model.Filters)
+		}
+	case traceQL == "":
+		result.Error = fmt.Errorf("traceql query is required")
+		result.ErrorSource = backend.ErrorSourceDownstream
+		return result, nil
+	case traceIDPattern.MatchString(traceQL):
+		// The TraceQL editor accepts trace IDs as well.
+		return s.getTrace(ctx, pCtx, query)
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	if metricsQueryPattern.MatchString(traceQL) {
+		return s.runTraceQLMetrics(ctx, dsInfo, model, query, traceQL)
+	}
+	return s.runTraceQLSearch(ctx, pCtx, dsInfo, model, query, traceQL)
+}
+
+func (s *Service) runTraceQLSearch(ctx context.Context, pCtx backend.PluginContext, dsInfo *Datasource, model *dataquery.TempoQuery, query backend.DataQuery, traceQL string) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runTraceQLSearch", trace.WithAttributes(
+		attribute.String("query", traceQL),
+	))
+	defer span.End()
+
+	limit := int64(defaultSearchLimit)
+	if model.Limit != nil && *model.Limit > 0 {
+		limit = *model.Limit
+	}
+	spss := int64(defaultSpss)
+	if model.Spss != nil && *model.Spss > 0 {
+		spss = *model.Spss
+	}
+	params := url.Values{
+		"q":     []string{traceQL},
+		"start": []string{strconv.FormatInt(query.TimeRange.From.Unix(), 10)},
+		"end":   []string{strconv.FormatInt(query.TimeRange.To.Unix(), 10)},
+		"limit": []string{strconv.FormatInt(limit, 10)},
+		"spss":  []string{strconv.FormatInt(spss, 10)},
+	}
+
+	body, errResponse := s.doTraceQLRequest(ctx, dsInfo, "api/search", params)
+	if errResponse != nil {
+		span.RecordError(errResponse.Error)
+		span.SetStatus(codes.Error, errResponse.Error.Error())
+		return errResponse, nil
+	}
+
+	searchResponse := &traceQLSearchResponse{}
+	if err := json.Unmarshal(body, searchResponse); err != nil {
+		ctxLogger.Error("Failed to unmarshal Tempo search response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to unmarshal Tempo search response: %w", err)
+	}
+
+	var frame *data.Frame
+	if model.TableType != nil && *model.TableType == dataquery.SearchTableTypeSpans {
+		frame = spansToFrame(searchResponse.Traces)
+	} else {
+		frame = tracesToFrame(searchResponse.Traces, pCtx.DataSourceInstanceSettings)
+	}
+	frame.RefID = query.RefID
+	frame.Meta = &data.FrameMeta{
+		PreferredVisualization: data.VisTypeTable,
+		ExecutedQueryString:    traceQL,
+	}
+	return &backend.DataResponse{Frames: data.Frames{frame}}, nil
+}
+
+func (s *Service) runTraceQLMetrics(ctx context.Context, dsInfo *Datasource, model *dataquery.TempoQuery, query backend.DataQuery, traceQL string) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.runTraceQLMetrics", trace.WithAttributes(
+		attribute.String("query", traceQL),
+	))
+	defer span.End()
+
+	step, err := metricsStep(model, query)
+	if err != nil {
+		return &backend.DataResponse{Error: err, ErrorSource: backend.ErrorSourceDownstream}, nil
+	}
+	start, end := alignTimeRange(query.TimeRange, step)
+	params := url.Values{
+		"q":     []string{traceQL},
+		"start": []string{strconv.FormatInt(start.UnixNano(), 10)},
+		"end":   []string{strconv.FormatInt(end.UnixNano(), 10)},
+		"step":  []string{step.String()},
+	}
+
+	body, errResponse := s.doTraceQLRequest(ctx, dsInfo, "api/metrics/query_range", params)
+	if errResponse != nil {
+		span.RecordError(errResponse.Error)
+		span.SetStatus(codes.Error, errResponse.Error.Error())
+		return errResponse, nil
+	}
+
+	metricsResponse := &traceQLMetricsResponse{}
+	if err := json.Unmarshal(body, metricsResponse); err != nil {
+		ctxLogger.Error("Failed to unmarshal Tempo metrics response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to unmarshal Tempo metrics response: %w", err)
+	}
+
+	frames := seriesToFrames(metricsResponse.Series, query.RefID, traceQL)
+	return &backend.DataResponse{Frames: frames}, nil
+}
+
+// doTraceQLRequest returns the body of a successful response or a data
+// response with the error returned by Tempo.
+func (s *Service) doTraceQLRequest(ctx context.Context, dsInfo *Datasource, apiPath string, params url.Values) ([]byte, *backend.DataResponse) {
+	ctxLogger := s.logger.FromContext(ctx)
+	res, body, err := s.doResourceRequest(ctx, dsInfo, apiPath, params)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "path", apiPath, "function", logEntrypoint())
+		return nil, &backend.DataResponse{
+			Error:       fmt.Errorf("failed to query tempo: %w", err),
+			ErrorSource: backend.ErrorSourceDownstream,
+		}
+	}
+	if res.StatusCode != http.StatusOK {
+		ctxLogger.Error("Failed to run TraceQL query", "statusCode", res.StatusCode, "path", apiPath, "function", logEntrypoint())
+		return nil, &backend.DataResponse{
+			Error:       fmt.Errorf("failed to run TraceQL query, status: %s, body: %s", res.Status, strings.TrimSpace(string(body))),
+			Status:      backend.Status(res.StatusCode),
+			ErrorSource: backend.ErrorSourceFromHTTPStatus(res.StatusCode),
+		}
+	}
+	return body, nil
+}
+
+// metricsStep returns the step of the query, or derives it from the interval
+// and the max data points of the query so that long time ranges do not
+// return more than maxMetricsSamples samples per series.
+func metricsStep(model *dataquery.TempoQuery, query backend.DataQuery) (time.Duration, error) {
+	if model.Step != nil && *model.Step != "" {
+		step, err := gtime.ParseDuration(*model.Step)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q: %w", *model.Step, err)
+		}
+		if step <= 0 {
+			return 0, fmt.Errorf("invalid step %q: must be positive", *model.Step)
+		}
+		return step, nil
+	}
+
+	timeRange := query.TimeRange.Duration()
+	step := query.Interval
+	if query.MaxDataPoints > 0 {
+		if minStep := timeRange / time.Duration(query.MaxDataPoints); step < minStep {
+			step = minStep
+		}
+	}
+	if safeStep := timeRange / maxMetricsSamples; step < safeStep {
+		step = safeStep
+	}
+	if step < minMetricsStep {
+		step = minMetricsStep
+	}
+	return step.Truncate(time.Millisecond), nil
+}
+
+// alignTimeRange aligns the start of the time range to the step so that
+// consecutive queries return samples with the same timestamps.
+func alignTimeRange(timeRange backend.TimeRange, step time.Duration) (time.Time, time.Time) {
+	start := timeRange.From.Truncate(step)
+	return start, timeRange.To
+}
+
+// traceQLFromFilters generates a TraceQL query from the filters of the
+// search query builder.
+func traceQLFromFilters(filters []dataquery.TraceqlFilter) string {
+	var conditions []string
+	for _, filter := range filters {
+		if filter.Tag == nil || *filter.Tag == "" || filter.Operator == nil || *filter.Operator == "" || filter.Value == nil {
+			continue
+		}
+		value, ok := traceQLFilterValue(filter)
+		if !ok {
+			continue
+		}
+		conditions = append(conditions, traceQLFilterTag(filter)+*filter.Operator+value)
+	}
+	if len(conditions) == 0 {
+		return "{}"
+	}
+	return "{" + strings.Join(conditions, " && ") + "}"
+}
+
+func traceQLFilterTag(filter dataquery.TraceqlFilter) string {
+	tag := strings.TrimPrefix(*filter.Tag, ".")
+	if filter.Scope == nil {
+		return "." + tag
+	}
+	switch *filter.Scope {
+	case dataquery.TraceqlSearchScopeIntrinsic:
+		return tag
+	case dataquery.TraceqlSearchScopeResource, dataquery.TraceqlSearchScopeSpan:
+		return string(*filter.Scope) + "." + tag
+	default:
+		return "." + tag
+	}
+}
+
+func traceQLFilterValue(filter dataquery.TraceqlFilter) (string, bool) {
+	var values []string
+	switch v := (*filter.Value).(type) {
+	case string:
+		values = []string{v}
+	case []any:
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+	default:
+		values = []string{fmt.Sprint(v)}
+	}
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		return "", false
+	}
+	value := strings.Join(values, "|")
+	if filter.ValueType == nil || *filter.ValueType == "string" {
+		return strconv.Quote(value), true
+	}
+	return value, true
+}
+
+type traceQLSearchResponse struct {
+	Traces []traceQLTrace `json:"traces"`
+}
+
+type traceQLTrace struct {
+	TraceID           string           `json:"traceID"`
+	RootServiceName   string           `json:"rootServiceName"`
+	RootTraceName     string           `json:"rootTraceName"`
+	StartTimeUnixNano jsonInt          `json:"startTimeUnixNano"`
+	DurationMs        *float64         `json:"durationMs"`
+	SpanSet           *traceQLSpanSet  `json:"spanSet"`
+	SpanSets          []traceQLSpanSet `json:"spanSets"`
+}
+
+type traceQLSpanSet struct {
+	Spans   []traceQLSpan `json:"spans"`
+	Matched int64         `json:"matched"`
+}
+
+type traceQLSpan struct {
+	SpanID            string            `json:"spanID"`
+	Name              string            `json:"name"`
+	StartTimeUnixNano jsonInt           `json:"startTimeUnixNano"`
+	DurationNanos     jsonInt           `json:"durationNanos"`
+	Attributes        []traceQLKeyValue `json:"attributes"`
+}
+
+type traceQLKeyValue struct {
+	Key   string       `json:"key"`
+	Value traceQLValue `json:"value"`
+}
+
+type traceQLValue struct {
+	StringValue *string  `json:"stringValue"`
+	IntValue    *jsonInt `json:"intValue"`
+	DoubleValue *float64 `json:"doubleValue"`
+	BoolValue   *bool    `json:"boolValue"`
+}
+
+func (v traceQLValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		return strconv.FormatInt(int64(*v.IntValue), 10)
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	default:
+		return ""
+	}
+}
+
+type traceQLMetricsResponse struct {
+	Series []traceQLSeries `json:"series"`
+}
+
+type traceQLSeries struct {
+	Labels     []traceQLKeyValue `json:"labels"`
+	Samples    []traceQLSample   `json:"samples"`
+	PromLabels string            `json:"promLabels"`
+}
+
+type traceQLSample struct {
+	TimestampMs jsonInt `json:"timestampMs"`
+	Value       float64 `json:"value"`
+}
+
+// jsonInt is a 64-bit integer which Tempo encodes as a JSON string.
+type jsonInt int64
+
+func (i *jsonInt) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(string(b), `"`)
+	if s == "" || s == "null" {
+		*i = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = jsonInt(n)
+	return nil
+}
+
+func tracesToFrame(traces []traceQLTrace, settings *backend.DataSourceInstanceSettings) *data.Frame {
+	traceIDs := make([]string, 0, len(traces))
+	startTimes := make([]time.Time, 0, len(traces))
+	services := make([]string, 0, len(traces))
+	names := make([]string, 0, len(traces))
+	durations := make([]*float64, 0, len(traces))
+	for _, t := range traces {
+		traceIDs = append(traceIDs, t.TraceID)
+		startTimes = append(startTimes, time.Unix(0, int64(t.StartTimeUnixNano)).UTC())
+		services = append(services, t.RootServiceName)
+		names = append(names, t.RootTraceName)
+		durations = append(durations, t.DurationMs)
+	}
+
+	traceIDConfig := &data.FieldConfig{DisplayNameFromDS: "Trace ID"}
+	if settings != nil {
+		traceIDConfig.Links = []data.DataLink{{
+			Title: "Trace: ${__value.raw}",
+			Internal: &data.InternalDataLink{
+				DatasourceUID:  settings.UID,
+				DatasourceName: settings.Name,
+				Query: map[string]any{
+					"query":     "${__value.raw}",
+					"queryType": string(dataquery.TempoQueryTypeTraceql),
+				},
+			},
+		}}
+	}
+
+	return data.NewFrame("Traces",
+		data.NewField("traceID", nil, traceIDs).SetConfig(traceIDConfig),
+		data.NewField("startTime", nil, startTimes).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("traceService", nil, services).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Service"}),
+		data.NewField("traceName", nil, names).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Name"}),
+		data.NewField("traceDuration", nil, durations).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ms"}),
+	)
+}
+
+// spansToFrame returns a row for every span of the span sets of the traces,
+// with a column for every attribute returned by the query.
+func spansToFrame(traces []traceQLTrace) *data.Frame {
+	traceIDs := []string{}
+	services := []string{}
+	traceNames := []string{}
+	spanIDs := []string{}
+	startTimes := []time.Time{}
+	names := []string{}
+	durations := []float64{}
+	var rowAttributes []map[string]string
+	keys := map[string]bool{}
+	for _, t := range traces {
+		spanSets := t.SpanSets
+		if len(spanSets) == 0 && t.SpanSet != nil {
+			spanSets = []traceQLSpanSet{*t.SpanSet}
+		}
+		for _, spanSet := range spanSets {
+			for _, span := range spanSet.Spans {
+				traceIDs = append(traceIDs, t.TraceID)
+				services = append(services, t.RootServiceName)
+				traceNames = append(traceNames, t.RootTraceName)
+				spanIDs = append(spanIDs, span.SpanID)
+				startTimes = append(startTimes, time.Unix(0, int64(span.StartTimeUnixNano)).UTC())
+				names = append(names, span.Name)
+				durations = append(durations, float64(span.DurationNanos))
+				attributes := make(map[string]string, len(span.Attributes))
+				for _, kv := range span.Attributes {
+					attributes[kv.Key] = kv.Value.String()
+					keys[kv.Key] = true
+				}
+				rowAttributes = append(rowAttributes, attributes)
+			}
+		}
+	}
+
+	frame := data.NewFrame("Spans",
+		data.NewField("traceID", nil, traceIDs).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace ID"}),
+		data.NewField("traceService", nil, services).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace service"}),
+		data.NewField("traceName", nil, traceNames).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Trace name"}),
+		data.NewField("spanID", nil, spanIDs).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Span ID"}),
+		data.NewField("time", nil, startTimes).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Start time"}),
+		data.NewField("name", nil, names).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Name"}),
+		data.NewField("duration", nil, durations).SetConfig(&data.FieldConfig{DisplayNameFromDS: "Duration", Unit: "ns"}),
+	)
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+	for _, key := range sortedKeys {
+		values := make([]*string, len(rowAttributes))
+		for i, attributes := range rowAttributes {
+			if value, ok := attributes[key]; ok {
+				values[i] = &value
+			}
+		}
+		frame.Fields = append(frame.Fields, data.NewField(key, nil, values))
+	}
+	return frame
+}
+
+// seriesToFrames returns a time series frame for every series of a TraceQL
+// metrics response.
+func seriesToFrames(series []traceQLSeries, refID string, traceQL string) data.Frames {
+	frames := make(data.Frames, 0, len(series))
+	for _, s := range series {
+		samples := append([]traceQLSample(nil), s.Samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i].TimestampMs < samples[j].TimestampMs })
+
+		times := make([]time.Time, 0, len(samples))
+		values := make([]float64, 0, len(samples))
+		for _, sample := range samples {
+			times = append(times, time.UnixMilli(int64(sample.TimestampMs)).UTC())
+			values = append(values, sample.Value)
+		}
+
+		labels := data.Labels{}
+		for _, kv := range s.Labels {
+			labels[kv.Key] = kv.Value.String()
+		}
+
+		valueField := data.NewField("value", labels, values)
+		if s.PromLabels != "" {
+			valueField.SetConfig(&data.FieldConfig{DisplayNameFromDS: s.PromLabels})
+		}
+		frame := data.NewFrame(refID, data.NewField("time", nil, times), valueField)
+		frame.RefID = refID
+		frame.Meta = &data.FrameMeta{
+			Type:                data.FrameTypeTimeSeriesMulti,
+			TypeVersion:         data.FrameTypeVersion{0, 1},
+			ExecutedQueryString: traceQL,
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
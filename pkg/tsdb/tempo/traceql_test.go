@@ -0,0 +1,248 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+)
+
+const searchResponse = `{
+	"traces": [
+		{
+			"traceID": "2f3e0cee77ae5dc9c17ade3689eb2e54",
+			"rootServiceName": "shop-backend",
+			"rootTraceName": "update-billing",
+			"startTimeUnixNano": "1684778327699392724",
+			"durationMs": 557,
+			"spanSets": [
+				{
+					"spans": [
+						{
+							"spanID": "563d623c76514f8e",
+							"name": "HTTP GET",
+							"startTimeUnixNano": "1684778327735077898",
+							"durationNanos": "446979497",
+							"attributes": [
+								{"key": "http.status_code", "value": {"intValue": "500"}}
+							]
+						},
+						{
+							"spanID": "9b6b8c2b1a3d4f5e",
+							"name": "db.query",
+							"startTimeUnixNano": "1684778327800000000",
+							"durationNanos": "1000",
+							"attributes": [
+								{"key": "db.system", "value": {"stringValue": "postgres"}}
+							]
+						}
+					],
+					"matched": 2
+				}
+			]
+		}
+	],
+	"metrics": {"inspectedBytes": "1000"}
+}`
+
+const metricsResponse = `{
+	"series": [
+		{
+			"labels": [{"key": "resource.service.name", "value": {"stringValue": "api"}}],
+			"promLabels": "{resource.service.name=\"api\"}",
+			"samples": [
+				{"timestampMs": "1700000060000", "value": 2},
+				{"timestampMs": "1700000000000", "value": 1.5}
+			]
+		}
+	]
+}`
+
+func traceQLQuery(t *testing.T, queryType dataquery.TempoQueryType, model dataquery.TempoQuery) backend.DataQuery {
+	t.Helper()
+	b, err := json.Marshal(model)
+	require.NoError(t, err)
+	return backend.DataQuery{
+		RefID:         "A",
+		QueryType:     string(queryType),
+		JSON:          b,
+		Interval:      15 * time.Second,
+		MaxDataPoints: 100,
+		TimeRange: backend.TimeRange{
+			From: time.Unix(1700000010, 0),
+			To:   time.Unix(1700003610, 0),
+		},
+	}
+}
+
+func TestTraceQLSearch(t *testing.T) {
+	var requests []*url.URL
+	service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL)
+		_, _ = w.Write([]byte(searchResponse))
+	})
+	pCtx := backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{UID: "tempo", Name: "Tempo"}}
+
+	t.Run("returns a table of traces", func(t *testing.T) {
+		query := `{ resource.service.name = "shop-backend" }`
+		res, err := service.query(context.Background(), pCtx, traceQLQuery(t, dataquery.TempoQueryTypeTraceql, dataquery.TempoQuery{Query: &query}))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, "A", frame.RefID)
+		require.Equal(t, data.VisType(data.VisTypeTable), frame.Meta.PreferredVisualization)
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "2f3e0cee77ae5dc9c17ade3689eb2e54", frame.Fields[0].At(0))
+		require.Equal(t, "tempo", frame.Fields[0].Config.Links[0].Internal.DatasourceUID)
+		require.Equal(t, time.Unix(0, 1684778327699392724).UTC(), frame.Fields[1].At(0))
+		require.Equal(t, "shop-backend", frame.Fields[2].At(0))
+		require.Equal(t, "update-billing", frame.Fields[3].At(0))
+		require.Equal(t, 557.0, *frame.Fields[4].At(0).(*float64))
+
+		req := requests[len(requests)-1]
+		require.Equal(t, "/api/search", req.Path)
+		require.Equal(t, query, req.Query().Get("q"))
+		require.Equal(t, "1700000010", req.Query().Get("start"))
+		require.Equal(t, "1700003610", req.Query().Get("end"))
+		require.Equal(t, "20", req.Query().Get("limit"))
+		require.Equal(t, "3", req.Query().Get("spss"))
+	})
+
+	t.Run("returns a table of spans", func(t *testing.T) {
+		query := `{ span.http.status_code >= 500 }`
+		tableType := dataquery.SearchTableTypeSpans
+		limit := int64(5)
+		res, err := service.query(context.Background(), pCtx, traceQLQuery(t, dataquery.TempoQueryTypeTraceql, dataquery.TempoQuery{Query: &query, TableType: &tableType, Limit: &limit}))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		frame := res.Frames[0]
+		require.Equal(t, 2, frame.Rows())
+		names := make([]string, 0, len(frame.Fields))
+		for _, field := range frame.Fields {
+			names = append(names, field.Name)
+		}
+		require.Equal(t, []string{"traceID", "traceService", "traceName", "spanID", "time", "name", "duration", "db.system", "http.status_code"}, names)
+		require.Equal(t, "563d623c76514f8e", frame.Fields[3].At(0))
+		require.Equal(t, 446979497.0, frame.Fields[6].At(0))
+		require.Nil(t, frame.Fields[7].At(0))
+		require.Equal(t, "postgres", *frame.Fields[7].At(1).(*string))
+		require.Equal(t, "500", *frame.Fields[8].At(0).(*string))
+		require.Equal(t, "5", requests[len(requests)-1].Query().Get("limit"))
+	})
+
+	t.Run("generates the query of the search builder from its filters", func(t *testing.T) {
+		op, tag, stringType := "=", "service.name", "string"
+		statusTag, intrinsic, statusValue := "status", dataquery.TraceqlSearchScopeIntrinsic, any("error")
+		durationTag, durationOp, durationType, durationValue := "duration", ">", "duration", any("100ms")
+		resource := dataquery.TraceqlSearchScopeResource
+		services := any([]any{"api", "web"})
+		regexOp := "=~"
+		filters := []dataquery.TraceqlFilter{
+			{Id: "service", Tag: &tag, Operator: &regexOp, Scope: &resource, Value: &services, ValueType: &stringType},
+			{Id: "status", Tag: &statusTag, Operator: &op, Scope: &intrinsic, Value: &statusValue, ValueType: &durationType},
+			{Id: "duration", Tag: &durationTag, Operator: &durationOp, Scope: &intrinsic, Value: &durationValue, ValueType: &durationType},
+			{Id: "empty", Tag: &tag, Operator: &op},
+		}
+		res, err := service.query(context.Background(), pCtx, traceQLQuery(t, dataquery.TempoQueryTypeTraceqlSearch, dataquery.TempoQuery{Filters: filters}))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+		require.Equal(t, `{resource.service.name=~"api|web" && status=error && duration>100ms}`, requests[len(requests)-1].Query().Get("q"))
+	})
+
+	t.Run("fails without a query", func(t *testing.T) {
+		res, err := service.query(context.Background(), pCtx, traceQLQuery(t, dataquery.TempoQueryTypeTraceql, dataquery.TempoQuery{}))
+		require.NoError(t, err)
+		require.EqualError(t, res.Error, "traceql query is required")
+	})
+}
+
+func TestTraceQLMetrics(t *testing.T) {
+	t.Run("returns time series", func(t *testing.T) {
+		var request *url.URL
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			request = r.URL
+			_, _ = w.Write([]byte(metricsResponse))
+		})
+
+		query := `{ } | rate() by (resource.service.name)`
+		res, err := service.query(context.Background(), backend.PluginContext{}, traceQLQuery(t, dataquery.TempoQueryTypeTraceql, dataquery.TempoQuery{Query: &query}))
+		require.NoError(t, err)
+		require.NoError(t, res.Error)
+
+		require.Equal(t, "/api/metrics/query_range", request.Path)
+		require.Equal(t, query, request.Query().Get("q"))
+		// The step is derived from the 1h range and the 100 max data points,
+		// the start of the range is aligned to the step.
+		require.Equal(t, "36s", request.Query().Get("step"))
+		require.Equal(t, "1699999992000000000", request.Query().Get("start"))
+		require.Equal(t, "1700003610000000000", request.Query().Get("end"))
+
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, frame.Meta.Type)
+		require.Equal(t, 2, frame.Rows())
+		require.Equal(t, time.UnixMilli(1700000000000).UTC(), frame.Fields[0].At(0))
+		require.Equal(t, 1.5, frame.Fields[1].At(0))
+		require.Equal(t, 2.0, frame.Fields[1].At(1))
+		require.Equal(t, data.Labels{"resource.service.name": "api"}, frame.Fields[1].Labels)
+	})
+
+	t.Run("returns tempo errors in the response", func(t *testing.T) {
+		service := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("invalid TraceQL query"))
+		})
+
+		query := `{ } | quantile_over_time(duration, .99)`
+		res, err := service.query(context.Background(), backend.PluginContext{}, traceQLQuery(t, dataquery.TempoQueryTypeTraceql, dataquery.TempoQuery{Query: &query}))
+		require.NoError(t, err)
+		require.ErrorContains(t, res.Error, "invalid TraceQL query")
+		require.Equal(t, backend.StatusBadRequest, res.Status)
+		require.Equal(t, backend.ErrorSourceDownstream, res.ErrorSource)
+	})
+}
+
+func TestMetricsStep(t *testing.T) {
+	hour := backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(3600, 0)}
+
+	tests := []struct {
+		name     string
+		step     string
+		query    backend.DataQuery
+		expected time.Duration
+	}{
+		{name: "uses the step of the query", step: "5m", query: backend.DataQuery{TimeRange: hour, Interval: time.Second}, expected: 5 * time.Minute},
+		{name: "uses the interval", query: backend.DataQuery{TimeRange: hour, Interval: time.Minute, MaxDataPoints: 1000}, expected: time.Minute},
+		{name: "limits the number of data points", query: backend.DataQuery{TimeRange: hour, Interval: time.Second, MaxDataPoints: 100}, expected: 36 * time.Second},
+		{name: "uses a minimum step", query: backend.DataQuery{TimeRange: hour}, expected: time.Second},
+		{name: "limits the number of samples", query: backend.DataQuery{TimeRange: backend.TimeRange{From: time.Unix(0, 0), To: time.Unix(0, 0).Add(30 * 24 * time.Hour)}}, expected: 235636 * time.Millisecond},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			model := &dataquery.TempoQuery{}
+			if tc.step != "" {
+				model.Step = &tc.step
+			}
+			step, err := metricsStep(model, tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, step)
+		})
+	}
+
+	invalid := "abc"
+	_, err := metricsStep(&dataquery.TempoQuery{Step: &invalid}, backend.DataQuery{TimeRange: hour})
+	require.Error(t, err)
+}
@@ -4,7 +4,7 @@ import { FetchError, getBackendSrv, isFetchError } from '@grafana/runtime';
 import config from 'app/core/config';
 import { t } from 'app/core/internationalization';
 
-import { LoginDTO } from './types';
+import { LoginDTO, MFAChallenge, MFAVerifyModel } from './types';
 
 const isOauthEnabled = () => {
   return !!config.oauth && Object.keys(config.oauth).length > 0;
@@ -32,6 +32,11 @@ interface Props {
     passwordHint: string;
     showDefaultPasswordWarning: boolean;
     loginErrorMessage: string | undefined;
+    mfaChallenge: MFAChallenge | undefined;
+    verifyMFA: (data: MFAVerifyModel) => void;
+    cancelMFA: () => void;
+    isVerifyingMFA: boolean;
+    recoveryCodes: string[] | undefined;
   }) => JSX.Element;
 }
 
@@ -40,10 +45,14 @@ interface State {
   isChangingPassword: boolean;
   showDefaultPasswordWarning: boolean;
   loginErrorMessage?: string;
+  mfaChallenge?: MFAChallenge;
+  isVerifyingMFA: boolean;
+  recoveryCodes?: string[];
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
   result: LoginDTO | undefined;
+  isDefaultPassword = false;
 
   constructor(props: Props) {
     super(props);
@@ -52,6 +61,7 @@ export class LoginCtrl extends PureComponent<Props, State> {
       isChangingPassword: false,
       showDefaultPasswordWarning: false,
       loginErrorMessage: config.loginError,
+      isVerifyingMFA: false,
     };
   }
 
@@ -102,6 +112,12 @@ export class LoginCtrl extends PureComponent<Props, State> {
         }
       })
       .catch((err) => {
+        if (isFetchError(err) && err.data?.messageId === 'mfa.required') {
+          this.isDefaultPassword = formModel.password === 'admin' && !config.ldapEnabled && !config.authProxyEnabled;
+          this.setState({ isLoggingIn: false, mfaChallenge: err.data.extra });
+          return;
+        }
+
         const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
         this.setState({
           isLoggingIn: false,
@@ -110,6 +126,51 @@ export class LoginCtrl extends PureComponent<Props, State> {
       });
   };
 
+  verifyMFA = (data: MFAVerifyModel) => {
+    const { mfaChallenge } = this.state;
+    if (!mfaChallenge) {
+      return;
+    }
+
+    this.setState({
+      loginErrorMessage: undefined,
+      isVerifyingMFA: true,
+    });
+
+    getBackendSrv()
+      .post<LoginDTO>('/login/mfa', { ...data, challengeId: mfaChallenge.challengeId }, { showErrorAlert: false })
+      .then(async (result) => {
+        this.result = result;
+        if (mfaChallenge.enroll) {
+          // the user enrolled their first factor while logging in, recovery codes are shown once before continuing
+          const { codes } = await getBackendSrv().post<{ codes: string[] }>('/api/user/mfa/recovery-codes');
+          this.setState({ isVerifyingMFA: false, recoveryCodes: codes });
+          return;
+        }
+        this.afterMFA();
+      })
+      .catch((err) => {
+        const fetchErrorMessage = isFetchError(err) ? getErrorMessage(err) : undefined;
+        this.setState({
+          isVerifyingMFA: false,
+          loginErrorMessage: fetchErrorMessage || t('login.error.unknown', 'Unknown error occurred'),
+        });
+      });
+  };
+
+  afterMFA = () => {
+    if (this.isDefaultPassword) {
+      this.setState({ mfaChallenge: undefined, recoveryCodes: undefined });
+      this.changeView(true);
+      return;
+    }
+    this.toGrafana();
+  };
+
+  cancelMFA = () => {
+    this.setState({ mfaChallenge: undefined, recoveryCodes: undefined, loginErrorMessage: undefined });
+  };
+
   changeView = (showDefaultPasswordWarning: boolean) => {
     this.setState({
       isChangingPassword: true,
@@ -132,8 +193,16 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword, showDefaultPasswordWarning, loginErrorMessage } = this.state;
-    const { login, toGrafana, changePassword } = this;
+    const {
+      isLoggingIn,
+      isChangingPassword,
+      showDefaultPasswordWarning,
+      loginErrorMessage,
+      mfaChallenge,
+      isVerifyingMFA,
+      recoveryCodes,
+    } = this.state;
+    const { login, toGrafana, changePassword, verifyMFA, cancelMFA } = this;
     const { loginHint, passwordHint, disableLoginForm, disableUserSignUp } = config;
 
     return (
@@ -151,6 +220,11 @@ export class LoginCtrl extends PureComponent<Props, State> {
           isChangingPassword,
           showDefaultPasswordWarning,
           loginErrorMessage,
+          mfaChallenge,
+          verifyMFA,
+          cancelMFA: recoveryCodes ? this.afterMFA : cancelMFA,
+          isVerifyingMFA,
+          recoveryCodes,
         })}
       </>
     );
@@ -159,7 +233,9 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
 export default LoginCtrl;
 
-function getErrorMessage(err: FetchError<undefined | { messageId?: string; message?: string }>): string | undefined {
+function getErrorMessage(
+  err: FetchError<undefined | { messageId?: string; message?: string; extra?: any }>
+): string | undefined {
   switch (err.data?.messageId) {
     case 'password-auth.empty':
     case 'password-auth.failed':
@@ -170,6 +246,16 @@ function getErrorMessage(err: FetchError<undefined | { messageId?: string; messa
         'login.error.blocked',
         'You have exceeded the number of login attempts for this user. Please try again later.'
       );
+    case 'mfa.invalid-code':
+    case 'mfa.invalid-credential':
+      return t('login.error.mfa-invalid', 'Invalid verification code or security key');
+    case 'mfa.challenge-not-found':
+      return t('login.error.mfa-expired', 'Your login has expired. Please log in again.');
+    case 'mfa.too-many-attempts':
+      return t(
+        'login.error.mfa-blocked',
+        'You have exceeded the number of verification attempts. Please try again later.'
+      );
     default:
       return err.data?.message;
   }
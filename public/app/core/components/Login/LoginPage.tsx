@@ -13,6 +13,7 @@ import { ChangePassword } from '../ForgottenPassword/ChangePassword';
 import LoginCtrl from './LoginCtrl';
 import { LoginForm } from './LoginForm';
 import { LoginLayout, InnerBox } from './LoginLayout';
+import { MFAForm, MFARecoveryCodes } from './MFAForm';
 import { LoginServiceButtons } from './LoginServiceButtons';
 import { UserSignup } from './UserSignup';
 
@@ -34,6 +35,11 @@ export const LoginPage = () => {
         isChangingPassword,
         showDefaultPasswordWarning,
         loginErrorMessage,
+        mfaChallenge,
+        verifyMFA,
+        cancelMFA,
+        isVerifyingMFA,
+        recoveryCodes,
       }) => (
         <LoginLayout isChangingPassword={isChangingPassword}>
           {!isChangingPassword && (
@@ -44,7 +50,18 @@ export const LoginPage = () => {
                 </Alert>
               )}
 
-              {!disableLoginForm && (
+              {mfaChallenge && recoveryCodes && <MFARecoveryCodes codes={recoveryCodes} onContinue={cancelMFA} />}
+
+              {mfaChallenge && !recoveryCodes && (
+                <MFAForm
+                  challenge={mfaChallenge}
+                  onSubmit={verifyMFA}
+                  onCancel={cancelMFA}
+                  isVerifying={isVerifyingMFA}
+                />
+              )}
+
+              {!disableLoginForm && !mfaChallenge && (
                 <LoginForm onSubmit={login} loginHint={loginHint} passwordHint={passwordHint} isLoggingIn={isLoggingIn}>
                   <Stack justifyContent="flex-end">
                     {!config.auth.disableLogin && (
@@ -59,8 +76,8 @@ export const LoginPage = () => {
                   </Stack>
                 </LoginForm>
               )}
-              <LoginServiceButtons />
-              {!disableUserSignUp && <UserSignup />}
+              {!mfaChallenge && <LoginServiceButtons />}
+              {!disableUserSignUp && !mfaChallenge && <UserSignup />}
             </InnerBox>
           )}
 
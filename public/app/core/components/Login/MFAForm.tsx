@@ -0,0 +1,236 @@
+import { css } from '@emotion/css';
+import { useId, useState } from 'react';
+import { useForm } from 'react-hook-form';
+
+import { GrafanaTheme2 } from '@grafana/data';
+import { getBackendSrv } from '@grafana/runtime';
+import { Button, ClipboardButton, Field, Input, LinkButton, Stack, Text, useStyles2 } from '@grafana/ui';
+import { t, Trans } from 'app/core/internationalization';
+
+import { MFAChallenge, MFAVerifyModel } from './types';
+import { createCredential, getCredential, isWebAuthnSupported, WebAuthnCreationOptionsDTO } from './webauthn';
+
+interface Props {
+  challenge: MFAChallenge;
+  onSubmit: (data: MFAVerifyModel) => void;
+  onCancel: () => void;
+  isVerifying: boolean;
+}
+
+interface CodeFormModel {
+  code: string;
+}
+
+interface TOTPEnrollment {
+  secret: string;
+  url: string;
+}
+
+export const MFAForm = ({ challenge, onSubmit, onCancel, isVerifying }: Props) => {
+  const styles = useStyles2(getStyles);
+  const codeId = useId();
+  const [isRecoveryCode, setIsRecoveryCode] = useState(false);
+  const [enrollment, setEnrollment] = useState<TOTPEnrollment>();
+  const [keyError, setKeyError] = useState<string>();
+  const {
+    handleSubmit,
+    register,
+    reset,
+    formState: { errors },
+  } = useForm<CodeFormModel>({ mode: 'onChange' });
+
+  const canUseTOTP = challenge.enroll || challenge.methods.includes('totp');
+  const canUseRecoveryCode = !challenge.enroll && challenge.methods.includes('recovery_code');
+  const canUseSecurityKey =
+    isWebAuthnSupported() && (challenge.enroll ? challenge.methods.includes('webauthn') : !!challenge.webauthn);
+
+  const submitCode = ({ code }: CodeFormModel) => {
+    onSubmit(isRecoveryCode ? { recoveryCode: code.trim() } : { code: code.replace(/\s/g, '') });
+  };
+
+  const beginTOTPEnrollment = async () => {
+    const result = await getBackendSrv().post<TOTPEnrollment>('/api/login/mfa/totp', {
+      challengeId: challenge.challengeId,
+    });
+    setEnrollment(result);
+  };
+
+  const verifySecurityKey = async () => {
+    setKeyError(undefined);
+    try {
+      if (challenge.enroll) {
+        const options = await getBackendSrv().post<WebAuthnCreationOptionsDTO>('/api/login/mfa/webauthn', {
+          challengeId: challenge.challengeId,
+        });
+        onSubmit({ webauthn: await createCredential(options) });
+      } else if (challenge.webauthn) {
+        onSubmit({ webauthn: await getCredential(challenge.webauthn) });
+      }
+    } catch {
+      // navigator.credentials rejects when the user cancels or the key is not registered for this site
+      setKeyError(t('login.mfa.security-key-failed', 'The security key could not be used. Try again.'));
+    }
+  };
+
+  const toggleRecoveryCode = () => {
+    reset();
+    setIsRecoveryCode(!isRecoveryCode);
+  };
+
+  const showCodeForm = !challenge.enroll || !!enrollment;
+
+  return (
+    <div className={styles.wrapper}>
+      <Stack direction="column" gap={2}>
+        <Text element="h3">
+          {challenge.enroll
+            ? t('login.mfa.enroll-title', 'Set up two-factor authentication')
+            : t('login.mfa.title', 'Two-factor authentication')}
+        </Text>
+
+        {challenge.enroll && !enrollment && (
+          <>
+            <Text color="secondary">
+              <Trans i18nKey="login.mfa.enroll-description">
+                A second factor is required for your account. Set up an authenticator app or a security key to continue.
+              </Trans>
+            </Text>
+            {canUseTOTP && (
+              <Button variant="secondary" fullWidth onClick={beginTOTPEnrollment}>
+                <Trans i18nKey="login.mfa.enroll-totp">Use an authenticator app</Trans>
+              </Button>
+            )}
+          </>
+        )}
+
+        {enrollment && (
+          <Stack direction="column" gap={1}>
+            <Text color="secondary">
+              <Trans i18nKey="login.mfa.enroll-totp-description">
+                Add this key to your authenticator app, then enter the code it shows.
+              </Trans>
+            </Text>
+            <Stack alignItems="center">
+              <code className={styles.secret}>{enrollment.secret}</code>
+              <ClipboardButton icon="copy" variant="secondary" size="sm" getText={() => enrollment.secret}>
+                <Trans i18nKey="login.mfa.copy">Copy</Trans>
+              </ClipboardButton>
+            </Stack>
+            <LinkButton fill="text" className={styles.linkButton} href={enrollment.url}>
+              <Trans i18nKey="login.mfa.open-app">Open in authenticator app</Trans>
+            </LinkButton>
+          </Stack>
+        )}
+
+        {showCodeForm && (canUseTOTP || isRecoveryCode) && (
+          <form onSubmit={handleSubmit(submitCode)}>
+            <Field
+              label={
+                isRecoveryCode
+                  ? t('login.mfa.recovery-code-label', 'Recovery code')
+                  : t('login.mfa.code-label', 'Verification code')
+              }
+              invalid={!!errors.code}
+              error={errors.code?.message}
+            >
+              <Input
+                {...register('code', { required: t('login.mfa.code-required', 'A code is required') })}
+                id={codeId}
+                autoFocus
+                autoComplete="one-time-code"
+                inputMode={isRecoveryCode ? 'text' : 'numeric'}
+                placeholder={isRecoveryCode ? 'xxxxxxxx-xxxxxxxx' : '123456'}
+              />
+            </Field>
+            <Button type="submit" className={styles.submitButton} disabled={isVerifying}>
+              {isVerifying ? t('login.mfa.verifying', 'Verifying...') : t('login.mfa.verify', 'Verify')}
+            </Button>
+          </form>
+        )}
+
+        {canUseSecurityKey && !enrollment && (
+          <Button
+            variant="secondary"
+            icon="key-skeleton-alt"
+            fullWidth
+            onClick={verifySecurityKey}
+            disabled={isVerifying}
+          >
+            {challenge.enroll
+              ? t('login.mfa.enroll-security-key', 'Register a security key')
+              : t('login.mfa.use-security-key', 'Use a security key')}
+          </Button>
+        )}
+        {keyError && <Text color="error">{keyError}</Text>}
+
+        <Stack justifyContent="space-between">
+          <Button fill="text" className={styles.linkButton} onClick={onCancel}>
+            <Trans i18nKey="login.mfa.back">Back to login</Trans>
+          </Button>
+          {canUseRecoveryCode && (
+            <Button fill="text" className={styles.linkButton} onClick={toggleRecoveryCode}>
+              {isRecoveryCode
+                ? t('login.mfa.use-code', 'Use a verification code')
+                : t('login.mfa.use-recovery-code', 'Use a recovery code')}
+            </Button>
+          )}
+        </Stack>
+      </Stack>
+    </div>
+  );
+};
+
+interface RecoveryCodesProps {
+  codes: string[];
+  onContinue: () => void;
+}
+
+export const MFARecoveryCodes = ({ codes, onContinue }: RecoveryCodesProps) => {
+  const styles = useStyles2(getStyles);
+
+  return (
+    <div className={styles.wrapper}>
+      <Stack direction="column" gap={2}>
+        <Text element="h3">{t('login.mfa.recovery-codes-title', 'Save your recovery codes')}</Text>
+        <Text color="secondary">
+          <Trans i18nKey="login.mfa.recovery-codes-description">
+            Each code can be used once to log in if you lose access to your second factor. They will not be shown
+            again.
+          </Trans>
+        </Text>
+        <pre className={styles.codes}>{codes.join('\n')}</pre>
+        <Stack justifyContent="space-between">
+          <ClipboardButton icon="copy" variant="secondary" getText={() => codes.join('\n')}>
+            <Trans i18nKey="login.mfa.copy">Copy</Trans>
+          </ClipboardButton>
+          <Button onClick={onContinue}>
+            <Trans i18nKey="login.mfa.continue">Continue</Trans>
+          </Button>
+        </Stack>
+      </Stack>
+    </div>
+  );
+};
+
+const getStyles = (theme: GrafanaTheme2) => {
+  return {
+    wrapper: css({
+      width: '100%',
+      paddingBottom: theme.spacing(2),
+    }),
+    submitButton: css({
+      justifyContent: 'center',
+      width: '100%',
+    }),
+    linkButton: css({
+      padding: 0,
+    }),
+    secret: css({
+      wordBreak: 'break-all',
+    }),
+    codes: css({
+      columnCount: 2,
+      textAlign: 'center',
+    }),
+  };
+};
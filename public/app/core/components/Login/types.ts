@@ -1,4 +1,23 @@
+import { WebAuthnAssertionOptionsDTO, WebAuthnCredentialDTO } from './webauthn';
+
 export interface LoginDTO {
   message: string;
   redirectUrl: string;
 }
+
+export type MFAMethod = 'totp' | 'webauthn' | 'recovery_code';
+
+// MFAChallenge is returned with the mfa.required error when the password was verified
+// but a second factor is needed to complete the login.
+export interface MFAChallenge {
+  challengeId: string;
+  methods: MFAMethod[];
+  enroll: boolean;
+  webauthn?: WebAuthnAssertionOptionsDTO;
+}
+
+export interface MFAVerifyModel {
+  code?: string;
+  recoveryCode?: string;
+  webauthn?: WebAuthnCredentialDTO;
+}
@@ -0,0 +1,108 @@
+// The server sends and expects the binary fields of the WebAuthn options and credentials as base64url strings.
+
+interface CredentialDescriptorDTO {
+  type: 'public-key';
+  id: string;
+}
+
+export interface WebAuthnCreationOptionsDTO {
+  challenge: string;
+  rp: { id: string; name: string };
+  user: { id: string; name: string; displayName: string };
+  pubKeyCredParams: Array<{ type: 'public-key'; alg: number }>;
+  timeout: number;
+  attestation: AttestationConveyancePreference;
+  excludeCredentials?: CredentialDescriptorDTO[];
+  authenticatorSelection: AuthenticatorSelectionCriteria;
+}
+
+export interface WebAuthnAssertionOptionsDTO {
+  challenge: string;
+  rpId: string;
+  timeout: number;
+  allowCredentials?: CredentialDescriptorDTO[];
+  userVerification: UserVerificationRequirement;
+}
+
+export interface WebAuthnCredentialDTO {
+  id: string;
+  rawId: string;
+  type: string;
+  response: {
+    clientDataJSON: string;
+    attestationObject?: string;
+    authenticatorData?: string;
+    signature?: string;
+    userHandle?: string;
+  };
+}
+
+export const isWebAuthnSupported = () => typeof window.PublicKeyCredential !== 'undefined';
+
+export async function createCredential(options: WebAuthnCreationOptionsDTO): Promise<WebAuthnCredentialDTO> {
+  const credential = await navigator.credentials.create({
+    publicKey: {
+      ...options,
+      challenge: decode(options.challenge),
+      user: { ...options.user, id: decode(options.user.id) },
+      excludeCredentials: options.excludeCredentials?.map(toDescriptor),
+    },
+  });
+  return serialize(credential);
+}
+
+export async function getCredential(options: WebAuthnAssertionOptionsDTO): Promise<WebAuthnCredentialDTO> {
+  const credential = await navigator.credentials.get({
+    publicKey: {
+      ...options,
+      challenge: decode(options.challenge),
+      allowCredentials: options.allowCredentials?.map(toDescriptor),
+    },
+  });
+  return serialize(credential);
+}
+
+function toDescriptor(descriptor: CredentialDescriptorDTO): PublicKeyCredentialDescriptor {
+  return { type: descriptor.type, id: decode(descriptor.id) };
+}
+
+function serialize(credential: Credential | null): WebAuthnCredentialDTO {
+  if (!(credential instanceof PublicKeyCredential)) {
+    throw new Error('No security key credential was returned');
+  }
+
+  const response = credential.response;
+  const dto: WebAuthnCredentialDTO = {
+    id: credential.id,
+    rawId: encode(credential.rawId),
+    type: credential.type,
+    response: { clientDataJSON: encode(response.clientDataJSON) },
+  };
+
+  if (response instanceof AuthenticatorAttestationResponse) {
+    dto.response.attestationObject = encode(response.attestationObject);
+  } else if (response instanceof AuthenticatorAssertionResponse) {
+    dto.response.authenticatorData = encode(response.authenticatorData);
+    dto.response.signature = encode(response.signature);
+    if (response.userHandle) {
+      dto.response.userHandle = encode(response.userHandle);
+    }
+  }
+  return dto;
+}
+
+function encode(buffer: ArrayBuffer): string {
+  let binary = '';
+  new Uint8Array(buffer).forEach((b) => (binary += String.fromCharCode(b)));
+  return btoa(binary).replace(/\+/g, '-').replace(/\//g, '_').replace(/=+$/, '');
+}
+
+function decode(value: string): ArrayBuffer {
+  const base64 = value.replace(/-/g, '+').replace(/_/g, '/');
+  const binary = atob(base64.padEnd(base64.length + ((4 - (base64.length % 4)) % 4), '='));
+  const bytes = new Uint8Array(binary.length);
+  for (let i = 0; i < binary.length; i++) {
+    bytes[i] = binary.charCodeAt(i);
+  }
+  return bytes.buffer;
+}
@@ -30,8 +30,9 @@ import { EmptyCorrelationsCTA } from './components/EmptyCorrelationsCTA';
 import type { RemoveCorrelationParams } from './types';
 import { CorrelationData, useCorrelations } from './useCorrelations';
 
+// the target of external correlations is undefined
 const sortDatasource: SortByFn<CorrelationData> = (a, b, column) =>
-  a.values[column].name.localeCompare(b.values[column].name);
+  (a.values[column]?.name ?? '').localeCompare(b.values[column]?.name ?? '');
 
 const isCorrelationsReadOnly = (correlation: CorrelationData) => correlation.provisioned;
 
@@ -248,7 +249,7 @@ function ExpendedRow({ correlation: { source, target, ...correlation }, readOnly
 
   return (
     <EditCorrelationForm
-      correlation={{ ...correlation, sourceUID: source.uid, targetUID: target.uid }}
+      correlation={{ ...correlation, sourceUID: source.uid, targetUID: target?.uid }}
       onUpdated={onUpdated}
       readOnly={readOnly}
     />
@@ -273,6 +274,10 @@ const DataSourceCell = memo(
   }: CellProps<CorrelationData, CorrelationData['source'] | CorrelationData['target']>) {
     const styles = useStyles2(getDatasourceCellStyles);
 
+    if (!value) {
+      return <span className={styles.root}>{t('correlations.list.external-target', 'External URL')}</span>;
+    }
+
     return (
       <span className={styles.root}>
         <img src={value.meta.info.logos.small} alt="" className={styles.dsLogo} />
@@ -281,7 +286,7 @@ const DataSourceCell = memo(
     );
   },
   ({ cell: { value } }, { cell: { value: prevValue } }) => {
-    return value.type === prevValue.type && value.name === prevValue.name;
+    return value?.type === prevValue?.type && value?.name === prevValue?.name;
   }
 );
 
@@ -54,7 +54,10 @@ export const AddCorrelationForm = ({ onClose, onCreated }: Props) => {
           defaultValues={defaultValues}
           pages={[ConfigureCorrelationBasicInfoForm, ConfigureCorrelationTargetForm, ConfigureCorrelationSourceForm]}
           navigation={CorrelationFormNavigation}
-          onSubmit={execute}
+          onSubmit={({ targetUID, ...correlation }) =>
+            // external correlations don't have a target data source
+            execute(correlation.config.type === 'external' ? correlation : { ...correlation, targetUID })
+          }
         />
       </CorrelationsFormContextProvider>
     </PanelContainer>
@@ -1,19 +1,29 @@
 import { Controller, useFormContext, useWatch } from 'react-hook-form';
 
 import { DataSourceInstanceSettings } from '@grafana/data';
-import { Field, FieldSet } from '@grafana/ui';
+import { Field, FieldSet, Input, RadioButtonGroup } from '@grafana/ui';
 import { Trans, t } from 'app/core/internationalization';
 import { DataSourcePicker } from 'app/features/datasources/components/picker/DataSourcePicker';
 
+import { CorrelationConfigType } from '../types';
+import { isExternalCorrelationTarget } from '../utils';
+
 import { QueryEditorField } from './QueryEditorField';
 import { useCorrelationsFormContext } from './correlationsFormContext';
 import { FormDTO } from './types';
+import { getInputId } from './utils';
 
 export const ConfigureCorrelationTargetForm = () => {
-  const { control, formState } = useFormContext<FormDTO>();
+  const { control, formState, setValue } = useFormContext<FormDTO>();
   const withDsUID = (fn: Function) => (ds: DataSourceInstanceSettings) => fn(ds.uid);
-  const { correlation } = useCorrelationsFormContext();
+  const { correlation, readOnly } = useCorrelationsFormContext();
   const targetUID: string | undefined = useWatch({ name: 'targetUID' }) || correlation?.targetUID;
+  const type: CorrelationConfigType = useWatch({ name: 'config.type' }) || correlation?.config.type || 'query';
+
+  const typeOptions: Array<{ label: string; value: CorrelationConfigType }> = [
+    { label: t('correlations.target-form.type-query', 'Query'), value: 'query' },
+    { label: t('correlations.target-form.type-external', 'External URL'), value: 'external' },
+  ];
 
   return (
     <>
@@ -26,39 +36,111 @@ export const ConfigureCorrelationTargetForm = () => {
         </Trans>
         <Controller
           control={control}
-          name="targetUID"
-          rules={{
-            required: { value: true, message: t('correlations.target-form.control-rules', 'This field is required.') },
-          }}
+          name="config.type"
           render={({ field: { onChange, value } }) => (
             <Field
-              label={t('correlations.target-form.target-label', 'Target')}
+              label={t('correlations.target-form.type-label', 'Type')}
               description={t(
-                'correlations.target-form.target-description',
-                'Specify which data source is queried when the link is clicked'
+                'correlations.target-form.type-description',
+                'Run a query in a data source, or open a URL outside of Grafana'
               )}
-              htmlFor="target"
-              invalid={!!formState.errors.targetUID}
-              error={formState.errors.targetUID?.message}
             >
-              <DataSourcePicker
-                onChange={withDsUID(onChange)}
-                noDefault
-                current={value}
-                inputId="target"
-                width={32}
+              <RadioButtonGroup
+                options={typeOptions}
+                value={value || 'query'}
+                onChange={(value) => {
+                  onChange(value);
+                  setValue('config.target', {});
+                }}
                 disabled={correlation !== undefined}
               />
             </Field>
           )}
         />
 
-        <QueryEditorField
-          name="config.target"
-          dsUid={targetUID}
-          invalid={!!formState.errors?.config?.target}
-          error={formState.errors?.config?.target?.message}
-        />
+        {type === 'external' ? (
+          <Controller
+            control={control}
+            name="config.target"
+            rules={{
+              validate: (target, formValues) => {
+                if (formValues.config.type !== 'external') {
+                  return true;
+                }
+                const url = isExternalCorrelationTarget(target) ? target.url : '';
+                if (url === '') {
+                  return t('correlations.target-form.control-rules', 'This field is required.');
+                }
+                if (!/^https?:\/\//i.test(url)) {
+                  return t('correlations.target-form.url-scheme', 'The URL must start with http:// or https://');
+                }
+                return true;
+              },
+            }}
+            render={({ field: { onChange, value } }) => (
+              <Field
+                label={t('correlations.target-form.url-label', 'URL')}
+                description={t(
+                  'correlations.target-form.url-description',
+                  'The URL the link opens. Field values and the output of transformations can be used as variables.'
+                )}
+                htmlFor={getInputId('url', correlation)}
+                invalid={!!formState.errors.config?.target}
+                error={formState.errors.config?.target?.message}
+              >
+                <Input
+                  id={getInputId('url', correlation)}
+                  value={isExternalCorrelationTarget(value) ? value.url : ''}
+                  onChange={(event) => onChange({ url: event.currentTarget.value })}
+                  readOnly={readOnly}
+                  placeholder="https://tickets.example.com/new?service=${service}"
+                />
+              </Field>
+            )}
+          />
+        ) : (
+          <>
+            <Controller
+              control={control}
+              name="targetUID"
+              rules={{
+                // the rule is kept when switching to an external correlation, which has no target data source
+                validate: (value, formValues) =>
+                  formValues.config.type === 'external' ||
+                  !!value ||
+                  t('correlations.target-form.control-rules', 'This field is required.'),
+              }}
+              render={({ field: { onChange, value } }) => (
+                <Field
+                  label={t('correlations.target-form.target-label', 'Target')}
+                  description={t(
+                    'correlations.target-form.target-description',
+                    'Specify which data source is queried when the link is clicked'
+                  )}
+                  htmlFor="target"
+                  invalid={!!formState.errors.targetUID}
+                  error={formState.errors.targetUID?.message}
+                >
+                  <DataSourcePicker
+                    onChange={withDsUID(onChange)}
+                    noDefault
+                    current={value}
+                    inputId="target"
+                    width={32}
+                    disabled={correlation !== undefined}
+                  />
+                </Field>
+              )}
+            />
+
+            <QueryEditorField
+              name="config.target"
+              dsUid={targetUID}
+              invalid={!!formState.errors?.config?.target}
+              error={formState.errors?.config?.target?.message}
+            />
+          </>
+        )}
       </FieldSet>
     </>
   );
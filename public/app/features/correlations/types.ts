@@ -1,4 +1,4 @@
-import { DataLinkTransformationConfig } from '@grafana/data';
+import { DataLink, DataLinkTransformationConfig } from '@grafana/data';
 
 export interface AddCorrelationResponse {
   correlation: Correlation;
@@ -26,19 +26,34 @@ export interface RemoveCorrelationResponse {
   message: string;
 }
 
-type CorrelationConfigType = 'query';
+export type CorrelationConfigType = 'query' | 'external';
+
+export interface ExternalCorrelationTarget {
+  url: string;
+}
 
 export interface CorrelationConfig {
   field: string;
-  target: object; // this contains anything that would go in the query editor, so any extension off DataQuery a datasource would have, and needs to be generic
+  // for query correlations, this contains anything that would go in the query editor, so any extension off DataQuery a
+  // datasource would have, and needs to be generic. For external correlations, this is an ExternalCorrelationTarget
+  target: object;
   type: CorrelationConfigType;
   transformations?: DataLinkTransformationConfig[];
 }
 
+/**
+ * Data link created from an external correlation. Transformations are applied to get the variables of the url,
+ * the same way as for the internal links of query correlations.
+ */
+export interface ExternalCorrelationDataLink extends DataLink {
+  transformations?: DataLinkTransformationConfig[];
+}
+
 export interface Correlation {
   uid: string;
   sourceUID: string;
-  targetUID: string;
+  // external correlations don't have a target data source
+  targetUID?: string;
   label?: string;
   description?: string;
   provisioned: boolean;
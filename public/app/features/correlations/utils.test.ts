@@ -1,7 +1,14 @@
-import { DataFrame, DataSourceInstanceSettings, FieldType, toDataFrame } from '@grafana/data';
+import {
+  DataFrame,
+  DataLinkConfigOrigin,
+  DataSourceInstanceSettings,
+  FieldType,
+  SupportedTransformationType,
+  toDataFrame,
+} from '@grafana/data';
 
 import { CorrelationData } from './useCorrelations';
-import { attachCorrelationsToDataFrames } from './utils';
+import { attachCorrelationsToDataFrames, isExternalCorrelationDataLink } from './utils';
 
 describe('correlations utils', () => {
   it('attaches correlations defined in the configuration', () => {
@@ -68,6 +75,39 @@ describe('correlations utils', () => {
     // Prometheus value (linked to Elastic)
     expect(testDataFrames[2].fields[0].config.links).toHaveLength(1);
   });
+
+  it('attaches external correlations as links to the url', () => {
+    const { testDataFrames, refIdMap } = setup();
+    const loki = { uid: 'loki-uid', name: 'loki' } as DataSourceInstanceSettings;
+    const transformations = [{ type: SupportedTransformationType.Logfmt }];
+    const correlations: CorrelationData[] = [
+      {
+        uid: 'loki-to-tickets',
+        label: 'open ticket',
+        source: loki,
+        config: {
+          type: 'external',
+          field: 'line',
+          target: { url: 'https://tickets.example.com/new?service=${service}' },
+          transformations,
+        },
+        provisioned: false,
+      },
+    ];
+    attachCorrelationsToDataFrames(testDataFrames, correlations, refIdMap);
+
+    expect(testDataFrames[0].fields[0].config.links).toEqual([
+      {
+        title: 'open ticket',
+        url: 'https://tickets.example.com/new?service=${service}',
+        targetBlank: true,
+        origin: DataLinkConfigOrigin.Correlations,
+        transformations,
+      },
+    ]);
+    expect(isExternalCorrelationDataLink(testDataFrames[0].fields[0].config.links![0])).toBe(true);
+    expect(testDataFrames[0].fields[1].config.links).toHaveLength(0);
+  });
 });
 
 function setup() {
@@ -1,12 +1,17 @@
 import { lastValueFrom } from 'rxjs';
 
-import { DataFrame, DataLinkConfigOrigin } from '@grafana/data';
+import { DataFrame, DataLink, DataLinkConfigOrigin } from '@grafana/data';
 import { createMonitoringLogger, getBackendSrv, getDataSourceSrv } from '@grafana/runtime';
 import { ExploreItemState } from 'app/types';
 
 import { formatValueName } from '../explore/PrometheusListView/ItemLabels';
 
-import { CreateCorrelationParams, CreateCorrelationResponse } from './types';
+import {
+  CreateCorrelationParams,
+  CreateCorrelationResponse,
+  ExternalCorrelationDataLink,
+  ExternalCorrelationTarget,
+} from './types';
 import {
   CorrelationData,
   CorrelationsData,
@@ -53,7 +58,19 @@ const decorateDataFrameWithInternalDataLinks = (dataFrame: DataFrame, correlatio
   dataFrame.fields.forEach((field) => {
     field.config.links = field.config.links?.filter((link) => link.origin !== DataLinkConfigOrigin.Correlations) || [];
     correlations.map((correlation) => {
-      if (correlation.config?.field === field.name) {
+      if (correlation.config?.field !== field.name) {
+        return;
+      }
+      if (correlation.config.type === 'external') {
+        const link: ExternalCorrelationDataLink = {
+          url: isExternalCorrelationTarget(correlation.config.target) ? correlation.config.target.url : '',
+          title: correlation.label || '',
+          targetBlank: true,
+          origin: DataLinkConfigOrigin.Correlations,
+          transformations: correlation.config.transformations,
+        };
+        field.config.links!.push(link);
+      } else if (correlation.target) {
         const targetQuery = correlation.config?.target || {};
         field.config.links!.push({
           internal: {
@@ -71,6 +88,12 @@ const decorateDataFrameWithInternalDataLinks = (dataFrame: DataFrame, correlatio
   });
 };
 
+export const isExternalCorrelationTarget = (target: object | undefined): target is ExternalCorrelationTarget =>
+  typeof (target as Partial<ExternalCorrelationTarget> | undefined)?.url === 'string';
+
+export const isExternalCorrelationDataLink = (link: DataLink): link is ExternalCorrelationDataLink =>
+  !link.internal && link.origin === DataLinkConfigOrigin.Correlations;
+
 export const getCorrelationsBySourceUIDs = async (sourceUIDs: string[]): Promise<CorrelationsData> => {
   return lastValueFrom(
     getBackendSrv().fetch<CorrelationsResponse>({
@@ -70,7 +70,7 @@ export function PublicDashboardScenePage({ match, route }: Props) {
 function PublicDashboardSceneRenderer({ model }: SceneComponentProps<DashboardScene>) {
   const [isActive, setIsActive] = useState(false);
   const { controls, title } = model.useState();
-  const { timePicker, refreshPicker, hideTimeControls } = controls!.useState();
+  const { timePicker, refreshPicker, hideTimeControls, variableControls, hideVariableControls } = controls!.useState();
   const bodyToRender = model.getBodyToRender();
   const styles = useStyles2(getStyles);
 
@@ -99,6 +99,13 @@ function PublicDashboardSceneRenderer({ model }: SceneComponentProps<DashboardSc
           </Stack>
         )}
       </div>
+      {!hideVariableControls && variableControls.length > 0 && (
+        <div className={styles.variables}>
+          {variableControls.map((c) => (
+            <c.Component model={c} key={c.state.key} />
+          ))}
+        </div>
+      )}
       <div className={styles.body}>
         <bodyToRender.Component model={bodyToRender} />
       </div>
@@ -145,6 +152,12 @@ function getStyles(theme: GrafanaTheme2) {
       fontSize: theme.typography.h4.fontSize,
       margin: 0,
     }),
+    variables: css({
+      display: 'flex',
+      flexWrap: 'wrap',
+      gap: theme.spacing(1),
+      paddingBottom: theme.spacing(2),
+    }),
     body: css({
       label: 'body',
       flex: 1,
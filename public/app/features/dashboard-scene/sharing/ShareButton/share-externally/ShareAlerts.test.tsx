@@ -3,6 +3,7 @@ import { act } from 'react-dom/test-utils';
 
 import { selectors as e2eSelectors } from '@grafana/e2e-selectors';
 import {
+  AdHocFiltersVariable,
   CustomVariable,
   SceneDataTransformer,
   SceneGridLayout,
@@ -28,7 +29,16 @@ beforeEach(() => {
 
 describe('ShareAlerts', () => {
   describe('UnsupportedTemplateVariablesAlert', () => {
-    it('should render alert when hasPermission and the dashboard has template vars', async () => {
+    it('should render alert when hasPermission and the dashboard has ad hoc filters', async () => {
+      await setup(undefined, {
+        $variables: new SceneVariableSet({
+          variables: [new AdHocFiltersVariable({ name: 'filters' })],
+        }),
+      });
+
+      expect(await screen.findByTestId(selectors.TemplateVariablesWarningAlert)).toBeInTheDocument();
+    });
+    it('should not render alert when hasPermission and the dashboard has supported template vars', async () => {
       await setup(undefined, {
         $variables: new SceneVariableSet({
           variables: [
@@ -42,7 +52,7 @@ describe('ShareAlerts', () => {
         }),
       });
 
-      expect(await screen.findByTestId(selectors.TemplateVariablesWarningAlert)).toBeInTheDocument();
+      expect(screen.queryByTestId(selectors.TemplateVariablesWarningAlert)).not.toBeInTheDocument();
     });
     it('should not render alert when hasPermission but the dashboard has no template vars', async () => {
       await setup();
@@ -3,6 +3,7 @@ import { EmailSharingPricingAlert } from 'app/features/dashboard/components/Shar
 import { UnsupportedDataSourcesAlert } from 'app/features/dashboard/components/ShareModal/SharePublicDashboard/ModalAlerts/UnsupportedDataSourcesAlert';
 import { UnsupportedTemplateVariablesAlert } from 'app/features/dashboard/components/ShareModal/SharePublicDashboard/ModalAlerts/UnsupportedTemplateVariablesAlert';
 import {
+  dashboardHasUnsupportedTemplateVariables,
   isEmailSharingEnabled,
   PublicDashboard,
   PublicDashboardShareType,
@@ -17,7 +18,9 @@ export default function ShareAlerts({ publicDashboard }: { publicDashboard?: Pub
   const { dashboard } = useShareDrawerContext();
   const hasWritePermissions = contextSrv.hasPermission(AccessControlAction.DashboardsPublicWrite);
   const unsupportedDataSources = useUnsupportedDatasources(dashboard);
-  const hasTemplateVariables = (dashboard.state.$variables?.state.variables.length ?? 0) > 0;
+  const hasTemplateVariables = dashboardHasUnsupportedTemplateVariables(
+    dashboard.state.$variables?.state.variables.map((variable) => variable.state) ?? []
+  );
 
   return (
     <>
@@ -1,5 +1,6 @@
 import { SceneComponentProps } from '@grafana/scenes';
 import { CreatePublicDashboardBase } from 'app/features/dashboard/components/ShareModal/SharePublicDashboard/CreatePublicDashboard/CreatePublicDashboard';
+import { dashboardHasUnsupportedTemplateVariables } from 'app/features/dashboard/components/ShareModal/SharePublicDashboard/SharePublicDashboardUtils';
 
 import { getDashboardSceneFor } from '../../utils/utils';
 
@@ -9,7 +10,9 @@ import { useUnsupportedDatasources } from './hooks';
 export function CreatePublicDashboard({ model }: SceneComponentProps<SharePublicDashboardTab>) {
   const dashboard = getDashboardSceneFor(model);
   const unsupportedDataSources = useUnsupportedDatasources(dashboard);
-  const hasTemplateVariables = (dashboard.state.$variables?.state.variables.length ?? 0) > 0;
+  const hasTemplateVariables = dashboardHasUnsupportedTemplateVariables(
+    dashboard.state.$variables?.state.variables.map((variable) => variable.state) ?? []
+  );
 
   return (
     <CreatePublicDashboardBase
@@ -9,14 +9,14 @@ export const UnsupportedTemplateVariablesAlert = ({ showDescription = true }: {
     severity="warning"
     title={t(
       'public-dashboard.modal-alerts.unsupported-template-variable-alert-title',
-      'Template variables are not supported'
+      'Ad hoc filters and group by variables are not supported'
     )}
     data-testid={selectors.TemplateVariablesWarningAlert}
     bottomSpacing={0}
   >
     {showDescription && (
       <Trans i18nKey="public-dashboard.modal-alerts.unsupported-template-variable-alert-desc">
-        This public dashboard may not work since it uses template variables
+        This public dashboard may not work since it uses ad hoc filters or group by variables
       </Trans>
     )}
   </Alert>
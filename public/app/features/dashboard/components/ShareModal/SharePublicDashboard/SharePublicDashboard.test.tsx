@@ -116,8 +116,8 @@ const alertTests = () => {
     await renderSharePublicDashboard();
     expect(screen.queryByTestId(selectors.NoUpsertPermissionsWarningAlert)).toBeInTheDocument();
   });
-  it('when dashboard has template variables, warning is shown', async () => {
-    jest.spyOn(sharePublicDashboardUtils, 'dashboardHasTemplateVariables').mockReturnValue(true);
+  it('when dashboard has unsupported template variables, warning is shown', async () => {
+    jest.spyOn(sharePublicDashboardUtils, 'dashboardHasUnsupportedTemplateVariables').mockReturnValue(true);
 
     await renderSharePublicDashboard();
     expect(screen.queryByTestId(selectors.TemplateVariablesWarningAlert)).toBeInTheDocument();
@@ -1,4 +1,3 @@
-import { TypedVariableModel } from '@grafana/data';
 import { DataSourceRef, DataQuery } from '@grafana/data/src/types/query';
 import { DataSourceWithBackend } from '@grafana/runtime';
 import { updateConfig } from 'app/core/config';
@@ -7,7 +6,7 @@ import { PanelModel } from 'app/features/dashboard/state/PanelModel';
 
 import {
   PublicDashboard,
-  dashboardHasTemplateVariables,
+  dashboardHasUnsupportedTemplateVariables,
   publicDashboardPersisted,
   generatePublicDashboardUrl,
   getUnsupportedDashboardDatasources,
@@ -36,16 +35,15 @@ jest.mock('@grafana/runtime/src/services/dataSourceSrv', () => {
   };
 });
 
-describe('dashboardHasTemplateVariables', () => {
+describe('dashboardHasUnsupportedTemplateVariables', () => {
   it('false', () => {
-    let variables: TypedVariableModel[] = [];
-    expect(dashboardHasTemplateVariables(variables)).toBe(false);
+    expect(dashboardHasUnsupportedTemplateVariables([])).toBe(false);
+    expect(dashboardHasUnsupportedTemplateVariables([{ type: 'custom' }, { type: 'query' }])).toBe(false);
   });
 
   it('true', () => {
-    //@ts-ignore
-    let variables: TypedVariableModel[] = ['a'];
-    expect(dashboardHasTemplateVariables(variables)).toBe(true);
+    expect(dashboardHasUnsupportedTemplateVariables([{ type: 'custom' }, { type: 'adhoc' }])).toBe(true);
+    expect(dashboardHasUnsupportedTemplateVariables([{ type: 'groupby' }])).toBe(true);
   });
 });
 
@@ -43,8 +43,12 @@ export interface SessionUser {
 }
 
 // Instance methods
-export const dashboardHasTemplateVariables = (variables: TypedVariableModel[]): boolean => {
-  return variables.length > 0;
+// Ad hoc filters and group by variables are applied by the data source in the browser and
+// cannot be evaluated by the public dashboard query API.
+const unsupportedVariableTypes = ['adhoc', 'groupby'];
+
+export const dashboardHasUnsupportedTemplateVariables = (variables: Array<Pick<TypedVariableModel, 'type'>>): boolean => {
+  return variables.some((variable) => unsupportedVariableTypes.includes(variable.type));
 };
 
 export const publicDashboardPersisted = (publicDashboard?: PublicDashboard): boolean => {
@@ -2,7 +2,7 @@ import { css } from '@emotion/css';
 import { useEffect } from 'react';
 import { usePrevious } from 'react-use';
 
-import { GrafanaTheme2, PageLayoutType, TimeZone } from '@grafana/data';
+import { GrafanaTheme2, PageLayoutType, TimeZone, VariableHide } from '@grafana/data';
 import { selectors as e2eSelectors } from '@grafana/e2e-selectors/src';
 import { PageToolbar, useStyles2 } from '@grafana/ui';
 import { Page } from 'app/core/components/Page/Page';
@@ -21,6 +21,7 @@ import { DashboardLoading } from '../components/DashboardLoading/DashboardLoadin
 import { PublicDashboardFooter } from '../components/PublicDashboard/PublicDashboardsFooter';
 import { useGetPublicDashboardConfig } from '../components/PublicDashboard/usePublicDashboardConfig';
 import { PublicDashboardNotAvailable } from '../components/PublicDashboardNotAvailable/PublicDashboardNotAvailable';
+import { SubMenu } from '../components/SubMenu/SubMenu';
 import { DashboardGrid } from '../dashgrid/DashboardGrid';
 import { getTimeSrv } from '../services/TimeSrv';
 import { DashboardModel } from '../state';
@@ -107,6 +108,11 @@ const PublicDashboardPage = (props: Props) => {
       <Toolbar dashboard={dashboard} />
       {dashboardState.initError && <DashboardFailed initError={dashboardState.initError} />}
       <div className={styles.gridContainer}>
+        {dashboard.getVariables().some((variable) => variable.hide !== VariableHide.hideVariable) && (
+          <section aria-label={e2eSelectors.pages.Dashboard.SubMenu.submenu}>
+            <SubMenu dashboard={dashboard} annotations={[]} links={[]} />
+          </section>
+        )}
         <DashboardGrid dashboard={dashboard} isEditable={false} viewPanel={null} editPanel={null} hidePanelMenus />
       </div>
       <div className={styles.footer}>
@@ -11,10 +11,12 @@ import {
   TimeRange,
   toDataFrame,
 } from '@grafana/data';
+import { VariableFormatID } from '@grafana/schema';
 import { setTemplateSrv, reportInteraction } from '@grafana/runtime';
 
 import { initTemplateSrv } from '../../../../test/helpers/initTemplateSrv';
 import { ContextSrv, setContextSrv } from '../../../core/services/context_srv';
+import { ExternalCorrelationDataLink } from '../../correlations/types';
 import { setLinkSrv } from '../../panel/panellinks/link_srv';
 
 import { getFieldLinksForExplore, getVariableUsageInfo } from './links';
@@ -322,6 +324,44 @@ describe('explore links utils', () => {
       );
     });
 
+    it('returns links of external correlations with transformation variables', () => {
+      const correlationLink: ExternalCorrelationDataLink = {
+        title: 'open ticket',
+        url: 'https://tickets.example.com/new?app=${application}',
+        origin: DataLinkConfigOrigin.Correlations,
+        transformations: [{ type: SupportedTransformationType.Logfmt }],
+      };
+
+      const { field, range, dataFrame } = setup(correlationLink, true, {
+        name: 'msg',
+        type: FieldType.string,
+        values: ['application=foo&bar host=dev-001'],
+        config: {
+          links: [correlationLink],
+        },
+      });
+      setLinkSrv({
+        getDataLinkUIModel(link: DataLink, replaceVariables: InterpolateFunction | undefined, origin) {
+          return {
+            href: replaceVariables!(link.url, undefined, VariableFormatID.UriEncode),
+            title: link.title,
+            origin: origin,
+          };
+        },
+        getAnchorInfo(link) {
+          return { ...link };
+        },
+        getLinkUrl(link) {
+          return link.url;
+        },
+      });
+
+      const links = getFieldLinksForExplore({ field, rowIndex: 0, range, dataFrame });
+      expect(links).toHaveLength(1);
+      expect(links[0].href).toBe('https://tickets.example.com/new?app=foo%26bar');
+      expect(links[0].title).toBe('open ticket');
+    });
+
     it('returns internal links with 2 unnamed regex transformations and use the last transformation', () => {
       const transformationLink: DataLink = {
         title: '',
@@ -17,6 +17,7 @@ import {
   CoreApp,
   SplitOpenOptions,
   DataLinkPostProcessor,
+  DataLinkTransformationConfig,
   ExploreUrlState,
   urlUtil,
 } from '@grafana/data';
@@ -24,6 +25,7 @@ import { getTemplateSrv, reportInteraction, VariableInterpolation } from '@grafa
 import { DataQuery } from '@grafana/schema';
 import { contextSrv } from 'app/core/services/context_srv';
 import { getTransformationVars } from 'app/features/correlations/transformations';
+import { isExternalCorrelationDataLink } from 'app/features/correlations/utils';
 import { ExploreItemState } from 'app/types/explore';
 
 import { getLinkSrv } from '../../panel/panellinks/link_srv';
@@ -65,14 +67,14 @@ export const exploreDataLinkPostProcessorFactory = (
     const { field, dataLinkScopedVars: vars, frame: dataFrame, link, linkModel } = options;
     const { valueRowIndex: rowIndex } = options.config;
 
-    if (!link.internal || rowIndex === undefined) {
+    if ((!link.internal && !isExternalCorrelationDataLink(link)) || rowIndex === undefined) {
       return linkModel;
     }
 
     /**
      * Even though getFieldLinksForExplore can produce internal and external links we re-use the logic for creating
-     * internal links only. Eventually code from getFieldLinksForExplore can be moved here and getFieldLinksForExplore
-     * can be removed (once all Explore panels start using field.getLinks).
+     * internal links and links of external correlations only. Eventually code from getFieldLinksForExplore can be
+     * moved here and getFieldLinksForExplore can be removed (once all Explore panels start using field.getLinks).
      */
     const links = getFieldLinksForExplore({
       field,
@@ -160,8 +162,13 @@ export const getFieldLinksForExplore = (options: {
 
     const fieldLinks = links.map((link) => {
       if (!link.internal) {
-        const replace: InterpolateFunction = (value, vars) =>
-          getTemplateSrv().replace(value, { ...vars, ...scopedVars });
+        let replace: InterpolateFunction = (value, vars) => getTemplateSrv().replace(value, { ...vars, ...scopedVars });
+        if (isExternalCorrelationDataLink(link)) {
+          // variables of external correlations are url encoded, the same way as when they are resolved by the server
+          const correlationVars = getTransformationsVars(link.transformations, field, rowIndex, dataFrame);
+          replace = (value, vars, format) =>
+            getTemplateSrv().replace(value, { ...vars, ...scopedVars, ...correlationVars }, format);
+        }
 
         const linkModel = getLinkSrv().getDataLinkUIModel(link, replace, field);
         if (!linkModel.title) {
@@ -169,23 +176,12 @@ export const getFieldLinksForExplore = (options: {
         }
         return linkModel;
       } else {
-        let internalLinkSpecificVars: ScopedVars = {};
-        if (link.internal?.transformations) {
-          link.internal?.transformations.forEach((transformation) => {
-            let fieldValue;
-            if (transformation.field) {
-              const transformField = dataFrame?.fields.find((field) => field.name === transformation.field);
-              fieldValue = transformField?.values[rowIndex];
-            } else {
-              fieldValue = field.values[rowIndex];
-            }
-
-            internalLinkSpecificVars = {
-              ...internalLinkSpecificVars,
-              ...getTransformationVars(transformation, fieldValue, field.name),
-            };
-          });
-        }
+        const internalLinkSpecificVars = getTransformationsVars(
+          link.internal.transformations,
+          field,
+          rowIndex,
+          dataFrame
+        );
 
         const allVars = { ...scopedVars, ...internalLinkSpecificVars };
         const variableData = getVariableUsageInfo(link, allVars);
@@ -231,6 +227,33 @@ export const getFieldLinksForExplore = (options: {
   return [];
 };
 
+/**
+ * Creates the variables of the transformations of a link for a row of the data frame
+ */
+const getTransformationsVars = (
+  transformations: DataLinkTransformationConfig[] | undefined,
+  field: Field,
+  rowIndex: number,
+  dataFrame?: DataFrame
+): ScopedVars => {
+  let transformationsVars: ScopedVars = {};
+  transformations?.forEach((transformation) => {
+    let fieldValue;
+    if (transformation.field) {
+      const transformField = dataFrame?.fields.find((field) => field.name === transformation.field);
+      fieldValue = transformField?.values[rowIndex];
+    } else {
+      fieldValue = field.values[rowIndex];
+    }
+
+    transformationsVars = {
+      ...transformationsVars,
+      ...getTransformationVars(transformation, fieldValue, field.name),
+    };
+  });
+  return transformationsVars;
+};
+
 /**
  * @internal
  */
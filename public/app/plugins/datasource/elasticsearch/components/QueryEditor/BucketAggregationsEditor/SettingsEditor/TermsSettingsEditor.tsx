@@ -2,7 +2,7 @@ import { uniqueId } from 'lodash';
 import { useRef } from 'react';
 
 import { SelectableValue } from '@grafana/data';
-import { InlineField, Select, Input } from '@grafana/ui';
+import { InlineField, InlineSwitch, Select, Input } from '@grafana/ui';
 
 import { useDispatch } from '../../../../hooks/useStatelessReducer';
 import { MetricAggregation, Percentiles, ExtendedStatMetaType, ExtendedStats, Terms } from '../../../../types';
@@ -87,6 +87,26 @@ export const TermsSettingsEditor = ({ bucketAgg }: Props) => {
           defaultValue={bucketAgg.settings?.missing || bucketAggregationConfig.terms.defaultSettings?.missing}
         />
       </InlineField>
+
+      <InlineField
+        label="Composite"
+        tooltip="Use a composite aggregation and page through all the terms, up to the maximum number of composite buckets of the data source. Size doesn't apply. Only applies to leading terms groups."
+        {...inlineFieldProps}
+      >
+        <InlineSwitch
+          id={`${baseId}-use_composite`}
+          onChange={(e) =>
+            dispatch(
+              changeBucketAggregationSetting({
+                bucketAgg,
+                settingName: 'useComposite',
+                newValue: e.currentTarget.checked,
+              })
+            )
+          }
+          checked={!!bucketAgg.settings?.useComposite}
+        />
+      </InlineField>
     </>
   );
 };
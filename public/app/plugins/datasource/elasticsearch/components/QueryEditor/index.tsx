@@ -2,13 +2,13 @@ import { css } from '@emotion/css';
 import { useEffect, useId, useState } from 'react';
 import { SemVer } from 'semver';
 
-import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps } from '@grafana/data';
-import { Alert, InlineField, InlineLabel, Input, QueryField, useStyles2 } from '@grafana/ui';
+import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps, SelectableValue } from '@grafana/data';
+import { Alert, InlineField, InlineLabel, Input, QueryField, RadioButtonGroup, TextArea, useStyles2 } from '@grafana/ui';
 
 import { ElasticDatasource } from '../../datasource';
 import { useNextId } from '../../hooks/useNextId';
 import { useDispatch } from '../../hooks/useStatelessReducer';
-import { ElasticsearchOptions, ElasticsearchQuery } from '../../types';
+import { ElasticsearchOptions, ElasticsearchQuery, ESQL_QUERY_TYPE } from '../../types';
 import { isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from '../../utils';
 
 import { BucketAggregationsEditor } from './BucketAggregationsEditor';
@@ -46,9 +46,23 @@ function useElasticVersion(datasource: ElasticDatasource): SemVer | null {
   return version;
 }
 
+type QueryLanguage = 'lucene' | 'esql';
+
+const queryLanguageOptions: Array<SelectableValue<QueryLanguage>> = [
+  { value: 'lucene', label: 'Lucene' },
+  { value: 'esql', label: 'ES|QL' },
+];
+
 export const QueryEditor = ({ query, onChange, onRunQuery, datasource, range }: ElasticQueryEditorProps) => {
   const elasticVersion = useElasticVersion(datasource);
   const showUnsupportedMessage = elasticVersion != null && !isSupportedVersion(elasticVersion);
+  const isESQL = query.queryType === ESQL_QUERY_TYPE;
+
+  const onQueryLanguageChange = (language: QueryLanguage) => {
+    // the lucene query and the ES|QL query share the query field, it is cleared when the language changes
+    onChange({ ...query, query: '', queryType: language === 'esql' ? ESQL_QUERY_TYPE : undefined });
+  };
+
   return (
     <ElasticsearchProvider
       datasource={datasource}
@@ -58,11 +72,53 @@ export const QueryEditor = ({ query, onChange, onRunQuery, datasource, range }:
       range={range || getDefaultTimeRange()}
     >
       {showUnsupportedMessage && <Alert title={unsupportedVersionMessage} />}
-      <QueryEditorForm value={query} />
+      <InlineField label="Query language" labelWidth={17}>
+        <RadioButtonGroup<QueryLanguage>
+          options={queryLanguageOptions}
+          value={isESQL ? 'esql' : 'lucene'}
+          onChange={onQueryLanguageChange}
+        />
+      </InlineField>
+      {isESQL ? (
+        <ESQLQueryField
+          value={query.query}
+          onChange={(value) => {
+            onChange({ ...query, query: value });
+            onRunQuery();
+          }}
+        />
+      ) : (
+        <QueryEditorForm value={query} />
+      )}
     </ElasticsearchProvider>
   );
 };
 
+const ESQLQueryField = ({ value, onChange }: { value?: string; onChange: (v: string) => void }) => {
+  const styles = useStyles2(getStyles);
+
+  return (
+    <div className={styles.root}>
+      <InlineLabel width={17} tooltip="The time range of the panel is applied to the time field of the data source.">
+        ES|QL Query
+      </InlineLabel>
+      <div className={styles.queryItem}>
+        <TextArea
+          aria-label="ES|QL query"
+          rows={3}
+          defaultValue={value}
+          onBlur={(e) => {
+            if (e.currentTarget.value !== value) {
+              onChange(e.currentTarget.value);
+            }
+          }}
+          placeholder="FROM logs-* | STATS count = COUNT(*) BY host"
+        />
+      </div>
+    </div>
+  );
+};
+
 const getStyles = (theme: GrafanaTheme2) => ({
   root: css`
     display: flex;
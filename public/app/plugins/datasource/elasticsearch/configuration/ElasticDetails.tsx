@@ -94,6 +94,21 @@ export const ElasticDetails = ({ value, onChange }: Props) => {
         />
       </InlineField>
 
+      <InlineField
+        label="Max composite buckets"
+        htmlFor="es_config_maxCompositeBuckets"
+        labelWidth={29}
+        tooltip="Maximum number of buckets fetched by the terms groups using composite aggregations. Defaults to 10000."
+      >
+        <Input
+          id="es_config_maxCompositeBuckets"
+          value={value.jsonData.maxCompositeBuckets || ''}
+          onChange={jsonDataChangeHandler('maxCompositeBuckets', value, onChange)}
+          width={24}
+          placeholder="10000"
+        />
+      </InlineField>
+
       <InlineField
         label="Min time interval"
         htmlFor="es_config_minTimeInterval"
@@ -99,6 +99,7 @@ composableKinds: DataQuery: {
 					min_doc_count?: string
 					orderBy?:       string
 					missing?:       string
+					useComposite?:  bool
 				} @cuetsy(kind="interface")
 
 				#Filters: {
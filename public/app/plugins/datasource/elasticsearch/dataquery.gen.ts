@@ -72,6 +72,7 @@ export interface Terms extends BucketAggregationWithField {
     min_doc_count?: string;
     orderBy?: string;
     missing?: string;
+    useComposite?: boolean;
   };
   type: 'terms';
 }
@@ -82,6 +83,7 @@ export interface TermsSettings {
   order?: TermsOrder;
   orderBy?: string;
   size?: string;
+  useComposite?: boolean;
 }
 
 export interface Filters extends BaseBucketAggregation {
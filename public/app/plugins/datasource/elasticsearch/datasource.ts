@@ -81,6 +81,7 @@ import {
   isElasticsearchResponseWithAggregations,
   isElasticsearchResponseWithHits,
   ElasticsearchHits,
+  ESQL_QUERY_TYPE,
 } from './types';
 import { getScriptValue, isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from './utils';
 
@@ -1080,6 +1081,15 @@ export class ElasticDatasource
     scopedVars: ScopedVars,
     filters?: AdHocVariableFilter[]
   ): ElasticsearchQuery {
+    // ES|QL queries are not lucene queries, only template variables are interpolated
+    if (query.queryType === ESQL_QUERY_TYPE) {
+      return {
+        ...query,
+        datasource: this.getRef(),
+        query: this.templateSrv.replace(query.query || '', scopedVars),
+      };
+    }
+
     // We need a separate interpolation format for lucene queries, therefore we first interpolate any
     // lucene query string and then everything else
     const interpolateBucketAgg = (bucketAgg: BucketAggregation): BucketAggregation => {
@@ -59,6 +59,7 @@ export interface ElasticsearchOptions extends DataSourceJsonData {
   interval?: Interval;
   timeInterval: string;
   maxConcurrentShardRequests?: number;
+  maxCompositeBuckets?: number;
   logMessageField?: string;
   logLevelField?: string;
   dataLinks?: DataLinkConfig[];
@@ -70,6 +71,9 @@ export interface ElasticsearchOptions extends DataSourceJsonData {
 
 export type QueryType = 'metrics' | 'logs' | 'raw_data' | 'raw_document';
 
+// ES|QL queries are run with the `esql` query type of the data query
+export const ESQL_QUERY_TYPE = 'esql';
+
 interface MetricConfiguration<T extends MetricAggregationType> {
   label: string;
   requiresField: boolean;
@@ -0,0 +1,68 @@
+import { useAsync } from 'react-use';
+
+import { SelectableValue } from '@grafana/data';
+import { getBackendSrv } from '@grafana/runtime';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, Select } from '@grafana/ui';
+
+import { EditorProps } from '../QueryEditor';
+import { ReplayQuery } from '../dataquery';
+
+const alignOptions: Array<SelectableValue<ReplayQuery['align']>> = [
+  { label: 'End', value: 'end', description: 'The end of the recording lines up with the end of the time range' },
+  {
+    label: 'Start',
+    value: 'start',
+    description: 'The start of the recording lines up with the start of the time range',
+  },
+];
+
+export const ReplayEditor = ({ onChange, query }: EditorProps) => {
+  const replay: ReplayQuery = query.replay ?? { name: '' };
+
+  const { loading, value: recordings } = useAsync(async () => {
+    const names: string[] = await getBackendSrv().get('/api/query-recordings');
+    return names.map((name) => ({ label: name, value: name }));
+  }, []);
+
+  const onReplayChange = (update: Partial<ReplayQuery>) => {
+    onChange({ ...query, replay: { ...replay, ...update } });
+  };
+
+  return (
+    <>
+      <InlineFieldRow>
+        <InlineField label="Recording" labelWidth={14}>
+          <Select
+            width={32}
+            isLoading={loading}
+            onChange={({ value }) => onReplayChange({ name: value ?? '' })}
+            placeholder="Select recording"
+            options={recordings ?? []}
+            value={recordings?.find((r) => r.value === replay.name) ?? null}
+          />
+        </InlineField>
+        <InlineField label="Ref ID" tooltip="RefID of the recorded query, defaults to the refId of this query">
+          <Input
+            width={8}
+            value={replay.refId}
+            placeholder={query.refId}
+            onChange={(e) => onReplayChange({ refId: e.currentTarget.value })}
+          />
+        </InlineField>
+      </InlineFieldRow>
+      <InlineFieldRow>
+        <InlineField label="Align" labelWidth={14}>
+          <Select
+            width={32}
+            onChange={({ value }) => onReplayChange({ align: value })}
+            options={alignOptions}
+            value={alignOptions.find((o) => o.value === (replay.align ?? 'end'))}
+          />
+        </InlineField>
+        <InlineField label="Stream" tooltip="Keep replaying the recording over Grafana Live">
+          <InlineSwitch value={!!replay.stream} onChange={(e) => onReplayChange({ stream: e.currentTarget.checked })} />
+        </InlineField>
+      </InlineFieldRow>
+    </>
+  );
+};
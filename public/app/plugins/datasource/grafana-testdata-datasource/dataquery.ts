@@ -25,6 +25,7 @@ export enum TestDataQueryType {
   RandomWalkTable = 'random_walk_table',
   RandomWalkWithError = 'random_walk_with_error',
   RawFrame = 'raw_frame',
+  Replay = 'replay',
   ServerError500 = 'server_error_500',
   Simulation = 'simulation',
   SlowQuery = 'slow_query',
@@ -63,6 +64,13 @@ export interface SimulationQuery {
   stream?: boolean;
 }
 
+export interface ReplayQuery {
+  align?: 'end' | 'start';
+  name: string;
+  refId?: string;
+  stream?: boolean;
+}
+
 export interface NodesQuery {
   count?: number;
   seed?: number;
@@ -118,6 +126,7 @@ export interface TestDataDataQuery extends common.DataQuery {
   points?: Array<Array<string | number>>;
   pulseWave?: PulseWaveQuery;
   rawFrameContent?: string;
+  replay?: ReplayQuery;
   scenarioId?: TestDataQueryType;
   seriesCount?: number;
   sim?: SimulationQuery;
@@ -0,0 +1,120 @@
+import * as React from 'react';
+
+import { SelectableValue } from '@grafana/data';
+import { InlineField, InlineFieldRow, InlineSwitch, Input, MultiSelect, Select, TagsInput } from '@grafana/ui';
+
+import { GrafanaQuery, GrafanaQueryType } from '../types';
+
+interface Props {
+  query: GrafanaQuery;
+  onChange: (query: GrafanaQuery) => void;
+  onRunQuery: () => void;
+}
+
+const labelWidth = 12;
+
+const annotationTypes: Array<SelectableValue<GrafanaQuery['annotationType']>> = [
+  { label: 'All', value: undefined },
+  { label: 'Annotations', value: 'annotation', description: 'Annotations created by users and the API' },
+  { label: 'Alerts', value: 'alert', description: 'Annotations of alert state changes' },
+];
+
+const alertStates: Array<SelectableValue<string>> = [
+  { label: 'Alerting', value: 'Alerting' },
+  { label: 'Pending', value: 'Pending' },
+  { label: 'Normal', value: 'Normal' },
+  { label: 'No data', value: 'NoData' },
+  { label: 'Error', value: 'Error' },
+];
+
+/**
+ * Editor of the queries returning annotations and alerting data of Grafana as data frames.
+ */
+export default function GrafanaDataEditor({ query, onChange, onRunQuery }: Props) {
+  const update = (changes: Partial<GrafanaQuery>) => {
+    onChange({ ...query, ...changes });
+    onRunQuery();
+  };
+
+  const onTextBlur = (key: 'dashboardUID' | 'ruleUID') => (e: React.FocusEvent<HTMLInputElement>) => {
+    const value = e.currentTarget.value || undefined;
+    if (value !== query[key]) {
+      update({ [key]: value });
+    }
+  };
+
+  const onNumberBlur = (key: 'panelId' | 'limit') => (e: React.FocusEvent<HTMLInputElement>) => {
+    const value = e.currentTarget.valueAsNumber;
+    update({ [key]: isNaN(value) ? undefined : value });
+  };
+
+  const isAlerting = query.queryType !== GrafanaQueryType.AnnotationList;
+
+  return (
+    <>
+      <InlineFieldRow>
+        {isAlerting && (
+          <InlineField label="Rule UID" labelWidth={labelWidth} tooltip="Only return data of this alert rule">
+            <Input defaultValue={query.ruleUID} placeholder="All rules" onBlur={onTextBlur('ruleUID')} width={24} />
+          </InlineField>
+        )}
+        <InlineField label="Dashboard UID" labelWidth={labelWidth + 2}>
+          <Input
+            defaultValue={query.dashboardUID}
+            placeholder="All dashboards"
+            onBlur={onTextBlur('dashboardUID')}
+            width={24}
+          />
+        </InlineField>
+        <InlineField label="Panel ID" labelWidth={labelWidth - 4}>
+          <Input type="number" defaultValue={query.panelId} onBlur={onNumberBlur('panelId')} width={10} />
+        </InlineField>
+        {query.queryType !== GrafanaQueryType.AlertInstances && (
+          <InlineField label="Limit" labelWidth={labelWidth - 6}>
+            <Input
+              type="number"
+              defaultValue={query.limit}
+              placeholder="100"
+              onBlur={onNumberBlur('limit')}
+              width={10}
+            />
+          </InlineField>
+        )}
+      </InlineFieldRow>
+      {query.queryType === GrafanaQueryType.AnnotationList && (
+        <InlineFieldRow>
+          <InlineField label="Type" labelWidth={labelWidth}>
+            <Select
+              options={annotationTypes}
+              value={annotationTypes.find((v) => v.value === query.annotationType) ?? annotationTypes[0]}
+              onChange={(v) => update({ annotationType: v.value })}
+              width={24}
+            />
+          </InlineField>
+          <InlineField label="Tags" labelWidth={labelWidth - 4}>
+            <TagsInput tags={query.tags ?? []} onChange={(tags) => update({ tags })} width={40} />
+          </InlineField>
+          <InlineField label="Match any" labelWidth={labelWidth - 2} tooltip="Match annotations with any of the tags">
+            <InlineSwitch
+              value={query.matchAny ?? false}
+              onChange={(e) => update({ matchAny: e.currentTarget.checked })}
+            />
+          </InlineField>
+        </InlineFieldRow>
+      )}
+      {query.queryType === GrafanaQueryType.AlertInstances && (
+        <InlineFieldRow>
+          <InlineField label="States" labelWidth={labelWidth}>
+            <MultiSelect
+              options={alertStates}
+              value={query.states ?? []}
+              placeholder="All states"
+              onChange={(v) => update({ states: v.map((s) => s.value!) })}
+              width={48}
+            />
+          </InlineField>
+        </InlineFieldRow>
+      )}
+    </>
+  );
+}
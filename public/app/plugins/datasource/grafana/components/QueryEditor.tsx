@@ -39,6 +39,7 @@ import { SearchQuery } from 'app/features/search/service';
 import { GrafanaDatasource } from '../datasource';
 import { defaultQuery, GrafanaQuery, GrafanaQueryType } from '../types';
 
+import GrafanaDataEditor from './GrafanaDataEditor';
 import SearchEditor from './SearchEditor';
 
 interface Props extends QueryEditorProps<GrafanaDatasource, GrafanaQuery>, Themeable2 {}
@@ -70,6 +71,21 @@ export class UnthemedQueryEditor extends PureComponent<Props, State> {
       value: GrafanaQueryType.List,
       description: 'Show directory listings for public resources',
     },
+    {
+      label: 'Annotations list',
+      value: GrafanaQueryType.AnnotationList,
+      description: 'Annotations in the time range as a table',
+    },
+    {
+      label: 'Alert instances',
+      value: GrafanaQueryType.AlertInstances,
+      description: 'Current state of alert instances',
+    },
+    {
+      label: 'Alert state history',
+      value: GrafanaQueryType.AlertStateHistory,
+      description: 'State changes of alert instances in the time range',
+    },
   ];
 
   constructor(props: Props) {
@@ -475,6 +491,11 @@ export class UnthemedQueryEditor extends PureComponent<Props, State> {
         {queryType === GrafanaQueryType.Search && (
           <SearchEditor value={query.search ?? {}} onChange={this.onSearchChange} />
         )}
+        {(queryType === GrafanaQueryType.AnnotationList ||
+          queryType === GrafanaQueryType.AlertInstances ||
+          queryType === GrafanaQueryType.AlertStateHistory) && (
+          <GrafanaDataEditor query={query} onChange={this.props.onChange} onRunQuery={this.props.onRunQuery} />
+        )}
       </>
     );
   }
@@ -19,6 +19,9 @@ export enum GrafanaQueryType {
   List = 'list',
   Read = 'read',
   Search = 'search',
+  AnnotationList = 'annotationList',
+  AlertInstances = 'alertInstances',
+  AlertStateHistory = 'alertStateHistory',
 }
 
 export interface GrafanaQuery extends DataQuery {
@@ -31,6 +34,15 @@ export interface GrafanaQuery extends DataQuery {
   snapshot?: DataFrameJSON[];
   timeRegion?: TimeRegionConfig;
   file?: GrafanaQueryFile;
+  // for annotation list and alerting queries
+  dashboardUID?: string;
+  panelId?: number;
+  tags?: string[];
+  matchAny?: boolean;
+  annotationType?: 'annotation' | 'alert';
+  ruleUID?: string;
+  states?: string[];
+  limit?: number;
 }
 
 export interface GrafanaQueryFile {
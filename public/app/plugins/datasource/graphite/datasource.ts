@@ -21,7 +21,7 @@ import {
   toDataFrame,
   getSearchFilterScopedVar,
 } from '@grafana/data';
-import { BackendSrvRequest, getBackendSrv } from '@grafana/runtime';
+import { BackendSrvRequest, getBackendSrv, HealthCheckResult, HealthStatus } from '@grafana/runtime';
 import { isVersionGtOrEq, SemVersion } from 'app/core/utils/version';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
 import { getRollupNotice, getRuntimeConsolidationNotice } from 'app/plugins/datasource/graphite/meta';
@@ -46,6 +46,12 @@ import {
 import { reduceError } from './utils';
 import { DEFAULT_GRAPHITE_VERSION } from './versions';
 
+/**
+ * Metric and tag lookups which are sent to the resource API of the backend, unless the data source is accessed
+ * directly from the browser. The backend only allows these read-only endpoints.
+ */
+const RESOURCE_PATHS = /^\/(metrics\/(find|expand)|tags(\/.+)?|functions|version)$/;
+
 const GRAPHITE_TAG_COMPARATORS = {
   '=': AbstractLabelOperator.Equal,
   '!=': AbstractLabelOperator.NotEqual,
@@ -250,7 +256,7 @@ export class GraphiteDatasource
     httpOptions: BackendSrvRequest,
     options: { dashboardId?: number; panelId?: number; panelPluginId?: string }
   ) {
-    const proxyMode = !this.url.match(/^http/);
+    const proxyMode = this.isProxyAccess();
     if (!httpOptions.headers) {
       httpOptions.headers = {};
     }
@@ -634,17 +640,26 @@ export class GraphiteDatasource
       params.until = range.until;
     }
 
-    const httpOptions: BackendSrvRequest = {
-      method: 'POST',
-      url: '/metrics/find',
-      params,
-      data: `query=${query}`,
-      headers: {
-        'Content-Type': 'application/x-www-form-urlencoded',
-      },
-      // for cancellations
-      requestId: requestId,
-    };
+    // The resource API only allows reading metrics with GET
+    const httpOptions: BackendSrvRequest = this.isProxyAccess()
+      ? {
+          method: 'GET',
+          url: '/metrics/find',
+          params: { ...params, query },
+          // for cancellations
+          requestId: requestId,
+        }
+      : {
+          method: 'POST',
+          url: '/metrics/find',
+          params,
+          data: `query=${query}`,
+          headers: {
+            'Content-Type': 'application/x-www-form-urlencoded',
+          },
+          // for cancellations
+          requestId: requestId,
+        };
 
     return lastValueFrom(
       this.doGraphiteRequest(httpOptions).pipe(
@@ -900,6 +915,10 @@ export class GraphiteDatasource
   }
 
   testDatasource() {
+    if (this.isProxyAccess()) {
+      return this.callHealthCheck();
+    }
+
     const query: DataQueryRequest<GraphiteQuery> = {
       app: 'graphite',
       interval: '10ms',
@@ -922,6 +941,31 @@ export class GraphiteDatasource
     return lastValueFrom(this.query(query)).then(() => ({ status: 'success', message: 'Data source is working' }));
   }
 
+  /**
+   * Checks that Grafana can list the metric tree of Graphite
+   */
+  private callHealthCheck() {
+    return lastValueFrom(
+      getBackendSrv().fetch<HealthCheckResult>({
+        method: 'GET',
+        url: `/api/datasources/uid/${this.uid}/health`,
+        showErrorAlert: false,
+      })
+    )
+      .then((res) => res.data)
+      .catch((err) => err.data)
+      .then((res?: HealthCheckResult) => {
+        if (res?.status === HealthStatus.OK) {
+          return { status: 'success', message: res.message };
+        }
+        return Promise.reject({ status: 'error', message: res?.message ?? 'Unable to connect with Graphite' });
+      });
+  }
+
+  isProxyAccess() {
+    return !this.url.match(/^http/);
+  }
+
   doGraphiteRequest(
     options: BackendSrvRequest & {
       inspect?: any;
@@ -935,7 +979,10 @@ export class GraphiteDatasource
       options.headers.Authorization = this.basicAuth;
     }
 
-    options.url = this.url + options.url;
+    options.url =
+      this.isProxyAccess() && RESOURCE_PATHS.test(options.url)
+        ? `/api/datasources/uid/${this.uid}/resources${options.url}`
+        : this.url + options.url;
     options.inspect = { type: 'graphite' };
 
     return getBackendSrv()
@@ -26,7 +26,7 @@ import {
   ScopedVars,
   toDataFrame,
 } from '@grafana/data';
-import { FetchResponse, getBackendSrv } from '@grafana/runtime';
+import { FetchResponse, getBackendSrv, HealthCheckResult, HealthStatus } from '@grafana/runtime';
 import { getTemplateSrv, TemplateSrv } from 'app/features/templating/template_srv';
 
 import { AnnotationEditor } from './components/AnnotationEditor';
@@ -324,9 +324,13 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     relativeUrl: string,
     params?: { type?: string; q?: string; max?: number; m?: string; limit?: number }
   ): Observable<FetchResponse> {
+    // Lookups are sent to the resource API of the backend, unless the data source is accessed directly from the
+    // browser
     const options = {
       method: 'GET',
-      url: this.url + relativeUrl,
+      url: this.isProxyAccess()
+        ? `/api/datasources/uid/${this.uid}/resources/${relativeUrl.replace(/^\/api\//, '')}`
+        : this.url + relativeUrl,
       params: params,
     };
 
@@ -335,6 +339,10 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
     return getBackendSrv().fetch(options);
   }
 
+  isProxyAccess() {
+    return !this.url.match(/^http/);
+  }
+
   _addCredentialOptions(options: Record<string, unknown>) {
     if (this.basicAuth || this.withCredentials) {
       options.withCredentials = true;
@@ -399,6 +407,24 @@ export default class OpenTsDatasource extends DataSourceApi<OpenTsdbQuery, OpenT
   }
 
   testDatasource() {
+    if (this.isProxyAccess()) {
+      return lastValueFrom(
+        getBackendSrv().fetch<HealthCheckResult>({
+          method: 'GET',
+          url: `/api/datasources/uid/${this.uid}/health`,
+          showErrorAlert: false,
+        })
+      )
+        .then((res) => res.data)
+        .catch((err) => err.data)
+        .then((res?: HealthCheckResult) => {
+          if (res?.status === HealthStatus.OK) {
+            return { status: 'success', message: res.message };
+          }
+          return Promise.reject({ status: 'error', message: res?.message ?? 'Unable to connect with OpenTSDB' });
+        });
+    }
+
     return lastValueFrom(
       this._performSuggestQuery('cpu', 'metrics').pipe(
         map(() => {
@@ -0,0 +1,61 @@
+import { DataSourcePluginOptionsEditorProps, onUpdateDatasourceJsonDataOption } from '@grafana/data';
+import { ConfigSection, DataSourceDescription } from '@grafana/experimental';
+import { ConnectionLimits, Divider, QueryLimits } from '@grafana/sql';
+import { Alert, Field, Input } from '@grafana/ui';
+
+import { SQLiteOptions } from '../types';
+
+const LONG_WIDTH = 40;
+
+export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+  const jsonData = options.jsonData;
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQLite"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/sqlite/"
+        hasRequiredFields
+      />
+      <Alert title="Read-only access" severity="info">
+        Database files are opened read-only and must be located in one of the directories configured with{' '}
+        <code>sqlite_allowed_paths</code> in the <code>[sql_datasources]</code> section of the Grafana configuration.
+      </Alert>
+      <Divider />
+      <ConfigSection title="Connection">
+        <Field
+          label="Database file"
+          description="Path of the database file, relative paths are relative to the first allowed directory."
+          required
+          invalid={!jsonData.database}
+          error={'Database file is required'}
+        >
+          <Input
+            width={LONG_WIDTH}
+            name="database"
+            value={jsonData.database || ''}
+            placeholder="metrics.db"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'database')}
+          />
+        </Field>
+      </ConfigSection>
+      <Divider />
+      <ConfigSection title="Additional settings" isCollapsible={true} isInitiallyOpen={true}>
+        <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
+        <QueryLimits options={options} onOptionsChange={onOptionsChange} alwaysReadOnly />
+        <Field
+          label="Min time interval"
+          description="A lower limit for the auto group by time interval. Recommended to be set to write frequency, for example 1m if your data is written every minute."
+        >
+          <Input
+            width={LONG_WIDTH}
+            placeholder="1m"
+            value={jsonData.timeInterval || ''}
+            onChange={onUpdateDatasourceJsonDataOption(props, 'timeInterval')}
+          />
+        </Field>
+      </ConfigSection>
+    </>
+  );
+};
@@ -0,0 +1,76 @@
+import { DataSourceInstanceSettings } from '@grafana/data';
+import { LanguageDefinition } from '@grafana/experimental';
+import { DB, SQLQuery, SqlDatasource, SQLSelectableValue, formatSQL } from '@grafana/sql';
+
+import { buildColumnQuery, buildTableQuery } from './sqliteMetaQuery';
+import { quoteIdentifierIfNecessary, quoteLiteral, toRawSql } from './sqlUtil';
+import { SQLiteOptions } from './types';
+
+// SQLite data sources query a single database file, its tables are listed
+// under the main schema.
+const MAIN_DATASET = 'main';
+
+export class SQLiteDatasource extends SqlDatasource {
+  sqlLanguageDefinition: LanguageDefinition | undefined;
+
+  constructor(instanceSettings: DataSourceInstanceSettings<SQLiteOptions>) {
+    super(instanceSettings);
+  }
+
+  getQueryModel() {
+    return { quoteLiteral };
+  }
+
+  getSqlLanguageDefinition(): LanguageDefinition {
+    if (this.sqlLanguageDefinition !== undefined) {
+      return this.sqlLanguageDefinition;
+    }
+    this.sqlLanguageDefinition = {
+      id: 'sql',
+      formatter: formatSQL,
+    };
+    return this.sqlLanguageDefinition;
+  }
+
+  async fetchTables(): Promise<string[]> {
+    const tables = await this.runSql<string[]>(buildTableQuery(), { refId: 'tables' });
+    return tables.map((t) => quoteIdentifierIfNecessary(t[0]));
+  }
+
+  async fetchFields(query: Partial<SQLQuery>): Promise<SQLSelectableValue[]> {
+    if (!query.table) {
+      return [];
+    }
+    const frame = await this.runSql<string[]>(buildColumnQuery(query.table), { refId: 'fields' });
+    return frame.map((f) => ({
+      label: f[0],
+      value: quoteIdentifierIfNecessary(f[0]),
+      type: f[1],
+    }));
+  }
+
+  getDB(): DB {
+    if (this.db !== undefined) {
+      return this.db;
+    }
+    return {
+      init: () => Promise.resolve(true),
+      datasets: () => Promise.resolve([MAIN_DATASET]),
+      tables: () => this.fetchTables(),
+      fields: (query: SQLQuery) => this.fetchFields(query),
+      getEditorLanguageDefinition: () => this.getSqlLanguageDefinition(),
+      validateQuery: (query) =>
+        Promise.resolve({ isError: false, isValid: true, query, error: '', rawSql: query.rawSql }),
+      dsID: () => this.id,
+      dispose: (_dsID?: string) => {},
+      toRawSql,
+      lookup: async (path?: string) => {
+        if (!path) {
+          const tables = await this.fetchTables();
+          return tables.map((t) => ({ name: t, completion: t }));
+        }
+        return [];
+      },
+    };
+  }
+}
@@ -0,0 +1,16 @@
+import { quoteLiteral } from './sqlUtil';
+
+export function buildTableQuery() {
+  return `SELECT name FROM sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%' ORDER BY name`;
+}
+
+export function buildColumnQuery(table: string) {
+  return `SELECT name, type FROM pragma_table_info(${quoteLiteral(unquoteIdentifier(table))}) ORDER BY cid`;
+}
+
+function unquoteIdentifier(value: string) {
+  if (value[0] === '"' && value[value.length - 1] === '"') {
+    return value.substring(1, value.length - 1).replace(/""/g, '"');
+  }
+  return value;
+}
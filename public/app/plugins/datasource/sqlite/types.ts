@@ -0,0 +1,3 @@
+import { SQLOptions } from '@grafana/sql';
+
+export interface SQLiteOptions extends SQLOptions {}
@@ -363,8 +363,8 @@ describe('Tempo data source', () => {
   });
 
   describe('test the testDatasource function', () => {
-    it('should return a success msg if response.ok is true', async () => {
-      mockObservable = () => of({ ok: true });
+    it('should return a success msg if the health check succeeds', async () => {
+      mockObservable = () => of({ data: { status: 'OK', message: 'Data source successfully connected.' } });
       const ds = new TempoDatasource(defaultSettings);
       const response = await ds.testDatasource();
       expect(response.status).toBe('success');
@@ -18,7 +18,6 @@ import {
   rangeUtil,
   ScopedVars,
   SelectableValue,
-  TestDataSourceResponse,
   urlUtil,
 } from '@grafana/data';
 import { NodeGraphOptions, SpanBarOptions, TraceToLogsOptions } from '@grafana/o11y-ds-frontend';
@@ -704,8 +703,18 @@ export class TempoDatasource extends DataSourceWithBackend<TempoQuery, TempoJson
     );
   }
 
+  /**
+   * Tag lookups are sent to the resource API of the backend
+   */
   async metadataRequest(url: string, params = {}) {
-    return await lastValueFrom(this._request(url, params, { method: 'GET', hideFromInspector: true }));
+    return await lastValueFrom(
+      getBackendSrv().fetch({
+        method: 'GET',
+        url: `/api/datasources/uid/${this.uid}/resources${url}`,
+        params,
+        hideFromInspector: true,
+      })
+    );
   }
 
   _request(apiUrl: string, data?: unknown, options?: Partial<BackendSrvRequest>): Observable<Record<string, any>> {
@@ -716,27 +725,6 @@ export class TempoDatasource extends DataSourceWithBackend<TempoQuery, TempoJson
     return getBackendSrv().fetch(req);
   }
 
-  async testDatasource(): Promise<TestDataSourceResponse> {
-    const options: BackendSrvRequest = {
-      headers: {},
-      method: 'GET',
-      url: `${this.instanceSettings.url}/api/echo`,
-    };
-
-    return await lastValueFrom(
-      getBackendSrv()
-        .fetch(options)
-        .pipe(
-          mergeMap(() => {
-            return of({ status: 'success', message: 'Data source successfully connected.' });
-          }),
-          catchError((err) => {
-            return of({ status: 'error', message: getErrorMessage(err.data.message, 'Unable to connect with Tempo') });
-          })
-        )
-    );
-  }
-
   getQueryDisplayText(query: TempoQuery) {
     if (query.queryType === 'traceql' || query.queryType === 'traceId') {
       return query.query ?? '';